@@ -4,7 +4,6 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"os"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
@@ -22,18 +21,6 @@ type Config struct {
 	SSLMode  string
 }
 
-// GetConfigFromEnv obtiene la configuración desde variables de entorno
-func GetConfigFromEnv() *Config {
-	return &Config{
-		Host:     getEnvOrDefault("POSTGRES_HOST", getEnvOrDefault("DB_HOST", "localhost")),
-		Port:     getEnvOrDefault("POSTGRES_PORT", getEnvOrDefault("DB_PORT", "5432")),
-		User:     getEnvOrDefault("POSTGRES_USER", getEnvOrDefault("DB_USER", "postgres")),
-		Password: getEnvOrDefault("POSTGRES_PASSWORD", getEnvOrDefault("DB_PASSWORD", "postgres")),
-		DBName:   getEnvOrDefault("POSTGRES_DB", getEnvOrDefault("DB_NAME", "banca_en_linea")),
-		SSLMode:  getEnvOrDefault("DB_SSLMODE", "disable"),
-	}
-}
-
 // GetDSN construye el Data Source Name para PostgreSQL
 func (c *Config) GetDSN() string {
 	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
@@ -49,6 +36,7 @@ func Connect(config *Config) (*sql.DB, error) {
 
 	// Verificar la conexión
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("error connecting to database: %w", err)
 	}
 
@@ -96,11 +84,3 @@ func RunMigrations(db *sql.DB, migrationsPath string) error {
 	log.Println("Migrations completed successfully")
 	return nil
 }
-
-// getEnvOrDefault obtiene una variable de entorno o devuelve un valor por defecto
-func getEnvOrDefault(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -38,38 +39,41 @@ func SeedDatabase(userService *db.UserService, jsonFilePath string) error {
 
 	log.Printf("Found %d test users in JSON file", len(testUsers))
 
-	// 3. Crear usuarios en el sistema
-	successCount := 0
-	errorCount := 0
-
+	// 3. Crear usuarios en el sistema. Las cuentas TigerBeetle se crean en lote para no hacer
+	// una llamada a TigerBeetle por cada usuario.
+	createReqs := make([]*models.CreateUserRequest, 0, len(testUsers))
 	for _, testUser := range testUsers {
-		// Crear request para el usuario
-		createReq := &models.CreateUserRequest{
+		createReqs = append(createReqs, &models.CreateUserRequest{
 			Email:     testUser.Email,
 			Password:  testUser.Password, // En producción, esto debería ser más seguro
 			FirstName: testUser.FirstName,
 			LastName:  testUser.LastName,
-		}
+		})
+	}
 
-		// Crear usuario con cuenta TigerBeetle
-		user, err := userService.CreateUserWithAccount(createReq)
-		if err != nil {
-			log.Printf("Error creating user %s: %v", testUser.Email, err)
-			errorCount++
-			continue
-		}
+	users, failed := userService.CreateUsersWithAccounts(createReqs)
+	for email, err := range failed {
+		log.Printf("Error creating user %s: %v", email, err)
+	}
+
+	successCount := len(users)
+	errorCount := len(failed)
 
+	for _, user := range users {
 		// Realizar un depósito inicial de prueba (1000.00 HNL = 100000 centavos)
 		depositAmount := uint64(100000) // 1000.00 HNL en centavos
-		if err := userService.DepositToUser(user.ID, depositAmount); err != nil {
+		if err := userService.DepositToUser(context.Background(), user.ID, depositAmount); err != nil {
 			log.Printf("Warning: Could not deposit initial amount for user %s: %v", user.Email, err)
 		} else {
 			log.Printf("Deposited initial amount of 1000.00 HNL to user %s", user.Email)
 		}
 
-		successCount++
-		log.Printf("Successfully created user: %s (ID: %s, TigerBeetle Account: %d)",
-			user.Email, user.ID, *user.TigerBeetleAccountID)
+		if user.TigerBeetleAccountID != nil {
+			log.Printf("Successfully created user: %s (ID: %s, TigerBeetle Account: %d)",
+				user.Email, user.ID, *user.TigerBeetleAccountID)
+		} else {
+			log.Printf("Successfully created user: %s (ID: %s)", user.Email, user.ID)
+		}
 	}
 
 	log.Printf("Database seeding completed. Success: %d, Errors: %d", successCount, errorCount)
@@ -95,7 +99,7 @@ func CreateSampleTransactions(userService *db.UserService, userRepo db.UserRepos
 	log.Println("Creating sample transactions...")
 
 	// Obtener algunos usuarios para crear transacciones
-	users, err := userRepo.List(5, 0) // Obtener los primeros 5 usuarios
+	users, err := userRepo.List(context.Background(), nil, 5) // Obtener los primeros 5 usuarios
 	if err != nil {
 		return fmt.Errorf("error getting users for sample transactions: %w", err)
 	}
@@ -125,7 +129,7 @@ func CreateSampleTransactions(userService *db.UserService, userRepo db.UserRepos
 		fromUser := users[tx.fromIndex]
 		toUser := users[tx.toIndex]
 
-		err := userService.TransferBetweenUsers(fromUser.ID, toUser.ID, tx.amount)
+		_, err := userService.TransferBetweenUsers(context.Background(), fromUser.ID, toUser.ID, tx.amount)
 		if err != nil {
 			log.Printf("Error creating sample transaction from %s to %s: %v",
 				fromUser.Email, toUser.Email, err)
@@ -144,7 +148,7 @@ func CreateSampleTransactions(userService *db.UserService, userRepo db.UserRepos
 func PrintUserBalances(userService *db.UserService, userRepo db.UserRepository) error {
 	log.Println("=== User Balances ===")
 
-	users, err := userRepo.List(100, 0) // Obtener hasta 100 usuarios
+	users, err := userRepo.List(context.Background(), nil, 100) // Obtener hasta 100 usuarios
 	if err != nil {
 		return fmt.Errorf("error getting users: %w", err)
 	}
@@ -0,0 +1,16 @@
+package audit
+
+import "context"
+
+type remoteAddrKey struct{}
+
+// WithRemoteAddr agrega al contexto la dirección del cliente que originó la solicitud
+func WithRemoteAddr(ctx context.Context, remoteAddr string) context.Context {
+	return context.WithValue(ctx, remoteAddrKey{}, remoteAddr)
+}
+
+// RemoteAddrFromContext obtiene la dirección del cliente del contexto, o "" si no existe
+func RemoteAddrFromContext(ctx context.Context) string {
+	remoteAddr, _ := ctx.Value(remoteAddrKey{}).(string)
+	return remoteAddr
+}
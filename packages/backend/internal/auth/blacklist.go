@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TokenBlacklist define el almacenamiento de los JWT revocados, identificados por su "jti"
+type TokenBlacklist interface {
+	Add(ctx context.Context, jti string, expiresAt time.Time) error
+	Contains(ctx context.Context, jti string) (bool, error)
+}
+
+// WithTokenBlacklist habilita la revocación de access tokens usando el almacén indicado
+func WithTokenBlacklist(blacklist TokenBlacklist) ServiceOption {
+	return func(s *Service) {
+		s.blacklist = blacklist
+	}
+}
+
+// RevokeToken revoca un access token hasta su expiración
+func (s *Service) RevokeToken(claims *Claims) error {
+	if s.blacklist == nil || claims.ID == "" {
+		return nil
+	}
+
+	expiresAt := time.Now().Add(24 * time.Hour)
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	if err := s.blacklist.Add(ctx, claims.ID, expiresAt); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	return nil
+}
+
+// IsTokenRevoked indica si el access token fue revocado. Los tokens emitidos antes de
+// incluir "jti" no pueden revocarse y se consideran vigentes.
+func (s *Service) IsTokenRevoked(claims *Claims) (bool, error) {
+	if s.blacklist == nil || claims.ID == "" {
+		return false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	revoked, err := s.blacklist.Contains(ctx, claims.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check token blacklist: %w", err)
+	}
+
+	return revoked, nil
+}
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/models"
+)
+
+var (
+	ErrInvalidVerificationToken          = errors.New("invalid email verification token")
+	ErrEmailVerificationStoreRequired    = errors.New("email verification store not configured")
+	errMalformedEmailVerificationPayload = errors.New("malformed email verification payload")
+)
+
+// emailVerificationTTL es la vigencia de los tokens de verificación de email
+const emailVerificationTTL = 24 * time.Hour
+
+// EmailVerificationStore define el almacenamiento de tokens de verificación de email.
+// Solo se guarda el hash del token y cada token puede consumirse una única vez.
+type EmailVerificationStore interface {
+	Create(ctx context.Context, tokenHash string, userID uuid.UUID, expiresAt time.Time) error
+	Consume(ctx context.Context, tokenHash string) (uuid.UUID, error)
+}
+
+// WithEmailVerificationStore habilita la verificación de email usando el almacén indicado
+func WithEmailVerificationStore(store EmailVerificationStore) ServiceOption {
+	return func(s *Service) {
+		s.emailVerifications = store
+	}
+}
+
+// GenerateEmailVerificationToken genera un token firmado con HMAC-SHA256 sobre el ID del
+// usuario y la fecha de expiración, y guarda su hash para que solo pueda usarse una vez
+func (s *Service) GenerateEmailVerificationToken(user *models.User) (string, error) {
+	if s.emailVerifications == nil {
+		return "", ErrEmailVerificationStoreRequired
+	}
+
+	expiresAt := time.Now().Add(emailVerificationTTL)
+	payload := user.ID.String() + ":" + strconv.FormatInt(expiresAt.Unix(), 10)
+
+	token := base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		base64.RawURLEncoding.EncodeToString(s.signEmailVerification(payload))
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	if err := s.emailVerifications.Create(ctx, hashToken(token), user.ID, expiresAt); err != nil {
+		return "", fmt.Errorf("failed to store email verification token: %w", err)
+	}
+
+	return token, nil
+}
+
+// VerifyEmailToken valida la firma y la expiración del token, lo consume y retorna el ID del usuario
+func (s *Service) VerifyEmailToken(token string) (uuid.UUID, error) {
+	if s.emailVerifications == nil {
+		return uuid.Nil, ErrEmailVerificationStoreRequired
+	}
+
+	userID, expiresAt, err := s.parseEmailVerificationToken(token)
+	if err != nil {
+		return uuid.Nil, ErrInvalidVerificationToken
+	}
+
+	if !time.Now().Before(expiresAt) {
+		return uuid.Nil, ErrInvalidVerificationToken
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	storedUserID, err := s.emailVerifications.Consume(ctx, hashToken(token))
+	if err != nil || storedUserID != userID {
+		return uuid.Nil, ErrInvalidVerificationToken
+	}
+
+	return userID, nil
+}
+
+// parseEmailVerificationToken verifica la firma del token y extrae su contenido
+func (s *Service) parseEmailVerificationToken(token string) (uuid.UUID, time.Time, error) {
+	encodedPayload, encodedSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return uuid.Nil, time.Time{}, errMalformedEmailVerificationPayload
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return uuid.Nil, time.Time{}, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return uuid.Nil, time.Time{}, err
+	}
+
+	if !hmac.Equal(signature, s.signEmailVerification(string(payload))) {
+		return uuid.Nil, time.Time{}, ErrInvalidVerificationToken
+	}
+
+	rawUserID, rawExpiry, ok := strings.Cut(string(payload), ":")
+	if !ok {
+		return uuid.Nil, time.Time{}, errMalformedEmailVerificationPayload
+	}
+
+	userID, err := uuid.Parse(rawUserID)
+	if err != nil {
+		return uuid.Nil, time.Time{}, err
+	}
+
+	expiry, err := strconv.ParseInt(rawExpiry, 10, 64)
+	if err != nil {
+		return uuid.Nil, time.Time{}, err
+	}
+
+	return userID, time.Unix(expiry, 0), nil
+}
+
+// signEmailVerification calcula el HMAC-SHA256 del payload con el secreto del servicio
+func (s *Service) signEmailVerification(payload string) []byte {
+	mac := hmac.New(sha256.New, s.jwtSecret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
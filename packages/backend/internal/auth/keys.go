@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// ErrNoKeyConfigured indica que no hay llaves RSA configuradas en el entorno
+var ErrNoKeyConfigured = errors.New("no signing key configured")
+
+// KeyProvider provee las llaves usadas para firmar y verificar los JWT
+type KeyProvider interface {
+	GetSigningKey() (crypto.Signer, error)
+	GetVerificationKey() (crypto.PublicKey, error)
+}
+
+// RSAKeyProvider provee un par de llaves RSA para firmar los JWT con RS256
+type RSAKeyProvider struct {
+	privateKey *rsa.PrivateKey
+	publicKey  *rsa.PublicKey
+}
+
+// NewRSAKeyProvider crea un proveedor a partir de llaves en formato PEM. La llave pública
+// es opcional; si no se indica se deriva de la privada.
+func NewRSAKeyProvider(privatePEM, publicPEM []byte) (*RSAKeyProvider, error) {
+	privateKey, err := parseRSAPrivateKey(privatePEM)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey := &privateKey.PublicKey
+	if len(publicPEM) > 0 {
+		if publicKey, err = parseRSAPublicKey(publicPEM); err != nil {
+			return nil, err
+		}
+		if publicKey.N.Cmp(privateKey.N) != 0 || publicKey.E != privateKey.E {
+			return nil, fmt.Errorf("public key does not match private key")
+		}
+	}
+
+	return &RSAKeyProvider{privateKey: privateKey, publicKey: publicKey}, nil
+}
+
+// NewRSAKeyProviderFromEnv carga las llaves PEM desde los archivos indicados en
+// JWT_PRIVATE_KEY_FILE y JWT_PUBLIC_KEY_FILE, o directamente desde JWT_PRIVATE_KEY y
+// JWT_PUBLIC_KEY. Retorna ErrNoKeyConfigured si no hay llave privada configurada.
+func NewRSAKeyProviderFromEnv() (*RSAKeyProvider, error) {
+	privatePEM, err := readPEMFromEnv("JWT_PRIVATE_KEY")
+	if err != nil {
+		return nil, err
+	}
+	if len(privatePEM) == 0 {
+		return nil, ErrNoKeyConfigured
+	}
+
+	publicPEM, err := readPEMFromEnv("JWT_PUBLIC_KEY")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRSAKeyProvider(privatePEM, publicPEM)
+}
+
+// GetSigningKey retorna la llave privada RSA
+func (p *RSAKeyProvider) GetSigningKey() (crypto.Signer, error) {
+	return p.privateKey, nil
+}
+
+// GetVerificationKey retorna la llave pública RSA
+func (p *RSAKeyProvider) GetVerificationKey() (crypto.PublicKey, error) {
+	return p.publicKey, nil
+}
+
+// readPEMFromEnv lee una llave desde el archivo en <name>_FILE o desde la variable <name>.
+// En la variable se aceptan saltos de línea escapados como "\n".
+func readPEMFromEnv(name string) ([]byte, error) {
+	if path := os.Getenv(name + "_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name+"_FILE", err)
+		}
+		return data, nil
+	}
+
+	return []byte(strings.ReplaceAll(os.Getenv(name), `\n`, "\n")), nil
+}
+
+// parseRSAPrivateKey decodifica una llave privada RSA en formato PKCS#1 o PKCS#8
+func parseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode private key PEM")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// parseRSAPublicKey decodifica una llave pública RSA en formato PKIX o PKCS#1
+func parseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode public key PEM")
+	}
+
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	key, ok := parsed.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is not an RSA key")
+	}
+	return key, nil
+}
+
+// JWK representa una llave pública RSA en formato JSON Web Key
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+}
+
+// JWKS representa un documento JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// newRSAJWK serializa una llave pública RSA como JWK
+func newRSAJWK(key *rsa.PublicKey) JWK {
+	return JWK{
+		KeyType:   "RSA",
+		Use:       "sig",
+		Algorithm: "RS256",
+		KeyID:     rsaKeyID(key),
+		Modulus:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		Exponent:  base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+// rsaKeyID calcula el thumbprint de la llave (RFC 7638) para usarlo como "kid"
+func rsaKeyID(key *rsa.PublicKey) string {
+	// Los miembros requeridos en orden lexicográfico, sin espacios
+	thumbprintInput, _ := json.Marshal(struct {
+		E   string `json:"e"`
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+	}{
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		Kty: "RSA",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+	})
+
+	sum := sha256.Sum256(thumbprintInput)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
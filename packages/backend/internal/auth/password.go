@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"strings"
+	"unicode"
+)
+
+// minPasswordLength es la longitud mínima de una contraseña
+const minPasswordLength = 8
+
+// PasswordComplexityError lista las reglas de complejidad que no cumple una contraseña
+type PasswordComplexityError struct {
+	Violations []string
+}
+
+func (e *PasswordComplexityError) Error() string {
+	return "password must " + strings.Join(e.Violations, ", ")
+}
+
+// ValidatePasswordComplexity verifica que la contraseña tenga al menos 8 caracteres,
+// una mayúscula, un dígito y un carácter especial. Retorna *PasswordComplexityError
+// con todas las reglas incumplidas.
+func ValidatePasswordComplexity(password string) error {
+	var hasUpper, hasDigit, hasSpecial bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSpecial = true
+		}
+	}
+
+	var violations []string
+	if len([]rune(password)) < minPasswordLength {
+		violations = append(violations, "be at least 8 characters long")
+	}
+	if !hasUpper {
+		violations = append(violations, "contain at least one uppercase letter")
+	}
+	if !hasDigit {
+		violations = append(violations, "contain at least one digit")
+	}
+	if !hasSpecial {
+		violations = append(violations, "contain at least one special character")
+	}
+
+	if len(violations) > 0 {
+		return &PasswordComplexityError{Violations: violations}
+	}
+	return nil
+}
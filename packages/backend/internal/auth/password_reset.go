@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/models"
+)
+
+var (
+	ErrInvalidResetToken          = errors.New("invalid password reset token")
+	ErrPasswordResetStoreRequired = errors.New("password reset store not configured")
+)
+
+// passwordResetTTL es la vigencia de los tokens de restablecimiento de contraseña
+const passwordResetTTL = time.Hour
+
+// PasswordResetStore define el almacenamiento de tokens de restablecimiento de contraseña.
+// Solo se guarda el hash del token y cada token puede consumirse una única vez.
+type PasswordResetStore interface {
+	Create(ctx context.Context, tokenHash string, userID uuid.UUID, expiresAt time.Time) error
+	Consume(ctx context.Context, tokenHash string) (uuid.UUID, error)
+}
+
+// WithPasswordResetStore habilita el restablecimiento de contraseña usando el almacén indicado
+func WithPasswordResetStore(store PasswordResetStore) ServiceOption {
+	return func(s *Service) {
+		s.passwordResets = store
+	}
+}
+
+// GeneratePasswordResetToken genera un token aleatorio de 32 bytes con vigencia de 1 hora y guarda su hash
+func (s *Service) GeneratePasswordResetToken(user *models.User) (string, error) {
+	if s.passwordResets == nil {
+		return "", ErrPasswordResetStoreRequired
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate password reset token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	if err := s.passwordResets.Create(ctx, hashToken(token), user.ID, time.Now().Add(passwordResetTTL)); err != nil {
+		return "", fmt.Errorf("failed to store password reset token: %w", err)
+	}
+
+	return token, nil
+}
+
+// ConsumePasswordResetToken valida el token, lo marca como usado y retorna el ID del usuario
+func (s *Service) ConsumePasswordResetToken(token string) (uuid.UUID, error) {
+	if s.passwordResets == nil {
+		return uuid.Nil, ErrPasswordResetStoreRequired
+	}
+	if token == "" {
+		return uuid.Nil, ErrInvalidResetToken
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	userID, err := s.passwordResets.Consume(ctx, hashToken(token))
+	if err != nil {
+		return uuid.Nil, ErrInvalidResetToken
+	}
+
+	return userID, nil
+}
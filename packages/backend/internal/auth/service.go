@@ -1,15 +1,21 @@
 package auth
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 
+	"banca-en-linea/backend/internal/config"
 	"banca-en-linea/backend/models"
 )
 
@@ -17,33 +23,84 @@ var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrTokenExpired       = errors.New("token expired")
 	ErrInvalidToken       = errors.New("invalid token")
+
+	ErrInvalidRefreshToken       = errors.New("invalid refresh token")
+	ErrRefreshTokenStoreRequired = errors.New("refresh token store not configured")
+	ErrSessionNotFound           = errors.New("session not found")
 )
 
+// refreshTokenTTL es la vigencia de los refresh tokens
+const refreshTokenTTL = 7 * 24 * time.Hour
+
+// storeTimeout es el tiempo máximo de las operaciones sobre los almacenes de tokens
+const storeTimeout = 5 * time.Second
+
+// RefreshTokenStore define el almacenamiento de refresh tokens. Solo se guarda el hash del token.
+type RefreshTokenStore interface {
+	Create(ctx context.Context, tokenHash string, userID uuid.UUID, expiresAt time.Time, session models.SessionInfo) error
+	GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	Revoke(ctx context.Context, tokenHash string) error
+	ListActiveByUser(ctx context.Context, userID uuid.UUID) ([]models.Session, error)
+	RevokeByID(ctx context.Context, id, userID uuid.UUID) (bool, error)
+}
+
 // Claims representa los claims del JWT
 type Claims struct {
-	UserID uuid.UUID `json:"user_id"`
-	Email  string    `json:"email"`
+	UserID  uuid.UUID `json:"user_id"`
+	Email   string    `json:"email"`
+	Roles   []string  `json:"roles,omitempty"`
+	Purpose string    `json:"purpose,omitempty"` // Vacío en los access tokens
 	jwt.RegisteredClaims
 }
 
 // Service maneja la autenticación y autorización
 type Service struct {
-	jwtSecret []byte
+	jwtSecret          []byte
+	keys               KeyProvider
+	refreshTokens      RefreshTokenStore
+	emailVerifications EmailVerificationStore
+	passwordResets     PasswordResetStore
+	blacklist          TokenBlacklist
 }
 
-// NewService crea una nueva instancia del servicio de autenticación
-func NewService() *Service {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		// En desarrollo, usar un secreto por defecto (NO hacer esto en producción)
-		secret = "your-super-secret-jwt-key-change-this-in-production"
+// ServiceOption configura dependencias opcionales del Service
+type ServiceOption func(*Service)
+
+// WithRefreshTokenStore habilita los refresh tokens usando el almacén indicado
+func WithRefreshTokenStore(store RefreshTokenStore) ServiceOption {
+	return func(s *Service) {
+		s.refreshTokens = store
 	}
+}
 
-	return &Service{
-		jwtSecret: []byte(secret),
+// WithKeyProvider firma los JWT con RS256 usando las llaves del proveedor. Sin esta
+// opción los JWT se firman con HS256 usando JWT_SECRET.
+func WithKeyProvider(keys KeyProvider) ServiceOption {
+	return func(s *Service) {
+		s.keys = keys
 	}
 }
 
+// WithJWTSecret firma los JWT con HS256 usando el secreto indicado (Config.JWTSecret)
+func WithJWTSecret(secret string) ServiceOption {
+	return func(s *Service) {
+		s.jwtSecret = []byte(secret)
+	}
+}
+
+// NewService crea una nueva instancia del servicio de autenticación. Sin WithJWTSecret se usa
+// el secreto de desarrollo config.DefaultJWTSecret (NO hacer esto en producción).
+func NewService(opts ...ServiceOption) *Service {
+	s := &Service{
+		jwtSecret: []byte(config.DefaultJWTSecret),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
 // HashPassword hashea una contraseña usando bcrypt
 func (s *Service) HashPassword(password string) (string, error) {
 	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -66,7 +123,9 @@ func (s *Service) GenerateToken(user *models.User) (string, error) {
 	claims := &Claims{
 		UserID: user.ID,
 		Email:  user.Email,
+		Roles:  user.Roles,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -75,8 +134,7 @@ func (s *Service) GenerateToken(user *models.User) (string, error) {
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(s.jwtSecret)
+	tokenString, err := s.signToken(claims)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate token: %w", err)
 	}
@@ -84,17 +142,84 @@ func (s *Service) GenerateToken(user *models.User) (string, error) {
 	return tokenString, nil
 }
 
-// ValidateToken valida un JWT token y retorna los claims
-func (s *Service) ValidateToken(tokenString string) (*Claims, error) {
-	claims := &Claims{}
+// signToken firma los claims con RS256 si hay un KeyProvider configurado, o con HS256 en otro caso
+func (s *Service) signToken(claims *Claims) (string, error) {
+	if s.keys == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.jwtSecret)
+	}
+
+	signer, err := s.keys.GetSigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	privateKey, ok := signer.(*rsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("signing key is not an RSA key")
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = rsaKeyID(&privateKey.PublicKey)
+	return token.SignedString(privateKey)
+}
 
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		// Verificar que el método de firma sea el esperado
+// verificationKey retorna la llave para verificar un JWT, exigiendo el algoritmo configurado
+func (s *Service) verificationKey(token *jwt.Token) (interface{}, error) {
+	if s.keys == nil {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return s.jwtSecret, nil
-	})
+	}
+
+	if token.Method != jwt.SigningMethodRS256 {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return s.keys.GetVerificationKey()
+}
+
+// JWKS retorna la llave pública de verificación como documento JWKS. Sin KeyProvider
+// (firma HS256) el documento no contiene llaves.
+func (s *Service) JWKS() (*JWKS, error) {
+	jwks := &JWKS{Keys: []JWK{}}
+	if s.keys == nil {
+		return jwks, nil
+	}
+
+	key, err := s.keys.GetVerificationKey()
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("verification key is not an RSA key")
+	}
+
+	jwks.Keys = append(jwks.Keys, newRSAJWK(publicKey))
+	return jwks, nil
+}
+
+// ValidateToken valida un JWT token y retorna los claims. Los tokens emitidos para
+// otro propósito (por ejemplo, el desafío MFA) no son aceptados como access token.
+func (s *Service) ValidateToken(tokenString string) (*Claims, error) {
+	claims, err := s.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Purpose != "" {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+// parseToken verifica la firma y la vigencia de un JWT y retorna sus claims
+func (s *Service) parseToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, s.verificationKey)
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -117,3 +242,108 @@ func (s *Service) AuthenticateUser(email, password, hashedPassword string) error
 	}
 	return nil
 }
+
+// GenerateRefreshToken genera un refresh token opaco de 7 días y guarda su hash junto con el
+// dispositivo y la IP de la sesión
+func (s *Service) GenerateRefreshToken(user *models.User, session models.SessionInfo) (string, error) {
+	if s.refreshTokens == nil {
+		return "", ErrRefreshTokenStoreRequired
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	if err := s.refreshTokens.Create(ctx, hashToken(token), user.ID, time.Now().Add(refreshTokenTTL), session); err != nil {
+		return "", fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return token, nil
+}
+
+// RefreshAccessToken valida un refresh token almacenado y emite un nuevo access token
+func (s *Service) RefreshAccessToken(refreshToken string) (string, error) {
+	if s.refreshTokens == nil {
+		return "", ErrRefreshTokenStoreRequired
+	}
+	if refreshToken == "" {
+		return "", ErrInvalidRefreshToken
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	stored, err := s.refreshTokens.GetByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		return "", ErrInvalidRefreshToken
+	}
+
+	if stored.RevokedAt != nil || !time.Now().Before(stored.ExpiresAt) {
+		return "", ErrInvalidRefreshToken
+	}
+
+	return s.GenerateToken(&models.User{ID: stored.UserID, Email: stored.UserEmail, Roles: stored.UserRoles})
+}
+
+// RevokeRefreshToken marca un refresh token como revocado
+func (s *Service) RevokeRefreshToken(refreshToken string) error {
+	if s.refreshTokens == nil {
+		return ErrRefreshTokenStoreRequired
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	if err := s.refreshTokens.Revoke(ctx, hashToken(refreshToken)); err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	return nil
+}
+
+// ListSessions lista las sesiones activas del usuario
+func (s *Service) ListSessions(ctx context.Context, userID uuid.UUID) ([]models.Session, error) {
+	if s.refreshTokens == nil {
+		return nil, ErrRefreshTokenStoreRequired
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, storeTimeout)
+	defer cancel()
+
+	sessions, err := s.refreshTokens.ListActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// RevokeSession revoca una sesión del usuario. Retorna ErrSessionNotFound si la sesión no existe,
+// ya fue revocada o pertenece a otro usuario.
+func (s *Service) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	if s.refreshTokens == nil {
+		return ErrRefreshTokenStoreRequired
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, storeTimeout)
+	defer cancel()
+
+	revoked, err := s.refreshTokens.RevokeByID(ctx, sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if !revoked {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// hashToken retorna el hash SHA-256 en hexadecimal de un token opaco
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pquerna/otp/totp"
+
+	"banca-en-linea/backend/models"
+)
+
+var (
+	ErrInvalidTOTPCode     = errors.New("invalid totp code")
+	ErrTOTPNotConfigured   = errors.New("totp not configured")
+	ErrInvalidMFAChallenge = errors.New("invalid mfa challenge token")
+)
+
+// totpIssuer es el emisor que muestran las aplicaciones de autenticación
+const totpIssuer = "Banca en Línea"
+
+// mfaChallengeTTL es la vigencia del token emitido tras validar la contraseña de un usuario con TOTP
+const mfaChallengeTTL = 5 * time.Minute
+
+// mfaChallengePurpose identifica a los JWT de desafío MFA, que no sirven como access token
+const mfaChallengePurpose = "mfa_challenge"
+
+// GenerateTOTPSecret genera un nuevo secreto TOTP para el usuario. Retorna el secreto
+// cifrado (para guardarlo en la base de datos) y la URI otpauth:// para el código QR.
+func (s *Service) GenerateTOTPSecret(user *models.User) (encryptedSecret, uri string, err error) {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      totpIssuer,
+		AccountName: user.Email,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate totp secret: %w", err)
+	}
+
+	encryptedSecret, err = s.encryptTOTPSecret(key.Secret())
+	if err != nil {
+		return "", "", err
+	}
+
+	return encryptedSecret, key.URL(), nil
+}
+
+// VerifyTOTP valida un código de 6 dígitos contra el secreto TOTP cifrado del usuario
+func (s *Service) VerifyTOTP(user *models.User, code string) error {
+	if user.TOTPSecret == nil {
+		return ErrTOTPNotConfigured
+	}
+
+	secret, err := s.decryptTOTPSecret(*user.TOTPSecret)
+	if err != nil {
+		return err
+	}
+
+	if !totp.Validate(code, secret) {
+		return ErrInvalidTOTPCode
+	}
+
+	return nil
+}
+
+// GenerateMFAChallengeToken genera un JWT de corta duración que solo sirve para completar
+// el inicio de sesión con el código TOTP
+func (s *Service) GenerateMFAChallengeToken(user *models.User) (string, error) {
+	now := time.Now()
+	claims := &Claims{
+		UserID:  user.ID,
+		Email:   user.Email,
+		Purpose: mfaChallengePurpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(mfaChallengeTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    "banca-en-linea",
+			Subject:   user.ID.String(),
+		},
+	}
+
+	token, err := s.signToken(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate mfa challenge token: %w", err)
+	}
+
+	return token, nil
+}
+
+// ValidateMFAChallengeToken valida un token de desafío MFA y retorna sus claims
+func (s *Service) ValidateMFAChallengeToken(tokenString string) (*Claims, error) {
+	claims, err := s.parseToken(tokenString)
+	if err != nil || claims.Purpose != mfaChallengePurpose {
+		return nil, ErrInvalidMFAChallenge
+	}
+	return claims, nil
+}
+
+// totpEncryptionKey deriva la clave AES-256 a partir de TOTP_ENCRYPTION_KEY
+func totpEncryptionKey() []byte {
+	secret := os.Getenv("TOTP_ENCRYPTION_KEY")
+	if secret == "" {
+		// En desarrollo, usar una clave por defecto (NO hacer esto en producción)
+		secret = "your-totp-encryption-key-change-this-in-production"
+	}
+	key := sha256.Sum256([]byte(secret))
+	return key[:]
+}
+
+// encryptTOTPSecret cifra el secreto con AES-GCM; el nonce se antepone al texto cifrado
+func (s *Service) encryptTOTPSecret(secret string) (string, error) {
+	gcm, err := newTOTPCipher()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptTOTPSecret descifra un secreto cifrado con encryptTOTPSecret
+func (s *Service) decryptTOTPSecret(encrypted string) (string, error) {
+	gcm, err := newTOTPCipher()
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode totp secret: %w", err)
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("failed to decrypt totp secret: ciphertext too short")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	secret, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt totp secret: %w", err)
+	}
+
+	return string(secret), nil
+}
+
+// newTOTPCipher crea el cifrador AES-GCM para los secretos TOTP
+func newTOTPCipher() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(totpEncryptionKey())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,70 @@
+package banking
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// AmountScaler convierte montos entre unidades de visualización (por ejemplo 12.34 HNL)
+// y las unidades enteras que se almacenan en TigerBeetle (por ejemplo 1234 centavos)
+type AmountScaler struct {
+	Decimals int
+}
+
+// HNL es el escalador para lempiras (2 decimales, montos en centavos)
+var HNL = AmountScaler{Decimals: 2}
+
+// factor retorna 10^Decimals como número racional
+func (s AmountScaler) factor() *big.Rat {
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(s.Decimals)), nil))
+}
+
+// FromFloat convierte un monto en unidades de visualización a unidades enteras.
+// Retorna error si el monto es negativo, no es finito, tiene más decimales de los
+// permitidos o no cabe en un uint64.
+func (s AmountScaler) FromFloat(f float64) (uint64, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("amount must be a finite number")
+	}
+	if f < 0 {
+		return 0, fmt.Errorf("amount must not be negative")
+	}
+
+	// La representación decimal más corta evita arrastrar el error binario del float64
+	// (0.1 se interpreta como "0.1" y no como 0.1000000000000000055...)
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(f, 'f', -1, 64))
+	if !ok {
+		return 0, fmt.Errorf("invalid amount: %v", f)
+	}
+
+	r.Mul(r, s.factor())
+	if !r.IsInt() {
+		return 0, fmt.Errorf("amount %v has more than %d decimal places", f, s.Decimals)
+	}
+
+	n := r.Num()
+	if !n.IsUint64() {
+		return 0, fmt.Errorf("amount %v overflows uint64", f)
+	}
+
+	return n.Uint64(), nil
+}
+
+// ToFloat convierte un monto en unidades enteras a unidades de visualización
+func (s AmountScaler) ToFloat(u uint64) float64 {
+	r := new(big.Rat).SetFrac(new(big.Int).SetUint64(u), s.factor().Num())
+	f, _ := r.Float64()
+	return f
+}
+
+// MustFromFloat es como FromFloat pero entra en pánico si la conversión falla.
+// Solo debe usarse en tests.
+func (s AmountScaler) MustFromFloat(f float64) uint64 {
+	u, err := s.FromFloat(f)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
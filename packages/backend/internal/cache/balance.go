@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultBalanceTTL es el tiempo que se conserva un balance en caché si no se invalida antes
+const DefaultBalanceTTL = 30 * time.Second
+
+// redisTimeout es el tiempo máximo de una operación contra Redis; si se supera se consulta TigerBeetle
+const redisTimeout = 200 * time.Millisecond
+
+// ErrNoRedisConfigured indica que no hay una URL de Redis configurada en el entorno
+var ErrNoRedisConfigured = errors.New("no redis url configured")
+
+// BalanceCache guarda los balances (en centavos) de las cuentas de TigerBeetle
+type BalanceCache interface {
+	Get(accountID uint64) (int64, bool)
+	Set(accountID uint64, balance int64, ttl time.Duration)
+	Invalidate(accountID uint64)
+}
+
+// RedisBalanceCache implementa BalanceCache sobre Redis. Los errores de Redis se registran y
+// se tratan como un fallo de caché para que el llamador consulte TigerBeetle.
+type RedisBalanceCache struct {
+	client *redis.Client
+}
+
+// NewRedisBalanceCache crea una caché de balances sobre el cliente de Redis indicado
+func NewRedisBalanceCache(client *redis.Client) *RedisBalanceCache {
+	return &RedisBalanceCache{client: client}
+}
+
+// NewRedisClientFromEnv crea un cliente de Redis a partir de REDIS_URL
+// (por ejemplo redis://localhost:6379/0). Retorna ErrNoRedisConfigured si no está definida.
+func NewRedisClientFromEnv() (*redis.Client, error) {
+	url := os.Getenv("REDIS_URL")
+	if url == "" {
+		return nil, ErrNoRedisConfigured
+	}
+
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+
+	return redis.NewClient(opts), nil
+}
+
+// NewRedisBalanceCacheFromEnv crea la caché a partir de REDIS_URL.
+// Retorna ErrNoRedisConfigured si no está definida.
+func NewRedisBalanceCacheFromEnv() (*RedisBalanceCache, error) {
+	client, err := NewRedisClientFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRedisBalanceCache(client), nil
+}
+
+// balanceKey retorna la llave de Redis del balance de una cuenta
+func balanceKey(accountID uint64) string {
+	return "balance:" + strconv.FormatUint(accountID, 10)
+}
+
+// Get obtiene el balance en caché de una cuenta
+func (c *RedisBalanceCache) Get(accountID uint64) (int64, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	balance, err := c.client.Get(ctx, balanceKey(accountID)).Int64()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			log.Printf("Error reading cached balance for account %d: %v", accountID, err)
+		}
+		return 0, false
+	}
+	return balance, true
+}
+
+// Set guarda el balance de una cuenta durante ttl
+func (c *RedisBalanceCache) Set(accountID uint64, balance int64, ttl time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	if err := c.client.Set(ctx, balanceKey(accountID), balance, ttl).Err(); err != nil {
+		log.Printf("Error caching balance for account %d: %v", accountID, err)
+	}
+}
+
+// Invalidate elimina el balance en caché de una cuenta
+func (c *RedisBalanceCache) Invalidate(accountID uint64) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	if err := c.client.Del(ctx, balanceKey(accountID)).Err(); err != nil {
+		log.Printf("Error invalidating cached balance for account %d: %v", accountID, err)
+	}
+}
+
+// Close cierra la conexión con Redis
+func (c *RedisBalanceCache) Close() error {
+	return c.client.Close()
+}
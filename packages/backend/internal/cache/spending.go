@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"banca-en-linea/backend/models"
+)
+
+// DefaultSpendingSummaryTTL es el tiempo que se conserva un resumen de gastos en caché. Calcularlo
+// recorre las transacciones del usuario, por lo que se acepta que quede unos minutos desactualizado.
+const DefaultSpendingSummaryTTL = 5 * time.Minute
+
+// SpendingSummaryCache guarda los resúmenes de gastos de los usuarios por rango de fechas
+type SpendingSummaryCache interface {
+	Get(userID uuid.UUID, from, to time.Time) (*models.SpendingSummary, bool)
+	Set(userID uuid.UUID, from, to time.Time, summary *models.SpendingSummary, ttl time.Duration)
+}
+
+// RedisSpendingSummaryCache implementa SpendingSummaryCache sobre Redis guardando los resúmenes
+// como JSON. Como en RedisBalanceCache, los errores de Redis se tratan como un fallo de caché.
+type RedisSpendingSummaryCache struct {
+	client *redis.Client
+}
+
+// NewRedisSpendingSummaryCache crea una caché de resúmenes de gastos sobre el cliente de Redis indicado
+func NewRedisSpendingSummaryCache(client *redis.Client) *RedisSpendingSummaryCache {
+	return &RedisSpendingSummaryCache{client: client}
+}
+
+// spendingKey retorna la llave de Redis del resumen de gastos de un usuario en un rango
+func spendingKey(userID uuid.UUID, from, to time.Time) string {
+	return "spending:" + userID.String() + ":" + from.UTC().Format(time.RFC3339) + ":" + to.UTC().Format(time.RFC3339)
+}
+
+// Get obtiene el resumen de gastos en caché de un usuario
+func (c *RedisSpendingSummaryCache) Get(userID uuid.UUID, from, to time.Time) (*models.SpendingSummary, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	data, err := c.client.Get(ctx, spendingKey(userID, from, to)).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			log.Printf("Error reading cached spending summary for user %s: %v", userID, err)
+		}
+		return nil, false
+	}
+
+	var summary models.SpendingSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		log.Printf("Error decoding cached spending summary for user %s: %v", userID, err)
+		return nil, false
+	}
+	return &summary, true
+}
+
+// Set guarda el resumen de gastos de un usuario durante ttl
+func (c *RedisSpendingSummaryCache) Set(userID uuid.UUID, from, to time.Time, summary *models.SpendingSummary, ttl time.Duration) {
+	data, err := json.Marshal(summary)
+	if err != nil {
+		log.Printf("Error encoding spending summary for user %s: %v", userID, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+
+	if err := c.client.Set(ctx, spendingKey(userID, from, to), data, ttl).Err(); err != nil {
+		log.Printf("Error caching spending summary for user %s: %v", userID, err)
+	}
+}
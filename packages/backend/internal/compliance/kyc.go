@@ -0,0 +1,30 @@
+package compliance
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strconv"
+)
+
+// defaultKYCThreshold es el monto en centavos a partir del cual se exige KYC aprobado
+const defaultKYCThreshold = 500000
+
+// ErrKYCRequired indica que el monto requiere que la identidad del usuario esté verificada
+var ErrKYCRequired = errors.New("approved kyc required for this amount")
+
+// KYCThresholdFromEnv lee de KYC_REQUIRED_THRESHOLD_CENTS el monto a partir del cual los retiros
+// y transferencias exigen KYC aprobado (500000 por defecto). 0 deshabilita el control.
+func KYCThresholdFromEnv() uint64 {
+	v := os.Getenv("KYC_REQUIRED_THRESHOLD_CENTS")
+	if v == "" {
+		return defaultKYCThreshold
+	}
+
+	threshold, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		log.Printf("Warning: invalid KYC_REQUIRED_THRESHOLD_CENTS %q, using %d", v, defaultKYCThreshold)
+		return defaultKYCThreshold
+	}
+	return threshold
+}
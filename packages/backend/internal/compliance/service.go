@@ -0,0 +1,83 @@
+package compliance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// amlWindow es la ventana móvil en la que se acumulan los montos salientes de un usuario
+const amlWindow = 24 * time.Hour
+
+// ErrBlocked indica que la operación fue bloqueada por los controles de cumplimiento
+var ErrBlocked = errors.New("operation blocked by compliance")
+
+// TransactionTotals obtiene los montos movidos por un usuario
+type TransactionTotals interface {
+	// GetOutgoingTotalSince suma en centavos los montos que salieron de las cuentas del usuario desde since
+	GetOutgoingTotalSince(ctx context.Context, userID uuid.UUID, since time.Time) (uint64, error)
+}
+
+// ComplianceDecision es el resultado de un control de cumplimiento
+type ComplianceDecision struct {
+	Allowed bool
+	Reason  string
+}
+
+// ComplianceService aplica los controles antilavado (AML) a las operaciones salientes
+type ComplianceService struct {
+	totals     TransactionTotals
+	dailyLimit uint64
+}
+
+// NewComplianceService crea una nueva instancia del servicio de cumplimiento. dailyLimit es el
+// monto máximo en centavos que un usuario puede mover en 24 horas.
+func NewComplianceService(totals TransactionTotals, dailyLimit uint64) *ComplianceService {
+	return &ComplianceService{
+		totals:     totals,
+		dailyLimit: dailyLimit,
+	}
+}
+
+// DailyLimitFromEnv lee el límite AML de AML_DAILY_LIMIT_CENTS. Retorna 0 (sin límite) si la
+// variable no está configurada o es inválida.
+func DailyLimitFromEnv() uint64 {
+	v := os.Getenv("AML_DAILY_LIMIT_CENTS")
+	if v == "" {
+		return 0
+	}
+
+	limit, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		log.Printf("Warning: invalid AML_DAILY_LIMIT_CENTS %q, AML check disabled", v)
+		return 0
+	}
+	return limit
+}
+
+// Check decide si el usuario puede sacar el monto indicado: el total saliente de las últimas 24
+// horas más el monto no debe superar el límite diario AML
+func (s *ComplianceService) Check(ctx context.Context, fromUserID uuid.UUID, amount uint64) (ComplianceDecision, error) {
+	if s.dailyLimit == 0 {
+		return ComplianceDecision{Allowed: true}, nil
+	}
+
+	total, err := s.totals.GetOutgoingTotalSince(ctx, fromUserID, time.Now().Add(-amlWindow))
+	if err != nil {
+		return ComplianceDecision{}, fmt.Errorf("error getting rolling total: %w", err)
+	}
+
+	if total+amount > s.dailyLimit {
+		return ComplianceDecision{
+			Reason: fmt.Sprintf("rolling 24h total %d plus amount %d exceeds AML limit %d", total, amount, s.dailyLimit),
+		}, nil
+	}
+
+	return ComplianceDecision{Allowed: true}, nil
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// DefaultJWTSecret es el secreto usado para firmar los JWT si no se configura JWT_SECRET.
+// Solo sirve para desarrollo: en producción siempre debe configurarse JWT_SECRET.
+const DefaultJWTSecret = "your-super-secret-jwt-key-change-this-in-production"
+
+// DefaultReversalWindow es el plazo de reversión de transferencias si no se configura REVERSAL_WINDOW
+const DefaultReversalWindow = 24 * time.Hour
+
+// Config contiene la configuración del servicio leída de las variables de entorno al arrancar.
+// La etiqueta env indica la variable de la que se lee cada campo.
+type Config struct {
+	PostgresHost     string `env:"POSTGRES_HOST" validate:"required"`
+	PostgresPort     string `env:"POSTGRES_PORT" validate:"required,numeric"`
+	PostgresUser     string `env:"POSTGRES_USER" validate:"required"`
+	PostgresPassword string `env:"POSTGRES_PASSWORD" validate:"required"`
+	PostgresDB       string `env:"POSTGRES_DB" validate:"required"`
+	PostgresSSLMode  string `env:"DB_SSLMODE" validate:"required,oneof=disable allow prefer require verify-ca verify-full"`
+
+	JWTSecret string `env:"JWT_SECRET" validate:"required,min=32"`
+
+	TigerBeetleAddress string `env:"TIGERBEETLE_ADDRESS" validate:"required,hostname_port"`
+
+	Port         string `env:"PORT" validate:"required,numeric"`
+	MetricsPort  string `env:"METRICS_PORT" validate:"required,numeric"`
+	OTLPEndpoint string `env:"OTEL_EXPORTER_OTLP_ENDPOINT" validate:"omitempty,url"`
+	SeedData     bool   `env:"SEED_DATA"`
+
+	// ReversalWindow es el plazo, contado desde la transferencia, dentro del cual el remitente puede
+	// revertirla con una disputa aprobada
+	ReversalWindow time.Duration `env:"REVERSAL_WINDOW" validate:"gt=0"`
+
+	// LogLevel en "debug" agrega a los logs de cada solicitud su cuerpo, con los datos personales ocultos
+	LogLevel string `env:"LOG_LEVEL" validate:"required,oneof=debug info warn error"`
+}
+
+// LoadConfig lee la configuración de las variables de entorno, aplicando los valores por defecto
+// de desarrollo, y la valida. Si hay campos inválidos el error los lista todos.
+func LoadConfig() (*Config, error) {
+	seed := os.Getenv("SEED_DATA")
+
+	cfg := &Config{
+		PostgresHost:       getEnv("POSTGRES_HOST", getEnv("DB_HOST", "localhost")),
+		PostgresPort:       getEnv("POSTGRES_PORT", getEnv("DB_PORT", "5432")),
+		PostgresUser:       getEnv("POSTGRES_USER", getEnv("DB_USER", "postgres")),
+		PostgresPassword:   getEnv("POSTGRES_PASSWORD", getEnv("DB_PASSWORD", "postgres")),
+		PostgresDB:         getEnv("POSTGRES_DB", getEnv("DB_NAME", "banca_en_linea")),
+		PostgresSSLMode:    getEnv("DB_SSLMODE", "disable"),
+		JWTSecret:          getEnv("JWT_SECRET", DefaultJWTSecret),
+		TigerBeetleAddress: getEnv("TIGERBEETLE_ADDRESS", "localhost:3000"),
+		Port:               getEnv("PORT", "8080"),
+		MetricsPort:        getEnv("METRICS_PORT", "9090"),
+		OTLPEndpoint:       os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		SeedData:           seed == "true" || seed == "1",
+		ReversalWindow:     getDuration("REVERSAL_WINDOW", DefaultReversalWindow),
+		LogLevel:           strings.ToLower(getEnv("LOG_LEVEL", "info")),
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate verifica los campos de la configuración y retorna un error que nombra la variable de
+// entorno y la regla incumplida de cada campo inválido
+func (c *Config) Validate() error {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return field.Tag.Get("env")
+	})
+
+	err := validate.Struct(c)
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+
+	problems := make([]string, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		problems = append(problems, describe(fieldErr))
+	}
+	return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+}
+
+// describe explica en una frase por qué un campo no cumple su regla de validación
+func describe(fieldErr validator.FieldError) string {
+	name := fieldErr.Field()
+	switch fieldErr.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", name)
+	case "numeric":
+		return fmt.Sprintf("%s must be numeric, got %q", name, fieldErr.Value())
+	case "min":
+		return fmt.Sprintf("%s must be at least %s characters long", name, fieldErr.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of [%s], got %q", name, fieldErr.Param(), fieldErr.Value())
+	case "hostname_port":
+		return fmt.Sprintf("%s must be a host:port address, got %q", name, fieldErr.Value())
+	case "gt":
+		return fmt.Sprintf("%s must be a positive duration", name)
+	case "url":
+		return fmt.Sprintf("%s must be a valid URL, got %q", name, fieldErr.Value())
+	}
+	return fmt.Sprintf("%s is invalid (%s)", name, fieldErr.Tag())
+}
+
+// getEnv obtiene una variable de entorno o devuelve un valor por defecto
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// getDuration obtiene una variable de entorno con una duración (por ejemplo "24h") o devuelve un
+// valor por defecto. Un valor que no es una duración se lee como cero para que Validate lo rechace.
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0
+	}
+	return duration
+}
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"banca-en-linea/backend/models"
+)
+
+// AccountDormancyRepository define la interfaz para marcar las cuentas bancarias sin movimientos
+type AccountDormancyRepository interface {
+	MarkDormant(ctx context.Context, inactiveSince time.Time) ([]*models.BankAccount, error)
+	ClearReactivated(ctx context.Context) (int64, error)
+}
+
+// accountActivityFilter indica que la cuenta de la fila de bank_accounts, o la cuenta principal de
+// su titular, tiene una transacción (no fallida ni cancelada) posterior a la fecha indicada a
+// continuación. Los movimientos de la cuenta principal se registran con la cuenta nula, a nombre
+// del titular o con él como destinatario; las correcciones no son actividad del titular.
+const accountActivityFilter = `EXISTS (
+		    SELECT 1 FROM transactions t
+		    WHERE (t.from_account_id = bank_accounts.id OR t.to_account_id = bank_accounts.id
+		        OR (t.from_account_id IS NULL AND t.created_by = bank_accounts.user_id
+		            AND t.transaction_type <> 'balance_correction')
+		        OR (t.to_account_id IS NULL AND t.recipient_user_id = bank_accounts.user_id))
+		      AND t.status IN ('pending', 'completed')
+		      AND t.created_at >= `
+
+// accountDormancyRepository implementa AccountDormancyRepository
+type accountDormancyRepository struct {
+	db *sql.DB
+}
+
+// NewAccountDormancyRepository crea una nueva instancia del repositorio de cuentas durmientes
+func NewAccountDormancyRepository(db *sql.DB) AccountDormancyRepository {
+	return &accountDormancyRepository{db: db}
+}
+
+// MarkDormant marca como durmientes las cuentas activas abiertas antes de inactiveSince que no
+// tienen transacciones desde esa fecha. Retorna solo las cuentas recién marcadas.
+func (r *accountDormancyRepository) MarkDormant(ctx context.Context, inactiveSince time.Time) ([]*models.BankAccount, error) {
+	query := `
+		UPDATE bank_accounts
+		SET is_dormant = true,
+		    dormant_since = NOW(),
+		    updated_at = NOW()
+		WHERE is_active = true AND is_dormant = false AND created_at < $1
+		  AND NOT ` + accountActivityFilter + `$1)
+		RETURNING ` + bankAccountColumns
+
+	rows, err := r.db.QueryContext(ctx, query, inactiveSince)
+	if err != nil {
+		return nil, fmt.Errorf("error marking dormant accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []*models.BankAccount
+	for rows.Next() {
+		account, err := scanBankAccount(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning bank account: %w", err)
+		}
+		accounts = append(accounts, account)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating dormant accounts: %w", err)
+	}
+
+	return accounts, nil
+}
+
+// ClearReactivated quita la marca de durmiente a las cuentas con transacciones posteriores a la
+// fecha en que se marcaron. Retorna la cantidad de cuentas reactivadas.
+func (r *accountDormancyRepository) ClearReactivated(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE bank_accounts
+		SET is_dormant = false,
+		    dormant_since = NULL,
+		    updated_at = NOW()
+		WHERE is_dormant = true
+		  AND ` + accountActivityFilter + `bank_accounts.dormant_since)`
+
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("error clearing reactivated accounts: %w", err)
+	}
+
+	return result.RowsAffected()
+}
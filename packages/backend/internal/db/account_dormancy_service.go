@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/models"
+)
+
+// dormancyPeriod es el tiempo sin transacciones tras el cual una cuenta se considera durmiente
+const dormancyPeriod = 365 * 24 * time.Hour
+
+// DormancyNotifier avisa al titular que su cuenta pasó a estar durmiente (implementado por
+// email.NotificationService)
+type DormancyNotifier interface {
+	SendDormancyNotice(user *models.User, account *models.BankAccount) error
+}
+
+// AccountDormancyService detecta las cuentas bancarias sin transacciones en el último año, que la
+// regulación considera durmientes, y avisa a sus titulares. Cuentan los depósitos, retiros y
+// transferencias de la cuenta y también los de la cuenta principal del titular: un cliente que
+// opera desde ella no recibe el aviso. Las cuentas principales no están en bank_accounts y no se
+// revisan.
+type AccountDormancyService struct {
+	repo     AccountDormancyRepository
+	userRepo UserRepository
+	notifier DormancyNotifier
+}
+
+// NewAccountDormancyService crea una nueva instancia del servicio de cuentas durmientes
+func NewAccountDormancyService(repo AccountDormancyRepository, userRepo UserRepository, notifier DormancyNotifier) *AccountDormancyService {
+	return &AccountDormancyService{
+		repo:     repo,
+		userRepo: userRepo,
+		notifier: notifier,
+	}
+}
+
+// DetectDormant marca como durmientes las cuentas activas sin transacciones en los últimos 365
+// días y envía un email a cada titular. Antes quita la marca a las cuentas que volvieron a
+// operar. Retorna los IDs de las cuentas recién marcadas.
+func (s *AccountDormancyService) DetectDormant(ctx context.Context) ([]uuid.UUID, error) {
+	reactivated, err := s.repo.ClearReactivated(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := s.repo.MarkDormant(ctx, time.Now().Add(-dormancyPeriod))
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(accounts))
+	for _, account := range accounts {
+		ids = append(ids, account.ID)
+		if err := s.notify(ctx, account); err != nil {
+			log.Printf("Error notifying dormancy of account %s: %v", account.ID, err)
+		}
+	}
+
+	log.Printf("Dormancy check completed: %d accounts marked dormant, %d reactivated", len(ids), reactivated)
+	return ids, nil
+}
+
+// notify envía al titular de la cuenta el aviso de inactividad
+func (s *AccountDormancyService) notify(ctx context.Context, account *models.BankAccount) error {
+	if s.notifier == nil {
+		return nil
+	}
+
+	owner, err := s.userRepo.GetByID(ctx, account.UserID)
+	if err != nil {
+		return fmt.Errorf("error getting account owner: %w", err)
+	}
+
+	return s.notifier.SendDormancyNotice(owner, account)
+}
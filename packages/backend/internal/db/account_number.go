@@ -0,0 +1,73 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/models"
+)
+
+// AccountNumberLength es la cantidad de dígitos de un número de cuenta
+const AccountNumberLength = 12
+
+// accountSequenceModulus limita el contador de secuencia a los 7 dígitos que ocupa en el número
+const accountSequenceModulus = 10_000_000
+
+// ErrInvalidAccountNumber indica que el número de cuenta no tiene el formato esperado
+var ErrInvalidAccountNumber = errors.New("invalid account number")
+
+// accountTypePrefix es el primer dígito del número de cuenta según su tipo
+var accountTypePrefix = map[string]int{
+	models.AccountTypeChecking: 1,
+	models.AccountTypeSavings:  2,
+}
+
+// GenerateAccountNumber construye un número de cuenta de 12 dígitos con el formato
+// T UUU SSSSSSS C, donde T es el prefijo del tipo de cuenta, UUU un fragmento del ID del
+// usuario, SSSSSSS el contador de secuencia del tipo de cuenta y C el dígito verificador Luhn.
+// El resultado es determinista: la unicidad la garantiza el contador por tipo.
+func GenerateAccountNumber(userID uuid.UUID, accountType string, seq int) string {
+	userFragment := (int(userID[0])<<8 | int(userID[1])) % 1000
+	body := fmt.Sprintf("%d%03d%07d", accountTypePrefix[accountType], userFragment, seq%accountSequenceModulus)
+	return body + string(rune('0'+luhnCheckDigit(body)))
+}
+
+// ValidateAccountNumber verifica la longitud, los dígitos y el dígito verificador Luhn
+func ValidateAccountNumber(accountNumber string) error {
+	if len(accountNumber) != AccountNumberLength {
+		return ErrInvalidAccountNumber
+	}
+
+	for _, c := range accountNumber {
+		if c < '0' || c > '9' {
+			return ErrInvalidAccountNumber
+		}
+	}
+
+	body := accountNumber[:AccountNumberLength-1]
+	if int(accountNumber[AccountNumberLength-1]-'0') != luhnCheckDigit(body) {
+		return ErrInvalidAccountNumber
+	}
+
+	return nil
+}
+
+// luhnCheckDigit calcula el dígito verificador Luhn para una cadena de dígitos
+func luhnCheckDigit(digits string) int {
+	sum := 0
+	double := true
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return (10 - sum%10) % 10
+}
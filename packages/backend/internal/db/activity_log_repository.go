@@ -0,0 +1,45 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"banca-en-linea/backend/models"
+)
+
+// ActivityLogRepository define la interfaz para el registro de actividad de los usuarios
+type ActivityLogRepository interface {
+	Record(ctx context.Context, entry *models.ActivityLog) error
+}
+
+// activityLogRepository implementa ActivityLogRepository
+type activityLogRepository struct {
+	db *sql.DB
+}
+
+// NewActivityLogRepository crea una nueva instancia del repositorio de actividad
+func NewActivityLogRepository(db *sql.DB) ActivityLogRepository {
+	return &activityLogRepository{db: db}
+}
+
+// Record registra una llamada de un usuario a la API
+func (r *activityLogRepository) Record(ctx context.Context, entry *models.ActivityLog) error {
+	query := `
+		INSERT INTO user_activity_log (user_id, action, endpoint, method, ip_address, user_agent)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''))`
+
+	_, err := r.db.ExecContext(ctx, query,
+		entry.UserID,
+		entry.Action,
+		entry.Endpoint,
+		entry.Method,
+		entry.IPAddress,
+		entry.UserAgent,
+	)
+	if err != nil {
+		return fmt.Errorf("error recording user activity: %w", err)
+	}
+
+	return nil
+}
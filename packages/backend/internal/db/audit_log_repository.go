@@ -0,0 +1,148 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/models"
+)
+
+// AuditLogRepository define la interfaz para la tabla de auditoría
+type AuditLogRepository interface {
+	Record(ctx context.Context, entry *models.AuditLog) error
+	ListAuthEvents(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*models.AuditLog, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.AuditLog, error)
+}
+
+// auditLogRepository implementa AuditLogRepository
+type auditLogRepository struct {
+	db *sql.DB
+}
+
+// NewAuditLogRepository crea una nueva instancia del repositorio de auditoría
+func NewAuditLogRepository(db *sql.DB) AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+// Record registra un evento de auditoría
+func (r *auditLogRepository) Record(ctx context.Context, entry *models.AuditLog) error {
+	details := entry.Details
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("error serializing audit details: %w", err)
+	}
+
+	query := `
+		INSERT INTO audit_logs (actor_user_id, action, entity_type, entity_id, details, remote_addr,
+		                        amount_cents, status, error_text)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, NULLIF($6, ''), $7, NULLIF($8, ''), NULLIF($9, ''))`
+
+	_, err = r.db.ExecContext(ctx, query,
+		entry.ActorUserID,
+		entry.Action,
+		entry.EntityType,
+		entry.EntityID,
+		detailsJSON,
+		entry.RemoteAddr,
+		entry.AmountCents,
+		entry.Status,
+		entry.ErrorText,
+	)
+	if err != nil {
+		return fmt.Errorf("error recording audit log: %w", err)
+	}
+
+	return nil
+}
+
+// auditLogColumns son las columnas que se leen al cargar un evento (en el orden de scanAuditLog)
+const auditLogColumns = `id, actor_user_id, action, COALESCE(entity_type, ''), COALESCE(entity_id, ''),
+		       details, amount_cents, COALESCE(status, ''), COALESCE(error_text, ''), COALESCE(remote_addr, ''), created_at`
+
+// scanAuditLog lee un evento a partir de una fila que contiene auditLogColumns
+func scanAuditLog(row rowScanner) (*models.AuditLog, error) {
+	entry := &models.AuditLog{}
+	var detailsJSON []byte
+	err := row.Scan(
+		&entry.ID,
+		&entry.ActorUserID,
+		&entry.Action,
+		&entry.EntityType,
+		&entry.EntityID,
+		&detailsJSON,
+		&entry.AmountCents,
+		&entry.Status,
+		&entry.ErrorText,
+		&entry.RemoteAddr,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(detailsJSON, &entry.Details); err != nil {
+		return nil, fmt.Errorf("error parsing audit details: %w", err)
+	}
+	return entry, nil
+}
+
+// ListAuthEvents obtiene los eventos de autenticación de un usuario en el rango indicado
+func (r *auditLogRepository) ListAuthEvents(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*models.AuditLog, error) {
+	query := `
+		SELECT ` + auditLogColumns + `
+		FROM audit_logs
+		WHERE actor_user_id = $1 AND action LIKE 'auth.%'
+		  AND created_at BETWEEN $2 AND $3
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error listing audit logs: %w", err)
+	}
+
+	return scanAuditLogs(rows)
+}
+
+// ListByUser obtiene todos los eventos realizados por el usuario o sobre el usuario, del más
+// reciente al más antiguo
+func (r *auditLogRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.AuditLog, error) {
+	query := `
+		SELECT ` + auditLogColumns + `
+		FROM audit_logs
+		WHERE actor_user_id = $1 OR (entity_type = 'user' AND entity_id = $1::text)
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing audit logs: %w", err)
+	}
+
+	return scanAuditLogs(rows)
+}
+
+// scanAuditLogs lee todas las filas de eventos y cierra rows
+func scanAuditLogs(rows *sql.Rows) ([]*models.AuditLog, error) {
+	defer rows.Close()
+
+	var entries []*models.AuditLog
+	for rows.Next() {
+		entry, err := scanAuditLog(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning audit log: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit logs: %w", err)
+	}
+
+	return entries, nil
+}
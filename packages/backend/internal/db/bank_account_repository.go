@@ -0,0 +1,255 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	apperrors "banca-en-linea/backend/internal/errors"
+	"banca-en-linea/backend/models"
+)
+
+// ErrBankAccountNotFound indica que la cuenta bancaria no existe o fue eliminada
+var ErrBankAccountNotFound = apperrors.ErrBankAccountNotFound
+
+// BankAccountRepository define la interfaz para operaciones de cuentas bancarias en la base de datos
+type BankAccountRepository interface {
+	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.BankAccount, error)
+	GetByAccountNumber(ctx context.Context, accountNumber string) (*models.BankAccount, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.BankAccount, error)
+	Create(ctx context.Context, account *models.BankAccount) (*models.BankAccount, error)
+	Update(ctx context.Context, id uuid.UUID, updates *models.UpdateBankAccountRequest) (*models.BankAccount, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	UpdateOverdraft(ctx context.Context, id uuid.UUID, enabled bool, limitCents int64) (*models.BankAccount, error)
+	NextAccountSequence(ctx context.Context, accountType string) (int, error)
+}
+
+// bankAccountColumns son las columnas que se leen al cargar una cuenta (en el orden de scanBankAccount).
+// El balance mínimo se toma de la configuración del tipo de cuenta.
+const bankAccountColumns = `id, user_id, account_number, account_type, tigerbeetle_account_id, currency,
+		       COALESCE((SELECT c.minimum_balance_cents FROM account_type_config c
+		                 WHERE c.account_type = bank_accounts.account_type), 0),
+		       overdraft_enabled, overdraft_limit_cents, created_at, updated_at, is_active, is_dormant, dormant_since`
+
+// scanBankAccount lee una cuenta bancaria a partir de una fila que contiene bankAccountColumns
+func scanBankAccount(row rowScanner) (*models.BankAccount, error) {
+	account := &models.BankAccount{}
+	err := row.Scan(
+		&account.ID,
+		&account.UserID,
+		&account.AccountNumber,
+		&account.AccountType,
+		&account.TigerBeetleAccountID,
+		&account.Currency,
+		&account.MinimumBalanceCents,
+		&account.OverdraftEnabled,
+		&account.OverdraftLimitCents,
+		&account.CreatedAt,
+		&account.UpdatedAt,
+		&account.IsActive,
+		&account.IsDormant,
+		&account.DormantSince,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// bankAccountRepository implementa BankAccountRepository
+type bankAccountRepository struct {
+	db *sql.DB
+}
+
+// NewBankAccountRepository crea una nueva instancia del repositorio de cuentas bancarias
+func NewBankAccountRepository(db *sql.DB) BankAccountRepository {
+	return &bankAccountRepository{db: db}
+}
+
+// GetByUserID obtiene todas las cuentas bancarias activas de un usuario
+func (r *bankAccountRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.BankAccount, error) {
+	query := `
+		SELECT ` + bankAccountColumns + `
+		FROM bank_accounts
+		WHERE user_id = $1 AND is_active = true
+		ORDER BY created_at`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing bank accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []*models.BankAccount
+	for rows.Next() {
+		account, err := scanBankAccount(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning bank account: %w", err)
+		}
+		accounts = append(accounts, account)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating bank accounts: %w", err)
+	}
+
+	return accounts, nil
+}
+
+// GetByAccountNumber obtiene una cuenta bancaria activa por su número de cuenta
+func (r *bankAccountRepository) GetByAccountNumber(ctx context.Context, accountNumber string) (*models.BankAccount, error) {
+	query := `
+		SELECT ` + bankAccountColumns + `
+		FROM bank_accounts
+		WHERE account_number = $1 AND is_active = true`
+
+	account, err := scanBankAccount(r.db.QueryRowContext(ctx, query, accountNumber))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrBankAccountNotFound
+		}
+		return nil, fmt.Errorf("error getting bank account: %w", err)
+	}
+
+	return account, nil
+}
+
+// GetByID obtiene una cuenta bancaria por su ID
+func (r *bankAccountRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.BankAccount, error) {
+	query := `
+		SELECT ` + bankAccountColumns + `
+		FROM bank_accounts
+		WHERE id = $1`
+
+	account, err := scanBankAccount(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrBankAccountNotFound
+		}
+		return nil, fmt.Errorf("error getting bank account: %w", err)
+	}
+
+	return account, nil
+}
+
+// Create inserta una nueva cuenta bancaria
+func (r *bankAccountRepository) Create(ctx context.Context, account *models.BankAccount) (*models.BankAccount, error) {
+	query := `
+		INSERT INTO bank_accounts (id, user_id, account_number, account_type, tigerbeetle_account_id, currency)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + bankAccountColumns
+
+	created, err := scanBankAccount(r.db.QueryRowContext(ctx, query,
+		account.ID,
+		account.UserID,
+		account.AccountNumber,
+		account.AccountType,
+		account.TigerBeetleAccountID,
+		account.Currency,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("error creating bank account: %w", err)
+	}
+
+	return created, nil
+}
+
+// Update actualiza el tipo y el estado de una cuenta bancaria
+func (r *bankAccountRepository) Update(ctx context.Context, id uuid.UUID, updates *models.UpdateBankAccountRequest) (*models.BankAccount, error) {
+	query := `
+		UPDATE bank_accounts
+		SET account_type = COALESCE($1, account_type),
+		    is_active = COALESCE($2, is_active),
+		    updated_at = NOW()
+		WHERE id = $3
+		RETURNING ` + bankAccountColumns
+
+	account, err := scanBankAccount(r.db.QueryRowContext(ctx, query, updates.AccountType, updates.IsActive, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrBankAccountNotFound
+		}
+		return nil, fmt.Errorf("error updating bank account: %w", err)
+	}
+
+	return account, nil
+}
+
+// UpdateOverdraft activa o desactiva el sobregiro de una cuenta bancaria y fija su límite
+func (r *bankAccountRepository) UpdateOverdraft(ctx context.Context, id uuid.UUID, enabled bool, limitCents int64) (*models.BankAccount, error) {
+	query := `
+		UPDATE bank_accounts
+		SET overdraft_enabled = $1,
+		    overdraft_limit_cents = $2,
+		    updated_at = NOW()
+		WHERE id = $3
+		RETURNING ` + bankAccountColumns
+
+	account, err := scanBankAccount(r.db.QueryRowContext(ctx, query, enabled, limitCents, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrBankAccountNotFound
+		}
+		return nil, fmt.Errorf("error updating bank account overdraft: %w", err)
+	}
+
+	return account, nil
+}
+
+// Delete desactiva una cuenta bancaria. La fila se conserva porque las transacciones la referencian.
+func (r *bankAccountRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE bank_accounts
+		SET is_active = false, updated_at = NOW()
+		WHERE id = $1 AND is_active = true`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("error deleting bank account: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrBankAccountNotFound
+	}
+
+	return nil
+}
+
+// NextAccountSequence reserva el siguiente valor del contador de números de cuenta del tipo indicado.
+// La fila del contador se bloquea con FOR UPDATE para que dos aperturas simultáneas no obtengan el mismo valor.
+func (r *bankAccountRepository) NextAccountSequence(ctx context.Context, accountType string) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	name := "account_number_" + accountType
+
+	var value int
+	err = tx.QueryRowContext(ctx, `SELECT value FROM sequences WHERE name = $1 FOR UPDATE`, name).Scan(&value)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("account sequence %s not found", name)
+		}
+		return 0, fmt.Errorf("error reading account sequence: %w", err)
+	}
+
+	value++
+	if _, err := tx.ExecContext(ctx, `UPDATE sequences SET value = $1 WHERE name = $2`, value, name); err != nil {
+		return 0, fmt.Errorf("error updating account sequence: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,239 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/internal/tigerbeetle"
+	"banca-en-linea/backend/models"
+)
+
+// BankAccountService maneja la lógica de negocio de las cuentas bancarias
+type BankAccountService struct {
+	bankAccountRepo    BankAccountRepository
+	transactionRepo    TransactionRepository
+	tigerBeetleService tigerbeetle.TigerBeetleService
+	transferIDs        TransferIDGenerator
+}
+
+// NewBankAccountService crea una nueva instancia del servicio de cuentas bancarias
+func NewBankAccountService(bankAccountRepo BankAccountRepository, transactionRepo TransactionRepository, tbService tigerbeetle.TigerBeetleService, transferIDs TransferIDGenerator) *BankAccountService {
+	return &BankAccountService{
+		bankAccountRepo:    bankAccountRepo,
+		transactionRepo:    transactionRepo,
+		tigerBeetleService: tbService,
+		transferIDs:        transferIDs,
+	}
+}
+
+// ErrAccountHasBalance indica que la cuenta no se puede cerrar porque aún tiene fondos
+var ErrAccountHasBalance = errors.New("account has non-zero balance")
+
+// ErrOverdraftNotSupported indica que se intentó activar el sobregiro de una cuenta. Las cuentas
+// se crean en TigerBeetle con DebitsMustNotExceedCredits, que rechaza cualquier débito mayor al
+// balance, así que un límite de sobregiro no podría usarse.
+var ErrOverdraftNotSupported = errors.New("overdraft is not supported for ledger accounts")
+
+// ErrUnrecordedMovements indica que TigerBeetle aplicó a la cuenta movimientos que no están
+// registrados en transactions, por lo que el balance esperado no es confiable para corregirla
+var ErrUnrecordedMovements = errors.New("tigerbeetle balance includes movements not recorded in transactions")
+
+// CreateAccount abre una nueva cuenta bancaria para el usuario y crea su cuenta en TigerBeetle
+func (s *BankAccountService) CreateAccount(ctx context.Context, userID uuid.UUID, req *models.CreateBankAccountRequest) (*models.BankAccount, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	currency := req.Currency
+	if currency == "" {
+		currency = models.DefaultCurrency
+	}
+
+	seq, err := s.bankAccountRepo.NextAccountSequence(ctx, req.AccountType)
+	if err != nil {
+		return nil, err
+	}
+
+	accountID := uuid.New()
+	tbAccountID := GenerateTigerBeetleAccountID(accountID)
+	account := &models.BankAccount{
+		ID:                   accountID,
+		UserID:               userID,
+		AccountNumber:        GenerateAccountNumber(userID, req.AccountType, seq),
+		AccountType:          req.AccountType,
+		TigerBeetleAccountID: int64(tbAccountID),
+		Currency:             currency,
+	}
+
+	// 1. Crear la cuenta en TigerBeetle
+	if s.tigerBeetleService != nil {
+		if _, err := s.tigerBeetleService.CreateUserAccount(tbAccountID); err != nil {
+			return nil, fmt.Errorf("error creating TigerBeetle account: %w", err)
+		}
+	}
+
+	// 2. Registrar los metadatos en PostgreSQL
+	created, err := s.bankAccountRepo.Create(ctx, account)
+	if err != nil {
+		log.Printf("TigerBeetle account %d created but bank account not recorded: %v", tbAccountID, err)
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// GetAccount obtiene una cuenta bancaria por su ID
+func (s *BankAccountService) GetAccount(ctx context.Context, accountID uuid.UUID) (*models.BankAccount, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	return s.bankAccountRepo.GetByID(ctx, accountID)
+}
+
+// GetUserAccountsWithBalances obtiene las cuentas bancarias del usuario con sus balances,
+// consultados en TigerBeetle con una sola llamada
+func (s *BankAccountService) GetUserAccountsWithBalances(ctx context.Context, userID uuid.UUID) ([]models.BankAccountWithBalance, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	accounts, err := s.bankAccountRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting bank accounts: %w", err)
+	}
+
+	return withBalances(s.tigerBeetleService, accounts)
+}
+
+// UpdateAccount actualiza el tipo o el estado de una cuenta bancaria
+func (s *BankAccountService) UpdateAccount(ctx context.Context, accountID uuid.UUID, req *models.UpdateBankAccountRequest) (*models.BankAccount, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	return s.bankAccountRepo.Update(ctx, accountID, req)
+}
+
+// UpdateOverdraft configura el sobregiro de una cuenta bancaria. Solo permite desactivarlo:
+// activarlo retorna ErrOverdraftNotSupported.
+func (s *BankAccountService) UpdateOverdraft(ctx context.Context, accountID uuid.UUID, req *models.UpdateOverdraftRequest) (*models.BankAccount, error) {
+	if req.OverdraftEnabled {
+		return nil, ErrOverdraftNotSupported
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	return s.bankAccountRepo.UpdateOverdraft(ctx, accountID, false, 0)
+}
+
+// DeleteAccount cierra una cuenta bancaria. Solo se permite si su balance en TigerBeetle es cero.
+func (s *BankAccountService) DeleteAccount(ctx context.Context, accountID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	account, err := s.bankAccountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	if s.tigerBeetleService != nil {
+		debits, credits, err := s.tigerBeetleService.GetAccountBalance(uint64(account.TigerBeetleAccountID))
+		if err != nil {
+			return fmt.Errorf("error getting tigerbeetle balance: %w", err)
+		}
+		if debits != credits {
+			return ErrAccountHasBalance
+		}
+	}
+
+	return s.bankAccountRepo.Delete(ctx, accountID)
+}
+
+// RecalculateBalance compara el balance de TigerBeetle con el calculado a partir de las
+// transacciones registradas y, si difieren, aplica una transferencia de corrección contra
+// la cuenta de correcciones. Retorna el balance de la cuenta después del ajuste.
+//
+// Si TigerBeetle tiene más débitos o créditos que los registrados, la diferencia viene de
+// movimientos sin registrar y corregirla movería dinero real del cliente: se retorna
+// ErrUnrecordedMovements y la cuenta debe revisarse a mano.
+func (s *BankAccountService) RecalculateBalance(ctx context.Context, accountID uuid.UUID, adminUserID uuid.UUID, reason string) (int64, error) {
+	if s.tigerBeetleService == nil {
+		return 0, fmt.Errorf("tigerbeetle service not configured")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	account, err := s.bankAccountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return 0, err
+	}
+
+	// 1. Balance actual en TigerBeetle
+	tbAccountID := uint64(account.TigerBeetleAccountID)
+	debits, credits, err := s.tigerBeetleService.GetAccountBalance(tbAccountID)
+	if err != nil {
+		return 0, fmt.Errorf("error getting tigerbeetle balance: %w", err)
+	}
+	actual := int64(credits) - int64(debits)
+
+	// 2. Balance esperado según las transacciones registradas
+	expected, err := s.transactionRepo.GetExpectedBalance(ctx, accountID)
+	if err != nil {
+		return 0, err
+	}
+
+	diff := expected - actual
+	if diff == 0 {
+		return actual, nil
+	}
+
+	recordedDebits, recordedCredits, err := s.transactionRepo.GetRecordedMovements(ctx, accountID)
+	if err != nil {
+		return 0, err
+	}
+	if debits > recordedDebits || credits > recordedCredits {
+		log.Printf("Balance of account %s not corrected: tigerbeetle debits %d / credits %d, recorded %d / %d",
+			account.AccountNumber, debits, credits, recordedDebits, recordedCredits)
+		return 0, ErrUnrecordedMovements
+	}
+
+	// 3. Transferencia de corrección desde/hacia la cuenta de correcciones
+	correctionAccountID := uint64(tigerbeetle.CorrectionAccount)
+	transferID, err := s.transferIDs.Next(ctx)
+	if err != nil {
+		return 0, err
+	}
+	correction := &models.Transaction{
+		TigerBeetleTransferID: int64(transferID),
+		Currency:              account.Currency,
+		Description:           reason,
+		TransactionType:       models.TransactionTypeBalanceCorrection,
+		Status:                models.TransactionStatusCompleted,
+		CreatedBy:             &adminUserID,
+	}
+
+	if diff > 0 {
+		err = s.tigerBeetleService.Transfer(correctionAccountID, tbAccountID, uint64(diff), transferID)
+		correction.ToAccountID = &account.ID
+		correction.Amount = diff
+	} else {
+		err = s.tigerBeetleService.Transfer(tbAccountID, correctionAccountID, uint64(-diff), transferID)
+		correction.FromAccountID = &account.ID
+		correction.Amount = -diff
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error applying balance correction: %w", err)
+	}
+
+	// 4. Registrar la corrección con el motivo indicado por el administrador
+	if _, err := s.transactionRepo.Create(ctx, correction); err != nil {
+		log.Printf("Balance correction %d applied in TigerBeetle but not recorded: %v", transferID, err)
+		return 0, fmt.Errorf("error recording balance correction: %w", err)
+	}
+
+	log.Printf("Balance of account %s corrected by %d (admin %s): %s", account.AccountNumber, diff, adminUserID, reason)
+	return expected, nil
+}
@@ -0,0 +1,139 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/models"
+)
+
+var (
+	// ErrBeneficiaryExists indica que el destinatario ya está guardado por el usuario
+	ErrBeneficiaryExists = errors.New("beneficiary already exists")
+	// ErrBeneficiaryNotFound indica que el beneficiario no existe o pertenece a otro usuario
+	ErrBeneficiaryNotFound = errors.New("beneficiary not found")
+)
+
+// BeneficiaryRepository define la interfaz para operaciones de beneficiarios en la base de datos
+type BeneficiaryRepository interface {
+	Create(ctx context.Context, beneficiary *models.Beneficiary) (*models.Beneficiary, error)
+	GetByOwner(ctx context.Context, ownerID uuid.UUID) ([]*models.Beneficiary, error)
+	Delete(ctx context.Context, id, ownerID uuid.UUID) error
+	CountByOwner(ctx context.Context, ownerID uuid.UUID) (int, error)
+}
+
+// beneficiaryColumns son las columnas que se leen al cargar un beneficiario (en el orden de scanBeneficiary)
+const beneficiaryColumns = `id, owner_user_id, recipient_user_id, COALESCE(alias, ''), created_at`
+
+// scanBeneficiary lee un beneficiario a partir de una fila que contiene beneficiaryColumns
+func scanBeneficiary(row rowScanner) (*models.Beneficiary, error) {
+	beneficiary := &models.Beneficiary{}
+	err := row.Scan(
+		&beneficiary.ID,
+		&beneficiary.OwnerUserID,
+		&beneficiary.RecipientUserID,
+		&beneficiary.Alias,
+		&beneficiary.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return beneficiary, nil
+}
+
+// beneficiaryRepository implementa BeneficiaryRepository
+type beneficiaryRepository struct {
+	db *sql.DB
+}
+
+// NewBeneficiaryRepository crea una nueva instancia del repositorio de beneficiarios
+func NewBeneficiaryRepository(db *sql.DB) BeneficiaryRepository {
+	return &beneficiaryRepository{db: db}
+}
+
+// Create guarda un destinatario del usuario. Retorna ErrBeneficiaryExists si ya estaba guardado.
+func (r *beneficiaryRepository) Create(ctx context.Context, beneficiary *models.Beneficiary) (*models.Beneficiary, error) {
+	query := `
+		INSERT INTO beneficiaries (owner_user_id, recipient_user_id, alias)
+		VALUES ($1, $2, NULLIF($3, ''))
+		ON CONFLICT (owner_user_id, recipient_user_id) DO NOTHING
+		RETURNING ` + beneficiaryColumns
+
+	created, err := scanBeneficiary(r.db.QueryRowContext(ctx, query,
+		beneficiary.OwnerUserID,
+		beneficiary.RecipientUserID,
+		beneficiary.Alias,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrBeneficiaryExists
+		}
+		return nil, fmt.Errorf("error creating beneficiary: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetByOwner obtiene los beneficiarios guardados por un usuario, los más recientes primero
+func (r *beneficiaryRepository) GetByOwner(ctx context.Context, ownerID uuid.UUID) ([]*models.Beneficiary, error) {
+	query := `
+		SELECT ` + beneficiaryColumns + `
+		FROM beneficiaries
+		WHERE owner_user_id = $1
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing beneficiaries: %w", err)
+	}
+	defer rows.Close()
+
+	beneficiaries := []*models.Beneficiary{}
+	for rows.Next() {
+		beneficiary, err := scanBeneficiary(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning beneficiary: %w", err)
+		}
+		beneficiaries = append(beneficiaries, beneficiary)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating beneficiaries: %w", err)
+	}
+
+	return beneficiaries, nil
+}
+
+// Delete elimina un beneficiario del usuario
+func (r *beneficiaryRepository) Delete(ctx context.Context, id, ownerID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM beneficiaries WHERE id = $1 AND owner_user_id = $2`, id, ownerID)
+	if err != nil {
+		return fmt.Errorf("error deleting beneficiary: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrBeneficiaryNotFound
+	}
+
+	return nil
+}
+
+// CountByOwner obtiene la cantidad de beneficiarios guardados por un usuario
+func (r *beneficiaryRepository) CountByOwner(ctx context.Context, ownerID uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM beneficiaries WHERE owner_user_id = $1`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, ownerID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error counting beneficiaries: %w", err)
+	}
+
+	return count, nil
+}
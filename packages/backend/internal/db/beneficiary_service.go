@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/models"
+)
+
+var (
+	// ErrRecipientNotFound indica que el destinatario no existe
+	ErrRecipientNotFound = errors.New("recipient not found")
+	// ErrRecipientInactive indica que el destinatario está desactivado y no puede recibir transferencias
+	ErrRecipientInactive = errors.New("recipient is inactive")
+	// ErrSelfBeneficiary indica que el usuario intentó guardarse a sí mismo como beneficiario
+	ErrSelfBeneficiary = errors.New("cannot add yourself as beneficiary")
+)
+
+// BeneficiaryService maneja los destinatarios guardados por los usuarios
+type BeneficiaryService struct {
+	beneficiaryRepo BeneficiaryRepository
+	userRepo        UserRepository
+}
+
+// NewBeneficiaryService crea una nueva instancia del servicio de beneficiarios
+func NewBeneficiaryService(beneficiaryRepo BeneficiaryRepository, userRepo UserRepository) *BeneficiaryService {
+	return &BeneficiaryService{
+		beneficiaryRepo: beneficiaryRepo,
+		userRepo:        userRepo,
+	}
+}
+
+// Add guarda un destinatario del usuario después de verificar que exista y esté activo
+func (s *BeneficiaryService) Add(ctx context.Context, ownerID, recipientID uuid.UUID, alias string) (*models.Beneficiary, error) {
+	if ownerID == recipientID {
+		return nil, ErrSelfBeneficiary
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	recipient, err := s.userRepo.GetByID(ctx, recipientID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrRecipientNotFound
+		}
+		return nil, fmt.Errorf("error getting recipient: %w", err)
+	}
+	if !recipient.IsActive {
+		return nil, ErrRecipientInactive
+	}
+
+	return s.beneficiaryRepo.Create(ctx, &models.Beneficiary{
+		OwnerUserID:     ownerID,
+		RecipientUserID: recipientID,
+		Alias:           strings.TrimSpace(alias),
+	})
+}
+
+// List obtiene los beneficiarios guardados por el usuario
+func (s *BeneficiaryService) List(ctx context.Context, ownerID uuid.UUID) ([]*models.Beneficiary, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	return s.beneficiaryRepo.GetByOwner(ctx, ownerID)
+}
+
+// Remove elimina un beneficiario del usuario
+func (s *BeneficiaryService) Remove(ctx context.Context, ownerID, beneficiaryID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	return s.beneficiaryRepo.Delete(ctx, beneficiaryID, ownerID)
+}
@@ -0,0 +1,164 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/models"
+)
+
+var (
+	// ErrDisputeExists indica que la transacción ya tiene una disputa abierta
+	ErrDisputeExists = errors.New("transaction already has an open dispute")
+	// ErrDisputeNotFound indica que la disputa no existe
+	ErrDisputeNotFound = errors.New("dispute not found")
+	// ErrDisputeNotOpen indica que la disputa ya fue resuelta
+	ErrDisputeNotOpen = errors.New("dispute is not open")
+)
+
+// userTransactionFilter limita las transacciones a las del usuario indicado en $1: las que envió,
+// las que recibió y las de sus cuentas, incluida la principal
+const userTransactionFilter = `(created_by = $1 OR recipient_user_id = $1 OR ` + userTransactionsFilter + `)`
+
+// DisputeRepository define la interfaz para las disputas de transacciones
+type DisputeRepository interface {
+	Create(ctx context.Context, dispute *models.Dispute) (*models.Dispute, error)
+	GetByUser(ctx context.Context, userID uuid.UUID) ([]*models.Dispute, error)
+	Resolve(ctx context.Context, id, resolverID uuid.UUID, status, note string) (*models.Dispute, error)
+	HasApproved(ctx context.Context, transactionID, userID uuid.UUID) (bool, error)
+}
+
+// disputeColumns son las columnas que se leen al cargar una disputa (en el orden de scanDispute)
+const disputeColumns = `id, user_id, transaction_id, reason, status, created_at, resolved_at, resolved_by,
+		       COALESCE(resolution_note, '')`
+
+// scanDispute lee una disputa a partir de una fila que contiene disputeColumns
+func scanDispute(row rowScanner) (*models.Dispute, error) {
+	dispute := &models.Dispute{}
+	err := row.Scan(
+		&dispute.ID,
+		&dispute.UserID,
+		&dispute.TransactionID,
+		&dispute.Reason,
+		&dispute.Status,
+		&dispute.CreatedAt,
+		&dispute.ResolvedAt,
+		&dispute.ResolvedBy,
+		&dispute.ResolutionNote,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return dispute, nil
+}
+
+// disputeRepository implementa DisputeRepository
+type disputeRepository struct {
+	db *sql.DB
+}
+
+// NewDisputeRepository crea una nueva instancia del repositorio de disputas
+func NewDisputeRepository(db *sql.DB) DisputeRepository {
+	return &disputeRepository{db: db}
+}
+
+// Create abre una disputa sobre una transacción del usuario. Retorna ErrTransactionNotFound si la
+// transacción no es suya y ErrDisputeExists si ya tiene una disputa abierta.
+func (r *disputeRepository) Create(ctx context.Context, dispute *models.Dispute) (*models.Dispute, error) {
+	query := `
+		INSERT INTO disputes (user_id, transaction_id, reason)
+		SELECT $1, $2, $3
+		WHERE EXISTS (SELECT 1 FROM transactions WHERE id = $2 AND ` + userTransactionFilter + `)
+		ON CONFLICT (transaction_id) WHERE status = 'open' DO NOTHING
+		RETURNING ` + disputeColumns
+
+	created, err := scanDispute(r.db.QueryRowContext(ctx, query, dispute.UserID, dispute.TransactionID, dispute.Reason))
+	if err == nil {
+		return created, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("error creating dispute: %w", err)
+	}
+
+	var owned bool
+	err = r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM transactions WHERE id = $2 AND `+userTransactionFilter+`)`,
+		dispute.UserID, dispute.TransactionID).Scan(&owned)
+	if err != nil {
+		return nil, fmt.Errorf("error checking transaction: %w", err)
+	}
+	if owned {
+		return nil, ErrDisputeExists
+	}
+	return nil, ErrTransactionNotFound
+}
+
+// GetByUser obtiene las disputas abiertas por un usuario, las más recientes primero
+func (r *disputeRepository) GetByUser(ctx context.Context, userID uuid.UUID) ([]*models.Dispute, error) {
+	query := `
+		SELECT ` + disputeColumns + `
+		FROM disputes
+		WHERE user_id = $1
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing disputes: %w", err)
+	}
+	defer rows.Close()
+
+	disputes := []*models.Dispute{}
+	for rows.Next() {
+		dispute, err := scanDispute(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning dispute: %w", err)
+		}
+		disputes = append(disputes, dispute)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating disputes: %w", err)
+	}
+
+	return disputes, nil
+}
+
+// Resolve cierra una disputa abierta con el estado y la nota indicados
+func (r *disputeRepository) Resolve(ctx context.Context, id, resolverID uuid.UUID, status, note string) (*models.Dispute, error) {
+	query := `
+		UPDATE disputes
+		SET status = $2, resolution_note = NULLIF($3, ''), resolved_by = $4, resolved_at = NOW()
+		WHERE id = $1 AND status = 'open'
+		RETURNING ` + disputeColumns
+
+	dispute, err := scanDispute(r.db.QueryRowContext(ctx, query, id, status, note, resolverID))
+	if err == nil {
+		return dispute, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("error resolving dispute: %w", err)
+	}
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM disputes WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("error checking dispute: %w", err)
+	}
+	if exists {
+		return nil, ErrDisputeNotOpen
+	}
+	return nil, ErrDisputeNotFound
+}
+
+// HasApproved indica si el usuario tiene una disputa aprobada sobre la transacción
+func (r *disputeRepository) HasApproved(ctx context.Context, transactionID, userID uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM disputes WHERE transaction_id = $1 AND user_id = $2 AND status = 'approved')`
+
+	var approved bool
+	if err := r.db.QueryRowContext(ctx, query, transactionID, userID).Scan(&approved); err != nil {
+		return false, fmt.Errorf("error checking dispute: %w", err)
+	}
+	return approved, nil
+}
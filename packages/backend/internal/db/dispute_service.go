@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/models"
+)
+
+var (
+	// ErrInvalidDisputeReason indica un motivo vacío o demasiado largo
+	ErrInvalidDisputeReason = errors.New("invalid dispute reason")
+	// ErrInvalidDisputeResolution indica un estado de resolución distinto de "approved" o "rejected"
+	ErrInvalidDisputeResolution = errors.New("invalid dispute resolution")
+)
+
+// DisputeService registra las disputas de los usuarios sobre sus transacciones y su resolución
+// por el equipo de cumplimiento. Resolver una disputa no mueve dinero: si se aprueba una disputa
+// sobre una transferencia enviada, el remitente puede revertirla con TransactionService.Reverse.
+type DisputeService struct {
+	repo DisputeRepository
+}
+
+// NewDisputeService crea una nueva instancia del servicio de disputas
+func NewDisputeService(repo DisputeRepository) *DisputeService {
+	return &DisputeService{
+		repo: repo,
+	}
+}
+
+// Create abre una disputa del usuario sobre una de sus transacciones
+func (s *DisputeService) Create(ctx context.Context, userID uuid.UUID, req *models.CreateDisputeRequest) (*models.Dispute, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" || utf8.RuneCountInString(reason) > models.MaxDisputeReasonLength {
+		return nil, ErrInvalidDisputeReason
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	dispute, err := s.repo.Create(ctx, &models.Dispute{
+		UserID:        userID,
+		TransactionID: req.TransactionID,
+		Reason:        reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Dispute %s opened by user %s on transaction %s", dispute.ID, userID, req.TransactionID)
+	return dispute, nil
+}
+
+// GetByUser obtiene las disputas abiertas por el usuario
+func (s *DisputeService) GetByUser(ctx context.Context, userID uuid.UUID) ([]*models.Dispute, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	return s.repo.GetByUser(ctx, userID)
+}
+
+// Resolve aprueba o rechaza una disputa abierta. resolverID es el usuario de cumplimiento que la resuelve.
+func (s *DisputeService) Resolve(ctx context.Context, disputeID, resolverID uuid.UUID, req *models.ResolveDisputeRequest) (*models.Dispute, error) {
+	if req.Status != models.DisputeStatusApproved && req.Status != models.DisputeStatusRejected {
+		return nil, ErrInvalidDisputeResolution
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	dispute, err := s.repo.Resolve(ctx, disputeID, resolverID, req.Status, strings.TrimSpace(req.Note))
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Dispute %s %s by user %s", dispute.ID, dispute.Status, resolverID)
+	return dispute, nil
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailVerificationTokenRepository define la interfaz para los tokens de verificación de email
+type EmailVerificationTokenRepository interface {
+	Create(ctx context.Context, tokenHash string, userID uuid.UUID, expiresAt time.Time) error
+	Consume(ctx context.Context, tokenHash string) (uuid.UUID, error)
+}
+
+// emailVerificationTokenRepository implementa EmailVerificationTokenRepository
+type emailVerificationTokenRepository struct {
+	db *sql.DB
+}
+
+// NewEmailVerificationTokenRepository crea una nueva instancia del repositorio de tokens de verificación
+func NewEmailVerificationTokenRepository(db *sql.DB) EmailVerificationTokenRepository {
+	return &emailVerificationTokenRepository{db: db}
+}
+
+// Create guarda el hash de un nuevo token de verificación
+func (r *emailVerificationTokenRepository) Create(ctx context.Context, tokenHash string, userID uuid.UUID, expiresAt time.Time) error {
+	query := `
+		INSERT INTO email_verification_tokens (token_hash, user_id, expires_at)
+		VALUES ($1, $2, $3)`
+
+	if _, err := r.db.ExecContext(ctx, query, tokenHash, userID, expiresAt); err != nil {
+		return fmt.Errorf("error creating email verification token: %w", err)
+	}
+
+	return nil
+}
+
+// Consume marca un token vigente como usado y retorna el usuario al que pertenece.
+// Un token solo puede consumirse una vez.
+func (r *emailVerificationTokenRepository) Consume(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	query := `
+		UPDATE email_verification_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id`
+
+	var userID uuid.UUID
+	if err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(&userID); err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.Nil, fmt.Errorf("email verification token not found")
+		}
+		return uuid.Nil, fmt.Errorf("error consuming email verification token: %w", err)
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/models"
+)
+
+// IdempotencyKeyRepository define la interfaz para las respuestas guardadas por llave de idempotencia
+type IdempotencyKeyRepository interface {
+	Get(ctx context.Context, userID uuid.UUID, keyHash string) (*models.IdempotencyRecord, error)
+	Save(ctx context.Context, record *models.IdempotencyRecord) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// idempotencyKeyRepository implementa IdempotencyKeyRepository
+type idempotencyKeyRepository struct {
+	db *sql.DB
+}
+
+// NewIdempotencyKeyRepository crea una nueva instancia del repositorio de llaves de idempotencia
+func NewIdempotencyKeyRepository(db *sql.DB) IdempotencyKeyRepository {
+	return &idempotencyKeyRepository{db: db}
+}
+
+// Get obtiene la respuesta guardada para la llave del usuario. Retorna nil si no existe o expiró.
+func (r *idempotencyKeyRepository) Get(ctx context.Context, userID uuid.UUID, keyHash string) (*models.IdempotencyRecord, error) {
+	query := `
+		SELECT key_hash, user_id, status_code, content_type, response_body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE user_id = $1 AND key_hash = $2 AND expires_at > NOW()`
+
+	record := &models.IdempotencyRecord{}
+	err := r.db.QueryRowContext(ctx, query, userID, keyHash).Scan(
+		&record.KeyHash,
+		&record.UserID,
+		&record.StatusCode,
+		&record.ContentType,
+		&record.ResponseBody,
+		&record.CreatedAt,
+		&record.ExpiresAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting idempotency key: %w", err)
+	}
+
+	return record, nil
+}
+
+// Save guarda la respuesta de una llave. Si la llave ya existía (una petición concurrente
+// terminó primero) se conserva la respuesta original.
+func (r *idempotencyKeyRepository) Save(ctx context.Context, record *models.IdempotencyRecord) error {
+	query := `
+		INSERT INTO idempotency_keys (key_hash, user_id, status_code, content_type, response_body, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, key_hash) DO UPDATE
+		SET status_code = EXCLUDED.status_code,
+		    content_type = EXCLUDED.content_type,
+		    response_body = EXCLUDED.response_body,
+		    created_at = NOW(),
+		    expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= NOW()`
+
+	_, err := r.db.ExecContext(ctx, query,
+		record.KeyHash,
+		record.UserID,
+		record.StatusCode,
+		record.ContentType,
+		record.ResponseBody,
+		record.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("error saving idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpired elimina las llaves expiradas y retorna cuántas se eliminaron
+func (r *idempotencyKeyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting expired idempotency keys: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error checking affected rows: %w", err)
+	}
+
+	return deleted, nil
+}
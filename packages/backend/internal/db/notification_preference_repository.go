@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/models"
+)
+
+// NotificationPreferenceRepository define la interfaz para las preferencias de notificación
+type NotificationPreferenceRepository interface {
+	Get(ctx context.Context, userID uuid.UUID) (*models.NotificationPreferences, error)
+	Upsert(ctx context.Context, prefs *models.NotificationPreferences) (*models.NotificationPreferences, error)
+}
+
+// notificationPreferenceColumns son las columnas que se leen al cargar preferencias (en el orden de scanNotificationPreferences)
+const notificationPreferenceColumns = `user_id, email_large_tx, email_login, email_statement, sms_large_tx, updated_at`
+
+// scanNotificationPreferences lee las preferencias a partir de una fila que contiene notificationPreferenceColumns
+func scanNotificationPreferences(row rowScanner) (*models.NotificationPreferences, error) {
+	prefs := &models.NotificationPreferences{}
+	err := row.Scan(
+		&prefs.UserID,
+		&prefs.EmailLargeTx,
+		&prefs.EmailLogin,
+		&prefs.EmailStatement,
+		&prefs.SMSLargeTx,
+		&prefs.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// notificationPreferenceRepository implementa NotificationPreferenceRepository
+type notificationPreferenceRepository struct {
+	db *sql.DB
+}
+
+// NewNotificationPreferenceRepository crea una nueva instancia del repositorio de preferencias de notificación
+func NewNotificationPreferenceRepository(db *sql.DB) NotificationPreferenceRepository {
+	return &notificationPreferenceRepository{db: db}
+}
+
+// Get obtiene las preferencias de notificación de un usuario. Si el usuario nunca las guardó
+// se retornan los valores por defecto.
+func (r *notificationPreferenceRepository) Get(ctx context.Context, userID uuid.UUID) (*models.NotificationPreferences, error) {
+	query := `
+		SELECT ` + notificationPreferenceColumns + `
+		FROM notification_preferences
+		WHERE user_id = $1`
+
+	prefs, err := scanNotificationPreferences(r.db.QueryRowContext(ctx, query, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.DefaultNotificationPreferences(userID), nil
+		}
+		return nil, fmt.Errorf("error getting notification preferences: %w", err)
+	}
+
+	return prefs, nil
+}
+
+// Upsert crea o reemplaza las preferencias de notificación de un usuario
+func (r *notificationPreferenceRepository) Upsert(ctx context.Context, prefs *models.NotificationPreferences) (*models.NotificationPreferences, error) {
+	query := `
+		INSERT INTO notification_preferences (user_id, email_large_tx, email_login, email_statement, sms_large_tx, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			email_large_tx = EXCLUDED.email_large_tx,
+			email_login = EXCLUDED.email_login,
+			email_statement = EXCLUDED.email_statement,
+			sms_large_tx = EXCLUDED.sms_large_tx,
+			updated_at = NOW()
+		RETURNING ` + notificationPreferenceColumns
+
+	saved, err := scanNotificationPreferences(r.db.QueryRowContext(
+		ctx,
+		query,
+		prefs.UserID,
+		prefs.EmailLargeTx,
+		prefs.EmailLogin,
+		prefs.EmailStatement,
+		prefs.SMSLargeTx,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("error saving notification preferences: %w", err)
+	}
+
+	return saved, nil
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/models"
+)
+
+// NotificationRepository define la interfaz para las notificaciones enviadas a los usuarios
+type NotificationRepository interface {
+	ListByUser(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*models.Notification, error)
+}
+
+// notificationRepository implementa NotificationRepository
+type notificationRepository struct {
+	db *sql.DB
+}
+
+// NewNotificationRepository crea una nueva instancia del repositorio de notificaciones
+func NewNotificationRepository(db *sql.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// ListByUser obtiene las notificaciones enviadas a un usuario en el rango indicado
+func (r *notificationRepository) ListByUser(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*models.Notification, error) {
+	query := `
+		SELECT id, user_id, channel, notification_type, COALESCE(subject, ''), created_at
+		FROM notifications
+		WHERE user_id = $1 AND created_at BETWEEN $2 AND $3
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error listing notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []*models.Notification
+	for rows.Next() {
+		n := &models.Notification{}
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Channel, &n.NotificationType, &n.Subject, &n.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning notification: %w", err)
+		}
+		notifications = append(notifications, n)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notifications: %w", err)
+	}
+
+	return notifications, nil
+}
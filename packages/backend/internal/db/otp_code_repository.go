@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/models"
+)
+
+// ErrOTPCodeNotFound indica que el desafío no existe, pertenece a otro usuario, ya fue usado o venció
+var ErrOTPCodeNotFound = errors.New("otp code not found")
+
+// OTPCodeRepository define la interfaz para los códigos OTP en la base de datos
+type OTPCodeRepository interface {
+	Create(ctx context.Context, code *models.OTPCode) (*models.OTPCode, error)
+	GetActive(ctx context.Context, id, userID uuid.UUID) (*models.OTPCode, error)
+	IncrementAttempts(ctx context.Context, id uuid.UUID) error
+	Consume(ctx context.Context, id uuid.UUID) error
+}
+
+// otpCodeColumns son las columnas que se leen al cargar un código OTP (en el orden de scanOTPCode)
+const otpCodeColumns = `id, user_id, code_hash, operation_hash, attempts, expires_at, used_at, created_at`
+
+// scanOTPCode lee un código OTP a partir de una fila que contiene otpCodeColumns
+func scanOTPCode(row rowScanner) (*models.OTPCode, error) {
+	code := &models.OTPCode{}
+	err := row.Scan(
+		&code.ID,
+		&code.UserID,
+		&code.CodeHash,
+		&code.OperationHash,
+		&code.Attempts,
+		&code.ExpiresAt,
+		&code.UsedAt,
+		&code.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return code, nil
+}
+
+// otpCodeRepository implementa OTPCodeRepository
+type otpCodeRepository struct {
+	db *sql.DB
+}
+
+// NewOTPCodeRepository crea una nueva instancia del repositorio de códigos OTP
+func NewOTPCodeRepository(db *sql.DB) OTPCodeRepository {
+	return &otpCodeRepository{db: db}
+}
+
+// Create guarda el hash de un nuevo código OTP
+func (r *otpCodeRepository) Create(ctx context.Context, code *models.OTPCode) (*models.OTPCode, error) {
+	query := `
+		INSERT INTO otp_codes (user_id, code_hash, operation_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + otpCodeColumns
+
+	created, err := scanOTPCode(r.db.QueryRowContext(ctx, query, code.UserID, code.CodeHash, code.OperationHash, code.ExpiresAt))
+	if err != nil {
+		return nil, fmt.Errorf("error creating otp code: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetActive obtiene un código del usuario que no ha sido usado ni ha vencido
+func (r *otpCodeRepository) GetActive(ctx context.Context, id, userID uuid.UUID) (*models.OTPCode, error) {
+	query := `
+		SELECT ` + otpCodeColumns + `
+		FROM otp_codes
+		WHERE id = $1 AND user_id = $2 AND used_at IS NULL AND expires_at > NOW()`
+
+	code, err := scanOTPCode(r.db.QueryRowContext(ctx, query, id, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrOTPCodeNotFound
+		}
+		return nil, fmt.Errorf("error getting otp code: %w", err)
+	}
+
+	return code, nil
+}
+
+// IncrementAttempts registra un intento fallido de verificación
+func (r *otpCodeRepository) IncrementAttempts(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE otp_codes SET attempts = attempts + 1 WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("error incrementing otp attempts: %w", err)
+	}
+	return nil
+}
+
+// Consume marca un código vigente como usado. Un código solo puede consumirse una vez.
+func (r *otpCodeRepository) Consume(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE otp_codes
+		SET used_at = NOW()
+		WHERE id = $1 AND used_at IS NULL AND expires_at > NOW()`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("error consuming otp code: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrOTPCodeNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/models"
+)
+
+const (
+	// OTPTTL es la vigencia de los códigos OTP
+	OTPTTL = 5 * time.Minute
+	// otpMaxAttempts es la cantidad de intentos fallidos tras la cual el código deja de aceptarse
+	otpMaxAttempts = 5
+)
+
+// ErrInvalidOTP indica que el código OTP es incorrecto, ya fue usado, venció o agotó sus intentos
+var ErrInvalidOTP = errors.New("invalid otp code")
+
+// OTPNotifier envía el código OTP al usuario (implementado por email.Service)
+type OTPNotifier interface {
+	SendOTPEmail(user *models.User, code string, ttl time.Duration) error
+}
+
+// OTPService genera y verifica los códigos de un solo uso que confirman operaciones sensibles
+type OTPService struct {
+	otpRepo  OTPCodeRepository
+	userRepo UserRepository
+	notifier OTPNotifier
+}
+
+// NewOTPService crea una nueva instancia del servicio de códigos OTP
+func NewOTPService(otpRepo OTPCodeRepository, userRepo UserRepository, notifier OTPNotifier) *OTPService {
+	return &OTPService{
+		otpRepo:  otpRepo,
+		userRepo: userRepo,
+		notifier: notifier,
+	}
+}
+
+// Generate crea un código de 6 dígitos con vigencia de OTPTTL, lo envía al usuario y retorna el
+// token del desafío con el que el cliente debe presentar el código. El código solo confirma la
+// operación indicada (por ejemplo el destino y el monto de una transferencia).
+func (s *OTPService) Generate(ctx context.Context, userID uuid.UUID, operation string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("error getting user: %w", err)
+	}
+
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", fmt.Errorf("error generating otp code: %w", err)
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+
+	created, err := s.otpRepo.Create(ctx, &models.OTPCode{
+		UserID:        userID,
+		CodeHash:      hashOTPValue(code),
+		OperationHash: hashOTPValue(operation),
+		ExpiresAt:     time.Now().Add(OTPTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.notifier.SendOTPEmail(user, code, OTPTTL); err != nil {
+		return "", fmt.Errorf("error sending otp code: %w", err)
+	}
+
+	return created.ID.String(), nil
+}
+
+// Verify comprueba el código presentado para el desafío del usuario y lo consume. El desafío debe
+// haberse generado para la misma operación; si se presenta para otra, cuenta como intento fallido.
+// Cada desafío admite otpMaxAttempts intentos fallidos.
+func (s *OTPService) Verify(ctx context.Context, userID uuid.UUID, challengeToken, code, operation string) error {
+	challengeID, err := uuid.Parse(challengeToken)
+	if err != nil {
+		return ErrInvalidOTP
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	stored, err := s.otpRepo.GetActive(ctx, challengeID, userID)
+	if err != nil {
+		if errors.Is(err, ErrOTPCodeNotFound) {
+			return ErrInvalidOTP
+		}
+		return err
+	}
+
+	if stored.Attempts >= otpMaxAttempts {
+		return ErrInvalidOTP
+	}
+
+	codeMatches := subtle.ConstantTimeCompare([]byte(hashOTPValue(code)), []byte(stored.CodeHash)) == 1
+	operationMatches := subtle.ConstantTimeCompare([]byte(hashOTPValue(operation)), []byte(stored.OperationHash)) == 1
+	if !codeMatches || !operationMatches {
+		if err := s.otpRepo.IncrementAttempts(ctx, stored.ID); err != nil {
+			return err
+		}
+		return ErrInvalidOTP
+	}
+
+	if err := s.otpRepo.Consume(ctx, stored.ID); err != nil {
+		if errors.Is(err, ErrOTPCodeNotFound) {
+			return ErrInvalidOTP
+		}
+		return err
+	}
+
+	return nil
+}
+
+// hashOTPValue retorna el hash SHA-256 en hexadecimal de un código OTP o de su operación
+func hashOTPValue(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
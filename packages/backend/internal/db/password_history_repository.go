@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// PasswordHistorySize es la cantidad de contraseñas anteriores que no pueden reutilizarse
+const PasswordHistorySize = 5
+
+// PasswordHistoryRepository define la interfaz para el historial de contraseñas
+type PasswordHistoryRepository interface {
+	Add(ctx context.Context, userID uuid.UUID, passwordHash string) error
+	ListRecent(ctx context.Context, userID uuid.UUID) ([]string, error)
+}
+
+// passwordHistoryRepository implementa PasswordHistoryRepository
+type passwordHistoryRepository struct {
+	db *sql.DB
+}
+
+// NewPasswordHistoryRepository crea una nueva instancia del repositorio de historial de contraseñas
+func NewPasswordHistoryRepository(db *sql.DB) PasswordHistoryRepository {
+	return &passwordHistoryRepository{db: db}
+}
+
+// Add registra un hash de contraseña y elimina los que exceden PasswordHistorySize
+func (r *passwordHistoryRepository) Add(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	insert := `
+		INSERT INTO password_history (user_id, password_hash)
+		VALUES ($1, $2)`
+
+	if _, err := tx.ExecContext(ctx, insert, userID, passwordHash); err != nil {
+		return fmt.Errorf("error adding password history: %w", err)
+	}
+
+	prune := `
+		DELETE FROM password_history
+		WHERE user_id = $1 AND id NOT IN (
+			SELECT id FROM password_history
+			WHERE user_id = $1
+			ORDER BY created_at DESC
+			LIMIT $2
+		)`
+
+	if _, err := tx.ExecContext(ctx, prune, userID, PasswordHistorySize); err != nil {
+		return fmt.Errorf("error pruning password history: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ListRecent obtiene los últimos PasswordHistorySize hashes de contraseña del usuario
+func (r *passwordHistoryRepository) ListRecent(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	query := `
+		SELECT password_hash
+		FROM password_history
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, PasswordHistorySize)
+	if err != nil {
+		return nil, fmt.Errorf("error listing password history: %w", err)
+	}
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, fmt.Errorf("error scanning password history: %w", err)
+		}
+		hashes = append(hashes, hash)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating password history: %w", err)
+	}
+
+	return hashes, nil
+}
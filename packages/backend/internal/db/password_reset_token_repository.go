@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PasswordResetTokenRepository define la interfaz para los tokens de restablecimiento de contraseña
+type PasswordResetTokenRepository interface {
+	Create(ctx context.Context, tokenHash string, userID uuid.UUID, expiresAt time.Time) error
+	Consume(ctx context.Context, tokenHash string) (uuid.UUID, error)
+}
+
+// passwordResetTokenRepository implementa PasswordResetTokenRepository
+type passwordResetTokenRepository struct {
+	db *sql.DB
+}
+
+// NewPasswordResetTokenRepository crea una nueva instancia del repositorio de tokens de restablecimiento de contraseña
+func NewPasswordResetTokenRepository(db *sql.DB) PasswordResetTokenRepository {
+	return &passwordResetTokenRepository{db: db}
+}
+
+// Create guarda el hash de un nuevo token de restablecimiento
+func (r *passwordResetTokenRepository) Create(ctx context.Context, tokenHash string, userID uuid.UUID, expiresAt time.Time) error {
+	query := `
+		INSERT INTO password_reset_tokens (token_hash, user_id, expires_at)
+		VALUES ($1, $2, $3)`
+
+	if _, err := r.db.ExecContext(ctx, query, tokenHash, userID, expiresAt); err != nil {
+		return fmt.Errorf("error creating password reset token: %w", err)
+	}
+
+	return nil
+}
+
+// Consume marca un token vigente como usado y retorna el usuario al que pertenece.
+// Un token solo puede consumirse una vez.
+func (r *passwordResetTokenRepository) Consume(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	query := `
+		UPDATE password_reset_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id`
+
+	var userID uuid.UUID
+	if err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(&userID); err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.Nil, fmt.Errorf("password reset token not found")
+		}
+		return uuid.Nil, fmt.Errorf("error consuming password reset token: %w", err)
+	}
+
+	return userID, nil
+}
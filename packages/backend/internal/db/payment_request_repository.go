@@ -0,0 +1,214 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/models"
+)
+
+var (
+	// ErrPaymentRequestNotFound indica que la solicitud de pago no existe o no corresponde al usuario
+	ErrPaymentRequestNotFound = errors.New("payment request not found")
+	// ErrPaymentRequestNotPending indica que la solicitud ya se pagó o se rechazó
+	ErrPaymentRequestNotPending = errors.New("payment request is not pending")
+	// ErrPaymentRequestExpired indica que la solicitud venció antes de aprobarse
+	ErrPaymentRequestExpired = errors.New("payment request has expired")
+)
+
+// PaymentRequestRepository define la interfaz para las solicitudes de pago
+type PaymentRequestRepository interface {
+	Create(ctx context.Context, request *models.PaymentRequest) (*models.PaymentRequest, error)
+	GetByID(ctx context.Context, id, payerID uuid.UUID) (*models.PaymentRequest, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.PaymentRequest, error)
+	Reject(ctx context.Context, id, payerID uuid.UUID) (*models.PaymentRequest, error)
+	Fulfill(ctx context.Context, id, payerID uuid.UUID, pay func(ctx context.Context, request *models.PaymentRequest) error) (*models.PaymentRequest, error)
+}
+
+// paymentRequestColumns son las columnas que se leen al cargar una solicitud de pago
+const paymentRequestColumns = `id, requester_user_id, payer_user_id, amount_cents, COALESCE(description, ''),
+		       status, expires_at, responded_at, created_at`
+
+// scanPaymentRequest lee una solicitud de pago a partir de una fila que contiene paymentRequestColumns
+func scanPaymentRequest(row rowScanner) (*models.PaymentRequest, error) {
+	request := &models.PaymentRequest{}
+	err := row.Scan(
+		&request.ID,
+		&request.RequesterUserID,
+		&request.PayerUserID,
+		&request.AmountCents,
+		&request.Description,
+		&request.Status,
+		&request.ExpiresAt,
+		&request.RespondedAt,
+		&request.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// paymentRequestRepository implementa PaymentRequestRepository
+type paymentRequestRepository struct {
+	db *sql.DB
+}
+
+// NewPaymentRequestRepository crea una nueva instancia del repositorio de solicitudes de pago
+func NewPaymentRequestRepository(db *sql.DB) PaymentRequestRepository {
+	return &paymentRequestRepository{db: db}
+}
+
+// Create registra una solicitud de pago pendiente
+func (r *paymentRequestRepository) Create(ctx context.Context, request *models.PaymentRequest) (*models.PaymentRequest, error) {
+	query := `
+		INSERT INTO payment_requests (requester_user_id, payer_user_id, amount_cents, description, expires_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+		RETURNING ` + paymentRequestColumns
+
+	created, err := scanPaymentRequest(r.db.QueryRowContext(ctx, query,
+		request.RequesterUserID,
+		request.PayerUserID,
+		request.AmountCents,
+		request.Description,
+		request.ExpiresAt,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("error creating payment request: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetByID obtiene una solicitud de pago dirigida al pagador indicado. Las solicitudes dirigidas
+// a otros usuarios se reportan como inexistentes.
+func (r *paymentRequestRepository) GetByID(ctx context.Context, id, payerID uuid.UUID) (*models.PaymentRequest, error) {
+	query := `
+		SELECT ` + paymentRequestColumns + `
+		FROM payment_requests
+		WHERE id = $1 AND payer_user_id = $2`
+
+	request, err := scanPaymentRequest(r.db.QueryRowContext(ctx, query, id, payerID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrPaymentRequestNotFound
+		}
+		return nil, fmt.Errorf("error getting payment request: %w", err)
+	}
+
+	return request, nil
+}
+
+// ListByUser obtiene las solicitudes de pago enviadas y recibidas por un usuario, las más recientes primero
+func (r *paymentRequestRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.PaymentRequest, error) {
+	query := `
+		SELECT ` + paymentRequestColumns + `
+		FROM payment_requests
+		WHERE requester_user_id = $1 OR payer_user_id = $1
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing payment requests: %w", err)
+	}
+	defer rows.Close()
+
+	requests := []*models.PaymentRequest{}
+	for rows.Next() {
+		request, err := scanPaymentRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning payment request: %w", err)
+		}
+		requests = append(requests, request)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating payment requests: %w", err)
+	}
+
+	return requests, nil
+}
+
+// Reject rechaza una solicitud pendiente dirigida al pagador indicado. Las solicitudes dirigidas
+// a otros usuarios se reportan como inexistentes.
+func (r *paymentRequestRepository) Reject(ctx context.Context, id, payerID uuid.UUID) (*models.PaymentRequest, error) {
+	query := `
+		UPDATE payment_requests
+		SET status = 'rejected', responded_at = NOW()
+		WHERE id = $1 AND payer_user_id = $2 AND status = 'pending'
+		RETURNING ` + paymentRequestColumns
+
+	request, err := scanPaymentRequest(r.db.QueryRowContext(ctx, query, id, payerID))
+	if err == nil {
+		return request, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("error rejecting payment request: %w", err)
+	}
+
+	var exists bool
+	err = r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM payment_requests WHERE id = $1 AND payer_user_id = $2)`, id, payerID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("error checking payment request: %w", err)
+	}
+	if exists {
+		return nil, ErrPaymentRequestNotPending
+	}
+	return nil, ErrPaymentRequestNotFound
+}
+
+// Fulfill bloquea una solicitud pendiente y vigente dirigida al pagador indicado, la paga con pay
+// y la marca como pagada en la misma transacción. Si pay falla la solicitud sigue pendiente. La
+// fila se toma con FOR UPDATE para que dos aprobaciones simultáneas no paguen dos veces.
+func (r *paymentRequestRepository) Fulfill(ctx context.Context, id, payerID uuid.UUID, pay func(ctx context.Context, request *models.PaymentRequest) error) (*models.PaymentRequest, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT ` + paymentRequestColumns + `
+		FROM payment_requests
+		WHERE id = $1 AND payer_user_id = $2
+		FOR UPDATE`
+
+	request, err := scanPaymentRequest(tx.QueryRowContext(ctx, query, id, payerID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrPaymentRequestNotFound
+		}
+		return nil, fmt.Errorf("error getting payment request: %w", err)
+	}
+
+	if request.Status != models.PaymentRequestStatusPending {
+		return nil, ErrPaymentRequestNotPending
+	}
+	if !request.ExpiresAt.After(time.Now()) {
+		return nil, ErrPaymentRequestExpired
+	}
+
+	if err := pay(ctx, request); err != nil {
+		return nil, err
+	}
+
+	fulfilled, err := scanPaymentRequest(tx.QueryRowContext(ctx, `
+		UPDATE payment_requests
+		SET status = 'fulfilled', responded_at = NOW()
+		WHERE id = $1
+		RETURNING `+paymentRequestColumns, id))
+	if err != nil {
+		return nil, fmt.Errorf("error fulfilling payment request: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing payment request: %w", err)
+	}
+
+	return fulfilled, nil
+}
@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/internal/idempotency"
+	"banca-en-linea/backend/models"
+)
+
+// paymentRequestTTL es el tiempo que una solicitud de pago puede aprobarse antes de vencer
+const paymentRequestTTL = 7 * 24 * time.Hour
+
+var (
+	// ErrSelfPaymentRequest indica que el usuario intentó pedirse dinero a sí mismo
+	ErrSelfPaymentRequest = errors.New("cannot request payment from yourself")
+	// ErrInvalidPaymentRequest indica un monto en cero o una descripción demasiado larga
+	ErrInvalidPaymentRequest = errors.New("invalid payment request")
+)
+
+// PaymentRequestService permite a un usuario pedir dinero a otro, que decide si paga o rechaza
+type PaymentRequestService struct {
+	repo        PaymentRequestRepository
+	userRepo    UserRepository
+	userService *UserService
+}
+
+// NewPaymentRequestService crea una nueva instancia del servicio de solicitudes de pago
+func NewPaymentRequestService(repo PaymentRequestRepository, userRepo UserRepository, userService *UserService) *PaymentRequestService {
+	return &PaymentRequestService{
+		repo:        repo,
+		userRepo:    userRepo,
+		userService: userService,
+	}
+}
+
+// Create registra una solicitud de pago dirigida a un usuario activo. La solicitud vence a los
+// siete días si el pagador no responde.
+func (s *PaymentRequestService) Create(ctx context.Context, req *models.CreatePaymentRequestRequest) (*models.PaymentRequest, error) {
+	if req.RequesterUserID == req.PayerUserID {
+		return nil, ErrSelfPaymentRequest
+	}
+
+	description := strings.TrimSpace(req.Description)
+	if req.Amount == 0 || utf8.RuneCountInString(description) > models.MaxPaymentRequestDescriptionLength {
+		return nil, ErrInvalidPaymentRequest
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	payer, err := s.userRepo.GetByID(ctx, req.PayerUserID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrRecipientNotFound
+		}
+		return nil, fmt.Errorf("error getting payer: %w", err)
+	}
+	if !payer.IsActive {
+		return nil, ErrRecipientInactive
+	}
+
+	return s.repo.Create(ctx, &models.PaymentRequest{
+		RequesterUserID: req.RequesterUserID,
+		PayerUserID:     req.PayerUserID,
+		AmountCents:     int64(req.Amount),
+		Description:     description,
+		ExpiresAt:       time.Now().Add(paymentRequestTTL),
+	})
+}
+
+// List obtiene las solicitudes de pago enviadas y recibidas por el usuario
+func (s *PaymentRequestService) List(ctx context.Context, userID uuid.UUID) ([]*models.PaymentRequest, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	return s.repo.ListByUser(ctx, userID)
+}
+
+// GetForPayer obtiene una solicitud de pago dirigida al usuario
+func (s *PaymentRequestService) GetForPayer(ctx context.Context, id, payerID uuid.UUID) (*models.PaymentRequest, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	return s.repo.GetByID(ctx, id, payerID)
+}
+
+// Approve paga una solicitud pendiente dirigida al usuario con una transferencia al solicitante
+// y la marca como pagada.
+//
+// Los IDs de TigerBeetle se derivan del pagador y del ID de la solicitud: si una aprobación
+// anterior aplicó la transferencia pero no llegó a marcar la solicitud, UserService la trata como
+// ya aplicada y la solicitud se considera pagada.
+func (s *PaymentRequestService) Approve(ctx context.Context, id, payerID uuid.UUID) (*models.PaymentRequest, error) {
+	return s.repo.Fulfill(ctx, id, payerID, func(ctx context.Context, request *models.PaymentRequest) error {
+		ctx = idempotency.WithKey(ctx, request.PayerUserID, idempotency.HashKey("payment-request:"+request.ID.String()))
+
+		_, err := s.userService.TransferBetweenUsers(ctx, request.PayerUserID, request.RequesterUserID, uint64(request.AmountCents))
+		return err
+	})
+}
+
+// Reject rechaza una solicitud pendiente dirigida al usuario
+func (s *PaymentRequestService) Reject(ctx context.Context, id, payerID uuid.UUID) (*models.PaymentRequest, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	return s.repo.Reject(ctx, id, payerID)
+}
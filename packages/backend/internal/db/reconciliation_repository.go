@@ -0,0 +1,47 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"banca-en-linea/backend/models"
+)
+
+// ReconciliationRepository define la interfaz para el reporte de conciliación de balances
+type ReconciliationRepository interface {
+	Record(ctx context.Context, mismatch *models.ReconciliationMismatch) error
+}
+
+// reconciliationRepository implementa ReconciliationRepository
+type reconciliationRepository struct {
+	db *sql.DB
+}
+
+// NewReconciliationRepository crea una nueva instancia del repositorio de conciliación
+func NewReconciliationRepository(db *sql.DB) ReconciliationRepository {
+	return &reconciliationRepository{db: db}
+}
+
+// Record registra una diferencia encontrada durante la conciliación
+func (r *reconciliationRepository) Record(ctx context.Context, mismatch *models.ReconciliationMismatch) error {
+	query := `
+		INSERT INTO reconciliation_report (run_id, user_id, tigerbeetle_account_id, tigerbeetle_balance_cents,
+		                                   expected_balance_cents, difference_cents)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+
+	err := r.db.QueryRowContext(ctx, query,
+		mismatch.RunID,
+		mismatch.UserID,
+		mismatch.TigerBeetleAccountID,
+		mismatch.TigerBeetleBalance,
+		mismatch.ExpectedBalance,
+		mismatch.Difference,
+	).Scan(&mismatch.ID, &mismatch.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error recording reconciliation mismatch: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/internal/tigerbeetle"
+	"banca-en-linea/backend/models"
+)
+
+// reconciliationPageSize es la cantidad de usuarios leídos por página durante la conciliación
+const reconciliationPageSize = 100
+
+// ReconciliationService compara el balance de TigerBeetle de la cuenta principal de cada usuario
+// con el calculado a partir de las transacciones registradas en PostgreSQL
+type ReconciliationService struct {
+	userRepo           UserRepository
+	transactionRepo    TransactionRepository
+	reconciliationRepo ReconciliationRepository
+	tigerBeetleService tigerbeetle.TigerBeetleService
+}
+
+// NewReconciliationService crea una nueva instancia del servicio de conciliación
+func NewReconciliationService(userRepo UserRepository, transactionRepo TransactionRepository, reconciliationRepo ReconciliationRepository, tbService tigerbeetle.TigerBeetleService) *ReconciliationService {
+	return &ReconciliationService{
+		userRepo:           userRepo,
+		transactionRepo:    transactionRepo,
+		reconciliationRepo: reconciliationRepo,
+		tigerBeetleService: tbService,
+	}
+}
+
+// Reconcile recorre todos los usuarios activos con cuenta en TigerBeetle, compara su balance con
+// el esperado según sus transacciones y registra cada diferencia en el reporte de conciliación.
+// Retorna las diferencias encontradas en esta ejecución.
+func (s *ReconciliationService) Reconcile(ctx context.Context) ([]models.ReconciliationMismatch, error) {
+	if s.tigerBeetleService == nil {
+		return nil, fmt.Errorf("tigerbeetle service not configured")
+	}
+
+	runID := uuid.New()
+	mismatches := []models.ReconciliationMismatch{}
+
+	var afterID *uuid.UUID
+	for {
+		users, err := s.listUsers(ctx, afterID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, user := range users {
+			if !user.IsActive || user.TigerBeetleAccountID == nil {
+				continue
+			}
+
+			mismatch, err := s.reconcileUser(ctx, runID, user)
+			if err != nil {
+				return nil, err
+			}
+			if mismatch != nil {
+				mismatches = append(mismatches, *mismatch)
+			}
+		}
+
+		if len(users) < reconciliationPageSize {
+			break
+		}
+		afterID = &users[len(users)-1].ID
+	}
+
+	log.Printf("Reconciliation %s finished with %d mismatches", runID, len(mismatches))
+	return mismatches, nil
+}
+
+// listUsers obtiene la página de usuarios que sigue a afterID
+func (s *ReconciliationService) listUsers(ctx context.Context, afterID *uuid.UUID) ([]*models.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	return s.userRepo.List(ctx, afterID, reconciliationPageSize)
+}
+
+// reconcileUser compara el balance de un usuario y registra la diferencia si existe
+func (s *ReconciliationService) reconcileUser(ctx context.Context, runID uuid.UUID, user *models.User) (*models.ReconciliationMismatch, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	accountID := *user.TigerBeetleAccountID
+	debits, credits, err := s.tigerBeetleService.GetAccountBalance(uint64(accountID))
+	if err != nil {
+		return nil, fmt.Errorf("error getting tigerbeetle balance for user %s: %w", user.ID, err)
+	}
+	actual := int64(credits) - int64(debits)
+
+	expected, err := s.transactionRepo.GetUserExpectedBalance(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if actual == expected {
+		return nil, nil
+	}
+
+	mismatch := &models.ReconciliationMismatch{
+		RunID:                runID,
+		UserID:               user.ID,
+		TigerBeetleAccountID: accountID,
+		TigerBeetleBalance:   actual,
+		ExpectedBalance:      expected,
+		Difference:           actual - expected,
+	}
+	if err := s.reconciliationRepo.Record(ctx, mismatch); err != nil {
+		return nil, err
+	}
+
+	return mismatch, nil
+}
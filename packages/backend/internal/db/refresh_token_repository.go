@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"banca-en-linea/backend/models"
+)
+
+// RefreshTokenRepository define la interfaz para los refresh tokens en la base de datos
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, tokenHash string, userID uuid.UUID, expiresAt time.Time, session models.SessionInfo) error
+	GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	Revoke(ctx context.Context, tokenHash string) error
+	ListActiveByUser(ctx context.Context, userID uuid.UUID) ([]models.Session, error)
+	RevokeByID(ctx context.Context, id, userID uuid.UUID) (bool, error)
+	RevokeAllByUser(ctx context.Context, userID uuid.UUID) error
+}
+
+// refreshTokenRepository implementa RefreshTokenRepository
+type refreshTokenRepository struct {
+	db *sql.DB
+}
+
+// NewRefreshTokenRepository crea una nueva instancia del repositorio de refresh tokens
+func NewRefreshTokenRepository(db *sql.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+// Create guarda el hash de un nuevo refresh token junto con el dispositivo y la IP de la sesión
+func (r *refreshTokenRepository) Create(ctx context.Context, tokenHash string, userID uuid.UUID, expiresAt time.Time, session models.SessionInfo) error {
+	query := `
+		INSERT INTO refresh_tokens (token_hash, user_id, expires_at, device_info, ip_address)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''))`
+
+	if _, err := r.db.ExecContext(ctx, query, tokenHash, userID, expiresAt, session.DeviceInfo, session.IPAddress); err != nil {
+		return fmt.Errorf("error creating refresh token: %w", err)
+	}
+
+	return nil
+}
+
+// GetByHash obtiene un refresh token junto con el email de su usuario
+func (r *refreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	query := `
+		SELECT rt.id, rt.token_hash, rt.user_id, u.email, u.roles, rt.expires_at, rt.revoked_at, rt.created_at
+		FROM refresh_tokens rt
+		JOIN users u ON u.id = rt.user_id
+		WHERE rt.token_hash = $1 AND u.deleted_at IS NULL`
+
+	token := &models.RefreshToken{}
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&token.ID,
+		&token.TokenHash,
+		&token.UserID,
+		&token.UserEmail,
+		pq.Array(&token.UserRoles),
+		&token.ExpiresAt,
+		&token.RevokedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("refresh token not found")
+		}
+		return nil, fmt.Errorf("error getting refresh token: %w", err)
+	}
+
+	return token, nil
+}
+
+// Revoke marca un refresh token como revocado
+func (r *refreshTokenRepository) Revoke(ctx context.Context, tokenHash string) error {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = NOW()
+		WHERE token_hash = $1 AND revoked_at IS NULL`
+
+	if _, err := r.db.ExecContext(ctx, query, tokenHash); err != nil {
+		return fmt.Errorf("error revoking refresh token: %w", err)
+	}
+
+	return nil
+}
+
+// ListActiveByUser lista las sesiones vigentes del usuario, de la más reciente a la más antigua
+func (r *refreshTokenRepository) ListActiveByUser(ctx context.Context, userID uuid.UUID) ([]models.Session, error) {
+	query := `
+		SELECT id, device_info, ip_address, created_at, expires_at
+		FROM refresh_tokens
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		var session models.Session
+		if err := rows.Scan(&session.ID, &session.DeviceInfo, &session.IPAddress, &session.CreatedAt, &session.ExpiresAt); err != nil {
+			return nil, fmt.Errorf("error scanning session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// RevokeByID revoca la sesión indicada si pertenece al usuario. Retorna false si no existe una
+// sesión activa con ese ID para el usuario.
+func (r *refreshTokenRepository) RevokeByID(ctx context.Context, id, userID uuid.UUID) (bool, error) {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("error revoking session: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error checking affected rows: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// RevokeAllByUser revoca todas las sesiones activas del usuario
+func (r *refreshTokenRepository) RevokeAllByUser(ctx context.Context, userID uuid.UUID) error {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL`
+
+	if _, err := r.db.ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("error revoking sessions: %w", err)
+	}
+
+	return nil
+}
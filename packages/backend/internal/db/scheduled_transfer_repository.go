@@ -0,0 +1,280 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/models"
+)
+
+// ErrScheduledTransferNotFound indica que la transferencia programada no existe
+var ErrScheduledTransferNotFound = errors.New("scheduled transfer not found")
+
+// ErrScheduledTransferNotPending indica que la transferencia ya se procesó o se canceló y no admite cambios
+var ErrScheduledTransferNotPending = errors.New("scheduled transfer is not pending")
+
+// ScheduledTransferRepository define la interfaz para las transferencias programadas
+type ScheduledTransferRepository interface {
+	Create(ctx context.Context, transfer *models.ScheduledTransfer) (*models.ScheduledTransfer, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ScheduledTransfer, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.ScheduledTransfer, error)
+	Update(ctx context.Context, id uuid.UUID, updates *models.UpdateScheduledTransferRequest) (*models.ScheduledTransfer, error)
+	Cancel(ctx context.Context, id, userID uuid.UUID) error
+	ProcessDue(ctx context.Context, limit int, execute func(ctx context.Context, transfer *models.ScheduledTransfer) *models.ScheduledTransfer) (int, error)
+}
+
+// scheduledTransferColumns son las columnas que se leen al cargar una transferencia programada
+const scheduledTransferColumns = `id, from_user_id, to_user_id, amount_cents, scheduled_at, recurrence_type,
+		       recurrence_end_date, executed_at, cancelled_at, status, COALESCE(error_text, ''), created_at`
+
+// scanScheduledTransfer lee una transferencia programada a partir de una fila que contiene scheduledTransferColumns
+func scanScheduledTransfer(row rowScanner) (*models.ScheduledTransfer, error) {
+	transfer := &models.ScheduledTransfer{}
+	err := row.Scan(
+		&transfer.ID,
+		&transfer.FromUserID,
+		&transfer.ToUserID,
+		&transfer.AmountCents,
+		&transfer.ScheduledAt,
+		&transfer.RecurrenceType,
+		&transfer.RecurrenceEndDate,
+		&transfer.ExecutedAt,
+		&transfer.CancelledAt,
+		&transfer.Status,
+		&transfer.ErrorText,
+		&transfer.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// scheduledTransferRepository implementa ScheduledTransferRepository
+type scheduledTransferRepository struct {
+	db *sql.DB
+}
+
+// NewScheduledTransferRepository crea una nueva instancia del repositorio de transferencias programadas
+func NewScheduledTransferRepository(db *sql.DB) ScheduledTransferRepository {
+	return &scheduledTransferRepository{db: db}
+}
+
+// insertScheduledTransferQuery registra una transferencia programada pendiente
+const insertScheduledTransferQuery = `
+		INSERT INTO scheduled_transfers (from_user_id, to_user_id, amount_cents, scheduled_at, recurrence_type, recurrence_end_date)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + scheduledTransferColumns
+
+// Create registra una transferencia programada pendiente
+func (r *scheduledTransferRepository) Create(ctx context.Context, transfer *models.ScheduledTransfer) (*models.ScheduledTransfer, error) {
+	created, err := scanScheduledTransfer(r.db.QueryRowContext(ctx, insertScheduledTransferQuery,
+		transfer.FromUserID,
+		transfer.ToUserID,
+		transfer.AmountCents,
+		transfer.ScheduledAt,
+		transfer.RecurrenceType,
+		transfer.RecurrenceEndDate,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("error creating scheduled transfer: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetByID obtiene una transferencia programada por su ID
+func (r *scheduledTransferRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ScheduledTransfer, error) {
+	query := `
+		SELECT ` + scheduledTransferColumns + `
+		FROM scheduled_transfers
+		WHERE id = $1`
+
+	transfer, err := scanScheduledTransfer(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrScheduledTransferNotFound
+		}
+		return nil, fmt.Errorf("error getting scheduled transfer: %w", err)
+	}
+
+	return transfer, nil
+}
+
+// ListByUser obtiene las transferencias programadas enviadas por un usuario, las próximas primero
+func (r *scheduledTransferRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.ScheduledTransfer, error) {
+	query := `
+		SELECT ` + scheduledTransferColumns + `
+		FROM scheduled_transfers
+		WHERE from_user_id = $1
+		ORDER BY scheduled_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing scheduled transfers: %w", err)
+	}
+	defer rows.Close()
+
+	transfers := []*models.ScheduledTransfer{}
+	for rows.Next() {
+		transfer, err := scanScheduledTransfer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning scheduled transfer: %w", err)
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating scheduled transfers: %w", err)
+	}
+
+	return transfers, nil
+}
+
+// Update modifica el monto, la fecha o el fin de la recurrencia de una transferencia pendiente
+func (r *scheduledTransferRepository) Update(ctx context.Context, id uuid.UUID, updates *models.UpdateScheduledTransferRequest) (*models.ScheduledTransfer, error) {
+	var amount *int64
+	if updates.Amount != nil {
+		cents := int64(*updates.Amount)
+		amount = &cents
+	}
+
+	query := `
+		UPDATE scheduled_transfers
+		SET amount_cents = COALESCE($1, amount_cents),
+		    scheduled_at = COALESCE($2, scheduled_at),
+		    recurrence_end_date = COALESCE($3, recurrence_end_date)
+		WHERE id = $4 AND status = 'pending'
+		RETURNING ` + scheduledTransferColumns
+
+	transfer, err := scanScheduledTransfer(r.db.QueryRowContext(ctx, query, amount, updates.ScheduledAt, updates.RecurrenceEndDate, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, r.notPendingError(ctx, id)
+		}
+		return nil, fmt.Errorf("error updating scheduled transfer: %w", err)
+	}
+
+	return transfer, nil
+}
+
+// Cancel cancela una transferencia pendiente enviada por el usuario indicado. Las transferencias
+// de otros usuarios se reportan como inexistentes.
+func (r *scheduledTransferRepository) Cancel(ctx context.Context, id, userID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE scheduled_transfers
+		SET status = 'cancelled', cancelled_at = NOW()
+		WHERE id = $1 AND from_user_id = $2 AND status = 'pending'`, id, userID)
+	if err != nil {
+		return fmt.Errorf("error cancelling scheduled transfer: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+	if rowsAffected > 0 {
+		return nil
+	}
+
+	var exists bool
+	err = r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM scheduled_transfers WHERE id = $1 AND from_user_id = $2)`, id, userID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("error checking scheduled transfer: %w", err)
+	}
+	if exists {
+		return ErrScheduledTransferNotPending
+	}
+	return ErrScheduledTransferNotFound
+}
+
+// notPendingError distingue una transferencia inexistente de una que ya no está pendiente
+func (r *scheduledTransferRepository) notPendingError(ctx context.Context, id uuid.UUID) error {
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM scheduled_transfers WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return fmt.Errorf("error checking scheduled transfer: %w", err)
+	}
+	if exists {
+		return ErrScheduledTransferNotPending
+	}
+	return ErrScheduledTransferNotFound
+}
+
+// ProcessDue bloquea hasta limit transferencias pendientes cuya fecha ya llegó, ejecuta cada una
+// con execute y guarda el estado y el error que execute dejó en ella. Si execute retorna la
+// siguiente ocurrencia de una transferencia recurrente, se inserta en la misma transacción. Las
+// filas se toman con FOR UPDATE SKIP LOCKED, de modo que otra réplica que procese al mismo tiempo
+// salte las bloqueadas en lugar de ejecutarlas dos veces. Retorna la cantidad de transferencias procesadas.
+func (r *scheduledTransferRepository) ProcessDue(ctx context.Context, limit int, execute func(ctx context.Context, transfer *models.ScheduledTransfer) *models.ScheduledTransfer) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT ` + scheduledTransferColumns + `
+		FROM scheduled_transfers
+		WHERE status = 'pending' AND scheduled_at <= NOW()
+		ORDER BY scheduled_at
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`
+
+	rows, err := tx.QueryContext(ctx, query, limit)
+	if err != nil {
+		return 0, fmt.Errorf("error listing due scheduled transfers: %w", err)
+	}
+
+	var due []*models.ScheduledTransfer
+	for rows.Next() {
+		transfer, err := scanScheduledTransfer(rows)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("error scanning scheduled transfer: %w", err)
+		}
+		due = append(due, transfer)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating scheduled transfers: %w", err)
+	}
+
+	for _, transfer := range due {
+		next := execute(ctx, transfer)
+
+		errorText := sql.NullString{String: transfer.ErrorText, Valid: transfer.ErrorText != ""}
+		_, err := tx.ExecContext(ctx, `
+			UPDATE scheduled_transfers
+			SET status = $1, executed_at = $2, error_text = $3
+			WHERE id = $4`,
+			transfer.Status, time.Now(), errorText, transfer.ID)
+		if err != nil {
+			return 0, fmt.Errorf("error updating scheduled transfer %s: %w", transfer.ID, err)
+		}
+
+		if next != nil {
+			_, err := tx.ExecContext(ctx, insertScheduledTransferQuery,
+				next.FromUserID,
+				next.ToUserID,
+				next.AmountCents,
+				next.ScheduledAt,
+				next.RecurrenceType,
+				next.RecurrenceEndDate,
+			)
+			if err != nil {
+				return 0, fmt.Errorf("error scheduling next occurrence of %s: %w", transfer.ID, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing scheduled transfers: %w", err)
+	}
+
+	return len(due), nil
+}
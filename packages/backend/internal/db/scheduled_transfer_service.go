@@ -0,0 +1,202 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/internal/idempotency"
+	"banca-en-linea/backend/models"
+)
+
+// scheduledTransferBatchSize es la cantidad de transferencias programadas que se bloquean y
+// ejecutan en cada transacción
+const scheduledTransferBatchSize = 50
+
+var (
+	// ErrScheduledInPast indica que la fecha de una transferencia programada no es futura
+	ErrScheduledInPast = errors.New("scheduled time must be in the future")
+	// ErrInvalidRecurrence indica un tipo de recurrencia desconocido o un fin de recurrencia
+	// anterior a la primera ejecución
+	ErrInvalidRecurrence = errors.New("invalid recurrence")
+)
+
+// ScheduledTransferService programa transferencias entre usuarios, únicas o recurrentes, y las
+// ejecuta cuando llega su fecha
+type ScheduledTransferService struct {
+	repo        ScheduledTransferRepository
+	userService *UserService
+}
+
+// NewScheduledTransferService crea una nueva instancia del servicio de transferencias programadas
+func NewScheduledTransferService(repo ScheduledTransferRepository, userService *UserService) *ScheduledTransferService {
+	return &ScheduledTransferService{
+		repo:        repo,
+		userService: userService,
+	}
+}
+
+// Schedule registra una transferencia para ejecutarse en la fecha indicada
+func (s *ScheduledTransferService) Schedule(ctx context.Context, req *models.ScheduleTransferRequest) (*models.ScheduledTransfer, error) {
+	if !req.ScheduledAt.After(time.Now()) {
+		return nil, ErrScheduledInPast
+	}
+
+	recurrence := req.RecurrenceType
+	if recurrence == "" {
+		recurrence = models.RecurrenceOnce
+	}
+	if err := validateRecurrence(recurrence, req.ScheduledAt, req.RecurrenceEndDate); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	return s.repo.Create(ctx, &models.ScheduledTransfer{
+		FromUserID:        req.FromUserID,
+		ToUserID:          req.ToUserID,
+		AmountCents:       int64(req.Amount),
+		ScheduledAt:       req.ScheduledAt,
+		RecurrenceType:    recurrence,
+		RecurrenceEndDate: req.RecurrenceEndDate,
+	})
+}
+
+// validateRecurrence verifica el tipo de recurrencia y que su fin no sea anterior a la primera ejecución
+func validateRecurrence(recurrence string, scheduledAt time.Time, endDate *time.Time) error {
+	switch recurrence {
+	case models.RecurrenceOnce, models.RecurrenceWeekly, models.RecurrenceMonthly:
+	default:
+		return ErrInvalidRecurrence
+	}
+
+	if endDate != nil && endDate.Before(scheduledAt) {
+		return ErrInvalidRecurrence
+	}
+	return nil
+}
+
+// GetScheduledTransfer obtiene una transferencia programada por su ID
+func (s *ScheduledTransferService) GetScheduledTransfer(ctx context.Context, id uuid.UUID) (*models.ScheduledTransfer, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// ListScheduledTransfers obtiene las transferencias programadas por un usuario
+func (s *ScheduledTransferService) ListScheduledTransfers(ctx context.Context, userID uuid.UUID) ([]*models.ScheduledTransfer, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	return s.repo.ListByUser(ctx, userID)
+}
+
+// UpdateScheduledTransfer modifica una transferencia que aún no se ejecutó
+func (s *ScheduledTransferService) UpdateScheduledTransfer(ctx context.Context, id uuid.UUID, req *models.UpdateScheduledTransferRequest) (*models.ScheduledTransfer, error) {
+	if req.ScheduledAt != nil && !req.ScheduledAt.After(time.Now()) {
+		return nil, ErrScheduledInPast
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	if req.RecurrenceEndDate != nil {
+		current, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		scheduledAt := current.ScheduledAt
+		if req.ScheduledAt != nil {
+			scheduledAt = *req.ScheduledAt
+		}
+		if err := validateRecurrence(current.RecurrenceType, scheduledAt, req.RecurrenceEndDate); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.repo.Update(ctx, id, req)
+}
+
+// Cancel cancela una transferencia del usuario que aún no se ejecutó. En una serie recurrente
+// cancela las ejecuciones futuras.
+func (s *ScheduledTransferService) Cancel(ctx context.Context, transferID, userID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	return s.repo.Cancel(ctx, transferID, userID)
+}
+
+// Execute ejecuta, por lotes, todas las transferencias pendientes cuya fecha ya llegó. Las que
+// fallan quedan en estado "failed" con el motivo y no se reintentan.
+func (s *ScheduledTransferService) Execute(ctx context.Context) error {
+	total := 0
+	for {
+		processed, err := s.repo.ProcessDue(ctx, scheduledTransferBatchSize, s.executeTransfer)
+		if err != nil {
+			return err
+		}
+		total += processed
+
+		if processed < scheduledTransferBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Printf("Scheduled transfers processed: %d", total)
+	}
+	return nil
+}
+
+// executeTransfer realiza una transferencia programada y deja en ella su estado final. Si es
+// recurrente y se ejecutó, retorna la siguiente ocurrencia; cuando la siguiente fecha supera el
+// fin de la recurrencia la serie termina y la transferencia queda "completed".
+//
+// Los IDs de TigerBeetle se derivan del remitente y del ID de la transferencia programada: si una
+// ejecución anterior aplicó la transferencia pero no llegó a registrar su estado, UserService la
+// trata como ya aplicada y se considera ejecutada.
+func (s *ScheduledTransferService) executeTransfer(ctx context.Context, transfer *models.ScheduledTransfer) *models.ScheduledTransfer {
+	ctx = idempotency.WithKey(ctx, transfer.FromUserID, idempotency.HashKey("scheduled-transfer:"+transfer.ID.String()))
+
+	_, err := s.userService.TransferBetweenUsers(ctx, transfer.FromUserID, transfer.ToUserID, uint64(transfer.AmountCents))
+	if err != nil {
+		log.Printf("Scheduled transfer %s failed: %v", transfer.ID, err)
+		transfer.Status = models.ScheduledTransferStatusFailed
+		transfer.ErrorText = fmt.Sprintf("error executing scheduled transfer: %v", err)
+		return nil
+	}
+
+	transfer.Status = models.ScheduledTransferStatusExecuted
+	if transfer.RecurrenceType == models.RecurrenceOnce {
+		return nil
+	}
+
+	nextAt := nextOccurrence(transfer.ScheduledAt, transfer.RecurrenceType)
+	if transfer.RecurrenceEndDate != nil && nextAt.After(*transfer.RecurrenceEndDate) {
+		transfer.Status = models.ScheduledTransferStatusCompleted
+		return nil
+	}
+
+	next := *transfer
+	next.ID = uuid.Nil
+	next.ScheduledAt = nextAt
+	next.ExecutedAt = nil
+	next.Status = models.ScheduledTransferStatusPending
+	next.ErrorText = ""
+	return &next
+}
+
+// nextOccurrence avanza la fecha de una transferencia recurrente un intervalo. Las mensuales
+// siguen la normalización de time.AddDate (un 31 de enero avanza al 2 o 3 de marzo).
+func nextOccurrence(scheduledAt time.Time, recurrence string) time.Time {
+	if recurrence == models.RecurrenceWeekly {
+		return scheduledAt.AddDate(0, 0, 7)
+	}
+	return scheduledAt.AddDate(0, 1, 0)
+}
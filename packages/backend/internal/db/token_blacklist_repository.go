@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// TokenBlacklistRepository define la interfaz para los JWT revocados
+type TokenBlacklistRepository interface {
+	Add(ctx context.Context, jti string, expiresAt time.Time) error
+	Contains(ctx context.Context, jti string) (bool, error)
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// tokenBlacklistRepository implementa TokenBlacklistRepository
+type tokenBlacklistRepository struct {
+	db *sql.DB
+}
+
+// NewTokenBlacklistRepository crea una nueva instancia del repositorio de JWT revocados
+func NewTokenBlacklistRepository(db *sql.DB) TokenBlacklistRepository {
+	return &tokenBlacklistRepository{db: db}
+}
+
+// Add revoca un JWT hasta su fecha de expiración
+func (r *tokenBlacklistRepository) Add(ctx context.Context, jti string, expiresAt time.Time) error {
+	query := `
+		INSERT INTO token_blacklist (jti, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (jti) DO NOTHING`
+
+	if _, err := r.db.ExecContext(ctx, query, jti, expiresAt); err != nil {
+		return fmt.Errorf("error blacklisting token: %w", err)
+	}
+
+	return nil
+}
+
+// Contains indica si un JWT fue revocado
+func (r *tokenBlacklistRepository) Contains(ctx context.Context, jti string) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM token_blacklist WHERE jti = $1)`
+
+	if err := r.db.QueryRowContext(ctx, query, jti).Scan(&exists); err != nil {
+		return false, fmt.Errorf("error checking token blacklist: %w", err)
+	}
+
+	return exists, nil
+}
+
+// DeleteExpired elimina los JWT revocados que ya expiraron y retorna cuántos se eliminaron
+func (r *tokenBlacklistRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM token_blacklist WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting expired tokens: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error checking affected rows: %w", err)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,589 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"banca-en-linea/backend/models"
+)
+
+// userOutgoingFilter limita las transacciones a las que salen de las cuentas del usuario indicado
+// en $1, incluidos los retiros y transferencias desde su cuenta principal, que no está en
+// bank_accounts y se registran con from_account_id nulo a nombre del usuario. Las correcciones
+// también se registran a nombre del administrador que las aplicó, por lo que no cuentan aquí.
+const userOutgoingFilter = `(from_account_id IN (SELECT id FROM bank_accounts WHERE user_id = $1)
+		    OR (from_account_id IS NULL AND created_by = $1 AND transaction_type <> 'balance_correction'))`
+
+// userIncomingFilter limita las transacciones a las que entran a las cuentas del usuario indicado
+// en $1, incluidos los depósitos y transferencias a su cuenta principal, que se registran con
+// to_account_id nulo y el usuario como destinatario
+const userIncomingFilter = `(to_account_id IN (SELECT id FROM bank_accounts WHERE user_id = $1)
+		    OR (to_account_id IS NULL AND recipient_user_id = $1))`
+
+// userTransactionsFilter limita las transacciones a las que salen o entran a las cuentas del
+// usuario indicado en $1, incluida su cuenta principal
+const userTransactionsFilter = `(` + userOutgoingFilter + ` OR ` + userIncomingFilter + `)`
+
+// userSpendingFilter limita las transacciones a los retiros y transferencias que salen de las
+// cuentas del usuario indicado en $1
+const userSpendingFilter = `transaction_type IN ('transfer', 'withdrawal') AND ` + userOutgoingFilter
+
+var (
+	// ErrTransactionNotFound indica que la transacción no existe o no pertenece al usuario
+	ErrTransactionNotFound = errors.New("transaction not found")
+	// ErrTransactionAlreadyReversed indica que la transacción ya fue revertida
+	ErrTransactionAlreadyReversed = errors.New("transaction already reversed")
+)
+
+// TransactionRepository define la interfaz para operaciones de transacciones en la base de datos
+type TransactionRepository interface {
+	GetSummarySince(ctx context.Context, userID uuid.UUID, since time.Time) (int, int64, error)
+	GetLastTransactionDate(ctx context.Context, userID uuid.UUID) (*time.Time, error)
+	GetExpectedBalance(ctx context.Context, accountID uuid.UUID) (int64, error)
+	GetRecordedMovements(ctx context.Context, accountID uuid.UUID) (uint64, uint64, error)
+	GetUserExpectedBalance(ctx context.Context, userID uuid.UUID) (int64, error)
+	Create(ctx context.Context, tx *models.Transaction) (*models.Transaction, error)
+	CreateReversal(ctx context.Context, tx *models.Transaction) (*models.Transaction, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Transaction, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, status string) error
+	ListOutgoingByUser(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*models.Transaction, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID, afterID *uuid.UUID, limit int) ([]*models.Transaction, error)
+	Filter(ctx context.Context, userID uuid.UUID, opts models.TransactionFilter) ([]*models.Transaction, error)
+	GetDailyTotal(ctx context.Context, userID uuid.UUID, txType string, date time.Time) (uint64, error)
+	GetVelocity(ctx context.Context, userID uuid.UUID, txType string, since time.Time) (int, uint64, error)
+	GetOutgoingTotalSince(ctx context.Context, userID uuid.UUID, since time.Time) (uint64, error)
+	UpdateCategory(ctx context.Context, userID, txID uuid.UUID, category string) error
+	GetCategoryBreakdown(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]models.CategorySpending, error)
+	GetSpendingSummary(ctx context.Context, userID uuid.UUID, from, to time.Time) (*models.SpendingSummary, error)
+}
+
+// transactionColumns son las columnas que se leen al cargar una transacción (en el orden de scanTransaction)
+const transactionColumns = `id, tigerbeetle_transfer_id, from_account_id, to_account_id,
+		       ROUND(amount * 100)::BIGINT, currency, COALESCE(description, ''), transaction_type, status,
+		       COALESCE(category, ''), tags, created_by, recipient_user_id, related_transaction_id,
+		       created_at, updated_at`
+
+// scanTransaction lee una transacción a partir de una fila que contiene transactionColumns
+func scanTransaction(row rowScanner) (*models.Transaction, error) {
+	tx := &models.Transaction{}
+	err := row.Scan(
+		&tx.ID,
+		&tx.TigerBeetleTransferID,
+		&tx.FromAccountID,
+		&tx.ToAccountID,
+		&tx.Amount,
+		&tx.Currency,
+		&tx.Description,
+		&tx.TransactionType,
+		&tx.Status,
+		&tx.Category,
+		pq.Array(&tx.Tags),
+		&tx.CreatedBy,
+		&tx.RecipientUserID,
+		&tx.RelatedTransactionID,
+		&tx.CreatedAt,
+		&tx.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// transactionRepository implementa TransactionRepository
+type transactionRepository struct {
+	db *sql.DB
+}
+
+// NewTransactionRepository crea una nueva instancia del repositorio de transacciones
+func NewTransactionRepository(db *sql.DB) TransactionRepository {
+	return &transactionRepository{db: db}
+}
+
+// GetSummarySince obtiene la cantidad de transacciones y el volumen total (en centavos)
+// de un usuario desde la fecha indicada
+func (r *transactionRepository) GetSummarySince(ctx context.Context, userID uuid.UUID, since time.Time) (int, int64, error) {
+	query := `
+		SELECT COUNT(*), COALESCE(ROUND(SUM(amount) * 100), 0)::BIGINT
+		FROM transactions
+		WHERE ` + userTransactionsFilter + `
+		  AND status NOT IN ('failed', 'cancelled')
+		  AND created_at >= $2`
+
+	var count int
+	var volume int64
+	if err := r.db.QueryRowContext(ctx, query, userID, since).Scan(&count, &volume); err != nil {
+		return 0, 0, fmt.Errorf("error getting transaction summary: %w", err)
+	}
+
+	return count, volume, nil
+}
+
+// GetLastTransactionDate obtiene la fecha de la última transacción de un usuario
+func (r *transactionRepository) GetLastTransactionDate(ctx context.Context, userID uuid.UUID) (*time.Time, error) {
+	query := `
+		SELECT MAX(created_at)
+		FROM transactions
+		WHERE ` + userTransactionsFilter
+
+	var last sql.NullTime
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&last); err != nil {
+		return nil, fmt.Errorf("error getting last transaction date: %w", err)
+	}
+
+	if !last.Valid {
+		return nil, nil
+	}
+	return &last.Time, nil
+}
+
+// GetExpectedBalance calcula el balance esperado de una cuenta (en centavos) sumando sus
+// transacciones registradas. Las correcciones manuales no se incluyen porque solo
+// alinean TigerBeetle con este valor.
+func (r *transactionRepository) GetExpectedBalance(ctx context.Context, accountID uuid.UUID) (int64, error) {
+	query := `
+		SELECT COALESCE(ROUND(SUM(CASE WHEN to_account_id = $1 THEN amount ELSE -amount END) * 100), 0)::BIGINT
+		FROM transactions
+		WHERE (from_account_id = $1 OR to_account_id = $1)
+		  AND status NOT IN ('failed', 'cancelled')
+		  AND transaction_type <> 'balance_correction'`
+
+	var balance int64
+	if err := r.db.QueryRowContext(ctx, query, accountID).Scan(&balance); err != nil {
+		return 0, fmt.Errorf("error calculating expected balance: %w", err)
+	}
+
+	return balance, nil
+}
+
+// GetRecordedMovements suma en centavos los débitos y los créditos registrados de una cuenta,
+// incluidas las correcciones, para compararlos con los totales que TigerBeetle aplicó a la
+// cuenta. Las transacciones fallidas o canceladas no cuentan.
+func (r *transactionRepository) GetRecordedMovements(ctx context.Context, accountID uuid.UUID) (uint64, uint64, error) {
+	query := `
+		SELECT COALESCE(SUM(ROUND(amount * 100)) FILTER (WHERE from_account_id = $1), 0)::BIGINT,
+		       COALESCE(SUM(ROUND(amount * 100)) FILTER (WHERE to_account_id = $1), 0)::BIGINT
+		FROM transactions
+		WHERE (from_account_id = $1 OR to_account_id = $1)
+		  AND status IN ('pending', 'completed')`
+
+	var debits, credits int64
+	if err := r.db.QueryRowContext(ctx, query, accountID).Scan(&debits, &credits); err != nil {
+		return 0, 0, fmt.Errorf("error getting recorded movements: %w", err)
+	}
+
+	return uint64(debits), uint64(credits), nil
+}
+
+// GetUserExpectedBalance calcula el balance esperado de la cuenta principal de un usuario (en
+// centavos) sumando sus transacciones registradas. La cuenta principal no está en bank_accounts:
+// sus movimientos se registran con la cuenta nula, a nombre del usuario si salen de ella y con el
+// usuario como destinatario si entran. Como en GetExpectedBalance, las correcciones no se incluyen.
+func (r *transactionRepository) GetUserExpectedBalance(ctx context.Context, userID uuid.UUID) (int64, error) {
+	query := `
+		SELECT COALESCE(ROUND(SUM(
+		           CASE WHEN to_account_id IS NULL AND recipient_user_id = $1 THEN amount ELSE 0 END -
+		           CASE WHEN from_account_id IS NULL AND created_by = $1 THEN amount ELSE 0 END
+		       ) * 100), 0)::BIGINT
+		FROM transactions
+		WHERE ((from_account_id IS NULL AND created_by = $1) OR (to_account_id IS NULL AND recipient_user_id = $1))
+		  AND status NOT IN ('failed', 'cancelled')
+		  AND transaction_type <> 'balance_correction'`
+
+	var balance int64
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&balance); err != nil {
+		return 0, fmt.Errorf("error calculating expected balance: %w", err)
+	}
+
+	return balance, nil
+}
+
+// Create registra una nueva transacción. El monto se recibe en centavos.
+func (r *transactionRepository) Create(ctx context.Context, tx *models.Transaction) (*models.Transaction, error) {
+	query := `
+		INSERT INTO transactions (tigerbeetle_transfer_id, from_account_id, to_account_id, amount, currency,
+		                          description, transaction_type, status, category, tags, created_by,
+		                          recipient_user_id, related_transaction_id)
+		VALUES ($1, $2, $3, $4::BIGINT / 100.0, $5, $6, $7, $8, NULLIF($9, ''), $10, $11, $12, $13)
+		RETURNING id, created_at, updated_at`
+
+	created := *tx
+	if created.Tags == nil {
+		created.Tags = []string{}
+	}
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		tx.TigerBeetleTransferID,
+		tx.FromAccountID,
+		tx.ToAccountID,
+		tx.Amount,
+		tx.Currency,
+		tx.Description,
+		tx.TransactionType,
+		tx.Status,
+		tx.Category,
+		pq.Array(created.Tags),
+		tx.CreatedBy,
+		tx.RecipientUserID,
+		tx.RelatedTransactionID,
+	).Scan(&created.ID, &created.CreatedAt, &created.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("error creating transaction: %w", err)
+	}
+
+	return &created, nil
+}
+
+// CreateReversal registra la reversión de una transacción. Retorna ErrTransactionAlreadyReversed
+// si la transacción original ya tiene una reversión que no falló.
+func (r *transactionRepository) CreateReversal(ctx context.Context, tx *models.Transaction) (*models.Transaction, error) {
+	query := `
+		INSERT INTO transactions (tigerbeetle_transfer_id, from_account_id, to_account_id, amount, currency,
+		                          description, transaction_type, status, created_by, recipient_user_id,
+		                          related_transaction_id)
+		VALUES ($1, $2, $3, $4::BIGINT / 100.0, $5, $6, 'reversal', $7, $8, $9, $10)
+		ON CONFLICT (related_transaction_id) WHERE transaction_type = 'reversal' AND status <> 'failed' DO NOTHING
+		RETURNING id, created_at, updated_at`
+
+	created := *tx
+	created.TransactionType = models.TransactionTypeReversal
+	created.Tags = []string{}
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		tx.TigerBeetleTransferID,
+		tx.FromAccountID,
+		tx.ToAccountID,
+		tx.Amount,
+		tx.Currency,
+		tx.Description,
+		tx.Status,
+		tx.CreatedBy,
+		tx.RecipientUserID,
+		tx.RelatedTransactionID,
+	).Scan(&created.ID, &created.CreatedAt, &created.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTransactionAlreadyReversed
+		}
+		return nil, fmt.Errorf("error creating reversal: %w", err)
+	}
+
+	return &created, nil
+}
+
+// GetByID obtiene una transacción por su ID
+func (r *transactionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Transaction, error) {
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions
+		WHERE id = $1`
+
+	tx, err := scanTransaction(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, fmt.Errorf("error getting transaction: %w", err)
+	}
+
+	return tx, nil
+}
+
+// UpdateStatus cambia el estado de una transacción
+func (r *transactionRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status string) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE transactions SET status = $2 WHERE id = $1`, id, status)
+	if err != nil {
+		return fmt.Errorf("error updating transaction status: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrTransactionNotFound
+	}
+
+	return nil
+}
+
+// ListOutgoingByUser obtiene las transacciones que salen de las cuentas de un usuario, incluida su
+// cuenta principal, en el rango indicado
+func (r *transactionRepository) ListOutgoingByUser(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*models.Transaction, error) {
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions
+		WHERE ` + userOutgoingFilter + `
+		  AND created_at BETWEEN $2 AND $3
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error listing transactions: %w", err)
+	}
+
+	return scanTransactions(rows)
+}
+
+// GetByUserID obtiene las transacciones de las cuentas de un usuario, de la más reciente a la
+// más antigua, usando paginación por keyset: si afterID no es nil se retornan las transacciones
+// posteriores (más antiguas) a esa transacción
+func (r *transactionRepository) GetByUserID(ctx context.Context, userID uuid.UUID, afterID *uuid.UUID, limit int) ([]*models.Transaction, error) {
+	return r.Filter(ctx, userID, models.TransactionFilter{Cursor: afterID, Limit: limit})
+}
+
+// Filter obtiene las transacciones de las cuentas de un usuario que cumplen los filtros indicados,
+// de la más reciente a la más antigua y con la misma paginación por keyset que GetByUserID
+func (r *transactionRepository) Filter(ctx context.Context, userID uuid.UUID, opts models.TransactionFilter) ([]*models.Transaction, error) {
+	// Construir la consulta dinámicamente basada en los filtros presentes
+	conditions := []string{userTransactionsFilter}
+	args := []interface{}{userID}
+	argIndex := 2
+
+	if opts.FromDate != nil {
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", argIndex))
+		args = append(args, *opts.FromDate)
+		argIndex++
+	}
+
+	if opts.ToDate != nil {
+		conditions = append(conditions, fmt.Sprintf("created_at <= $%d", argIndex))
+		args = append(args, *opts.ToDate)
+		argIndex++
+	}
+
+	if opts.Type != nil {
+		conditions = append(conditions, fmt.Sprintf("transaction_type = $%d", argIndex))
+		args = append(args, *opts.Type)
+		argIndex++
+	}
+
+	if opts.MinAmount != nil {
+		conditions = append(conditions, fmt.Sprintf("ROUND(amount * 100) >= $%d", argIndex))
+		args = append(args, *opts.MinAmount)
+		argIndex++
+	}
+
+	if opts.MaxAmount != nil {
+		conditions = append(conditions, fmt.Sprintf("ROUND(amount * 100) <= $%d", argIndex))
+		args = append(args, *opts.MaxAmount)
+		argIndex++
+	}
+
+	if opts.Cursor != nil {
+		conditions = append(conditions, fmt.Sprintf(
+			"(created_at, id) < (SELECT created_at, id FROM transactions WHERE id = $%d)", argIndex))
+		args = append(args, *opts.Cursor)
+		argIndex++
+	}
+
+	// Agregar el límite al final de los argumentos
+	args = append(args, opts.Limit)
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM transactions
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d`,
+		transactionColumns,
+		strings.Join(conditions, " AND "),
+		argIndex,
+	)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing transactions: %w", err)
+	}
+
+	return scanTransactions(rows)
+}
+
+// GetDailyTotal suma en centavos los montos que salieron de las cuentas del usuario, incluida su
+// cuenta principal, con el tipo indicado durante el día de date (en la zona horaria de date). Las
+// transacciones fallidas o canceladas no cuentan para el total.
+func (r *transactionRepository) GetDailyTotal(ctx context.Context, userID uuid.UUID, txType string, date time.Time) (uint64, error) {
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	end := start.AddDate(0, 0, 1)
+
+	query := `
+		SELECT COALESCE(SUM(ROUND(amount * 100)), 0)::BIGINT
+		FROM transactions
+		WHERE ` + userOutgoingFilter + `
+		  AND transaction_type = $2
+		  AND status IN ('pending', 'completed')
+		  AND created_at >= $3 AND created_at < $4`
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, query, userID, txType, start, end).Scan(&total); err != nil {
+		return 0, fmt.Errorf("error getting daily total: %w", err)
+	}
+
+	return uint64(total), nil
+}
+
+// GetVelocity cuenta las transacciones del tipo indicado que entraron o salieron de las cuentas
+// del usuario, incluida su cuenta principal, desde since y suma sus montos en centavos. Las
+// transacciones fallidas o canceladas no cuentan.
+func (r *transactionRepository) GetVelocity(ctx context.Context, userID uuid.UUID, txType string, since time.Time) (int, uint64, error) {
+	query := `
+		SELECT COUNT(*), COALESCE(SUM(ROUND(amount * 100)), 0)::BIGINT
+		FROM transactions
+		WHERE ` + userTransactionsFilter + `
+		  AND transaction_type = $2
+		  AND status IN ('pending', 'completed')
+		  AND created_at >= $3`
+
+	var count int
+	var total int64
+	if err := r.db.QueryRowContext(ctx, query, userID, txType, since).Scan(&count, &total); err != nil {
+		return 0, 0, fmt.Errorf("error getting transaction velocity: %w", err)
+	}
+
+	return count, uint64(total), nil
+}
+
+// GetOutgoingTotalSince suma en centavos los retiros y transferencias que salieron de las cuentas
+// del usuario, incluida su cuenta principal, desde since. Las comisiones no cuentan, como tampoco
+// cuentan en el monto que se verifica, ni las transacciones fallidas o canceladas.
+func (r *transactionRepository) GetOutgoingTotalSince(ctx context.Context, userID uuid.UUID, since time.Time) (uint64, error) {
+	query := `
+		SELECT COALESCE(SUM(ROUND(amount * 100)), 0)::BIGINT
+		FROM transactions
+		WHERE ` + userSpendingFilter + `
+		  AND status IN ('pending', 'completed')
+		  AND created_at >= $2`
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, query, userID, since).Scan(&total); err != nil {
+		return 0, fmt.Errorf("error getting outgoing total: %w", err)
+	}
+
+	return uint64(total), nil
+}
+
+// UpdateCategory cambia la categoría de una transacción del usuario. Una categoría vacía la deja
+// sin categoría. Retorna ErrTransactionNotFound si la transacción no es del usuario.
+func (r *transactionRepository) UpdateCategory(ctx context.Context, userID, txID uuid.UUID, category string) error {
+	query := `
+		UPDATE transactions
+		SET category = NULLIF($2, '')
+		WHERE id = $3
+		  AND (` + userTransactionsFilter + ` OR created_by = $1)`
+
+	result, err := r.db.ExecContext(ctx, query, userID, category, txID)
+	if err != nil {
+		return fmt.Errorf("error updating transaction category: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrTransactionNotFound
+	}
+
+	return nil
+}
+
+// GetCategoryBreakdown suma en centavos y cuenta los retiros y transferencias del usuario entre
+// from y to agrupados por categoría, de la de mayor gasto a la de menor. Las transacciones sin
+// categoría se agrupan en models.UncategorizedCategory y las fallidas o canceladas no cuentan.
+func (r *transactionRepository) GetCategoryBreakdown(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]models.CategorySpending, error) {
+	query := `
+		SELECT COALESCE(category, $4), COALESCE(SUM(ROUND(amount * 100)), 0)::BIGINT, COUNT(*)
+		FROM transactions
+		WHERE ` + userSpendingFilter + `
+		  AND status IN ('pending', 'completed')
+		  AND created_at >= $2 AND created_at <= $3
+		GROUP BY 1
+		ORDER BY 2 DESC, 1`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, from, to, models.UncategorizedCategory)
+	if err != nil {
+		return nil, fmt.Errorf("error getting category breakdown: %w", err)
+	}
+	defer rows.Close()
+
+	breakdown := []models.CategorySpending{}
+	for rows.Next() {
+		var spending models.CategorySpending
+		if err := rows.Scan(&spending.Category, &spending.Total, &spending.Count); err != nil {
+			return nil, fmt.Errorf("error scanning category breakdown: %w", err)
+		}
+		breakdown = append(breakdown, spending)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating category breakdown: %w", err)
+	}
+
+	return breakdown, nil
+}
+
+// GetSpendingSummary calcula en centavos lo que salió y lo que entró a las cuentas del usuario
+// entre from y to, la cantidad de transacciones y el desglose por categoría de sus gastos. Los
+// movimientos entre cuentas del mismo usuario cuentan como débito y como crédito; las
+// correcciones de balance y las transacciones fallidas o canceladas no se incluyen.
+func (r *transactionRepository) GetSpendingSummary(ctx context.Context, userID uuid.UUID, from, to time.Time) (*models.SpendingSummary, error) {
+	query := `
+		SELECT COALESCE(SUM(ROUND(amount * 100)) FILTER (WHERE ` + userOutgoingFilter + `), 0)::BIGINT,
+		       COALESCE(SUM(ROUND(amount * 100)) FILTER (WHERE ` + userIncomingFilter + `), 0)::BIGINT,
+		       COUNT(*)
+		FROM transactions
+		WHERE ` + userTransactionsFilter + `
+		  AND transaction_type <> 'balance_correction'
+		  AND status IN ('pending', 'completed')
+		  AND created_at >= $2 AND created_at <= $3`
+
+	summary := &models.SpendingSummary{
+		From:                from,
+		To:                  to,
+		ByCategoryBreakdown: map[string]models.CategorySummary{},
+	}
+	err := r.db.QueryRowContext(ctx, query, userID, from, to).
+		Scan(&summary.TotalDebits, &summary.TotalCredits, &summary.TransactionCount)
+	if err != nil {
+		return nil, fmt.Errorf("error getting spending summary: %w", err)
+	}
+
+	breakdown, err := r.GetCategoryBreakdown(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, spending := range breakdown {
+		summary.ByCategoryBreakdown[spending.Category] = models.CategorySummary{Total: spending.Total, Count: spending.Count}
+	}
+
+	return summary, nil
+}
+
+// scanTransactions lee todas las filas de transacciones y cierra rows
+func scanTransactions(rows *sql.Rows) ([]*models.Transaction, error) {
+	defer rows.Close()
+
+	var transactions []*models.Transaction
+	for rows.Next() {
+		tx, err := scanTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning transaction: %w", err)
+		}
+		transactions = append(transactions, tx)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transactions: %w", err)
+	}
+
+	return transactions, nil
+}
@@ -0,0 +1,181 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/internal/tigerbeetle"
+	"banca-en-linea/backend/models"
+)
+
+// ErrTransactionNotReversible indica que la transacción no es una transferencia completada o que
+// no registra a su destinatario
+var ErrTransactionNotReversible = errors.New("transaction cannot be reversed")
+
+var (
+	// ErrReversalWindowExpired indica que la transferencia es más antigua que el plazo de reversión
+	ErrReversalWindowExpired = errors.New("reversal window has expired")
+	// ErrReversalNotApproved indica que el remitente no tiene una disputa aprobada sobre la transferencia
+	ErrReversalNotApproved = errors.New("reversal requires an approved dispute")
+)
+
+// TransactionService opera sobre las transacciones ya registradas
+type TransactionService struct {
+	transactionRepo TransactionRepository
+	disputeRepo     DisputeRepository
+	userService     *UserService
+	reversalWindow  time.Duration
+}
+
+// NewTransactionService crea una nueva instancia del servicio de transacciones. reversalWindow es
+// el plazo, contado desde la transferencia, dentro del cual puede revertirse.
+func NewTransactionService(transactionRepo TransactionRepository, disputeRepo DisputeRepository, userService *UserService, reversalWindow time.Duration) *TransactionService {
+	return &TransactionService{
+		transactionRepo: transactionRepo,
+		disputeRepo:     disputeRepo,
+		userService:     userService,
+		reversalWindow:  reversalWindow,
+	}
+}
+
+// Reverse devuelve al remitente el monto de una transferencia suya con una nueva transferencia en
+// sentido contrario. La comisión de la transferencia original no se devuelve. Las transferencias
+// de otros usuarios se reportan como inexistentes.
+//
+// El remitente no puede retirar el dinero por su cuenta: la reversión exige que cumplimiento haya
+// aprobado una disputa suya sobre la transferencia y que esta no sea más antigua que el plazo de
+// reversión.
+//
+// La reversión se registra como pendiente antes de mover el dinero, de modo que dos reversiones
+// simultáneas de la misma transferencia no puedan aplicarse ambas; si TigerBeetle la rechaza
+// queda fallida y puede intentarse de nuevo.
+func (s *TransactionService) Reverse(ctx context.Context, originalTxID, requesterID uuid.UUID) (*models.Transaction, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	original, err := s.transactionRepo.GetByID(ctx, originalTxID)
+	if err != nil {
+		return nil, err
+	}
+
+	sender, senderAccount, err := s.transferParty(ctx, original.FromAccountID, original.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	if sender == nil || sender.ID != requesterID {
+		return nil, ErrTransactionNotFound
+	}
+
+	if original.TransactionType != models.TransactionTypeTransfer || original.Status != models.TransactionStatusCompleted {
+		return nil, ErrTransactionNotReversible
+	}
+	if time.Since(original.CreatedAt) > s.reversalWindow {
+		return nil, ErrReversalWindowExpired
+	}
+
+	approved, err := s.disputeRepo.HasApproved(ctx, original.ID, requesterID)
+	if err != nil {
+		return nil, err
+	}
+	if !approved {
+		return nil, ErrReversalNotApproved
+	}
+
+	recipient, recipientAccount, err := s.transferParty(ctx, original.ToAccountID, original.RecipientUserID)
+	if err != nil {
+		return nil, err
+	}
+	if recipient == nil {
+		return nil, ErrTransactionNotReversible
+	}
+
+	if sender.IsFrozen || recipient.IsFrozen {
+		return nil, ErrAccountFrozen
+	}
+
+	tb := s.userService.tigerBeetleService
+	if tb != nil {
+		if err := s.userService.checkFunds(recipientAccount, uint64(original.Amount)); err != nil {
+			return nil, err
+		}
+	}
+
+	transferID, err := s.userService.nextTransferID(ctx, "reversal")
+	if err != nil {
+		return nil, err
+	}
+
+	reversal, err := s.transactionRepo.CreateReversal(ctx, &models.Transaction{
+		TigerBeetleTransferID: int64(transferID),
+		FromAccountID:         original.ToAccountID,
+		ToAccountID:           original.FromAccountID,
+		Amount:                original.Amount,
+		Currency:              original.Currency,
+		Status:                models.TransactionStatusPending,
+		CreatedBy:             &recipient.ID,
+		RecipientUserID:       &sender.ID,
+		RelatedTransactionID:  &original.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if tb == nil {
+		log.Printf("TigerBeetle disabled - would reverse transfer %s of %d from user %s to user %s", original.ID, original.Amount, recipient.Email, sender.Email)
+	} else {
+		err = tb.Transfer(uint64(recipientAccount.TigerBeetleAccountID), uint64(senderAccount.TigerBeetleAccountID), uint64(original.Amount), transferID)
+		if err != nil {
+			if statusErr := s.transactionRepo.UpdateStatus(ctx, reversal.ID, models.TransactionStatusFailed); statusErr != nil {
+				log.Printf("Error marking reversal %s as failed: %v", reversal.ID, statusErr)
+			}
+			if errors.Is(err, tigerbeetle.ErrExceedsCredits) {
+				return nil, s.userService.refreshedFundsError(recipientAccount.TigerBeetleAccountID, uint64(original.Amount))
+			}
+			return nil, fmt.Errorf("error processing reversal: %w", err)
+		}
+		s.userService.invalidateBalances(recipientAccount.TigerBeetleAccountID, senderAccount.TigerBeetleAccountID)
+		s.userService.publishBalance(recipient.ID, recipientAccount.TigerBeetleAccountID)
+		s.userService.publishBalance(sender.ID, senderAccount.TigerBeetleAccountID)
+	}
+
+	if err := s.transactionRepo.UpdateStatus(ctx, reversal.ID, models.TransactionStatusCompleted); err != nil {
+		// El dinero ya se movió: la reversión queda pendiente en PostgreSQL hasta corregirla
+		log.Printf("Error marking reversal %s as completed: %v", reversal.ID, err)
+	} else {
+		reversal.Status = models.TransactionStatusCompleted
+	}
+
+	return reversal, nil
+}
+
+// transferParty obtiene el usuario y la cuenta de un lado de una transferencia registrada: la
+// cuenta bancaria si la hay o, si fue la cuenta principal, la del usuario indicado. Retorna un
+// usuario nil si la transacción no identifica a ninguno de los dos.
+func (s *TransactionService) transferParty(ctx context.Context, accountID, userID *uuid.UUID) (*models.User, *models.BankAccount, error) {
+	if accountID != nil {
+		account, owner, err := s.userService.accountWithOwner(ctx, *accountID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return owner, account, nil
+	}
+
+	if userID == nil {
+		return nil, nil, nil
+	}
+
+	user, err := s.userService.userRepo.GetByID(ctx, *userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting user: %w", err)
+	}
+	account, err := s.userService.primaryAccount(user)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, account, nil
+}
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"sync/atomic"
+)
+
+// firstTransferID es el primer ID asignable; los IDs 1-10000 están reservados para uso manual
+const firstTransferID = 10001
+
+// TransferIDGenerator genera IDs únicos para las transferencias de TigerBeetle
+type TransferIDGenerator interface {
+	Next(ctx context.Context) (uint64, error)
+}
+
+// memoryTransferIDGenerator genera IDs secuenciales en memoria. Los IDs no sobreviven a un
+// reinicio, por lo que solo debe usarse en tests o cuando no hay base de datos.
+type memoryTransferIDGenerator struct {
+	next atomic.Uint64
+}
+
+// NewMemoryTransferIDGenerator crea un generador en memoria que comienza en firstTransferID
+func NewMemoryTransferIDGenerator() TransferIDGenerator {
+	g := &memoryTransferIDGenerator{}
+	g.next.Store(firstTransferID)
+	return g
+}
+
+// Next retorna el siguiente ID
+func (g *memoryTransferIDGenerator) Next(ctx context.Context) (uint64, error) {
+	return g.next.Add(1) - 1, nil
+}
+
+// randomTransferIDGenerator genera IDs aleatorios con crypto/rand. No depende de la base de
+// datos ni de estado en memoria, por lo que los IDs no se repiten tras un reinicio; un
+// duplicado improbable lo rechaza TigerBeetle y el servicio reintenta con otro ID.
+type randomTransferIDGenerator struct{}
+
+// NewRandomTransferIDGenerator crea un generador de IDs aleatorios
+func NewRandomTransferIDGenerator() TransferIDGenerator {
+	return randomTransferIDGenerator{}
+}
+
+// Next retorna un ID aleatorio fuera del rango reservado. El bit alto se limpia porque los IDs
+// derivados de llaves de idempotencia lo usan como marca.
+func (randomTransferIDGenerator) Next(ctx context.Context) (uint64, error) {
+	var b [8]byte
+	for {
+		if _, err := rand.Read(b[:]); err != nil {
+			return 0, fmt.Errorf("error generating transfer ID: %w", err)
+		}
+
+		id := binary.BigEndian.Uint64(b[:]) &^ (1 << 63)
+		if id >= firstTransferID {
+			return id, nil
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/models"
+)
+
+var (
+	// ErrTransferTemplateExists indica que el usuario ya tiene una plantilla con ese nombre
+	ErrTransferTemplateExists = errors.New("transfer template already exists")
+	// ErrTransferTemplateNotFound indica que la plantilla no existe o pertenece a otro usuario
+	ErrTransferTemplateNotFound = errors.New("transfer template not found")
+)
+
+// TransferTemplateRepository define la interfaz para las plantillas de transferencia
+type TransferTemplateRepository interface {
+	Create(ctx context.Context, template *models.TransferTemplate) (*models.TransferTemplate, error)
+	GetByID(ctx context.Context, id, ownerID uuid.UUID) (*models.TransferTemplate, error)
+	GetByOwner(ctx context.Context, ownerID uuid.UUID) ([]*models.TransferTemplate, error)
+	Delete(ctx context.Context, id, ownerID uuid.UUID) error
+}
+
+// transferTemplateColumns son las columnas que se leen al cargar una plantilla (en el orden de scanTransferTemplate)
+const transferTemplateColumns = `id, owner_user_id, recipient_user_id, amount_cents, COALESCE(description, ''), name, created_at`
+
+// scanTransferTemplate lee una plantilla a partir de una fila que contiene transferTemplateColumns
+func scanTransferTemplate(row rowScanner) (*models.TransferTemplate, error) {
+	template := &models.TransferTemplate{}
+	err := row.Scan(
+		&template.ID,
+		&template.OwnerUserID,
+		&template.RecipientUserID,
+		&template.AmountCents,
+		&template.Description,
+		&template.Name,
+		&template.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// transferTemplateRepository implementa TransferTemplateRepository
+type transferTemplateRepository struct {
+	db *sql.DB
+}
+
+// NewTransferTemplateRepository crea una nueva instancia del repositorio de plantillas de transferencia
+func NewTransferTemplateRepository(db *sql.DB) TransferTemplateRepository {
+	return &transferTemplateRepository{db: db}
+}
+
+// Create guarda una plantilla del usuario. Retorna ErrTransferTemplateExists si el nombre ya está en uso.
+func (r *transferTemplateRepository) Create(ctx context.Context, template *models.TransferTemplate) (*models.TransferTemplate, error) {
+	query := `
+		INSERT INTO transfer_templates (owner_user_id, recipient_user_id, amount_cents, description, name)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+		ON CONFLICT (owner_user_id, name) DO NOTHING
+		RETURNING ` + transferTemplateColumns
+
+	created, err := scanTransferTemplate(r.db.QueryRowContext(ctx, query,
+		template.OwnerUserID,
+		template.RecipientUserID,
+		template.AmountCents,
+		template.Description,
+		template.Name,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTransferTemplateExists
+		}
+		return nil, fmt.Errorf("error creating transfer template: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetByID obtiene una plantilla del usuario. Las plantillas de otros usuarios se reportan como inexistentes.
+func (r *transferTemplateRepository) GetByID(ctx context.Context, id, ownerID uuid.UUID) (*models.TransferTemplate, error) {
+	query := `
+		SELECT ` + transferTemplateColumns + `
+		FROM transfer_templates
+		WHERE id = $1 AND owner_user_id = $2`
+
+	template, err := scanTransferTemplate(r.db.QueryRowContext(ctx, query, id, ownerID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTransferTemplateNotFound
+		}
+		return nil, fmt.Errorf("error getting transfer template: %w", err)
+	}
+
+	return template, nil
+}
+
+// GetByOwner obtiene las plantillas del usuario ordenadas por nombre
+func (r *transferTemplateRepository) GetByOwner(ctx context.Context, ownerID uuid.UUID) ([]*models.TransferTemplate, error) {
+	query := `
+		SELECT ` + transferTemplateColumns + `
+		FROM transfer_templates
+		WHERE owner_user_id = $1
+		ORDER BY name`
+
+	rows, err := r.db.QueryContext(ctx, query, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing transfer templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := []*models.TransferTemplate{}
+	for rows.Next() {
+		template, err := scanTransferTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning transfer template: %w", err)
+		}
+		templates = append(templates, template)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transfer templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+// Delete elimina una plantilla del usuario
+func (r *transferTemplateRepository) Delete(ctx context.Context, id, ownerID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM transfer_templates WHERE id = $1 AND owner_user_id = $2`, id, ownerID)
+	if err != nil {
+		return fmt.Errorf("error deleting transfer template: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrTransferTemplateNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/models"
+)
+
+var (
+	// ErrSelfTransferTemplate indica que el destinatario de la plantilla es el mismo usuario
+	ErrSelfTransferTemplate = errors.New("cannot create a transfer template to yourself")
+	// ErrInvalidTransferTemplate indica un nombre vacío o demasiado largo o un monto en cero
+	ErrInvalidTransferTemplate = errors.New("invalid transfer template")
+)
+
+// TransferTemplateService guarda las transferencias frecuentes del usuario y las ejecuta
+type TransferTemplateService struct {
+	repo        TransferTemplateRepository
+	userRepo    UserRepository
+	userService *UserService
+}
+
+// NewTransferTemplateService crea una nueva instancia del servicio de plantillas de transferencia
+func NewTransferTemplateService(repo TransferTemplateRepository, userRepo UserRepository, userService *UserService) *TransferTemplateService {
+	return &TransferTemplateService{
+		repo:        repo,
+		userRepo:    userRepo,
+		userService: userService,
+	}
+}
+
+// Create guarda una plantilla del usuario hacia un destinatario activo
+func (s *TransferTemplateService) Create(ctx context.Context, ownerID uuid.UUID, req *models.CreateTransferTemplateRequest) (*models.TransferTemplate, error) {
+	if ownerID == req.RecipientUserID {
+		return nil, ErrSelfTransferTemplate
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > models.MaxTransferTemplateNameLength || req.Amount == 0 {
+		return nil, ErrInvalidTransferTemplate
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	recipient, err := s.userRepo.GetByID(ctx, req.RecipientUserID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrRecipientNotFound
+		}
+		return nil, fmt.Errorf("error getting recipient: %w", err)
+	}
+	if !recipient.IsActive {
+		return nil, ErrRecipientInactive
+	}
+
+	return s.repo.Create(ctx, &models.TransferTemplate{
+		OwnerUserID:     ownerID,
+		RecipientUserID: req.RecipientUserID,
+		AmountCents:     int64(req.Amount),
+		Description:     strings.TrimSpace(req.Description),
+		Name:            name,
+	})
+}
+
+// Get obtiene una plantilla del usuario
+func (s *TransferTemplateService) Get(ctx context.Context, ownerID, templateID uuid.UUID) (*models.TransferTemplate, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	return s.repo.GetByID(ctx, templateID, ownerID)
+}
+
+// List obtiene las plantillas guardadas por el usuario
+func (s *TransferTemplateService) List(ctx context.Context, ownerID uuid.UUID) ([]*models.TransferTemplate, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	return s.repo.GetByOwner(ctx, ownerID)
+}
+
+// Delete elimina una plantilla del usuario
+func (s *TransferTemplateService) Delete(ctx context.Context, ownerID, templateID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	return s.repo.Delete(ctx, templateID, ownerID)
+}
+
+// Execute realiza la transferencia guardada en una plantilla del usuario y retorna la comisión
+// cobrada. Las validaciones de la transferencia (fondos, límite diario, cuenta congelada) son las
+// mismas que las de una transferencia llenada a mano.
+func (s *TransferTemplateService) Execute(ctx context.Context, template *models.TransferTemplate) (uint64, error) {
+	return s.userService.TransferBetweenUsers(ctx, template.OwnerUserID, template.RecipientUserID, uint64(template.AmountCents))
+}
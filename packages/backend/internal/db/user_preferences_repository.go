@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/models"
+)
+
+// UserPreferencesRepository define la interfaz para las preferencias de usuario en la base de datos
+type UserPreferencesRepository interface {
+	Get(ctx context.Context, userID uuid.UUID) (*models.UserPreferences, error)
+	Upsert(ctx context.Context, prefs *models.UserPreferences) (*models.UserPreferences, error)
+}
+
+// userPreferencesColumns son las columnas que se leen al cargar preferencias (en el orden de scanUserPreferences)
+const userPreferencesColumns = `user_id, language, timezone, email_on_deposit, email_on_withdrawal,
+		       email_on_transfer, email_on_login, updated_at`
+
+// scanUserPreferences lee las preferencias a partir de una fila que contiene userPreferencesColumns
+func scanUserPreferences(row rowScanner) (*models.UserPreferences, error) {
+	prefs := &models.UserPreferences{}
+	err := row.Scan(
+		&prefs.UserID,
+		&prefs.Language,
+		&prefs.Timezone,
+		&prefs.EmailOnDeposit,
+		&prefs.EmailOnWithdrawal,
+		&prefs.EmailOnTransfer,
+		&prefs.EmailOnLogin,
+		&prefs.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// userPreferencesRepository implementa UserPreferencesRepository
+type userPreferencesRepository struct {
+	db *sql.DB
+}
+
+// NewUserPreferencesRepository crea una nueva instancia del repositorio de preferencias
+func NewUserPreferencesRepository(db *sql.DB) UserPreferencesRepository {
+	return &userPreferencesRepository{db: db}
+}
+
+// Get obtiene las preferencias de un usuario. Si el usuario nunca las guardó
+// se retornan los valores por defecto.
+func (r *userPreferencesRepository) Get(ctx context.Context, userID uuid.UUID) (*models.UserPreferences, error) {
+	query := `
+		SELECT ` + userPreferencesColumns + `
+		FROM user_preferences
+		WHERE user_id = $1`
+
+	prefs, err := scanUserPreferences(r.db.QueryRowContext(ctx, query, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.DefaultUserPreferences(userID), nil
+		}
+		return nil, fmt.Errorf("error getting user preferences: %w", err)
+	}
+
+	return prefs, nil
+}
+
+// Upsert crea o reemplaza las preferencias de un usuario
+func (r *userPreferencesRepository) Upsert(ctx context.Context, prefs *models.UserPreferences) (*models.UserPreferences, error) {
+	query := `
+		INSERT INTO user_preferences (user_id, language, timezone, email_on_deposit, email_on_withdrawal,
+		                              email_on_transfer, email_on_login, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			language = EXCLUDED.language,
+			timezone = EXCLUDED.timezone,
+			email_on_deposit = EXCLUDED.email_on_deposit,
+			email_on_withdrawal = EXCLUDED.email_on_withdrawal,
+			email_on_transfer = EXCLUDED.email_on_transfer,
+			email_on_login = EXCLUDED.email_on_login,
+			updated_at = NOW()
+		RETURNING ` + userPreferencesColumns
+
+	saved, err := scanUserPreferences(r.db.QueryRowContext(
+		ctx,
+		query,
+		prefs.UserID,
+		prefs.Language,
+		prefs.Timezone,
+		prefs.EmailOnDeposit,
+		prefs.EmailOnWithdrawal,
+		prefs.EmailOnTransfer,
+		prefs.EmailOnLogin,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("error saving user preferences: %w", err)
+	}
+
+	return saved, nil
+}
@@ -1,29 +1,108 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"golang.org/x/crypto/bcrypt"
 
+	apperrors "banca-en-linea/backend/internal/errors"
+	"banca-en-linea/backend/internal/validation"
 	"banca-en-linea/backend/models"
 )
 
+var (
+	// ErrConcurrentLogin indica que otro inicio de sesión del mismo usuario está en curso
+	ErrConcurrentLogin = errors.New("concurrent login in progress")
+	// ErrUserNotFound indica que el usuario no existe o fue eliminado
+	ErrUserNotFound = apperrors.ErrUserNotFound
+)
+
 // UserRepository define la interfaz para operaciones de usuario en la base de datos
 type UserRepository interface {
-	Create(user *models.CreateUserRequest) (*models.User, error)
-	GetByID(id uuid.UUID) (*models.User, error)
-	GetByEmail(email string) (*models.User, error)
-	Update(id uuid.UUID, updates *models.UpdateUserRequest) (*models.User, error)
-	Delete(id uuid.UUID) error
-	List(limit, offset int) ([]*models.User, error)
-	UpdateTigerBeetleAccountID(userID uuid.UUID, accountID int64) error
+	Create(ctx context.Context, user *models.CreateUserRequest) (*models.User, error)
+	CreateWithAttributes(ctx context.Context, user *models.CreateUserRequest, roles []string, kycStatus string) (*models.User, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	GetByEmailForUpdate(ctx context.Context, tx *sql.Tx, email string) (*models.User, error)
+	BeginTx(ctx context.Context) (*sql.Tx, error)
+	Update(ctx context.Context, id uuid.UUID, updates *models.UpdateUserRequest) (*models.User, error)
+	UpdateFlags(ctx context.Context, id uuid.UUID, flags *models.UpdateUserFlagsRequest) (*models.User, error)
+	UpdateKYC(ctx context.Context, id uuid.UUID, status string, documentRef *string) (*models.User, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	HardDelete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, afterID *uuid.UUID, limit int) ([]*models.User, error)
+	Search(ctx context.Context, query string, limit int, afterID *uuid.UUID) ([]*models.User, error)
+	UpdateTigerBeetleAccountID(ctx context.Context, userID uuid.UUID, accountID int64) error
+	MarkEmailVerified(ctx context.Context, userID uuid.UUID) error
+	Freeze(ctx context.Context, userID uuid.UUID, reason string) error
+	Unfreeze(ctx context.Context, userID uuid.UUID) error
+	UpdatePassword(ctx context.Context, userID uuid.UUID, hashedPassword string) error
+	UpdatePhone(ctx context.Context, userID uuid.UUID, phone string) error
+	UpdateDateOfBirth(ctx context.Context, userID uuid.UUID, dateOfBirth time.Time) error
+	UpdateLastLogin(ctx context.Context, userID uuid.UUID) error
+	SetTOTPSecret(ctx context.Context, userID uuid.UUID, encryptedSecret string) error
+	EnableTOTP(ctx context.Context, userID uuid.UUID) error
 	VerifyPassword(hashedPassword, password string) error
 }
 
+// userColumns son las columnas que se leen al cargar un usuario (en el orden de scanUser)
+const userColumns = `id, email, password_hash, first_name, last_name, phone, date_of_birth,
+		       tigerbeetle_account_id, roles, kyc_status, created_at, updated_at, is_active, email_verified,
+		       totp_secret, totp_enabled, daily_transfer_limit_cents, daily_withdrawal_limit_cents,
+		       is_frozen, frozen_at, frozen_reason, last_login_at, kyc_document_ref, kyc_reviewed_at`
+
+// rowScanner es implementado por *sql.Row y *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanUser lee un usuario a partir de una fila que contiene userColumns
+func scanUser(row rowScanner) (*models.User, error) {
+	user := &models.User{}
+	err := row.Scan(
+		&user.ID,
+		&user.Email,
+		&user.PasswordHash,
+		&user.FirstName,
+		&user.LastName,
+		&user.Phone,
+		&user.DateOfBirth,
+		&user.TigerBeetleAccountID,
+		pq.Array(&user.Roles),
+		&user.KYCStatus,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&user.IsActive,
+		&user.EmailVerified,
+		&user.TOTPSecret,
+		&user.TOTPEnabled,
+		&user.DailyTransferLimitCents,
+		&user.DailyWithdrawalLimitCents,
+		&user.IsFrozen,
+		&user.FrozenAt,
+		&user.FrozenReason,
+		&user.LastLoginAt,
+		&user.KYCDocumentRef,
+		&user.KYCReviewedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// queryRower es implementado por *sql.DB y *sql.Tx
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
 // userRepository implementa UserRepository
 type userRepository struct {
 	db *sql.DB
@@ -35,89 +114,101 @@ func NewUserRepository(db *sql.DB) UserRepository {
 }
 
 // Create crea un nuevo usuario en la base de datos
-func (r *userRepository) Create(req *models.CreateUserRequest) (*models.User, error) {
+func (r *userRepository) Create(ctx context.Context, req *models.CreateUserRequest) (*models.User, error) {
+	user, err := r.insert(ctx, r.db, req)
+	if err != nil {
+		return nil, fmt.Errorf("error creating user: %w", err)
+	}
+
+	return user, nil
+}
+
+// CreateWithAttributes crea un nuevo usuario y asigna sus roles y estado KYC
+// dentro de una misma transacción de base de datos
+func (r *userRepository) CreateWithAttributes(ctx context.Context, req *models.CreateUserRequest, roles []string, kycStatus string) (*models.User, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	user, err := r.insert(ctx, tx, req)
+	if err != nil {
+		return nil, fmt.Errorf("error creating user: %w", err)
+	}
+
+	query := `
+		UPDATE users
+		SET roles = $1, kyc_status = $2, updated_at = NOW()
+		WHERE id = $3
+		RETURNING ` + userColumns
+
+	user, err = scanUser(tx.QueryRowContext(ctx, query, pq.Array(roles), kycStatus, user.ID))
+	if err != nil {
+		return nil, fmt.Errorf("error setting user attributes: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return user, nil
+}
+
+// insert inserta un nuevo usuario usando la conexión o transacción indicada
+func (r *userRepository) insert(ctx context.Context, q queryRower, req *models.CreateUserRequest) (*models.User, error) {
 	// Hash de la contraseña
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, fmt.Errorf("error hashing password: %w", err)
 	}
 
-	user := &models.User{
-		ID:            uuid.New(),
-		Email:         req.Email,
-		PasswordHash:  string(hashedPassword),
-		FirstName:     req.FirstName,
-		LastName:      req.LastName,
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
-		IsActive:      true,
-		EmailVerified: false,
+	var phone *string
+	if req.Phone != nil {
+		normalized := validation.NormalizePhone(*req.Phone)
+		phone = &normalized
 	}
 
+	now := time.Now()
 	query := `
-		INSERT INTO users (id, email, password_hash, first_name, last_name, created_at, updated_at, is_active, email_verified)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		RETURNING id, email, first_name, last_name, phone, date_of_birth, tigerbeetle_account_id, created_at, updated_at, is_active, email_verified`
+		INSERT INTO users (id, email, password_hash, first_name, last_name, phone, date_of_birth, created_at, updated_at, is_active, email_verified)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING ` + userColumns
 
-	err = r.db.QueryRow(
+	return scanUser(q.QueryRowContext(
+		ctx,
 		query,
-		user.ID,
-		user.Email,
-		user.PasswordHash,
-		user.FirstName,
-		user.LastName,
-		user.CreatedAt,
-		user.UpdatedAt,
-		user.IsActive,
-		user.EmailVerified,
-	).Scan(
-		&user.ID,
-		&user.Email,
-		&user.FirstName,
-		&user.LastName,
-		&user.Phone,
-		&user.DateOfBirth,
-		&user.TigerBeetleAccountID,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-		&user.IsActive,
-		&user.EmailVerified,
-	)
-
-	if err != nil {
-		return nil, fmt.Errorf("error creating user: %w", err)
-	}
+		uuid.New(),
+		normalizeEmail(req.Email),
+		string(hashedPassword),
+		req.FirstName,
+		req.LastName,
+		phone,
+		req.DateOfBirth,
+		now,
+		now,
+		true,
+		false,
+	))
+}
 
-	return user, nil
+// normalizeEmail retorna el email en minúsculas y sin espacios alrededor. Los emails se guardan
+// normalizados para que la unicidad y las búsquedas no distingan mayúsculas.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
 }
 
 // GetByID obtiene un usuario por su ID
-func (r *userRepository) GetByID(id uuid.UUID) (*models.User, error) {
-	user := &models.User{}
+func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	query := `
-		SELECT id, email, password_hash, first_name, last_name, phone, date_of_birth, 
-		       tigerbeetle_account_id, created_at, updated_at, is_active, email_verified
-		FROM users 
+		SELECT ` + userColumns + `
+		FROM users
 		WHERE id = $1`
 
-	err := r.db.QueryRow(query, id).Scan(
-		&user.ID,
-		&user.Email,
-		&user.PasswordHash,
-		&user.FirstName,
-		&user.LastName,
-		&user.Phone,
-		&user.DateOfBirth,
-		&user.TigerBeetleAccountID,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-		&user.IsActive,
-		&user.EmailVerified,
-	)
-
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("error getting user: %w", err)
 	}
@@ -125,33 +216,17 @@ func (r *userRepository) GetByID(id uuid.UUID) (*models.User, error) {
 	return user, nil
 }
 
-// GetByEmail obtiene un usuario por su email
-func (r *userRepository) GetByEmail(email string) (*models.User, error) {
-	user := &models.User{}
+// GetByEmail obtiene un usuario por su email, sin distinguir mayúsculas
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	query := `
-		SELECT id, email, password_hash, first_name, last_name, phone, date_of_birth,
-		       tigerbeetle_account_id, created_at, updated_at, is_active, email_verified
-		FROM users 
+		SELECT ` + userColumns + `
+		FROM users
 		WHERE email = $1`
 
-	err := r.db.QueryRow(query, email).Scan(
-		&user.ID,
-		&user.Email,
-		&user.PasswordHash,
-		&user.FirstName,
-		&user.LastName,
-		&user.Phone,
-		&user.DateOfBirth,
-		&user.TigerBeetleAccountID,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-		&user.IsActive,
-		&user.EmailVerified,
-	)
-
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, normalizeEmail(email)))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("error getting user: %w", err)
 	}
@@ -159,8 +234,43 @@ func (r *userRepository) GetByEmail(email string) (*models.User, error) {
 	return user, nil
 }
 
+// GetByEmailForUpdate obtiene un usuario por su email bloqueando la fila dentro de la transacción.
+// Si la fila ya está bloqueada por otro inicio de sesión retorna ErrConcurrentLogin.
+func (r *userRepository) GetByEmailForUpdate(ctx context.Context, tx *sql.Tx, email string) (*models.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE email = $1
+		FOR UPDATE SKIP LOCKED`
+
+	email = normalizeEmail(email)
+	user, err := scanUser(tx.QueryRowContext(ctx, query, email))
+	if err == nil {
+		return user, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("error getting user: %w", err)
+	}
+
+	// SKIP LOCKED no distingue entre una fila bloqueada y una inexistente
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`, email).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("error checking user: %w", err)
+	}
+	if exists {
+		return nil, ErrConcurrentLogin
+	}
+
+	return nil, ErrUserNotFound
+}
+
+// BeginTx inicia una transacción de base de datos
+func (r *userRepository) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	return r.db.BeginTx(ctx, nil)
+}
+
 // Update actualiza un usuario existente
-func (r *userRepository) Update(id uuid.UUID, updates *models.UpdateUserRequest) (*models.User, error) {
+func (r *userRepository) Update(ctx context.Context, id uuid.UUID, updates *models.UpdateUserRequest) (*models.User, error) {
 	// Construir la consulta dinámicamente basada en los campos a actualizar
 	setParts := []string{"updated_at = NOW()"}
 	args := []interface{}{}
@@ -168,7 +278,7 @@ func (r *userRepository) Update(id uuid.UUID, updates *models.UpdateUserRequest)
 
 	if updates.Email != nil {
 		setParts = append(setParts, fmt.Sprintf("email = $%d", argIndex))
-		args = append(args, *updates.Email)
+		args = append(args, normalizeEmail(*updates.Email))
 		argIndex++
 	}
 
@@ -188,32 +298,19 @@ func (r *userRepository) Update(id uuid.UUID, updates *models.UpdateUserRequest)
 	args = append(args, id)
 
 	query := fmt.Sprintf(`
-		UPDATE users 
+		UPDATE users
 		SET %s
 		WHERE id = $%d
-		RETURNING id, email, first_name, last_name, phone, date_of_birth, tigerbeetle_account_id, created_at, updated_at, is_active, email_verified`,
+		RETURNING %s`,
 		strings.Join(setParts, ", "),
 		argIndex,
+		userColumns,
 	)
 
-	user := &models.User{}
-	err := r.db.QueryRow(query, args...).Scan(
-		&user.ID,
-		&user.Email,
-		&user.FirstName,
-		&user.LastName,
-		&user.Phone,
-		&user.DateOfBirth,
-		&user.TigerBeetleAccountID,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-		&user.IsActive,
-		&user.EmailVerified,
-	)
-
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, args...))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("error updating user: %w", err)
 	}
@@ -221,14 +318,58 @@ func (r *userRepository) Update(id uuid.UUID, updates *models.UpdateUserRequest)
 	return user, nil
 }
 
+// UpdateFlags actualiza los indicadores de cuenta (activa, email verificado) de un usuario
+func (r *userRepository) UpdateFlags(ctx context.Context, id uuid.UUID, flags *models.UpdateUserFlagsRequest) (*models.User, error) {
+	query := `
+		UPDATE users
+		SET is_active = COALESCE($1, is_active),
+		    email_verified = COALESCE($2, email_verified),
+		    updated_at = NOW()
+		WHERE id = $3 AND deleted_at IS NULL
+		RETURNING ` + userColumns
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, flags.IsActive, flags.EmailVerified, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("error updating user flags: %w", err)
+	}
+
+	return user, nil
+}
+
+// UpdateKYC cambia el estado KYC del usuario y, si se indica, la referencia a su documento.
+// kyc_reviewed_at se fija al aprobar o rechazar y se limpia en los demás estados.
+func (r *userRepository) UpdateKYC(ctx context.Context, id uuid.UUID, status string, documentRef *string) (*models.User, error) {
+	query := `
+		UPDATE users
+		SET kyc_status = $1,
+		    kyc_document_ref = COALESCE($2, kyc_document_ref),
+		    kyc_reviewed_at = CASE WHEN $1 IN ('approved', 'rejected') THEN NOW() ELSE NULL END,
+		    updated_at = NOW()
+		WHERE id = $3 AND deleted_at IS NULL
+		RETURNING ` + userColumns
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, status, documentRef, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("error updating user kyc: %w", err)
+	}
+
+	return user, nil
+}
+
 // Delete realiza un soft delete del usuario
-func (r *userRepository) Delete(id uuid.UUID) error {
+func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `
-		UPDATE users 
+		UPDATE users
 		SET deleted_at = NOW(), updated_at = NOW()
 		WHERE id = $1 AND deleted_at IS NULL`
 
-	result, err := r.db.Exec(query, id)
+	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("error deleting user: %w", err)
 	}
@@ -239,21 +380,76 @@ func (r *userRepository) Delete(id uuid.UUID) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("user not found")
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// HardDelete elimina definitivamente al usuario (derecho al olvido). Las transacciones no se
+// borran porque forman parte del historial de las contrapartes: se desvinculan de las cuentas del
+// usuario y se borra su descripción. También se eliminan sus eventos de auditoría, sus cuentas
+// bancarias y, por cascada, sus tokens, preferencias, beneficiarios y notificaciones.
+func (r *userRepository) HardDelete(ctx context.Context, id uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	statements := []struct {
+		query string
+		what  string
+	}{
+		{`UPDATE transactions
+		  SET from_account_id = NULL, description = NULL, updated_at = NOW()
+		  WHERE from_account_id IN (SELECT id FROM bank_accounts WHERE user_id = $1)`, "anonymizing outgoing transactions"},
+		{`UPDATE transactions
+		  SET to_account_id = NULL, description = NULL, updated_at = NOW()
+		  WHERE to_account_id IN (SELECT id FROM bank_accounts WHERE user_id = $1)`, "anonymizing incoming transactions"},
+		{`UPDATE transactions SET created_by = NULL WHERE created_by = $1`, "anonymizing created transactions"},
+		{`DELETE FROM audit_logs WHERE actor_user_id = $1 OR (entity_type = 'user' AND entity_id = $1::text)`, "deleting audit logs"},
+		{`DELETE FROM refresh_tokens WHERE user_id = $1`, "deleting refresh tokens"},
+		{`DELETE FROM bank_accounts WHERE user_id = $1`, "deleting bank accounts"},
+	}
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt.query, id); err != nil {
+			return fmt.Errorf("error %s: %w", stmt.what, err)
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting user: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
 	}
 
 	return nil
 }
 
-// List obtiene una lista paginada de usuarios
-func (r *userRepository) List(limit, offset int) ([]*models.User, error) {
+// List obtiene una página de usuarios ordenada por ID. afterID es el ID del último usuario de la
+// página anterior (nil para la primera página); la paginación por llave evita recorrer las filas
+// que OFFSET tendría que saltar.
+func (r *userRepository) List(ctx context.Context, afterID *uuid.UUID, limit int) ([]*models.User, error) {
 	query := `
-		SELECT id, email, first_name, last_name, phone, date_of_birth, tigerbeetle_account_id, created_at, updated_at, is_active, email_verified
-		FROM users 
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2`
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE ($1::uuid IS NULL OR id > $1)
+		ORDER BY id
+		LIMIT $2`
 
-	rows, err := r.db.Query(query, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, afterID, limit)
 	if err != nil {
 		return nil, fmt.Errorf("error listing users: %w", err)
 	}
@@ -261,20 +457,45 @@ func (r *userRepository) List(limit, offset int) ([]*models.User, error) {
 
 	var users []*models.User
 	for rows.Next() {
-		user := &models.User{}
-		err := rows.Scan(
-			&user.ID,
-			&user.Email,
-			&user.FirstName,
-			&user.LastName,
-			&user.Phone,
-			&user.DateOfBirth,
-			&user.TigerBeetleAccountID,
-			&user.CreatedAt,
-			&user.UpdatedAt,
-			&user.IsActive,
-			&user.EmailVerified,
-		)
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return users, nil
+}
+
+// searchEscaper escapa los comodines de LIKE para que la búsqueda sea literal
+var searchEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Search obtiene una página de usuarios cuyo email, nombre o apellido contienen query (sin
+// distinguir mayúsculas), ordenada por ID. La expresión coincide con el índice idx_users_search.
+func (r *userRepository) Search(ctx context.Context, query string, limit int, afterID *uuid.UUID) ([]*models.User, error) {
+	sqlQuery := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE lower(email || ' ' || first_name || ' ' || last_name) LIKE $1
+		  AND ($2::uuid IS NULL OR id > $2)
+		ORDER BY id
+		LIMIT $3`
+
+	pattern := "%" + searchEscaper.Replace(strings.ToLower(query)) + "%"
+
+	rows, err := r.db.QueryContext(ctx, sqlQuery, pattern, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error searching users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		user, err := scanUser(rows)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning user: %w", err)
 		}
@@ -289,13 +510,13 @@ func (r *userRepository) List(limit, offset int) ([]*models.User, error) {
 }
 
 // UpdateTigerBeetleAccountID actualiza el ID de cuenta de TigerBeetle para un usuario
-func (r *userRepository) UpdateTigerBeetleAccountID(userID uuid.UUID, accountID int64) error {
+func (r *userRepository) UpdateTigerBeetleAccountID(ctx context.Context, userID uuid.UUID, accountID int64) error {
 	query := `
-		UPDATE users 
+		UPDATE users
 		SET tigerbeetle_account_id = $1, updated_at = NOW()
 		WHERE id = $2 AND deleted_at IS NULL`
 
-	result, err := r.db.Exec(query, accountID, userID)
+	result, err := r.db.ExecContext(ctx, query, accountID, userID)
 	if err != nil {
 		return fmt.Errorf("error updating tigerbeetle account id: %w", err)
 	}
@@ -306,7 +527,214 @@ func (r *userRepository) UpdateTigerBeetleAccountID(userID uuid.UUID, accountID
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("user not found")
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// Freeze congela la cuenta del usuario registrando el motivo
+func (r *userRepository) Freeze(ctx context.Context, userID uuid.UUID, reason string) error {
+	query := `
+		UPDATE users
+		SET is_frozen = true, frozen_at = NOW(), frozen_reason = $1, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL`
+
+	return r.execUserUpdate(ctx, "freezing user", query, reason, userID)
+}
+
+// Unfreeze descongela la cuenta del usuario y limpia el motivo
+func (r *userRepository) Unfreeze(ctx context.Context, userID uuid.UUID) error {
+	query := `
+		UPDATE users
+		SET is_frozen = false, frozen_at = NULL, frozen_reason = NULL, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL`
+
+	return r.execUserUpdate(ctx, "unfreezing user", query, userID)
+}
+
+// execUserUpdate ejecuta una actualización sobre un único usuario y retorna ErrUserNotFound si no existe
+func (r *userRepository) execUserUpdate(ctx context.Context, action, query string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("error %s: %w", action, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// MarkEmailVerified marca el email del usuario como verificado
+func (r *userRepository) MarkEmailVerified(ctx context.Context, userID uuid.UUID) error {
+	query := `
+		UPDATE users
+		SET email_verified = TRUE, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("error marking email verified: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// UpdateLastLogin registra el momento actual como último inicio de sesión del usuario
+func (r *userRepository) UpdateLastLogin(ctx context.Context, userID uuid.UUID) error {
+	query := `
+		UPDATE users
+		SET last_login_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("error updating last login: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// UpdatePassword reemplaza el hash de la contraseña del usuario
+func (r *userRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, hashedPassword string) error {
+	query := `
+		UPDATE users
+		SET password_hash = $1, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, hashedPassword, userID)
+	if err != nil {
+		return fmt.Errorf("error updating password: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// UpdatePhone reemplaza el teléfono del usuario; se guarda sin espacios ni guiones
+func (r *userRepository) UpdatePhone(ctx context.Context, userID uuid.UUID, phone string) error {
+	query := `
+		UPDATE users
+		SET phone = $1, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, validation.NormalizePhone(phone), userID)
+	if err != nil {
+		return fmt.Errorf("error updating phone: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// UpdateDateOfBirth reemplaza la fecha de nacimiento del usuario
+func (r *userRepository) UpdateDateOfBirth(ctx context.Context, userID uuid.UUID, dateOfBirth time.Time) error {
+	query := `
+		UPDATE users
+		SET date_of_birth = $1, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, dateOfBirth, userID)
+	if err != nil {
+		return fmt.Errorf("error updating date of birth: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// SetTOTPSecret guarda un nuevo secreto TOTP cifrado; TOTP queda desactivado hasta verificarlo
+func (r *userRepository) SetTOTPSecret(ctx context.Context, userID uuid.UUID, encryptedSecret string) error {
+	query := `
+		UPDATE users
+		SET totp_secret = $1, totp_enabled = FALSE, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, encryptedSecret, userID)
+	if err != nil {
+		return fmt.Errorf("error setting totp secret: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+// EnableTOTP activa TOTP para un usuario que ya tiene secreto configurado
+func (r *userRepository) EnableTOTP(ctx context.Context, userID uuid.UUID) error {
+	query := `
+		UPDATE users
+		SET totp_enabled = TRUE, updated_at = NOW()
+		WHERE id = $1 AND totp_secret IS NOT NULL AND deleted_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("error enabling totp: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return ErrUserNotFound
 	}
 
 	return nil
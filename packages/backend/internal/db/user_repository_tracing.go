@@ -0,0 +1,212 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/internal/tracing"
+	"banca-en-linea/backend/models"
+)
+
+// tracedUserRepository envuelve un UserRepository creando un span por cada consulta
+type tracedUserRepository struct {
+	repo UserRepository
+}
+
+// NewTracedUserRepository crea un UserRepository que registra cada llamada como un span hijo
+// del span presente en el contexto
+func NewTracedUserRepository(repo UserRepository) UserRepository {
+	return &tracedUserRepository{repo: repo}
+}
+
+// Create crea un usuario dentro de un span
+func (r *tracedUserRepository) Create(ctx context.Context, req *models.CreateUserRequest) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.Create")
+	user, err := r.repo.Create(ctx, req)
+	tracing.End(span, err)
+	return user, err
+}
+
+// CreateWithAttributes crea un usuario con roles y estado KYC dentro de un span
+func (r *tracedUserRepository) CreateWithAttributes(ctx context.Context, req *models.CreateUserRequest, roles []string, kycStatus string) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.CreateWithAttributes")
+	user, err := r.repo.CreateWithAttributes(ctx, req, roles, kycStatus)
+	tracing.End(span, err)
+	return user, err
+}
+
+// GetByID obtiene un usuario por ID dentro de un span
+func (r *tracedUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.GetByID")
+	user, err := r.repo.GetByID(ctx, id)
+	tracing.End(span, err)
+	return user, err
+}
+
+// GetByEmail obtiene un usuario por email dentro de un span
+func (r *tracedUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.GetByEmail")
+	user, err := r.repo.GetByEmail(ctx, email)
+	tracing.End(span, err)
+	return user, err
+}
+
+// GetByEmailForUpdate obtiene y bloquea un usuario por email dentro de un span
+func (r *tracedUserRepository) GetByEmailForUpdate(ctx context.Context, tx *sql.Tx, email string) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.GetByEmailForUpdate")
+	user, err := r.repo.GetByEmailForUpdate(ctx, tx, email)
+	tracing.End(span, err)
+	return user, err
+}
+
+// BeginTx inicia una transacción dentro de un span
+func (r *tracedUserRepository) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.BeginTx")
+	tx, err := r.repo.BeginTx(ctx)
+	tracing.End(span, err)
+	return tx, err
+}
+
+// Update actualiza un usuario dentro de un span
+func (r *tracedUserRepository) Update(ctx context.Context, id uuid.UUID, updates *models.UpdateUserRequest) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.Update")
+	user, err := r.repo.Update(ctx, id, updates)
+	tracing.End(span, err)
+	return user, err
+}
+
+// UpdateFlags actualiza los roles y estados de un usuario dentro de un span
+func (r *tracedUserRepository) UpdateFlags(ctx context.Context, id uuid.UUID, flags *models.UpdateUserFlagsRequest) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.UpdateFlags")
+	user, err := r.repo.UpdateFlags(ctx, id, flags)
+	tracing.End(span, err)
+	return user, err
+}
+
+// UpdateKYC actualiza el estado KYC de un usuario dentro de un span
+func (r *tracedUserRepository) UpdateKYC(ctx context.Context, id uuid.UUID, status string, documentRef *string) (*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.UpdateKYC")
+	user, err := r.repo.UpdateKYC(ctx, id, status, documentRef)
+	tracing.End(span, err)
+	return user, err
+}
+
+// Delete elimina un usuario dentro de un span
+func (r *tracedUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.Delete")
+	err := r.repo.Delete(ctx, id)
+	tracing.End(span, err)
+	return err
+}
+
+// HardDelete elimina definitivamente un usuario dentro de un span
+func (r *tracedUserRepository) HardDelete(ctx context.Context, id uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.HardDelete")
+	err := r.repo.HardDelete(ctx, id)
+	tracing.End(span, err)
+	return err
+}
+
+// List lista usuarios dentro de un span
+func (r *tracedUserRepository) List(ctx context.Context, afterID *uuid.UUID, limit int) ([]*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.List")
+	users, err := r.repo.List(ctx, afterID, limit)
+	tracing.End(span, err)
+	return users, err
+}
+
+// Search busca usuarios dentro de un span
+func (r *tracedUserRepository) Search(ctx context.Context, query string, limit int, afterID *uuid.UUID) ([]*models.User, error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.Search")
+	users, err := r.repo.Search(ctx, query, limit, afterID)
+	tracing.End(span, err)
+	return users, err
+}
+
+// UpdateTigerBeetleAccountID guarda la cuenta TigerBeetle de un usuario dentro de un span
+func (r *tracedUserRepository) UpdateTigerBeetleAccountID(ctx context.Context, userID uuid.UUID, accountID int64) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.UpdateTigerBeetleAccountID")
+	err := r.repo.UpdateTigerBeetleAccountID(ctx, userID, accountID)
+	tracing.End(span, err)
+	return err
+}
+
+// MarkEmailVerified marca el email de un usuario como verificado dentro de un span
+func (r *tracedUserRepository) MarkEmailVerified(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.MarkEmailVerified")
+	err := r.repo.MarkEmailVerified(ctx, userID)
+	tracing.End(span, err)
+	return err
+}
+
+// Freeze congela la cuenta de un usuario dentro de un span
+func (r *tracedUserRepository) Freeze(ctx context.Context, userID uuid.UUID, reason string) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.Freeze")
+	err := r.repo.Freeze(ctx, userID, reason)
+	tracing.End(span, err)
+	return err
+}
+
+// Unfreeze descongela la cuenta de un usuario dentro de un span
+func (r *tracedUserRepository) Unfreeze(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.Unfreeze")
+	err := r.repo.Unfreeze(ctx, userID)
+	tracing.End(span, err)
+	return err
+}
+
+// UpdateLastLogin registra el último inicio de sesión de un usuario dentro de un span
+func (r *tracedUserRepository) UpdateLastLogin(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.UpdateLastLogin")
+	err := r.repo.UpdateLastLogin(ctx, userID)
+	tracing.End(span, err)
+	return err
+}
+
+// UpdatePassword actualiza la contraseña de un usuario dentro de un span
+func (r *tracedUserRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, hashedPassword string) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.UpdatePassword")
+	err := r.repo.UpdatePassword(ctx, userID, hashedPassword)
+	tracing.End(span, err)
+	return err
+}
+
+// UpdatePhone actualiza el teléfono de un usuario dentro de un span
+func (r *tracedUserRepository) UpdatePhone(ctx context.Context, userID uuid.UUID, phone string) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.UpdatePhone")
+	err := r.repo.UpdatePhone(ctx, userID, phone)
+	tracing.End(span, err)
+	return err
+}
+
+// UpdateDateOfBirth actualiza la fecha de nacimiento de un usuario dentro de un span
+func (r *tracedUserRepository) UpdateDateOfBirth(ctx context.Context, userID uuid.UUID, dateOfBirth time.Time) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.UpdateDateOfBirth")
+	err := r.repo.UpdateDateOfBirth(ctx, userID, dateOfBirth)
+	tracing.End(span, err)
+	return err
+}
+
+// SetTOTPSecret guarda el secreto TOTP de un usuario dentro de un span
+func (r *tracedUserRepository) SetTOTPSecret(ctx context.Context, userID uuid.UUID, encryptedSecret string) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.SetTOTPSecret")
+	err := r.repo.SetTOTPSecret(ctx, userID, encryptedSecret)
+	tracing.End(span, err)
+	return err
+}
+
+// EnableTOTP activa TOTP para un usuario dentro de un span
+func (r *tracedUserRepository) EnableTOTP(ctx context.Context, userID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "UserRepository.EnableTOTP")
+	err := r.repo.EnableTOTP(ctx, userID)
+	tracing.End(span, err)
+	return err
+}
+
+// VerifyPassword verifica una contraseña; no consulta la base de datos, por lo que no crea un span
+func (r *tracedUserRepository) VerifyPassword(hashedPassword, password string) error {
+	return r.repo.VerifyPassword(hashedPassword, password)
+}
@@ -1,164 +1,2745 @@
 package db
 
 import (
+	"context"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"log"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/sync/errgroup"
 
-	// "banca-en-linea/backend/internal/tigerbeetle" // Comentado temporalmente
+	"banca-en-linea/backend/internal/audit"
+	"banca-en-linea/backend/internal/auth"
+	"banca-en-linea/backend/internal/cache"
+	"banca-en-linea/backend/internal/compliance"
+	"banca-en-linea/backend/internal/email"
+	apperrors "banca-en-linea/backend/internal/errors"
+	"banca-en-linea/backend/internal/fee"
+	"banca-en-linea/backend/internal/fraud"
+	"banca-en-linea/backend/internal/idempotency"
+	"banca-en-linea/backend/internal/tigerbeetle"
+	"banca-en-linea/backend/internal/tracing"
+	"banca-en-linea/backend/internal/validation"
 	"banca-en-linea/backend/models"
 )
 
+// queryTimeout es el tiempo máximo que puede tardar una operación contra el repositorio
+const queryTimeout = 5 * time.Second
+
+// maxTransferIDAttempts es la cantidad máxima de intentos cuando TigerBeetle rechaza un ID
+// de transferencia por duplicado
+const maxTransferIDAttempts = 3
+
+var (
+	// ErrInvalidCredentials indica que el email o la contraseña no son válidos
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrAccountDeactivated indica que la cuenta del usuario está desactivada
+	ErrAccountDeactivated = errors.New("account is deactivated")
+	// ErrIncorrectPassword indica que la contraseña actual indicada no es correcta
+	ErrIncorrectPassword = errors.New("current password is incorrect")
+	// ErrPasswordReused indica que la nueva contraseña coincide con una de las últimas usadas
+	ErrPasswordReused = errors.New("password was used recently")
+	// ErrAccountFrozen indica que la cuenta del usuario está congelada y no admite operaciones
+	ErrAccountFrozen = apperrors.ErrAccountFrozen
+	// ErrNoTigerBeetleAccount indica que el usuario no tiene cuenta en TigerBeetle
+	ErrNoTigerBeetleAccount = apperrors.ErrNoTigerBeetleAccount
+	// ErrAccountInactive indica que la cuenta bancaria está cerrada y no admite operaciones
+	ErrAccountInactive = errors.New("bank account is inactive")
+	// ErrBalanceRemaining indica que el usuario aún tiene saldo (o deuda) en alguna de sus cuentas
+	ErrBalanceRemaining = errors.New("user accounts still have a balance")
+	// ErrSameAccount indica que la cuenta origen y la cuenta destino de una transferencia son la misma
+	ErrSameAccount = errors.New("cannot transfer to the same account")
+	// ErrInvalidCategory indica que la categoría de una transacción es demasiado larga
+	ErrInvalidCategory = fmt.Errorf("category must be at most %d characters", models.MaxCategoryLength)
+	// ErrInvalidTags indica que las etiquetas de una transacción son demasiadas, vacías o demasiado largas
+	ErrInvalidTags = fmt.Errorf("at most %d tags of 1 to %d characters are allowed", models.MaxTags, models.MaxTagLength)
+	// ErrInvalidSplitTransfer indica una transferencia dividida sin destinatarios o con demasiados,
+	// con un monto en cero, un destinatario repetido o una descripción demasiado larga
+	ErrInvalidSplitTransfer = errors.New("invalid split transfer")
+)
+
 // UserService maneja la lógica de negocio para usuarios
 type UserService struct {
-	userRepo UserRepository
-	// tigerBeetleService *tigerbeetle.Service // Comentado temporalmente
+	userRepo           UserRepository
+	tigerBeetleService tigerbeetle.TigerBeetleService
+	bankAccountRepo    BankAccountRepository
+	transactionRepo    TransactionRepository
+	beneficiaryRepo    BeneficiaryRepository
+	preferencesRepo    UserPreferencesRepository
+	notificationPrefs  NotificationPreferenceRepository
+	publishers         []TransactionPublisher
+	balancePublisher   BalancePublisher
+	auditLogRepo       AuditLogRepository
+	notificationRepo   NotificationRepository
+	transferIDs        TransferIDGenerator
+	passwordHistory    PasswordHistoryRepository
+	refreshTokens      RefreshTokenRepository
+	feeSchedule        fee.FeeSchedule
+	balanceCache       cache.BalanceCache
+	spendingCache      cache.SpendingSummaryCache
+	velocityChecker    *fraud.VelocityChecker
+	compliance         *compliance.ComplianceService
+	kycThreshold       uint64
+	alerter            TransactionAlerter
+	alertThreshold     uint64
+}
+
+// TransactionPublisher recibe los eventos de las transacciones completadas
+type TransactionPublisher interface {
+	Publish(event models.TransactionEvent)
+}
+
+// BalancePublisher recibe el nuevo balance de las cuentas modificadas por una operación
+type BalancePublisher interface {
+	HasSubscribers(userID uuid.UUID) bool
+	PublishBalance(userID uuid.UUID, event models.BalanceChangedEvent)
+}
+
+// TransactionAlerter avisa al usuario de una transacción grande (implementado por email.NotificationService)
+type TransactionAlerter interface {
+	SendTransactionAlert(user *models.User, event email.TransactionEvent) error
+}
+
+// UserServiceOption configura dependencias opcionales del servicio de usuarios
+type UserServiceOption func(*UserService)
+
+// WithBankAccountRepository configura el repositorio de cuentas bancarias
+func WithBankAccountRepository(repo BankAccountRepository) UserServiceOption {
+	return func(s *UserService) {
+		s.bankAccountRepo = repo
+	}
+}
+
+// WithTransactionRepository configura el repositorio de transacciones
+func WithTransactionRepository(repo TransactionRepository) UserServiceOption {
+	return func(s *UserService) {
+		s.transactionRepo = repo
+	}
+}
+
+// WithBeneficiaryRepository configura el repositorio de beneficiarios
+func WithBeneficiaryRepository(repo BeneficiaryRepository) UserServiceOption {
+	return func(s *UserService) {
+		s.beneficiaryRepo = repo
+	}
+}
+
+// WithUserPreferencesRepository configura el repositorio de preferencias de usuario
+func WithUserPreferencesRepository(repo UserPreferencesRepository) UserServiceOption {
+	return func(s *UserService) {
+		s.preferencesRepo = repo
+	}
+}
+
+// WithNotificationPreferenceRepository configura el repositorio de preferencias de notificación
+func WithNotificationPreferenceRepository(repo NotificationPreferenceRepository) UserServiceOption {
+	return func(s *UserService) {
+		s.notificationPrefs = repo
+	}
+}
+
+// WithTransactionPublisher agrega un destino para los eventos de transacción. Puede usarse
+// varias veces: cada evento se publica en todos los destinos configurados.
+func WithTransactionPublisher(publisher TransactionPublisher) UserServiceOption {
+	return func(s *UserService) {
+		s.publishers = append(s.publishers, publisher)
+	}
+}
+
+// WithBalancePublisher configura el destino de los cambios de balance de las cuentas
+func WithBalancePublisher(publisher BalancePublisher) UserServiceOption {
+	return func(s *UserService) {
+		s.balancePublisher = publisher
+	}
+}
+
+// WithAuditLogRepository configura el repositorio de auditoría
+func WithAuditLogRepository(repo AuditLogRepository) UserServiceOption {
+	return func(s *UserService) {
+		s.auditLogRepo = repo
+	}
+}
+
+// WithNotificationRepository configura el repositorio de notificaciones
+func WithNotificationRepository(repo NotificationRepository) UserServiceOption {
+	return func(s *UserService) {
+		s.notificationRepo = repo
+	}
+}
+
+// WithTransferIDGenerator configura el generador de IDs de transferencias de TigerBeetle
+func WithTransferIDGenerator(generator TransferIDGenerator) UserServiceOption {
+	return func(s *UserService) {
+		s.transferIDs = generator
+	}
+}
+
+// WithPasswordHistoryRepository configura el historial de contraseñas usado para evitar su reutilización
+func WithPasswordHistoryRepository(repo PasswordHistoryRepository) UserServiceOption {
+	return func(s *UserService) {
+		s.passwordHistory = repo
+	}
+}
+
+// WithRefreshTokenRepository configura las sesiones que se revocan al cambiar la contraseña
+func WithRefreshTokenRepository(repo RefreshTokenRepository) UserServiceOption {
+	return func(s *UserService) {
+		s.refreshTokens = repo
+	}
+}
+
+// WithBalanceCache configura la caché de balances de TigerBeetle
+func WithBalanceCache(balanceCache cache.BalanceCache) UserServiceOption {
+	return func(s *UserService) {
+		s.balanceCache = balanceCache
+	}
+}
+
+// WithSpendingSummaryCache configura la caché de los resúmenes de gastos
+func WithSpendingSummaryCache(spendingCache cache.SpendingSummaryCache) UserServiceOption {
+	return func(s *UserService) {
+		s.spendingCache = spendingCache
+	}
+}
+
+// WithFeeSchedule configura la comisión cobrada en las transferencias entre usuarios
+func WithFeeSchedule(schedule fee.FeeSchedule) UserServiceOption {
+	return func(s *UserService) {
+		s.feeSchedule = schedule
+	}
+}
+
+// WithVelocityChecker configura la detección de ráfagas de transacciones sospechosas
+func WithVelocityChecker(checker *fraud.VelocityChecker) UserServiceOption {
+	return func(s *UserService) {
+		s.velocityChecker = checker
+	}
+}
+
+// WithComplianceService configura los controles antilavado de retiros y transferencias
+func WithComplianceService(service *compliance.ComplianceService) UserServiceOption {
+	return func(s *UserService) {
+		s.compliance = service
+	}
+}
+
+// WithKYCThreshold exige KYC aprobado para retiros y transferencias que superen el monto en
+// centavos indicado. Con 0 no se exige.
+func WithKYCThreshold(cents uint64) UserServiceOption {
+	return func(s *UserService) {
+		s.kycThreshold = cents
+	}
+}
+
+// WithTransactionAlerts avisa por email al usuario de los retiros y transferencias que superen
+// el monto en centavos indicado
+func WithTransactionAlerts(alerter TransactionAlerter, thresholdCents uint64) UserServiceOption {
+	return func(s *UserService) {
+		s.alerter = alerter
+		s.alertThreshold = thresholdCents
+	}
+}
+
+// TransferOption configura datos opcionales de una transferencia
+type TransferOption func(*transferDetails)
+
+// transferDetails son los datos opcionales con que se registra una transferencia
+type transferDetails struct {
+	category    string
+	tags        []string
+	description string
+}
+
+// WithTransferCategory registra la transferencia con la categoría y las etiquetas del usuario
+func WithTransferCategory(category string, tags []string) TransferOption {
+	return func(d *transferDetails) {
+		d.category = category
+		d.tags = tags
+	}
 }
 
 // NewUserService crea una nueva instancia del servicio de usuarios
-func NewUserService(userRepo UserRepository, tbService interface{}) *UserService {
-	return &UserService{
-		userRepo: userRepo,
-		// tigerBeetleService: tbService, // Comentado temporalmente
+func NewUserService(userRepo UserRepository, tbService tigerbeetle.TigerBeetleService, opts ...UserServiceOption) *UserService {
+	service := &UserService{
+		userRepo:           userRepo,
+		tigerBeetleService: tbService,
+		transferIDs:        NewRandomTransferIDGenerator(),
+	}
+
+	for _, opt := range opts {
+		opt(service)
+	}
+
+	// Proteger las llamadas a TigerBeetle con un circuit breaker
+	if tbService != nil {
+		if _, ok := tbService.(*tigerbeetle.CircuitBreakerService); !ok {
+			service.tigerBeetleService = tigerbeetle.NewCircuitBreakerService(tbService)
+		}
+	}
+
+	return service
+}
+
+// newQueryContext crea un contexto con el timeout por defecto para operaciones del repositorio
+func newQueryContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), queryTimeout)
+}
+
+// ErrInsufficientFunds indica que la cuenta no tiene balance suficiente para la operación
+var ErrInsufficientFunds = apperrors.ErrInsufficientFunds
+
+// InsufficientFundsError detalla el monto solicitado y el balance disponible cuando
+// una operación es rechazada por falta de fondos
+type InsufficientFundsError struct {
+	Expected uint64 // Monto solicitado en centavos
+	Actual   uint64 // Balance disponible en centavos
+}
+
+func (e *InsufficientFundsError) Error() string {
+	return "insufficient funds"
+}
+
+// Is permite usar errors.Is(err, ErrInsufficientFunds)
+func (e *InsufficientFundsError) Is(target error) bool {
+	return target == ErrInsufficientFunds
+}
+
+// ErrBelowMinimumBalance indica que la operación dejaría la cuenta por debajo del balance mínimo
+// de su tipo de cuenta
+var ErrBelowMinimumBalance = errors.New("operation would leave the account below its minimum balance")
+
+// ErrDailyLimitExceeded indica que la operación supera el límite diario del usuario
+var ErrDailyLimitExceeded = apperrors.ErrDailyLimitExceeded
+
+// DailyLimitExceededError detalla el límite diario, lo ya usado en el día y el monto
+// solicitado cuando una operación es rechazada por superar el límite
+type DailyLimitExceededError struct {
+	TransactionType string
+	Limit           uint64 // Límite diario en centavos
+	Used            uint64 // Monto ya operado en el día en centavos
+	Requested       uint64 // Monto solicitado en centavos
+}
+
+func (e *DailyLimitExceededError) Error() string {
+	return fmt.Sprintf("daily %s limit exceeded: limit %d, used %d, requested %d",
+		e.TransactionType, e.Limit, e.Used, e.Requested)
+}
+
+// Is permite usar errors.Is(err, ErrDailyLimitExceeded)
+func (e *DailyLimitExceededError) Is(target error) bool {
+	return target == ErrDailyLimitExceeded
+}
+
+// SplitFailure es la parte de una transferencia dividida que TigerBeetle rechazó
+type SplitFailure struct {
+	ToUserID uuid.UUID
+	Err      error
+}
+
+// SplitTransferError detalla las partes rechazadas de una transferencia dividida. Las partes de
+// los demás destinatarios sí se aplicaron.
+type SplitTransferError struct {
+	Failures []SplitFailure
+}
+
+func (e *SplitTransferError) Error() string {
+	return fmt.Sprintf("%d split transfers failed", len(e.Failures))
+}
+
+// Unwrap permite usar errors.Is y errors.As con los errores de cada parte
+func (e *SplitTransferError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, failure := range e.Failures {
+		errs[i] = failure.Err
 	}
+	return errs
 }
 
-// CreateUserWithAccount crea un usuario (sin TigerBeetle temporalmente)
+// CreateUserWithAccount crea un usuario y su cuenta en TigerBeetle
 func (s *UserService) CreateUserWithAccount(req *models.CreateUserRequest) (*models.User, error) {
 	// 1. Crear el usuario en PostgreSQL
-	user, err := s.userRepo.Create(req)
+	user, err := s.createUserWithoutAccount(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := newQueryContext()
+	defer cancel()
+
+	// 2. Crear la cuenta en TigerBeetle
+	if err := s.createTigerBeetleAccount(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// CreateUsersWithAccounts crea varios usuarios y luego sus cuentas TigerBeetle con una sola llamada
+// por lote a BatchCreateUserAccounts. Retorna los usuarios creados y, por email, el error de los que
+// no se pudieron crear. Los usuarios cuya cuenta TigerBeetle falla se eliminan igual que en
+// CreateUserWithAccount.
+func (s *UserService) CreateUsersWithAccounts(reqs []*models.CreateUserRequest) ([]*models.User, map[string]error) {
+	failed := make(map[string]error)
+	users := make([]*models.User, 0, len(reqs))
+
+	// 1. Crear los usuarios en PostgreSQL
+	for _, req := range reqs {
+		user, err := s.createUserWithoutAccount(req)
+		if err != nil {
+			failed[req.Email] = err
+			continue
+		}
+		users = append(users, user)
+	}
+
+	if s.tigerBeetleService == nil || len(users) == 0 {
+		return users, failed
+	}
+
+	// 2. Crear todas las cuentas TigerBeetle en lote
+	usersByAccount := make(map[uint64]*models.User, len(users))
+	accountIDs := make([]uint64, 0, len(users))
+	for _, user := range users {
+		accountID := GenerateTigerBeetleAccountID(user.ID)
+		usersByAccount[accountID] = user
+		accountIDs = append(accountIDs, accountID)
+	}
+
+	accounts, _, batchErr := s.tigerBeetleService.BatchCreateUserAccounts(accountIDs)
+
+	// 3. Guardar el ID de cada cuenta creada
+	withAccount := make([]*models.User, 0, len(accounts))
+	for _, account := range accounts {
+		user := usersByAccount[account.GetID()]
+		delete(usersByAccount, account.GetID())
+
+		ctx, cancel := newQueryContext()
+		tbAccountID := int64(account.GetID())
+		err := s.userRepo.UpdateTigerBeetleAccountID(ctx, user.ID, tbAccountID)
+		cancel()
+		if err != nil {
+			failed[user.Email] = fmt.Errorf("error saving TigerBeetle account ID: %w", err)
+			continue
+		}
+		user.TigerBeetleAccountID = &tbAccountID
+		withAccount = append(withAccount, user)
+	}
+
+	// 4. Eliminar los usuarios que quedaron sin cuenta
+	for _, user := range usersByAccount {
+		ctx, cancel := newQueryContext()
+		if delErr := s.userRepo.Delete(ctx, user.ID); delErr != nil {
+			log.Printf("Error rolling back user %s: %v", user.ID, delErr)
+		}
+		cancel()
+
+		if batchErr != nil {
+			failed[user.Email] = fmt.Errorf("error creating TigerBeetle account: %w", batchErr)
+		} else {
+			failed[user.Email] = fmt.Errorf("error creating TigerBeetle account: rejected by TigerBeetle")
+		}
+	}
+
+	return withAccount, failed
+}
+
+// createUserWithoutAccount valida y crea el usuario en PostgreSQL sin crear su cuenta TigerBeetle
+func (s *UserService) createUserWithoutAccount(req *models.CreateUserRequest) (*models.User, error) {
+	if req.Phone != nil {
+		if err := validation.ValidatePhone(*req.Phone); err != nil {
+			return nil, err
+		}
+	}
+	if req.DateOfBirth != nil {
+		if err := validation.ValidateMinimumAge(*req.DateOfBirth, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := newQueryContext()
+	defer cancel()
+
+	user, err := s.userRepo.Create(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("error creating user: %w", err)
+	}
+	s.recordPasswordHistory(ctx, user.ID, user.PasswordHash)
+
+	return user, nil
+}
+
+// CreateAdminUser crea un usuario con rol y estado KYC definidos por un administrador.
+// El rol y el estado KYC se guardan en la misma transacción que la creación del usuario
+// y, si se indica, se realiza el depósito inicial.
+//
+// El depósito inicial lo ordena el administrador, así que se asienta directamente en el libro
+// mayor sin los controles de KYC, velocidad y límites de un depósito del cliente. Si falla, el
+// usuario se elimina: la creación no queda a medias.
+func (s *UserService) CreateAdminUser(ctx context.Context, req *models.CreateAdminUserRequest) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	role := req.Role
+	if role == "" {
+		role = models.RoleUser
+	}
+
+	kycStatus := req.KYCStatus
+	if kycStatus == "" {
+		kycStatus = models.KYCStatusPending
+	}
+
+	createReq := &models.CreateUserRequest{
+		Email:     req.Email,
+		Password:  req.Password,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+	}
+
+	// 1. Crear el usuario con sus atributos en PostgreSQL
+	user, err := s.userRepo.CreateWithAttributes(ctx, createReq, []string{role}, kycStatus)
 	if err != nil {
 		return nil, fmt.Errorf("error creating user: %w", err)
 	}
+	s.recordPasswordHistory(ctx, user.ID, user.PasswordHash)
+
+	// 2. Crear la cuenta en TigerBeetle
+	if err := s.createTigerBeetleAccount(ctx, user); err != nil {
+		return nil, err
+	}
+
+	// 3. Depósito inicial
+	if req.InitialDepositCentavos > 0 {
+		err := s.initialDeposit(ctx, user, req.InitialDepositCentavos)
+		s.recordFinancialAudit(ctx, models.AuditActionDeposit, user.ID, user.ID, req.InitialDepositCentavos, err)
+		if err != nil {
+			s.rollbackUser(ctx, user.ID)
+			return nil, fmt.Errorf("error making initial deposit: %w", err)
+		}
+	}
 
-	// Temporalmente sin TigerBeetle - solo crear el usuario
-	log.Printf("Successfully created user %s (TigerBeetle disabled)", user.Email)
 	return user, nil
 }
 
-// GetUserWithBalance obtiene un usuario (sin balance temporalmente)
+// initialDeposit acredita el depósito inicial en la cuenta principal de un usuario recién creado
+func (s *UserService) initialDeposit(ctx context.Context, user *models.User, amount uint64) error {
+	if s.tigerBeetleService == nil {
+		log.Printf("TigerBeetle disabled - would deposit %d to user %s", amount, user.Email)
+		return nil
+	}
+
+	account, err := s.primaryAccount(user)
+	if err != nil {
+		return err
+	}
+	return s.postDeposit(ctx, user, account, amount)
+}
+
+// rollbackUser elimina un usuario cuya creación no pudo completarse. Su cuenta de TigerBeetle,
+// que no puede borrarse, queda sin saldo y sin usuario que la use.
+func (s *UserService) rollbackUser(ctx context.Context, userID uuid.UUID) {
+	if err := s.userRepo.Delete(ctx, userID); err != nil {
+		log.Printf("Error rolling back user %s: %v", userID, err)
+	}
+}
+
+// createTigerBeetleAccount crea la cuenta TigerBeetle del usuario y guarda su ID.
+// Si falla, el usuario recién creado se elimina para no dejarlo sin cuenta.
+func (s *UserService) createTigerBeetleAccount(ctx context.Context, user *models.User) error {
+	if s.tigerBeetleService == nil {
+		log.Printf("Successfully created user %s (TigerBeetle disabled)", user.Email)
+		return nil
+	}
+
+	accountID := GenerateTigerBeetleAccountID(user.ID)
+	if _, err := s.tigerBeetleService.CreateUserAccount(accountID); err != nil {
+		s.rollbackUser(ctx, user.ID)
+		return fmt.Errorf("error creating TigerBeetle account: %w", err)
+	}
+
+	tbAccountID := int64(accountID)
+	if err := s.userRepo.UpdateTigerBeetleAccountID(ctx, user.ID, tbAccountID); err != nil {
+		s.rollbackUser(ctx, user.ID)
+		return fmt.Errorf("error saving TigerBeetle account ID: %w", err)
+	}
+	user.TigerBeetleAccountID = &tbAccountID
+
+	return nil
+}
+
+// GetUserWithBalance obtiene un usuario junto con su balance en TigerBeetle (en centavos)
 func (s *UserService) GetUserWithBalance(userID uuid.UUID) (*models.User, uint64, error) {
+	ctx, cancel := newQueryContext()
+	defer cancel()
+
 	// 1. Obtener el usuario de PostgreSQL
-	user, err := s.userRepo.GetByID(userID)
+	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return nil, 0, fmt.Errorf("error getting user: %w", err)
 	}
 
-	// Temporalmente sin TigerBeetle - devolver balance 0
-	return user, 0, nil
+	// 2. Sin cuenta TigerBeetle el balance es 0
+	if s.tigerBeetleService == nil || user.TigerBeetleAccountID == nil {
+		return user, 0, nil
+	}
+
+	balance, err := s.cachedAccountBalance(*user.TigerBeetleAccountID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return user, balance, nil
 }
 
-// DepositToUser realiza un depósito a la cuenta de un usuario (temporalmente sin TigerBeetle)
-func (s *UserService) DepositToUser(userID uuid.UUID, amount uint64) error {
-	// 1. Obtener el usuario
-	user, err := s.userRepo.GetByID(userID)
+// DepositToUser realiza un depósito a la cuenta principal de un usuario y registra el resultado en la auditoría
+func (s *UserService) DepositToUser(ctx context.Context, userID uuid.UUID, amount uint64) error {
+	err := s.depositToUser(ctx, userID, amount)
+	s.recordFinancialAudit(ctx, models.AuditActionDeposit, userID, userID, amount, err)
+	return err
+}
+
+// depositToUser realiza el depósito en la cuenta principal del usuario
+func (s *UserService) depositToUser(ctx context.Context, userID uuid.UUID, amount uint64) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return fmt.Errorf("error getting user: %w", err)
 	}
 
-	// Temporalmente sin TigerBeetle - solo registrar la operación
-	log.Printf("TigerBeetle disabled - would deposit %d to user %s", amount, user.Email)
-	return nil
+	account, err := s.primaryAccount(user)
+	if err != nil {
+		return err
+	}
+
+	return s.deposit(ctx, user, account, amount)
 }
 
-// WithdrawFromUser realiza un retiro de la cuenta de un usuario (temporalmente sin TigerBeetle)
-func (s *UserService) WithdrawFromUser(userID uuid.UUID, amount uint64) error {
-	// 1. Obtener el usuario
-	user, err := s.userRepo.GetByID(userID)
+// DepositToAccount realiza un depósito a una cuenta bancaria y registra el resultado en la
+// auditoría a nombre de su titular
+func (s *UserService) DepositToAccount(ctx context.Context, accountID uuid.UUID, amount uint64) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	account, owner, err := s.accountWithOwner(ctx, accountID)
 	if err != nil {
-		return fmt.Errorf("error getting user: %w", err)
+		return err
+	}
+
+	err = s.deposit(ctx, owner, account, amount)
+	s.recordFinancialAudit(ctx, models.AuditActionDeposit, owner.ID, owner.ID, amount, err)
+	return err
+}
+
+// deposit acredita el monto en la cuenta bancaria del usuario
+func (s *UserService) deposit(ctx context.Context, user *models.User, account *models.BankAccount, amount uint64) error {
+	if user.IsFrozen {
+		return ErrAccountFrozen
+	}
+	if !account.IsActive {
+		return ErrAccountInactive
 	}
+	if err := s.checkVelocity(ctx, user, models.TransactionTypeDeposit, amount); err != nil {
+		return err
+	}
+
+	if s.tigerBeetleService == nil {
+		log.Printf("TigerBeetle disabled - would deposit %d to user %s", amount, user.Email)
+		s.publishTransaction(models.TransactionEventDeposit, amount, nil, &user.ID)
+		return nil
+	}
+
+	return s.postDeposit(ctx, user, account, amount)
+}
+
+// postDeposit asienta el depósito en TigerBeetle, lo registra y lo publica, sin más controles
+func (s *UserService) postDeposit(ctx context.Context, user *models.User, account *models.BankAccount, amount uint64) error {
+	accountID := account.TigerBeetleAccountID
 
-	// Temporalmente sin TigerBeetle - solo registrar la operación
-	log.Printf("TigerBeetle disabled - would withdraw %d from user %s", amount, user.Email)
+	var transferID uint64
+	err := s.withTransferIDs(ctx, []string{"deposit"}, func(ids []uint64) error {
+		transferID = ids[0]
+		return s.tigerBeetleService.Deposit(uint64(accountID), amount, ids[0])
+	})
+	if err != nil {
+		return fmt.Errorf("error processing deposit: %w", err)
+	}
+	s.invalidateBalances(accountID)
+	s.recordMovement(ctx, user, account, models.TransactionTypeDeposit, transferID, amount)
+	s.publishBalance(user.ID, accountID)
+	s.publishTransaction(models.TransactionEventDeposit, amount, nil, &user.ID)
 	return nil
 }
 
-// TransferBetweenUsers realiza una transferencia entre dos usuarios (temporalmente sin TigerBeetle)
-func (s *UserService) TransferBetweenUsers(fromUserID, toUserID uuid.UUID, amount uint64) error {
-	// 1. Obtener ambos usuarios
-	fromUser, err := s.userRepo.GetByID(fromUserID)
+// WithdrawFromUser realiza un retiro de la cuenta principal de un usuario
+func (s *UserService) WithdrawFromUser(ctx context.Context, userID uuid.UUID, amount uint64) error {
+	err := s.withdrawFromUser(ctx, userID, amount)
+	s.recordFinancialAudit(ctx, models.AuditActionWithdrawal, userID, userID, amount, err)
+	return err
+}
+
+// withdrawFromUser realiza el retiro de la cuenta principal del usuario
+func (s *UserService) withdrawFromUser(ctx context.Context, userID uuid.UUID, amount uint64) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return fmt.Errorf("error getting source user: %w", err)
+		return fmt.Errorf("error getting user: %w", err)
 	}
 
-	toUser, err := s.userRepo.GetByID(toUserID)
+	account, err := s.primaryAccount(user)
 	if err != nil {
-		return fmt.Errorf("error getting destination user: %w", err)
+		return err
 	}
 
-	// Temporalmente sin TigerBeetle - solo registrar la operación
-	log.Printf("TigerBeetle disabled - would transfer %d from user %s to user %s", amount, fromUser.Email, toUser.Email)
-	return nil
+	return s.withdraw(ctx, user, account, amount)
 }
 
-// AssociateTigerBeetleAccount asocia una cuenta TigerBeetle existente a un usuario (temporalmente sin TigerBeetle)
-func (s *UserService) AssociateTigerBeetleAccount(userID uuid.UUID) error {
-	// 1. Obtener el usuario
-	user, err := s.userRepo.GetByID(userID)
+// WithdrawFromAccount realiza un retiro de una cuenta bancaria y registra el resultado en la
+// auditoría a nombre de su titular
+func (s *UserService) WithdrawFromAccount(ctx context.Context, accountID uuid.UUID, amount uint64) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	account, owner, err := s.accountWithOwner(ctx, accountID)
 	if err != nil {
-		return fmt.Errorf("error getting user: %w", err)
+		return err
+	}
+
+	err = s.withdraw(ctx, owner, account, amount)
+	s.recordFinancialAudit(ctx, models.AuditActionWithdrawal, owner.ID, owner.ID, amount, err)
+	return err
+}
+
+// withdraw debita el monto de la cuenta bancaria del usuario
+func (s *UserService) withdraw(ctx context.Context, user *models.User, account *models.BankAccount, amount uint64) error {
+	if user.IsFrozen {
+		return ErrAccountFrozen
+	}
+	if !account.IsActive {
+		return ErrAccountInactive
+	}
+	if err := s.checkKYC(user, amount); err != nil {
+		return err
 	}
+	if err := s.checkVelocity(ctx, user, models.TransactionTypeWithdrawal, amount); err != nil {
+		return err
+	}
+	if err := s.checkCompliance(ctx, user, amount); err != nil {
+		return err
+	}
+
+	if s.tigerBeetleService == nil {
+		log.Printf("TigerBeetle disabled - would withdraw %d from user %s", amount, user.Email)
+	} else {
+		accountID := account.TigerBeetleAccountID
+
+		// 1. Verificar el límite diario de retiros y que el balance sea suficiente
+		if err := s.checkDailyLimit(ctx, user.ID, models.TransactionTypeWithdrawal, user.DailyWithdrawalLimitCents, amount); err != nil {
+			return err
+		}
+		err := s.checkFunds(account, amount)
+		if err != nil {
+			return err
+		}
 
-	// Temporalmente sin TigerBeetle - solo registrar la operación
-	log.Printf("TigerBeetle disabled - would associate account to user %s", user.Email)
+		// 2. Realizar el retiro en TigerBeetle
+		var transferID uint64
+		err = s.withTransferIDs(ctx, []string{"withdrawal"}, func(ids []uint64) error {
+			transferID = ids[0]
+			return s.tigerBeetleService.Withdraw(uint64(accountID), amount, ids[0])
+		})
+		if err != nil {
+			if errors.Is(err, tigerbeetle.ErrExceedsCredits) {
+				return s.refreshedFundsError(accountID, amount)
+			}
+			return fmt.Errorf("error processing withdrawal: %w", err)
+		}
+		s.invalidateBalances(accountID)
+		s.recordMovement(ctx, user, account, models.TransactionTypeWithdrawal, transferID, amount)
+		s.publishBalance(user.ID, accountID)
+	}
+
+	s.publishTransaction(models.TransactionEventWithdrawal, amount, &user.ID, nil)
+	s.alertLargeTransaction(user, models.TransactionEventWithdrawal, amount, nil)
 	return nil
 }
 
-// GetUser obtiene un usuario por su ID
-func (s *UserService) GetUser(userID uuid.UUID) (*models.User, error) {
-	user, err := s.userRepo.GetByID(userID)
-	if err != nil {
-		return nil, fmt.Errorf("error getting user: %w", err)
+// TransferBetweenUsers realiza una transferencia entre las cuentas principales de dos usuarios y
+// cobra la comisión correspondiente hacia la cuenta de comisiones. Retorna la comisión cobrada
+// en centavos.
+func (s *UserService) TransferBetweenUsers(ctx context.Context, fromUserID, toUserID uuid.UUID, amount uint64, opts ...TransferOption) (uint64, error) {
+	var details transferDetails
+	for _, opt := range opts {
+		opt(&details)
 	}
-	return user, nil
+
+	var err error
+	if details.category, err = normalizeCategory(details.category); err != nil {
+		return 0, err
+	}
+	if details.tags, err = normalizeTags(details.tags); err != nil {
+		return 0, err
+	}
+
+	ctx, span := tracing.Start(ctx, "UserService.TransferBetweenUsers")
+	transferFee, err := s.transferBetweenUsers(ctx, fromUserID, toUserID, amount, details)
+	s.recordFinancialAudit(ctx, models.AuditActionTransfer, fromUserID, toUserID, amount, err)
+	tracing.End(span, err)
+	return transferFee, err
 }
 
-// ListUsers obtiene una lista paginada de usuarios
-func (s *UserService) ListUsers(limit, offset int) ([]*models.User, error) {
-	users, err := s.userRepo.List(limit, offset)
+// transferBetweenUsers realiza la transferencia entre las cuentas principales de los usuarios
+func (s *UserService) transferBetweenUsers(ctx context.Context, fromUserID, toUserID uuid.UUID, amount uint64, details transferDetails) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	// 1. Obtener ambos usuarios y sus cuentas principales
+	fromUser, err := s.userRepo.GetByID(ctx, fromUserID)
 	if err != nil {
-		return nil, fmt.Errorf("error listing users: %w", err)
+		return 0, fmt.Errorf("error getting source user: %w", err)
 	}
-	return users, nil
-}
 
-// GetUserByEmail obtiene un usuario por su email
-func (s *UserService) GetUserByEmail(email string) (*models.User, error) {
-	user, err := s.userRepo.GetByEmail(email)
+	toUser, err := s.userRepo.GetByID(ctx, toUserID)
 	if err != nil {
-		return nil, fmt.Errorf("error getting user by email: %w", err)
+		return 0, fmt.Errorf("error getting destination user: %w", err)
+	}
+
+	fromAccount, err := s.primaryAccount(fromUser)
+	if err != nil {
+		return 0, err
 	}
-	return user, nil
-}
 
-// generateTigerBeetleAccountID genera un ID único para una cuenta TigerBeetle basado en el UUID del usuario
-func generateTigerBeetleAccountID(userID uuid.UUID) uint64 {
-	// Convertir los primeros 8 bytes del UUID a uint64
-	bytes := userID[:]
-	var id uint64
-	for i := 0; i < 8; i++ {
-		id = (id << 8) | uint64(bytes[i])
+	toAccount, err := s.primaryAccount(toUser)
+	if err != nil {
+		return 0, err
 	}
 
-	// Asegurar que el ID no sea 1 o 2 (reservados para cuentas maestras)
-	if id <= 2 {
-		id += 1000
+	return s.transfer(ctx, fromUser, toUser, fromAccount, toAccount, amount, details)
+}
+
+// TransferByEmail realiza una transferencia desde la cuenta principal del usuario hacia el
+// usuario registrado con el email indicado. Retorna la comisión cobrada en centavos.
+func (s *UserService) TransferByEmail(ctx context.Context, fromUserID uuid.UUID, toEmail string, amount uint64) (uint64, error) {
+	toUser, err := s.transferRecipientByEmail(ctx, fromUserID, toEmail)
+	if err != nil {
+		return 0, err
 	}
 
-	return id
+	return s.TransferBetweenUsers(ctx, fromUserID, toUser.ID, amount)
 }
 
-// generateTransferID genera un ID único para una transferencia
-func generateTransferID() uint64 {
-	// En una implementación real, esto debería ser un ID único global
-	// Por simplicidad, usamos un UUID convertido a uint64
-	id := uuid.New()
-	bytes := id[:]
-	var transferID uint64
-	for i := 0; i < 8; i++ {
-		transferID = (transferID << 8) | uint64(bytes[i])
+// transferRecipientByEmail resuelve el destinatario de una transferencia por email y verifica que
+// tanto él como el usuario origen estén activos y tengan cuenta en TigerBeetle
+func (s *UserService) transferRecipientByEmail(ctx context.Context, fromUserID uuid.UUID, toEmail string) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	toUser, err := s.userRepo.GetByEmail(ctx, strings.TrimSpace(toEmail))
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrRecipientNotFound
+		}
+		return nil, fmt.Errorf("error getting recipient: %w", err)
+	}
+	if toUser.ID == fromUserID {
+		return nil, ErrSameAccount
+	}
+	if !toUser.IsActive {
+		return nil, ErrRecipientInactive
+	}
+
+	fromUser, err := s.userRepo.GetByID(ctx, fromUserID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting source user: %w", err)
 	}
-	return transferID
+	if !fromUser.IsActive {
+		return nil, ErrAccountDeactivated
+	}
+
+	if _, err := s.primaryAccount(fromUser); err != nil {
+		return nil, err
+	}
+	if _, err := s.primaryAccount(toUser); err != nil {
+		return nil, err
+	}
+
+	return toUser, nil
+}
+
+// SplitTransfer divide un pago entre varios usuarios desde la cuenta principal de fromUserID.
+// Verifica una sola vez que la cuenta cubra el total con las comisiones y envía todas las
+// transferencias en un solo lote a TigerBeetle. Las partes no se enlazan entre sí, así que el
+// rechazo de una no impide las demás: retorna el ID de la transacción registrada de cada parte,
+// en el orden de splits (también las rechazadas, que quedan como fallidas), y un
+// *SplitTransferError con las partes rechazadas.
+func (s *UserService) SplitTransfer(ctx context.Context, fromUserID uuid.UUID, splits []models.SplitTarget, description string) ([]uuid.UUID, error) {
+	ctx, span := tracing.Start(ctx, "UserService.SplitTransfer")
+	ids, splitErrs, err := s.splitTransfer(ctx, fromUserID, splits, strings.TrimSpace(description))
+	if errors.Is(err, ErrInvalidSplitTransfer) || errors.Is(err, ErrSameAccount) {
+		tracing.End(span, err)
+		return nil, err
+	}
+
+	var failures []SplitFailure
+	for i, split := range splits {
+		splitErr := err
+		if splitErr == nil && splitErrs[i] != nil {
+			splitErr = splitErrs[i]
+			failures = append(failures, SplitFailure{ToUserID: split.ToUserID, Err: splitErr})
+		}
+		s.recordFinancialAudit(ctx, models.AuditActionTransfer, fromUserID, split.ToUserID, split.AmountCents, splitErr)
+	}
+	if err == nil && len(failures) > 0 {
+		err = &SplitTransferError{Failures: failures}
+	}
+
+	tracing.End(span, err)
+	return ids, err
+}
+
+// splitTransfer valida las partes y a los destinatarios, y realiza el lote de transferencias.
+// Retorna los IDs de las transacciones registradas y el error de cada parte, o un error si no se
+// transfirió nada.
+func (s *UserService) splitTransfer(ctx context.Context, fromUserID uuid.UUID, splits []models.SplitTarget, description string) ([]uuid.UUID, []error, error) {
+	if len(splits) == 0 || len(splits) > models.MaxSplitTargets ||
+		utf8.RuneCountInString(description) > models.MaxSplitDescriptionLength {
+		return nil, nil, ErrInvalidSplitTransfer
+	}
+
+	var total uint64
+	seen := make(map[uuid.UUID]bool, len(splits))
+	for _, split := range splits {
+		if split.ToUserID == fromUserID {
+			return nil, nil, ErrSameAccount
+		}
+		if split.AmountCents == 0 || seen[split.ToUserID] || total+split.AmountCents < total {
+			return nil, nil, ErrInvalidSplitTransfer
+		}
+		seen[split.ToUserID] = true
+		total += split.AmountCents
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	// 1. Obtener al usuario origen y a los destinatarios con sus cuentas principales
+	fromUser, err := s.userRepo.GetByID(ctx, fromUserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting source user: %w", err)
+	}
+	fromAccount, err := s.primaryAccount(fromUser)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	recipients := make([]*models.User, len(splits))
+	toAccounts := make([]*models.BankAccount, len(splits))
+	for i, split := range splits {
+		toUser, err := s.userRepo.GetByID(ctx, split.ToUserID)
+		if err != nil {
+			if errors.Is(err, ErrUserNotFound) {
+				return nil, nil, fmt.Errorf("user %s: %w", split.ToUserID, ErrRecipientNotFound)
+			}
+			return nil, nil, fmt.Errorf("error getting recipient: %w", err)
+		}
+		if !toUser.IsActive {
+			return nil, nil, fmt.Errorf("user %s: %w", split.ToUserID, ErrRecipientInactive)
+		}
+		if toUser.IsFrozen {
+			return nil, nil, ErrAccountFrozen
+		}
+		if toAccounts[i], err = s.primaryAccount(toUser); err != nil {
+			return nil, nil, err
+		}
+		recipients[i] = toUser
+	}
+
+	// 2. Aplicar los controles al total, como si fuera una sola transferencia
+	if fromUser.IsFrozen {
+		return nil, nil, ErrAccountFrozen
+	}
+	if err := s.checkKYC(fromUser, total); err != nil {
+		return nil, nil, err
+	}
+	if err := s.checkVelocity(ctx, fromUser, models.TransactionTypeTransfer, total); err != nil {
+		return nil, nil, err
+	}
+	if err := s.checkCompliance(ctx, fromUser, total); err != nil {
+		return nil, nil, err
+	}
+
+	fees := make([]uint64, len(splits))
+	var totalFee uint64
+	for i, split := range splits {
+		fees[i] = s.feeSchedule.Calculate(split.AmountCents)
+		totalFee += fees[i]
+	}
+
+	ids := make([]uuid.UUID, len(splits))
+	splitErrs := make([]error, len(splits))
+	details := transferDetails{description: description}
+
+	if s.tigerBeetleService == nil {
+		log.Printf("TigerBeetle disabled - would split %d (fees %d) from user %s among %d users", total, totalFee, fromUser.Email, len(splits))
+		for i, split := range splits {
+			s.publishTransaction(models.TransactionEventTransfer, split.AmountCents, &fromUser.ID, &recipients[i].ID)
+		}
+		return ids, splitErrs, nil
+	}
+
+	// 3. Verificar el límite diario y que el balance cubra el total con las comisiones
+	if err := s.checkDailyLimit(ctx, fromUser.ID, models.TransactionTypeTransfer, fromUser.DailyTransferLimitCents, total); err != nil {
+		return nil, nil, err
+	}
+	if err := s.checkFunds(fromAccount, total+totalFee); err != nil {
+		return nil, nil, err
+	}
+
+	// 4. Enviar todas las partes en un solo lote. Cada parte se enlaza con su comisión, pero las
+	// partes son independientes entre sí.
+	var purposes []string
+	for i := range splits {
+		purposes = append(purposes, "transfer")
+		if fees[i] > 0 {
+			purposes = append(purposes, "transfer_fee")
+		}
+	}
+	fromAccountID := fromAccount.TigerBeetleAccountID
+	transferIDs := make([]uint64, len(splits))
+	feeTransferIDs := make([]uint64, len(splits))
+	err = s.withTransferIDs(ctx, purposes, func(ids []uint64) (err error) {
+		_, span := tracing.Start(ctx, "TigerBeetleService.BatchTransfer")
+		defer func() { tracing.End(span, err) }()
+
+		groups := make([][]tigerbeetle.LinkedTransferRequest, len(splits))
+		next := 0
+		for i, split := range splits {
+			transferIDs[i] = ids[next]
+			groups[i] = []tigerbeetle.LinkedTransferRequest{
+				{FromAccountID: uint64(fromAccountID), ToAccountID: uint64(toAccounts[i].TigerBeetleAccountID), Amount: split.AmountCents, TransferID: ids[next]},
+			}
+			next++
+			if fees[i] > 0 {
+				feeTransferIDs[i] = ids[next]
+				groups[i] = append(groups[i], tigerbeetle.LinkedTransferRequest{
+					FromAccountID: uint64(fromAccountID), ToAccountID: uint64(tigerbeetle.FeeAccount), Amount: fees[i], TransferID: ids[next], Code: tigerbeetle.TransferCodeFee,
+				})
+				next++
+			}
+		}
+
+		groupErrs, err := s.tigerBeetleService.BatchTransfer(groups)
+		if err != nil {
+			return err
+		}
+		for i, groupErr := range groupErrs {
+			// Con llave de idempotencia, una parte ya aplicada por un intento anterior es exitosa
+			if _, idempotent := idempotency.KeyFromContext(ctx); idempotent && isAppliedTransfer(groupErr) {
+				log.Printf("Split transfer %d was already applied by an earlier attempt, replaying", transferIDs[i])
+				groupErr = nil
+			}
+			splitErrs[i] = groupErr
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error processing split transfer: %w", err)
+	}
+
+	// 5. Registrar cada parte, también las rechazadas, y notificar las aplicadas
+	var debited uint64
+	for i, split := range splits {
+		toUser, toAccount := recipients[i], toAccounts[i]
+		if splitErrs[i] != nil {
+			if errors.Is(splitErrs[i], tigerbeetle.ErrExceedsCredits) {
+				splitErrs[i] = s.refreshedFundsError(fromAccountID, split.AmountCents+fees[i])
+			}
+			log.Printf("Split transfer %d from user %s to user %s failed: %v", transferIDs[i], fromUser.ID, toUser.ID, splitErrs[i])
+			ids[i] = s.recordTransfer(ctx, fromUser, toUser, fromAccount, toAccount, transferIDs[i], split.AmountCents, models.TransactionStatusFailed, details)
+			continue
+		}
+
+		debited += split.AmountCents + fees[i]
+		ids[i] = s.recordTransfer(ctx, fromUser, toUser, fromAccount, toAccount, transferIDs[i], split.AmountCents, models.TransactionStatusCompleted, details)
+		s.recordFee(ctx, fromUser, fromAccount, feeTransferIDs[i], fees[i], ids[i])
+		s.invalidateBalances(toAccount.TigerBeetleAccountID)
+		s.publishBalance(toUser.ID, toAccount.TigerBeetleAccountID)
+		s.publishTransaction(models.TransactionEventTransfer, split.AmountCents, &fromUser.ID, &toUser.ID)
+		s.alertLargeTransaction(fromUser, models.TransactionEventTransfer, split.AmountCents, toUser)
+	}
+
+	if debited > 0 {
+		s.invalidateBalances(fromAccountID)
+		s.publishBalance(fromUser.ID, fromAccountID)
+	}
+
+	return ids, splitErrs, nil
+}
+
+// TransferBetweenAccounts realiza una transferencia entre dos cuentas bancarias, que pueden ser
+// del mismo titular, y cobra la comisión correspondiente. Retorna la comisión cobrada en centavos.
+func (s *UserService) TransferBetweenAccounts(ctx context.Context, fromAccID, toAccID uuid.UUID, amount uint64) (uint64, error) {
+	ctx, span := tracing.Start(ctx, "UserService.TransferBetweenAccounts")
+	transferFee, err := s.transferBetweenAccounts(ctx, fromAccID, toAccID, amount)
+	tracing.End(span, err)
+	return transferFee, err
+}
+
+// TransferToAccountNumber realiza una transferencia desde una cuenta bancaria hacia la cuenta con el
+// número indicado. Retorna la comisión cobrada en centavos.
+func (s *UserService) TransferToAccountNumber(ctx context.Context, fromAccID uuid.UUID, toAccountNumber string, amount uint64) (uint64, error) {
+	if s.bankAccountRepo == nil {
+		return 0, fmt.Errorf("bank accounts not configured")
+	}
+
+	if err := ValidateAccountNumber(toAccountNumber); err != nil {
+		return 0, err
+	}
+
+	lookupCtx, cancel := context.WithTimeout(ctx, queryTimeout)
+	toAccount, err := s.bankAccountRepo.GetByAccountNumber(lookupCtx, toAccountNumber)
+	cancel()
+	if err != nil {
+		return 0, err
+	}
+
+	return s.TransferBetweenAccounts(ctx, fromAccID, toAccount.ID, amount)
+}
+
+// transferBetweenAccounts obtiene ambas cuentas con sus titulares, realiza la transferencia y la
+// registra en la auditoría
+func (s *UserService) transferBetweenAccounts(ctx context.Context, fromAccID, toAccID uuid.UUID, amount uint64) (uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	if fromAccID == toAccID {
+		return 0, ErrSameAccount
+	}
+
+	fromAccount, fromUser, err := s.accountWithOwner(ctx, fromAccID)
+	if err != nil {
+		return 0, err
+	}
+
+	toAccount, toUser, err := s.accountWithOwner(ctx, toAccID)
+	if err != nil {
+		return 0, err
+	}
+
+	transferFee, err := s.transfer(ctx, fromUser, toUser, fromAccount, toAccount, amount, transferDetails{})
+	s.recordFinancialAudit(ctx, models.AuditActionTransfer, fromUser.ID, toUser.ID, amount, err)
+	return transferFee, err
+}
+
+// transfer mueve el monto entre dos cuentas bancarias, cobra la comisión a la cuenta origen y
+// registra la transferencia en PostgreSQL con los datos opcionales indicados
+func (s *UserService) transfer(ctx context.Context, fromUser, toUser *models.User, fromAccount, toAccount *models.BankAccount, amount uint64, details transferDetails) (uint64, error) {
+	// Una cuenta congelada no puede enviar ni recibir transferencias
+	if fromUser.IsFrozen || toUser.IsFrozen {
+		return 0, ErrAccountFrozen
+	}
+	if !fromAccount.IsActive || !toAccount.IsActive {
+		return 0, ErrAccountInactive
+	}
+	if err := s.checkKYC(fromUser, amount); err != nil {
+		return 0, err
+	}
+	if err := s.checkVelocity(ctx, fromUser, models.TransactionTypeTransfer, amount); err != nil {
+		return 0, err
+	}
+	if err := s.checkCompliance(ctx, fromUser, amount); err != nil {
+		return 0, err
+	}
+
+	transferFee := s.feeSchedule.Calculate(amount)
+
+	if s.tigerBeetleService == nil {
+		log.Printf("TigerBeetle disabled - would transfer %d (fee %d) from user %s to user %s", amount, transferFee, fromUser.Email, toUser.Email)
+	} else {
+		fromAccountID := fromAccount.TigerBeetleAccountID
+		toAccountID := toAccount.TigerBeetleAccountID
+
+		// 1. Verificar el límite diario de transferencias y que el balance de la cuenta
+		// origen cubra el monto y la comisión
+		if err := s.checkDailyLimit(ctx, fromUser.ID, models.TransactionTypeTransfer, fromUser.DailyTransferLimitCents, amount); err != nil {
+			return 0, err
+		}
+		err := s.checkFunds(fromAccount, amount+transferFee)
+		if err != nil {
+			return 0, err
+		}
+
+		// 2. Realizar la transferencia en TigerBeetle (enlazada con la comisión si la hay)
+		purposes := []string{"transfer"}
+		if transferFee > 0 {
+			purposes = append(purposes, "transfer_fee")
+		}
+		var transferID, feeTransferID uint64
+		err = s.withTransferIDs(ctx, purposes, func(ids []uint64) (err error) {
+			_, span := tracing.Start(ctx, "TigerBeetleService.Transfer")
+			defer func() { tracing.End(span, err) }()
+
+			transferID = ids[0]
+
+			if transferFee == 0 {
+				return s.tigerBeetleService.Transfer(uint64(fromAccountID), uint64(toAccountID), amount, ids[0])
+			}
+			feeTransferID = ids[1]
+			return s.tigerBeetleService.LinkedTransfer([]tigerbeetle.LinkedTransferRequest{
+				{FromAccountID: uint64(fromAccountID), ToAccountID: uint64(toAccountID), Amount: amount, TransferID: ids[0]},
+				{FromAccountID: uint64(fromAccountID), ToAccountID: uint64(tigerbeetle.FeeAccount), Amount: transferFee, TransferID: ids[1], Code: tigerbeetle.TransferCodeFee},
+			})
+		})
+		if err != nil {
+			if errors.Is(err, tigerbeetle.ErrExceedsCredits) {
+				return 0, s.refreshedFundsError(fromAccountID, amount+transferFee)
+			}
+			return 0, fmt.Errorf("error processing transfer: %w", err)
+		}
+		s.invalidateBalances(fromAccountID, toAccountID)
+		txID := s.recordTransfer(ctx, fromUser, toUser, fromAccount, toAccount, transferID, amount, models.TransactionStatusCompleted, details)
+		s.recordFee(ctx, fromUser, fromAccount, feeTransferID, transferFee, txID)
+		s.publishBalance(fromUser.ID, fromAccountID)
+		s.publishBalance(toUser.ID, toAccountID)
+	}
+
+	s.publishTransaction(models.TransactionEventTransfer, amount, &fromUser.ID, &toUser.ID)
+	s.alertLargeTransaction(fromUser, models.TransactionEventTransfer, amount, toUser)
+	return transferFee, nil
+}
+
+// recordTransfer registra en PostgreSQL una transferencia ya realizada en TigerBeetle. Las cuentas
+// principales no están en bank_accounts, por lo que se registran como nulas y la transferencia
+// queda a nombre del usuario origen, con el usuario destino como destinatario. Un error solo se
+// registra en el log: el dinero ya se movió. Retorna el ID de la transacción registrada, o uuid.Nil
+// si no se registró.
+func (s *UserService) recordTransfer(ctx context.Context, fromUser, toUser *models.User, fromAccount, toAccount *models.BankAccount, transferID, amount uint64, status string, details transferDetails) uuid.UUID {
+	if s.transactionRepo == nil {
+		return uuid.Nil
+	}
+
+	tx := &models.Transaction{
+		TigerBeetleTransferID: int64(transferID),
+		Amount:                int64(amount),
+		Currency:              fromAccount.Currency,
+		Description:           details.description,
+		TransactionType:       models.TransactionTypeTransfer,
+		Status:                status,
+		Category:              details.category,
+		Tags:                  details.tags,
+		CreatedBy:             &fromUser.ID,
+		RecipientUserID:       &toUser.ID,
+	}
+	if fromAccount.ID != uuid.Nil {
+		tx.FromAccountID = &fromAccount.ID
+	}
+	if toAccount.ID != uuid.Nil {
+		tx.ToAccountID = &toAccount.ID
+	}
+
+	created, err := s.transactionRepo.Create(ctx, tx)
+	if err != nil {
+		log.Printf("Error recording transfer %d of user %s: %v", transferID, fromUser.ID, err)
+		return uuid.Nil
+	}
+	return created.ID
+}
+
+// recordFee registra en PostgreSQL la comisión cobrada por una transferencia ya realizada, enlazada
+// con la transacción de la transferencia si se registró. No hace nada si no hubo comisión.
+func (s *UserService) recordFee(ctx context.Context, fromUser *models.User, fromAccount *models.BankAccount, transferID, fee uint64, transactionID uuid.UUID) {
+	if s.transactionRepo == nil || fee == 0 {
+		return
+	}
+
+	tx := &models.Transaction{
+		TigerBeetleTransferID: int64(transferID),
+		Amount:                int64(fee),
+		Currency:              fromAccount.Currency,
+		TransactionType:       models.TransactionTypeFee,
+		Status:                models.TransactionStatusCompleted,
+		CreatedBy:             &fromUser.ID,
+	}
+	if fromAccount.ID != uuid.Nil {
+		tx.FromAccountID = &fromAccount.ID
+	}
+	if transactionID != uuid.Nil {
+		tx.RelatedTransactionID = &transactionID
+	}
+
+	if _, err := s.transactionRepo.Create(ctx, tx); err != nil {
+		log.Printf("Error recording fee %d of user %s: %v", transferID, fromUser.ID, err)
+	}
+}
+
+// recordMovement registra en PostgreSQL un depósito o retiro ya realizado en TigerBeetle, para que
+// cuente en los límites diarios, los controles de fraude y la conciliación. Como en recordTransfer,
+// la cuenta principal se registra como nula: el retiro queda a nombre del usuario y el depósito lo
+// tiene como destinatario. Un error solo se registra en el log: el dinero ya se movió.
+func (s *UserService) recordMovement(ctx context.Context, user *models.User, account *models.BankAccount, txType string, transferID, amount uint64) {
+	if s.transactionRepo == nil {
+		return
+	}
+
+	tx := &models.Transaction{
+		TigerBeetleTransferID: int64(transferID),
+		Amount:                int64(amount),
+		Currency:              account.Currency,
+		TransactionType:       txType,
+		Status:                models.TransactionStatusCompleted,
+	}
+	if txType == models.TransactionTypeDeposit {
+		tx.RecipientUserID = &user.ID
+		if account.ID != uuid.Nil {
+			tx.ToAccountID = &account.ID
+		}
+	} else {
+		tx.CreatedBy = &user.ID
+		if account.ID != uuid.Nil {
+			tx.FromAccountID = &account.ID
+		}
+	}
+
+	if _, err := s.transactionRepo.Create(ctx, tx); err != nil {
+		log.Printf("Error recording %s %d of user %s: %v", txType, transferID, user.ID, err)
+	}
+}
+
+// primaryAccount retorna la cuenta principal del usuario: la que se crea junto con él y cuyo ID
+// de TigerBeetle se guarda en el propio usuario. Sin TigerBeetle la cuenta no necesita ese ID.
+func (s *UserService) primaryAccount(user *models.User) (*models.BankAccount, error) {
+	account := &models.BankAccount{
+		UserID:   user.ID,
+		Currency: models.DefaultCurrency,
+		IsActive: true,
+	}
+
+	if user.TigerBeetleAccountID != nil {
+		account.TigerBeetleAccountID = *user.TigerBeetleAccountID
+	} else if s.tigerBeetleService != nil {
+		return nil, fmt.Errorf("user %s: %w", user.ID, ErrNoTigerBeetleAccount)
+	}
+
+	return account, nil
+}
+
+// accountWithOwner obtiene una cuenta bancaria junto con su titular
+func (s *UserService) accountWithOwner(ctx context.Context, accountID uuid.UUID) (*models.BankAccount, *models.User, error) {
+	if s.bankAccountRepo == nil {
+		return nil, nil, fmt.Errorf("bank account repository not configured")
+	}
+
+	account, err := s.bankAccountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	owner, err := s.userRepo.GetByID(ctx, account.UserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting account owner: %w", err)
+	}
+
+	return account, owner, nil
+}
+
+// AssociateTigerBeetleAccount crea y asocia una cuenta TigerBeetle a un usuario que no la tiene
+func (s *UserService) AssociateTigerBeetleAccount(userID uuid.UUID) error {
+	ctx, cancel := newQueryContext()
+	defer cancel()
+
+	// 1. Obtener el usuario
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("error getting user: %w", err)
+	}
+
+	if s.tigerBeetleService == nil {
+		log.Printf("TigerBeetle disabled - would associate account to user %s", user.Email)
+		return nil
+	}
+
+	if user.TigerBeetleAccountID != nil {
+		return nil
+	}
+
+	// 2. Crear la cuenta en TigerBeetle y guardar su ID
+	accountID := GenerateTigerBeetleAccountID(user.ID)
+	if _, err := s.tigerBeetleService.CreateUserAccount(accountID); err != nil {
+		return fmt.Errorf("error creating TigerBeetle account: %w", err)
+	}
+
+	if err := s.userRepo.UpdateTigerBeetleAccountID(ctx, user.ID, int64(accountID)); err != nil {
+		return fmt.Errorf("error saving TigerBeetle account ID: %w", err)
+	}
+
+	return nil
+}
+
+// cachedAccountBalance obtiene el balance de una cuenta desde la caché si está configurada,
+// consultando TigerBeetle y guardando el resultado cuando no está en caché
+func (s *UserService) cachedAccountBalance(accountID int64) (uint64, error) {
+	if s.balanceCache == nil {
+		return s.accountBalance(accountID)
+	}
+
+	if balance, ok := s.balanceCache.Get(uint64(accountID)); ok {
+		return uint64(balance), nil
+	}
+
+	balance, err := s.accountBalance(accountID)
+	if err != nil {
+		return 0, err
+	}
+
+	s.balanceCache.Set(uint64(accountID), int64(balance), cache.DefaultBalanceTTL)
+	return balance, nil
+}
+
+// invalidateBalances elimina de la caché los balances de las cuentas modificadas por una operación
+func (s *UserService) invalidateBalances(accountIDs ...int64) {
+	if s.balanceCache == nil {
+		return
+	}
+
+	for _, accountID := range accountIDs {
+		s.balanceCache.Invalidate(uint64(accountID))
+	}
+}
+
+// TigerBeetleStatus verifica la conectividad con TigerBeetle mediante Ping y retorna su estado
+// junto con el del circuit breaker que protege sus llamadas ("closed", "half-open" u "open").
+// El estado es "disabled" si no está configurado, "connected" si respondió, "degraded" si falló
+// pero el circuito sigue cerrado, y "disconnected" si el circuito está abierto.
+func (s *UserService) TigerBeetleStatus() (status string, circuit string) {
+	if s.tigerBeetleService == nil {
+		return "disabled", "none"
+	}
+
+	err := s.tigerBeetleService.Ping()
+	if err != nil {
+		log.Printf("TigerBeetle ping failed: %v", err)
+	}
+
+	circuit = "none"
+	breaker, ok := s.tigerBeetleService.(*tigerbeetle.CircuitBreakerService)
+	if ok {
+		circuit = breaker.State()
+	}
+
+	switch {
+	case err == nil:
+		return "connected", circuit
+	case ok && circuit != "open":
+		return "degraded", circuit
+	default:
+		return "disconnected", circuit
+	}
+}
+
+// accountBalance obtiene el balance disponible (créditos - débitos) de una cuenta TigerBeetle.
+// Una cuenta en sobregiro tiene balance disponible cero.
+func (s *UserService) accountBalance(accountID int64) (uint64, error) {
+	balance, err := s.signedAccountBalance(accountID)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(max(balance, 0)), nil
+}
+
+// signedAccountBalance obtiene el balance de una cuenta TigerBeetle, negativo si está en sobregiro
+func (s *UserService) signedAccountBalance(accountID int64) (int64, error) {
+	debits, credits, err := s.tigerBeetleService.GetAccountBalance(uint64(accountID))
+	if err != nil {
+		return 0, fmt.Errorf("error getting balance: %w", err)
+	}
+	return int64(credits) - int64(debits), nil
+}
+
+// nextTransferID retorna el ID para la siguiente transferencia de TigerBeetle. Si la operación
+// trae una llave de idempotencia el ID se deriva de ella, de modo que un reintento genere el
+// mismo ID y TigerBeetle lo rechace como duplicado; si no, se toma del generador.
+func (s *UserService) nextTransferID(ctx context.Context, purpose string) (uint64, error) {
+	if key, ok := idempotency.KeyFromContext(ctx); ok {
+		return key.TransferID(purpose), nil
+	}
+	return s.transferIDs.Next(ctx)
+}
+
+// withTransferIDs obtiene un ID de transferencia por cada propósito y ejecuta submit con ellos.
+// Si TigerBeetle rechaza un ID por duplicado se generan IDs nuevos y se reintenta, hasta
+// maxTransferIDAttempts veces. Los IDs derivados de una llave de idempotencia no se
+// reintentan: si TigerBeetle ya tiene las mismas transferencias la operación se aplicó en un
+// intento anterior y se trata como exitosa. Un duplicado con otros datos significa que la llave
+// se reutilizó para otra operación y se retorna como error.
+func (s *UserService) withTransferIDs(ctx context.Context, purposes []string, submit func(ids []uint64) error) error {
+	_, idempotent := idempotency.KeyFromContext(ctx)
+
+	var err error
+	for attempt := 1; attempt <= maxTransferIDAttempts; attempt++ {
+		ids := make([]uint64, len(purposes))
+		for i, purpose := range purposes {
+			if ids[i], err = s.nextTransferID(ctx, purpose); err != nil {
+				return err
+			}
+		}
+
+		err = submit(ids)
+		if idempotent && isAppliedTransfer(err) {
+			log.Printf("Transfer IDs %v were already applied by an earlier attempt, replaying", ids)
+			return nil
+		}
+		if idempotent || !errors.Is(err, tigerbeetle.ErrTransferExists) {
+			return err
+		}
+		log.Printf("Transfer IDs %v already exist in TigerBeetle (attempt %d), regenerating", ids, attempt)
+	}
+
+	return err
+}
+
+// isAppliedTransfer indica si TigerBeetle rechazó la transferencia porque ya existe otra idéntica
+func isAppliedTransfer(err error) bool {
+	return errors.Is(err, tigerbeetle.ErrTransferExists) && !errors.Is(err, tigerbeetle.ErrTransferMismatch)
+}
+
+// checkDailyLimit verifica que el monto, sumado a lo ya operado hoy con el mismo tipo de
+// transacción, no supere el límite diario del usuario. Sin repositorio de transacciones
+// no hay historial contra el cual comparar y la verificación se omite.
+func (s *UserService) checkDailyLimit(ctx context.Context, userID uuid.UUID, txType string, limit int64, amount uint64) error {
+	if s.transactionRepo == nil {
+		return nil
+	}
+
+	used, err := s.transactionRepo.GetDailyTotal(ctx, userID, txType, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if limit < 0 || used+amount > uint64(limit) {
+		return &DailyLimitExceededError{
+			TransactionType: txType,
+			Limit:           uint64(max(limit, 0)),
+			Used:            used,
+			Requested:       amount,
+		}
+	}
+
+	return nil
+}
+
+// checkVelocity verifica que el usuario no esté realizando una ráfaga sospechosa de transacciones
+func (s *UserService) checkVelocity(ctx context.Context, user *models.User, txType string, amount uint64) error {
+	if s.velocityChecker == nil {
+		return nil
+	}
+	return s.velocityChecker.Check(ctx, user.ID, amount, txType, user.DailyTransferLimitCents)
+}
+
+// checkCompliance aplica los controles antilavado al monto que sale de las cuentas del usuario.
+// Cada bloqueo queda registrado en la auditoría.
+func (s *UserService) checkCompliance(ctx context.Context, user *models.User, amount uint64) error {
+	if s.compliance == nil {
+		return nil
+	}
+
+	decision, err := s.compliance.Check(ctx, user.ID, amount)
+	if err != nil {
+		return err
+	}
+	if decision.Allowed {
+		return nil
+	}
+
+	blockErr := fmt.Errorf("%w: %s", compliance.ErrBlocked, decision.Reason)
+	zap.L().Warn("Operation blocked by compliance",
+		zap.String("user_id", user.ID.String()),
+		zap.Uint64("amount_cents", amount),
+		zap.String("reason", decision.Reason),
+	)
+	s.recordFinancialAudit(ctx, models.AuditActionComplianceBlock, user.ID, user.ID, amount, blockErr)
+	return blockErr
+}
+
+// checkKYC exige que el usuario tenga KYC aprobado para mover montos mayores al umbral configurado
+func (s *UserService) checkKYC(user *models.User, amount uint64) error {
+	if s.kycThreshold == 0 || amount <= s.kycThreshold {
+		return nil
+	}
+	if user.KYCStatus != models.KYCStatusApproved {
+		return compliance.ErrKYCRequired
+	}
+	return nil
+}
+
+// checkFunds verifica que la cuenta tenga balance suficiente para debitar el monto indicado y
+// que después del débito conserve el balance mínimo de su tipo de cuenta. Las cuentas de usuario
+// en TigerBeetle se crean con DebitsMustNotExceedCredits, que además protege contra débitos
+// concurrentes; por eso ninguna cuenta puede quedar en negativo (ver ErrOverdraftNotSupported).
+func (s *UserService) checkFunds(account *models.BankAccount, amount uint64) error {
+	balance, err := s.signedAccountBalance(account.TigerBeetleAccountID)
+	if err != nil {
+		return err
+	}
+	remaining := balance - int64(amount)
+
+	if remaining < 0 {
+		return &InsufficientFundsError{Expected: amount, Actual: uint64(max(balance, 0))}
+	}
+	if remaining < account.MinimumBalanceCents {
+		return ErrBelowMinimumBalance
+	}
+	return nil
+}
+
+// refreshedFundsError se usa cuando TigerBeetle rechaza una transferencia que pasó la
+// verificación previa de balance (otra operación la debitó entre ambas). Vuelve a leer
+// el balance real para informar al cliente el monto disponible.
+func (s *UserService) refreshedFundsError(accountID int64, amount uint64) error {
+	balance, err := s.accountBalance(accountID)
+	if err != nil {
+		log.Printf("Error refreshing balance of account %d: %v", accountID, err)
+	}
+	return &InsufficientFundsError{Expected: amount, Actual: balance}
+}
+
+// GetUser obtiene un usuario por su ID
+func (s *UserService) GetUser(userID uuid.UUID) (*models.User, error) {
+	ctx, cancel := newQueryContext()
+	defer cancel()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting user: %w", err)
+	}
+	return user, nil
+}
+
+// VerifyEmail marca como verificado el email del usuario
+func (s *UserService) VerifyEmail(ctx context.Context, userID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	if err := s.userRepo.MarkEmailVerified(ctx, userID); err != nil {
+		return fmt.Errorf("error verifying email: %w", err)
+	}
+	return nil
+}
+
+// ResetPassword establece una nueva contraseña para el usuario
+func (s *UserService) ResetPassword(ctx context.Context, userID uuid.UUID, newPassword string) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("error getting user: %w", err)
+	}
+
+	return s.setPassword(ctx, user, newPassword)
+}
+
+// ChangePassword cambia la contraseña del usuario tras verificar la contraseña actual
+func (s *UserService) ChangePassword(userID uuid.UUID, oldPassword, newPassword string) error {
+	ctx, cancel := newQueryContext()
+	defer cancel()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("error getting user: %w", err)
+	}
+
+	if err := s.userRepo.VerifyPassword(user.PasswordHash, oldPassword); err != nil {
+		return ErrIncorrectPassword
+	}
+
+	return s.setPassword(ctx, user, newPassword)
+}
+
+// setPassword valida la complejidad de la nueva contraseña, verifica que no esté en el
+// historial reciente, la guarda y la registra en el historial. Todas las sesiones del usuario
+// se revocan para que un refresh token robado no sobreviva al cambio.
+func (s *UserService) setPassword(ctx context.Context, user *models.User, newPassword string) error {
+	if err := auth.ValidatePasswordComplexity(newPassword); err != nil {
+		return err
+	}
+
+	if err := s.checkPasswordHistory(ctx, user, newPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("error hashing password: %w", err)
+	}
+
+	// Las sesiones se revocan antes de guardar la contraseña: si la revocación falla, la
+	// contraseña no cambia y puede reintentarse
+	if err := s.revokeSessions(ctx, user.ID); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, string(hashedPassword)); err != nil {
+		return fmt.Errorf("error updating password: %w", err)
+	}
+
+	// Y de nuevo después, por si se renovó una sesión mientras tanto
+	if err := s.revokeSessions(ctx, user.ID); err != nil {
+		log.Printf("Error revoking sessions of user %s after password change: %v", user.ID, err)
+	}
+
+	s.recordPasswordHistory(ctx, user.ID, string(hashedPassword))
+	return nil
+}
+
+// revokeSessions revoca todos los refresh tokens del usuario
+func (s *UserService) revokeSessions(ctx context.Context, userID uuid.UUID) error {
+	if s.refreshTokens == nil {
+		return nil
+	}
+	return s.refreshTokens.RevokeAllByUser(ctx, userID)
+}
+
+// checkPasswordHistory retorna ErrPasswordReused si la contraseña coincide con la actual
+// o con alguna de las últimas PasswordHistorySize
+func (s *UserService) checkPasswordHistory(ctx context.Context, user *models.User, password string) error {
+	hashes := []string{user.PasswordHash}
+	if s.passwordHistory != nil {
+		recent, err := s.passwordHistory.ListRecent(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("error getting password history: %w", err)
+		}
+		hashes = append(hashes, recent...)
+	}
+
+	for _, hash := range hashes {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+			return ErrPasswordReused
+		}
+	}
+	return nil
+}
+
+// recordPasswordHistory agrega un hash al historial de contraseñas. Un fallo se registra
+// en el log pero no revierte el cambio de contraseña.
+func (s *UserService) recordPasswordHistory(ctx context.Context, userID uuid.UUID, passwordHash string) {
+	if s.passwordHistory == nil {
+		return
+	}
+
+	if err := s.passwordHistory.Add(ctx, userID, passwordHash); err != nil {
+		log.Printf("Error recording password history for user %s: %v", userID, err)
+	}
+}
+
+// SetTOTPSecret guarda el secreto TOTP cifrado del usuario, pendiente de verificación
+func (s *UserService) SetTOTPSecret(ctx context.Context, userID uuid.UUID, encryptedSecret string) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	if err := s.userRepo.SetTOTPSecret(ctx, userID, encryptedSecret); err != nil {
+		return fmt.Errorf("error setting totp secret: %w", err)
+	}
+	return nil
+}
+
+// EnableTOTP activa la autenticación de dos factores del usuario
+func (s *UserService) EnableTOTP(ctx context.Context, userID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	if err := s.userRepo.EnableTOTP(ctx, userID); err != nil {
+		return fmt.Errorf("error enabling totp: %w", err)
+	}
+	return nil
+}
+
+// UpdateUserFlags ajusta los indicadores de cuenta de un usuario (uso administrativo)
+func (s *UserService) UpdateUserFlags(ctx context.Context, userID uuid.UUID, flags *models.UpdateUserFlagsRequest) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	user, err := s.userRepo.UpdateFlags(ctx, userID, flags)
+	if err != nil {
+		return nil, fmt.Errorf("error updating user flags: %w", err)
+	}
+	return user, nil
+}
+
+// UpdatePhone cambia el teléfono del usuario. El teléfono se valida en formato E.164 tras
+// eliminar espacios y guiones; retorna validation.ErrInvalidPhone si no es válido.
+func (s *UserService) UpdatePhone(ctx context.Context, userID uuid.UUID, phone string) error {
+	if err := validation.ValidatePhone(phone); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	if err := s.userRepo.UpdatePhone(ctx, userID, validation.NormalizePhone(phone)); err != nil {
+		return fmt.Errorf("error updating phone: %w", err)
+	}
+	return nil
+}
+
+// UpdateDateOfBirth cambia la fecha de nacimiento del usuario. Retorna validation.ErrUnderage
+// si con la nueva fecha el usuario no alcanza la edad mínima.
+func (s *UserService) UpdateDateOfBirth(ctx context.Context, userID uuid.UUID, dateOfBirth time.Time) error {
+	if err := validation.ValidateMinimumAge(dateOfBirth, time.Now()); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	if err := s.userRepo.UpdateDateOfBirth(ctx, userID, dateOfBirth); err != nil {
+		return fmt.Errorf("error updating date of birth: %w", err)
+	}
+	return nil
+}
+
+// RecordLogin registra el inicio de sesión exitoso del usuario en last_login_at
+func (s *UserService) RecordLogin(ctx context.Context, userID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	if err := s.userRepo.UpdateLastLogin(ctx, userID); err != nil {
+		return fmt.Errorf("error recording login: %w", err)
+	}
+	return nil
+}
+
+// UpdateKYC registra la revisión KYC de un usuario hecha por un administrador
+func (s *UserService) UpdateKYC(ctx context.Context, userID uuid.UUID, req *models.UpdateKYCRequest) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	user, err := s.userRepo.UpdateKYC(ctx, userID, req.KYCStatus, req.KYCDocumentRef)
+	if err != nil {
+		return nil, fmt.Errorf("error updating kyc: %w", err)
+	}
+
+	log.Printf("KYC status of user %s set to %s", userID, req.KYCStatus)
+	return user, nil
+}
+
+// FreezeAccount congela la cuenta de un usuario, bloqueando depósitos, retiros y transferencias
+func (s *UserService) FreezeAccount(userID uuid.UUID, reason string) error {
+	ctx, cancel := newQueryContext()
+	defer cancel()
+
+	if err := s.userRepo.Freeze(ctx, userID, reason); err != nil {
+		return fmt.Errorf("error freezing account: %w", err)
+	}
+
+	log.Printf("Account of user %s frozen: %s", userID, reason)
+	return nil
+}
+
+// UnfreezeAccount descongela la cuenta de un usuario
+func (s *UserService) UnfreezeAccount(userID uuid.UUID) error {
+	ctx, cancel := newQueryContext()
+	defer cancel()
+
+	if err := s.userRepo.Unfreeze(ctx, userID); err != nil {
+		return fmt.Errorf("error unfreezing account: %w", err)
+	}
+
+	log.Printf("Account of user %s unfrozen", userID)
+	return nil
+}
+
+// ListUsers obtiene una página de usuarios. afterID es el cursor retornado en la página anterior
+// (nil para la primera página).
+func (s *UserService) ListUsers(afterID *uuid.UUID, limit int) (*models.UserPage, error) {
+	ctx, cancel := newQueryContext()
+	defer cancel()
+
+	// Se pide un elemento extra para saber si existe una página siguiente
+	users, err := s.userRepo.List(ctx, afterID, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("error listing users: %w", err)
+	}
+
+	return newUserPage(users, limit), nil
+}
+
+// SearchUsers obtiene una página de usuarios cuyo email, nombre o apellido contienen query
+func (s *UserService) SearchUsers(query string, afterID *uuid.UUID, limit int) (*models.UserPage, error) {
+	ctx, cancel := newQueryContext()
+	defer cancel()
+
+	users, err := s.userRepo.Search(ctx, query, limit+1, afterID)
+	if err != nil {
+		return nil, fmt.Errorf("error searching users: %w", err)
+	}
+
+	return newUserPage(users, limit), nil
+}
+
+// newUserPage arma la página a partir de hasta limit+1 usuarios: el elemento extra indica que
+// existe una página siguiente
+func newUserPage(users []*models.User, limit int) *models.UserPage {
+	page := &models.UserPage{}
+	if len(users) > limit {
+		users = users[:limit]
+		page.NextCursor = &users[limit-1].ID
+	}
+
+	page.Users = make([]models.UserResponse, len(users))
+	for i, user := range users {
+		page.Users[i] = user.ToResponse()
+	}
+
+	return page
+}
+
+// GetUserByEmail obtiene un usuario por su email
+func (s *UserService) GetUserByEmail(email string) (*models.User, error) {
+	ctx, cancel := newQueryContext()
+	defer cancel()
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("error getting user by email: %w", err)
+	}
+	return user, nil
+}
+
+// Authenticate valida las credenciales de un usuario bloqueando su fila durante el inicio de sesión,
+// de modo que dos logins simultáneos de la misma cuenta no se procesen en paralelo
+func (s *UserService) Authenticate(ctx context.Context, email, password string) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	tx, err := s.userRepo.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	user, err := s.userRepo.GetByEmailForUpdate(ctx, tx, email)
+	if err != nil {
+		if errors.Is(err, ErrConcurrentLogin) {
+			return nil, err
+		}
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("error getting user by email: %w", err)
+	}
+
+	if !user.IsActive {
+		return nil, ErrAccountDeactivated
+	}
+
+	if err := s.userRepo.VerifyPassword(user.PasswordHash, password); err != nil {
+		// Liberar el bloqueo antes de auditar: la FK de audit_logs necesita leer la fila del usuario
+		tx.Rollback()
+		s.recordAudit(ctx, &user.ID, models.AuditActionLoginFailed)
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	s.recordAudit(ctx, &user.ID, models.AuditActionLogin)
+	return user, nil
+}
+
+// recordAudit registra un evento de auditoría sin interrumpir la operación si falla
+func (s *UserService) recordAudit(ctx context.Context, actorUserID *uuid.UUID, action string) {
+	if s.auditLogRepo == nil {
+		return
+	}
+
+	entry := &models.AuditLog{ActorUserID: actorUserID, Action: action}
+	if err := s.auditLogRepo.Record(ctx, entry); err != nil {
+		log.Printf("Error recording audit log %s: %v", action, err)
+	}
+}
+
+// recordFinancialAudit registra en la auditoría el resultado de una operación financiera. Usa un
+// contexto propio para que el registro se complete aunque la solicitud haya sido cancelada.
+func (s *UserService) recordFinancialAudit(ctx context.Context, action string, userID, targetUserID uuid.UUID, amount uint64, opErr error) {
+	if s.auditLogRepo == nil {
+		return
+	}
+
+	auditCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), queryTimeout)
+	defer cancel()
+
+	amountCents := int64(amount)
+	entry := &models.AuditLog{
+		ActorUserID: &userID,
+		Action:      action,
+		EntityType:  "user",
+		EntityID:    targetUserID.String(),
+		AmountCents: &amountCents,
+		Status:      models.AuditStatusSuccess,
+		RemoteAddr:  audit.RemoteAddrFromContext(ctx),
+	}
+	if opErr != nil {
+		entry.Status = models.AuditStatusFailure
+		entry.ErrorText = opErr.Error()
+	}
+
+	if err := s.auditLogRepo.Record(auditCtx, entry); err != nil {
+		log.Printf("Error recording audit log %s: %v", action, err)
+	}
+}
+
+// GetActivity obtiene la línea de tiempo de un usuario combinando eventos de autenticación,
+// transacciones salientes y notificaciones, ordenada de la más reciente a la más antigua
+func (s *UserService) GetActivity(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]models.UserActivity, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	var (
+		authEvents    []*models.AuditLog
+		transactions  []*models.Transaction
+		notifications []*models.Notification
+	)
+
+	g, gctx := errgroup.WithContext(ctx)
+
+	if s.auditLogRepo != nil {
+		g.Go(func() error {
+			var err error
+			authEvents, err = s.auditLogRepo.ListAuthEvents(gctx, userID, from, to)
+			return err
+		})
+	}
+
+	if s.transactionRepo != nil {
+		g.Go(func() error {
+			var err error
+			transactions, err = s.transactionRepo.ListOutgoingByUser(gctx, userID, from, to)
+			return err
+		})
+	}
+
+	if s.notificationRepo != nil {
+		g.Go(func() error {
+			var err error
+			notifications, err = s.notificationRepo.ListByUser(gctx, userID, from, to)
+			return err
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, fmt.Errorf("error getting user activity: %w", err)
+	}
+
+	activity := make([]models.UserActivity, 0, len(authEvents)+len(transactions)+len(notifications))
+
+	for _, event := range authEvents {
+		details := map[string]interface{}{"action": event.Action}
+		for k, v := range event.Details {
+			details[k] = v
+		}
+		if event.RemoteAddr != "" {
+			details["remote_addr"] = event.RemoteAddr
+		}
+		activity = append(activity, models.UserActivity{
+			Timestamp: event.CreatedAt,
+			EventType: models.ActivityEventAuth,
+			Details:   details,
+		})
+	}
+
+	for _, tx := range transactions {
+		details := map[string]interface{}{
+			"id":               tx.ID,
+			"transaction_type": tx.TransactionType,
+			"amount":           tx.Amount,
+			"status":           tx.Status,
+			"description":      tx.Description,
+		}
+		if tx.ToAccountID != nil {
+			details["to_account_id"] = *tx.ToAccountID
+		}
+		activity = append(activity, models.UserActivity{
+			Timestamp: tx.CreatedAt,
+			EventType: models.ActivityEventTransaction,
+			Details:   details,
+		})
+	}
+
+	for _, n := range notifications {
+		activity = append(activity, models.UserActivity{
+			Timestamp: n.CreatedAt,
+			EventType: models.ActivityEventNotification,
+			Details: map[string]interface{}{
+				"channel":           n.Channel,
+				"notification_type": n.NotificationType,
+				"subject":           n.Subject,
+			},
+		})
+	}
+
+	sort.SliceStable(activity, func(i, j int) bool {
+		return activity[i].Timestamp.After(activity[j].Timestamp)
+	})
+
+	return activity, nil
+}
+
+// exportPageSize es la cantidad de transacciones que se leen por consulta al exportar los datos
+const exportPageSize = 500
+
+// ExportUserData reúne el usuario, sus cuentas bancarias, sus transacciones y los eventos de
+// auditoría que lo involucran
+func (s *UserService) ExportUserData(ctx context.Context, userID uuid.UUID) (*models.UserDataExport, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &models.UserDataExport{
+		ExportedAt:   time.Now().UTC(),
+		User:         user.ToResponse(),
+		BankAccounts: []*models.BankAccount{},
+		Transactions: []*models.Transaction{},
+		AuditLogs:    []*models.AuditLog{},
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+
+	if s.bankAccountRepo != nil {
+		g.Go(func() error {
+			accounts, err := s.bankAccountRepo.GetByUserID(gctx, userID)
+			if err != nil {
+				return err
+			}
+			export.BankAccounts = append(export.BankAccounts, accounts...)
+			return nil
+		})
+	}
+
+	if s.transactionRepo != nil {
+		g.Go(func() error {
+			var afterID *uuid.UUID
+			for {
+				transactions, err := s.transactionRepo.GetByUserID(gctx, userID, afterID, exportPageSize)
+				if err != nil {
+					return err
+				}
+				export.Transactions = append(export.Transactions, transactions...)
+				if len(transactions) < exportPageSize {
+					return nil
+				}
+				afterID = &transactions[len(transactions)-1].ID
+			}
+		})
+	}
+
+	if s.auditLogRepo != nil {
+		g.Go(func() error {
+			entries, err := s.auditLogRepo.ListByUser(gctx, userID)
+			if err != nil {
+				return err
+			}
+			export.AuditLogs = append(export.AuditLogs, entries...)
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, fmt.Errorf("error exporting user data: %w", err)
+	}
+
+	return export, nil
+}
+
+// EraseUserData elimina definitivamente los datos del usuario (derecho al olvido). Solo se permite
+// si todas sus cuentas tienen balance cero en TigerBeetle; las cuentas de TigerBeetle no se borran
+// porque el ledger es inmutable, pero dejan de estar asociadas a datos personales.
+func (s *UserService) EraseUserData(ctx context.Context, userID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if s.tigerBeetleService != nil {
+		accountIDs := []int64{}
+		if user.TigerBeetleAccountID != nil {
+			accountIDs = append(accountIDs, *user.TigerBeetleAccountID)
+		}
+		if s.bankAccountRepo != nil {
+			accounts, err := s.bankAccountRepo.GetByUserID(ctx, userID)
+			if err != nil {
+				return fmt.Errorf("error getting bank accounts: %w", err)
+			}
+			for _, account := range accounts {
+				accountIDs = append(accountIDs, account.TigerBeetleAccountID)
+			}
+		}
+
+		for _, accountID := range accountIDs {
+			balance, err := s.signedAccountBalance(accountID)
+			if err != nil {
+				return err
+			}
+			if balance != 0 {
+				return ErrBalanceRemaining
+			}
+		}
+	}
+
+	if err := s.userRepo.HardDelete(ctx, userID); err != nil {
+		return err
+	}
+
+	log.Printf("User %s permanently deleted at their request", userID)
+	return nil
+}
+
+// GetTransactionHistory obtiene una página del historial de transacciones del usuario.
+// filter.Cursor es el cursor retornado en la página anterior (nil para la primera página).
+func (s *UserService) GetTransactionHistory(ctx context.Context, userID uuid.UUID, filter models.TransactionFilter) (*models.TransactionPage, error) {
+	if s.transactionRepo == nil {
+		return nil, fmt.Errorf("transactions not configured")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	// Se pide un elemento extra para saber si existe una página siguiente
+	limit := filter.Limit
+	filter.Limit = limit + 1
+	transactions, err := s.transactionRepo.Filter(ctx, userID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("error getting transaction history: %w", err)
+	}
+
+	page := &models.TransactionPage{Transactions: transactions}
+	if len(transactions) > limit {
+		page.Transactions = transactions[:limit]
+		page.NextCursor = &transactions[limit-1].ID
+	}
+	if page.Transactions == nil {
+		page.Transactions = []*models.Transaction{}
+	}
+
+	return page, nil
+}
+
+// UpdateTransactionCategory cambia la categoría de una transacción del usuario. Una categoría
+// vacía la deja sin categoría.
+func (s *UserService) UpdateTransactionCategory(ctx context.Context, userID, txID uuid.UUID, category string) error {
+	if s.transactionRepo == nil {
+		return fmt.Errorf("transactions not configured")
+	}
+
+	category, err := normalizeCategory(category)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	return s.transactionRepo.UpdateCategory(ctx, userID, txID, category)
+}
+
+// GetSpendingByCategory obtiene el gasto del usuario entre from y to agrupado por categoría
+func (s *UserService) GetSpendingByCategory(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]models.CategorySpending, error) {
+	if s.transactionRepo == nil {
+		return nil, fmt.Errorf("transactions not configured")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	breakdown, err := s.transactionRepo.GetCategoryBreakdown(ctx, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error getting spending by category: %w", err)
+	}
+	return breakdown, nil
+}
+
+// GetSpendingSummary obtiene el resumen de movimientos del usuario entre from y to. Si hay caché
+// configurada el resumen se guarda durante cache.DefaultSpendingSummaryTTL.
+func (s *UserService) GetSpendingSummary(ctx context.Context, userID uuid.UUID, from, to time.Time) (*models.SpendingSummary, error) {
+	if s.transactionRepo == nil {
+		return nil, fmt.Errorf("transactions not configured")
+	}
+
+	if s.spendingCache != nil {
+		if summary, ok := s.spendingCache.Get(userID, from, to); ok {
+			return summary, nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	summary, err := s.transactionRepo.GetSpendingSummary(ctx, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error getting spending summary: %w", err)
+	}
+
+	if s.spendingCache != nil {
+		s.spendingCache.Set(userID, from, to, summary, cache.DefaultSpendingSummaryTTL)
+	}
+	return summary, nil
+}
+
+// normalizeCategory limpia los espacios de una categoría, la pasa a minúsculas y verifica su longitud
+func normalizeCategory(category string) (string, error) {
+	category = strings.ToLower(strings.TrimSpace(category))
+	if len([]rune(category)) > models.MaxCategoryLength {
+		return "", ErrInvalidCategory
+	}
+	return category, nil
+}
+
+// normalizeTags limpia los espacios de las etiquetas, elimina las repetidas y verifica su cantidad
+// y longitud
+func normalizeTags(tags []string) ([]string, error) {
+	if len(tags) > models.MaxTags {
+		return nil, ErrInvalidTags
+	}
+
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || len([]rune(tag)) > models.MaxTagLength {
+			return nil, ErrInvalidTags
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized, nil
+}
+
+// GetUserStats obtiene el resumen completo de un usuario para el dashboard.
+// Las consultas se ejecutan en paralelo y el fallo de cualquiera cancela las demás.
+func (s *UserService) GetUserStats(ctx context.Context, userID uuid.UUID) (*models.UserStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	// 1. Usuario, necesario para conocer su cuenta principal
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting user: %w", err)
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	stats := &models.UserStats{User: user.ToResponse(), Accounts: []models.BankAccountWithBalance{}}
+
+	// 2. Cuenta principal y cuentas bancarias con sus balances en TigerBeetle
+	if s.bankAccountRepo != nil {
+		g.Go(func() error {
+			accounts, err := s.getAccountsWithBalance(gctx, user)
+			if err != nil {
+				return err
+			}
+
+			for _, account := range accounts {
+				stats.Accounts = append(stats.Accounts, account)
+				stats.TotalBalance += account.Balance
+			}
+			return nil
+		})
+	}
+
+	if s.transactionRepo != nil {
+		// 3. Cantidad y volumen de transacciones del mes actual
+		g.Go(func() error {
+			now := time.Now()
+			monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+			count, volume, err := s.transactionRepo.GetSummarySince(gctx, userID, monthStart)
+			if err != nil {
+				return err
+			}
+			stats.MonthlyTransactionCount = count
+			stats.MonthlyVolume = volume
+			return nil
+		})
+
+		// 4. Fecha de la última transacción
+		g.Go(func() error {
+			last, err := s.transactionRepo.GetLastTransactionDate(gctx, userID)
+			if err != nil {
+				return err
+			}
+			stats.LastTransactionAt = last
+			return nil
+		})
+	}
+
+	// 5. Cantidad de beneficiarios
+	if s.beneficiaryRepo != nil {
+		g.Go(func() error {
+			count, err := s.beneficiaryRepo.CountByOwner(gctx, userID)
+			if err != nil {
+				return err
+			}
+			stats.BeneficiaryCount = count
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, fmt.Errorf("error getting user stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// GetUserAccounts obtiene la cuenta principal y las cuentas bancarias activas de un usuario con
+// sus balances
+func (s *UserService) GetUserAccounts(ctx context.Context, userID uuid.UUID) ([]models.BankAccountWithBalance, error) {
+	if s.bankAccountRepo == nil {
+		return nil, fmt.Errorf("bank accounts not configured")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getAccountsWithBalance(ctx, user)
+}
+
+// getAccountsWithBalance obtiene la cuenta principal del usuario, si tiene una en TigerBeetle, y
+// sus cuentas bancarias junto con sus balances. La cuenta principal va primero, sin ID ni número
+// de cuenta y con el tipo models.AccountTypePrimary.
+func (s *UserService) getAccountsWithBalance(ctx context.Context, user *models.User) ([]models.BankAccountWithBalance, error) {
+	accounts, err := s.bankAccountRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting bank accounts: %w", err)
+	}
+
+	if user.TigerBeetleAccountID != nil {
+		primary := &models.BankAccount{
+			UserID:               user.ID,
+			AccountType:          models.AccountTypePrimary,
+			TigerBeetleAccountID: *user.TigerBeetleAccountID,
+			Currency:             models.DefaultCurrency,
+			CreatedAt:            user.CreatedAt,
+			UpdatedAt:            user.UpdatedAt,
+			IsActive:             true,
+		}
+		accounts = append([]*models.BankAccount{primary}, accounts...)
+	}
+
+	return withBalances(s.tigerBeetleService, accounts)
+}
+
+// withBalances agrega a cada cuenta su balance de TigerBeetle consultando todas las cuentas en una
+// sola llamada en lugar de una por cuenta. Sin TigerBeetle los balances quedan en cero.
+func withBalances(tbService tigerbeetle.TigerBeetleService, accounts []*models.BankAccount) ([]models.BankAccountWithBalance, error) {
+	var balances map[uint64]int64
+	if tbService != nil && len(accounts) > 0 {
+		ids := make([]uint64, 0, len(accounts))
+		for _, account := range accounts {
+			ids = append(ids, uint64(account.TigerBeetleAccountID))
+		}
+
+		var err error
+		balances, err = tbService.GetMultipleAccountBalances(ids)
+		if err != nil {
+			return nil, fmt.Errorf("error getting account balances: %w", err)
+		}
+	}
+
+	result := make([]models.BankAccountWithBalance, 0, len(accounts))
+	for _, account := range accounts {
+		result = append(result, models.BankAccountWithBalance{
+			BankAccount: *account,
+			Balance:     balances[uint64(account.TigerBeetleAccountID)],
+		})
+	}
+
+	return result, nil
+}
+
+// LookupAccount resuelve un número de cuenta al nombre de su titular para confirmar el destinatario
+func (s *UserService) LookupAccount(ctx context.Context, accountNumber string) (*models.AccountLookupResponse, error) {
+	if s.bankAccountRepo == nil {
+		return nil, fmt.Errorf("bank accounts not configured")
+	}
+
+	if err := ValidateAccountNumber(accountNumber); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	account, err := s.bankAccountRepo.GetByAccountNumber(ctx, accountNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, account.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting account holder: %w", err)
+	}
+
+	return &models.AccountLookupResponse{
+		AccountHolder: user.FirstName + " " + user.LastName,
+		AccountType:   account.AccountType,
+		Bank:          models.BankName,
+	}, nil
+}
+
+// ConfirmRecipientAccount verifica que el número de cuenta confirmado pertenezca al destinatario
+func (s *UserService) ConfirmRecipientAccount(ctx context.Context, toUserID uuid.UUID, accountNumber string) error {
+	if s.bankAccountRepo == nil {
+		return fmt.Errorf("bank accounts not configured")
+	}
+
+	if err := ValidateAccountNumber(accountNumber); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	account, err := s.bankAccountRepo.GetByAccountNumber(ctx, accountNumber)
+	if err != nil {
+		if errors.Is(err, ErrBankAccountNotFound) {
+			return fmt.Errorf("recipient account mismatch")
+		}
+		return err
+	}
+
+	if account.UserID != toUserID {
+		return fmt.Errorf("recipient account mismatch")
+	}
+
+	return nil
+}
+
+// GetPreferences obtiene las preferencias de un usuario existente
+func (s *UserService) GetPreferences(ctx context.Context, userID uuid.UUID) (*models.UserPreferences, error) {
+	if s.preferencesRepo == nil {
+		return nil, fmt.Errorf("user preferences not configured")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return s.preferencesRepo.Get(ctx, userID)
+}
+
+// UpdatePreferences aplica los cambios solicitados sobre las preferencias actuales y las guarda
+func (s *UserService) UpdatePreferences(ctx context.Context, userID uuid.UUID, req *models.UpdateUserPreferencesRequest) (*models.UserPreferences, error) {
+	prefs, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	prefs.Apply(req)
+	return s.preferencesRepo.Upsert(ctx, prefs)
+}
+
+// ShouldSendEmail indica si se debe enviar un email al usuario para el evento indicado.
+// Ante un error al leer las preferencias se usan los valores por defecto.
+func (s *UserService) ShouldSendEmail(ctx context.Context, userID uuid.UUID, event string) bool {
+	if s.preferencesRepo == nil {
+		return models.DefaultUserPreferences(userID).WantsEmail(event)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	prefs, err := s.preferencesRepo.Get(ctx, userID)
+	if err != nil {
+		log.Printf("Error getting preferences for user %s, using defaults: %v", userID, err)
+		prefs = models.DefaultUserPreferences(userID)
+	}
+
+	return prefs.WantsEmail(event)
+}
+
+// GetNotificationPreferences obtiene las preferencias de notificación de un usuario existente
+func (s *UserService) GetNotificationPreferences(ctx context.Context, userID uuid.UUID) (*models.NotificationPreferences, error) {
+	if s.notificationPrefs == nil {
+		return nil, fmt.Errorf("notification preferences not configured")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return s.notificationPrefs.Get(ctx, userID)
+}
+
+// UpdateNotificationPreferences reemplaza todas las preferencias de notificación de un usuario existente
+func (s *UserService) UpdateNotificationPreferences(ctx context.Context, userID uuid.UUID, req *models.UpdateNotificationPreferencesRequest) (*models.NotificationPreferences, error) {
+	if s.notificationPrefs == nil {
+		return nil, fmt.Errorf("notification preferences not configured")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return s.notificationPrefs.Upsert(ctx, &models.NotificationPreferences{
+		UserID:         userID,
+		EmailLargeTx:   *req.EmailLargeTx,
+		EmailLogin:     *req.EmailLogin,
+		EmailStatement: *req.EmailStatement,
+		SMSLargeTx:     *req.SMSLargeTx,
+	})
+}
+
+// NotificationAllowed indica si el usuario acepta recibir el tipo de notificación por el canal
+// indicado. Debe consultarse antes de enviar cualquier aviso que no sea parte de un flujo de
+// seguridad (verificación de email, recuperación de contraseña y códigos OTP se envían siempre).
+// Ante un error al leer las preferencias se usan los valores por defecto.
+func (s *UserService) NotificationAllowed(ctx context.Context, userID uuid.UUID, channel, kind string) bool {
+	if s.notificationPrefs == nil {
+		return models.DefaultNotificationPreferences(userID).Allows(channel, kind)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	prefs, err := s.notificationPrefs.Get(ctx, userID)
+	if err != nil {
+		log.Printf("Error getting notification preferences for user %s, using defaults: %v", userID, err)
+		prefs = models.DefaultNotificationPreferences(userID)
+	}
+
+	return prefs.Allows(channel, kind)
+}
+
+// publishTransaction notifica una transacción completada a los publicadores configurados
+func (s *UserService) publishTransaction(eventType string, amount uint64, fromUserID, toUserID *uuid.UUID) {
+	if len(s.publishers) == 0 {
+		return
+	}
+
+	event := models.TransactionEvent{
+		ID:         uuid.New().String(),
+		Type:       eventType,
+		Amount:     amount,
+		FromUserID: fromUserID,
+		ToUserID:   toUserID,
+		Timestamp:  time.Now(),
+	}
+	for _, publisher := range s.publishers {
+		publisher.Publish(event)
+	}
+}
+
+// alertLargeTransaction envía en segundo plano el aviso por email de una transacción que supera
+// el umbral de transacciones grandes, si el usuario acepta los avisos de transacciones grandes
+// por email y no desactivó los emails de ese tipo de evento
+func (s *UserService) alertLargeTransaction(user *models.User, eventType string, amount uint64, counterpart *models.User) {
+	if s.alerter == nil || amount <= s.alertThreshold {
+		return
+	}
+
+	event := email.TransactionEvent{
+		TransactionEvent: models.TransactionEvent{
+			ID:         uuid.New().String(),
+			Type:       eventType,
+			Amount:     amount,
+			FromUserID: &user.ID,
+			Timestamp:  time.Now(),
+		},
+	}
+	if counterpart != nil {
+		event.ToUserID = &counterpart.ID
+		event.CounterpartName = counterpart.FirstName + " " + counterpart.LastName
+	}
+
+	go func() {
+		ctx := context.Background()
+		if !s.NotificationAllowed(ctx, user.ID, models.NotificationChannelEmail, models.NotificationKindLargeTransaction) ||
+			!s.ShouldSendEmail(ctx, user.ID, eventType) {
+			return
+		}
+		if err := s.alerter.SendTransactionAlert(user, event); err != nil {
+			log.Printf("Error sending %s alert to user %s: %v", eventType, user.ID, err)
+		}
+	}()
+}
+
+// publishBalance notifica el nuevo balance de una cuenta a los streams abiertos de su titular.
+// El balance solo se consulta a TigerBeetle si el usuario tiene algún stream abierto.
+func (s *UserService) publishBalance(userID uuid.UUID, accountID int64) {
+	if s.balancePublisher == nil || !s.balancePublisher.HasSubscribers(userID) {
+		return
+	}
+
+	balance, err := s.signedAccountBalance(accountID)
+	if err != nil {
+		log.Printf("Error getting balance of account %d to publish: %v", accountID, err)
+		return
+	}
+
+	s.balancePublisher.PublishBalance(userID, models.BalanceChangedEvent{
+		AccountID:  accountID,
+		NewBalance: balance,
+	})
+}
+
+// GenerateTigerBeetleAccountID genera el ID de la cuenta TigerBeetle a partir de un UUID.
+// Los 16 bytes del UUID se pliegan a 64 bits con XOR (los 8 bytes altos sobre los 8 bajos)
+// para que dos UUIDs que solo difieren en su segunda mitad no produzcan el mismo ID.
+// Si el resultado coincide con una cuenta del sistema se reintenta con un contador.
+func GenerateTigerBeetleAccountID(id uuid.UUID) uint64 {
+	folded := binary.BigEndian.Uint64(id[:8]) ^ binary.BigEndian.Uint64(id[8:])
+
+	accountID := folded
+	for counter := uint64(1); isReservedTigerBeetleAccountID(accountID); counter++ {
+		accountID = folded ^ (counter << 32)
+	}
+
+	return accountID
+}
+
+// isReservedTigerBeetleAccountID indica si el ID pertenece a una cuenta del sistema
+// (maestras, comisiones o correcciones)
+func isReservedTigerBeetleAccountID(id uint64) bool {
+	return id <= uint64(tigerbeetle.FeeAccount) || id == uint64(tigerbeetle.CorrectionAccount)
 }
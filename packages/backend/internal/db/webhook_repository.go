@@ -0,0 +1,143 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"banca-en-linea/backend/models"
+)
+
+// ErrWebhookNotFound indica que el webhook no existe o pertenece a otro usuario
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+// WebhookRepository define la interfaz para las suscripciones de webhooks en la base de datos
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *models.Webhook) (*models.Webhook, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.Webhook, error)
+	ListActiveForEvent(ctx context.Context, userIDs []uuid.UUID, eventType string) ([]*models.Webhook, error)
+	Delete(ctx context.Context, id, userID uuid.UUID) error
+}
+
+// webhookColumns son las columnas que se leen al cargar un webhook (en el orden de scanWebhook)
+const webhookColumns = `id, user_id, url, secret, events, active, created_at`
+
+// scanWebhook lee un webhook a partir de una fila que contiene webhookColumns
+func scanWebhook(row rowScanner) (*models.Webhook, error) {
+	webhook := &models.Webhook{}
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.UserID,
+		&webhook.URL,
+		&webhook.Secret,
+		pq.Array(&webhook.Events),
+		&webhook.Active,
+		&webhook.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// webhookRepository implementa WebhookRepository
+type webhookRepository struct {
+	db *sql.DB
+}
+
+// NewWebhookRepository crea una nueva instancia del repositorio de webhooks
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+// Create guarda una suscripción de webhook activa
+func (r *webhookRepository) Create(ctx context.Context, webhook *models.Webhook) (*models.Webhook, error) {
+	query := `
+		INSERT INTO webhooks (user_id, url, secret, events)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + webhookColumns
+
+	created, err := scanWebhook(r.db.QueryRowContext(ctx, query,
+		webhook.UserID,
+		webhook.URL,
+		webhook.Secret,
+		pq.Array(webhook.Events),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("error creating webhook: %w", err)
+	}
+
+	return created, nil
+}
+
+// ListByUser obtiene los webhooks de un usuario, los más recientes primero
+func (r *webhookRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.Webhook, error) {
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhooks
+		WHERE user_id = $1
+		ORDER BY created_at DESC`
+
+	return r.list(ctx, query, userID)
+}
+
+// ListActiveForEvent obtiene los webhooks activos de los usuarios indicados suscritos al tipo de evento
+func (r *webhookRepository) ListActiveForEvent(ctx context.Context, userIDs []uuid.UUID, eventType string) ([]*models.Webhook, error) {
+	ids := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		ids[i] = id.String()
+	}
+
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhooks
+		WHERE user_id = ANY($1::uuid[]) AND active AND $2 = ANY(events)`
+
+	return r.list(ctx, query, pq.Array(ids), eventType)
+}
+
+// list ejecuta una consulta que retorna webhookColumns
+func (r *webhookRepository) list(ctx context.Context, query string, args ...interface{}) ([]*models.Webhook, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []*models.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning webhook: %w", err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhooks: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// Delete elimina un webhook del usuario
+func (r *webhookRepository) Delete(ctx context.Context, id, userID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("error deleting webhook: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
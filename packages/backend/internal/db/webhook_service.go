@@ -0,0 +1,348 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/models"
+)
+
+const (
+	// WebhookSignatureHeader es el header con la firma HMAC-SHA256 del cuerpo de cada entrega
+	WebhookSignatureHeader = "X-Webhook-Signature"
+
+	// webhookEventHeader es el header con el tipo de evento entregado
+	webhookEventHeader = "X-Webhook-Event"
+
+	// webhookQueueSize es la cantidad de eventos pendientes de entrega antes de descartarlos
+	webhookQueueSize = 256
+
+	// webhookMaxRetries es la cantidad de reintentos ante una respuesta no 2xx o un error de red
+	webhookMaxRetries = 3
+
+	// webhookRetryBaseDelay es la espera antes del primer reintento; se duplica en cada intento
+	webhookRetryBaseDelay = time.Second
+
+	// webhookRequestTimeout es el tiempo máximo de cada intento de entrega
+	webhookRequestTimeout = 10 * time.Second
+
+	// webhookWorkers es la cantidad de eventos que se entregan a la vez. Un endpoint lento o
+	// caído solo ocupa a los workers de sus eventos mientras los demás siguen entregando.
+	webhookWorkers = 8
+)
+
+var (
+	// ErrInvalidWebhookURL indica que la URL del webhook no es una URL HTTPS absoluta
+	ErrInvalidWebhookURL = errors.New("webhook url must be an absolute https url")
+	// ErrInvalidWebhookEvents indica que no se indicaron eventos o alguno no es un tipo de transacción
+	ErrInvalidWebhookEvents = errors.New("webhook events must be deposit, withdrawal or transfer")
+	// ErrWebhookAddressNotAllowed indica que el host del webhook resuelve a una dirección interna
+	// (loopback, privada, link-local, etc.), a la que el banco no debe enviar solicitudes
+	ErrWebhookAddressNotAllowed = errors.New("webhook url must resolve to a public address")
+)
+
+// cgnatPrefix es el espacio compartido de CGNAT (RFC 6598), que algunos proveedores usan para
+// sus servicios de metadatos
+var cgnatPrefix = netip.MustParsePrefix("100.64.0.0/10")
+
+// isPublicAddress indica si la dirección puede recibir webhooks: se excluyen loopback, redes
+// privadas, link-local (incluida la de metadatos 169.254.169.254), CGNAT, multicast y la
+// dirección no especificada
+func isPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified() &&
+		!cgnatPrefix.Contains(addr)
+}
+
+// WebhookService gestiona las suscripciones de webhooks y entrega a los sistemas externos los
+// eventos de las transacciones completadas. Implementa TransactionPublisher: los eventos se
+// encolan sin bloquear la operación financiera y Run los entrega en segundo plano.
+type WebhookService struct {
+	repo           WebhookRepository
+	client         *http.Client
+	queue          chan models.TransactionEvent
+	retryBaseDelay time.Duration
+	addressAllowed func(netip.Addr) bool
+}
+
+// WebhookServiceOption configura dependencias opcionales del WebhookService
+type WebhookServiceOption func(*WebhookService)
+
+// WithWebhookHTTPClient configura el cliente HTTP usado para las entregas. El cliente por defecto
+// rechaza al conectar las direcciones no públicas; uno configurado debe aplicar sus propias
+// restricciones de red.
+func WithWebhookHTTPClient(client *http.Client) WebhookServiceOption {
+	return func(s *WebhookService) {
+		s.client = client
+	}
+}
+
+// WithWebhookRetryBaseDelay configura la espera antes del primer reintento de una entrega
+func WithWebhookRetryBaseDelay(delay time.Duration) WebhookServiceOption {
+	return func(s *WebhookService) {
+		s.retryBaseDelay = delay
+	}
+}
+
+// WithWebhookAddressFilter reemplaza la regla que decide a qué direcciones se permiten webhooks,
+// tanto al registrarlos como al conectar
+func WithWebhookAddressFilter(allowed func(netip.Addr) bool) WebhookServiceOption {
+	return func(s *WebhookService) {
+		s.addressAllowed = allowed
+	}
+}
+
+// NewWebhookService crea una nueva instancia del servicio de webhooks
+func NewWebhookService(repo WebhookRepository, opts ...WebhookServiceOption) *WebhookService {
+	s := &WebhookService{
+		repo:           repo,
+		queue:          make(chan models.TransactionEvent, webhookQueueSize),
+		retryBaseDelay: webhookRetryBaseDelay,
+		addressAllowed: isPublicAddress,
+	}
+
+	// Verificar la dirección al conectar, ya resuelta, para que un DNS que cambia después del
+	// registro no pueda dirigir las entregas a la red interna
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{Timeout: webhookRequestTimeout, Control: s.dialControl}).DialContext
+	s.client = &http.Client{Timeout: webhookRequestTimeout, Transport: transport}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// dialControl rechaza las conexiones de entrega hacia direcciones no permitidas
+func (s *WebhookService) dialControl(network, address string, c syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid webhook address %q: %w", address, err)
+	}
+	if !s.addressAllowed(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrWebhookAddressNotAllowed, addrPort.Addr())
+	}
+	return nil
+}
+
+// checkWebhookHost resuelve el host del webhook y verifica que todas sus direcciones estén permitidas
+func (s *WebhookService) checkWebhookHost(ctx context.Context, host string) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("%w: cannot resolve %s", ErrWebhookAddressNotAllowed, host)
+	}
+	for _, addr := range addrs {
+		if !s.addressAllowed(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrWebhookAddressNotAllowed, host, addr)
+		}
+	}
+	return nil
+}
+
+// Create suscribe una URL HTTPS del usuario a los tipos de evento indicados y genera el secreto
+// con el que se firman las entregas. El host debe resolver solo a direcciones públicas.
+func (s *WebhookService) Create(ctx context.Context, userID uuid.UUID, req *models.CreateWebhookRequest) (*models.Webhook, error) {
+	parsed, err := url.Parse(req.URL)
+	if err != nil || parsed.Scheme != "https" || parsed.Hostname() == "" {
+		return nil, ErrInvalidWebhookURL
+	}
+	if len(req.Events) == 0 {
+		return nil, ErrInvalidWebhookEvents
+	}
+	for _, event := range req.Events {
+		switch event {
+		case models.TransactionEventDeposit, models.TransactionEventWithdrawal, models.TransactionEventTransfer:
+		default:
+			return nil, ErrInvalidWebhookEvents
+		}
+	}
+	if err := s.checkWebhookHost(ctx, parsed.Hostname()); err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("error generating webhook secret: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	return s.repo.Create(ctx, &models.Webhook{
+		UserID: userID,
+		URL:    req.URL,
+		Secret: hex.EncodeToString(secret),
+		Events: req.Events,
+	})
+}
+
+// List obtiene los webhooks del usuario
+func (s *WebhookService) List(ctx context.Context, userID uuid.UUID) ([]*models.Webhook, error) {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	return s.repo.ListByUser(ctx, userID)
+}
+
+// Delete elimina un webhook del usuario
+func (s *WebhookService) Delete(ctx context.Context, userID, webhookID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+
+	return s.repo.Delete(ctx, webhookID, userID)
+}
+
+// Publish encola el evento para entregarlo en segundo plano. Si la cola está llena el evento se
+// descarta para no demorar la operación financiera.
+func (s *WebhookService) Publish(event models.TransactionEvent) {
+	select {
+	case s.queue <- event:
+	default:
+		log.Printf("Webhook queue is full, dropping event %s", event.ID)
+	}
+}
+
+// Run entrega los eventos encolados con webhookWorkers workers hasta que se cancele ctx, y
+// retorna cuando todos terminan la entrega en curso
+func (s *WebhookService) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < webhookWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case event := <-s.queue:
+					if err := s.Deliver(event); err != nil {
+						log.Printf("Error delivering webhooks for event %s: %v", event.ID, err)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Deliver envía el evento a todos los webhooks activos de los usuarios involucrados que estén
+// suscritos a su tipo, en paralelo para que los reintentos de uno no demoren a los demás. Cada
+// entrega se firma y se reintenta hasta webhookMaxRetries veces con backoff exponencial; el
+// error reúne las entregas que fallaron.
+func (s *WebhookService) Deliver(event models.TransactionEvent) error {
+	var userIDs []uuid.UUID
+	if event.FromUserID != nil {
+		userIDs = append(userIDs, *event.FromUserID)
+	}
+	if event.ToUserID != nil {
+		userIDs = append(userIDs, *event.ToUserID)
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	ctx, cancel := newQueryContext()
+	webhooks, err := s.repo.ListActiveForEvent(ctx, userIDs, event.Type)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("error getting webhooks: %w", err)
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error encoding webhook payload: %w", err)
+	}
+
+	errs := make([]error, len(webhooks))
+	var wg sync.WaitGroup
+	for i, webhook := range webhooks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.deliverTo(webhook, event.Type, payload); err != nil {
+				errs[i] = fmt.Errorf("webhook %s: %w", webhook.ID, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// deliverTo envía el payload firmado a un webhook, reintentando ante errores de red o respuestas no 2xx
+func (s *WebhookService) deliverTo(webhook *models.Webhook, eventType string, payload []byte) error {
+	signature := SignWebhookPayload(webhook.Secret, payload)
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = s.post(webhook.URL, eventType, signature, payload)
+		// Una dirección no permitida no cambia con los reintentos
+		if err == nil || attempt == webhookMaxRetries || errors.Is(err, ErrWebhookAddressNotAllowed) {
+			return err
+		}
+
+		delay := s.retryBaseDelay << attempt
+		log.Printf("Webhook %s delivery failed, retrying in %s: %v", webhook.ID, delay, err)
+		time.Sleep(delay)
+	}
+}
+
+// post realiza un intento de entrega
+func (s *WebhookService) post(url, eventType, signature string, payload []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("error creating webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookSignatureHeader, signature)
+	req.Header.Set(webhookEventHeader, eventType)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending webhook: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SignWebhookPayload calcula la firma "sha256=<hmac>" del payload con el secreto del webhook.
+// Los receptores la recalculan para verificar que el evento proviene del banco.
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
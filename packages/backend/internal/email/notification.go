@@ -0,0 +1,96 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"banca-en-linea/backend/models"
+)
+
+// TransactionEvent es una transacción a notificar por email junto con el nombre de la
+// contraparte (vacío si la operación no tiene contraparte, como un retiro)
+type TransactionEvent struct {
+	models.TransactionEvent
+	CounterpartName string
+}
+
+// NotificationService envía los avisos por email de la actividad financiera del usuario
+type NotificationService struct {
+	sender Sender
+}
+
+// NewNotificationService crea una nueva instancia del servicio de notificaciones
+func NewNotificationService(sender Sender) *NotificationService {
+	return &NotificationService{
+		sender: sender,
+	}
+}
+
+// SendTransactionAlert avisa al usuario de una transacción realizada desde su cuenta, con el
+// monto, la contraparte y la fecha de la operación
+func (n *NotificationService) SendTransactionAlert(user *models.User, event TransactionEvent) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hola %s,\n\nSe realizó %s desde tu cuenta:\n\n", user.FirstName, describeTransaction(event.Type))
+	fmt.Fprintf(&b, "Monto: L %s\n", formatCents(event.Amount))
+	if event.CounterpartName != "" {
+		fmt.Fprintf(&b, "Destinatario: %s\n", event.CounterpartName)
+	}
+	fmt.Fprintf(&b, "Fecha: %s\n", event.Timestamp.UTC().Format("2006-01-02 15:04:05 UTC"))
+	b.WriteString("\nSi no reconoces esta operación, cambia tu contraseña y contacta al banco de inmediato.\n")
+
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	if err := n.sender.Send(ctx, user.Email, "Aviso de transacción", b.String()); err != nil {
+		return fmt.Errorf("error sending transaction alert: %w", err)
+	}
+
+	return nil
+}
+
+// SendDormancyNotice avisa al usuario que su cuenta pasó a estar inactiva por no tener
+// transacciones en el último año
+func (n *NotificationService) SendDormancyNotice(user *models.User, account *models.BankAccount) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hola %s,\n\nTu cuenta terminada en %s no registra transacciones en los últimos 12 meses ", user.FirstName, lastDigits(account.AccountNumber))
+	b.WriteString("y fue marcada como inactiva.\n\n")
+	b.WriteString("Tu saldo sigue disponible. Realiza cualquier transacción con la cuenta para reactivarla, ")
+	b.WriteString("o contacta al banco si deseas cerrarla.\n")
+
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	if err := n.sender.Send(ctx, user.Email, "Tu cuenta está inactiva", b.String()); err != nil {
+		return fmt.Errorf("error sending dormancy notice: %w", err)
+	}
+
+	return nil
+}
+
+// lastDigits retorna los últimos cuatro caracteres de un número de cuenta
+func lastDigits(accountNumber string) string {
+	if len(accountNumber) <= 4 {
+		return accountNumber
+	}
+	return accountNumber[len(accountNumber)-4:]
+}
+
+// describeTransaction retorna la descripción de un tipo de transacción para el cuerpo del email
+func describeTransaction(eventType string) string {
+	switch eventType {
+	case models.TransactionEventWithdrawal:
+		return "un retiro"
+	case models.TransactionEventTransfer:
+		return "una transferencia"
+	case models.TransactionEventDeposit:
+		return "un depósito"
+	default:
+		return "una transacción"
+	}
+}
+
+// formatCents formatea un monto en centavos con dos decimales (150000 -> "1500.00")
+func formatCents(cents uint64) string {
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
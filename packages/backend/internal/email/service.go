@@ -0,0 +1,179 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/smtp"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"banca-en-linea/backend/models"
+)
+
+// sendTimeout es el tiempo máximo para enviar un email
+const sendTimeout = 10 * time.Second
+
+// Sender envía un email a un destinatario
+type Sender interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// TokenGenerator genera los tokens incluidos en los enlaces de los emails (implementado por auth.Service)
+type TokenGenerator interface {
+	GenerateEmailVerificationToken(user *models.User) (string, error)
+	GeneratePasswordResetToken(user *models.User) (string, error)
+}
+
+// Service envía los emails transaccionales de la aplicación
+type Service struct {
+	sender      Sender
+	tokens      TokenGenerator
+	frontendURL string
+}
+
+// NewService crea una nueva instancia del servicio de email. La URL del frontend
+// usada en los enlaces se lee de FRONTEND_URL.
+func NewService(sender Sender, tokens TokenGenerator) *Service {
+	frontendURL := os.Getenv("FRONTEND_URL")
+	if frontendURL == "" {
+		frontendURL = "http://localhost:8082"
+	}
+
+	return &Service{
+		sender:      sender,
+		tokens:      tokens,
+		frontendURL: strings.TrimRight(frontendURL, "/"),
+	}
+}
+
+// SendVerificationEmail genera un token de verificación y envía al usuario el enlace para confirmar su email
+func (s *Service) SendVerificationEmail(user *models.User) error {
+	token, err := s.tokens.GenerateEmailVerificationToken(user)
+	if err != nil {
+		return fmt.Errorf("error generating verification token: %w", err)
+	}
+
+	link := s.frontendURL + "/verify-email?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Hola %s,\n\nConfirma tu correo electrónico abriendo el siguiente enlace:\n\n%s\n\nEl enlace vence en 24 horas.\n",
+		user.FirstName, link)
+
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	if err := s.sender.Send(ctx, user.Email, "Verifica tu correo electrónico", body); err != nil {
+		return fmt.Errorf("error sending verification email: %w", err)
+	}
+
+	return nil
+}
+
+// SendPasswordResetEmail genera un token de restablecimiento y envía al usuario el enlace para cambiar su contraseña
+func (s *Service) SendPasswordResetEmail(user *models.User) error {
+	token, err := s.tokens.GeneratePasswordResetToken(user)
+	if err != nil {
+		return fmt.Errorf("error generating password reset token: %w", err)
+	}
+
+	link := s.frontendURL + "/reset-password?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Hola %s,\n\nRecibimos una solicitud para restablecer tu contraseña. Abre el siguiente enlace para elegir una nueva:\n\n%s\n\nEl enlace vence en 1 hora. Si no solicitaste el cambio, ignora este mensaje.\n",
+		user.FirstName, link)
+
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	if err := s.sender.Send(ctx, user.Email, "Restablece tu contraseña", body); err != nil {
+		return fmt.Errorf("error sending password reset email: %w", err)
+	}
+
+	return nil
+}
+
+// SendOTPEmail envía al usuario el código de un solo uso para confirmar una operación
+func (s *Service) SendOTPEmail(user *models.User, code string, ttl time.Duration) error {
+	body := fmt.Sprintf("Hola %s,\n\nTu código de confirmación es:\n\n%s\n\nEl código vence en %d minutos. Si no estás realizando una transferencia, cambia tu contraseña de inmediato.\n",
+		user.FirstName, code, int(ttl.Minutes()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	if err := s.sender.Send(ctx, user.Email, "Código de confirmación", body); err != nil {
+		return fmt.Errorf("error sending otp email: %w", err)
+	}
+
+	return nil
+}
+
+// NewSenderFromEnv crea un SMTPSender si SMTP_HOST está configurado; en otro caso un LogSender
+func NewSenderFromEnv() Sender {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return LogSender{}
+	}
+
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		from = "no-reply@banca-en-linea.local"
+	}
+
+	return &SMTPSender{
+		Addr:     host + ":" + port,
+		Host:     host,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     from,
+	}
+}
+
+// LogSender registra los emails en el log en lugar de enviarlos (para desarrollo)
+type LogSender struct{}
+
+// Send registra el email en el log
+func (LogSender) Send(ctx context.Context, to, subject, body string) error {
+	log.Printf("Email para %s: %s\n%s", to, subject, body)
+	return nil
+}
+
+// SMTPSender envía emails mediante un servidor SMTP
+type SMTPSender struct {
+	Addr     string
+	Host     string
+	Username string
+	Password string
+	From     string
+}
+
+// Send envía el email por SMTP
+func (s *SMTPSender) Send(ctx context.Context, to, subject, body string) error {
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+
+	msg := "From: " + s.From + "\r\n" +
+		"To: " + to + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" + body
+
+	// net/smtp no acepta contexto; se ejecuta en una goroutine para respetar la cancelación
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(s.Addr, auth, s.From, []string{to}, []byte(msg))
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
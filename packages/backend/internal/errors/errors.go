@@ -0,0 +1,22 @@
+// Package errors define los errores de dominio que retornan los servicios. Los servicios los
+// envuelven con %w para agregar contexto y los handlers los reconocen con errors.Is, sin
+// depender del texto del mensaje.
+package errors
+
+import "errors"
+
+var (
+	// ErrInsufficientFunds indica que la cuenta no tiene balance suficiente para la operación
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrNoTigerBeetleAccount indica que el usuario no tiene cuenta en TigerBeetle, así que no
+	// puede realizar operaciones financieras
+	ErrNoTigerBeetleAccount = errors.New("user does not have a TigerBeetle account")
+	// ErrAccountFrozen indica que la cuenta del usuario está congelada y no admite operaciones
+	ErrAccountFrozen = errors.New("account is frozen")
+	// ErrDailyLimitExceeded indica que la operación supera el límite diario del usuario
+	ErrDailyLimitExceeded = errors.New("daily limit exceeded")
+	// ErrUserNotFound indica que el usuario no existe o fue eliminado
+	ErrUserNotFound = errors.New("user not found")
+	// ErrBankAccountNotFound indica que la cuenta bancaria no existe o fue eliminada
+	ErrBankAccountNotFound = errors.New("bank account not found")
+)
@@ -0,0 +1,48 @@
+package fee
+
+import (
+	"log"
+	"math"
+	"os"
+	"strconv"
+)
+
+// FeeSchedule define la comisión cobrada por transferencia: un monto fijo más un
+// porcentaje del monto transferido
+type FeeSchedule struct {
+	BaseFeeCents uint64  // Comisión fija en centavos
+	PercentFee   float64 // Porcentaje del monto (0.5 = 0.5%)
+}
+
+// Calculate retorna la comisión en centavos para el monto indicado, redondeando el
+// componente porcentual al centavo más cercano
+func (f FeeSchedule) Calculate(amount uint64) uint64 {
+	percent := math.Round(float64(amount) * f.PercentFee / 100)
+	return f.BaseFeeCents + uint64(percent)
+}
+
+// NewScheduleFromEnv construye la tarifa a partir de TRANSFER_BASE_FEE_CENTS y
+// TRANSFER_PERCENT_FEE. Los valores ausentes o inválidos equivalen a cero.
+func NewScheduleFromEnv() FeeSchedule {
+	var schedule FeeSchedule
+
+	if v := os.Getenv("TRANSFER_BASE_FEE_CENTS"); v != "" {
+		base, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			log.Printf("Warning: invalid TRANSFER_BASE_FEE_CENTS %q, using 0", v)
+		} else {
+			schedule.BaseFeeCents = base
+		}
+	}
+
+	if v := os.Getenv("TRANSFER_PERCENT_FEE"); v != "" {
+		percent, err := strconv.ParseFloat(v, 64)
+		if err != nil || percent < 0 {
+			log.Printf("Warning: invalid TRANSFER_PERCENT_FEE %q, using 0", v)
+		} else {
+			schedule.PercentFee = percent
+		}
+	}
+
+	return schedule
+}
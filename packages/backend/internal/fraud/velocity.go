@@ -0,0 +1,67 @@
+package fraud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// VelocityWindow es la ventana de tiempo en la que se cuentan las transacciones recientes
+	VelocityWindow = 5 * time.Minute
+	// MaxTransactionsPerWindow es la cantidad máxima de transacciones del mismo tipo en la ventana
+	MaxTransactionsPerWindow = 10
+)
+
+// ErrVelocityExceeded indica que el usuario realizó demasiadas transacciones o movió demasiado
+// dinero en poco tiempo
+var ErrVelocityExceeded = errors.New("transaction velocity exceeded")
+
+// VelocityStore obtiene la actividad reciente de un usuario
+type VelocityStore interface {
+	// GetVelocity retorna la cantidad de transacciones del tipo indicado en las que participó el
+	// usuario desde since y la suma de sus montos en centavos
+	GetVelocity(ctx context.Context, userID uuid.UUID, txType string, since time.Time) (int, uint64, error)
+}
+
+// VelocityChecker detecta ráfagas de transacciones sospechosas
+type VelocityChecker struct {
+	store VelocityStore
+}
+
+// NewVelocityChecker crea una nueva instancia del verificador de velocidad
+func NewVelocityChecker(store VelocityStore) *VelocityChecker {
+	return &VelocityChecker{store: store}
+}
+
+// Check verifica que la transacción no supere la velocidad permitida: más de
+// MaxTransactionsPerWindow transacciones del mismo tipo en VelocityWindow, o un total en la
+// ventana (incluyendo amount) mayor a la mitad del límite diario de transferencias del usuario.
+func (c *VelocityChecker) Check(ctx context.Context, userID uuid.UUID, amount uint64, txType string, dailyTransferLimit int64) error {
+	count, total, err := c.store.GetVelocity(ctx, userID, txType, time.Now().Add(-VelocityWindow))
+	if err != nil {
+		return fmt.Errorf("error checking transaction velocity: %w", err)
+	}
+
+	maxTotal := uint64(max(dailyTransferLimit, 0)) / 2
+	exceeded := count+1 > MaxTransactionsPerWindow || total+amount > maxTotal
+
+	zap.L().Info("Velocity check",
+		zap.String("user_id", userID.String()),
+		zap.String("transaction_type", txType),
+		zap.Int("recent_count", count),
+		zap.Uint64("recent_total_cents", total),
+		zap.Uint64("amount_cents", amount),
+		zap.Uint64("max_total_cents", maxTotal),
+		zap.Bool("exceeded", exceeded),
+	)
+
+	if exceeded {
+		return ErrVelocityExceeded
+	}
+	return nil
+}
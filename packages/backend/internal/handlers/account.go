@@ -0,0 +1,336 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/middleware"
+	"banca-en-linea/backend/internal/problem"
+	"banca-en-linea/backend/models"
+)
+
+// AccountHandler maneja las operaciones sobre las cuentas bancarias de los usuarios
+type AccountHandler struct {
+	userService        *db.UserService
+	bankAccountService *db.BankAccountService
+	largeTransfers     *LargeTransferGuard
+}
+
+// NewAccountHandler crea una nueva instancia del handler de cuentas bancarias
+func NewAccountHandler(userService *db.UserService, bankAccountService *db.BankAccountService, largeTransfers *LargeTransferGuard) *AccountHandler {
+	return &AccountHandler{
+		userService:        userService,
+		bankAccountService: bankAccountService,
+		largeTransfers:     largeTransfers,
+	}
+}
+
+// ListAccounts retorna las cuentas de un usuario con sus saldos; solo las ven su titular y los
+// administradores
+func (h *AccountHandler) ListAccounts(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid user ID", "", r.URL.Path, nil)
+		return
+	}
+
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || (claims.UserID != userID && !middleware.HasRole(r.Context(), models.RoleAdmin)) {
+		problem.Write(w, http.StatusForbidden, "Forbidden", "", r.URL.Path, nil)
+		return
+	}
+
+	accounts, err := h.userService.GetUserAccounts(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, db.ErrUserNotFound) {
+			problem.Write(w, http.StatusNotFound, "User not found", "", r.URL.Path, nil)
+			return
+		}
+		middleware.Logger(r.Context()).Error("Error getting user accounts", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error getting user accounts", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(accounts)
+}
+
+// CreateAccount abre una nueva cuenta bancaria para el usuario autenticado
+func (h *AccountHandler) CreateAccount(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid user ID", "", r.URL.Path, nil)
+		return
+	}
+
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || claims.UserID != userID {
+		problem.Write(w, http.StatusForbidden, "Forbidden", "", r.URL.Path, nil)
+		return
+	}
+
+	var req models.CreateBankAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	if !validAccountType(req.AccountType) {
+		problem.Write(w, http.StatusBadRequest, "Invalid account type", "", r.URL.Path, nil)
+		return
+	}
+
+	if req.Currency != "" && len(req.Currency) != 3 {
+		problem.Write(w, http.StatusBadRequest, "Invalid currency", "", r.URL.Path, nil)
+		return
+	}
+
+	account, err := h.bankAccountService.CreateAccount(r.Context(), userID, &req)
+	if err != nil {
+		middleware.Logger(r.Context()).Error("Error creating bank account", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error creating bank account", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(account)
+}
+
+// GetAccount retorna una cuenta bancaria del usuario autenticado
+func (h *AccountHandler) GetAccount(w http.ResponseWriter, r *http.Request) {
+	account, ok := h.ownedAccount(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(account)
+}
+
+// UpdateAccount modifica el tipo o el estado de una cuenta bancaria del usuario autenticado
+func (h *AccountHandler) UpdateAccount(w http.ResponseWriter, r *http.Request) {
+	account, ok := h.ownedAccount(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateBankAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	if req.AccountType == nil && req.IsActive == nil {
+		problem.Write(w, http.StatusBadRequest, "At least one field is required", "", r.URL.Path, nil)
+		return
+	}
+
+	if req.AccountType != nil && !validAccountType(*req.AccountType) {
+		problem.Write(w, http.StatusBadRequest, "Invalid account type", "", r.URL.Path, nil)
+		return
+	}
+
+	updated, err := h.bankAccountService.UpdateAccount(r.Context(), account.ID, &req)
+	if err != nil {
+		middleware.Logger(r.Context()).Error("Error updating bank account", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error updating bank account", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// DeleteAccount cierra una cuenta bancaria del usuario autenticado
+func (h *AccountHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	account, ok := h.ownedAccount(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.bankAccountService.DeleteAccount(r.Context(), account.ID); err != nil {
+		switch {
+		case errors.Is(err, db.ErrAccountHasBalance):
+			problem.Write(w, http.StatusConflict, "Account balance must be zero before closing it", "", r.URL.Path, nil)
+		case errors.Is(err, db.ErrBankAccountNotFound):
+			problem.Write(w, http.StatusNotFound, "Account not found", "", r.URL.Path, nil)
+		default:
+			middleware.Logger(r.Context()).Error("Error deleting bank account", zap.Error(err))
+			problem.Write(w, http.StatusInternalServerError, "Error deleting bank account", "", r.URL.Path, nil)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Deposit acredita un monto en una cuenta bancaria del usuario autenticado
+func (h *AccountHandler) Deposit(w http.ResponseWriter, r *http.Request) {
+	account, ok := h.ownedAccount(w, r)
+	if !ok {
+		return
+	}
+
+	amount, ok := decodeAmount(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.DepositToAccount(r.Context(), account.ID, amount); err != nil {
+		h.writeMovementError(w, r, err, "Error processing deposit")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+}
+
+// Withdraw debita un monto de una cuenta bancaria del usuario autenticado, respetando el balance
+// mínimo de su tipo de cuenta
+func (h *AccountHandler) Withdraw(w http.ResponseWriter, r *http.Request) {
+	account, ok := h.ownedAccount(w, r)
+	if !ok {
+		return
+	}
+
+	amount, ok := decodeAmount(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.WithdrawFromAccount(r.Context(), account.ID, amount); err != nil {
+		h.writeMovementError(w, r, err, "Error processing withdrawal")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+}
+
+// Transfer envía un monto desde una cuenta bancaria del usuario autenticado hacia otra cuenta,
+// propia o de otro usuario, identificada por su número. Como en POST /transfer, los montos
+// grandes requieren confirmación OTP.
+func (h *AccountHandler) Transfer(w http.ResponseWriter, r *http.Request) {
+	account, ok := h.ownedAccount(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.AccountTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	if req.Amount == 0 {
+		problem.Write(w, http.StatusBadRequest, "Amount must be greater than 0", "", r.URL.Path, nil)
+		return
+	}
+
+	if req.ToAccountNumber == "" {
+		problem.Write(w, http.StatusBadRequest, "Destination account number is required", "", r.URL.Path, nil)
+		return
+	}
+
+	if !h.largeTransfers.Confirm(w, r, account.UserID, req.Amount, RecipientAccount(req.ToAccountNumber)) {
+		return
+	}
+
+	transferFee, err := h.userService.TransferToAccountNumber(r.Context(), account.ID, req.ToAccountNumber, req.Amount)
+	if err != nil {
+		h.writeMovementError(w, r, err, "Error processing transfer")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"amount": req.Amount,
+		"fee":    transferFee,
+	})
+}
+
+// decodeAmount lee el monto de un depósito o retiro. Si falta o es inválido escribe la respuesta
+// de error y retorna false.
+func decodeAmount(w http.ResponseWriter, r *http.Request) (uint64, bool) {
+	var req struct {
+		Amount uint64 `json:"amount"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return 0, false
+	}
+
+	if req.Amount == 0 {
+		problem.Write(w, http.StatusBadRequest, "Amount must be greater than 0", "", r.URL.Path, nil)
+		return 0, false
+	}
+	return req.Amount, true
+}
+
+// writeMovementError responde el error de un depósito, retiro o transferencia sobre una cuenta bancaria
+func (h *AccountHandler) writeMovementError(w http.ResponseWriter, r *http.Request, err error, title string) {
+	if writeTransferError(w, r, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, db.ErrAccountInactive):
+		problem.Write(w, http.StatusConflict, "Account is closed", "", r.URL.Path, nil)
+	case errors.Is(err, db.ErrSameAccount):
+		problem.Write(w, http.StatusBadRequest, "Cannot transfer to the same account", "", r.URL.Path, nil)
+	case errors.Is(err, db.ErrInvalidAccountNumber):
+		problem.Write(w, http.StatusBadRequest, "Invalid account number", "", r.URL.Path, nil)
+	case errors.Is(err, db.ErrBankAccountNotFound):
+		problem.Write(w, http.StatusNotFound, "Destination account not found", "", r.URL.Path, nil)
+	default:
+		middleware.Logger(r.Context()).Error(title, zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, title, "", r.URL.Path, nil)
+	}
+}
+
+// ownedAccount carga la cuenta indicada en la ruta y verifica que pertenezca al usuario autenticado.
+// Si no es así escribe la respuesta de error y retorna false.
+func (h *AccountHandler) ownedAccount(w http.ResponseWriter, r *http.Request) (*models.BankAccount, bool) {
+	accountID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid account ID", "", r.URL.Path, nil)
+		return nil, false
+	}
+
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		problem.Write(w, http.StatusUnauthorized, "Authentication required", "", r.URL.Path, nil)
+		return nil, false
+	}
+
+	account, err := h.bankAccountService.GetAccount(r.Context(), accountID)
+	if err != nil {
+		if errors.Is(err, db.ErrBankAccountNotFound) {
+			problem.Write(w, http.StatusNotFound, "Account not found", "", r.URL.Path, nil)
+			return nil, false
+		}
+		middleware.Logger(r.Context()).Error("Error getting bank account", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error getting bank account", "", r.URL.Path, nil)
+		return nil, false
+	}
+
+	if account.UserID != claims.UserID {
+		problem.Write(w, http.StatusForbidden, "Forbidden", "", r.URL.Path, nil)
+		return nil, false
+	}
+
+	return account, true
+}
+
+// validAccountType indica si el tipo de cuenta es uno de los soportados
+func validAccountType(accountType string) bool {
+	return accountType == models.AccountTypeChecking || accountType == models.AccountTypeSavings
+}
@@ -0,0 +1,425 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"banca-en-linea/backend/internal/auth"
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/middleware"
+	"banca-en-linea/backend/internal/problem"
+	"banca-en-linea/backend/models"
+)
+
+// AdminHandler maneja las operaciones administrativas
+type AdminHandler struct {
+	userService        *db.UserService
+	bankAccountService *db.BankAccountService
+	authService        *auth.Service
+}
+
+// ConfirmationTokenHeader es el header con el JWT del segundo administrador que confirma una operación sensible
+const ConfirmationTokenHeader = "X-Confirmation-Token"
+
+// NewAdminHandler crea una nueva instancia del handler de administración
+func NewAdminHandler(userService *db.UserService, bankAccountService *db.BankAccountService, authService *auth.Service) *AdminHandler {
+	return &AdminHandler{
+		userService:        userService,
+		bankAccountService: bankAccountService,
+		authService:        authService,
+	}
+}
+
+// CreateUser crea un usuario con rol, estado KYC y depósito inicial en una sola llamada
+func (h *AdminHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateAdminUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	// Validar que los campos requeridos estén presentes
+	if req.Email == "" || req.Password == "" || req.FirstName == "" || req.LastName == "" {
+		problem.Write(w, http.StatusBadRequest, "Email, password, first name, and last name are required", "", r.URL.Path, nil)
+		return
+	}
+
+	// Validar la complejidad de la contraseña
+	if err := auth.ValidatePasswordComplexity(req.Password); err != nil {
+		problem.Write(w, http.StatusBadRequest, "", err.Error(), r.URL.Path, nil)
+		return
+	}
+
+	switch req.Role {
+	case "", models.RoleUser, models.RoleAdmin, models.RoleCompliance:
+	default:
+		problem.Write(w, http.StatusBadRequest, "Invalid role", "", r.URL.Path, nil)
+		return
+	}
+
+	switch req.KYCStatus {
+	case "", models.KYCStatusPending, models.KYCStatusSubmitted, models.KYCStatusApproved, models.KYCStatusRejected:
+	default:
+		problem.Write(w, http.StatusBadRequest, "Invalid KYC status", "", r.URL.Path, nil)
+		return
+	}
+
+	user, err := h.userService.CreateAdminUser(r.Context(), &req)
+	if err != nil {
+		middleware.Logger(r.Context()).Error("Error creating user", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error creating user", "", r.URL.Path, nil)
+		return
+	}
+
+	stats, err := h.userService.GetUserStats(r.Context(), user.ID)
+	if err != nil {
+		middleware.Logger(r.Context()).Error("Error getting user stats", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error getting user stats", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(stats)
+}
+
+// UpdateUserFlags ajusta los indicadores de cuenta de un usuario (activa, email verificado)
+func (h *AdminHandler) UpdateUserFlags(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := uuid.Parse(vars["id"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid user ID", "", r.URL.Path, nil)
+		return
+	}
+
+	var req models.UpdateUserFlagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	if req.IsActive == nil && req.EmailVerified == nil {
+		problem.Write(w, http.StatusBadRequest, "At least one flag is required", "", r.URL.Path, nil)
+		return
+	}
+
+	user, err := h.userService.UpdateUserFlags(r.Context(), userID, &req)
+	if err != nil {
+		if errors.Is(err, db.ErrUserNotFound) {
+			problem.Write(w, http.StatusNotFound, "User not found", "", r.URL.Path, nil)
+			return
+		}
+		middleware.Logger(r.Context()).Error("Error updating user flags", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error updating user flags", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user.ToResponse())
+}
+
+// UpdateKYC registra la revisión KYC de un usuario: su estado y la referencia al documento presentado
+func (h *AdminHandler) UpdateKYC(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := uuid.Parse(vars["id"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid user ID", "", r.URL.Path, nil)
+		return
+	}
+
+	var req models.UpdateKYCRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	switch req.KYCStatus {
+	case models.KYCStatusPending, models.KYCStatusSubmitted, models.KYCStatusApproved, models.KYCStatusRejected:
+	default:
+		problem.Write(w, http.StatusBadRequest, "Invalid KYC status", "", r.URL.Path, nil)
+		return
+	}
+
+	user, err := h.userService.UpdateKYC(r.Context(), userID, &req)
+	if err != nil {
+		if errors.Is(err, db.ErrUserNotFound) {
+			problem.Write(w, http.StatusNotFound, "User not found", "", r.URL.Path, nil)
+			return
+		}
+		middleware.Logger(r.Context()).Error("Error updating user kyc", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error updating KYC status", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user.ToKYCStatusResponse())
+}
+
+// FreezeAccountRequest representa la solicitud de congelamiento de una cuenta
+type FreezeAccountRequest struct {
+	Reason string `json:"reason"`
+}
+
+// FreezeAccount congela la cuenta de un usuario para impedir nuevas operaciones
+func (h *AdminHandler) FreezeAccount(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := uuid.Parse(vars["id"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid user ID", "", r.URL.Path, nil)
+		return
+	}
+
+	var req FreezeAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	if strings.TrimSpace(req.Reason) == "" {
+		problem.Write(w, http.StatusBadRequest, "Reason is required", "", r.URL.Path, nil)
+		return
+	}
+
+	if err := h.userService.FreezeAccount(userID, req.Reason); err != nil {
+		h.writeFreezeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id":   userID,
+		"is_frozen": true,
+	})
+}
+
+// UnfreezeAccount descongela la cuenta de un usuario
+func (h *AdminHandler) UnfreezeAccount(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := uuid.Parse(vars["id"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid user ID", "", r.URL.Path, nil)
+		return
+	}
+
+	if err := h.userService.UnfreezeAccount(userID); err != nil {
+		h.writeFreezeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id":   userID,
+		"is_frozen": false,
+	})
+}
+
+// writeFreezeError responde el error de un cambio de congelamiento
+func (h *AdminHandler) writeFreezeError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, db.ErrUserNotFound) {
+		problem.Write(w, http.StatusNotFound, "User not found", "", r.URL.Path, nil)
+		return
+	}
+	middleware.Logger(r.Context()).Error("Error updating account freeze", zap.Error(err))
+	problem.Write(w, http.StatusInternalServerError, "Error updating account freeze", "", r.URL.Path, nil)
+}
+
+// RecalculateBalanceRequest representa la solicitud de corrección manual de balance
+type RecalculateBalanceRequest struct {
+	Reason string `json:"reason"`
+}
+
+// RecalculateBalance fuerza la corrección del balance de una cuenta. Requiere la confirmación
+// de un segundo administrador mediante su JWT en el header X-Confirmation-Token.
+func (h *AdminHandler) RecalculateBalance(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	accountID, err := uuid.Parse(vars["id"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid account ID", "", r.URL.Path, nil)
+		return
+	}
+
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		problem.Write(w, http.StatusUnauthorized, "Authentication required", "", r.URL.Path, nil)
+		return
+	}
+
+	var req RecalculateBalanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	if strings.TrimSpace(req.Reason) == "" {
+		problem.Write(w, http.StatusBadRequest, "Reason is required", "", r.URL.Path, nil)
+		return
+	}
+
+	// Verificar la confirmación de un segundo administrador
+	confirmingAdminID, ok := h.confirmingAdmin(w, r, claims.UserID)
+	if !ok {
+		return
+	}
+
+	reason := req.Reason + " (confirmed by " + confirmingAdminID.String() + ")"
+	balance, err := h.bankAccountService.RecalculateBalance(r.Context(), accountID, claims.UserID, reason)
+	if err != nil {
+		if errors.Is(err, db.ErrBankAccountNotFound) {
+			problem.Write(w, http.StatusNotFound, "Account not found", "", r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, db.ErrUnrecordedMovements) {
+			problem.Write(w, http.StatusConflict, "Balance includes unrecorded movements", "", r.URL.Path, nil)
+			return
+		}
+		middleware.Logger(r.Context()).Error("Error recalculating balance", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error recalculating balance", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"account_id": accountID,
+		"balance":    balance,
+	})
+}
+
+// UpdateOverdraft desactiva el sobregiro de una cuenta bancaria. Activarlo responde 422, ya que
+// TigerBeetle no permite que las cuentas queden en negativo.
+func (h *AdminHandler) UpdateOverdraft(w http.ResponseWriter, r *http.Request) {
+	accountID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid account ID", "", r.URL.Path, nil)
+		return
+	}
+
+	var req models.UpdateOverdraftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	if req.OverdraftLimitCents < 0 {
+		problem.Write(w, http.StatusBadRequest, "Overdraft limit cannot be negative", "", r.URL.Path, nil)
+		return
+	}
+
+	account, err := h.bankAccountService.UpdateOverdraft(r.Context(), accountID, &req)
+	if err != nil {
+		if errors.Is(err, db.ErrOverdraftNotSupported) {
+			problem.Write(w, http.StatusUnprocessableEntity, "Overdraft is not supported", "", r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, db.ErrBankAccountNotFound) {
+			problem.Write(w, http.StatusNotFound, "Account not found", "", r.URL.Path, nil)
+			return
+		}
+		middleware.Logger(r.Context()).Error("Error updating overdraft", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error updating overdraft", "", r.URL.Path, nil)
+		return
+	}
+
+	middleware.Logger(r.Context()).Info("Overdraft updated",
+		zap.String("account_id", account.ID.String()),
+		zap.Bool("overdraft_enabled", account.OverdraftEnabled),
+		zap.Int64("overdraft_limit_cents", account.OverdraftLimitCents),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(account)
+}
+
+// confirmingAdmin valida el JWT de confirmación y retorna el ID del segundo administrador.
+// Si la confirmación no es válida escribe la respuesta de error y retorna false.
+func (h *AdminHandler) confirmingAdmin(w http.ResponseWriter, r *http.Request, requesterID uuid.UUID) (uuid.UUID, bool) {
+	token := r.Header.Get(ConfirmationTokenHeader)
+	if token == "" {
+		problem.Write(w, http.StatusForbidden, "Confirmation from a second admin is required", "", r.URL.Path, nil)
+		return uuid.Nil, false
+	}
+
+	claims, err := h.authService.ValidateToken(token)
+	if err != nil {
+		problem.Write(w, http.StatusForbidden, "Invalid confirmation token", "", r.URL.Path, nil)
+		return uuid.Nil, false
+	}
+
+	// Un token revocado (logout) no confirma nada; si no puede comprobarse se trata como revocado
+	revoked, err := h.authService.IsTokenRevoked(claims)
+	if err != nil {
+		middleware.Logger(r.Context()).Error("Error checking confirmation token blacklist", zap.Error(err))
+	}
+	if err != nil || revoked {
+		problem.Write(w, http.StatusForbidden, "Invalid confirmation token", "", r.URL.Path, nil)
+		return uuid.Nil, false
+	}
+
+	if claims.UserID == requesterID {
+		problem.Write(w, http.StatusForbidden, "Confirmation must come from a different admin", "", r.URL.Path, nil)
+		return uuid.Nil, false
+	}
+
+	user, err := h.userService.GetUser(claims.UserID)
+	if err != nil || !user.HasRole(models.RoleAdmin) {
+		problem.Write(w, http.StatusForbidden, "Confirming user is not an admin", "", r.URL.Path, nil)
+		return uuid.Nil, false
+	}
+
+	return claims.UserID, true
+}
+
+// activityDefaultRange es el rango consultado cuando no se indica "from"
+const activityDefaultRange = 30 * 24 * time.Hour
+
+// GetUserActivity retorna la línea de tiempo de actividad de un usuario para análisis de fraude.
+// Acepta los parámetros opcionales "from" y "to" en formato RFC3339.
+func (h *AdminHandler) GetUserActivity(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := uuid.Parse(vars["id"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid user ID", "", r.URL.Path, nil)
+		return
+	}
+
+	to := time.Now()
+	if v := r.URL.Query().Get("to"); v != "" {
+		if to, err = time.Parse(time.RFC3339, v); err != nil {
+			problem.Write(w, http.StatusBadRequest, "Invalid 'to' date, expected RFC3339", "", r.URL.Path, nil)
+			return
+		}
+	}
+
+	from := to.Add(-activityDefaultRange)
+	if v := r.URL.Query().Get("from"); v != "" {
+		if from, err = time.Parse(time.RFC3339, v); err != nil {
+			problem.Write(w, http.StatusBadRequest, "Invalid 'from' date, expected RFC3339", "", r.URL.Path, nil)
+			return
+		}
+	}
+
+	if !from.Before(to) {
+		problem.Write(w, http.StatusBadRequest, "'from' must be before 'to'", "", r.URL.Path, nil)
+		return
+	}
+
+	activity, err := h.userService.GetActivity(r.Context(), userID, from, to)
+	if err != nil {
+		if errors.Is(err, db.ErrUserNotFound) {
+			problem.Write(w, http.StatusNotFound, "User not found", "", r.URL.Path, nil)
+			return
+		}
+		middleware.Logger(r.Context()).Error("Error getting user activity", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error getting user activity", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(activity)
+}
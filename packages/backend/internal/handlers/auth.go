@@ -2,84 +2,135 @@ package handlers
 
 import (
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
 
 	"banca-en-linea/backend/internal/auth"
 	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/email"
+	"banca-en-linea/backend/internal/middleware"
+	"banca-en-linea/backend/internal/problem"
+	"banca-en-linea/backend/internal/validation"
 	"banca-en-linea/backend/models"
 )
 
 // AuthHandler maneja las operaciones de autenticación
 type AuthHandler struct {
-	userService *db.UserService
-	authService *auth.Service
+	userService  *db.UserService
+	authService  *auth.Service
+	emailService *email.Service
+	validate     *validator.Validate
 }
 
 // NewAuthHandler crea una nueva instancia del handler de autenticación
-func NewAuthHandler(userService *db.UserService, authService *auth.Service) *AuthHandler {
+func NewAuthHandler(userService *db.UserService, authService *auth.Service, emailService *email.Service) *AuthHandler {
 	return &AuthHandler{
-		userService: userService,
-		authService: authService,
+		userService:  userService,
+		authService:  authService,
+		emailService: emailService,
+		validate:     newValidator(),
 	}
 }
 
 // RegisterResponse representa la respuesta del registro
 type RegisterResponse struct {
-	User  models.UserResponse `json:"user"`
-	Token string              `json:"token"`
+	User         models.UserResponse `json:"user"`
+	Token        string              `json:"token"`
+	RefreshToken string              `json:"refresh_token"`
 }
 
 // LoginResponse representa la respuesta del login
 type LoginResponse struct {
-	User  models.UserResponse `json:"user"`
-	Token string              `json:"token"`
+	User         models.UserResponse `json:"user"`
+	Token        string              `json:"token"`
+	RefreshToken string              `json:"refresh_token"`
+}
+
+// MFAChallengeResponse es la respuesta del login cuando el usuario tiene TOTP activado
+type MFAChallengeResponse struct {
+	MFARequired       bool   `json:"mfa_required"`
+	MFAChallengeToken string `json:"mfa_challenge_token"`
+}
+
+// TOTPEnableResponse contiene la URI otpauth:// para configurar la aplicación de autenticación
+type TOTPEnableResponse struct {
+	QRURI string `json:"qr_uri"`
 }
 
 // Register maneja el registro de nuevos usuarios
 func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		middleware.WriteDecodeError(w, r, err)
 		return
 	}
 
-	// Validar que los campos requeridos estén presentes
-	if req.Email == "" || req.Password == "" || req.FirstName == "" || req.LastName == "" {
-		http.Error(w, "Email, password, first name, and last name are required", http.StatusBadRequest)
+	// Validar formato y longitud de los campos según las etiquetas validate del modelo
+	if err := h.validate.Struct(&req); err != nil {
+		writeValidationError(w, r, err)
 		return
 	}
 
-	// Validar longitud mínima de contraseña
-	if len(req.Password) < 8 {
-		http.Error(w, "Password must be at least 8 characters long", http.StatusBadRequest)
+	// Validar la complejidad de la contraseña
+	if err := auth.ValidatePasswordComplexity(req.Password); err != nil {
+		problem.Write(w, http.StatusBadRequest, "", err.Error(), r.URL.Path, nil)
 		return
 	}
 
+	// Validar la edad mínima si se indicó la fecha de nacimiento
+	if req.DateOfBirth != nil {
+		if err := validation.ValidateMinimumAge(*req.DateOfBirth, time.Now()); err != nil {
+			problem.Write(w, http.StatusBadRequest, "Invalid date of birth", err.Error(), r.URL.Path, nil)
+			return
+		}
+	}
+
 	// Crear usuario con cuenta TigerBeetle
 	user, err := h.userService.CreateUserWithAccount(&req)
 	if err != nil {
-		log.Printf("Error creating user: %v", err)
+		middleware.Logger(r.Context()).Error("Error creating user", zap.Error(err))
 		if err.Error() == "user already exists" {
-			http.Error(w, "User with this email already exists", http.StatusConflict)
+			problem.Write(w, http.StatusConflict, "User with this email already exists", "", r.URL.Path, nil)
 			return
 		}
-		http.Error(w, "Error creating user", http.StatusInternalServerError)
+		problem.Write(w, http.StatusInternalServerError, "Error creating user", "", r.URL.Path, nil)
 		return
 	}
 
+	// Enviar email de verificación; un fallo no impide el registro, el usuario puede verificarse después
+	if err := h.emailService.SendVerificationEmail(user); err != nil {
+		middleware.Logger(r.Context()).Error("Error sending verification email", zap.Stringer("user_id", user.ID), zap.Error(err))
+	}
+
 	// Generar token JWT
 	token, err := h.authService.GenerateToken(user)
 	if err != nil {
-		log.Printf("Error generating token: %v", err)
-		http.Error(w, "Error generating authentication token", http.StatusInternalServerError)
+		middleware.Logger(r.Context()).Error("Error generating token", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error generating authentication token", "", r.URL.Path, nil)
+		return
+	}
+
+	refreshToken, err := h.authService.GenerateRefreshToken(user, sessionInfo(r))
+	if err != nil {
+		middleware.Logger(r.Context()).Error("Error generating refresh token", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error generating authentication token", "", r.URL.Path, nil)
 		return
 	}
 
-	// Responder con el usuario y token
+	// Responder con el usuario y tokens
 	response := RegisterResponse{
-		User:  user.ToResponse(),
-		Token: token,
+		User:         user.ToResponse(),
+		Token:        token,
+		RefreshToken: refreshToken,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -91,62 +142,331 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req models.LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		middleware.WriteDecodeError(w, r, err)
 		return
 	}
 
-	// Validar que los campos requeridos estén presentes
-	if req.Email == "" || req.Password == "" {
-		http.Error(w, "Email and password are required", http.StatusBadRequest)
+	// Validar formato de los campos según las etiquetas validate del modelo
+	if err := h.validate.Struct(&req); err != nil {
+		writeValidationError(w, r, err)
 		return
 	}
 
-	// Obtener usuario por email
-	user, err := h.userService.GetUserByEmail(req.Email)
+	// Validar credenciales bloqueando la cuenta mientras dura el inicio de sesión
+	user, err := h.userService.Authenticate(r.Context(), req.Email, req.Password)
 	if err != nil {
-		log.Printf("Error getting user by email: %v", err)
-		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+		switch {
+		case errors.Is(err, db.ErrConcurrentLogin):
+			problem.Write(w, http.StatusTooManyRequests, "Another login is in progress for this account", "", r.URL.Path, nil)
+		case errors.Is(err, db.ErrAccountDeactivated):
+			problem.Write(w, http.StatusUnauthorized, "Account is deactivated", "", r.URL.Path, nil)
+		case errors.Is(err, db.ErrInvalidCredentials):
+			middleware.Logger(r.Context()).Warn("Invalid credentials", zap.String("email", req.Email))
+			problem.Write(w, http.StatusUnauthorized, "Invalid credentials", "", r.URL.Path, nil)
+		default:
+			middleware.Logger(r.Context()).Error("Error authenticating user", zap.Error(err))
+			problem.Write(w, http.StatusUnauthorized, "Invalid credentials", "", r.URL.Path, nil)
+		}
 		return
 	}
 
-	// Verificar que el usuario esté activo
-	if !user.IsActive {
-		http.Error(w, "Account is deactivated", http.StatusUnauthorized)
+	// Con TOTP activado el access token solo se emite tras validar el segundo factor
+	if user.TOTPEnabled {
+		challenge, err := h.authService.GenerateMFAChallengeToken(user)
+		if err != nil {
+			middleware.Logger(r.Context()).Error("Error generating mfa challenge token", zap.Error(err))
+			problem.Write(w, http.StatusInternalServerError, "Error generating authentication token", "", r.URL.Path, nil)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(MFAChallengeResponse{
+			MFARequired:       true,
+			MFAChallengeToken: challenge,
+		})
 		return
 	}
 
-	// Verificar contraseña
-	if err := h.authService.VerifyPassword(user.PasswordHash, req.Password); err != nil {
-		log.Printf("Invalid password for user %s", req.Email)
-		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
-		return
+	h.writeLoginResponse(w, r, user)
+}
+
+// writeLoginResponse registra el inicio de sesión y emite el access token y el refresh token del
+// usuario autenticado. La respuesta conserva el last_login_at anterior para mostrar la última conexión.
+func (h *AuthHandler) writeLoginResponse(w http.ResponseWriter, r *http.Request, user *models.User) {
+	// Un fallo al registrar el inicio de sesión no impide emitir los tokens
+	if err := h.userService.RecordLogin(r.Context(), user.ID); err != nil {
+		middleware.Logger(r.Context()).Error("Error recording login", zap.Stringer("user_id", user.ID), zap.Error(err))
 	}
 
 	// Generar token JWT
 	token, err := h.authService.GenerateToken(user)
 	if err != nil {
-		log.Printf("Error generating token: %v", err)
-		http.Error(w, "Error generating authentication token", http.StatusInternalServerError)
+		middleware.Logger(r.Context()).Error("Error generating token", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error generating authentication token", "", r.URL.Path, nil)
+		return
+	}
+
+	refreshToken, err := h.authService.GenerateRefreshToken(user, sessionInfo(r))
+	if err != nil {
+		middleware.Logger(r.Context()).Error("Error generating refresh token", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error generating authentication token", "", r.URL.Path, nil)
 		return
 	}
 
-	// Responder con el usuario y token
+	// Responder con el usuario y tokens
 	response := LoginResponse{
-		User:  user.ToResponse(),
-		Token: token,
+		User:         user.ToResponse(),
+		Token:        token,
+		RefreshToken: refreshToken,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
-// Logout maneja el cierre de sesión (en este caso, simplemente confirma el logout)
+// sessionInfo retorna el dispositivo y la IP desde los que se inicia la sesión
+func sessionInfo(r *http.Request) models.SessionInfo {
+	return models.SessionInfo{
+		DeviceInfo: r.UserAgent(),
+		IPAddress:  middleware.ClientIP(r),
+	}
+}
+
+// ListSessions lista las sesiones activas del usuario autenticado
+func (h *AuthHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizedUser(w, r)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.authService.ListSessions(r.Context(), userID)
+	if err != nil {
+		middleware.Logger(r.Context()).Error("Error listing sessions", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error listing sessions", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sessions)
+}
+
+// RevokeSession cierra una sesión del usuario autenticado revocando su refresh token
+func (h *AuthHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizedUser(w, r)
+	if !ok {
+		return
+	}
+
+	sessionID, err := uuid.Parse(mux.Vars(r)["sessionId"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid session ID", "", r.URL.Path, nil)
+		return
+	}
+
+	if err := h.authService.RevokeSession(r.Context(), userID, sessionID); err != nil {
+		if errors.Is(err, auth.ErrSessionNotFound) {
+			problem.Write(w, http.StatusNotFound, "Session not found", "", r.URL.Path, nil)
+			return
+		}
+		middleware.Logger(r.Context()).Error("Error revoking session", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error revoking session", "", r.URL.Path, nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// EnableTOTP genera un nuevo secreto TOTP para el usuario autenticado y retorna la URI
+// para el código QR. TOTP no se activa hasta confirmar un código con VerifyTOTP.
+func (h *AuthHandler) EnableTOTP(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		problem.Write(w, http.StatusUnauthorized, "Authentication required", "", r.URL.Path, nil)
+		return
+	}
+
+	user, err := h.userService.GetUser(claims.UserID)
+	if err != nil {
+		middleware.Logger(r.Context()).Error("Error getting user", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error getting user information", "", r.URL.Path, nil)
+		return
+	}
+
+	if user.TOTPEnabled {
+		problem.Write(w, http.StatusConflict, "TOTP is already enabled", "", r.URL.Path, nil)
+		return
+	}
+
+	encryptedSecret, uri, err := h.authService.GenerateTOTPSecret(user)
+	if err != nil {
+		middleware.Logger(r.Context()).Error("Error generating totp secret", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error enabling TOTP", "", r.URL.Path, nil)
+		return
+	}
+
+	if err := h.userService.SetTOTPSecret(r.Context(), user.ID, encryptedSecret); err != nil {
+		middleware.Logger(r.Context()).Error("Error storing totp secret", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error enabling TOTP", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TOTPEnableResponse{QRURI: uri})
+}
+
+// VerifyTOTP activa TOTP tras validar un código generado con el secreto recién configurado
+func (h *AuthHandler) VerifyTOTP(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		problem.Write(w, http.StatusUnauthorized, "Authentication required", "", r.URL.Path, nil)
+		return
+	}
+
+	var req models.TOTPCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	user, err := h.userService.GetUser(claims.UserID)
+	if err != nil {
+		middleware.Logger(r.Context()).Error("Error getting user", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error getting user information", "", r.URL.Path, nil)
+		return
+	}
+
+	if err := h.authService.VerifyTOTP(user, req.Code); err != nil {
+		h.writeTOTPError(w, r, err)
+		return
+	}
+
+	if err := h.userService.EnableTOTP(r.Context(), user.ID); err != nil {
+		middleware.Logger(r.Context()).Error("Error enabling totp", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error enabling TOTP", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "TOTP enabled successfully",
+	})
+}
+
+// ConfirmTOTP completa el inicio de sesión validando el código TOTP contra el desafío MFA
+func (h *AuthHandler) ConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	var req models.TOTPConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	if req.MFAChallengeToken == "" || req.Code == "" {
+		problem.Write(w, http.StatusBadRequest, "MFA challenge token and code are required", "", r.URL.Path, nil)
+		return
+	}
+
+	claims, err := h.authService.ValidateMFAChallengeToken(req.MFAChallengeToken)
+	if err != nil {
+		problem.Write(w, http.StatusUnauthorized, "Invalid or expired MFA challenge", "", r.URL.Path, nil)
+		return
+	}
+
+	user, err := h.userService.GetUser(claims.UserID)
+	if err != nil {
+		middleware.Logger(r.Context()).Error("Error getting user", zap.Error(err))
+		problem.Write(w, http.StatusUnauthorized, "Invalid or expired MFA challenge", "", r.URL.Path, nil)
+		return
+	}
+
+	if !user.TOTPEnabled {
+		problem.Write(w, http.StatusUnauthorized, "Invalid or expired MFA challenge", "", r.URL.Path, nil)
+		return
+	}
+
+	if err := h.authService.VerifyTOTP(user, req.Code); err != nil {
+		h.writeTOTPError(w, r, err)
+		return
+	}
+
+	h.writeLoginResponse(w, r, user)
+}
+
+// writeTOTPError traduce los errores de validación TOTP a respuestas HTTP
+func (h *AuthHandler) writeTOTPError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, auth.ErrInvalidTOTPCode):
+		problem.Write(w, http.StatusUnauthorized, "Invalid TOTP code", "", r.URL.Path, nil)
+	case errors.Is(err, auth.ErrTOTPNotConfigured):
+		problem.Write(w, http.StatusBadRequest, "TOTP is not configured", "", r.URL.Path, nil)
+	default:
+		middleware.Logger(r.Context()).Error("Error verifying totp code", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error verifying TOTP code", "", r.URL.Path, nil)
+	}
+}
+
+// Refresh emite un nuevo access token a partir de un refresh token válido
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req models.RefreshTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	if req.RefreshToken == "" {
+		problem.Write(w, http.StatusBadRequest, "Refresh token is required", "", r.URL.Path, nil)
+		return
+	}
+
+	token, err := h.authService.RefreshAccessToken(req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidRefreshToken) {
+			problem.Write(w, http.StatusUnauthorized, "Invalid or expired refresh token", "", r.URL.Path, nil)
+			return
+		}
+		middleware.Logger(r.Context()).Error("Error refreshing token", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error refreshing token", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"token": token,
+	})
+}
+
+// Logout cierra la sesión revocando el access token del header Authorization (hasta su
+// expiración) y, si se envía, el refresh token del cuerpo
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
-	// En una implementación JWT stateless, el logout se maneja en el cliente
-	// eliminando el token. Aquí simplemente confirmamos el logout.
+	var req models.RefreshTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	accessToken, hasAccessToken := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !hasAccessToken && req.RefreshToken == "" {
+		problem.Write(w, http.StatusBadRequest, "Access token or refresh token is required", "", r.URL.Path, nil)
+		return
+	}
 
-	// En el futuro, se podría implementar una blacklist de tokens
-	// o usar refresh tokens para un control más granular.
+	// Un access token inválido o expirado ya no puede usarse; no hay nada que revocar
+	if hasAccessToken {
+		if claims, err := h.authService.ValidateToken(accessToken); err == nil {
+			if err := h.authService.RevokeToken(claims); err != nil {
+				middleware.Logger(r.Context()).Error("Error revoking access token", zap.Error(err))
+				problem.Write(w, http.StatusInternalServerError, "Error logging out", "", r.URL.Path, nil)
+				return
+			}
+		}
+	}
+
+	if req.RefreshToken != "" {
+		if err := h.authService.RevokeRefreshToken(req.RefreshToken); err != nil {
+			middleware.Logger(r.Context()).Error("Error revoking refresh token", zap.Error(err))
+			problem.Write(w, http.StatusInternalServerError, "Error logging out", "", r.URL.Path, nil)
+			return
+		}
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
@@ -154,20 +474,213 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// VerifyEmail marca como verificado el email del usuario dueño del token
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req models.VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	if req.Token == "" {
+		problem.Write(w, http.StatusBadRequest, "Token is required", "", r.URL.Path, nil)
+		return
+	}
+
+	userID, err := h.authService.VerifyEmailToken(req.Token)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidVerificationToken) {
+			problem.Write(w, http.StatusBadRequest, "Invalid or expired verification token", "", r.URL.Path, nil)
+			return
+		}
+		middleware.Logger(r.Context()).Error("Error validating verification token", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error verifying email", "", r.URL.Path, nil)
+		return
+	}
+
+	if err := h.userService.VerifyEmail(r.Context(), userID); err != nil {
+		middleware.Logger(r.Context()).Error("Error verifying email", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error verifying email", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Email verified successfully",
+	})
+}
+
+// ForgotPassword envía un enlace de restablecimiento de contraseña al email indicado.
+// Siempre responde lo mismo para no revelar qué emails están registrados.
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req models.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	if req.Email == "" {
+		problem.Write(w, http.StatusBadRequest, "Email is required", "", r.URL.Path, nil)
+		return
+	}
+
+	user, err := h.userService.GetUserByEmail(req.Email)
+	if err != nil {
+		middleware.Logger(r.Context()).Info("Password reset requested for unknown email", zap.String("email", req.Email))
+	} else if user.IsActive {
+		if err := h.emailService.SendPasswordResetEmail(user); err != nil {
+			middleware.Logger(r.Context()).Error("Error sending password reset email", zap.Stringer("user_id", user.ID), zap.Error(err))
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "If the email is registered, a password reset link has been sent",
+	})
+}
+
+// ResetPassword establece una nueva contraseña usando un token de restablecimiento de un solo uso
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req models.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	if req.Token == "" || req.NewPassword == "" {
+		problem.Write(w, http.StatusBadRequest, "Token and new password are required", "", r.URL.Path, nil)
+		return
+	}
+
+	// Validar la complejidad antes de consumir el token
+	if err := auth.ValidatePasswordComplexity(req.NewPassword); err != nil {
+		problem.Write(w, http.StatusBadRequest, "", err.Error(), r.URL.Path, nil)
+		return
+	}
+
+	userID, err := h.authService.ConsumePasswordResetToken(req.Token)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidResetToken) {
+			problem.Write(w, http.StatusBadRequest, "Invalid or expired reset token", "", r.URL.Path, nil)
+			return
+		}
+		middleware.Logger(r.Context()).Error("Error validating reset token", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error resetting password", "", r.URL.Path, nil)
+		return
+	}
+
+	if err := h.userService.ResetPassword(r.Context(), userID, req.NewPassword); err != nil {
+		if writePasswordError(w, r, err) {
+			return
+		}
+		middleware.Logger(r.Context()).Error("Error resetting password", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error resetting password", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Password reset successfully",
+	})
+}
+
+// ChangePassword cambia la contraseña del usuario autenticado verificando su contraseña actual.
+// Un usuario solo puede cambiar su propia contraseña.
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid user ID", "", r.URL.Path, nil)
+		return
+	}
+
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		problem.Write(w, http.StatusUnauthorized, "Authentication required", "", r.URL.Path, nil)
+		return
+	}
+
+	if claims.UserID != userID {
+		problem.Write(w, http.StatusForbidden, "Forbidden", "", r.URL.Path, nil)
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	if req.CurrentPassword == "" || req.NewPassword == "" {
+		problem.Write(w, http.StatusBadRequest, "Current password and new password are required", "", r.URL.Path, nil)
+		return
+	}
+
+	if err := h.userService.ChangePassword(userID, req.CurrentPassword, req.NewPassword); err != nil {
+		if writePasswordError(w, r, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, db.ErrIncorrectPassword):
+			problem.Write(w, http.StatusUnauthorized, "Current password is incorrect", "", r.URL.Path, nil)
+		case errors.Is(err, db.ErrUserNotFound):
+			problem.Write(w, http.StatusNotFound, "User not found", "", r.URL.Path, nil)
+		default:
+			middleware.Logger(r.Context()).Error("Error changing password", zap.Error(err))
+			problem.Write(w, http.StatusInternalServerError, "Error changing password", "", r.URL.Path, nil)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Password changed successfully",
+	})
+}
+
+// writePasswordError responde 400 con el detalle cuando la nueva contraseña no cumple
+// las reglas de complejidad o ya fue usada. Retorna false si err es de otro tipo.
+func writePasswordError(w http.ResponseWriter, r *http.Request, err error) bool {
+	var complexityErr *auth.PasswordComplexityError
+	switch {
+	case errors.As(err, &complexityErr):
+		problem.Write(w, http.StatusBadRequest, "", complexityErr.Error(), r.URL.Path, nil)
+	case errors.Is(err, db.ErrPasswordReused):
+		problem.Write(w, http.StatusBadRequest, "", fmt.Sprintf("New password must not match any of your last %d passwords", db.PasswordHistorySize), r.URL.Path, nil)
+	default:
+		return false
+	}
+	return true
+}
+
+// JWKS publica la llave pública de verificación de los JWT para que otros servicios
+// puedan validarlos sin compartir secretos
+func (h *AuthHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	jwks, err := h.authService.JWKS()
+	if err != nil {
+		middleware.Logger(r.Context()).Error("Error building JWKS", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error building JWKS", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(jwks)
+}
+
 // Me retorna la información del usuario autenticado
 func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
 	// Obtener claims del contexto (agregado por el middleware de auth)
 	claims, ok := r.Context().Value("user").(*auth.Claims)
 	if !ok {
-		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		problem.Write(w, http.StatusInternalServerError, "User not found in context", "", r.URL.Path, nil)
 		return
 	}
 
 	// Obtener información actualizada del usuario
 	user, err := h.userService.GetUser(claims.UserID)
 	if err != nil {
-		log.Printf("Error getting user: %v", err)
-		http.Error(w, "Error getting user information", http.StatusInternalServerError)
+		middleware.Logger(r.Context()).Error("Error getting user", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error getting user information", "", r.URL.Path, nil)
 		return
 	}
 
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"banca-en-linea/backend/internal/middleware"
+	"banca-en-linea/backend/internal/monitoring"
+	"banca-en-linea/backend/internal/problem"
+)
+
+// BalanceStreamHandler expone los cambios de balance del usuario en tiempo real
+type BalanceStreamHandler struct {
+	broker *monitoring.BalanceBroker
+}
+
+// NewBalanceStreamHandler crea una nueva instancia del handler de streams de balance
+func NewBalanceStreamHandler(broker *monitoring.BalanceBroker) *BalanceStreamHandler {
+	return &BalanceStreamHandler{
+		broker: broker,
+	}
+}
+
+// Stream envía los cambios de balance de las cuentas del usuario usando Server-Sent Events,
+// hasta que el cliente se desconecta
+func (h *BalanceStreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizedUser(w, r)
+	if !ok {
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		problem.Write(w, http.StatusInternalServerError, "Streaming not supported", "", r.URL.Path, nil)
+		return
+	}
+
+	events, unsubscribe, err := h.broker.Subscribe(userID)
+	if err != nil {
+		if errors.Is(err, monitoring.ErrTooManySubscribers) {
+			problem.Write(w, http.StatusServiceUnavailable, "Too many concurrent streams", "", r.URL.Path, nil)
+			return
+		}
+		middleware.Logger(r.Context()).Error("Error subscribing to balance changes", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error subscribing to balance changes", "", r.URL.Path, nil)
+		return
+	}
+	defer unsubscribe()
+
+	startEventStream(w, r, flusher)
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if !writeEvent(w, r, flusher, event) {
+				return
+			}
+		}
+	}
+}
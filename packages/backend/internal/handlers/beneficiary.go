@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/middleware"
+	"banca-en-linea/backend/internal/problem"
+	"banca-en-linea/backend/models"
+)
+
+// BeneficiaryHandler maneja los destinatarios guardados del usuario
+type BeneficiaryHandler struct {
+	beneficiaryService *db.BeneficiaryService
+}
+
+// NewBeneficiaryHandler crea una nueva instancia del handler de beneficiarios
+func NewBeneficiaryHandler(beneficiaryService *db.BeneficiaryService) *BeneficiaryHandler {
+	return &BeneficiaryHandler{
+		beneficiaryService: beneficiaryService,
+	}
+}
+
+// List retorna los beneficiarios guardados por el usuario
+func (h *BeneficiaryHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizedUser(w, r)
+	if !ok {
+		return
+	}
+
+	beneficiaries, err := h.beneficiaryService.List(r.Context(), userID)
+	if err != nil {
+		h.writeError(w, r, err, "Error listing beneficiaries")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(beneficiaries)
+}
+
+// Create guarda un nuevo beneficiario del usuario
+func (h *BeneficiaryHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizedUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CreateBeneficiaryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	if req.RecipientUserID == uuid.Nil {
+		problem.Write(w, http.StatusBadRequest, "Recipient user ID is required", "", r.URL.Path, nil)
+		return
+	}
+
+	if len(req.Alias) > 100 {
+		problem.Write(w, http.StatusBadRequest, "Alias must be at most 100 characters", "", r.URL.Path, nil)
+		return
+	}
+
+	beneficiary, err := h.beneficiaryService.Add(r.Context(), userID, req.RecipientUserID, req.Alias)
+	if err != nil {
+		h.writeError(w, r, err, "Error adding beneficiary")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(beneficiary)
+}
+
+// Delete elimina un beneficiario del usuario
+func (h *BeneficiaryHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizedUser(w, r)
+	if !ok {
+		return
+	}
+
+	beneficiaryID, err := uuid.Parse(mux.Vars(r)["beneficiaryId"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid beneficiary ID", "", r.URL.Path, nil)
+		return
+	}
+
+	if err := h.beneficiaryService.Remove(r.Context(), userID, beneficiaryID); err != nil {
+		h.writeError(w, r, err, "Error deleting beneficiary")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeError responde el error de una operación sobre beneficiarios
+func (h *BeneficiaryHandler) writeError(w http.ResponseWriter, r *http.Request, err error, title string) {
+	switch {
+	case errors.Is(err, db.ErrSelfBeneficiary):
+		problem.Write(w, http.StatusBadRequest, "Cannot add yourself as beneficiary", "", r.URL.Path, nil)
+	case errors.Is(err, db.ErrRecipientNotFound):
+		problem.Write(w, http.StatusNotFound, "Recipient not found", "", r.URL.Path, nil)
+	case errors.Is(err, db.ErrRecipientInactive):
+		problem.Write(w, http.StatusUnprocessableEntity, "Recipient is inactive", "", r.URL.Path, nil)
+	case errors.Is(err, db.ErrBeneficiaryExists):
+		problem.Write(w, http.StatusConflict, "Beneficiary already exists", "", r.URL.Path, nil)
+	case errors.Is(err, db.ErrBeneficiaryNotFound):
+		problem.Write(w, http.StatusNotFound, "Beneficiary not found", "", r.URL.Path, nil)
+	default:
+		middleware.Logger(r.Context()).Error(title, zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, title, "", r.URL.Path, nil)
+	}
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/middleware"
+	"banca-en-linea/backend/internal/problem"
+	"banca-en-linea/backend/models"
+)
+
+// DisputeHandler maneja las disputas de transacciones y su resolución
+type DisputeHandler struct {
+	disputeService *db.DisputeService
+}
+
+// NewDisputeHandler crea una nueva instancia del handler de disputas
+func NewDisputeHandler(disputeService *db.DisputeService) *DisputeHandler {
+	return &DisputeHandler{
+		disputeService: disputeService,
+	}
+}
+
+// Create abre una disputa del usuario sobre una de sus transacciones
+func (h *DisputeHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizedUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CreateDisputeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	if req.TransactionID == uuid.Nil {
+		problem.Write(w, http.StatusBadRequest, "Transaction ID is required", "", r.URL.Path, nil)
+		return
+	}
+
+	dispute, err := h.disputeService.Create(r.Context(), userID, &req)
+	if err != nil {
+		h.writeError(w, r, err, "Error creating dispute")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(dispute)
+}
+
+// List retorna las disputas abiertas por el usuario
+func (h *DisputeHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizedUser(w, r)
+	if !ok {
+		return
+	}
+
+	disputes, err := h.disputeService.GetByUser(r.Context(), userID)
+	if err != nil {
+		h.writeError(w, r, err, "Error listing disputes")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(disputes)
+}
+
+// Resolve aprueba o rechaza una disputa abierta
+func (h *DisputeHandler) Resolve(w http.ResponseWriter, r *http.Request) {
+	resolverID, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	disputeID, err := uuid.Parse(mux.Vars(r)["disputeId"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid dispute ID", "", r.URL.Path, nil)
+		return
+	}
+
+	var req models.ResolveDisputeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	dispute, err := h.disputeService.Resolve(r.Context(), disputeID, resolverID, &req)
+	if err != nil {
+		h.writeError(w, r, err, "Error resolving dispute")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dispute)
+}
+
+// writeError responde el error de una operación sobre disputas
+func (h *DisputeHandler) writeError(w http.ResponseWriter, r *http.Request, err error, title string) {
+	switch {
+	case errors.Is(err, db.ErrInvalidDisputeReason):
+		problem.Write(w, http.StatusBadRequest, "Reason is required and must be at most 1000 characters", "", r.URL.Path, nil)
+	case errors.Is(err, db.ErrInvalidDisputeResolution):
+		problem.Write(w, http.StatusBadRequest, "Status must be 'approved' or 'rejected'", "", r.URL.Path, nil)
+	case errors.Is(err, db.ErrTransactionNotFound):
+		problem.Write(w, http.StatusNotFound, "Transaction not found", "", r.URL.Path, nil)
+	case errors.Is(err, db.ErrDisputeExists):
+		problem.Write(w, http.StatusConflict, "Transaction already has an open dispute", "", r.URL.Path, nil)
+	case errors.Is(err, db.ErrDisputeNotFound):
+		problem.Write(w, http.StatusNotFound, "Dispute not found", "", r.URL.Path, nil)
+	case errors.Is(err, db.ErrDisputeNotOpen):
+		problem.Write(w, http.StatusConflict, "Dispute is already resolved", "", r.URL.Path, nil)
+	default:
+		middleware.Logger(r.Context()).Error(title, zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, title, "", r.URL.Path, nil)
+	}
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/middleware"
+	"banca-en-linea/backend/internal/problem"
+	"banca-en-linea/backend/models"
+)
+
+const (
+	// OTPChallengeHeader lleva el token del desafío recibido en la respuesta 202
+	OTPChallengeHeader = "X-OTP-Challenge"
+	// OTPCodeHeader lleva el código OTP que el usuario recibió por email
+	OTPCodeHeader = "X-OTP-Code"
+
+	// defaultLargeTransferThreshold es el monto en centavos a partir del cual se exige OTP
+	defaultLargeTransferThreshold = 100000
+)
+
+// LargeTransferGuard exige confirmación OTP para las transferencias que superan un umbral
+type LargeTransferGuard struct {
+	otpService *db.OTPService
+	threshold  uint64
+}
+
+// NewLargeTransferGuard crea una nueva instancia del verificador de transferencias grandes
+func NewLargeTransferGuard(otpService *db.OTPService, threshold uint64) *LargeTransferGuard {
+	return &LargeTransferGuard{
+		otpService: otpService,
+		threshold:  threshold,
+	}
+}
+
+// LargeTransferThresholdFromEnv lee el umbral de LARGE_TRANSFER_THRESHOLD_CENTS (100000 por defecto)
+func LargeTransferThresholdFromEnv() uint64 {
+	v := os.Getenv("LARGE_TRANSFER_THRESHOLD_CENTS")
+	if v == "" {
+		return defaultLargeTransferThreshold
+	}
+
+	threshold, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		log.Printf("Warning: invalid LARGE_TRANSFER_THRESHOLD_CENTS %q, using %d", v, defaultLargeTransferThreshold)
+		return defaultLargeTransferThreshold
+	}
+	return threshold
+}
+
+// RecipientUser identifica como destino de una transferencia a la cuenta principal de un usuario
+func RecipientUser(userID uuid.UUID) string {
+	return "user:" + userID.String()
+}
+
+// RecipientEmail identifica como destino de una transferencia al usuario con el email indicado
+func RecipientEmail(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// RecipientAccount identifica como destino de una transferencia a la cuenta bancaria con el número indicado
+func RecipientAccount(accountNumber string) string {
+	return "account:" + strings.TrimSpace(accountNumber)
+}
+
+// RecipientSplits identifica como destino de una transferencia dividida a sus partes, en orden
+func RecipientSplits(splits []models.SplitTarget) string {
+	parts := make([]string, len(splits))
+	for i, split := range splits {
+		parts[i] = fmt.Sprintf("%s=%d", split.ToUserID, split.AmountCents)
+	}
+	return "split:" + strings.Join(parts, ",")
+}
+
+// Confirm indica si la transferencia puede continuar. Si el monto supera el umbral y la petición
+// no trae un código OTP, envía uno al usuario y responde 202 con el token del desafío; si el
+// código es incorrecto responde 401. En ambos casos la transferencia no se realiza.
+//
+// El desafío queda vinculado al destino (ver RecipientUser, RecipientEmail y RecipientSplits) y
+// al monto, de modo que el código no confirma una transferencia distinta de la que lo generó.
+func (g *LargeTransferGuard) Confirm(w http.ResponseWriter, r *http.Request, userID uuid.UUID, amount uint64, recipient string) bool {
+	if amount <= g.threshold {
+		return true
+	}
+
+	operation := fmt.Sprintf("%s|%d", recipient, amount)
+	code := r.Header.Get(OTPCodeHeader)
+	if code == "" {
+		challengeToken, err := g.otpService.Generate(r.Context(), userID, operation)
+		if err != nil {
+			middleware.Logger(r.Context()).Error("Error generating OTP code", zap.Error(err))
+			problem.Write(w, http.StatusInternalServerError, "Error sending confirmation code", "", r.URL.Path, nil)
+			return false
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"otp_required":    true,
+			"challenge_token": challengeToken,
+			"expires_in":      int(db.OTPTTL.Seconds()),
+		})
+		return false
+	}
+
+	err := g.otpService.Verify(r.Context(), userID, r.Header.Get(OTPChallengeHeader), code, operation)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidOTP) {
+			problem.Write(w, http.StatusUnauthorized, "Invalid or expired OTP code", "", r.URL.Path, nil)
+			return false
+		}
+		middleware.Logger(r.Context()).Error("Error verifying OTP code", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error verifying confirmation code", "", r.URL.Path, nil)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"banca-en-linea/backend/internal/middleware"
+	"banca-en-linea/backend/internal/monitoring"
+	"banca-en-linea/backend/internal/problem"
+)
+
+// MonitoringHandler expone el stream de transacciones para administradores
+type MonitoringHandler struct {
+	monitoringService *monitoring.MonitoringService
+}
+
+// NewMonitoringHandler crea una nueva instancia del handler de monitoreo
+func NewMonitoringHandler(monitoringService *monitoring.MonitoringService) *MonitoringHandler {
+	return &MonitoringHandler{
+		monitoringService: monitoringService,
+	}
+}
+
+// StreamTransactions envía las transacciones en tiempo real usando Server-Sent Events
+func (h *MonitoringHandler) StreamTransactions(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		problem.Write(w, http.StatusInternalServerError, "Streaming not supported", "", r.URL.Path, nil)
+		return
+	}
+
+	events, unsubscribe, err := h.monitoringService.Subscribe()
+	if err != nil {
+		if errors.Is(err, monitoring.ErrTooManySubscribers) {
+			problem.Write(w, http.StatusServiceUnavailable, "Too many concurrent streams", "", r.URL.Path, nil)
+			return
+		}
+		middleware.Logger(r.Context()).Error("Error subscribing to transactions", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error subscribing to transactions", "", r.URL.Path, nil)
+		return
+	}
+	defer unsubscribe()
+
+	startEventStream(w, r, flusher)
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if !writeEvent(w, r, flusher, event) {
+				return
+			}
+		}
+	}
+}
+
+// startEventStream envía los encabezados de una respuesta Server-Sent Events
+func startEventStream(w http.ResponseWriter, r *http.Request, flusher http.Flusher) {
+	// El stream es una conexión de larga duración: quitar el WriteTimeout del servidor
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		middleware.Logger(r.Context()).Warn("Could not clear write deadline for stream", zap.Error(err))
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+}
+
+// writeEvent escribe un evento del stream como JSON. Retorna false si el cliente se desconectó.
+// Los eventos que no se pueden serializar se omiten.
+func writeEvent(w http.ResponseWriter, r *http.Request, flusher http.Flusher, event any) bool {
+	data, err := json.Marshal(event)
+	if err != nil {
+		middleware.Logger(r.Context()).Error("Error serializing stream event", zap.Error(err))
+		return true
+	}
+
+	if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+		return false
+	}
+	flusher.Flush()
+	return true
+}
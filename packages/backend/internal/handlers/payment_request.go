@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/middleware"
+	"banca-en-linea/backend/internal/problem"
+	"banca-en-linea/backend/models"
+)
+
+// PaymentRequestHandler maneja las solicitudes de dinero entre usuarios
+type PaymentRequestHandler struct {
+	paymentRequestService *db.PaymentRequestService
+	largeTransfers        *LargeTransferGuard
+}
+
+// NewPaymentRequestHandler crea una nueva instancia del handler de solicitudes de pago
+func NewPaymentRequestHandler(paymentRequestService *db.PaymentRequestService, largeTransfers *LargeTransferGuard) *PaymentRequestHandler {
+	return &PaymentRequestHandler{
+		paymentRequestService: paymentRequestService,
+		largeTransfers:        largeTransfers,
+	}
+}
+
+// Create pide dinero a otro usuario en nombre del usuario autenticado
+func (h *PaymentRequestHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CreatePaymentRequestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+	req.RequesterUserID = userID
+
+	if req.PayerUserID == uuid.Nil {
+		problem.Write(w, http.StatusBadRequest, "Payer user ID is required", "", r.URL.Path, nil)
+		return
+	}
+
+	request, err := h.paymentRequestService.Create(r.Context(), &req)
+	if err != nil {
+		h.writeError(w, r, err, "Error creating payment request")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(request)
+}
+
+// List retorna las solicitudes de pago enviadas y recibidas por el usuario autenticado
+func (h *PaymentRequestHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	requests, err := h.paymentRequestService.List(r.Context(), userID)
+	if err != nil {
+		h.writeError(w, r, err, "Error listing payment requests")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(requests)
+}
+
+// Approve paga una solicitud dirigida al usuario autenticado. Como en POST /transfer, los montos
+// grandes requieren confirmación OTP.
+func (h *PaymentRequestHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	userID, requestID, ok := h.pathRequest(w, r)
+	if !ok {
+		return
+	}
+
+	request, err := h.paymentRequestService.GetForPayer(r.Context(), requestID, userID)
+	if err != nil {
+		h.writeError(w, r, err, "Error getting payment request")
+		return
+	}
+
+	if request.Status != models.PaymentRequestStatusPending {
+		h.writeError(w, r, db.ErrPaymentRequestNotPending, "Error approving payment request")
+		return
+	}
+
+	if !h.largeTransfers.Confirm(w, r, userID, uint64(request.AmountCents), RecipientUser(request.RequesterUserID)) {
+		return
+	}
+
+	request, err = h.paymentRequestService.Approve(r.Context(), requestID, userID)
+	if err != nil {
+		h.writeError(w, r, err, "Error approving payment request")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(request)
+}
+
+// Reject rechaza una solicitud dirigida al usuario autenticado
+func (h *PaymentRequestHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	userID, requestID, ok := h.pathRequest(w, r)
+	if !ok {
+		return
+	}
+
+	request, err := h.paymentRequestService.Reject(r.Context(), requestID, userID)
+	if err != nil {
+		h.writeError(w, r, err, "Error rejecting payment request")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(request)
+}
+
+// currentUser obtiene el usuario autenticado para las rutas que no llevan el usuario en la URL
+func currentUser(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		problem.Write(w, http.StatusUnauthorized, "Unauthorized", "", r.URL.Path, nil)
+		return uuid.Nil, false
+	}
+	return claims.UserID, true
+}
+
+// pathRequest obtiene el usuario autenticado y el ID de la solicitud de la ruta
+func (h *PaymentRequestHandler) pathRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := currentUser(w, r)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	requestID, err := uuid.Parse(mux.Vars(r)["paymentRequestId"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid payment request ID", "", r.URL.Path, nil)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, requestID, true
+}
+
+// writeError responde el error de una operación sobre solicitudes de pago, incluidos los
+// rechazos de la transferencia al aprobar
+func (h *PaymentRequestHandler) writeError(w http.ResponseWriter, r *http.Request, err error, title string) {
+	if writeTransferError(w, r, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, db.ErrSelfPaymentRequest):
+		problem.Write(w, http.StatusBadRequest, "Cannot request payment from yourself", "", r.URL.Path, nil)
+	case errors.Is(err, db.ErrInvalidPaymentRequest):
+		problem.Write(w, http.StatusBadRequest, "Invalid payment request", "Amount must be greater than 0 and the description at most 140 characters", r.URL.Path, nil)
+	case errors.Is(err, db.ErrRecipientNotFound):
+		problem.Write(w, http.StatusNotFound, "Payer not found", "", r.URL.Path, nil)
+	case errors.Is(err, db.ErrRecipientInactive):
+		problem.Write(w, http.StatusUnprocessableEntity, "Payer is inactive", "", r.URL.Path, nil)
+	case errors.Is(err, db.ErrPaymentRequestNotFound):
+		problem.Write(w, http.StatusNotFound, "Payment request not found", "", r.URL.Path, nil)
+	case errors.Is(err, db.ErrPaymentRequestNotPending):
+		problem.Write(w, http.StatusConflict, "Payment request is no longer pending", "", r.URL.Path, nil)
+	case errors.Is(err, db.ErrPaymentRequestExpired):
+		problem.Write(w, http.StatusConflict, "Payment request has expired", "", r.URL.Path, nil)
+	default:
+		middleware.Logger(r.Context()).Error(title, zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, title, "", r.URL.Path, nil)
+	}
+}
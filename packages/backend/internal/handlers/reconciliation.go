@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/middleware"
+	"banca-en-linea/backend/internal/problem"
+)
+
+// ReconciliationHandler maneja la conciliación de balances entre TigerBeetle y PostgreSQL
+type ReconciliationHandler struct {
+	reconciliationService *db.ReconciliationService
+}
+
+// NewReconciliationHandler crea una nueva instancia del handler de conciliación
+func NewReconciliationHandler(reconciliationService *db.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{reconciliationService: reconciliationService}
+}
+
+// Reconcile ejecuta la conciliación y retorna las diferencias encontradas
+func (h *ReconciliationHandler) Reconcile(w http.ResponseWriter, r *http.Request) {
+	mismatches, err := h.reconciliationService.Reconcile(r.Context())
+	if err != nil {
+		middleware.Logger(r.Context()).Error("Error reconciling balances", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error reconciling balances", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"mismatches": mismatches,
+		"count":      len(mismatches),
+	})
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/middleware"
+	"banca-en-linea/backend/internal/problem"
+	"banca-en-linea/backend/models"
+)
+
+// ScheduledTransferHandler maneja las transferencias programadas, únicas o recurrentes, del usuario
+type ScheduledTransferHandler struct {
+	scheduledTransferService *db.ScheduledTransferService
+	largeTransfers           *LargeTransferGuard
+}
+
+// NewScheduledTransferHandler crea una nueva instancia del handler de transferencias programadas
+func NewScheduledTransferHandler(scheduledTransferService *db.ScheduledTransferService, largeTransfers *LargeTransferGuard) *ScheduledTransferHandler {
+	return &ScheduledTransferHandler{
+		scheduledTransferService: scheduledTransferService,
+		largeTransfers:           largeTransfers,
+	}
+}
+
+// Schedule programa una transferencia desde la cuenta del usuario. Las transferencias grandes se
+// confirman con OTP al programarlas, ya que el worker las ejecuta sin el usuario presente.
+func (h *ScheduledTransferHandler) Schedule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizedUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.ScheduleTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+	req.FromUserID = userID
+
+	if req.Amount == 0 {
+		problem.Write(w, http.StatusBadRequest, "Amount must be greater than 0", "", r.URL.Path, nil)
+		return
+	}
+
+	if req.FromUserID == req.ToUserID {
+		problem.Write(w, http.StatusBadRequest, "Cannot transfer to the same user", "", r.URL.Path, nil)
+		return
+	}
+
+	if !h.largeTransfers.Confirm(w, r, userID, req.Amount, RecipientUser(req.ToUserID)) {
+		return
+	}
+
+	transfer, err := h.scheduledTransferService.Schedule(r.Context(), &req)
+	if err != nil {
+		h.writeError(w, r, err, "Error scheduling transfer")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(transfer)
+}
+
+// List retorna las transferencias programadas por el usuario
+func (h *ScheduledTransferHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizedUser(w, r)
+	if !ok {
+		return
+	}
+
+	transfers, err := h.scheduledTransferService.ListScheduledTransfers(r.Context(), userID)
+	if err != nil {
+		h.writeError(w, r, err, "Error listing scheduled transfers")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transfers)
+}
+
+// Get retorna una transferencia programada del usuario
+func (h *ScheduledTransferHandler) Get(w http.ResponseWriter, r *http.Request) {
+	transfer, ok := h.ownedTransfer(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transfer)
+}
+
+// Update modifica el monto, la fecha o el fin de la recurrencia de una transferencia pendiente.
+// Un nuevo monto por encima del umbral exige confirmación OTP.
+func (h *ScheduledTransferHandler) Update(w http.ResponseWriter, r *http.Request) {
+	transfer, ok := h.ownedTransfer(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateScheduledTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	if req.Amount == nil && req.ScheduledAt == nil && req.RecurrenceEndDate == nil {
+		problem.Write(w, http.StatusBadRequest, "At least one field is required", "", r.URL.Path, nil)
+		return
+	}
+
+	if req.Amount != nil && *req.Amount == 0 {
+		problem.Write(w, http.StatusBadRequest, "Amount must be greater than 0", "", r.URL.Path, nil)
+		return
+	}
+
+	if req.Amount != nil && !h.largeTransfers.Confirm(w, r, transfer.FromUserID, *req.Amount, RecipientUser(transfer.ToUserID)) {
+		return
+	}
+
+	updated, err := h.scheduledTransferService.UpdateScheduledTransfer(r.Context(), transfer.ID, &req)
+	if err != nil {
+		h.writeError(w, r, err, "Error updating scheduled transfer")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// Cancel cancela una transferencia pendiente del usuario
+func (h *ScheduledTransferHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizedUser(w, r)
+	if !ok {
+		return
+	}
+
+	transferID, err := uuid.Parse(mux.Vars(r)["transferId"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid scheduled transfer ID", "", r.URL.Path, nil)
+		return
+	}
+
+	if err := h.scheduledTransferService.Cancel(r.Context(), transferID, userID); err != nil {
+		h.writeError(w, r, err, "Error cancelling scheduled transfer")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authorizedUser obtiene el usuario de la ruta y verifica que sea el usuario autenticado
+func authorizedUser(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid user ID", "", r.URL.Path, nil)
+		return uuid.Nil, false
+	}
+
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || claims.UserID != userID {
+		problem.Write(w, http.StatusForbidden, "Forbidden", "", r.URL.Path, nil)
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+// ownedTransfer obtiene la transferencia programada de la ruta y verifica que pertenezca al
+// usuario autenticado. Las transferencias de otros usuarios se reportan como inexistentes.
+func (h *ScheduledTransferHandler) ownedTransfer(w http.ResponseWriter, r *http.Request) (*models.ScheduledTransfer, bool) {
+	userID, ok := authorizedUser(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	transferID, err := uuid.Parse(mux.Vars(r)["transferId"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid scheduled transfer ID", "", r.URL.Path, nil)
+		return nil, false
+	}
+
+	transfer, err := h.scheduledTransferService.GetScheduledTransfer(r.Context(), transferID)
+	if err == nil && transfer.FromUserID != userID {
+		err = db.ErrScheduledTransferNotFound
+	}
+	if err != nil {
+		h.writeError(w, r, err, "Error getting scheduled transfer")
+		return nil, false
+	}
+
+	return transfer, true
+}
+
+// writeError responde el error de una operación sobre transferencias programadas
+func (h *ScheduledTransferHandler) writeError(w http.ResponseWriter, r *http.Request, err error, title string) {
+	switch {
+	case errors.Is(err, db.ErrScheduledInPast):
+		problem.Write(w, http.StatusBadRequest, "Scheduled time must be in the future", "", r.URL.Path, nil)
+	case errors.Is(err, db.ErrInvalidRecurrence):
+		problem.Write(w, http.StatusBadRequest, "Invalid recurrence", "", r.URL.Path, nil)
+	case errors.Is(err, db.ErrScheduledTransferNotFound):
+		problem.Write(w, http.StatusNotFound, "Scheduled transfer not found", "", r.URL.Path, nil)
+	case errors.Is(err, db.ErrScheduledTransferNotPending):
+		problem.Write(w, http.StatusConflict, "Scheduled transfer is no longer pending", "", r.URL.Path, nil)
+	default:
+		middleware.Logger(r.Context()).Error(title, zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, title, "", r.URL.Path, nil)
+	}
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/middleware"
+	"banca-en-linea/backend/internal/problem"
+	"banca-en-linea/backend/models"
+)
+
+// SplitTransferHandler maneja los pagos de gastos grupales divididos entre varios usuarios
+type SplitTransferHandler struct {
+	userService    *db.UserService
+	largeTransfers *LargeTransferGuard
+}
+
+// NewSplitTransferHandler crea una nueva instancia del handler de transferencias divididas
+func NewSplitTransferHandler(userService *db.UserService, largeTransfers *LargeTransferGuard) *SplitTransferHandler {
+	return &SplitTransferHandler{
+		userService:    userService,
+		largeTransfers: largeTransfers,
+	}
+}
+
+// splitFailureResponse describe una parte rechazada de una transferencia dividida
+type splitFailureResponse struct {
+	ToUserID uuid.UUID `json:"to_user_id"`
+	Error    string    `json:"error"`
+}
+
+// Split transfiere a varios usuarios desde la cuenta principal del usuario. Si solo algunas partes
+// fueron rechazadas responde 207 con el detalle de cada una; las demás ya se aplicaron.
+func (h *SplitTransferHandler) Split(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizedUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.SplitTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	// El código OTP de las transferencias grandes se exige sobre el total
+	var total uint64
+	for _, split := range req.Splits {
+		if total > math.MaxUint64-split.AmountCents {
+			h.writeError(w, r, db.ErrInvalidSplitTransfer)
+			return
+		}
+		total += split.AmountCents
+	}
+	if !h.largeTransfers.Confirm(w, r, userID, total, RecipientSplits(req.Splits)) {
+		return
+	}
+
+	ids, err := h.userService.SplitTransfer(r.Context(), userID, req.Splits, req.Description)
+
+	var splitErr *db.SplitTransferError
+	if errors.As(err, &splitErr) && len(splitErr.Failures) < len(req.Splits) {
+		failures := make([]splitFailureResponse, len(splitErr.Failures))
+		for i, failure := range splitErr.Failures {
+			failures[i] = splitFailureResponse{ToUserID: failure.ToUserID, Error: failure.Err.Error()}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMultiStatus)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":          "partial",
+			"transaction_ids": ids,
+			"failures":        failures,
+		})
+		return
+	}
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":          "success",
+		"amount":          total,
+		"transaction_ids": ids,
+	})
+}
+
+// writeError responde el rechazo de una transferencia dividida. Si todas las partes fueron
+// rechazadas responde el error de la primera.
+func (h *SplitTransferHandler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var splitErr *db.SplitTransferError
+	if errors.As(err, &splitErr) {
+		err = splitErr.Failures[0].Err
+	}
+
+	if writeTransferError(w, r, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, db.ErrInvalidSplitTransfer):
+		problem.Write(w, http.StatusBadRequest, "Invalid split transfer",
+			fmt.Sprintf("Provide 1 to %d distinct recipients with positive amounts and a description of at most %d characters",
+				models.MaxSplitTargets, models.MaxSplitDescriptionLength), r.URL.Path, nil)
+	case errors.Is(err, db.ErrSameAccount):
+		problem.Write(w, http.StatusBadRequest, "Cannot split a payment with yourself", "", r.URL.Path, nil)
+	case errors.Is(err, db.ErrRecipientNotFound):
+		problem.Write(w, http.StatusNotFound, "Recipient not found", err.Error(), r.URL.Path, nil)
+	case errors.Is(err, db.ErrRecipientInactive):
+		problem.Write(w, http.StatusUnprocessableEntity, "Recipient is inactive", err.Error(), r.URL.Path, nil)
+	default:
+		middleware.Logger(r.Context()).Error("Error processing split transfer", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error processing transfer", "", r.URL.Path, nil)
+	}
+}
@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/middleware"
+	"banca-en-linea/backend/internal/problem"
+	"banca-en-linea/backend/models"
+)
+
+// Tamaños de página del historial de transacciones
+const (
+	defaultTransactionPageSize = 20
+	maxTransactionPageSize     = 100
+)
+
+// TransactionHandler maneja las consultas de transacciones de los usuarios y sus reversiones
+type TransactionHandler struct {
+	userService        *db.UserService
+	transactionService *db.TransactionService
+}
+
+// NewTransactionHandler crea una nueva instancia del handler de transacciones
+func NewTransactionHandler(userService *db.UserService, transactionService *db.TransactionService) *TransactionHandler {
+	return &TransactionHandler{
+		userService:        userService,
+		transactionService: transactionService,
+	}
+}
+
+// ListTransactions retorna el historial de transacciones del usuario con paginación por cursor.
+// Acepta los parámetros opcionales "after" (cursor de la página anterior), "limit", "from" y "to"
+// (RFC3339), "type" y "min_amount"/"max_amount" (en centavos).
+func (h *TransactionHandler) ListTransactions(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid user ID", "", r.URL.Path, nil)
+		return
+	}
+
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || claims.UserID != userID {
+		problem.Write(w, http.StatusForbidden, "Forbidden", "", r.URL.Path, nil)
+		return
+	}
+
+	filter, err := parseTransactionFilter(r.URL.Query())
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "", err.Error(), r.URL.Path, nil)
+		return
+	}
+
+	page, err := h.userService.GetTransactionHistory(r.Context(), userID, filter)
+	if err != nil {
+		middleware.Logger(r.Context()).Error("Error getting transaction history", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error getting transaction history", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// parseTransactionFilter construye el filtro del historial a partir de los parámetros de la consulta
+func parseTransactionFilter(query url.Values) (models.TransactionFilter, error) {
+	filter := models.TransactionFilter{Limit: defaultTransactionPageSize}
+
+	if v := query.Get("after"); v != "" {
+		cursor, err := uuid.Parse(v)
+		if err != nil {
+			return filter, fmt.Errorf("Invalid cursor")
+		}
+		filter.Cursor = &cursor
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return filter, fmt.Errorf("Invalid limit")
+		}
+		filter.Limit = min(limit, maxTransactionPageSize)
+	}
+
+	if v := query.Get("from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, fmt.Errorf("Invalid 'from' date, expected RFC3339")
+		}
+		filter.FromDate = &from
+	}
+
+	if v := query.Get("to"); v != "" {
+		to, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, fmt.Errorf("Invalid 'to' date, expected RFC3339")
+		}
+		filter.ToDate = &to
+	}
+
+	if filter.FromDate != nil && filter.ToDate != nil && filter.FromDate.After(*filter.ToDate) {
+		return filter, fmt.Errorf("'from' must be before 'to'")
+	}
+
+	if v := query.Get("type"); v != "" {
+		switch v {
+		case models.TransactionTypeTransfer, models.TransactionTypeDeposit,
+			models.TransactionTypeWithdrawal, models.TransactionTypeBalanceCorrection, models.TransactionTypeReversal,
+			models.TransactionTypeFee:
+		default:
+			return filter, fmt.Errorf("Invalid transaction type")
+		}
+		filter.Type = &v
+	}
+
+	if v := query.Get("min_amount"); v != "" {
+		amount, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || amount < 0 {
+			return filter, fmt.Errorf("Invalid min_amount")
+		}
+		filter.MinAmount = &amount
+	}
+
+	if v := query.Get("max_amount"); v != "" {
+		amount, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || amount < 0 {
+			return filter, fmt.Errorf("Invalid max_amount")
+		}
+		filter.MaxAmount = &amount
+	}
+
+	if filter.MinAmount != nil && filter.MaxAmount != nil && *filter.MinAmount > *filter.MaxAmount {
+		return filter, fmt.Errorf("min_amount must not exceed max_amount")
+	}
+
+	return filter, nil
+}
+
+// SpendingByCategory retorna el gasto del usuario agrupado por categoría entre los parámetros
+// opcionales "from" y "to" (RFC3339). Por defecto cubre desde el inicio del mes actual hasta ahora.
+func (h *TransactionHandler) SpendingByCategory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizedUser(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	to := now
+
+	query := r.URL.Query()
+	if v := query.Get("from"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			problem.Write(w, http.StatusBadRequest, "", "Invalid 'from' date, expected RFC3339", r.URL.Path, nil)
+			return
+		}
+		from = parsed
+	}
+	if v := query.Get("to"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			problem.Write(w, http.StatusBadRequest, "", "Invalid 'to' date, expected RFC3339", r.URL.Path, nil)
+			return
+		}
+		to = parsed
+	}
+	if from.After(to) {
+		problem.Write(w, http.StatusBadRequest, "", "'from' must be before 'to'", r.URL.Path, nil)
+		return
+	}
+
+	breakdown, err := h.userService.GetSpendingByCategory(r.Context(), userID, from, to)
+	if err != nil {
+		middleware.Logger(r.Context()).Error("Error getting spending by category", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error getting spending by category", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(breakdown)
+}
+
+// UpdateCategory cambia la categoría de una transacción del usuario
+func (h *TransactionHandler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizedUser(w, r)
+	if !ok {
+		return
+	}
+
+	txID, err := uuid.Parse(mux.Vars(r)["transactionId"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid transaction ID", "", r.URL.Path, nil)
+		return
+	}
+
+	var req models.UpdateTransactionCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	err = h.userService.UpdateTransactionCategory(r.Context(), userID, txID, req.Category)
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, db.ErrInvalidCategory):
+		problem.Write(w, http.StatusBadRequest, "Invalid category", err.Error(), r.URL.Path, nil)
+	case errors.Is(err, db.ErrTransactionNotFound):
+		problem.Write(w, http.StatusNotFound, "Transaction not found", "", r.URL.Path, nil)
+	default:
+		middleware.Logger(r.Context()).Error("Error updating transaction category", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error updating transaction category", "", r.URL.Path, nil)
+	}
+}
+
+// Reverse revierte una transferencia enviada por el usuario, con una disputa aprobada y dentro del
+// plazo de reversión, y retorna la transacción de reversión
+func (h *TransactionHandler) Reverse(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizedUser(w, r)
+	if !ok {
+		return
+	}
+
+	txID, err := uuid.Parse(mux.Vars(r)["transactionId"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid transaction ID", "", r.URL.Path, nil)
+		return
+	}
+
+	reversal, err := h.transactionService.Reverse(r.Context(), txID, userID)
+	if err != nil {
+		if writeTransferError(w, r, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, db.ErrTransactionNotFound):
+			problem.Write(w, http.StatusNotFound, "Transaction not found", "", r.URL.Path, nil)
+		case errors.Is(err, db.ErrTransactionNotReversible):
+			problem.Write(w, http.StatusUnprocessableEntity, "Transaction cannot be reversed", "Only completed transfers can be reversed", r.URL.Path, nil)
+		case errors.Is(err, db.ErrReversalWindowExpired):
+			problem.Write(w, http.StatusUnprocessableEntity, "Reversal window expired", "The transfer is too old to be reversed", r.URL.Path, nil)
+		case errors.Is(err, db.ErrReversalNotApproved):
+			problem.Write(w, http.StatusForbidden, "Reversal not approved", "Open a dispute on the transfer and wait for compliance to approve it", r.URL.Path, nil)
+		case errors.Is(err, db.ErrTransactionAlreadyReversed):
+			problem.Write(w, http.StatusConflict, "Transaction already reversed", "", r.URL.Path, nil)
+		default:
+			middleware.Logger(r.Context()).Error("Error reversing transaction", zap.Error(err))
+			problem.Write(w, http.StatusInternalServerError, "Error reversing transaction", "", r.URL.Path, nil)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(reversal)
+}
+
+// SpendingSummary retorna el resumen de movimientos del usuario entre los parámetros opcionales
+// "from" y "to" (fechas YYYY-MM-DD en UTC, ambas incluidas). Por defecto cubre desde el primer día
+// del mes actual hasta hoy.
+func (h *TransactionHandler) SpendingSummary(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizedUser(w, r)
+	if !ok {
+		return
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	from := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := today
+
+	query := r.URL.Query()
+	if v := query.Get("from"); v != "" {
+		parsed, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			problem.Write(w, http.StatusBadRequest, "", "Invalid 'from' date, expected YYYY-MM-DD", r.URL.Path, nil)
+			return
+		}
+		from = parsed
+	}
+	if v := query.Get("to"); v != "" {
+		parsed, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			problem.Write(w, http.StatusBadRequest, "", "Invalid 'to' date, expected YYYY-MM-DD", r.URL.Path, nil)
+			return
+		}
+		to = parsed
+	}
+	if from.After(to) {
+		problem.Write(w, http.StatusBadRequest, "", "'from' must be before 'to'", r.URL.Path, nil)
+		return
+	}
+
+	// "to" incluye el día completo
+	summary, err := h.userService.GetSpendingSummary(r.Context(), userID, from, to.AddDate(0, 0, 1).Add(-time.Nanosecond))
+	if err != nil {
+		middleware.Logger(r.Context()).Error("Error getting spending summary", zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, "Error getting spending summary", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
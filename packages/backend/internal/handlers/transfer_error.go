@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"banca-en-linea/backend/internal/compliance"
+	"banca-en-linea/backend/internal/db"
+	apperrors "banca-en-linea/backend/internal/errors"
+	"banca-en-linea/backend/internal/fraud"
+	"banca-en-linea/backend/internal/problem"
+	"banca-en-linea/backend/internal/tigerbeetle"
+)
+
+// writeTransferError responde los rechazos de TransferBetweenUsers con el mismo problem type que
+// POST /transfer. Retorna false si el error no es un rechazo de la transferencia.
+func writeTransferError(w http.ResponseWriter, r *http.Request, err error) bool {
+	var fundsErr *db.InsufficientFundsError
+	var limitErr *db.DailyLimitExceededError
+
+	switch {
+	case errors.Is(err, apperrors.ErrAccountFrozen):
+		problem.WriteType(w, problem.ErrAccountFrozen, "", r.URL.Path, nil)
+	case errors.Is(err, apperrors.ErrNoTigerBeetleAccount):
+		problem.WriteType(w, problem.ErrNoLedgerAccount, "", r.URL.Path, nil)
+	case errors.Is(err, fraud.ErrVelocityExceeded):
+		problem.WriteType(w, problem.ErrVelocityExceeded, "", r.URL.Path, nil)
+	case errors.Is(err, compliance.ErrBlocked):
+		problem.WriteType(w, problem.ErrComplianceBlocked, "", r.URL.Path, nil)
+	case errors.Is(err, compliance.ErrKYCRequired):
+		problem.WriteType(w, problem.ErrKYCRequired, "Complete identity verification to move this amount", r.URL.Path, nil)
+	case errors.As(err, &fundsErr):
+		problem.WriteType(w, problem.ErrInsufficientFunds, fundsErr.Error(), r.URL.Path, map[string]any{
+			"error_code": "INSUFFICIENT_FUNDS",
+			"available":  fundsErr.Actual,
+			"requested":  fundsErr.Expected,
+		})
+	case errors.Is(err, db.ErrBelowMinimumBalance):
+		problem.WriteType(w, problem.ErrBelowMinimumBalance, err.Error(), r.URL.Path, nil)
+	case errors.As(err, &limitErr):
+		problem.WriteType(w, problem.ErrDailyLimitExceeded, limitErr.Error(), r.URL.Path, map[string]any{
+			"error_code": "DAILY_LIMIT_EXCEEDED",
+			"limit":      limitErr.Limit,
+			"used":       limitErr.Used,
+			"requested":  limitErr.Requested,
+		})
+	case errors.Is(err, tigerbeetle.ErrTransferMismatch):
+		problem.WriteType(w, problem.ErrIdempotencyKeyReuse, "", r.URL.Path, nil)
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/middleware"
+	"banca-en-linea/backend/internal/problem"
+	"banca-en-linea/backend/models"
+)
+
+// TransferTemplateHandler maneja las plantillas de transferencias frecuentes del usuario
+type TransferTemplateHandler struct {
+	templateService *db.TransferTemplateService
+	largeTransfers  *LargeTransferGuard
+}
+
+// NewTransferTemplateHandler crea una nueva instancia del handler de plantillas de transferencia
+func NewTransferTemplateHandler(templateService *db.TransferTemplateService, largeTransfers *LargeTransferGuard) *TransferTemplateHandler {
+	return &TransferTemplateHandler{
+		templateService: templateService,
+		largeTransfers:  largeTransfers,
+	}
+}
+
+// List retorna las plantillas guardadas por el usuario
+func (h *TransferTemplateHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizedUser(w, r)
+	if !ok {
+		return
+	}
+
+	templates, err := h.templateService.List(r.Context(), userID)
+	if err != nil {
+		h.writeError(w, r, err, "Error listing transfer templates")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(templates)
+}
+
+// Create guarda una nueva plantilla del usuario
+func (h *TransferTemplateHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizedUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CreateTransferTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	if req.RecipientUserID == uuid.Nil {
+		problem.Write(w, http.StatusBadRequest, "Recipient user ID is required", "", r.URL.Path, nil)
+		return
+	}
+
+	template, err := h.templateService.Create(r.Context(), userID, &req)
+	if err != nil {
+		h.writeError(w, r, err, "Error creating transfer template")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(template)
+}
+
+// Delete elimina una plantilla del usuario
+func (h *TransferTemplateHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizedUser(w, r)
+	if !ok {
+		return
+	}
+
+	templateID, err := uuid.Parse(mux.Vars(r)["templateId"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid transfer template ID", "", r.URL.Path, nil)
+		return
+	}
+
+	if err := h.templateService.Delete(r.Context(), userID, templateID); err != nil {
+		h.writeError(w, r, err, "Error deleting transfer template")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Execute realiza la transferencia guardada en una plantilla del usuario. Como en POST /transfer,
+// los montos grandes requieren confirmación OTP.
+func (h *TransferTemplateHandler) Execute(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizedUser(w, r)
+	if !ok {
+		return
+	}
+
+	templateID, err := uuid.Parse(mux.Vars(r)["templateId"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid transfer template ID", "", r.URL.Path, nil)
+		return
+	}
+
+	template, err := h.templateService.Get(r.Context(), userID, templateID)
+	if err != nil {
+		h.writeError(w, r, err, "Error getting transfer template")
+		return
+	}
+
+	if !h.largeTransfers.Confirm(w, r, userID, uint64(template.AmountCents), RecipientUser(template.RecipientUserID)) {
+		return
+	}
+
+	transferFee, err := h.templateService.Execute(r.Context(), template)
+	if err != nil {
+		h.writeError(w, r, err, "Error processing transfer")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"amount": template.AmountCents,
+		"fee":    transferFee,
+	})
+}
+
+// writeError responde el error de una operación sobre plantillas, incluidos los rechazos de la
+// transferencia al ejecutarlas
+func (h *TransferTemplateHandler) writeError(w http.ResponseWriter, r *http.Request, err error, title string) {
+	if writeTransferError(w, r, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, db.ErrSelfTransferTemplate):
+		problem.Write(w, http.StatusBadRequest, "Cannot create a transfer template to yourself", "", r.URL.Path, nil)
+	case errors.Is(err, db.ErrInvalidTransferTemplate):
+		problem.Write(w, http.StatusBadRequest, "Invalid transfer template", "Name is required and must be at most 100 characters; amount must be greater than 0", r.URL.Path, nil)
+	case errors.Is(err, db.ErrRecipientNotFound):
+		problem.Write(w, http.StatusNotFound, "Recipient not found", "", r.URL.Path, nil)
+	case errors.Is(err, db.ErrRecipientInactive):
+		problem.Write(w, http.StatusUnprocessableEntity, "Recipient is inactive", "", r.URL.Path, nil)
+	case errors.Is(err, db.ErrTransferTemplateExists):
+		problem.Write(w, http.StatusConflict, "Transfer template with this name already exists", "", r.URL.Path, nil)
+	case errors.Is(err, db.ErrTransferTemplateNotFound):
+		problem.Write(w, http.StatusNotFound, "Transfer template not found", "", r.URL.Path, nil)
+	default:
+		middleware.Logger(r.Context()).Error(title, zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, title, "", r.URL.Path, nil)
+	}
+}
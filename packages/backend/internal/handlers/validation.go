@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"banca-en-linea/backend/internal/problem"
+	"banca-en-linea/backend/internal/validation"
+)
+
+// FieldViolation describe un campo de la solicitud que no cumple sus reglas de validación
+type FieldViolation struct {
+	Field     string `json:"field"`
+	Violation string `json:"violation"`
+}
+
+// newValidator crea un validador que reporta los campos con su nombre JSON
+func newValidator() *validator.Validate {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	// phone acepta teléfonos E.164, ignorando espacios y guiones
+	validate.RegisterValidation("phone", func(fl validator.FieldLevel) bool {
+		return validation.ValidatePhone(fl.Field().String()) == nil
+	})
+	return validate
+}
+
+// writeValidationError responde un problema 400 cuya extensión "fields" lista los campos
+// inválidos y la regla que incumple cada uno
+func writeValidationError(w http.ResponseWriter, r *http.Request, err error) {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		problem.Write(w, http.StatusBadRequest, "Invalid request", "", r.URL.Path, nil)
+		return
+	}
+
+	fields := make([]FieldViolation, 0, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		violation := fieldErr.Tag()
+		if fieldErr.Param() != "" {
+			violation += "=" + fieldErr.Param()
+		}
+		fields = append(fields, FieldViolation{
+			Field:     fieldErr.Field(),
+			Violation: violation,
+		})
+	}
+
+	problem.Write(w, http.StatusBadRequest, "Validation failed", "", r.URL.Path, map[string]any{"fields": fields})
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/middleware"
+	"banca-en-linea/backend/internal/problem"
+	"banca-en-linea/backend/models"
+)
+
+// WebhookHandler maneja las suscripciones de webhooks del usuario
+type WebhookHandler struct {
+	webhookService *db.WebhookService
+}
+
+// NewWebhookHandler crea una nueva instancia del handler de webhooks
+func NewWebhookHandler(webhookService *db.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// List retorna los webhooks del usuario
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizedUser(w, r)
+	if !ok {
+		return
+	}
+
+	webhooks, err := h.webhookService.List(r.Context(), userID)
+	if err != nil {
+		h.writeError(w, r, err, "Error listing webhooks")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhooks)
+}
+
+// Create suscribe una URL a los eventos de transacción del usuario. El secreto de firma solo
+// se incluye en esta respuesta.
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizedUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	webhook, err := h.webhookService.Create(r.Context(), userID, &req)
+	if err != nil {
+		h.writeError(w, r, err, "Error creating webhook")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.CreateWebhookResponse{Webhook: webhook, Secret: webhook.Secret})
+}
+
+// Delete elimina un webhook del usuario
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := authorizedUser(w, r)
+	if !ok {
+		return
+	}
+
+	webhookID, err := uuid.Parse(mux.Vars(r)["webhookId"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid webhook ID", "", r.URL.Path, nil)
+		return
+	}
+
+	if err := h.webhookService.Delete(r.Context(), userID, webhookID); err != nil {
+		h.writeError(w, r, err, "Error deleting webhook")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeError responde el error de una operación sobre webhooks
+func (h *WebhookHandler) writeError(w http.ResponseWriter, r *http.Request, err error, title string) {
+	switch {
+	case errors.Is(err, db.ErrInvalidWebhookURL), errors.Is(err, db.ErrWebhookAddressNotAllowed):
+		problem.Write(w, http.StatusBadRequest, "Invalid webhook URL", err.Error(), r.URL.Path, nil)
+	case errors.Is(err, db.ErrInvalidWebhookEvents):
+		problem.Write(w, http.StatusBadRequest, "Invalid webhook events", err.Error(), r.URL.Path, nil)
+	case errors.Is(err, db.ErrWebhookNotFound):
+		problem.Write(w, http.StatusNotFound, "Webhook not found", "", r.URL.Path, nil)
+	default:
+		middleware.Logger(r.Context()).Error(title, zap.Error(err))
+		problem.Write(w, http.StatusInternalServerError, title, "", r.URL.Path, nil)
+	}
+}
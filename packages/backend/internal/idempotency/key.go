@@ -0,0 +1,52 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+
+	"github.com/google/uuid"
+)
+
+// HeaderName es el header con el que el cliente identifica una operación reintentable
+const HeaderName = "X-Idempotency-Key"
+
+// transferIDFlag marca los IDs derivados de una llave de idempotencia. Los IDs asignados por
+// la secuencia de transferencias nunca llegan a este bit, por lo que ambos rangos no se cruzan.
+const transferIDFlag = uint64(1) << 63
+
+type contextKey struct{}
+
+// Key identifica una operación reintentable: el usuario que la solicita y el hash de su llave.
+// Dos usuarios pueden enviar la misma llave sin que sus operaciones se confundan.
+type Key struct {
+	UserID uuid.UUID
+	Hash   string
+}
+
+// HashKey retorna el SHA-256 en hexadecimal del valor del header
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// WithKey agrega al contexto la llave de idempotencia del usuario que solicita la operación
+func WithKey(ctx context.Context, userID uuid.UUID, keyHash string) context.Context {
+	return context.WithValue(ctx, contextKey{}, Key{UserID: userID, Hash: keyHash})
+}
+
+// KeyFromContext obtiene la llave de idempotencia del contexto, si existe
+func KeyFromContext(ctx context.Context) (Key, bool) {
+	key, ok := ctx.Value(contextKey{}).(Key)
+	return key, ok && key.Hash != ""
+}
+
+// TransferID deriva un ID de transferencia de TigerBeetle determinista a partir del usuario y
+// el hash de su llave. purpose distingue las transferencias de una misma operación (por ejemplo
+// el principal y la comisión). Reintentar la operación produce el mismo ID, de modo que
+// TigerBeetle rechaza el duplicado.
+func (k Key) TransferID(purpose string) uint64 {
+	sum := sha256.Sum256([]byte(k.UserID.String() + ":" + k.Hash + ":" + purpose))
+	return binary.BigEndian.Uint64(sum[:8]) | transferIDFlag
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"banca-en-linea/backend/models"
+)
+
+const (
+	// activityLogTimeout es el tiempo máximo para registrar la actividad de una solicitud
+	activityLogTimeout = 2 * time.Second
+	// maxUserAgentLength es la longitud máxima del user-agent que se guarda
+	maxUserAgentLength = 512
+)
+
+// ActivityLogRepository registra la actividad de los usuarios (implementado por db.ActivityLogRepository)
+type ActivityLogRepository interface {
+	Record(ctx context.Context, entry *models.ActivityLog) error
+}
+
+// ActivityLogger crea un middleware que registra en repo cada solicitud de un usuario
+// autenticado, con su IP y user-agent. Debe aplicarse después de AuthMiddleware; las sondas de
+// salud y las métricas no se registran. Un error al registrar se loguea sin afectar la respuesta.
+func ActivityLogger(repo ActivityLogRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+
+			claims, ok := GetUserFromContext(r.Context())
+			if !ok || skipActivityLog(r.URL.Path) {
+				return
+			}
+
+			userAgent := r.UserAgent()
+			if len(userAgent) > maxUserAgentLength {
+				userAgent = userAgent[:maxUserAgentLength]
+			}
+
+			entry := &models.ActivityLog{
+				UserID:    claims.UserID,
+				Action:    routePath(r),
+				Endpoint:  r.URL.Path,
+				Method:    r.Method,
+				IPAddress: ClientIP(r),
+				UserAgent: userAgent,
+			}
+
+			// El contexto de la solicitud puede estar cancelado o vencido al terminar el handler
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), activityLogTimeout)
+			defer cancel()
+
+			if err := repo.Record(ctx, entry); err != nil {
+				Logger(r.Context()).Error("Error recording user activity", zap.Error(err))
+			}
+		})
+	}
+}
+
+// skipActivityLog indica si la ruta es una sonda de salud o de métricas, que no se registran
+func skipActivityLog(path string) bool {
+	return strings.HasSuffix(path, "/health") ||
+		strings.HasPrefix(path, "/healthz/") ||
+		strings.HasSuffix(path, "/metrics")
+}
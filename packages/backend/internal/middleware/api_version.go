@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// APIVersionHeader es el header de respuesta con la versión de la API que atendió la solicitud
+const APIVersionHeader = "X-API-Version"
+
+// NewVersionedRouter crea el subrouter de una versión de la API bajo /api/v{N}, por ejemplo
+// NewVersionedRouter(router, "2") atiende /api/v2/. Todas las respuestas del subrouter incluyen
+// X-API-Version para que los clientes detecten cuándo están llamando una versión anterior.
+//
+// Las rutas bajo /api/ sin prefijo de versión están obsoletas: toda ruta nueva se registra en
+// un subrouter versionado y los cambios incompatibles se publican en una versión nueva.
+func NewVersionedRouter(base *mux.Router, version string) *mux.Router {
+	version = "v" + strings.TrimPrefix(version, "v")
+
+	router := base.PathPrefix("/api/" + version).Subrouter()
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(APIVersionHeader, version)
+			next.ServeHTTP(w, r)
+		})
+	})
+	return router
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"log"
 	"net/http"
 	"strings"
 
@@ -50,6 +51,18 @@ func AuthMiddleware(authService *auth.Service) func(http.Handler) http.Handler {
 				return
 			}
 
+			// Verificar que el token no haya sido revocado (logout)
+			revoked, err := authService.IsTokenRevoked(claims)
+			if err != nil {
+				log.Printf("Error checking token blacklist: %v", err)
+				http.Error(w, "Token validation failed", http.StatusUnauthorized)
+				return
+			}
+			if revoked {
+				http.Error(w, "Token revoked", http.StatusUnauthorized)
+				return
+			}
+
 			// Agregar la información del usuario al contexto
 			ctx := context.WithValue(r.Context(), UserContextKey, claims)
 			r = r.WithContext(ctx)
@@ -60,6 +73,16 @@ func AuthMiddleware(authService *auth.Service) func(http.Handler) http.Handler {
 	}
 }
 
+// isRevoked indica si el token fue revocado; ante un error se considera revocado
+func isRevoked(authService *auth.Service, claims *auth.Claims) bool {
+	revoked, err := authService.IsTokenRevoked(claims)
+	if err != nil {
+		log.Printf("Error checking token blacklist: %v", err)
+		return true
+	}
+	return revoked
+}
+
 // GetUserFromContext extrae la información del usuario del contexto
 func GetUserFromContext(ctx context.Context) (*auth.Claims, bool) {
 	claims, ok := ctx.Value(UserContextKey).(*auth.Claims)
@@ -82,7 +105,7 @@ func OptionalAuthMiddleware(authService *auth.Service) func(http.Handler) http.H
 			parts := strings.SplitN(authHeader, " ", 2)
 			if len(parts) == 2 && parts[0] == "Bearer" {
 				token := parts[1]
-				if claims, err := authService.ValidateToken(token); err == nil {
+				if claims, err := authService.ValidateToken(token); err == nil && !isRevoked(authService, claims) {
 					// Token válido, agregar al contexto
 					ctx := context.WithValue(r.Context(), UserContextKey, claims)
 					r = r.WithContext(ctx)
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/time/rate"
+)
+
+// AuthenticatedRateLimiter maneja el rate limiting por usuario autenticado. Complementa al
+// RateLimiter por IP: usuarios detrás de un mismo NAT no comparten límite y un usuario
+// puede ser limitado individualmente.
+type AuthenticatedRateLimiter struct {
+	users map[uuid.UUID]*rate.Limiter
+	mu    sync.Mutex
+	rate  rate.Limit
+	burst int
+}
+
+// NewAuthenticatedRateLimiter crea un nuevo rate limiter por usuario
+func NewAuthenticatedRateLimiter(r rate.Limit, b int) *AuthenticatedRateLimiter {
+	return &AuthenticatedRateLimiter{
+		users: make(map[uuid.UUID]*rate.Limiter),
+		rate:  r,
+		burst: b,
+	}
+}
+
+// getUser obtiene o crea el rate limiter de un usuario
+func (rl *AuthenticatedRateLimiter) getUser(userID uuid.UUID) *rate.Limiter {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	limiter, exists := rl.users[userID]
+	if !exists {
+		limiter = rate.NewLimiter(rl.rate, rl.burst)
+		rl.users[userID] = limiter
+	}
+
+	return limiter
+}
+
+// cleanupUsers elimina los limiters que ya recuperaron todos sus tokens (usuarios inactivos)
+func (rl *AuthenticatedRateLimiter) cleanupUsers() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	for userID, limiter := range rl.users {
+		if limiter.Tokens() >= float64(rl.burst) {
+			delete(rl.users, userID)
+		}
+	}
+}
+
+// StartCleanup inicia la limpieza periódica de usuarios inactivos
+func (rl *AuthenticatedRateLimiter) StartCleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			rl.cleanupUsers()
+		}
+	}()
+}
+
+// Middleware retorna un middleware HTTP que aplica rate limiting por usuario.
+// Debe aplicarse después de AuthMiddleware.
+func (rl *AuthenticatedRateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := GetUserFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Authentication required", http.StatusUnauthorized)
+			return
+		}
+
+		if !rl.getUser(claims.UserID).Allow() {
+			http.Error(w, "Rate limit exceeded. Please try again later.", http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// CreateFinancialRateLimiter crea un rate limiter por usuario para los endpoints financieros
+// Permite 20 requests por minuto por usuario
+func CreateFinancialRateLimiter() *AuthenticatedRateLimiter {
+	rl := NewAuthenticatedRateLimiter(rate.Every(3*time.Second), 20) // 20 requests per minute
+	rl.StartCleanup(10 * time.Minute)                                // Limpiar cada 10 minutos
+	return rl
+}
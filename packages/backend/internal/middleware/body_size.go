@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"banca-en-linea/backend/internal/problem"
+)
+
+const (
+	// DefaultMaxBodySize es el tamaño máximo del cuerpo de las solicitudes JSON (64 KB)
+	DefaultMaxBodySize int64 = 64 << 10
+
+	// UploadMaxBodySize es el tamaño máximo del cuerpo en los endpoints de carga de archivos (1 MB)
+	UploadMaxBodySize int64 = 1 << 20
+)
+
+// MaxBodySize limita el cuerpo de la solicitud a maxBytes. Al superarse el límite la lectura
+// falla; los handlers deben responder con WriteDecodeError para retornar 413.
+func MaxBodySize(maxBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// IsBodyTooLarge indica si el error proviene de leer un cuerpo que supera el límite de MaxBodySize
+func IsBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr) || (err != nil && strings.Contains(err.Error(), "http: request body too large"))
+}
+
+// WriteDecodeError responde al error de decodificar el JSON de la solicitud: 413 si el cuerpo
+// supera el límite y 400 en cualquier otro caso
+func WriteDecodeError(w http.ResponseWriter, r *http.Request, err error) {
+	if IsBodyTooLarge(err) {
+		problem.Write(w, http.StatusRequestEntityTooLarge, "Request body too large", "", r.URL.Path, nil)
+		return
+	}
+	problem.Write(w, http.StatusBadRequest, "Invalid JSON", "", r.URL.Path, nil)
+}
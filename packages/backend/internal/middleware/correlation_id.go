@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// CorrelationIDHeader es el header con el que se recibe y se devuelve el ID de correlación
+	CorrelationIDHeader = "X-Correlation-ID"
+
+	// requestIDHeader es el header alternativo aceptado como ID de correlación
+	requestIDHeader = "X-Request-ID"
+
+	// maxCorrelationIDLength es el largo máximo aceptado para un ID de correlación recibido
+	maxCorrelationIDLength = 128
+)
+
+type correlationIDKey struct{}
+
+// CorrelationID lee el ID de correlación de X-Correlation-ID (o X-Request-ID), genera uno nuevo si
+// no viene o no es válido, lo agrega al contexto de la solicitud y lo devuelve en la respuesta
+func CorrelationID() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(CorrelationIDHeader)
+			if id == "" {
+				id = r.Header.Get(requestIDHeader)
+			}
+			if !isValidCorrelationID(id) {
+				id = uuid.New().String()
+			}
+
+			w.Header().Set(CorrelationIDHeader, id)
+			ctx := context.WithValue(r.Context(), correlationIDKey{}, id)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// isValidCorrelationID rechaza IDs vacíos, demasiado largos o con caracteres no imprimibles para
+// que un cliente no pueda inyectar líneas en los logs
+func isValidCorrelationID(id string) bool {
+	if id == "" || len(id) > maxCorrelationIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// CorrelationIDFromContext obtiene el ID de correlación del contexto, o "" si no existe
+func CorrelationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// Logger retorna el logger global de zap con el ID de correlación de la solicitud, para agrupar
+// los logs de una misma solicitud
+func Logger(ctx context.Context) *zap.Logger {
+	return withCorrelationID(ctx, zap.L())
+}
+
+// withCorrelationID agrega al logger el ID de correlación presente en ctx, si existe
+func withCorrelationID(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	if id := CorrelationIDFromContext(ctx); id != "" {
+		return logger.With(zap.String("correlation_id", id))
+	}
+	return logger
+}
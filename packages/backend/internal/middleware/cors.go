@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// CORSConfig define qué orígenes del navegador pueden consumir la API y con qué métodos y headers
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+}
+
+// DefaultCORSConfig retorna la política usada en desarrollo: los servidores locales del frontend
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedOrigins: []string{
+			"http://localhost:3000",
+			"http://localhost:5173",
+			"http://localhost:5174",
+			"http://localhost:8082",
+			"http://127.0.0.1:3000",
+			"http://127.0.0.1:5173",
+			"http://127.0.0.1:5174",
+			"http://127.0.0.1:8082",
+		},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-Requested-With", "X-Idempotency-Key", CorrelationIDHeader, "X-Request-ID", "X-OTP-Challenge", "X-OTP-Code"},
+		AllowCredentials: true,
+	}
+}
+
+// NewCORSConfigFromEnv construye la política a partir de CORS_ORIGINS, CORS_METHODS, CORS_HEADERS
+// (listas separadas por comas) y CORS_ALLOW_CREDENTIALS. Las variables ausentes conservan el
+// valor de DefaultCORSConfig.
+func NewCORSConfigFromEnv() CORSConfig {
+	config := DefaultCORSConfig()
+
+	if origins := splitList(os.Getenv("CORS_ORIGINS")); len(origins) > 0 {
+		config.AllowedOrigins = origins
+	}
+	if methods := splitList(os.Getenv("CORS_METHODS")); len(methods) > 0 {
+		config.AllowedMethods = methods
+	}
+	if headers := splitList(os.Getenv("CORS_HEADERS")); len(headers) > 0 {
+		config.AllowedHeaders = headers
+	}
+	if v := os.Getenv("CORS_ALLOW_CREDENTIALS"); v != "" {
+		allow, err := strconv.ParseBool(v)
+		if err != nil {
+			log.Printf("Warning: invalid CORS_ALLOW_CREDENTIALS %q, using %t", v, config.AllowCredentials)
+		} else {
+			config.AllowCredentials = allow
+		}
+	}
+
+	return config
+}
+
+// splitList separa una lista de valores separados por comas descartando los vacíos
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// isOriginAllowed indica si el origen coincide exactamente con alguno de los permitidos
+func (c CORSConfig) isOriginAllowed(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	for _, allowed := range c.AllowedOrigins {
+		if origin == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// CORS aplica la política de orígenes cruzados. Solo los orígenes permitidos reciben
+// Access-Control-Allow-Origin (con el mismo origen de la solicitud, nunca "*"); para el resto el
+// header se omite y el navegador bloquea la respuesta. Las solicitudes OPTIONS se responden aquí.
+func CORS(config CORSConfig) func(http.Handler) http.Handler {
+	methods := strings.Join(config.AllowedMethods, ", ")
+	headers := strings.Join(config.AllowedHeaders, ", ")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// La respuesta depende del origen: las cachés intermedias no deben compartirla
+			w.Header().Set("Vary", "Origin")
+
+			origin := r.Header.Get("Origin")
+			if config.isOriginAllowed(origin) {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				if config.AllowCredentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
+			}
+
+			w.Header().Set("Access-Control-Allow-Methods", methods)
+			w.Header().Set("Access-Control-Allow-Headers", headers)
+			w.Header().Set("Access-Control-Expose-Headers", CorrelationIDHeader+", "+APIVersionHeader)
+			w.Header().Set("Access-Control-Max-Age", "86400") // 24 horas
+
+			if r.Method == http.MethodOptions {
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/models"
+)
+
+// UserGetter obtiene un usuario por su ID (implementado por db.UserService)
+type UserGetter interface {
+	GetUser(id uuid.UUID) (*models.User, error)
+}
+
+// RequireEmailVerified crea un middleware que solo permite el acceso a usuarios con el email
+// verificado. Debe aplicarse después de AuthMiddleware.
+func RequireEmailVerified(users UserGetter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := GetUserFromContext(r.Context())
+			if !ok {
+				http.Error(w, "Authentication required", http.StatusUnauthorized)
+				return
+			}
+
+			user, err := users.GetUser(claims.UserID)
+			if err != nil {
+				log.Printf("Error getting user: %v", err)
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+
+			if !user.EmailVerified {
+				http.Error(w, "Email verification required", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
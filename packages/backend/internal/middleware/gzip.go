@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// gzipMinSize es el tamaño mínimo en bytes de una respuesta para comprimirla; en respuestas más
+// pequeñas el encabezado de gzip ahorra poco o nada
+const gzipMinSize = 1024
+
+// gzipResponseWriter acumula la respuesta hasta saber si supera gzipMinSize. Si la supera la
+// envía comprimida; si no, la envía tal cual al terminar el handler.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	buffer      bytes.Buffer
+	gz          *gzip.Writer
+	passthrough bool
+}
+
+// WriteHeader captura el código de estado hasta decidir si se comprime
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// Write acumula el cuerpo hasta gzipMinSize y luego lo escribe comprimido
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.gz != nil {
+		return w.gz.Write(b)
+	}
+	if w.passthrough {
+		return w.ResponseWriter.Write(b)
+	}
+
+	w.buffer.Write(b)
+	if w.buffer.Len() < gzipMinSize {
+		return len(b), nil
+	}
+
+	// El handler ya codificó la respuesta: enviarla sin tocar
+	if w.Header().Get("Content-Encoding") != "" {
+		w.passthrough = true
+		w.ResponseWriter.WriteHeader(w.status)
+		if _, err := w.ResponseWriter.Write(w.buffer.Bytes()); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+
+	w.Header().Set("Content-Encoding", "gzip")
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.status)
+
+	w.gz = gzip.NewWriter(w.ResponseWriter)
+	if _, err := w.gz.Write(w.buffer.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// finish envía la respuesta pendiente: cierra el gzip o escribe sin comprimir lo acumulado
+func (w *gzipResponseWriter) finish() {
+	if w.gz != nil {
+		w.gz.Close()
+		return
+	}
+	if w.passthrough {
+		return
+	}
+
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.Write(w.buffer.Bytes())
+}
+
+// GzipCompression comprime con gzip las respuestas de al menos gzipMinSize bytes cuando el
+// cliente envía Accept-Encoding: gzip. Las respuestas se acumulan en memoria hasta ese tamaño,
+// así que no debe aplicarse a rutas que transmiten eventos (SSE).
+func GzipCompression() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if !acceptsGzip(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			gw := &gzipResponseWriter{ResponseWriter: w}
+			defer gw.finish()
+			next.ServeHTTP(gw, r)
+		})
+	}
+}
+
+// acceptsGzip indica si el header Accept-Encoding incluye gzip sin q=0
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(encoding, ";")
+		if !strings.EqualFold(strings.TrimSpace(name), "gzip") {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if weight, err := strconv.ParseFloat(q, 64); err == nil && weight == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
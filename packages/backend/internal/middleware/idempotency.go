@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/internal/idempotency"
+	"banca-en-linea/backend/models"
+)
+
+// IdempotencyTTL es el tiempo durante el cual se conserva la respuesta de una llave
+const IdempotencyTTL = 24 * time.Hour
+
+// IdempotencyStore guarda las respuestas asociadas a llaves de idempotencia
+// (implementado por db.IdempotencyKeyRepository)
+type IdempotencyStore interface {
+	Get(ctx context.Context, userID uuid.UUID, keyHash string) (*models.IdempotencyRecord, error)
+	Save(ctx context.Context, record *models.IdempotencyRecord) error
+}
+
+// Idempotency crea un middleware que, si la petición trae el header X-Idempotency-Key,
+// responde con la respuesta guardada para esa llave en lugar de ejecutar de nuevo la
+// operación. Las respuestas 5xx no se guardan para que el cliente pueda reintentar, ni las 202
+// y 401 de las operaciones que esperan o rechazaron una confirmación OTP: el cliente repite la
+// petición con la misma llave al presentar el código.
+// El hash de la llave se agrega al contexto para derivar los IDs de TigerBeetle.
+// Debe aplicarse después de AuthMiddleware.
+func Idempotency(store IdempotencyStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotency.HeaderName)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			claims, ok := GetUserFromContext(r.Context())
+			if !ok {
+				http.Error(w, "Authentication required", http.StatusUnauthorized)
+				return
+			}
+
+			keyHash := idempotency.HashKey(key)
+
+			stored, err := store.Get(r.Context(), claims.UserID, keyHash)
+			if err != nil {
+				log.Printf("Error getting idempotency key: %v", err)
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				return
+			}
+
+			if stored != nil {
+				if stored.ContentType != "" {
+					w.Header().Set("Content-Type", stored.ContentType)
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(stored.StatusCode)
+				w.Write(stored.ResponseBody)
+				return
+			}
+
+			buffered := &bufferedResponseWriter{ResponseWriter: w}
+			next.ServeHTTP(buffered, r.WithContext(idempotency.WithKey(r.Context(), claims.UserID, keyHash)))
+
+			if buffered.status == 0 {
+				buffered.status = http.StatusOK
+			}
+
+			if isReplayable(buffered.status) {
+				record := &models.IdempotencyRecord{
+					KeyHash:      keyHash,
+					UserID:       claims.UserID,
+					StatusCode:   buffered.status,
+					ContentType:  w.Header().Get("Content-Type"),
+					ResponseBody: buffered.body.Bytes(),
+					ExpiresAt:    time.Now().Add(IdempotencyTTL),
+				}
+				if err := store.Save(r.Context(), record); err != nil {
+					log.Printf("Error saving idempotency key: %v", err)
+				}
+			}
+
+			w.WriteHeader(buffered.status)
+			w.Write(buffered.body.Bytes())
+		})
+	}
+}
+
+// isReplayable indica si la respuesta con el código indicado se guarda para repetirse
+func isReplayable(status int) bool {
+	switch status {
+	case http.StatusAccepted, http.StatusUnauthorized:
+		return false
+	}
+	return status < http.StatusInternalServerError
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// unmatchedRoutePath es la etiqueta de ruta usada cuando la solicitud no corresponde a una ruta registrada
+const unmatchedRoutePath = "unmatched"
+
+// statusRecorder captura el código de estado escrito por el handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader captura el código de estado
+func (w *statusRecorder) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Flush permite que los handlers de streaming (SSE) sigan enviando datos a través del recorder
+func (w *statusRecorder) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap retorna el ResponseWriter original para http.ResponseController
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// PrometheusMiddleware registra en registry las métricas http_requests_total y
+// http_request_duration_seconds de cada solicitud. La ruta se etiqueta con la plantilla de
+// mux (por ejemplo /api/v1/users/{id}/accounts) para no crear una serie por cada ID.
+func PrometheusMiddleware(registry prometheus.Registerer) func(http.Handler) http.Handler {
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total de solicitudes HTTP por método, ruta y código de estado.",
+	}, []string{"method", "path", "status"})
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Duración de las solicitudes HTTP en segundos.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "path"})
+	registry.MustRegister(requests, duration)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(recorder, r)
+
+			path := routePath(r)
+			requests.WithLabelValues(r.Method, path, strconv.Itoa(recorder.status)).Inc()
+			duration.WithLabelValues(r.Method, path).Observe(time.Since(start).Seconds())
+		})
+	}
+}
+
+// routePath retorna la plantilla de la ruta de mux que atendió la solicitud
+func routePath(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return unmatchedRoutePath
+	}
+
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return unmatchedRoutePath
+	}
+	return template
+}
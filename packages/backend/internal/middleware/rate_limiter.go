@@ -65,8 +65,8 @@ func (rl *RateLimiter) StartCleanup(interval time.Duration) {
 	}()
 }
 
-// getIP extrae la IP real del cliente considerando proxies
-func getIP(r *http.Request) string {
+// ClientIP extrae la IP real del cliente considerando proxies
+func ClientIP(r *http.Request) string {
 	// Verificar headers de proxy
 	if ip := r.Header.Get("X-Forwarded-For"); ip != "" {
 		// X-Forwarded-For puede contener múltiples IPs, tomar la primera
@@ -91,7 +91,7 @@ func getIP(r *http.Request) string {
 // Middleware retorna un middleware HTTP que aplica rate limiting
 func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ip := getIP(r)
+		ip := ClientIP(r)
 		limiter := rl.getVisitor(ip)
 
 		if !limiter.Allow() {
@@ -110,3 +110,11 @@ func CreateAuthRateLimiter() *RateLimiter {
 	rl.StartCleanup(10 * time.Minute)                   // Limpiar cada 10 minutos
 	return rl
 }
+
+// CreateLookupRateLimiter crea un rate limiter estricto para la consulta pública de cuentas
+// Permite 3 requests por minuto por IP
+func CreateLookupRateLimiter() *RateLimiter {
+	rl := NewRateLimiter(rate.Every(20*time.Second), 3) // 3 requests per minute
+	rl.StartCleanup(10 * time.Minute)                   // Limpiar cada 10 minutos
+	return rl
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"runtime/debug"
+
+	"go.uber.org/zap"
+)
+
+// Recovery recupera los panics de los handlers, los registra con el stack trace y el ID de
+// correlación de la solicitud, y responde 500 con un error JSON en lugar de cortar la conexión
+func Recovery(logger *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				// http.ErrAbortHandler es la forma estándar de abortar una respuesta; no es un error
+				if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(recovered)
+				}
+
+				withCorrelationID(r.Context(), logger).Error("Panic recovered",
+					zap.Any("panic", recovered),
+					zap.String("method", r.Method),
+					zap.String("uri", r.RequestURI),
+					zap.ByteString("stack", debug.Stack()),
+				)
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(map[string]string{"error": "internal server error"})
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
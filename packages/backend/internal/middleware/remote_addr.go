@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"net/http"
+
+	"banca-en-linea/backend/internal/audit"
+)
+
+// RemoteAddr agrega la dirección del cliente (r.RemoteAddr) al contexto de la solicitud para
+// que los servicios la registren en la auditoría
+func RemoteAddr(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := audit.WithRemoteAddr(r.Context(), r.RemoteAddr)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
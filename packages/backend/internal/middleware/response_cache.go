@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// bufferedResponseWriter captura el status y el cuerpo de la respuesta antes de enviarla al cliente
+type bufferedResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader captura el código de estado
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+// Write acumula el cuerpo de la respuesta en memoria
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+// ResponseCache crea un middleware que agrega los headers Cache-Control y ETag a las
+// respuestas 200 de peticiones GET. Si el cliente envía If-None-Match con el ETag actual
+// se responde 304 sin cuerpo. No debe aplicarse a rutas de escritura.
+func ResponseCache(ttl time.Duration) func(http.Handler) http.Handler {
+	cacheControl := fmt.Sprintf("private, max-age=%d", int(ttl.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			buffered := &bufferedResponseWriter{ResponseWriter: w}
+			next.ServeHTTP(buffered, r)
+
+			if buffered.status == 0 {
+				buffered.status = http.StatusOK
+			}
+
+			// Solo se cachean respuestas exitosas
+			if buffered.status != http.StatusOK {
+				w.WriteHeader(buffered.status)
+				w.Write(buffered.body.Bytes())
+				return
+			}
+
+			sum := sha256.Sum256(buffered.body.Bytes())
+			etag := `"` + hex.EncodeToString(sum[:]) + `"`
+
+			w.Header().Set("Cache-Control", cacheControl)
+			w.Header().Set("ETag", etag)
+
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+			w.Write(buffered.body.Bytes())
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+)
+
+// RequireRole crea un middleware que solo permite el acceso si los claims del JWT incluyen
+// alguno de los roles indicados. Debe aplicarse después de AuthMiddleware.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := GetUserFromContext(r.Context())
+			if !ok {
+				http.Error(w, "Authentication required", http.StatusUnauthorized)
+				return
+			}
+
+			if !hasAnyRole(claims.Roles, roles) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// HasRole indica si el usuario autenticado del contexto tiene alguno de los roles indicados. Sirve
+// para los handlers que, además del propio usuario, permiten el acceso a ciertos roles.
+func HasRole(ctx context.Context, roles ...string) bool {
+	claims, ok := GetUserFromContext(ctx)
+	return ok && hasAnyRole(claims.Roles, roles)
+}
+
+// hasAnyRole indica si alguno de los roles del usuario está entre los requeridos
+func hasAnyRole(userRoles, required []string) bool {
+	for _, userRole := range userRoles {
+		for _, role := range required {
+			if userRole == role {
+				return true
+			}
+		}
+	}
+	return false
+}
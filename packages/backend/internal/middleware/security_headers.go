@@ -0,0 +1,29 @@
+package middleware
+
+import "net/http"
+
+// securityHeaders son los headers de seguridad que se agregan a todas las respuestas
+var securityHeaders = map[string]string{
+	// Usar siempre HTTPS durante un año, también en los subdominios
+	"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+	// No permitir que la API se muestre dentro de un frame (clickjacking)
+	"X-Frame-Options": "DENY",
+	// No dejar que el navegador adivine el tipo de contenido
+	"X-Content-Type-Options": "nosniff",
+	// La API solo responde JSON: no se permite cargar ningún recurso
+	"Content-Security-Policy": "default-src 'none'",
+	"Referrer-Policy":         "strict-origin-when-cross-origin",
+}
+
+// SecurityHeaders agrega a todas las respuestas los headers que protegen al frontend contra
+// clickjacking, downgrade a HTTP y detección de tipo de contenido
+func SecurityHeaders() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for name, value := range securityHeaders {
+				w.Header().Set(name, value)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
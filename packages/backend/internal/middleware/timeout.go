@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// timeoutMessage es el cuerpo de la respuesta cuando una solicitud supera su tiempo máximo
+const timeoutMessage = "Request timeout"
+
+// Timeout limita la duración de cada solicitud a d. Responde 503 mediante http.TimeoutHandler y
+// además cancela el contexto de la solicitud para que las consultas a PostgreSQL
+// (QueryContext, ExecContext) y demás operaciones que lo usan se detengan y liberen su goroutine.
+// No debe usarse en respuestas de streaming, ya que http.TimeoutHandler no soporta http.Flusher.
+func Timeout(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		timeoutHandler := http.TimeoutHandler(next, d, timeoutMessage)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+			timeoutHandler.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package mocks
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/models"
+)
+
+// Verificar en tiempo de compilación que el mock implementa la interfaz
+var _ db.UserRepository = (*MockUserRepository)(nil)
+
+// MockUserRepository es un mock de db.UserRepository para testing
+type MockUserRepository struct {
+	mock.Mock
+
+	ctxMu sync.Mutex
+	ctx   context.Context
+}
+
+// SetupContext captura el contexto recibido para que los tests puedan verificar su deadline
+func (m *MockUserRepository) SetupContext(ctx context.Context) {
+	m.ctxMu.Lock()
+	defer m.ctxMu.Unlock()
+	m.ctx = ctx
+}
+
+// CapturedContext retorna el último contexto recibido por el mock
+func (m *MockUserRepository) CapturedContext() context.Context {
+	m.ctxMu.Lock()
+	defer m.ctxMu.Unlock()
+	return m.ctx
+}
+
+func (m *MockUserRepository) Create(ctx context.Context, user *models.CreateUserRequest) (*models.User, error) {
+	m.SetupContext(ctx)
+	args := m.Called(ctx, user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) CreateWithAttributes(ctx context.Context, user *models.CreateUserRequest, roles []string, kycStatus string) (*models.User, error) {
+	m.SetupContext(ctx)
+	args := m.Called(ctx, user, roles, kycStatus)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	m.SetupContext(ctx)
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	m.SetupContext(ctx)
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) GetByEmailForUpdate(ctx context.Context, tx *sql.Tx, email string) (*models.User, error) {
+	m.SetupContext(ctx)
+	args := m.Called(ctx, tx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	m.SetupContext(ctx)
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*sql.Tx), args.Error(1)
+}
+
+func (m *MockUserRepository) Update(ctx context.Context, id uuid.UUID, updates *models.UpdateUserRequest) (*models.User, error) {
+	m.SetupContext(ctx)
+	args := m.Called(ctx, id, updates)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateFlags(ctx context.Context, id uuid.UUID, flags *models.UpdateUserFlagsRequest) (*models.User, error) {
+	m.SetupContext(ctx)
+	args := m.Called(ctx, id, flags)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateKYC(ctx context.Context, id uuid.UUID, status string, documentRef *string) (*models.User, error) {
+	m.SetupContext(ctx)
+	args := m.Called(ctx, id, status, documentRef)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	m.SetupContext(ctx)
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) HardDelete(ctx context.Context, id uuid.UUID) error {
+	m.SetupContext(ctx)
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) List(ctx context.Context, afterID *uuid.UUID, limit int) ([]*models.User, error) {
+	m.SetupContext(ctx)
+	args := m.Called(ctx, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Search(ctx context.Context, query string, limit int, afterID *uuid.UUID) ([]*models.User, error) {
+	m.SetupContext(ctx)
+	args := m.Called(ctx, query, limit, afterID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateTigerBeetleAccountID(ctx context.Context, userID uuid.UUID, accountID int64) error {
+	m.SetupContext(ctx)
+	args := m.Called(ctx, userID, accountID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) MarkEmailVerified(ctx context.Context, userID uuid.UUID) error {
+	m.SetupContext(ctx)
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Freeze(ctx context.Context, userID uuid.UUID, reason string) error {
+	m.SetupContext(ctx)
+	args := m.Called(ctx, userID, reason)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Unfreeze(ctx context.Context, userID uuid.UUID) error {
+	m.SetupContext(ctx)
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateLastLogin(ctx context.Context, userID uuid.UUID) error {
+	m.SetupContext(ctx)
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, hashedPassword string) error {
+	m.SetupContext(ctx)
+	args := m.Called(ctx, userID, hashedPassword)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdatePhone(ctx context.Context, userID uuid.UUID, phone string) error {
+	m.SetupContext(ctx)
+	args := m.Called(ctx, userID, phone)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateDateOfBirth(ctx context.Context, userID uuid.UUID, dateOfBirth time.Time) error {
+	m.SetupContext(ctx)
+	args := m.Called(ctx, userID, dateOfBirth)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) SetTOTPSecret(ctx context.Context, userID uuid.UUID, encryptedSecret string) error {
+	m.SetupContext(ctx)
+	args := m.Called(ctx, userID, encryptedSecret)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) EnableTOTP(ctx context.Context, userID uuid.UUID) error {
+	m.SetupContext(ctx)
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) VerifyPassword(hashedPassword, password string) error {
+	args := m.Called(hashedPassword, password)
+	return args.Error(0)
+}
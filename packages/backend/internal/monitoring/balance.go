@@ -0,0 +1,96 @@
+package monitoring
+
+import (
+	"log"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"banca-en-linea/backend/models"
+)
+
+// MaxBalanceStreamsPerUser es la cantidad máxima de streams de balance simultáneos por usuario
+const MaxBalanceStreamsPerUser = 5
+
+// BalanceBroker distribuye los cambios de balance a los streams abiertos de cada usuario.
+// Los canales se guardan por usuario en un sync.Map para que publicar a un usuario no bloquee
+// a los demás.
+type BalanceBroker struct {
+	users sync.Map // uuid.UUID -> *balanceSubscribers
+}
+
+// balanceSubscribers son los canales abiertos de un usuario
+type balanceSubscribers struct {
+	mu       sync.Mutex
+	channels map[chan models.BalanceChangedEvent]struct{}
+}
+
+// NewBalanceBroker crea una nueva instancia del distribuidor de balances
+func NewBalanceBroker() *BalanceBroker {
+	return &BalanceBroker{}
+}
+
+// Subscribe registra un stream de balances del usuario. La función retornada debe llamarse al
+// desconectarse.
+func (b *BalanceBroker) Subscribe(userID uuid.UUID) (<-chan models.BalanceChangedEvent, func(), error) {
+	value, _ := b.users.LoadOrStore(userID, &balanceSubscribers{
+		channels: make(map[chan models.BalanceChangedEvent]struct{}),
+	})
+	subs := value.(*balanceSubscribers)
+
+	subs.mu.Lock()
+	defer subs.mu.Unlock()
+
+	if len(subs.channels) >= MaxBalanceStreamsPerUser {
+		return nil, nil, ErrTooManySubscribers
+	}
+
+	ch := make(chan models.BalanceChangedEvent, subscriberBuffer)
+	subs.channels[ch] = struct{}{}
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			subs.mu.Lock()
+			defer subs.mu.Unlock()
+			delete(subs.channels, ch)
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe, nil
+}
+
+// HasSubscribers indica si el usuario tiene algún stream de balances abierto
+func (b *BalanceBroker) HasSubscribers(userID uuid.UUID) bool {
+	value, ok := b.users.Load(userID)
+	if !ok {
+		return false
+	}
+
+	subs := value.(*balanceSubscribers)
+	subs.mu.Lock()
+	defer subs.mu.Unlock()
+	return len(subs.channels) > 0
+}
+
+// PublishBalance envía un cambio de balance a los streams del usuario sin bloquear.
+// Si el buffer de un stream está lleno el evento se descarta para ese stream.
+func (b *BalanceBroker) PublishBalance(userID uuid.UUID, event models.BalanceChangedEvent) {
+	value, ok := b.users.Load(userID)
+	if !ok {
+		return
+	}
+
+	subs := value.(*balanceSubscribers)
+	subs.mu.Lock()
+	defer subs.mu.Unlock()
+
+	for ch := range subs.channels {
+		select {
+		case ch <- event:
+		default:
+			log.Printf("Balance stream of user %s is too slow, dropping event for account %d", userID, event.AccountID)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package monitoring
+
+import (
+	"errors"
+	"log"
+	"sync"
+
+	"banca-en-linea/backend/models"
+)
+
+const (
+	// MaxSubscribers es la cantidad máxima de conexiones SSE simultáneas
+	MaxSubscribers = 10
+
+	// subscriberBuffer es la cantidad de eventos que se encolan por suscriptor antes de descartarlos
+	subscriberBuffer = 64
+)
+
+// ErrTooManySubscribers indica que se alcanzó el límite de conexiones simultáneas
+var ErrTooManySubscribers = errors.New("too many subscribers")
+
+// MonitoringService distribuye los eventos de transacción a los suscriptores en tiempo real
+type MonitoringService struct {
+	mu             sync.Mutex
+	subscribers    map[chan models.TransactionEvent]struct{}
+	maxSubscribers int
+}
+
+// NewMonitoringService crea una nueva instancia del servicio de monitoreo
+func NewMonitoringService() *MonitoringService {
+	return &MonitoringService{
+		subscribers:    make(map[chan models.TransactionEvent]struct{}),
+		maxSubscribers: MaxSubscribers,
+	}
+}
+
+// Subscribe registra un nuevo suscriptor. La función retornada debe llamarse al desconectarse.
+func (m *MonitoringService) Subscribe() (<-chan models.TransactionEvent, func(), error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.subscribers) >= m.maxSubscribers {
+		return nil, nil, ErrTooManySubscribers
+	}
+
+	ch := make(chan models.TransactionEvent, subscriberBuffer)
+	m.subscribers[ch] = struct{}{}
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			delete(m.subscribers, ch)
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe, nil
+}
+
+// Publish envía un evento a todos los suscriptores sin bloquear.
+// Si el buffer de un suscriptor está lleno el evento se descarta para ese suscriptor.
+func (m *MonitoringService) Publish(event models.TransactionEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for ch := range m.subscribers {
+		select {
+		case ch <- event:
+		default:
+			log.Printf("Monitoring subscriber is too slow, dropping event %s", event.ID)
+		}
+	}
+}
+
+// SubscriberCount retorna la cantidad de suscriptores conectados
+func (m *MonitoringService) SubscriberCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.subscribers)
+}
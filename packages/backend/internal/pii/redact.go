@@ -0,0 +1,96 @@
+// Package pii oculta los datos personales y secretos de los cuerpos de las solicitudes antes de
+// escribirlos en los logs.
+package pii
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Placeholder es el valor que reemplaza a los datos ocultos
+const Placeholder = "[REDACTED]"
+
+// DefaultDenyList son las claves cuyos valores se ocultan por defecto
+var DefaultDenyList = []string{"password", "password_hash", "card_number", "cvv", "account_number"}
+
+// Redactor oculta los valores de las claves de una lista de denegación en documentos JSON
+type Redactor struct {
+	denied map[string]bool
+}
+
+// NewRedactor crea un Redactor que oculta los valores de las claves indicadas. La comparación no
+// distingue mayúsculas y también oculta las claves que terminan en "_" más una clave de la lista
+// (por ejemplo "new_password" o "confirmed_account_number").
+func NewRedactor(keys ...string) *Redactor {
+	denied := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		denied[strings.ToLower(key)] = true
+	}
+	return &Redactor{denied: denied}
+}
+
+// defaultRedactor es el Redactor con DefaultDenyList usado por Redact
+var defaultRedactor = NewRedactor(DefaultDenyList...)
+
+// Redact oculta en body los valores de las claves de DefaultDenyList
+func Redact(body []byte) []byte {
+	return defaultRedactor.Redact(body)
+}
+
+// Redact interpreta body como JSON y reemplaza por Placeholder los valores de las claves
+// denegadas, también dentro de objetos y arreglos anidados. Un cuerpo que no es JSON se
+// reemplaza completo por Placeholder, ya que no se puede saber qué contiene.
+func (r *Redactor) Redact(body []byte) []byte {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return body
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber() // Conservar los montos tal como llegaron
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return []byte(Placeholder)
+	}
+
+	redacted, err := json.Marshal(r.redactValue(doc))
+	if err != nil {
+		return []byte(Placeholder)
+	}
+	return redacted
+}
+
+// redactValue recorre un valor JSON decodificado ocultando las claves denegadas
+func (r *Redactor) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if r.denies(key) {
+				v[key] = Placeholder
+			} else {
+				v[key] = r.redactValue(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.redactValue(item)
+		}
+	}
+	return value
+}
+
+// denies indica si el valor de la clave debe ocultarse: la clave completa o alguno de sus sufijos
+// tras un "_" está en la lista
+func (r *Redactor) denies(key string) bool {
+	key = strings.ToLower(key)
+	for {
+		if r.denied[key] {
+			return true
+		}
+		i := strings.Index(key, "_")
+		if i < 0 {
+			return false
+		}
+		key = key[i+1:]
+	}
+}
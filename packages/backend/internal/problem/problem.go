@@ -0,0 +1,102 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ContentType es el media type de las respuestas de error según RFC 7807
+const ContentType = "application/problem+json"
+
+// blankType es el tipo de los problemas que no tienen una semántica propia más allá del código HTTP
+const blankType = "about:blank"
+
+// Type es la URI que identifica un tipo de problema del dominio. Implementa error para poder
+// usarse como constante de error.
+type Type string
+
+// Tipos de problema del dominio bancario
+const (
+	ErrInsufficientFunds   Type = "/problems/insufficient-funds"
+	ErrAccountFrozen       Type = "/problems/account-frozen"
+	ErrDailyLimitExceeded  Type = "/problems/daily-limit-exceeded"
+	ErrBelowMinimumBalance Type = "/problems/below-minimum-balance"
+	ErrVelocityExceeded    Type = "/problems/velocity-exceeded"
+	ErrComplianceBlocked   Type = "/problems/compliance-blocked"
+	ErrKYCRequired         Type = "/problems/kyc-required"
+	ErrNoLedgerAccount     Type = "/problems/no-ledger-account"
+	ErrIdempotencyKeyReuse Type = "/problems/idempotency-key-reuse"
+)
+
+// typeInfo es el código HTTP y el título de un tipo de problema
+type typeInfo struct {
+	status int
+	title  string
+}
+
+var types = map[Type]typeInfo{
+	ErrInsufficientFunds:   {status: http.StatusUnprocessableEntity, title: "Insufficient funds"},
+	ErrAccountFrozen:       {status: http.StatusForbidden, title: "Account is frozen"},
+	ErrDailyLimitExceeded:  {status: http.StatusUnprocessableEntity, title: "Daily limit exceeded"},
+	ErrBelowMinimumBalance: {status: http.StatusUnprocessableEntity, title: "Below minimum balance"},
+	ErrVelocityExceeded:    {status: http.StatusTooManyRequests, title: "Too many transactions in a short period"},
+	ErrComplianceBlocked:   {status: http.StatusForbidden, title: "Operation blocked by compliance review"},
+	ErrKYCRequired:         {status: http.StatusForbidden, title: "Identity verification required"},
+	ErrNoLedgerAccount:     {status: http.StatusConflict, title: "Account has no ledger account"},
+	ErrIdempotencyKeyReuse: {status: http.StatusUnprocessableEntity, title: "Idempotency key was used for a different operation"},
+}
+
+// Error retorna el título del tipo de problema
+func (t Type) Error() string {
+	return t.Title()
+}
+
+// Title retorna el título del tipo de problema
+func (t Type) Title() string {
+	return types[t].title
+}
+
+// Status retorna el código HTTP con el que se responde el tipo de problema
+func (t Type) Status() int {
+	if info, ok := types[t]; ok {
+		return info.status
+	}
+	return http.StatusInternalServerError
+}
+
+// Write responde un problema genérico (type "about:blank"). Si title está vacío se usa el texto
+// estándar del código HTTP. Los miembros de extensions se agregan al cuerpo junto a los estándar.
+func Write(w http.ResponseWriter, status int, title, detail, instance string, extensions map[string]any) {
+	write(w, blankType, status, title, detail, instance, extensions)
+}
+
+// WriteType responde un problema de un tipo del dominio con su código HTTP y título
+func WriteType(w http.ResponseWriter, problemType Type, detail, instance string, extensions map[string]any) {
+	write(w, string(problemType), problemType.Status(), problemType.Title(), detail, instance, extensions)
+}
+
+// write arma el cuerpo del problema; los miembros estándar tienen prioridad sobre las extensiones
+func write(w http.ResponseWriter, problemType string, status int, title, detail, instance string, extensions map[string]any) {
+	if title == "" {
+		title = http.StatusText(status)
+	}
+
+	body := make(map[string]any, len(extensions)+5)
+	for key, value := range extensions {
+		body[key] = value
+	}
+	body["type"] = problemType
+	body["title"] = title
+	body["status"] = status
+	if detail != "" {
+		body["detail"] = detail
+	}
+	if instance != "" {
+		body["instance"] = instance
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -9,10 +9,11 @@ type AccountWrapper struct {
 	*types.Account
 }
 
-// Implementación de AccountInterface para AccountWrapper
-func (a *AccountWrapper) GetID() uint64            { return a.ID }
+// Implementación de AccountInterface para AccountWrapper. Los montos de 128 bits se saturan
+// en math.MaxUint64 si no caben en 64 bits.
+func (a *AccountWrapper) GetID() uint64            { return saturatingUint64(a.ID) }
 func (a *AccountWrapper) GetLedger() uint32        { return a.Ledger }
 func (a *AccountWrapper) GetCode() uint16          { return a.Code }
-func (a *AccountWrapper) GetFlags() uint16         { return a.Flags.ToUint16() }
-func (a *AccountWrapper) GetDebitsPosted() uint64  { return a.DebitsPosted }
-func (a *AccountWrapper) GetCreditsPosted() uint64 { return a.CreditsPosted }
+func (a *AccountWrapper) GetFlags() uint16         { return a.Flags }
+func (a *AccountWrapper) GetDebitsPosted() uint64  { return saturatingUint64(a.DebitsPosted) }
+func (a *AccountWrapper) GetCreditsPosted() uint64 { return saturatingUint64(a.CreditsPosted) }
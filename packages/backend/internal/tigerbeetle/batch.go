@@ -0,0 +1,106 @@
+package tigerbeetle
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// lookupBatchSize es la cantidad máxima de cuentas consultadas por llamada a LookupAccounts
+	lookupBatchSize = 100
+
+	// createAccountsBatchSize es la cantidad máxima de cuentas que TigerBeetle acepta por llamada a CreateAccounts
+	createAccountsBatchSize = 8190
+
+	// batchLookupTimeout es el tiempo máximo para completar todas las consultas en paralelo
+	batchLookupTimeout = 5 * time.Second
+)
+
+// AccountBalance contiene los débitos y créditos registrados de una cuenta
+type AccountBalance struct {
+	DebitsPosted  uint64
+	CreditsPosted uint64
+}
+
+// Balance retorna el balance neto (créditos - débitos) en centavos
+func (b AccountBalance) Balance() int64 {
+	return int64(b.CreditsPosted) - int64(b.DebitsPosted)
+}
+
+// netBalances retorna el balance neto de cada cuenta indexado por su ID
+func netBalances(accounts []AccountInterface) map[uint64]int64 {
+	balances := make(map[uint64]int64, len(accounts))
+	for _, account := range accounts {
+		balances[account.GetID()] = AccountBalance{
+			DebitsPosted:  account.GetDebitsPosted(),
+			CreditsPosted: account.GetCreditsPosted(),
+		}.Balance()
+	}
+	return balances
+}
+
+// BatchLookupAccountsBalance obtiene los balances de muchas cuentas dividiendo los IDs en
+// bloques de lookupBatchSize y consultando cada bloque en paralelo. Si el conjunto de
+// consultas no termina dentro de batchLookupTimeout se retorna un error.
+func BatchLookupAccountsBalance(ctx context.Context, service TigerBeetleService, accountIDs []uint64) (map[uint64]AccountBalance, error) {
+	ctx, cancel := context.WithTimeout(ctx, batchLookupTimeout)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		balances = make(map[uint64]AccountBalance, len(accountIDs))
+		firstErr error
+		wg       sync.WaitGroup
+	)
+
+	for start := 0; start < len(accountIDs); start += lookupBatchSize {
+		end := start + lookupBatchSize
+		if end > len(accountIDs) {
+			end = len(accountIDs)
+		}
+		chunk := accountIDs[start:end]
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			accounts, err := service.LookupAccounts(chunk)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			for _, account := range accounts {
+				balances[account.GetID()] = AccountBalance{
+					DebitsPosted:  account.GetDebitsPosted(),
+					CreditsPosted: account.GetCreditsPosted(),
+				}
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("error looking up account balances: %w", ctx.Err())
+	}
+
+	if firstErr != nil {
+		return nil, fmt.Errorf("error looking up account balances: %w", firstErr)
+	}
+
+	return balances, nil
+}
@@ -0,0 +1,211 @@
+package tigerbeetle
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/sony/gobreaker"
+)
+
+const (
+	// breakerMaxRequests es la cantidad de llamadas de prueba permitidas en estado semiabierto
+	breakerMaxRequests = 1
+
+	// breakerInterval es cada cuánto se reinician los contadores en estado cerrado
+	breakerInterval = 30 * time.Second
+
+	// breakerTimeout es el tiempo que el circuito permanece abierto antes de probar de nuevo
+	breakerTimeout = 60 * time.Second
+
+	// breakerFailureThreshold es la cantidad de errores consecutivos que abren el circuito
+	breakerFailureThreshold = 5
+)
+
+// ErrCircuitOpen indica que la llamada no se realizó porque el circuito hacia TigerBeetle está abierto
+var ErrCircuitOpen = gobreaker.ErrOpenState
+
+// CircuitBreakerService envuelve un TigerBeetleService con un circuit breaker para que, si
+// TigerBeetle no está disponible, las llamadas fallen de inmediato en lugar de esperar el
+// timeout del cliente
+type CircuitBreakerService struct {
+	service TigerBeetleService
+	breaker *gobreaker.CircuitBreaker
+}
+
+// BreakerOption configura un CircuitBreakerService
+type BreakerOption func(*gobreaker.Settings)
+
+// WithBreakerTimeout configura el tiempo que el circuito permanece abierto
+func WithBreakerTimeout(timeout time.Duration) BreakerOption {
+	return func(st *gobreaker.Settings) {
+		st.Timeout = timeout
+	}
+}
+
+// NewCircuitBreakerService crea un TigerBeetleService protegido por un circuit breaker
+func NewCircuitBreakerService(service TigerBeetleService, opts ...BreakerOption) *CircuitBreakerService {
+	settings := gobreaker.Settings{
+		Name:        "tigerbeetle",
+		MaxRequests: breakerMaxRequests,
+		Interval:    breakerInterval,
+		Timeout:     breakerTimeout,
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			return counts.ConsecutiveFailures >= breakerFailureThreshold
+		},
+		OnStateChange: func(name string, from, to gobreaker.State) {
+			log.Printf("Circuit breaker %s changed from %s to %s", name, from, to)
+		},
+		IsSuccessful: isBreakerSuccess,
+	}
+
+	for _, opt := range opts {
+		opt(&settings)
+	}
+
+	return &CircuitBreakerService{
+		service: service,
+		breaker: gobreaker.NewCircuitBreaker(settings),
+	}
+}
+
+// isBreakerSuccess indica si el resultado de una llamada cuenta como éxito para el circuito.
+// Los rechazos de negocio (fondos insuficientes, ID duplicado) significan que TigerBeetle
+// respondió, por lo que no deben abrir el circuito.
+func isBreakerSuccess(err error) bool {
+	return err == nil || errors.Is(err, ErrExceedsCredits) || errors.Is(err, ErrTransferExists)
+}
+
+// State retorna el estado actual del circuito ("closed", "half-open" u "open")
+func (s *CircuitBreakerService) State() string {
+	return s.breaker.State().String()
+}
+
+// Close cierra el servicio envuelto
+func (s *CircuitBreakerService) Close() {
+	s.service.Close()
+}
+
+// Ping verifica la conectividad con TigerBeetle a través del circuito
+func (s *CircuitBreakerService) Ping() error {
+	return s.execute(s.service.Ping)
+}
+
+// CreateUserAccount crea una cuenta de usuario a través del circuito
+func (s *CircuitBreakerService) CreateUserAccount(userID uint64) (AccountInterface, error) {
+	return s.executeAccount(func() (AccountInterface, error) {
+		return s.service.CreateUserAccount(userID)
+	})
+}
+
+// BatchCreateUserAccounts crea varias cuentas de usuario a través del circuito
+func (s *CircuitBreakerService) BatchCreateUserAccounts(userIDs []uint64) ([]AccountInterface, []uint64, error) {
+	var (
+		created []AccountInterface
+		failed  []uint64
+	)
+	err := s.execute(func() error {
+		var err error
+		created, failed, err = s.service.BatchCreateUserAccounts(userIDs)
+		return err
+	})
+	return created, failed, err
+}
+
+// GetAccount obtiene una cuenta a través del circuito
+func (s *CircuitBreakerService) GetAccount(accountID uint64) (AccountInterface, error) {
+	return s.executeAccount(func() (AccountInterface, error) {
+		return s.service.GetAccount(accountID)
+	})
+}
+
+// GetAccountBalance obtiene los débitos y créditos de una cuenta a través del circuito
+func (s *CircuitBreakerService) GetAccountBalance(accountID uint64) (uint64, uint64, error) {
+	var debits, credits uint64
+	err := s.execute(func() error {
+		var err error
+		debits, credits, err = s.service.GetAccountBalance(accountID)
+		return err
+	})
+	return debits, credits, err
+}
+
+// LookupAccounts obtiene varias cuentas a través del circuito
+func (s *CircuitBreakerService) LookupAccounts(accountIDs []uint64) ([]AccountInterface, error) {
+	var accounts []AccountInterface
+	err := s.execute(func() error {
+		var err error
+		accounts, err = s.service.LookupAccounts(accountIDs)
+		return err
+	})
+	return accounts, err
+}
+
+// GetMultipleAccountBalances obtiene los balances de varias cuentas a través del circuito
+func (s *CircuitBreakerService) GetMultipleAccountBalances(accountIDs []uint64) (map[uint64]int64, error) {
+	var balances map[uint64]int64
+	err := s.execute(func() error {
+		var err error
+		balances, err = s.service.GetMultipleAccountBalances(accountIDs)
+		return err
+	})
+	return balances, err
+}
+
+// Transfer realiza una transferencia a través del circuito
+func (s *CircuitBreakerService) Transfer(fromAccountID, toAccountID, amount uint64, transferID uint64) error {
+	return s.execute(func() error {
+		return s.service.Transfer(fromAccountID, toAccountID, amount, transferID)
+	})
+}
+
+// LinkedTransfer realiza transferencias enlazadas a través del circuito
+func (s *CircuitBreakerService) LinkedTransfer(transfers []LinkedTransferRequest) error {
+	return s.execute(func() error {
+		return s.service.LinkedTransfer(transfers)
+	})
+}
+
+// BatchTransfer realiza varios grupos de transferencias independientes a través del circuito
+func (s *CircuitBreakerService) BatchTransfer(groups [][]LinkedTransferRequest) ([]error, error) {
+	var errs []error
+	err := s.execute(func() error {
+		var err error
+		errs, err = s.service.BatchTransfer(groups)
+		return err
+	})
+	return errs, err
+}
+
+// Deposit realiza un depósito a través del circuito
+func (s *CircuitBreakerService) Deposit(userAccountID, amount, transferID uint64) error {
+	return s.execute(func() error {
+		return s.service.Deposit(userAccountID, amount, transferID)
+	})
+}
+
+// Withdraw realiza un retiro a través del circuito
+func (s *CircuitBreakerService) Withdraw(userAccountID, amount, transferID uint64) error {
+	return s.execute(func() error {
+		return s.service.Withdraw(userAccountID, amount, transferID)
+	})
+}
+
+// execute ejecuta una llamada sin valor de retorno a través del circuito
+func (s *CircuitBreakerService) execute(call func() error) error {
+	_, err := s.breaker.Execute(func() (interface{}, error) {
+		return nil, call()
+	})
+	return err
+}
+
+// executeAccount ejecuta una llamada que retorna una cuenta a través del circuito
+func (s *CircuitBreakerService) executeAccount(call func() (AccountInterface, error)) (AccountInterface, error) {
+	var account AccountInterface
+	err := s.execute(func() error {
+		var err error
+		account, err = call()
+		return err
+	})
+	return account, err
+}
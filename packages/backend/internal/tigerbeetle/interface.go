@@ -1,12 +1,65 @@
 package tigerbeetle
 
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrExceedsCredits indica que TigerBeetle rechazó la transferencia porque la cuenta
+// origen no tiene créditos suficientes
+var ErrExceedsCredits = errors.New("insufficient funds: transfer exceeds credits")
+
+// ErrTransferExists indica que TigerBeetle rechazó la transferencia porque ya existe otra
+// con el mismo ID
+var ErrTransferExists = errors.New("transfer ID already exists")
+
+// ErrTransferMismatch indica que ya existe una transferencia con el mismo ID pero con otros datos
+// (cuentas, monto, código...). También se reconoce como ErrTransferExists.
+var ErrTransferMismatch = fmt.Errorf("%w with different fields", ErrTransferExists)
+
+// ErrNotConnected indica que la operación no se realizó porque no hay conexión con TigerBeetle
+var ErrNotConnected = errors.New("tigerbeetle not connected")
+
+// ErrPingFailed indica que TigerBeetle respondió a la verificación de conectividad sin la cuenta
+// maestra de débito
+var ErrPingFailed = errors.New("tigerbeetle ping failed: master debit account not found")
+
+// Códigos de transferencia registrados en TigerBeetle
+const (
+	TransferCodeStandard uint16 = 1 // Transferencia estándar
+	TransferCodeFee      uint16 = 2 // Cobro de comisión
+)
+
+// LinkedTransferRequest describe una transferencia dentro de un grupo de transferencias enlazadas
+type LinkedTransferRequest struct {
+	FromAccountID uint64
+	ToAccountID   uint64
+	Amount        uint64
+	TransferID    uint64
+	Code          uint16 // Si es 0 se usa TransferCodeStandard
+}
+
+// code retorna el código de la transferencia, usando el estándar si no se indicó
+func (r LinkedTransferRequest) code() uint16 {
+	if r.Code == 0 {
+		return TransferCodeStandard
+	}
+	return r.Code
+}
+
 // TigerBeetleService define la interfaz común para el servicio TigerBeetle
 type TigerBeetleService interface {
 	Close()
+	Ping() error
 	CreateUserAccount(userID uint64) (AccountInterface, error)
+	BatchCreateUserAccounts(userIDs []uint64) ([]AccountInterface, []uint64, error)
 	GetAccount(accountID uint64) (AccountInterface, error)
 	GetAccountBalance(accountID uint64) (uint64, uint64, error)
+	LookupAccounts(accountIDs []uint64) ([]AccountInterface, error)
+	GetMultipleAccountBalances(accountIDs []uint64) (map[uint64]int64, error)
 	Transfer(fromAccountID, toAccountID, amount uint64, transferID uint64) error
+	LinkedTransfer(transfers []LinkedTransferRequest) error
+	BatchTransfer(groups [][]LinkedTransferRequest) ([]error, error)
 	Deposit(userAccountID, amount, transferID uint64) error
 	Withdraw(userAccountID, amount, transferID uint64) error
 }
@@ -0,0 +1,37 @@
+package tigerbeetle
+
+import (
+	"context"
+	"time"
+)
+
+// ReconnectBackoff define las esperas entre los intentos de reconexión con TigerBeetle
+type ReconnectBackoff struct {
+	InitialDelay time.Duration // Espera antes del primer intento; se duplica en cada intento fallido
+	MaxDelay     time.Duration // Espera máxima entre intentos
+}
+
+// Reconnect espera InitialDelay y llama a connect con el número de intento; si falla vuelve a
+// intentarlo duplicando la espera hasta MaxDelay. Retorna nil cuando connect tiene éxito, o el
+// error de ctx si se cancela antes, sin volver a llamar a connect.
+func Reconnect(ctx context.Context, backoff ReconnectBackoff, connect func(attempt int) error) error {
+	delay := backoff.InitialDelay
+	for attempt := 1; ; attempt++ {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		if err := connect(attempt); err == nil {
+			return nil
+		}
+
+		delay *= 2
+		if delay > backoff.MaxDelay {
+			delay = backoff.MaxDelay
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package tigerbeetle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// maxRetries es la cantidad de reintentos ante errores transitorios de red
+	maxRetries = 3
+
+	// retryBaseDelay es la espera antes del primer reintento; se duplica en cada intento
+	retryBaseDelay = 100 * time.Millisecond
+)
+
+// CallWithRetry ejecuta fn y la reintenta hasta maxRetries veces con backoff exponencial
+// (100ms, 200ms, 400ms más un jitter de hasta la mitad) si falla por un error transitorio.
+// Los rechazos de TigerBeetle (fondos insuficientes, cuenta existente, etc.) no se reintentan.
+func CallWithRetry(fn func() error) error {
+	err := fn()
+	for attempt := 0; attempt < maxRetries && IsTransientError(err); attempt++ {
+		delay := retryBaseDelay << attempt
+		delay += rand.N(delay / 2)
+
+		log.Printf("TigerBeetle call failed with transient error, retrying in %s: %v", delay, err)
+		waitBackoff(delay)
+
+		err = fn()
+	}
+	return err
+}
+
+// IsTransientError indica si el error proviene de la conexión con TigerBeetle (io.EOF o
+// net.Error) y no de un resultado de la operación
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var netErr net.Error
+	return errors.Is(err, io.EOF) || errors.As(err, &netErr)
+}
+
+// waitBackoff espera el tiempo indicado usando un limitador de una sola ficha ya consumida
+func waitBackoff(delay time.Duration) {
+	limiter := rate.NewLimiter(rate.Every(delay), 1)
+	limiter.Allow()
+	_ = limiter.Wait(context.Background())
+}
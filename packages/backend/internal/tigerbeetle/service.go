@@ -5,6 +5,7 @@ package tigerbeetle
 import (
 	"fmt"
 	"log"
+	"sync"
 
 	"github.com/tigerbeetle/tigerbeetle-go"
 	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
@@ -17,6 +18,10 @@ const (
 	// Cuentas maestras del sistema
 	MasterDebitAccount  AccountType = 1 // Cuenta maestra de débito
 	MasterCreditAccount AccountType = 2 // Cuenta maestra de crédito
+	FeeAccount          AccountType = 3 // Cuenta de comisiones cobradas por transferencias
+
+	// Cuenta de ajustes manuales de balance realizados por administradores
+	CorrectionAccount AccountType = 999
 
 	// Cuentas de usuario
 	UserAccount AccountType = 100 // Cuenta de usuario individual
@@ -24,58 +29,108 @@ const (
 
 // Service maneja las operaciones de TigerBeetle
 type Service struct {
-	client tigerbeetle.Client
+	client func() tigerbeetle_go.Client
+	owned  tigerbeetle_go.Client // Cliente creado por NewService, que Close cierra
+
+	mu          sync.Mutex
+	initialized tigerbeetle_go.Client // Último cliente en el que se crearon las cuentas maestras
 }
 
 // NewService crea una nueva instancia del servicio TigerBeetle
-func NewService(clusterID uint128, addresses []string) (*Service, error) {
-	client, err := tigerbeetle.NewClient(clusterID, addresses)
+func NewService(clusterID types.Uint128, addresses []string) (*Service, error) {
+	client, err := tigerbeetle_go.NewClient(clusterID, addresses)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create TigerBeetle client: %w", err)
 	}
 
-	service := &Service{client: client}
+	service := &Service{client: func() tigerbeetle_go.Client { return client }, owned: client}
 
 	// Inicializar cuentas maestras si no existen
 	if err := service.initializeMasterAccounts(); err != nil {
 		return nil, fmt.Errorf("failed to initialize master accounts: %w", err)
 	}
+	service.initialized = client
 
 	return service, nil
 }
 
-// Close cierra la conexión con TigerBeetle
+// NewServiceWithClient crea un servicio que opera con el cliente que retorna client en cada
+// llamada, de modo que quien administra la conexión puede reemplazarlo al reconectar. Las cuentas
+// maestras se crean la primera vez que se usa cada cliente. Mientras client retorne nil las
+// operaciones fallan con ErrNotConnected. Close no cierra el cliente.
+func NewServiceWithClient(client func() tigerbeetle_go.Client) *Service {
+	return &Service{client: client}
+}
+
+// Close cierra la conexión con TigerBeetle si el servicio la creó
 func (s *Service) Close() {
-	s.client.Close()
+	if s.owned != nil {
+		s.owned.Close()
+	}
+}
+
+// conn retorna el cliente actual de TigerBeetle, creando las cuentas maestras si es un cliente nuevo
+func (s *Service) conn() (tigerbeetle_go.Client, error) {
+	client := s.client()
+	if client == nil {
+		return nil, ErrNotConnected
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.initialized != client {
+		if err := createMasterAccounts(client); err != nil {
+			return nil, fmt.Errorf("failed to initialize master accounts: %w", err)
+		}
+		s.initialized = client
+	}
+	return client, nil
 }
 
 // initializeMasterAccounts crea las cuentas maestras del sistema
 func (s *Service) initializeMasterAccounts() error {
+	return createMasterAccounts(s.owned)
+}
+
+// createMasterAccounts crea con el cliente indicado las cuentas maestras que aún no existen
+func createMasterAccounts(client tigerbeetle_go.Client) error {
 	// Definir las cuentas maestras
 	masterAccounts := []types.Account{
 		{
-			ID:     1, // ID fijo para cuenta maestra de débito
+			ID:     types.ToUint128(1), // ID fijo para cuenta maestra de débito
 			Ledger: 1,
 			Code:   uint16(MasterDebitAccount),
 			Flags:  types.AccountFlags{}.ToUint16(),
 		},
 		{
-			ID:     2, // ID fijo para cuenta maestra de crédito
+			ID:     types.ToUint128(2), // ID fijo para cuenta maestra de crédito
 			Ledger: 1,
 			Code:   uint16(MasterCreditAccount),
 			Flags:  types.AccountFlags{}.ToUint16(),
 		},
+		{
+			ID:     types.ToUint128(3), // ID fijo para cuenta de comisiones
+			Ledger: 1,
+			Code:   uint16(FeeAccount),
+			Flags:  types.AccountFlags{}.ToUint16(),
+		},
+		{
+			ID:     types.ToUint128(999), // ID fijo para cuenta de correcciones
+			Ledger: 1,
+			Code:   uint16(CorrectionAccount),
+			Flags:  types.AccountFlags{}.ToUint16(),
+		},
 	}
 
 	// Intentar crear las cuentas maestras
-	results, err := s.client.CreateAccounts(masterAccounts)
+	results, err := client.CreateAccounts(masterAccounts)
 	if err != nil {
 		return fmt.Errorf("error creating master accounts: %w", err)
 	}
 
 	// Verificar si hubo errores (ignorar si las cuentas ya existen)
 	for _, result := range results {
-		if result.Result != types.AccountExistsError && result.Result != types.AccountOK {
+		if result.Result != types.AccountExists && result.Result != types.AccountOK {
 			log.Printf("Warning: Master account creation result: %v", result.Result)
 		}
 	}
@@ -87,21 +142,28 @@ func (s *Service) initializeMasterAccounts() error {
 // CreateUserAccount crea una nueva cuenta para un usuario
 func (s *Service) CreateUserAccount(userID uint64) (AccountInterface, error) {
 	account := types.Account{
-		ID:     userID, // Usar el ID del usuario como ID de cuenta
+		ID:     types.ToUint128(userID), // Usar el ID del usuario como ID de cuenta
 		Ledger: 1,
 		Code:   uint16(UserAccount),
-		Flags:  types.AccountFlags{}.ToUint16(),
+		// TigerBeetle rechaza los débitos que superan los créditos, así que dos retiros concurrentes
+		// no pueden dejar la cuenta en negativo aunque ambos pasen la verificación de balance
+		Flags: types.AccountFlags{DebitsMustNotExceedCredits: true}.ToUint16(),
+	}
+
+	client, err := s.conn()
+	if err != nil {
+		return nil, err
 	}
 
 	accounts := []types.Account{account}
-	results, err := s.client.CreateAccounts(accounts)
+	results, err := client.CreateAccounts(accounts)
 	if err != nil {
 		return nil, fmt.Errorf("error creating user account: %w", err)
 	}
 
 	// Verificar el resultado
 	if len(results) > 0 && results[0].Result != types.AccountOK {
-		if results[0].Result == types.AccountExistsError {
+		if results[0].Result == types.AccountExists {
 			return &AccountWrapper{&account}, fmt.Errorf("account already exists for user %d", userID)
 		}
 		return nil, fmt.Errorf("failed to create account: %v", results[0].Result)
@@ -111,9 +173,68 @@ func (s *Service) CreateUserAccount(userID uint64) (AccountInterface, error) {
 	return &AccountWrapper{&account}, nil
 }
 
+// BatchCreateUserAccounts crea las cuentas de varios usuarios enviándolas a TigerBeetle en bloques
+// de createAccountsBatchSize. Retorna por separado las cuentas creadas y los IDs que TigerBeetle
+// rechazó (incluidas las cuentas que ya existían). Si una llamada falla, se retorna lo procesado
+// hasta ese momento junto con el error.
+func (s *Service) BatchCreateUserAccounts(userIDs []uint64) ([]AccountInterface, []uint64, error) {
+	created := make([]AccountInterface, 0, len(userIDs))
+	var failed []uint64
+
+	for start := 0; start < len(userIDs); start += createAccountsBatchSize {
+		end := start + createAccountsBatchSize
+		if end > len(userIDs) {
+			end = len(userIDs)
+		}
+
+		accounts := make([]types.Account, end-start)
+		for i, userID := range userIDs[start:end] {
+			accounts[i] = types.Account{
+				ID:     types.ToUint128(userID),
+				Ledger: 1,
+				Code:   uint16(UserAccount),
+				Flags:  types.AccountFlags{DebitsMustNotExceedCredits: true}.ToUint16(),
+			}
+		}
+
+		client, err := s.conn()
+		if err != nil {
+			return created, failed, err
+		}
+		results, err := client.CreateAccounts(accounts)
+		if err != nil {
+			return created, failed, fmt.Errorf("error creating user accounts: %w", err)
+		}
+
+		// TigerBeetle solo retorna resultados para las cuentas que no se crearon
+		rejected := make(map[uint32]bool, len(results))
+		for _, result := range results {
+			if result.Result != types.AccountOK {
+				rejected[result.Index] = true
+			}
+		}
+
+		for i := range accounts {
+			if rejected[uint32(i)] {
+				failed = append(failed, userIDs[start+i])
+				continue
+			}
+			created = append(created, &AccountWrapper{&accounts[i]})
+		}
+	}
+
+	log.Printf("Created %d TigerBeetle accounts in batch (%d failed)", len(created), len(failed))
+	return created, failed, nil
+}
+
 // GetAccount obtiene información de una cuenta
 func (s *Service) GetAccount(accountID uint64) (AccountInterface, error) {
-	accounts, err := s.client.LookupAccounts([]uint64{accountID})
+	client, err := s.conn()
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := client.LookupAccounts([]types.Uint128{types.ToUint128(accountID)})
 	if err != nil {
 		return nil, fmt.Errorf("error looking up account: %w", err)
 	}
@@ -125,6 +246,53 @@ func (s *Service) GetAccount(accountID uint64) (AccountInterface, error) {
 	return &AccountWrapper{&accounts[0]}, nil
 }
 
+// LookupAccounts obtiene varias cuentas en una sola llamada.
+// Las cuentas inexistentes se omiten del resultado.
+func (s *Service) LookupAccounts(accountIDs []uint64) ([]AccountInterface, error) {
+	ids := make([]types.Uint128, len(accountIDs))
+	for i, id := range accountIDs {
+		ids[i] = types.ToUint128(id)
+	}
+
+	client, err := s.conn()
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := client.LookupAccounts(ids)
+	if err != nil {
+		return nil, fmt.Errorf("error looking up accounts: %w", err)
+	}
+
+	result := make([]AccountInterface, 0, len(accounts))
+	for i := range accounts {
+		result = append(result, &AccountWrapper{&accounts[i]})
+	}
+	return result, nil
+}
+
+// GetMultipleAccountBalances obtiene el balance neto (créditos - débitos) de varias cuentas con
+// una sola llamada a LookupAccounts. Las cuentas inexistentes no aparecen en el resultado.
+func (s *Service) GetMultipleAccountBalances(accountIDs []uint64) (map[uint64]int64, error) {
+	accounts, err := s.LookupAccounts(accountIDs)
+	if err != nil {
+		return nil, err
+	}
+	return netBalances(accounts), nil
+}
+
+// Ping verifica la conectividad con TigerBeetle consultando la cuenta maestra de débito
+func (s *Service) Ping() error {
+	accounts, err := s.LookupAccounts([]uint64{uint64(MasterDebitAccount)})
+	if err != nil {
+		return err
+	}
+	if len(accounts) == 0 {
+		return ErrPingFailed
+	}
+	return nil
+}
+
 // GetAccountBalance obtiene el balance de una cuenta
 func (s *Service) GetAccountBalance(accountID uint64) (uint64, uint64, error) {
 	account, err := s.GetAccount(accountID)
@@ -138,30 +306,166 @@ func (s *Service) GetAccountBalance(accountID uint64) (uint64, uint64, error) {
 // Transfer realiza una transferencia entre cuentas
 func (s *Service) Transfer(fromAccountID, toAccountID, amount uint64, transferID uint64) error {
 	transfer := types.Transfer{
-		ID:              transferID,
-		DebitAccountID:  fromAccountID,
-		CreditAccountID: toAccountID,
-		Amount:          amount,
+		ID:              types.ToUint128(transferID),
+		DebitAccountID:  types.ToUint128(fromAccountID),
+		CreditAccountID: types.ToUint128(toAccountID),
+		Amount:          types.ToUint128(amount),
 		Ledger:          1,
-		Code:            1, // Código de transferencia estándar
+		Code:            TransferCodeStandard,
 		Flags:           types.TransferFlags{}.ToUint16(),
 	}
 
-	transfers := []types.Transfer{transfer}
-	results, err := s.client.CreateTransfers(transfers)
+	results, retried, err := s.createTransfers([]types.Transfer{transfer})
 	if err != nil {
 		return fmt.Errorf("error creating transfer: %w", err)
 	}
 
-	// Verificar el resultado
+	// Verificar el resultado. Si hubo reintentos, TransferExists significa que un intento
+	// anterior sí se aplicó antes de perder la conexión.
 	if len(results) > 0 && results[0].Result != types.TransferOK {
-		return fmt.Errorf("transfer failed: %v", results[0].Result)
+		if retried && results[0].Result == types.TransferExists {
+			return nil
+		}
+		return transferResultError(results[0].Result)
 	}
 
 	log.Printf("Transfer completed: %d from account %d to account %d", amount, fromAccountID, toAccountID)
 	return nil
 }
 
+// LinkedTransfer aplica varias transferencias de forma atómica: todas llevan el flag Linked
+// excepto la última, que cierra la cadena, así que TigerBeetle aplica todas o ninguna
+func (s *Service) LinkedTransfer(transfers []LinkedTransferRequest) error {
+	if len(transfers) == 0 {
+		return nil
+	}
+
+	batch := make([]types.Transfer, len(transfers))
+	for i, transfer := range transfers {
+		batch[i] = types.Transfer{
+			ID:              types.ToUint128(transfer.TransferID),
+			DebitAccountID:  types.ToUint128(transfer.FromAccountID),
+			CreditAccountID: types.ToUint128(transfer.ToAccountID),
+			Amount:          types.ToUint128(transfer.Amount),
+			Ledger:          1,
+			Code:            transfer.code(),
+			Flags:           types.TransferFlags{Linked: i < len(transfers)-1}.ToUint16(),
+		}
+	}
+
+	results, retried, err := s.createTransfers(batch)
+	if err != nil {
+		return fmt.Errorf("error creating transfer: %w", err)
+	}
+
+	// Verificar el resultado (las transferencias enlazadas fallan juntas)
+	for _, result := range results {
+		if result.Result == types.TransferOK || result.Result == types.TransferLinkedEventFailed {
+			continue
+		}
+		if retried && result.Result == types.TransferExists {
+			continue
+		}
+		return transferResultError(result.Result)
+	}
+
+	log.Printf("Linked transfer completed: %d transfers", len(transfers))
+	return nil
+}
+
+// BatchTransfer envía varios grupos de transferencias en una sola llamada a CreateTransfers. Las
+// transferencias de cada grupo se enlazan entre sí, pero los grupos no: uno rechazado no impide
+// aplicar los demás. Retorna el error de cada grupo (nil si se aplicó), o un error si la llamada
+// completa falló.
+func (s *Service) BatchTransfer(groups [][]LinkedTransferRequest) ([]error, error) {
+	var batch []types.Transfer
+	var groupOf []int // Grupo de cada transferencia del lote
+	for g, group := range groups {
+		for i, transfer := range group {
+			batch = append(batch, types.Transfer{
+				ID:              types.ToUint128(transfer.TransferID),
+				DebitAccountID:  types.ToUint128(transfer.FromAccountID),
+				CreditAccountID: types.ToUint128(transfer.ToAccountID),
+				Amount:          types.ToUint128(transfer.Amount),
+				Ledger:          1,
+				Code:            transfer.code(),
+				Flags:           types.TransferFlags{Linked: i < len(group)-1}.ToUint16(),
+			})
+			groupOf = append(groupOf, g)
+		}
+	}
+
+	errs := make([]error, len(groups))
+	if len(batch) == 0 {
+		return errs, nil
+	}
+
+	results, retried, err := s.createTransfers(batch)
+	if err != nil {
+		return nil, fmt.Errorf("error creating transfers: %w", err)
+	}
+
+	// Cada grupo falla por la primera transferencia rechazada; el resto de sus transferencias
+	// se reportan como TransferLinkedEventFailed
+	for _, result := range results {
+		if result.Result == types.TransferOK || result.Result == types.TransferLinkedEventFailed {
+			continue
+		}
+		if retried && result.Result == types.TransferExists {
+			continue
+		}
+		if g := groupOf[result.Index]; errs[g] == nil {
+			errs[g] = transferResultError(result.Result)
+		}
+	}
+
+	log.Printf("Batch transfer completed: %d transfers in %d groups", len(batch), len(groups))
+	return errs, nil
+}
+
+// createTransfers envía las transferencias reintentando ante errores transitorios de red.
+// Indica además si hubo reintentos, ya que un intento fallido pudo haberse aplicado.
+func (s *Service) createTransfers(transfers []types.Transfer) ([]types.TransferEventResult, bool, error) {
+	client, err := s.conn()
+	if err != nil {
+		return nil, false, err
+	}
+
+	var results []types.TransferEventResult
+	attempts := 0
+	err = CallWithRetry(func() error {
+		attempts++
+		var err error
+		results, err = client.CreateTransfers(transfers)
+		return err
+	})
+	return results, attempts > 1, err
+}
+
+// transferResultError convierte el resultado de una transferencia rechazada en un error,
+// usando los errores de la interfaz para los casos que el llamador puede manejar
+func transferResultError(result types.CreateTransferResult) error {
+	switch result {
+	case types.TransferExceedsCredits:
+		return ErrExceedsCredits
+	case types.TransferExists:
+		return fmt.Errorf("%w: %v", ErrTransferExists, result)
+	case types.TransferExistsWithDifferentFlags,
+		types.TransferExistsWithDifferentPendingID,
+		types.TransferExistsWithDifferentTimeout,
+		types.TransferExistsWithDifferentDebitAccountID,
+		types.TransferExistsWithDifferentCreditAccountID,
+		types.TransferExistsWithDifferentAmount,
+		types.TransferExistsWithDifferentUserData128,
+		types.TransferExistsWithDifferentUserData64,
+		types.TransferExistsWithDifferentUserData32,
+		types.TransferExistsWithDifferentLedger,
+		types.TransferExistsWithDifferentCode:
+		return fmt.Errorf("%w: %v", ErrTransferMismatch, result)
+	}
+	return fmt.Errorf("transfer failed: %v", result)
+}
+
 // Deposit realiza un depósito a una cuenta de usuario desde la cuenta maestra de crédito
 func (s *Service) Deposit(userAccountID, amount, transferID uint64) error {
 	return s.Transfer(2, userAccountID, amount, transferID) // 2 = MasterCreditAccount
@@ -171,8 +475,3 @@ func (s *Service) Deposit(userAccountID, amount, transferID uint64) error {
 func (s *Service) Withdraw(userAccountID, amount, transferID uint64) error {
 	return s.Transfer(userAccountID, 1, amount, transferID) // 1 = MasterDebitAccount
 }
-
-// uint128 helper para crear un uint128 desde dos uint64
-func uint128(high, low uint64) types.Uint128 {
-	return types.Uint128{High: high, Low: low}
-}
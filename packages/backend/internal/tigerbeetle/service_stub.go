@@ -5,6 +5,7 @@ package tigerbeetle
 import (
 	"fmt"
 	"log"
+	"sync"
 )
 
 // AccountType define el tipo de cuenta
@@ -14,11 +15,18 @@ const (
 	// Cuentas maestras del sistema
 	MasterDebitAccount  AccountType = 1 // Cuenta maestra de débito
 	MasterCreditAccount AccountType = 2 // Cuenta maestra de crédito
+	FeeAccount          AccountType = 3 // Cuenta de comisiones cobradas por transferencias
+
+	// Cuenta de ajustes manuales de balance realizados por administradores
+	CorrectionAccount AccountType = 999
 
 	// Cuentas de usuario
 	UserAccount AccountType = 100 // Cuenta de usuario individual
 )
 
+// flagDebitsMustNotExceedCredits es el bit de AccountFlags.DebitsMustNotExceedCredits en TigerBeetle
+const flagDebitsMustNotExceedCredits uint16 = 1 << 1
+
 // Account representa una cuenta simplificada para el stub
 type Account struct {
 	ID            uint64
@@ -37,18 +45,30 @@ func (a *Account) GetFlags() uint16         { return a.Flags }
 func (a *Account) GetDebitsPosted() uint64  { return a.DebitsPosted }
 func (a *Account) GetCreditsPosted() uint64 { return a.CreditsPosted }
 
-// Service maneja las operaciones de TigerBeetle (stub para CI)
+// Service maneja las operaciones de TigerBeetle (stub para CI). Las operaciones se serializan
+// igual que en TigerBeetle, así que el stub puede usarse desde varias goroutines.
 type Service struct {
+	mu             sync.Mutex
 	accounts       map[uint64]*Account
+	transfers      map[uint64]stubTransfer
 	nextTransferID uint64
 }
 
+// stubTransfer guarda los datos de una transferencia aplicada para distinguir un duplicado
+// idéntico de uno con otros datos, igual que TigerBeetle
+type stubTransfer struct {
+	fromAccountID uint64
+	toAccountID   uint64
+	amount        uint64
+}
+
 // NewService crea una nueva instancia del servicio TigerBeetle (stub)
 func NewService(clusterID interface{}, addresses []string) (*Service, error) {
 	log.Println("Using TigerBeetle stub service for CI/testing")
 
 	service := &Service{
 		accounts:       make(map[uint64]*Account),
+		transfers:      make(map[uint64]stubTransfer),
 		nextTransferID: 1,
 	}
 
@@ -66,6 +86,7 @@ func NewServiceStub() *Service {
 
 	service := &Service{
 		accounts:       make(map[uint64]*Account),
+		transfers:      make(map[uint64]stubTransfer),
 		nextTransferID: 1,
 	}
 
@@ -84,6 +105,9 @@ func (s *Service) InitializeMasterAccounts() error {
 
 // initializeMasterAccounts crea las cuentas maestras del sistema (stub)
 func (s *Service) initializeMasterAccounts() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	// Crear cuentas maestras en memoria
 	s.accounts[1] = &Account{
 		ID:            1,
@@ -103,17 +127,42 @@ func (s *Service) initializeMasterAccounts() error {
 		CreditsPosted: 1000000000, // 10,000,000.00 en centavos como balance inicial
 	}
 
+	s.accounts[3] = &Account{
+		ID:            3,
+		Ledger:        1,
+		Code:          uint16(FeeAccount),
+		Flags:         0,
+		DebitsPosted:  0,
+		CreditsPosted: 0,
+	}
+
+	s.accounts[999] = &Account{
+		ID:            999,
+		Ledger:        1,
+		Code:          uint16(CorrectionAccount),
+		Flags:         0,
+		DebitsPosted:  0,
+		CreditsPosted: 0,
+	}
+
 	log.Println("Master accounts initialized (stub)")
 	return nil
 }
 
 // CreateUserAccount crea una nueva cuenta de usuario (stub)
 func (s *Service) CreateUserAccount(userID uint64) (AccountInterface, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.accounts[userID]; exists {
+		return nil, fmt.Errorf("account already exists")
+	}
+
 	account := &Account{
 		ID:            userID,
 		Ledger:        1,
 		Code:          uint16(UserAccount),
-		Flags:         0,
+		Flags:         flagDebitsMustNotExceedCredits,
 		DebitsPosted:  0,
 		CreditsPosted: 0,
 	}
@@ -123,44 +172,185 @@ func (s *Service) CreateUserAccount(userID uint64) (AccountInterface, error) {
 	return account, nil
 }
 
+// BatchCreateUserAccounts crea las cuentas de varios usuarios en una sola operación (stub).
+// Las cuentas que ya existen se retornan como fallidas.
+func (s *Service) BatchCreateUserAccounts(userIDs []uint64) ([]AccountInterface, []uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	created := make([]AccountInterface, 0, len(userIDs))
+	var failed []uint64
+	for _, userID := range userIDs {
+		if _, exists := s.accounts[userID]; exists {
+			failed = append(failed, userID)
+			continue
+		}
+
+		account := &Account{
+			ID:     userID,
+			Ledger: 1,
+			Code:   uint16(UserAccount),
+			Flags:  flagDebitsMustNotExceedCredits,
+		}
+		s.accounts[userID] = account
+		created = append(created, account)
+	}
+
+	log.Printf("Created %d user accounts in batch (stub, %d failed)", len(created), len(failed))
+	return created, failed, nil
+}
+
 // GetAccount obtiene una cuenta por ID (stub)
 func (s *Service) GetAccount(accountID uint64) (AccountInterface, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	account, exists := s.accounts[accountID]
 	if !exists {
-		return nil, fmt.Errorf("account %d not found", accountID)
+		return nil, fmt.Errorf("account not found: %d", accountID)
 	}
 	return account, nil
 }
 
+// LookupAccounts obtiene varias cuentas en una sola llamada (stub).
+// Igual que TigerBeetle, las cuentas inexistentes se omiten del resultado.
+func (s *Service) LookupAccounts(accountIDs []uint64) ([]AccountInterface, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accounts := make([]AccountInterface, 0, len(accountIDs))
+	for _, id := range accountIDs {
+		if account, exists := s.accounts[id]; exists {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts, nil
+}
+
+// GetMultipleAccountBalances obtiene el balance neto de varias cuentas en una sola llamada (stub)
+func (s *Service) GetMultipleAccountBalances(accountIDs []uint64) (map[uint64]int64, error) {
+	accounts, err := s.LookupAccounts(accountIDs)
+	if err != nil {
+		return nil, err
+	}
+	return netBalances(accounts), nil
+}
+
+// Ping verifica la conectividad con TigerBeetle consultando la cuenta maestra de débito (stub)
+func (s *Service) Ping() error {
+	accounts, err := s.LookupAccounts([]uint64{uint64(MasterDebitAccount)})
+	if err != nil {
+		return err
+	}
+	if len(accounts) == 0 {
+		return ErrPingFailed
+	}
+	return nil
+}
+
 // GetAccountBalance obtiene el balance de una cuenta (stub)
 func (s *Service) GetAccountBalance(accountID uint64) (uint64, uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	account, exists := s.accounts[accountID]
 	if !exists {
-		return 0, 0, fmt.Errorf("account %d not found", accountID)
+		return 0, 0, fmt.Errorf("account not found: %d", accountID)
 	}
 	return account.DebitsPosted, account.CreditsPosted, nil
 }
 
 // Transfer realiza una transferencia entre cuentas (stub)
 func (s *Service) Transfer(fromAccountID, toAccountID, amount uint64, transferID uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.transfer(fromAccountID, toAccountID, amount, transferID)
+}
+
+// transfer aplica una transferencia; quien la llama debe tener tomado s.mu
+func (s *Service) transfer(fromAccountID, toAccountID, amount uint64, transferID uint64) error {
 	fromAccount, exists := s.accounts[fromAccountID]
 	if !exists {
-		return fmt.Errorf("from account %d not found", fromAccountID)
+		return fmt.Errorf("from account not found: %d", fromAccountID)
 	}
 
 	toAccount, exists := s.accounts[toAccountID]
 	if !exists {
-		return fmt.Errorf("to account %d not found", toAccountID)
+		return fmt.Errorf("to account not found: %d", toAccountID)
+	}
+
+	// Rechazar IDs de transferencia repetidos, igual que TigerBeetle
+	if existing, exists := s.transfers[transferID]; exists {
+		if existing != (stubTransfer{fromAccountID, toAccountID, amount}) {
+			return fmt.Errorf("%w: %d", ErrTransferMismatch, transferID)
+		}
+		return fmt.Errorf("%w: %d", ErrTransferExists, transferID)
+	}
+
+	// Las cuentas con DebitsMustNotExceedCredits no pueden quedar con débitos mayores a sus créditos
+	if exceedsCredits(fromAccount, amount) {
+		return ErrExceedsCredits
 	}
 
 	// Simular transferencia
 	fromAccount.DebitsPosted += amount
 	toAccount.CreditsPosted += amount
+	s.transfers[transferID] = stubTransfer{fromAccountID, toAccountID, amount}
 
 	log.Printf("Transfer %d: %d -> %d, amount: %d (stub)", transferID, fromAccountID, toAccountID, amount)
 	return nil
 }
 
+// LinkedTransfer aplica varias transferencias de forma atómica (stub). Si alguna falla se
+// revierten las ya aplicadas, igual que TigerBeetle con las transferencias enlazadas.
+func (s *Service) LinkedTransfer(transfers []LinkedTransferRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, transfer := range transfers {
+		if err := s.transfer(transfer.FromAccountID, transfer.ToAccountID, transfer.Amount, transfer.TransferID); err != nil {
+			s.revert(transfers[:i])
+			return err
+		}
+	}
+	return nil
+}
+
+// BatchTransfer aplica varios grupos de transferencias enlazadas de forma independiente (stub):
+// un grupo rechazado se revierte sin afectar a los demás
+func (s *Service) BatchTransfer(groups [][]LinkedTransferRequest) ([]error, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	errs := make([]error, len(groups))
+	for g, group := range groups {
+		for i, transfer := range group {
+			if err := s.transfer(transfer.FromAccountID, transfer.ToAccountID, transfer.Amount, transfer.TransferID); err != nil {
+				s.revert(group[:i])
+				errs[g] = err
+				break
+			}
+		}
+	}
+	return errs, nil
+}
+
+// revert deshace transferencias ya aplicadas; quien la llama debe tener tomado s.mu
+func (s *Service) revert(transfers []LinkedTransferRequest) {
+	for _, transfer := range transfers {
+		s.accounts[transfer.FromAccountID].DebitsPosted -= transfer.Amount
+		s.accounts[transfer.ToAccountID].CreditsPosted -= transfer.Amount
+		delete(s.transfers, transfer.TransferID)
+	}
+}
+
+// exceedsCredits indica si debitar el monto dejaría los débitos de una cuenta con
+// DebitsMustNotExceedCredits por encima de sus créditos
+func exceedsCredits(account *Account, amount uint64) bool {
+	return account.Flags&flagDebitsMustNotExceedCredits != 0 && account.CreditsPosted < account.DebitsPosted+amount
+}
+
 // Deposit realiza un depósito a una cuenta de usuario (stub)
 func (s *Service) Deposit(userAccountID, amount, transferID uint64) error {
 	return s.Transfer(2, userAccountID, amount, transferID) // Desde cuenta maestra de crédito
@@ -0,0 +1,50 @@
+package tigerbeetle
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+)
+
+// ErrAmountOverflow indica que un monto de 128 bits de TigerBeetle no cabe en 64 bits
+var ErrAmountOverflow = errors.New("amount exceeds 64 bits")
+
+// Uint128ToUint64 convierte un Uint128 de TigerBeetle a uint64 leyendo sus bytes directamente
+// (little-endian). Retorna ErrAmountOverflow si los 64 bits altos no son cero.
+func Uint128ToUint64(value types.Uint128) (uint64, error) {
+	bytes := value.Bytes()
+	if binary.LittleEndian.Uint64(bytes[8:]) != 0 {
+		return 0, ErrAmountOverflow
+	}
+	return binary.LittleEndian.Uint64(bytes[:8]), nil
+}
+
+// saturatingUint64 convierte un Uint128 a uint64, retornando math.MaxUint64 si no cabe
+func saturatingUint64(value types.Uint128) uint64 {
+	v, err := Uint128ToUint64(value)
+	if err != nil {
+		return math.MaxUint64
+	}
+	return v
+}
+
+// NetBalance calcula el balance disponible (créditos - débitos) de una cuenta a partir de sus
+// montos registrados de 128 bits. Si los débitos superan a los créditos el balance es cero.
+func NetBalance(creditsPosted, debitsPosted types.Uint128) (uint64, error) {
+	credits, err := Uint128ToUint64(creditsPosted)
+	if err != nil {
+		return 0, err
+	}
+
+	debits, err := Uint128ToUint64(debitsPosted)
+	if err != nil {
+		return 0, err
+	}
+
+	if debits > credits {
+		return 0, nil
+	}
+	return credits - debits, nil
+}
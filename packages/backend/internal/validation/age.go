@@ -0,0 +1,21 @@
+package validation
+
+import (
+	"errors"
+	"time"
+)
+
+// MinimumAge es la edad mínima en años para abrir una cuenta
+const MinimumAge = 18
+
+// ErrUnderage indica que el usuario no alcanza la edad mínima
+var ErrUnderage = errors.New("user must be at least 18 years old")
+
+// ValidateMinimumAge verifica que a la fecha now hayan pasado al menos MinimumAge años desde
+// la fecha de nacimiento. Se comparan años calendario para no fallar por los años bisiestos.
+func ValidateMinimumAge(dateOfBirth, now time.Time) error {
+	if dateOfBirth.AddDate(MinimumAge, 0, 0).After(now) {
+		return ErrUnderage
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+// Package validation contiene reglas de validación de datos de usuario compartidas entre
+// handlers y servicios
+package validation
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidPhone indica que el teléfono no está en formato E.164
+var ErrInvalidPhone = errors.New("phone must be in E.164 format, e.g. +5215512345678")
+
+// e164Pattern acepta un "+" seguido de 7 a 15 dígitos sin cero inicial
+var e164Pattern = regexp.MustCompile(`^\+[1-9]\d{6,14}$`)
+
+// phoneSeparators elimina los separadores que los usuarios suelen escribir en un teléfono
+var phoneSeparators = strings.NewReplacer(" ", "", "-", "")
+
+// NormalizePhone retorna el teléfono sin espacios ni guiones, la forma en que se guarda
+func NormalizePhone(phone string) string {
+	return phoneSeparators.Replace(phone)
+}
+
+// ValidatePhone verifica que el teléfono, una vez normalizado, esté en formato E.164
+func ValidatePhone(phone string) error {
+	if !e164Pattern.MatchString(NormalizePhone(phone)) {
+		return ErrInvalidPhone
+	}
+	return nil
+}
@@ -1,43 +1,117 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"io"
 	"log"
 	"net/http"
-	"os"
+	"os/signal"
 	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.uber.org/zap"
 
 	"banca-en-linea/backend/database"
 	"banca-en-linea/backend/internal/auth"
+	"banca-en-linea/backend/internal/cache"
+	"banca-en-linea/backend/internal/compliance"
+	"banca-en-linea/backend/internal/config"
 	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/email"
+	apperrors "banca-en-linea/backend/internal/errors"
+	"banca-en-linea/backend/internal/fee"
+	"banca-en-linea/backend/internal/fraud"
 	"banca-en-linea/backend/internal/handlers"
 	"banca-en-linea/backend/internal/middleware"
-	// "banca-en-linea/backend/internal/tigerbeetle" // Comentado temporalmente
+	"banca-en-linea/backend/internal/monitoring"
+	"banca-en-linea/backend/internal/pii"
+	"banca-en-linea/backend/internal/problem"
+	"banca-en-linea/backend/internal/tigerbeetle"
+	"banca-en-linea/backend/internal/tracing"
+	"banca-en-linea/backend/internal/validation"
 	"banca-en-linea/backend/models"
 )
 
 type Server struct {
-	userService *db.UserService
-	// tigerBeetleClient *tigerbeetle.Client // Comentado temporalmente
-	authService *auth.Service
-	authHandler *handlers.AuthHandler
+	userService           *db.UserService
+	authService           *auth.Service
+	authHandler           *handlers.AuthHandler
+	adminHandler          *handlers.AdminHandler
+	monitoringHandler     *handlers.MonitoringHandler
+	transactionHandler    *handlers.TransactionHandler
+	accountHandler        *handlers.AccountHandler
+	idempotencyStore      middleware.IdempotencyStore
+	activityLog           middleware.ActivityLogRepository
+	reconciliationHandler *handlers.ReconciliationHandler
+	scheduledTransfers    *handlers.ScheduledTransferHandler
+	paymentRequests       *handlers.PaymentRequestHandler
+	beneficiaries         *handlers.BeneficiaryHandler
+	transferTemplates     *handlers.TransferTemplateHandler
+	splitTransfers        *handlers.SplitTransferHandler
+	disputes              *handlers.DisputeHandler
+	webhooks              *handlers.WebhookHandler
+	balanceStream         *handlers.BalanceStreamHandler
+	largeTransfers        *handlers.LargeTransferGuard
+	metricsRegistry       *prometheus.Registry
+	dbConn                *sql.DB
+	corsConfig            middleware.CORSConfig
+	logBodies             bool
 }
 
 func main() {
 	// Configurar logging
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	// Usar el logger de zap como global para los logs de los handlers
+	zap.ReplaceGlobals(logger)
 	log.Println("Iniciando servidor backend...")
 
+	// Leer y validar la configuración antes de conectar a cualquier servicio
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Configuración inválida: %v", err)
+	}
+
+	// Configurar el trazado distribuido (los spans no se exportan si no hay colector configurado)
+	tracerProvider, err := tracing.Init("banca-en-linea-backend", cfg.OTLPEndpoint)
+	if err != nil {
+		log.Fatalf("Error configurando el trazado: %v", err)
+	}
+	defer tracerProvider.Shutdown(context.Background())
+	if cfg.OTLPEndpoint == "" {
+		log.Println("Advertencia: OTEL_EXPORTER_OTLP_ENDPOINT no configurada, las trazas no se exportan")
+	}
+
 	// Obtener configuración de la base de datos
-	config := database.GetConfigFromEnv()
+	dbConfig := &database.Config{
+		Host:     cfg.PostgresHost,
+		Port:     cfg.PostgresPort,
+		User:     cfg.PostgresUser,
+		Password: cfg.PostgresPassword,
+		DBName:   cfg.PostgresDB,
+		SSLMode:  cfg.PostgresSSLMode,
+	}
 	log.Printf("Conectando a la base de datos: %s@%s:%s/%s",
-		config.User, config.Host, config.Port, config.DBName)
+		dbConfig.User, dbConfig.Host, dbConfig.Port, dbConfig.DBName)
+
+	// Cancelar el arranque y, más adelante, detener el servidor al recibir SIGTERM o SIGINT
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
 
-	// Conectar a la base de datos
-	dbConn, err := database.Connect(config)
+	// Conectar a la base de datos, reintentando mientras PostgreSQL termina de arrancar
+	dbConn, err := connectDatabase(ctx, dbConfig)
 	if err != nil {
 		log.Fatalf("Error conectando a la base de datos: %v", err)
 	}
@@ -49,36 +123,183 @@ func main() {
 		log.Fatalf("Error ejecutando migraciones: %v", err)
 	}
 
-	// Inicializar TigerBeetle service (usando stub para desarrollo)
-	// log.Println("Inicializando servicio TigerBeetle...")
-	// tbService := tigerbeetle.NewServiceStub()
-	// defer tbService.Close()
+	// Conectar a TigerBeetle; si no está disponible se reintenta en segundo plano y, mientras tanto,
+	// las operaciones financieras fallan en lugar de detener el arranque. shutdown cierra la conexión.
+	initTigerBeetle(cfg.TigerBeetleAddress)
+
+	// Todos los servicios comparten un circuit breaker, de modo que con TigerBeetle caído las
+	// llamadas fallan de inmediato y /health reporta el estado del circuito
+	tbService := tigerbeetle.NewCircuitBreakerService(newTigerBeetleService())
+
+	// Crear servicio de monitoreo de transacciones en tiempo real
+	monitoringService := monitoring.NewMonitoringService()
 
-	// Inicializar cuentas maestras de TigerBeetle
-	// if err := tbService.InitializeMasterAccounts(); err != nil {
-	//	log.Fatalf("Error inicializando cuentas maestras TigerBeetle: %v", err)
-	// }
+	// Crear distribuidor de cambios de balance para los streams de los usuarios
+	balanceBroker := monitoring.NewBalanceBroker()
+
+	// Crear servicio de webhooks y entregar los eventos de transacción en segundo plano
+	webhookService := db.NewWebhookService(db.NewWebhookRepository(dbConn))
+	go webhookService.Run(ctx)
 
 	// Crear repositorio y servicio de usuarios
-	userRepo := db.NewUserRepository(dbConn)
-	userService := db.NewUserService(userRepo, nil) // Pasar nil temporalmente
+	userRepo := db.NewTracedUserRepository(db.NewUserRepository(dbConn))
+	beneficiaryRepo := db.NewBeneficiaryRepository(dbConn)
+	bankAccountRepo := db.NewBankAccountRepository(dbConn)
+	transactionRepo := db.NewTransactionRepository(dbConn)
+	transferIDs := db.NewRandomTransferIDGenerator()
+	refreshTokenRepo := db.NewRefreshTokenRepository(dbConn)
+	userServiceOpts := []db.UserServiceOption{
+		db.WithBankAccountRepository(bankAccountRepo),
+		db.WithTransactionRepository(transactionRepo),
+		db.WithVelocityChecker(fraud.NewVelocityChecker(transactionRepo)),
+		db.WithBeneficiaryRepository(beneficiaryRepo),
+		db.WithUserPreferencesRepository(db.NewUserPreferencesRepository(dbConn)),
+		db.WithNotificationPreferenceRepository(db.NewNotificationPreferenceRepository(dbConn)),
+		db.WithTransactionPublisher(monitoringService),
+		db.WithTransactionPublisher(webhookService),
+		db.WithBalancePublisher(balanceBroker),
+		db.WithAuditLogRepository(db.NewAuditLogRepository(dbConn)),
+		db.WithNotificationRepository(db.NewNotificationRepository(dbConn)),
+		db.WithTransferIDGenerator(transferIDs),
+		db.WithPasswordHistoryRepository(db.NewPasswordHistoryRepository(dbConn)),
+		db.WithRefreshTokenRepository(refreshTokenRepo),
+		db.WithFeeSchedule(fee.NewScheduleFromEnv()),
+	}
+
+	// Guardar en Redis los balances consultados a TigerBeetle y los resúmenes de gastos si hay
+	// Redis configurado
+	redisClient, err := cache.NewRedisClientFromEnv()
+	switch {
+	case err == nil:
+		log.Println("Usando Redis como caché de balances y resúmenes de gastos")
+		defer redisClient.Close()
+		userServiceOpts = append(userServiceOpts,
+			db.WithBalanceCache(cache.NewRedisBalanceCache(redisClient)),
+			db.WithSpendingSummaryCache(cache.NewRedisSpendingSummaryCache(redisClient)))
+	case errors.Is(err, cache.ErrNoRedisConfigured):
+		log.Println("Advertencia: REDIS_URL no configurada, los balances se consultan siempre a TigerBeetle")
+	default:
+		log.Fatalf("Error configurando Redis: %v", err)
+	}
+
+	// Avisar por email de los retiros y transferencias grandes
+	emailSender := email.NewSenderFromEnv()
+	userServiceOpts = append(userServiceOpts, db.WithTransactionAlerts(email.NewNotificationService(emailSender), handlers.LargeTransferThresholdFromEnv()))
+
+	// Exigir KYC aprobado para retiros y transferencias grandes
+	userServiceOpts = append(userServiceOpts, db.WithKYCThreshold(compliance.KYCThresholdFromEnv()))
+
+	// Aplicar el límite antilavado (AML) a retiros y transferencias si está configurado
+	if amlLimit := compliance.DailyLimitFromEnv(); amlLimit > 0 {
+		userServiceOpts = append(userServiceOpts, db.WithComplianceService(compliance.NewComplianceService(transactionRepo, amlLimit)))
+	} else {
+		log.Println("Advertencia: AML_DAILY_LIMIT_CENTS no configurado, el control antilavado está deshabilitado")
+	}
+
+	userService := db.NewUserService(userRepo, tbService, userServiceOpts...)
+
+	// Crear repositorio de llaves de idempotencia para las operaciones financieras
+	idempotencyRepo := db.NewIdempotencyKeyRepository(dbConn)
+	go cleanupExpired("llaves de idempotencia", idempotencyRepo, time.Hour)
+
+	// Crear servicio de cuentas bancarias
+	bankAccountService := db.NewBankAccountService(bankAccountRepo, transactionRepo, tbService, transferIDs)
+
+	// Crear servicio de conciliación de balances y programarlo cada noche
+	reconciliationService := db.NewReconciliationService(userRepo, transactionRepo, db.NewReconciliationRepository(dbConn), tbService)
+	go runNightly("conciliación de balances", reconciliationHour, func(ctx context.Context) error {
+		_, err := reconciliationService.Reconcile(ctx)
+		return err
+	})
+
+	// Marcar cada noche las cuentas sin transacciones en el último año y avisar a sus titulares
+	dormancyService := db.NewAccountDormancyService(db.NewAccountDormancyRepository(dbConn), userRepo, email.NewNotificationService(emailSender))
+	go runNightly("detección de cuentas durmientes", dormancyHour, func(ctx context.Context) error {
+		_, err := dormancyService.DetectDormant(ctx)
+		return err
+	})
+
+	// Crear servicio de transferencias programadas y ejecutar las vencidas cada minuto
+	scheduledTransferService := db.NewScheduledTransferService(db.NewScheduledTransferRepository(dbConn), userService)
+	go runPeriodically("transferencias programadas", time.Minute, scheduledTransferService.Execute)
+
+	// Crear servicio de solicitudes de pago entre usuarios
+	paymentRequestService := db.NewPaymentRequestService(db.NewPaymentRequestRepository(dbConn), userRepo, userService)
+
+	// Crear servicio de plantillas de transferencia
+	transferTemplateService := db.NewTransferTemplateService(db.NewTransferTemplateRepository(dbConn), userRepo, userService)
 
 	// Crear servicio de autenticación
-	authService := auth.NewService()
+	tokenBlacklistRepo := db.NewTokenBlacklistRepository(dbConn)
+	go cleanupExpired("tokens revocados", tokenBlacklistRepo, 15*time.Minute)
+	authOpts := []auth.ServiceOption{
+		auth.WithJWTSecret(cfg.JWTSecret),
+		auth.WithRefreshTokenStore(refreshTokenRepo),
+		auth.WithTokenBlacklist(tokenBlacklistRepo),
+		auth.WithEmailVerificationStore(db.NewEmailVerificationTokenRepository(dbConn)),
+		auth.WithPasswordResetStore(db.NewPasswordResetTokenRepository(dbConn)),
+	}
+
+	// Firmar los JWT con RS256 si hay llaves RSA configuradas
+	keyProvider, err := auth.NewRSAKeyProviderFromEnv()
+	switch {
+	case err == nil:
+		log.Println("Firmando JWT con RS256")
+		authOpts = append(authOpts, auth.WithKeyProvider(keyProvider))
+	case errors.Is(err, auth.ErrNoKeyConfigured):
+		log.Println("Advertencia: no hay llaves RSA configuradas, los JWT se firman con HS256")
+	default:
+		log.Fatalf("Error cargando llaves RSA: %v", err)
+	}
+	authService := auth.NewService(authOpts...)
+
+	// Crear servicio de email
+	emailService := email.NewService(emailSender, authService)
+
+	// Crear servicio de códigos OTP para confirmar las transferencias grandes
+	otpService := db.NewOTPService(db.NewOTPCodeRepository(dbConn), userRepo, emailService)
+	largeTransfers := handlers.NewLargeTransferGuard(otpService, handlers.LargeTransferThresholdFromEnv())
 
 	// Crear handler de autenticación
-	authHandler := handlers.NewAuthHandler(userService, authService)
+	authHandler := handlers.NewAuthHandler(userService, authService, emailService)
+
+	// Crear handler de administración
+	adminHandler := handlers.NewAdminHandler(userService, bankAccountService, authService)
+
+	// Crear handler de monitoreo
+	monitoringHandler := handlers.NewMonitoringHandler(monitoringService)
+
+	disputeRepo := db.NewDisputeRepository(dbConn)
 
 	// Crear servidor
 	server := &Server{
-		userService: userService,
-		// tigerBeetleClient: tbService, // Comentado temporalmente
-		authService: authService,
-		authHandler: authHandler,
+		userService:           userService,
+		authService:           authService,
+		authHandler:           authHandler,
+		adminHandler:          adminHandler,
+		monitoringHandler:     monitoringHandler,
+		transactionHandler:    handlers.NewTransactionHandler(userService, db.NewTransactionService(transactionRepo, disputeRepo, userService, cfg.ReversalWindow)),
+		accountHandler:        handlers.NewAccountHandler(userService, bankAccountService, largeTransfers),
+		idempotencyStore:      idempotencyRepo,
+		activityLog:           db.NewActivityLogRepository(dbConn),
+		reconciliationHandler: handlers.NewReconciliationHandler(reconciliationService),
+		scheduledTransfers:    handlers.NewScheduledTransferHandler(scheduledTransferService, largeTransfers),
+		paymentRequests:       handlers.NewPaymentRequestHandler(paymentRequestService, largeTransfers),
+		beneficiaries:         handlers.NewBeneficiaryHandler(db.NewBeneficiaryService(beneficiaryRepo, userRepo)),
+		transferTemplates:     handlers.NewTransferTemplateHandler(transferTemplateService, largeTransfers),
+		splitTransfers:        handlers.NewSplitTransferHandler(userService, largeTransfers),
+		disputes:              handlers.NewDisputeHandler(db.NewDisputeService(disputeRepo)),
+		webhooks:              handlers.NewWebhookHandler(webhookService),
+		balanceStream:         handlers.NewBalanceStreamHandler(balanceBroker),
+		largeTransfers:        largeTransfers,
+		metricsRegistry:       newMetricsRegistry(),
+		dbConn:                dbConn,
+		corsConfig:            middleware.NewCORSConfigFromEnv(),
+		logBodies:             cfg.LogLevel == "debug",
 	}
 
 	// Verificar si se debe inicializar con datos de prueba
-	if shouldSeedData() {
+	if cfg.SeedData {
 		log.Println("Inicializando datos de prueba...")
 		if err := database.SeedDatabase(userService, "./datos-prueba-HNL (1).json"); err != nil {
 			log.Printf("Advertencia: Error inicializando datos de prueba: %v", err)
@@ -90,29 +311,152 @@ func main() {
 	// Configurar rutas
 	router := server.setupRoutes()
 
+	// Exponer las métricas en un puerto separado que no se publica
+	go serveMetrics(server.metricsRegistry, cfg.MetricsPort)
+
 	// Obtener puerto del servidor
-	port := getServerPort()
+	port := cfg.Port
 	log.Printf("Servidor iniciado en puerto %s", port)
 	log.Printf("API disponible en: http://localhost:%s", port)
 
 	// Iniciar servidor
-	if err := http.ListenAndServe(":"+port, router); err != nil {
-		log.Fatalf("Error iniciando servidor: %v", err)
+	httpServer := &http.Server{
+		Addr:         ":" + port,
+		Handler:      otelhttp.NewHandler(router, "http.server"),
+		WriteTimeout: serverWriteTimeout,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Error iniciando servidor: %v", err)
+		}
+	case <-ctx.Done():
+		stop()
+		shutdown(httpServer, dbConn)
+	}
+}
+
+const (
+	// dbConnectMaxAttempts es la cantidad máxima de intentos de conexión a PostgreSQL al arrancar
+	dbConnectMaxAttempts = 10
+
+	// dbConnectInitialDelay es la espera antes del primer reintento; se duplica en cada intento
+	dbConnectInitialDelay = time.Second
+
+	// dbConnectMaxDelay es la espera máxima entre intentos de conexión
+	dbConnectMaxDelay = 30 * time.Second
+)
+
+// connectDatabase conecta a PostgreSQL reintentando con backoff exponencial (1s, 2s, 4s, ...
+// hasta dbConnectMaxDelay) para tolerar que la base de datos arranque después que el servicio.
+// Se rinde tras dbConnectMaxAttempts intentos o si se cancela ctx.
+func connectDatabase(ctx context.Context, config *database.Config) (*sql.DB, error) {
+	delay := dbConnectInitialDelay
+	for attempt := 1; ; attempt++ {
+		dbConn, err := database.Connect(config)
+		if err == nil {
+			return dbConn, nil
+		}
+		if attempt == dbConnectMaxAttempts {
+			return nil, err
+		}
+
+		logger.Info("Reintentando conexión a la base de datos",
+			zap.Int("attempt", attempt),
+			zap.Duration("retry_in", delay),
+			zap.Error(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > dbConnectMaxDelay {
+			delay = dbConnectMaxDelay
+		}
 	}
 }
 
+const (
+	// serverWriteTimeout es el tiempo máximo para escribir una respuesta
+	serverWriteTimeout = 30 * time.Second
+
+	// shutdownTimeout es el tiempo máximo para terminar las solicitudes en curso al apagar
+	shutdownTimeout = 30 * time.Second
+)
+
+// shutdown detiene el servidor esperando las solicitudes en curso, para no dejar operaciones
+// financieras a medias, y luego cierra TigerBeetle y el pool de PostgreSQL
+func shutdown(httpServer *http.Server, dbConn *sql.DB) {
+	logger.Info("Señal de apagado recibida, esperando las solicitudes en curso",
+		zap.Duration("timeout", shutdownTimeout))
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := httpServer.Shutdown(ctx); err != nil {
+		logger.Error("Error esperando las solicitudes en curso", zap.Error(err), zap.Duration("elapsed", time.Since(start)))
+	} else {
+		logger.Info("Solicitudes en curso terminadas", zap.Duration("elapsed", time.Since(start)))
+	}
+
+	start = time.Now()
+	closeTigerBeetle()
+	logger.Info("Cliente de TigerBeetle cerrado", zap.Duration("elapsed", time.Since(start)))
+
+	start = time.Now()
+	if err := dbConn.Close(); err != nil {
+		logger.Error("Error cerrando el pool de PostgreSQL", zap.Error(err))
+	} else {
+		logger.Info("Pool de PostgreSQL cerrado", zap.Duration("elapsed", time.Since(start)))
+	}
+
+	logger.Info("Servidor detenido")
+}
+
 func (s *Server) setupRoutes() *mux.Router {
 	router := mux.NewRouter()
+	corsMiddleware := middleware.CORS(s.corsConfig)
+
+	// Headers de seguridad en todas las respuestas, antes del resto de middleware (incluido CORS)
+	router.Use(middleware.SecurityHeaders())
+
+	// Tiempo máximo de cada solicitud
+	router.Use(timeoutMiddleware)
+
+	// Tamaño máximo del cuerpo de las solicitudes
+	router.Use(bodyLimitMiddleware)
+
+	// ID de correlación para agrupar los logs de cada solicitud
+	router.Use(middleware.CorrelationID())
+
+	// Recuperar los panics de los handlers y responder 500
+	router.Use(middleware.Recovery(logger))
 
 	// Middleware para logging
-	router.Use(loggingMiddleware)
+	router.Use(newLoggingMiddleware(s.logBodies))
+	router.Use(middleware.PrometheusMiddleware(s.metricsRegistry))
 	router.Use(corsMiddleware)
 
+	// Dirección del cliente para la auditoría de operaciones financieras
+	router.Use(middleware.RemoteAddr)
+
 	// Crear rate limiter para autenticación
 	authRateLimiter := middleware.CreateAuthRateLimiter()
 
-	// Rutas de la API
-	api := router.PathPrefix("/api/v1").Subrouter()
+	// Rutas de la API. Las rutas bajo /api/ sin versión están obsoletas: las nuevas se registran
+	// en un subrouter versionado y los cambios incompatibles van en /api/v2.
+	api := middleware.NewVersionedRouter(router, "1")
 	api.Use(corsMiddleware) // Aplicar CORS también al subrouter de API
 
 	// Rutas de autenticación (públicas con rate limiting)
@@ -123,32 +467,142 @@ func (s *Server) setupRoutes() *mux.Router {
 	authRoutes.HandleFunc("/register", s.handleOptions).Methods("OPTIONS")
 	authRoutes.HandleFunc("/login", s.authHandler.Login).Methods("POST")
 	authRoutes.HandleFunc("/login", s.handleOptions).Methods("OPTIONS")
+	authRoutes.HandleFunc("/refresh", s.authHandler.Refresh).Methods("POST")
+	authRoutes.HandleFunc("/refresh", s.handleOptions).Methods("OPTIONS")
 	authRoutes.HandleFunc("/logout", s.authHandler.Logout).Methods("POST")
 	authRoutes.HandleFunc("/logout", s.handleOptions).Methods("OPTIONS")
+	authRoutes.HandleFunc("/verify-email", s.authHandler.VerifyEmail).Methods("POST")
+	authRoutes.HandleFunc("/verify-email", s.handleOptions).Methods("OPTIONS")
+	authRoutes.HandleFunc("/forgot-password", s.authHandler.ForgotPassword).Methods("POST")
+	authRoutes.HandleFunc("/forgot-password", s.handleOptions).Methods("OPTIONS")
+	authRoutes.HandleFunc("/reset-password", s.authHandler.ResetPassword).Methods("POST")
+	authRoutes.HandleFunc("/reset-password", s.handleOptions).Methods("OPTIONS")
+	authRoutes.HandleFunc("/totp/confirm", s.authHandler.ConfirmTOTP).Methods("POST")
+	authRoutes.HandleFunc("/totp/confirm", s.handleOptions).Methods("OPTIONS")
+
+	// Consulta pública de cuentas (sin autenticación, con rate limiting estricto).
+	// Debe registrarse antes de las rutas protegidas porque estas usan un prefijo vacío.
+	lookupRateLimiter := middleware.CreateLookupRateLimiter()
+	api.Handle("/accounts/lookup", lookupRateLimiter.Middleware(http.HandlerFunc(s.lookupAccount))).Methods("GET")
 
 	// Rutas protegidas
 	protectedRoutes := api.PathPrefix("").Subrouter()
 	protectedRoutes.Use(middleware.AuthMiddleware(s.authService))
+	protectedRoutes.Use(middleware.ActivityLogger(s.activityLog))
+
+	// Las rutas GET que retornan listas se comprimen con gzip si el cliente lo acepta
+	compress := middleware.GzipCompression()
 
 	// Rutas de usuarios (protegidas)
 	protectedRoutes.HandleFunc("/users", s.createUser).Methods("POST")
 	protectedRoutes.HandleFunc("/users/{id}", s.getUser).Methods("GET")
-	protectedRoutes.HandleFunc("/users/{id}/balance", s.getUserBalance).Methods("GET")
-	protectedRoutes.HandleFunc("/users", s.listUsers).Methods("GET")
-
-	// Rutas de transacciones (protegidas)
-	protectedRoutes.HandleFunc("/users/{id}/deposit", s.depositToUser).Methods("POST")
-	protectedRoutes.HandleFunc("/users/{id}/withdraw", s.withdrawFromUser).Methods("POST")
-	protectedRoutes.HandleFunc("/transfer", s.transferBetweenUsers).Methods("POST")
+	protectedRoutes.Handle("/users/{id}/balance",
+		middleware.ResponseCache(5*time.Second)(http.HandlerFunc(s.getUserBalance))).Methods("GET")
+	protectedRoutes.HandleFunc("/users/{id}/stats", s.getUserStats).Methods("GET")
+	protectedRoutes.Handle("/users/{id}/accounts", compress(http.HandlerFunc(s.accountHandler.ListAccounts))).Methods("GET")
+	protectedRoutes.HandleFunc("/users/{id}/accounts", s.accountHandler.CreateAccount).Methods("POST")
+	protectedRoutes.HandleFunc("/accounts/{id}", s.accountHandler.GetAccount).Methods("GET")
+	protectedRoutes.HandleFunc("/accounts/{id}", s.accountHandler.UpdateAccount).Methods("PATCH")
+	protectedRoutes.HandleFunc("/accounts/{id}", s.accountHandler.DeleteAccount).Methods("DELETE")
+	protectedRoutes.HandleFunc("/users/{id}/preferences", s.getUserPreferences).Methods("GET")
+	protectedRoutes.HandleFunc("/users/{id}/preferences", s.updateUserPreferences).Methods("PUT")
+	protectedRoutes.HandleFunc("/users/{id}/notification-preferences", s.getNotificationPreferences).Methods("GET")
+	protectedRoutes.HandleFunc("/users/{id}/notification-preferences", s.updateNotificationPreferences).Methods("PUT")
+	protectedRoutes.HandleFunc("/users/{id}/change-password", s.authHandler.ChangePassword).Methods("POST")
+	protectedRoutes.Handle("/users", middleware.RequireRole(models.RoleAdmin)(compress(http.HandlerFunc(s.listUsers)))).Methods("GET")
+
+	// Rutas de transacciones (protegidas, con rate limiting por usuario, email verificado
+	// y soporte del header X-Idempotency-Key para reintentos seguros)
+	financialRateLimiter := middleware.CreateFinancialRateLimiter()
+	requireEmailVerified := middleware.RequireEmailVerified(s.userService)
+	idempotent := middleware.Idempotency(s.idempotencyStore)
+	financial := func(handler http.HandlerFunc) http.Handler {
+		return financialRateLimiter.Middleware(requireEmailVerified(idempotent(handler)))
+	}
+	protectedRoutes.Handle("/users/{id}/deposit", financial(s.depositToUser)).Methods("POST")
+	protectedRoutes.Handle("/users/{id}/withdraw", financial(s.withdrawFromUser)).Methods("POST")
+	protectedRoutes.Handle("/transfer", financial(s.transferBetweenUsers)).Methods("POST")
+	protectedRoutes.Handle("/users/{id}/transfer-to-email", financial(s.transferToEmail)).Methods("POST")
+	protectedRoutes.Handle("/accounts/{id}/deposit", financial(s.accountHandler.Deposit)).Methods("POST")
+	protectedRoutes.Handle("/accounts/{id}/withdraw", financial(s.accountHandler.Withdraw)).Methods("POST")
+	protectedRoutes.Handle("/accounts/{id}/transfer", financial(s.accountHandler.Transfer)).Methods("POST")
+	protectedRoutes.Handle("/users/{id}/split-transfers", financial(s.splitTransfers.Split)).Methods("POST")
+	protectedRoutes.Handle("/users/{id}/scheduled-transfers", financial(s.scheduledTransfers.Schedule)).Methods("POST")
+	protectedRoutes.Handle("/users/{id}/scheduled-transfers", compress(http.HandlerFunc(s.scheduledTransfers.List))).Methods("GET")
+	protectedRoutes.HandleFunc("/users/{id}/scheduled-transfers/{transferId}", s.scheduledTransfers.Get).Methods("GET")
+	protectedRoutes.HandleFunc("/users/{id}/scheduled-transfers/{transferId}", s.scheduledTransfers.Update).Methods("PATCH")
+	protectedRoutes.HandleFunc("/users/{id}/scheduled-transfers/{transferId}", s.scheduledTransfers.Cancel).Methods("DELETE")
+	protectedRoutes.HandleFunc("/payment-requests", s.paymentRequests.Create).Methods("POST")
+	protectedRoutes.Handle("/payment-requests", compress(http.HandlerFunc(s.paymentRequests.List))).Methods("GET")
+	protectedRoutes.Handle("/payment-requests/{paymentRequestId}/approve", financial(s.paymentRequests.Approve)).Methods("POST")
+	protectedRoutes.HandleFunc("/payment-requests/{paymentRequestId}/reject", s.paymentRequests.Reject).Methods("POST")
+	protectedRoutes.HandleFunc("/users/{id}/export", s.exportUserData).Methods("GET")
+	protectedRoutes.HandleFunc("/users/{id}/data", s.eraseUserData).Methods("DELETE")
+	protectedRoutes.HandleFunc("/users/{id}/phone", s.updatePhone).Methods("PATCH")
+	protectedRoutes.HandleFunc("/users/{id}/date-of-birth", s.updateDateOfBirth).Methods("PATCH")
+	protectedRoutes.HandleFunc("/users/{id}/kyc", s.getKYCStatus).Methods("GET")
+	protectedRoutes.Handle("/users/{id}/sessions", compress(http.HandlerFunc(s.authHandler.ListSessions))).Methods("GET")
+	protectedRoutes.HandleFunc("/users/{id}/sessions/{sessionId}", s.authHandler.RevokeSession).Methods("DELETE")
+	protectedRoutes.Handle("/users/{id}/beneficiaries", compress(http.HandlerFunc(s.beneficiaries.List))).Methods("GET")
+	protectedRoutes.HandleFunc("/users/{id}/beneficiaries", s.beneficiaries.Create).Methods("POST")
+	protectedRoutes.HandleFunc("/users/{id}/beneficiaries/{beneficiaryId}", s.beneficiaries.Delete).Methods("DELETE")
+	protectedRoutes.Handle("/users/{id}/transfer-templates", compress(http.HandlerFunc(s.transferTemplates.List))).Methods("GET")
+	protectedRoutes.HandleFunc("/users/{id}/transfer-templates", s.transferTemplates.Create).Methods("POST")
+	protectedRoutes.HandleFunc("/users/{id}/transfer-templates/{templateId}", s.transferTemplates.Delete).Methods("DELETE")
+	protectedRoutes.Handle("/users/{id}/transfer-templates/{templateId}/execute", financial(s.transferTemplates.Execute)).Methods("POST")
+	protectedRoutes.Handle("/users/{id}/webhooks", compress(http.HandlerFunc(s.webhooks.List))).Methods("GET")
+	protectedRoutes.HandleFunc("/users/{id}/webhooks", s.webhooks.Create).Methods("POST")
+	protectedRoutes.HandleFunc("/users/{id}/webhooks/{webhookId}", s.webhooks.Delete).Methods("DELETE")
+	protectedRoutes.HandleFunc("/users/{id}/balance-stream", s.balanceStream.Stream).Methods("GET")
+	protectedRoutes.Handle("/users/{id}/transactions", compress(http.HandlerFunc(s.transactionHandler.ListTransactions))).Methods("GET")
+	protectedRoutes.HandleFunc("/users/{id}/transactions/categories", s.transactionHandler.SpendingByCategory).Methods("GET")
+	protectedRoutes.HandleFunc("/users/{id}/analytics/spending", s.transactionHandler.SpendingSummary).Methods("GET")
+	protectedRoutes.HandleFunc("/users/{id}/transactions/{transactionId}/category", s.transactionHandler.UpdateCategory).Methods("PATCH")
+	protectedRoutes.Handle("/users/{id}/transactions/{transactionId}/reverse", financial(s.transactionHandler.Reverse)).Methods("POST")
+	protectedRoutes.HandleFunc("/users/{id}/disputes", s.disputes.Create).Methods("POST")
+	protectedRoutes.Handle("/users/{id}/disputes", compress(http.HandlerFunc(s.disputes.List))).Methods("GET")
+
+	// Congelamiento de cuentas (protegidas, cumplimiento o administradores)
+	complianceRoutes := protectedRoutes.PathPrefix("/admin/users/{id}").Subrouter()
+	complianceRoutes.Use(middleware.RequireRole(models.RoleCompliance, models.RoleAdmin))
+	complianceRoutes.HandleFunc("/freeze", s.adminHandler.FreezeAccount).Methods("POST")
+	complianceRoutes.HandleFunc("/unfreeze", s.adminHandler.UnfreezeAccount).Methods("POST")
+
+	// Resolución de disputas (protegidas, cumplimiento o administradores)
+	disputeRoutes := protectedRoutes.PathPrefix("/admin/disputes").Subrouter()
+	disputeRoutes.Use(middleware.RequireRole(models.RoleCompliance, models.RoleAdmin))
+	disputeRoutes.HandleFunc("/{disputeId}/resolve", s.disputes.Resolve).Methods("POST")
+
+	// Rutas de administración (protegidas, solo administradores)
+	adminRoutes := protectedRoutes.PathPrefix("/admin").Subrouter()
+	adminRoutes.Use(middleware.RequireRole(models.RoleAdmin))
+	adminRoutes.Handle("/users", compress(http.HandlerFunc(s.listUsers))).Methods("GET")
+	adminRoutes.HandleFunc("/users", s.adminHandler.CreateUser).Methods("POST")
+	adminRoutes.HandleFunc("/users/{id}/flags", s.adminHandler.UpdateUserFlags).Methods("PATCH")
+	adminRoutes.HandleFunc("/users/{id}/kyc", s.adminHandler.UpdateKYC).Methods("POST")
+	adminRoutes.Handle("/users/{id}/activity", compress(http.HandlerFunc(s.adminHandler.GetUserActivity))).Methods("GET")
+	adminRoutes.HandleFunc("/accounts/{id}/recalculate-balance", s.adminHandler.RecalculateBalance).Methods("POST")
+	adminRoutes.HandleFunc("/accounts/{id}/overdraft", s.adminHandler.UpdateOverdraft).Methods("PATCH")
+	adminRoutes.HandleFunc("/transactions/stream", s.monitoringHandler.StreamTransactions).Methods("GET")
+	adminRoutes.HandleFunc("/reconcile", s.reconciliationHandler.Reconcile).Methods("POST")
 
 	// Ruta para obtener información del usuario autenticado
 	protectedRoutes.HandleFunc("/auth/me", s.authHandler.Me).Methods("GET")
 
-	// Ruta de salud (pública)
-	api.HandleFunc("/health", s.healthCheck).Methods("GET")
+	// Rutas de configuración de TOTP (protegidas)
+	protectedRoutes.HandleFunc("/auth/totp/enable", s.authHandler.EnableTOTP).Methods("POST")
+	protectedRoutes.HandleFunc("/auth/totp/verify", s.authHandler.VerifyTOTP).Methods("POST")
+
+	// Llaves públicas para validar los JWT (pública)
+	router.HandleFunc("/.well-known/jwks.json", s.authHandler.JWKS).Methods("GET")
 
-	// Ruta de salud adicional sin prefijo para facilidad de acceso
-	router.HandleFunc("/health", s.healthCheck).Methods("GET")
+	// Sondas de liveness y readiness (públicas)
+	router.HandleFunc("/healthz/live", s.livenessCheck).Methods("GET")
+	router.HandleFunc("/healthz/ready", s.readinessCheck).Methods("GET")
+
+	// Rutas de salud anteriores, alias de la sonda de readiness por compatibilidad
+	api.HandleFunc("/health", s.readinessCheck).Methods("GET")
+	router.HandleFunc("/health", s.readinessCheck).Methods("GET")
 
 	return router
 }
@@ -158,14 +612,22 @@ func (s *Server) setupRoutes() *mux.Router {
 func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		middleware.WriteDecodeError(w, r, err)
 		return
 	}
 
 	user, err := s.userService.CreateUserWithAccount(&req)
 	if err != nil {
+		if errors.Is(err, validation.ErrInvalidPhone) {
+			problem.Write(w, http.StatusBadRequest, "Invalid phone", err.Error(), r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, validation.ErrUnderage) {
+			problem.Write(w, http.StatusBadRequest, "Invalid date of birth", err.Error(), r.URL.Path, nil)
+			return
+		}
 		log.Printf("Error creating user: %v", err)
-		http.Error(w, "Error creating user", http.StatusInternalServerError)
+		problem.Write(w, http.StatusInternalServerError, "Error creating user", "", r.URL.Path, nil)
 		return
 	}
 
@@ -178,18 +640,18 @@ func (s *Server) getUser(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userID, err := uuid.Parse(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		problem.Write(w, http.StatusBadRequest, "Invalid user ID", "", r.URL.Path, nil)
 		return
 	}
 
 	user, err := s.userService.GetUser(userID)
 	if err != nil {
-		if err.Error() == "user not found" {
-			http.Error(w, "User not found", http.StatusNotFound)
+		if errors.Is(err, db.ErrUserNotFound) {
+			problem.Write(w, http.StatusNotFound, "User not found", "", r.URL.Path, nil)
 			return
 		}
 		log.Printf("Error getting user: %v", err)
-		http.Error(w, "Error getting user", http.StatusInternalServerError)
+		problem.Write(w, http.StatusInternalServerError, "Error getting user", "", r.URL.Path, nil)
 		return
 	}
 
@@ -201,18 +663,18 @@ func (s *Server) getUserBalance(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userID, err := uuid.Parse(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		problem.Write(w, http.StatusBadRequest, "Invalid user ID", "", r.URL.Path, nil)
 		return
 	}
 
 	user, balance, err := s.userService.GetUserWithBalance(userID)
 	if err != nil {
-		if err.Error() == "user not found" {
-			http.Error(w, "User not found", http.StatusNotFound)
+		if errors.Is(err, db.ErrUserNotFound) {
+			problem.Write(w, http.StatusNotFound, "User not found", "", r.URL.Path, nil)
 			return
 		}
 		log.Printf("Error getting user balance: %v", err)
-		http.Error(w, "Error getting user balance", http.StatusInternalServerError)
+		problem.Write(w, http.StatusInternalServerError, "Error getting user balance", "", r.URL.Path, nil)
 		return
 	}
 
@@ -225,13 +687,192 @@ func (s *Server) getUserBalance(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+func (s *Server) getUserStats(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := uuid.Parse(vars["id"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid user ID", "", r.URL.Path, nil)
+		return
+	}
+
+	// Las estadísticas solo las ven su titular y los administradores
+	if !canAccessUser(r, userID) && !middleware.HasRole(r.Context(), models.RoleAdmin) {
+		problem.Write(w, http.StatusForbidden, "Forbidden", "", r.URL.Path, nil)
+		return
+	}
+
+	stats, err := s.userService.GetUserStats(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, db.ErrUserNotFound) {
+			problem.Write(w, http.StatusNotFound, "User not found", "", r.URL.Path, nil)
+			return
+		}
+		log.Printf("Error getting user stats: %v", err)
+		problem.Write(w, http.StatusInternalServerError, "Error getting user stats", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+func (s *Server) getUserPreferences(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := uuid.Parse(vars["id"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid user ID", "", r.URL.Path, nil)
+		return
+	}
+
+	if !canAccessUser(r, userID) {
+		problem.Write(w, http.StatusForbidden, "Forbidden", "", r.URL.Path, nil)
+		return
+	}
+
+	prefs, err := s.userService.GetPreferences(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, db.ErrUserNotFound) {
+			problem.Write(w, http.StatusNotFound, "User not found", "", r.URL.Path, nil)
+			return
+		}
+		log.Printf("Error getting user preferences: %v", err)
+		problem.Write(w, http.StatusInternalServerError, "Error getting user preferences", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prefs)
+}
+
+func (s *Server) updateUserPreferences(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := uuid.Parse(vars["id"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid user ID", "", r.URL.Path, nil)
+		return
+	}
+
+	if !canAccessUser(r, userID) {
+		problem.Write(w, http.StatusForbidden, "Forbidden", "", r.URL.Path, nil)
+		return
+	}
+
+	var req models.UpdateUserPreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	if req.Language != nil && (len(*req.Language) < 2 || len(*req.Language) > 5) {
+		problem.Write(w, http.StatusBadRequest, "Invalid language", "", r.URL.Path, nil)
+		return
+	}
+
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || len(*req.Timezone) > 50 {
+			problem.Write(w, http.StatusBadRequest, "Invalid timezone", "", r.URL.Path, nil)
+			return
+		}
+	}
+
+	prefs, err := s.userService.UpdatePreferences(r.Context(), userID, &req)
+	if err != nil {
+		if errors.Is(err, db.ErrUserNotFound) {
+			problem.Write(w, http.StatusNotFound, "User not found", "", r.URL.Path, nil)
+			return
+		}
+		log.Printf("Error updating user preferences: %v", err)
+		problem.Write(w, http.StatusInternalServerError, "Error updating user preferences", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prefs)
+}
+
+// getNotificationPreferences retorna las preferencias de notificación del usuario
+func (s *Server) getNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := uuid.Parse(vars["id"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid user ID", "", r.URL.Path, nil)
+		return
+	}
+
+	if !canAccessUser(r, userID) {
+		problem.Write(w, http.StatusForbidden, "Forbidden", "", r.URL.Path, nil)
+		return
+	}
+
+	prefs, err := s.userService.GetNotificationPreferences(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, db.ErrUserNotFound) {
+			problem.Write(w, http.StatusNotFound, "User not found", "", r.URL.Path, nil)
+			return
+		}
+		log.Printf("Error getting notification preferences: %v", err)
+		problem.Write(w, http.StatusInternalServerError, "Error getting notification preferences", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prefs)
+}
+
+// updateNotificationPreferences reemplaza todas las preferencias de notificación del usuario
+func (s *Server) updateNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := uuid.Parse(vars["id"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid user ID", "", r.URL.Path, nil)
+		return
+	}
+
+	if !canAccessUser(r, userID) {
+		problem.Write(w, http.StatusForbidden, "Forbidden", "", r.URL.Path, nil)
+		return
+	}
+
+	var req models.UpdateNotificationPreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	if !req.Complete() {
+		problem.Write(w, http.StatusBadRequest, "All notification preferences are required",
+			"email_large_tx, email_login, email_statement and sms_large_tx must be set", r.URL.Path, nil)
+		return
+	}
+
+	prefs, err := s.userService.UpdateNotificationPreferences(r.Context(), userID, &req)
+	if err != nil {
+		if errors.Is(err, db.ErrUserNotFound) {
+			problem.Write(w, http.StatusNotFound, "User not found", "", r.URL.Path, nil)
+			return
+		}
+		log.Printf("Error updating notification preferences: %v", err)
+		problem.Write(w, http.StatusInternalServerError, "Error updating notification preferences", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prefs)
+}
+
+// canAccessUser verifica que el usuario autenticado solo acceda a sus propios datos
+func canAccessUser(r *http.Request, userID uuid.UUID) bool {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	return ok && claims.UserID == userID
+}
+
 func (s *Server) listUsers(w http.ResponseWriter, r *http.Request) {
-	// Obtener parámetros de paginación
+	// Obtener parámetros de paginación: ?after=<uuid del último usuario>&limit=<n>. Con ?q= se
+	// filtran los usuarios cuyo email, nombre o apellido contienen el texto.
 	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
+	afterStr := r.URL.Query().Get("after")
 
 	limit := 10 // default
-	offset := 0 // default
 
 	if limitStr != "" {
 		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
@@ -239,34 +880,208 @@ func (s *Server) listUsers(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-			offset = o
+	var afterID *uuid.UUID
+	if afterStr != "" {
+		after, err := uuid.Parse(afterStr)
+		if err != nil {
+			problem.Write(w, http.StatusBadRequest, "Invalid cursor", "", r.URL.Path, nil)
+			return
 		}
+		afterID = &after
 	}
 
-	users, err := s.userService.ListUsers(limit, offset)
+	var page *models.UserPage
+	var err error
+	if query := strings.TrimSpace(r.URL.Query().Get("q")); query != "" {
+		page, err = s.userService.SearchUsers(query, afterID, limit)
+	} else {
+		page, err = s.userService.ListUsers(afterID, limit)
+	}
 	if err != nil {
 		log.Printf("Error listing users: %v", err)
-		http.Error(w, "Error listing users", http.StatusInternalServerError)
+		problem.Write(w, http.StatusInternalServerError, "Error listing users", "", r.URL.Path, nil)
 		return
 	}
 
-	// Convertir a responses
-	responses := make([]models.UserResponse, len(users))
-	for i, user := range users {
-		responses[i] = user.ToResponse()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// exportUserData descarga como JSON todos los datos que el banco guarda sobre el usuario autenticado
+func (s *Server) exportUserData(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid user ID", "", r.URL.Path, nil)
+		return
+	}
+
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || claims.UserID != userID {
+		problem.Write(w, http.StatusForbidden, "Forbidden", "", r.URL.Path, nil)
+		return
+	}
+
+	export, err := s.userService.ExportUserData(r.Context(), userID)
+	if err != nil {
+		if errors.Is(err, db.ErrUserNotFound) {
+			problem.Write(w, http.StatusNotFound, "User not found", "", r.URL.Path, nil)
+			return
+		}
+		log.Printf("Error exporting user data: %v", err)
+		problem.Write(w, http.StatusInternalServerError, "Error exporting user data", "", r.URL.Path, nil)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(responses)
+	w.Header().Set("Content-Disposition", `attachment; filename="export.json"`)
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(export)
+}
+
+// eraseUserData elimina definitivamente los datos del usuario autenticado (derecho al olvido)
+func (s *Server) eraseUserData(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid user ID", "", r.URL.Path, nil)
+		return
+	}
+
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || claims.UserID != userID {
+		problem.Write(w, http.StatusForbidden, "Forbidden", "", r.URL.Path, nil)
+		return
+	}
+
+	if err := s.userService.EraseUserData(r.Context(), userID); err != nil {
+		if errors.Is(err, db.ErrBalanceRemaining) {
+			problem.Write(w, http.StatusConflict, "Accounts still have a balance", "Withdraw or transfer the remaining balance before deleting your data", r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, db.ErrUserNotFound) {
+			problem.Write(w, http.StatusNotFound, "User not found", "", r.URL.Path, nil)
+			return
+		}
+		log.Printf("Error erasing user data: %v", err)
+		problem.Write(w, http.StatusInternalServerError, "Error deleting user data", "", r.URL.Path, nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// updatePhone cambia el teléfono del usuario autenticado
+func (s *Server) updatePhone(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid user ID", "", r.URL.Path, nil)
+		return
+	}
+
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || claims.UserID != userID {
+		problem.Write(w, http.StatusForbidden, "Forbidden", "", r.URL.Path, nil)
+		return
+	}
+
+	var req models.UpdatePhoneRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	if err := s.userService.UpdatePhone(r.Context(), userID, req.Phone); err != nil {
+		if errors.Is(err, validation.ErrInvalidPhone) {
+			problem.Write(w, http.StatusBadRequest, "Invalid phone", err.Error(), r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, db.ErrUserNotFound) {
+			problem.Write(w, http.StatusNotFound, "User not found", "", r.URL.Path, nil)
+			return
+		}
+		log.Printf("Error updating phone: %v", err)
+		problem.Write(w, http.StatusInternalServerError, "Error updating phone", "", r.URL.Path, nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getKYCStatus retorna el estado de verificación de identidad del usuario autenticado
+func (s *Server) getKYCStatus(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid user ID", "", r.URL.Path, nil)
+		return
+	}
+
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || claims.UserID != userID {
+		problem.Write(w, http.StatusForbidden, "Forbidden", "", r.URL.Path, nil)
+		return
+	}
+
+	user, err := s.userService.GetUser(userID)
+	if err != nil {
+		if errors.Is(err, db.ErrUserNotFound) {
+			problem.Write(w, http.StatusNotFound, "User not found", "", r.URL.Path, nil)
+			return
+		}
+		log.Printf("Error getting user: %v", err)
+		problem.Write(w, http.StatusInternalServerError, "Error getting KYC status", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user.ToKYCStatusResponse())
+}
+
+// updateDateOfBirth cambia la fecha de nacimiento del usuario autenticado
+func (s *Server) updateDateOfBirth(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid user ID", "", r.URL.Path, nil)
+		return
+	}
+
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || claims.UserID != userID {
+		problem.Write(w, http.StatusForbidden, "Forbidden", "", r.URL.Path, nil)
+		return
+	}
+
+	var req models.UpdateDateOfBirthRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	if req.DateOfBirth.IsZero() {
+		problem.Write(w, http.StatusBadRequest, "Date of birth is required", "", r.URL.Path, nil)
+		return
+	}
+
+	if err := s.userService.UpdateDateOfBirth(r.Context(), userID, req.DateOfBirth); err != nil {
+		if errors.Is(err, validation.ErrUnderage) {
+			problem.Write(w, http.StatusBadRequest, "Invalid date of birth", err.Error(), r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, db.ErrUserNotFound) {
+			problem.Write(w, http.StatusNotFound, "User not found", "", r.URL.Path, nil)
+			return
+		}
+		log.Printf("Error updating date of birth: %v", err)
+		problem.Write(w, http.StatusInternalServerError, "Error updating date of birth", "", r.URL.Path, nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
 }
 
 func (s *Server) depositToUser(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userID, err := uuid.Parse(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		problem.Write(w, http.StatusBadRequest, "Invalid user ID", "", r.URL.Path, nil)
 		return
 	}
 
@@ -274,18 +1089,34 @@ func (s *Server) depositToUser(w http.ResponseWriter, r *http.Request) {
 		Amount uint64 `json:"amount"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		middleware.WriteDecodeError(w, r, err)
 		return
 	}
 
 	if req.Amount == 0 {
-		http.Error(w, "Amount must be greater than 0", http.StatusBadRequest)
+		problem.Write(w, http.StatusBadRequest, "Amount must be greater than 0", "", r.URL.Path, nil)
 		return
 	}
 
-	if err := s.userService.DepositToUser(userID, req.Amount); err != nil {
+	if err := s.userService.DepositToUser(r.Context(), userID, req.Amount); err != nil {
+		if errors.Is(err, apperrors.ErrAccountFrozen) {
+			problem.WriteType(w, problem.ErrAccountFrozen, "", r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, apperrors.ErrNoTigerBeetleAccount) {
+			problem.WriteType(w, problem.ErrNoLedgerAccount, "", r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, fraud.ErrVelocityExceeded) {
+			problem.WriteType(w, problem.ErrVelocityExceeded, "", r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, tigerbeetle.ErrTransferMismatch) {
+			problem.WriteType(w, problem.ErrIdempotencyKeyReuse, "", r.URL.Path, nil)
+			return
+		}
 		log.Printf("Error depositing to user: %v", err)
-		http.Error(w, "Error processing deposit", http.StatusInternalServerError)
+		problem.Write(w, http.StatusInternalServerError, "Error processing deposit", "", r.URL.Path, nil)
 		return
 	}
 
@@ -297,7 +1128,7 @@ func (s *Server) withdrawFromUser(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userID, err := uuid.Parse(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		problem.Write(w, http.StatusBadRequest, "Invalid user ID", "", r.URL.Path, nil)
 		return
 	}
 
@@ -305,22 +1136,56 @@ func (s *Server) withdrawFromUser(w http.ResponseWriter, r *http.Request) {
 		Amount uint64 `json:"amount"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		middleware.WriteDecodeError(w, r, err)
 		return
 	}
 
 	if req.Amount == 0 {
-		http.Error(w, "Amount must be greater than 0", http.StatusBadRequest)
+		problem.Write(w, http.StatusBadRequest, "Amount must be greater than 0", "", r.URL.Path, nil)
 		return
 	}
 
-	if err := s.userService.WithdrawFromUser(userID, req.Amount); err != nil {
-		if err.Error() == "insufficient funds" {
-			http.Error(w, "Insufficient funds", http.StatusBadRequest)
+	if err := s.userService.WithdrawFromUser(r.Context(), userID, req.Amount); err != nil {
+		if errors.Is(err, apperrors.ErrAccountFrozen) {
+			problem.WriteType(w, problem.ErrAccountFrozen, "", r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, apperrors.ErrNoTigerBeetleAccount) {
+			problem.WriteType(w, problem.ErrNoLedgerAccount, "", r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, fraud.ErrVelocityExceeded) {
+			problem.WriteType(w, problem.ErrVelocityExceeded, "", r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, compliance.ErrBlocked) {
+			problem.WriteType(w, problem.ErrComplianceBlocked, "", r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, compliance.ErrKYCRequired) {
+			problem.WriteType(w, problem.ErrKYCRequired, "Complete identity verification to move this amount", r.URL.Path, nil)
+			return
+		}
+		var fundsErr *db.InsufficientFundsError
+		if errors.As(err, &fundsErr) {
+			writeInsufficientFunds(w, r, fundsErr)
+			return
+		}
+		if errors.Is(err, db.ErrBelowMinimumBalance) {
+			problem.WriteType(w, problem.ErrBelowMinimumBalance, err.Error(), r.URL.Path, nil)
+			return
+		}
+		var limitErr *db.DailyLimitExceededError
+		if errors.As(err, &limitErr) {
+			writeDailyLimitExceeded(w, r, limitErr)
+			return
+		}
+		if errors.Is(err, tigerbeetle.ErrTransferMismatch) {
+			problem.WriteType(w, problem.ErrIdempotencyKeyReuse, "", r.URL.Path, nil)
 			return
 		}
 		log.Printf("Error withdrawing from user: %v", err)
-		http.Error(w, "Error processing withdrawal", http.StatusInternalServerError)
+		problem.Write(w, http.StatusInternalServerError, "Error processing withdrawal", "", r.URL.Path, nil)
 		return
 	}
 
@@ -329,116 +1194,479 @@ func (s *Server) withdrawFromUser(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) transferBetweenUsers(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		FromUserID uuid.UUID `json:"from_user_id"`
-		ToUserID   uuid.UUID `json:"to_user_id"`
-		Amount     uint64    `json:"amount"`
-	}
+	var req models.TransferRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		middleware.WriteDecodeError(w, r, err)
 		return
 	}
 
 	if req.Amount == 0 {
-		http.Error(w, "Amount must be greater than 0", http.StatusBadRequest)
+		problem.Write(w, http.StatusBadRequest, "Amount must be greater than 0", "", r.URL.Path, nil)
 		return
 	}
 
 	if req.FromUserID == req.ToUserID {
-		http.Error(w, "Cannot transfer to the same user", http.StatusBadRequest)
+		problem.Write(w, http.StatusBadRequest, "Cannot transfer to the same user", "", r.URL.Path, nil)
 		return
 	}
 
-	if err := s.userService.TransferBetweenUsers(req.FromUserID, req.ToUserID, req.Amount); err != nil {
-		if err.Error() == "insufficient funds" {
-			http.Error(w, "Insufficient funds", http.StatusBadRequest)
+	if req.ConfirmedAccountNumber == "" {
+		problem.Write(w, http.StatusBadRequest, "Confirmed account number is required", "", r.URL.Path, nil)
+		return
+	}
+
+	// Confirmar que el número de cuenta verificado por el usuario corresponde al destinatario
+	if err := s.userService.ConfirmRecipientAccount(r.Context(), req.ToUserID, req.ConfirmedAccountNumber); err != nil {
+		if errors.Is(err, db.ErrInvalidAccountNumber) {
+			problem.Write(w, http.StatusBadRequest, "Invalid account number", "", r.URL.Path, nil)
+			return
+		}
+		if err.Error() == "recipient account mismatch" {
+			problem.Write(w, http.StatusBadRequest, "Confirmed account number does not match recipient", "", r.URL.Path, nil)
+			return
+		}
+		log.Printf("Error confirming recipient account: %v", err)
+		problem.Write(w, http.StatusInternalServerError, "Error processing transfer", "", r.URL.Path, nil)
+		return
+	}
+
+	// Las transferencias grandes requieren confirmación con un código OTP
+	if !s.largeTransfers.Confirm(w, r, req.FromUserID, req.Amount, handlers.RecipientUser(req.ToUserID)) {
+		return
+	}
+
+	transferFee, err := s.userService.TransferBetweenUsers(r.Context(), req.FromUserID, req.ToUserID, req.Amount,
+		db.WithTransferCategory(req.Category, req.Tags))
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidCategory) || errors.Is(err, db.ErrInvalidTags) {
+			problem.Write(w, http.StatusBadRequest, "Invalid category or tags", err.Error(), r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, apperrors.ErrAccountFrozen) {
+			problem.WriteType(w, problem.ErrAccountFrozen, "", r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, apperrors.ErrNoTigerBeetleAccount) {
+			problem.WriteType(w, problem.ErrNoLedgerAccount, "", r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, fraud.ErrVelocityExceeded) {
+			problem.WriteType(w, problem.ErrVelocityExceeded, "", r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, compliance.ErrBlocked) {
+			problem.WriteType(w, problem.ErrComplianceBlocked, "", r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, compliance.ErrKYCRequired) {
+			problem.WriteType(w, problem.ErrKYCRequired, "Complete identity verification to move this amount", r.URL.Path, nil)
+			return
+		}
+		var fundsErr *db.InsufficientFundsError
+		if errors.As(err, &fundsErr) {
+			writeInsufficientFunds(w, r, fundsErr)
+			return
+		}
+		if errors.Is(err, db.ErrBelowMinimumBalance) {
+			problem.WriteType(w, problem.ErrBelowMinimumBalance, err.Error(), r.URL.Path, nil)
+			return
+		}
+		var limitErr *db.DailyLimitExceededError
+		if errors.As(err, &limitErr) {
+			writeDailyLimitExceeded(w, r, limitErr)
+			return
+		}
+		if errors.Is(err, tigerbeetle.ErrTransferMismatch) {
+			problem.WriteType(w, problem.ErrIdempotencyKeyReuse, "", r.URL.Path, nil)
 			return
 		}
 		log.Printf("Error transferring between users: %v", err)
-		http.Error(w, "Error processing transfer", http.StatusInternalServerError)
+		problem.Write(w, http.StatusInternalServerError, "Error processing transfer", "", r.URL.Path, nil)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"amount": req.Amount,
+		"fee":    transferFee,
+	})
 }
 
-func (s *Server) healthCheck(w http.ResponseWriter, r *http.Request) {
+// transferToEmail transfiere desde la cuenta del usuario autenticado hacia el usuario con el email indicado
+func (s *Server) transferToEmail(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		problem.Write(w, http.StatusBadRequest, "Invalid user ID", "", r.URL.Path, nil)
+		return
+	}
+
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok || claims.UserID != userID {
+		problem.Write(w, http.StatusForbidden, "Forbidden", "", r.URL.Path, nil)
+		return
+	}
+
+	var req models.TransferToEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.WriteDecodeError(w, r, err)
+		return
+	}
+
+	if strings.TrimSpace(req.ToEmail) == "" {
+		problem.Write(w, http.StatusBadRequest, "Recipient email is required", "", r.URL.Path, nil)
+		return
+	}
+
+	if req.Amount == 0 {
+		problem.Write(w, http.StatusBadRequest, "Amount must be greater than 0", "", r.URL.Path, nil)
+		return
+	}
+
+	// Las transferencias grandes requieren confirmación con un código OTP
+	if !s.largeTransfers.Confirm(w, r, userID, req.Amount, handlers.RecipientEmail(req.ToEmail)) {
+		return
+	}
+
+	transferFee, err := s.userService.TransferByEmail(r.Context(), userID, req.ToEmail, req.Amount)
+	if err != nil {
+		if errors.Is(err, db.ErrRecipientNotFound) {
+			problem.Write(w, http.StatusNotFound, "Recipient not found", "No user is registered with that email address; check it and try again", r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, db.ErrRecipientInactive) {
+			problem.Write(w, http.StatusUnprocessableEntity, "Recipient is inactive", "The recipient's account is deactivated and cannot receive transfers", r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, db.ErrSameAccount) {
+			problem.Write(w, http.StatusBadRequest, "Cannot transfer to the same user", "", r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, db.ErrAccountDeactivated) {
+			problem.Write(w, http.StatusForbidden, "Account is deactivated", "", r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, apperrors.ErrAccountFrozen) {
+			problem.WriteType(w, problem.ErrAccountFrozen, "", r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, apperrors.ErrNoTigerBeetleAccount) {
+			problem.WriteType(w, problem.ErrNoLedgerAccount, "", r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, fraud.ErrVelocityExceeded) {
+			problem.WriteType(w, problem.ErrVelocityExceeded, "", r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, compliance.ErrBlocked) {
+			problem.WriteType(w, problem.ErrComplianceBlocked, "", r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, compliance.ErrKYCRequired) {
+			problem.WriteType(w, problem.ErrKYCRequired, "Complete identity verification to move this amount", r.URL.Path, nil)
+			return
+		}
+		var fundsErr *db.InsufficientFundsError
+		if errors.As(err, &fundsErr) {
+			writeInsufficientFunds(w, r, fundsErr)
+			return
+		}
+		if errors.Is(err, db.ErrBelowMinimumBalance) {
+			problem.WriteType(w, problem.ErrBelowMinimumBalance, err.Error(), r.URL.Path, nil)
+			return
+		}
+		var limitErr *db.DailyLimitExceededError
+		if errors.As(err, &limitErr) {
+			writeDailyLimitExceeded(w, r, limitErr)
+			return
+		}
+		log.Printf("Error transferring to email: %v", err)
+		problem.Write(w, http.StatusInternalServerError, "Error processing transfer", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"amount": req.Amount,
+		"fee":    transferFee,
+	})
+}
+
+// writeInsufficientFunds responde 422 con el monto solicitado y el balance disponible
+func writeInsufficientFunds(w http.ResponseWriter, r *http.Request, err *db.InsufficientFundsError) {
+	problem.WriteType(w, problem.ErrInsufficientFunds, err.Error(), r.URL.Path, map[string]any{
+		"error_code": "INSUFFICIENT_FUNDS",
+		"available":  err.Actual,
+		"requested":  err.Expected,
+	})
+}
+
+// writeDailyLimitExceeded responde 422 con el límite diario, lo usado en el día y el monto solicitado
+func writeDailyLimitExceeded(w http.ResponseWriter, r *http.Request, err *db.DailyLimitExceededError) {
+	problem.WriteType(w, problem.ErrDailyLimitExceeded, err.Error(), r.URL.Path, map[string]any{
+		"error_code": "DAILY_LIMIT_EXCEEDED",
+		"limit":      err.Limit,
+		"used":       err.Used,
+		"requested":  err.Requested,
+	})
+}
+
+func (s *Server) lookupAccount(w http.ResponseWriter, r *http.Request) {
+	accountNumber := r.URL.Query().Get("account_number")
+	if accountNumber == "" {
+		problem.Write(w, http.StatusBadRequest, "Account number is required", "", r.URL.Path, nil)
+		return
+	}
+
+	lookup, err := s.userService.LookupAccount(r.Context(), accountNumber)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidAccountNumber) {
+			problem.Write(w, http.StatusBadRequest, "Invalid account number", "", r.URL.Path, nil)
+			return
+		}
+		if errors.Is(err, db.ErrBankAccountNotFound) {
+			problem.Write(w, http.StatusNotFound, "Account not found", "", r.URL.Path, nil)
+			return
+		}
+		log.Printf("Error looking up account: %v", err)
+		problem.Write(w, http.StatusInternalServerError, "Error looking up account", "", r.URL.Path, nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lookup)
+}
+
+// readinessTimeout es el tiempo máximo de la verificación de PostgreSQL en la sonda de readiness
+const readinessTimeout = 2 * time.Second
+
+// livenessCheck indica que el proceso está corriendo; no consulta dependencias para que una
+// base de datos lenta no se interprete como una caída del proceso
+func (s *Server) livenessCheck(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
-		"status":  "healthy",
+		"status":  "alive",
 		"service": "banca-en-linea-backend",
 	})
 }
 
+// readinessCheck verifica que PostgreSQL y TigerBeetle respondan y retorna 503 si alguno no
+// está disponible
+func (s *Server) readinessCheck(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	pgStatus := "connected"
+	if err := s.dbConn.PingContext(ctx); err != nil {
+		log.Printf("PostgreSQL ping failed: %v", err)
+		pgStatus = "disconnected"
+	}
+
+	tbStatus, tbCircuit := s.userService.TigerBeetleStatus()
+
+	status := "healthy"
+	httpStatus := http.StatusOK
+	switch {
+	case pgStatus == "disconnected" || tbStatus == "disconnected":
+		status = "unhealthy"
+		httpStatus = http.StatusServiceUnavailable
+	case tbStatus == "degraded":
+		status = "degraded"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":              status,
+		"service":             "banca-en-linea-backend",
+		"postgres":            pgStatus,
+		"tigerbeetle":         tbStatus,
+		"tigerbeetle_circuit": tbCircuit,
+	})
+}
+
 // Middleware
 
-func loggingMiddleware(next http.Handler) http.Handler {
+const (
+	// requestTimeout es el tiempo máximo de las solicitudes a la API
+	requestTimeout = 30 * time.Second
+
+	// healthRequestTimeout es el tiempo máximo de las sondas de salud
+	healthRequestTimeout = 5 * time.Second
+)
+
+// timeoutMiddleware aplica el tiempo máximo de cada solicitud según su ruta. Los streams de
+// eventos (SSE) no tienen límite porque son conexiones de larga duración.
+func timeoutMiddleware(next http.Handler) http.Handler {
+	regular := middleware.Timeout(requestTimeout)(next)
+	health := middleware.Timeout(healthRequestTimeout)(next)
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		log.Printf("%s %s %s", r.Method, r.RequestURI, r.RemoteAddr)
-		next.ServeHTTP(w, r)
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/stream") || strings.HasSuffix(r.URL.Path, "/balance-stream"):
+			next.ServeHTTP(w, r)
+		case strings.HasPrefix(r.URL.Path, "/health") || strings.HasSuffix(r.URL.Path, "/health"):
+			health.ServeHTTP(w, r)
+		default:
+			regular.ServeHTTP(w, r)
+		}
 	})
 }
 
-func corsMiddleware(next http.Handler) http.Handler {
+// uploadPathPrefix es el prefijo de las rutas de carga de archivos, que admiten cuerpos más grandes
+const uploadPathPrefix = "/api/v1/uploads"
+
+// bodyLimitMiddleware limita el cuerpo de las solicitudes POST, PUT y PATCH a 64 KB, o a 1 MB en
+// las rutas de carga de archivos
+func bodyLimitMiddleware(next http.Handler) http.Handler {
+	regular := middleware.MaxBodySize(middleware.DefaultMaxBodySize)(next)
+	upload := middleware.MaxBodySize(middleware.UploadMaxBodySize)(next)
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Configuración estricta de CORS
-		origin := r.Header.Get("Origin")
-		allowedOrigins := []string{
-			"http://localhost:3000",
-			"http://localhost:5173",
-			"http://localhost:5174",
-			"http://localhost:8082",
-			"http://127.0.0.1:3000",
-			"http://127.0.0.1:5173",
-			"http://127.0.0.1:5174",
-			"http://127.0.0.1:8082",
-		}
-
-		// Verificar si el origen está permitido
-		isAllowed := false
-		for _, allowedOrigin := range allowedOrigins {
-			if origin == allowedOrigin {
-				isAllowed = true
-				break
-			}
+		switch {
+		case r.Method != http.MethodPost && r.Method != http.MethodPut && r.Method != http.MethodPatch:
+			next.ServeHTTP(w, r)
+		case strings.HasPrefix(r.URL.Path, uploadPathPrefix):
+			upload.ServeHTTP(w, r)
+		default:
+			regular.ServeHTTP(w, r)
 		}
+	})
+}
 
-		if isAllowed {
-			w.Header().Set("Access-Control-Allow-Origin", origin)
-		}
+// newLoggingMiddleware registra cada solicitud. Con logBodies (LOG_LEVEL=debug) registra también
+// el cuerpo de las solicitudes JSON, con las contraseñas y los números de cuenta y de tarjeta
+// ocultos por pii.Redact.
+func newLoggingMiddleware(logBodies bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			middleware.Logger(r.Context()).Info("HTTP request",
+				zap.String("method", r.Method),
+				zap.String("uri", r.RequestURI),
+				zap.String("remote_addr", r.RemoteAddr),
+			)
+			if logBodies {
+				logRequestBody(r)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
 
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
-		w.Header().Set("Access-Control-Max-Age", "86400") // 24 horas
+// logRequestBody registra en debug el cuerpo de una solicitud JSON sin datos personales y lo
+// deja disponible para el handler. Los demás tipos de contenido (por ejemplo las cargas de
+// archivos) no se leen.
+func logRequestBody(r *http.Request) {
+	if r.Body == nil || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		return
+	}
 
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
+	body, err := io.ReadAll(r.Body)
+	// Si la lectura falló (por ejemplo por superar el tamaño máximo), el handler recibe lo leído
+	// seguido del mismo error
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	if err != nil {
+		return
+	}
 
-		next.ServeHTTP(w, r)
-	})
+	middleware.Logger(r.Context()).Debug("HTTP request body",
+		zap.String("uri", r.RequestURI),
+		zap.ByteString("body", pii.Redact(body)),
+	)
 }
 
 // Funciones auxiliares
 
 func (s *Server) handleOptions(w http.ResponseWriter, r *http.Request) {
 	// Esta función maneja las solicitudes OPTIONS para CORS
-	// Los headers CORS ya se establecen en el middleware corsMiddleware
+	// Los headers CORS ya se establecen en el middleware middleware.CORS
 	w.WriteHeader(http.StatusOK)
 }
 
-func shouldSeedData() bool {
-	seedEnv := os.Getenv("SEED_DATA")
-	return seedEnv == "true" || seedEnv == "1"
+// expiredRecordsCleaner elimina registros expirados (implementado por los repositorios de
+// JWT revocados y de llaves de idempotencia)
+type expiredRecordsCleaner interface {
+	DeleteExpired(ctx context.Context) (int64, error)
 }
 
-func getServerPort() string {
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+// cleanupExpired elimina periódicamente los registros expirados del repositorio indicado
+func cleanupExpired(name string, repo expiredRecordsCleaner, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		deleted, err := repo.DeleteExpired(ctx)
+		cancel()
+		if err != nil {
+			log.Printf("Error limpiando %s: %v", name, err)
+			continue
+		}
+		if deleted > 0 {
+			log.Printf("%s expirados eliminados: %d", name, deleted)
+		}
+	}
+}
+
+// runPeriodically ejecuta job cada interval
+func runPeriodically(name string, interval time.Duration, job func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		if err := job(ctx); err != nil {
+			log.Printf("Error ejecutando %s: %v", name, err)
+		}
+		cancel()
+	}
+}
+
+// reconciliationHour es la hora local en la que se ejecuta la conciliación nocturna
+const reconciliationHour = 2
+
+// dormancyHour es la hora local en la que se buscan las cuentas durmientes
+const dormancyHour = 3
+
+// runNightly ejecuta job todos los días a la hora local indicada
+func runNightly(name string, hour int, job func(ctx context.Context) error) {
+	for {
+		now := time.Now()
+		next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+		if !next.After(now) {
+			next = next.AddDate(0, 0, 1)
+		}
+		time.Sleep(time.Until(next))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		if err := job(ctx); err != nil {
+			log.Printf("Error ejecutando %s: %v", name, err)
+		}
+		cancel()
+	}
+}
+
+// newMetricsRegistry crea el registro de Prometheus con las métricas del proceso y del runtime de Go
+func newMetricsRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
+}
+
+// serveMetrics expone GET /metrics en el puerto indicado (METRICS_PORT, 9090 por defecto)
+func serveMetrics(registry *prometheus.Registry, port string) {
+	metricsRouter := mux.NewRouter()
+	metricsRouter.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{})).Methods("GET")
+
+	log.Printf("Métricas disponibles en: http://localhost:%s/metrics", port)
+	if err := http.ListenAndServe(":"+port, metricsRouter); err != nil {
+		log.Printf("Error iniciando servidor de métricas: %v", err)
 	}
-	return port
 }
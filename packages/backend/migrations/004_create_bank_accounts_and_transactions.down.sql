@@ -0,0 +1,9 @@
+-- Revertir cambios de la migración 004
+
+-- Eliminar triggers
+DROP TRIGGER IF EXISTS update_transactions_updated_at ON transactions;
+DROP TRIGGER IF EXISTS update_bank_accounts_updated_at ON bank_accounts;
+
+-- Eliminar tablas
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS bank_accounts;
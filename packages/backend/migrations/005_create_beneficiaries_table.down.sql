@@ -0,0 +1,7 @@
+-- Revertir cambios de la migración 005
+
+-- Eliminar índice
+DROP INDEX IF EXISTS idx_beneficiaries_owner_user_id;
+
+-- Eliminar tabla
+DROP TABLE IF EXISTS beneficiaries;
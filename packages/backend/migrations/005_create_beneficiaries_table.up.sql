@@ -0,0 +1,12 @@
+-- Crear tabla de beneficiarios (destinatarios guardados por el usuario)
+CREATE TABLE IF NOT EXISTS beneficiaries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipient_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    alias TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (owner_user_id, recipient_user_id)
+);
+
+-- Crear índice para búsquedas por propietario
+CREATE INDEX IF NOT EXISTS idx_beneficiaries_owner_user_id ON beneficiaries(owner_user_id);
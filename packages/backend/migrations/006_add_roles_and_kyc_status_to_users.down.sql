@@ -0,0 +1,8 @@
+-- Revertir cambios de la migración 006
+
+-- Eliminar índice
+DROP INDEX IF EXISTS idx_users_kyc_status;
+
+-- Eliminar campos roles y kyc_status
+ALTER TABLE users DROP COLUMN IF EXISTS kyc_status;
+ALTER TABLE users DROP COLUMN IF EXISTS roles;
@@ -0,0 +1,17 @@
+-- Agregar roles y estado KYC a la tabla users (si no existen)
+DO $$ 
+BEGIN 
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+                   WHERE table_name = 'users' AND column_name = 'roles') THEN
+        ALTER TABLE users ADD COLUMN roles TEXT[] NOT NULL DEFAULT '{user}';
+    END IF;
+
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+                   WHERE table_name = 'users' AND column_name = 'kyc_status') THEN
+        ALTER TABLE users ADD COLUMN kyc_status TEXT NOT NULL DEFAULT 'pending'
+            CHECK (kyc_status IN ('pending', 'submitted', 'approved', 'rejected'));
+    END IF;
+END $$;
+
+-- Crear índice para búsquedas por estado KYC (si no existe)
+CREATE INDEX IF NOT EXISTS idx_users_kyc_status ON users(kyc_status) WHERE deleted_at IS NULL;
@@ -0,0 +1,4 @@
+-- Revertir cambios de la migración 007
+
+-- Eliminar tabla
+DROP TABLE IF EXISTS user_preferences;
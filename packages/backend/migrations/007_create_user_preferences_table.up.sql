@@ -0,0 +1,11 @@
+-- Crear tabla de preferencias de usuario (idioma, zona horaria y notificaciones por email)
+CREATE TABLE IF NOT EXISTS user_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    language VARCHAR(5) NOT NULL DEFAULT 'es',
+    timezone VARCHAR(50) NOT NULL DEFAULT 'America/Tegucigalpa',
+    email_on_deposit BOOLEAN NOT NULL DEFAULT true,
+    email_on_withdrawal BOOLEAN NOT NULL DEFAULT true,
+    email_on_transfer BOOLEAN NOT NULL DEFAULT true,
+    email_on_login BOOLEAN NOT NULL DEFAULT false,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
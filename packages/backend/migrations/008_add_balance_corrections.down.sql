@@ -0,0 +1,10 @@
+-- Revertir cambios de la migración 008
+
+-- Eliminar columna del administrador
+ALTER TABLE transactions DROP COLUMN IF EXISTS created_by;
+
+-- Eliminar correcciones y restaurar la restricción original
+DELETE FROM transactions WHERE transaction_type = 'balance_correction';
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK (transaction_type IN ('transfer', 'deposit', 'withdrawal'));
@@ -0,0 +1,7 @@
+-- Permitir transacciones de corrección manual de balance
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK (transaction_type IN ('transfer', 'deposit', 'withdrawal', 'balance_correction'));
+
+-- Registrar el administrador que originó la transacción (solo para correcciones manuales)
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS created_by UUID REFERENCES users(id);
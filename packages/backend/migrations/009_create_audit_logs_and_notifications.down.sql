@@ -0,0 +1,9 @@
+-- Revertir cambios de la migración 009
+
+-- Eliminar índices
+DROP INDEX IF EXISTS idx_notifications_user_created_at;
+DROP INDEX IF EXISTS idx_audit_logs_actor_created_at;
+
+-- Eliminar tablas
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS audit_logs;
@@ -0,0 +1,4 @@
+-- Revertir cambios de la migración 010
+
+-- Eliminar secuencia
+DROP SEQUENCE IF EXISTS tigerbeetle_transfer_id_seq;
@@ -0,0 +1,4 @@
+-- Secuencia para los IDs de transferencias de TigerBeetle.
+-- Los IDs 1-10000 quedan reservados para uso manual. Cada nextval reserva un bloque de 100 IDs
+-- que el backend asigna desde memoria.
+CREATE SEQUENCE IF NOT EXISTS tigerbeetle_transfer_id_seq START WITH 10001 INCREMENT BY 100;
@@ -0,0 +1,7 @@
+-- Revertir cambios de la migración 011
+
+-- Eliminar índice
+DROP INDEX IF EXISTS idx_refresh_tokens_user_id;
+
+-- Eliminar tabla
+DROP TABLE IF EXISTS refresh_tokens;
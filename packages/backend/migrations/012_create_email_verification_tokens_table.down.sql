@@ -0,0 +1,7 @@
+-- Revertir cambios de la migración 012
+
+-- Eliminar índice
+DROP INDEX IF EXISTS idx_email_verification_tokens_user_id;
+
+-- Eliminar tabla
+DROP TABLE IF EXISTS email_verification_tokens;
@@ -0,0 +1,7 @@
+-- Revertir cambios de la migración 013
+
+-- Eliminar índice
+DROP INDEX IF EXISTS idx_password_reset_tokens_user_id;
+
+-- Eliminar tabla
+DROP TABLE IF EXISTS password_reset_tokens;
@@ -0,0 +1,5 @@
+-- Revertir cambios de la migración 014
+
+-- Eliminar columnas de TOTP
+ALTER TABLE users DROP COLUMN IF EXISTS totp_enabled;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_secret;
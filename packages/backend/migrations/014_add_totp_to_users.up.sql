@@ -0,0 +1,4 @@
+-- Agregar columnas para la autenticación de dos factores (TOTP).
+-- El secreto se guarda cifrado con AES-GCM.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_enabled BOOLEAN NOT NULL DEFAULT FALSE;
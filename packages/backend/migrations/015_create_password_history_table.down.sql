@@ -0,0 +1,7 @@
+-- Revertir cambios de la migración 015
+
+-- Eliminar índice
+DROP INDEX IF EXISTS idx_password_history_user_id_created_at;
+
+-- Eliminar tabla
+DROP TABLE IF EXISTS password_history;
@@ -0,0 +1,14 @@
+-- Crear tabla con los últimos hashes de contraseña de cada usuario (para evitar su reutilización)
+CREATE TABLE IF NOT EXISTS password_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Crear índice para consultar el historial de un usuario por fecha
+CREATE INDEX IF NOT EXISTS idx_password_history_user_id_created_at ON password_history(user_id, created_at DESC);
+
+-- Registrar la contraseña actual de los usuarios existentes
+INSERT INTO password_history (user_id, password_hash)
+SELECT id, password_hash FROM users;
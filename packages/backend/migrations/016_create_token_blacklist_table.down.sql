@@ -0,0 +1,7 @@
+-- Revertir cambios de la migración 016
+
+-- Eliminar índice
+DROP INDEX IF EXISTS idx_token_blacklist_expires_at;
+
+-- Eliminar tabla
+DROP TABLE IF EXISTS token_blacklist;
@@ -0,0 +1,8 @@
+-- Crear tabla de JWT revocados (por logout) hasta su expiración
+CREATE TABLE IF NOT EXISTS token_blacklist (
+    jti TEXT PRIMARY KEY,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Crear índice para la limpieza de tokens expirados
+CREATE INDEX IF NOT EXISTS idx_token_blacklist_expires_at ON token_blacklist(expires_at);
@@ -0,0 +1,2 @@
+-- Revertir cambios de la migración 017
+DROP TABLE IF EXISTS sequences;
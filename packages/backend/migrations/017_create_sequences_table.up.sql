@@ -0,0 +1,13 @@
+-- Crear tabla de contadores usados para generar números de cuenta.
+-- Cada fila se bloquea con FOR UPDATE al reservar el siguiente valor.
+CREATE TABLE IF NOT EXISTS sequences (
+    name VARCHAR(50) PRIMARY KEY,
+    value BIGINT NOT NULL DEFAULT 0
+);
+
+-- Contadores por tipo de cuenta, iniciados con las cuentas existentes
+INSERT INTO sequences (name, value)
+SELECT 'account_number_' || t.account_type,
+       (SELECT COUNT(*) FROM bank_accounts WHERE account_type = t.account_type)
+FROM (VALUES ('checking'), ('savings')) AS t(account_type)
+ON CONFLICT (name) DO NOTHING;
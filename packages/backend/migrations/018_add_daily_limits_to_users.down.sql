@@ -0,0 +1,5 @@
+-- Revertir cambios de la migración 018
+
+-- Eliminar columnas de límites diarios
+ALTER TABLE users DROP COLUMN IF EXISTS daily_withdrawal_limit_cents;
+ALTER TABLE users DROP COLUMN IF EXISTS daily_transfer_limit_cents;
@@ -0,0 +1,3 @@
+-- Agregar límites diarios de transferencias y retiros por usuario (en centavos)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS daily_transfer_limit_cents BIGINT NOT NULL DEFAULT 5000000;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS daily_withdrawal_limit_cents BIGINT NOT NULL DEFAULT 2000000;
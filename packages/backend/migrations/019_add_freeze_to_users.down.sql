@@ -0,0 +1,6 @@
+-- Revertir cambios de la migración 019
+
+-- Eliminar columnas de congelamiento
+ALTER TABLE users DROP COLUMN IF EXISTS frozen_reason;
+ALTER TABLE users DROP COLUMN IF EXISTS frozen_at;
+ALTER TABLE users DROP COLUMN IF EXISTS is_frozen;
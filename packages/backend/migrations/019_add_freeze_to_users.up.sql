@@ -0,0 +1,4 @@
+-- Agregar columnas para el congelamiento de cuentas por administración o cumplimiento
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_frozen BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS frozen_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS frozen_reason TEXT;
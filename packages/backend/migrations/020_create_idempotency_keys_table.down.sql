@@ -0,0 +1,7 @@
+-- Revertir cambios de la migración 020
+
+-- Eliminar índice
+DROP INDEX IF EXISTS idx_idempotency_keys_expires_at;
+
+-- Eliminar tabla
+DROP TABLE IF EXISTS idempotency_keys;
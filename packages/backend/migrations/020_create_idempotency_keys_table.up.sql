@@ -0,0 +1,15 @@
+-- Crear tabla de llaves de idempotencia de operaciones financieras.
+-- Se guarda el SHA-256 del header X-Idempotency-Key junto con la respuesta enviada.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key_hash TEXT NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status_code INTEGER NOT NULL,
+    content_type TEXT NOT NULL DEFAULT '',
+    response_body BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (user_id, key_hash)
+);
+
+-- Crear índice para la limpieza de llaves expiradas
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
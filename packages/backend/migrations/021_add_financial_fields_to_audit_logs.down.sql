@@ -0,0 +1,9 @@
+-- Revertir cambios de la migración 021
+
+-- Eliminar índice
+DROP INDEX IF EXISTS idx_audit_logs_action_created_at;
+
+-- Eliminar columnas
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS error_text;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS status;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS amount_cents;
@@ -0,0 +1,7 @@
+-- Agregar columnas para auditar el resultado de las operaciones financieras
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS amount_cents BIGINT;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS status TEXT;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS error_text TEXT;
+
+-- Crear índice para consultar las operaciones por acción y fecha
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action_created_at ON audit_logs(action, created_at);
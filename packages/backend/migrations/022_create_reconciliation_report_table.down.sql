@@ -0,0 +1,8 @@
+-- Revertir cambios de la migración 022
+
+-- Eliminar índices
+DROP INDEX IF EXISTS idx_reconciliation_report_user_created_at;
+DROP INDEX IF EXISTS idx_reconciliation_report_run_id;
+
+-- Eliminar tabla
+DROP TABLE IF EXISTS reconciliation_report;
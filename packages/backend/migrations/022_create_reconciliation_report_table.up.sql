@@ -0,0 +1,16 @@
+-- Crear tabla con las diferencias encontradas entre el balance de TigerBeetle y el calculado
+-- a partir de las transacciones registradas en PostgreSQL
+CREATE TABLE IF NOT EXISTS reconciliation_report (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    run_id UUID NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tigerbeetle_account_id BIGINT NOT NULL,
+    tigerbeetle_balance_cents BIGINT NOT NULL,
+    expected_balance_cents BIGINT NOT NULL,
+    difference_cents BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Crear índices para consultar por ejecución y por usuario
+CREATE INDEX IF NOT EXISTS idx_reconciliation_report_run_id ON reconciliation_report(run_id);
+CREATE INDEX IF NOT EXISTS idx_reconciliation_report_user_created_at ON reconciliation_report(user_id, created_at);
@@ -0,0 +1,7 @@
+-- Revertir cambios de la migración 023
+
+-- Eliminar trigger
+DROP TRIGGER IF EXISTS update_account_type_config_updated_at ON account_type_config;
+
+-- Eliminar tabla
+DROP TABLE IF EXISTS account_type_config;
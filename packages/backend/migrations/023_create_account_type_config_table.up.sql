@@ -0,0 +1,19 @@
+-- Crear tabla con la configuración de cada tipo de cuenta bancaria
+CREATE TABLE IF NOT EXISTS account_type_config (
+    account_type VARCHAR(20) PRIMARY KEY CHECK (account_type IN ('checking', 'savings')),
+    minimum_balance_cents BIGINT NOT NULL DEFAULT 0 CHECK (minimum_balance_cents >= 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Balance mínimo por defecto: sin mínimo para cheques, 500.00 para ahorros
+INSERT INTO account_type_config (account_type, minimum_balance_cents) VALUES
+    ('checking', 0),
+    ('savings', 50000)
+ON CONFLICT (account_type) DO NOTHING;
+
+-- Trigger para actualizar updated_at
+DROP TRIGGER IF EXISTS update_account_type_config_updated_at ON account_type_config;
+CREATE TRIGGER update_account_type_config_updated_at
+    BEFORE UPDATE ON account_type_config
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,6 @@
+-- Revertir cambios de la migración 024
+
+-- Eliminar columnas de sobregiro
+ALTER TABLE bank_accounts
+    DROP COLUMN IF EXISTS overdraft_limit_cents,
+    DROP COLUMN IF EXISTS overdraft_enabled;
@@ -0,0 +1,4 @@
+-- Agregar la protección de sobregiro a las cuentas bancarias
+ALTER TABLE bank_accounts
+    ADD COLUMN IF NOT EXISTS overdraft_enabled BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS overdraft_limit_cents BIGINT NOT NULL DEFAULT 0 CHECK (overdraft_limit_cents >= 0);
@@ -0,0 +1,8 @@
+-- Revertir cambios de la migración 025
+
+-- Eliminar índices
+DROP INDEX IF EXISTS idx_scheduled_transfers_from_user_id;
+DROP INDEX IF EXISTS idx_scheduled_transfers_pending;
+
+-- Eliminar tabla
+DROP TABLE IF EXISTS scheduled_transfers;
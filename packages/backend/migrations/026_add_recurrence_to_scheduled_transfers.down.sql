@@ -0,0 +1,13 @@
+-- Revertir cambios de la migración 026
+
+-- Restaurar los estados originales
+ALTER TABLE scheduled_transfers DROP CONSTRAINT IF EXISTS scheduled_transfers_status_check;
+UPDATE scheduled_transfers SET status = 'executed' WHERE status = 'completed';
+UPDATE scheduled_transfers SET status = 'failed', error_text = 'cancelled' WHERE status = 'cancelled';
+ALTER TABLE scheduled_transfers ADD CONSTRAINT scheduled_transfers_status_check
+    CHECK (status IN ('pending', 'executed', 'failed'));
+
+-- Eliminar columnas de recurrencia
+ALTER TABLE scheduled_transfers
+    DROP COLUMN IF EXISTS recurrence_end_date,
+    DROP COLUMN IF EXISTS recurrence_type;
@@ -0,0 +1,9 @@
+-- Agregar la recurrencia semanal o mensual a las transferencias programadas
+ALTER TABLE scheduled_transfers
+    ADD COLUMN IF NOT EXISTS recurrence_type VARCHAR(10) NOT NULL DEFAULT 'once' CHECK (recurrence_type IN ('once', 'weekly', 'monthly')),
+    ADD COLUMN IF NOT EXISTS recurrence_end_date TIMESTAMP WITH TIME ZONE;
+
+-- Estados de una serie terminada y de una transferencia cancelada por el usuario
+ALTER TABLE scheduled_transfers DROP CONSTRAINT IF EXISTS scheduled_transfers_status_check;
+ALTER TABLE scheduled_transfers ADD CONSTRAINT scheduled_transfers_status_check
+    CHECK (status IN ('pending', 'executed', 'failed', 'completed', 'cancelled'));
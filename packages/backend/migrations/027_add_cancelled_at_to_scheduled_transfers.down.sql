@@ -0,0 +1,4 @@
+-- Revertir cambios de la migración 027
+
+-- Eliminar columna
+ALTER TABLE scheduled_transfers DROP COLUMN IF EXISTS cancelled_at;
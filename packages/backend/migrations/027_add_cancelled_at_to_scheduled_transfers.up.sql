@@ -0,0 +1,2 @@
+-- Registrar cuándo el usuario canceló una transferencia programada
+ALTER TABLE scheduled_transfers ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP WITH TIME ZONE;
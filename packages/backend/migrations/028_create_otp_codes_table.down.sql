@@ -0,0 +1,7 @@
+-- Revertir cambios de la migración 028
+
+-- Eliminar índice
+DROP INDEX IF EXISTS idx_otp_codes_user_id;
+
+-- Eliminar tabla
+DROP TABLE IF EXISTS otp_codes;
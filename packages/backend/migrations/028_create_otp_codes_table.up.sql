@@ -0,0 +1,13 @@
+-- Crear tabla de códigos OTP para confirmar operaciones sensibles (solo se guarda el hash SHA-256 del código)
+CREATE TABLE IF NOT EXISTS otp_codes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Crear índice para búsquedas por usuario
+CREATE INDEX IF NOT EXISTS idx_otp_codes_user_id ON otp_codes(user_id);
@@ -0,0 +1,4 @@
+-- Revertir cambios de la migración 029
+
+-- Eliminar índice (la extensión pg_trgm se conserva por si otros objetos la usan)
+DROP INDEX IF EXISTS idx_users_search;
@@ -0,0 +1,7 @@
+-- Crear extensión de trigramas para búsquedas por subcadena
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Crear índice GIN para la búsqueda de usuarios por email y nombre (debe coincidir con la
+-- expresión usada en UserRepository.Search)
+CREATE INDEX IF NOT EXISTS idx_users_search ON users
+    USING GIN (lower(email || ' ' || first_name || ' ' || last_name) gin_trgm_ops);
@@ -0,0 +1,4 @@
+-- Revertir cambios de la migración 030 (los emails normalizados se conservan en minúsculas)
+
+-- Eliminar índice
+DROP INDEX IF EXISTS idx_users_email_lower;
@@ -0,0 +1,6 @@
+-- Normalizar los emails existentes a minúsculas. Falla si dos cuentas solo difieren en
+-- mayúsculas; esas cuentas deben fusionarse manualmente antes de aplicar la migración.
+UPDATE users SET email = lower(email) WHERE email <> lower(email);
+
+-- Crear índice único sin distinguir mayúsculas
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON users(lower(email));
@@ -0,0 +1,4 @@
+-- Revertir cambios de la migración 031
+
+-- Eliminar columna del último inicio de sesión
+ALTER TABLE users DROP COLUMN IF EXISTS last_login_at;
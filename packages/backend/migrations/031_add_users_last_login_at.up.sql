@@ -0,0 +1,2 @@
+-- Registrar el último inicio de sesión del usuario (detección de cuentas inactivas y alertas de fraude)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMP WITH TIME ZONE;
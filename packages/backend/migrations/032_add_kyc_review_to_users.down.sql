@@ -0,0 +1,5 @@
+-- Revertir cambios de la migración 032
+
+-- Eliminar columnas de revisión KYC
+ALTER TABLE users DROP COLUMN IF EXISTS kyc_reviewed_at;
+ALTER TABLE users DROP COLUMN IF EXISTS kyc_document_ref;
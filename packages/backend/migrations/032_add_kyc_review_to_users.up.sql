@@ -0,0 +1,3 @@
+-- Agregar la referencia al documento de identidad y la fecha de revisión KYC
+ALTER TABLE users ADD COLUMN IF NOT EXISTS kyc_document_ref TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS kyc_reviewed_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,9 @@
+-- Revertir cambios de la migración 033
+
+-- Eliminar índice
+DROP INDEX IF EXISTS idx_refresh_tokens_id;
+
+-- Eliminar columnas de la sesión
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS ip_address;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS device_info;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS id;
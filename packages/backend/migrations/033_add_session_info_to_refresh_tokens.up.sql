@@ -0,0 +1,7 @@
+-- Identificar cada refresh token como una sesión y guardar el dispositivo y la IP de origen
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS id UUID NOT NULL DEFAULT uuid_generate_v4();
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS device_info TEXT;
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS ip_address TEXT;
+
+-- Crear índice único para revocar sesiones por su ID
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_id ON refresh_tokens(id);
@@ -0,0 +1,7 @@
+-- Revertir cambios de la migración 034
+
+-- Eliminar índice
+DROP INDEX IF EXISTS idx_webhooks_user_id_active;
+
+-- Eliminar tabla
+DROP TABLE IF EXISTS webhooks;
@@ -0,0 +1,13 @@
+-- Crear tabla de suscripciones de webhooks a los eventos de transacción de cada usuario
+CREATE TABLE IF NOT EXISTS webhooks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT[] NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Índice parcial para buscar las suscripciones activas de un usuario al entregar un evento
+CREATE INDEX IF NOT EXISTS idx_webhooks_user_id_active ON webhooks(user_id) WHERE active;
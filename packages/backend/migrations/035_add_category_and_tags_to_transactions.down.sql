@@ -0,0 +1,8 @@
+-- Revertir cambios de la migración 035
+
+-- Eliminar índice
+DROP INDEX IF EXISTS idx_transactions_category;
+
+-- Eliminar columnas
+ALTER TABLE transactions DROP COLUMN IF EXISTS tags;
+ALTER TABLE transactions DROP COLUMN IF EXISTS category;
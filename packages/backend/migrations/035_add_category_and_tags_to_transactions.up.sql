@@ -0,0 +1,6 @@
+-- Agregar la categoría y las etiquetas definidas por el usuario a las transacciones
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS category TEXT;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- Crear índice para el desglose de gastos por categoría
+CREATE INDEX IF NOT EXISTS idx_transactions_category ON transactions(category);
@@ -0,0 +1,8 @@
+-- Revertir cambios de la migración 036
+
+-- Eliminar índices
+DROP INDEX IF EXISTS idx_payment_requests_payer_user_id;
+DROP INDEX IF EXISTS idx_payment_requests_requester_user_id;
+
+-- Eliminar tabla
+DROP TABLE IF EXISTS payment_requests;
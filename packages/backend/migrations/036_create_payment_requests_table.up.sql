@@ -0,0 +1,17 @@
+-- Crear tabla de solicitudes de pago: un usuario pide a otro que le transfiera un monto
+CREATE TABLE IF NOT EXISTS payment_requests (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    requester_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    payer_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount_cents BIGINT NOT NULL CHECK (amount_cents > 0),
+    description TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'fulfilled', 'rejected')),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    responded_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (requester_user_id <> payer_user_id)
+);
+
+-- Crear índices para listar las solicitudes enviadas y recibidas por un usuario
+CREATE INDEX IF NOT EXISTS idx_payment_requests_requester_user_id ON payment_requests(requester_user_id);
+CREATE INDEX IF NOT EXISTS idx_payment_requests_payer_user_id ON payment_requests(payer_user_id);
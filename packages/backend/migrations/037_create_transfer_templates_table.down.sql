@@ -0,0 +1,4 @@
+-- Revertir cambios de la migración 037
+
+-- Eliminar tabla
+DROP TABLE IF EXISTS transfer_templates;
@@ -0,0 +1,12 @@
+-- Crear tabla de plantillas de transferencia (transferencias frecuentes guardadas por el usuario)
+CREATE TABLE IF NOT EXISTS transfer_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipient_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount_cents BIGINT NOT NULL CHECK (amount_cents > 0),
+    description TEXT,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (owner_user_id, name),
+    CHECK (owner_user_id <> recipient_user_id)
+);
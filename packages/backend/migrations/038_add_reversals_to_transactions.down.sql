@@ -0,0 +1,14 @@
+-- Revertir cambios de la migración 038
+
+-- Eliminar índice
+DROP INDEX IF EXISTS idx_transactions_reversal_of;
+
+-- Eliminar reversiones y columnas
+DELETE FROM transactions WHERE transaction_type = 'reversal';
+ALTER TABLE transactions DROP COLUMN IF EXISTS recipient_user_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS related_transaction_id;
+
+-- Restaurar la restricción anterior
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK (transaction_type IN ('transfer', 'deposit', 'withdrawal', 'balance_correction'));
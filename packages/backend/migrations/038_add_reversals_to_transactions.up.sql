@@ -0,0 +1,14 @@
+-- Permitir reversiones de transferencias
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK (transaction_type IN ('transfer', 'deposit', 'withdrawal', 'balance_correction', 'reversal'));
+
+-- Transacción original de una reversión
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS related_transaction_id UUID REFERENCES transactions(id);
+
+-- Usuario destinatario de una transferencia (las cuentas principales no están en bank_accounts)
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS recipient_user_id UUID REFERENCES users(id);
+
+-- Una transferencia solo puede revertirse una vez (las reversiones fallidas no cuentan)
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_reversal_of ON transactions(related_transaction_id)
+    WHERE transaction_type = 'reversal' AND status <> 'failed';
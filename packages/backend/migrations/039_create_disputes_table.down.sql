@@ -0,0 +1,8 @@
+-- Revertir cambios de la migración 039
+
+-- Eliminar índices
+DROP INDEX IF EXISTS idx_disputes_open_transaction;
+DROP INDEX IF EXISTS idx_disputes_user_id;
+
+-- Eliminar tabla
+DROP TABLE IF EXISTS disputes;
@@ -0,0 +1,18 @@
+-- Crear tabla de disputas (reclamos de los usuarios sobre sus transacciones)
+CREATE TABLE IF NOT EXISTS disputes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    transaction_id UUID NOT NULL REFERENCES transactions(id),
+    reason TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'approved', 'rejected')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    resolved_by UUID REFERENCES users(id),
+    resolution_note TEXT
+);
+
+-- Crear índice para búsquedas por usuario
+CREATE INDEX IF NOT EXISTS idx_disputes_user_id ON disputes(user_id);
+
+-- Una transacción solo puede tener una disputa abierta a la vez
+CREATE UNIQUE INDEX IF NOT EXISTS idx_disputes_open_transaction ON disputes(transaction_id) WHERE status = 'open';
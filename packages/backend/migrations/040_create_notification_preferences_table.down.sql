@@ -0,0 +1,4 @@
+-- Revertir cambios de la migración 040
+
+-- Eliminar tabla
+DROP TABLE IF EXISTS notification_preferences;
@@ -0,0 +1,9 @@
+-- Crear tabla de preferencias de notificación (consentimiento del usuario por canal y tipo de aviso)
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    email_large_tx BOOLEAN NOT NULL DEFAULT true,
+    email_login BOOLEAN NOT NULL DEFAULT false,
+    email_statement BOOLEAN NOT NULL DEFAULT true,
+    sms_large_tx BOOLEAN NOT NULL DEFAULT false,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
@@ -0,0 +1,8 @@
+-- Revertir cambios de la migración 041
+
+-- Eliminar índices
+DROP INDEX IF EXISTS idx_user_activity_log_ip_address;
+DROP INDEX IF EXISTS idx_user_activity_log_user_created_at;
+
+-- Eliminar tabla
+DROP TABLE IF EXISTS user_activity_log;
@@ -0,0 +1,16 @@
+-- Crear tabla de actividad de los usuarios (todas las llamadas autenticadas a la API, para
+-- investigaciones de seguridad)
+CREATE TABLE IF NOT EXISTS user_activity_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    action TEXT NOT NULL,
+    endpoint TEXT NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    ip_address TEXT,
+    user_agent TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Crear índices para consultas por usuario y fecha, y por dirección IP
+CREATE INDEX IF NOT EXISTS idx_user_activity_log_user_created_at ON user_activity_log(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_user_activity_log_ip_address ON user_activity_log(ip_address);
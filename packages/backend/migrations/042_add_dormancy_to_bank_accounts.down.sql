@@ -0,0 +1,6 @@
+-- Revertir cambios de la migración 042
+
+-- Eliminar columnas de inactividad
+ALTER TABLE bank_accounts
+    DROP COLUMN IF EXISTS dormant_since,
+    DROP COLUMN IF EXISTS is_dormant;
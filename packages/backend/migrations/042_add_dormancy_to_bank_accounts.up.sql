@@ -0,0 +1,4 @@
+-- Agregar la marca de inactividad (cuenta durmiente) a las cuentas bancarias
+ALTER TABLE bank_accounts
+    ADD COLUMN IF NOT EXISTS is_dormant BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS dormant_since TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,9 @@
+-- Revertir cambios de la migración 043
+
+-- Eliminar comisiones
+DELETE FROM transactions WHERE transaction_type = 'fee';
+
+-- Restaurar la restricción anterior
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK (transaction_type IN ('transfer', 'deposit', 'withdrawal', 'balance_correction', 'reversal'));
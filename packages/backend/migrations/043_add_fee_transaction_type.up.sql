@@ -0,0 +1,4 @@
+-- Registrar las comisiones de transferencia como transacciones propias
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_transaction_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_transaction_type_check
+    CHECK (transaction_type IN ('transfer', 'deposit', 'withdrawal', 'balance_correction', 'reversal', 'fee'));
@@ -0,0 +1,3 @@
+-- Revertir cambios de la migración 044
+
+ALTER TABLE otp_codes DROP COLUMN IF EXISTS operation_hash;
@@ -0,0 +1,2 @@
+-- Vincular cada código OTP con la operación que confirma (hash SHA-256 del destino y el monto)
+ALTER TABLE otp_codes ADD COLUMN IF NOT EXISTS operation_hash VARCHAR(64) NOT NULL DEFAULT '';
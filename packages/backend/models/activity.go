@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Tipos de evento de la línea de tiempo de actividad
+const (
+	ActivityEventAuth         = "auth"
+	ActivityEventTransaction  = "transaction"
+	ActivityEventNotification = "notification"
+)
+
+// UserActivity representa un evento en la línea de tiempo de un usuario para análisis de fraude
+type UserActivity struct {
+	Timestamp time.Time              `json:"timestamp"`
+	EventType string                 `json:"event_type"`
+	Details   map[string]interface{} `json:"details"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ActivityLog representa una llamada autenticada a la API registrada para investigaciones de seguridad
+type ActivityLog struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	Action    string    `json:"action" db:"action"`
+	Endpoint  string    `json:"endpoint" db:"endpoint"`
+	Method    string    `json:"method" db:"method"`
+	IPAddress string    `json:"ip_address,omitempty" db:"ip_address"`
+	UserAgent string    `json:"user_agent,omitempty" db:"user_agent"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Acciones registradas en la auditoría
+const (
+	AuditActionLogin       = "auth.login"
+	AuditActionLoginFailed = "auth.login_failed"
+
+	AuditActionDeposit    = "transaction.deposit"
+	AuditActionWithdrawal = "transaction.withdrawal"
+	AuditActionTransfer   = "transaction.transfer"
+
+	AuditActionComplianceBlock = "compliance_block"
+)
+
+// Resultados de una operación auditada
+const (
+	AuditStatusSuccess = "success"
+	AuditStatusFailure = "failure"
+)
+
+// AuditLog representa un evento registrado en la tabla de auditoría
+type AuditLog struct {
+	ID          uuid.UUID              `json:"id" db:"id"`
+	ActorUserID *uuid.UUID             `json:"actor_user_id,omitempty" db:"actor_user_id"`
+	Action      string                 `json:"action" db:"action"`
+	EntityType  string                 `json:"entity_type,omitempty" db:"entity_type"`
+	EntityID    string                 `json:"entity_id,omitempty" db:"entity_id"`
+	Details     map[string]interface{} `json:"details,omitempty" db:"details"`
+	AmountCents *int64                 `json:"amount_cents,omitempty" db:"amount_cents"`
+	Status      string                 `json:"status,omitempty" db:"status"`
+	ErrorText   string                 `json:"error_text,omitempty" db:"error_text"`
+	RemoteAddr  string                 `json:"remote_addr,omitempty" db:"remote_addr"`
+	CreatedAt   time.Time              `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,9 @@
+package models
+
+// BalanceChangedEvent representa el nuevo balance de una cuenta después de una operación.
+// AccountID es el ID de la cuenta en TigerBeetle y NewBalance está en centavos, negativo si la
+// cuenta quedó en sobregiro.
+type BalanceChangedEvent struct {
+	AccountID  int64 `json:"account_id"`
+	NewBalance int64 `json:"new_balance"`
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BankName es el nombre del banco mostrado en las consultas públicas de cuentas
+const BankName = "Banca en Línea"
+
+// Tipos de cuenta bancaria
+const (
+	AccountTypeChecking = "checking"
+	AccountTypeSavings  = "savings"
+	// AccountTypePrimary identifica en los listados a la cuenta principal del usuario, que se crea
+	// junto con él y no está en bank_accounts
+	AccountTypePrimary = "primary"
+)
+
+// DefaultCurrency es la moneda asignada a las cuentas cuando no se indica otra
+const DefaultCurrency = "USD"
+
+// BankAccount representa una cuenta bancaria (metadatos, los balances están en TigerBeetle)
+type BankAccount struct {
+	ID                   uuid.UUID  `json:"id" db:"id"`
+	UserID               uuid.UUID  `json:"user_id" db:"user_id"`
+	AccountNumber        string     `json:"account_number" db:"account_number"`
+	AccountType          string     `json:"account_type" db:"account_type"`
+	TigerBeetleAccountID int64      `json:"tigerbeetle_account_id" db:"tigerbeetle_account_id"`
+	Currency             string     `json:"currency" db:"currency"`
+	MinimumBalanceCents  int64      `json:"minimum_balance" db:"minimum_balance_cents"` // Según account_type_config
+	OverdraftEnabled     bool       `json:"overdraft_enabled" db:"overdraft_enabled"`
+	OverdraftLimitCents  int64      `json:"overdraft_limit" db:"overdraft_limit_cents"` // Saldo negativo máximo permitido
+	CreatedAt            time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at" db:"updated_at"`
+	IsActive             bool       `json:"is_active" db:"is_active"`
+	IsDormant            bool       `json:"is_dormant" db:"is_dormant"` // Sin transacciones en el último año
+	DormantSince         *time.Time `json:"dormant_since,omitempty" db:"dormant_since"`
+}
+
+// BankAccountWithBalance representa una cuenta bancaria junto con su balance en TigerBeetle
+type BankAccountWithBalance struct {
+	BankAccount
+	Balance int64 `json:"balance"`
+}
+
+// CreateBankAccountRequest representa la solicitud de apertura de una cuenta bancaria
+type CreateBankAccountRequest struct {
+	AccountType string `json:"account_type"`
+	Currency    string `json:"currency"`
+}
+
+// UpdateBankAccountRequest representa los campos modificables de una cuenta bancaria
+type UpdateBankAccountRequest struct {
+	AccountType *string `json:"account_type,omitempty"`
+	IsActive    *bool   `json:"is_active,omitempty"`
+}
+
+// UpdateOverdraftRequest representa la configuración de sobregiro que un administrador asigna a una cuenta
+type UpdateOverdraftRequest struct {
+	OverdraftEnabled    bool  `json:"overdraft_enabled"`
+	OverdraftLimitCents int64 `json:"overdraft_limit"`
+}
+
+// AccountLookupResponse es la información pública de una cuenta, usada para confirmar
+// el destinatario antes de una transferencia. No expone el UUID ni el ID de TigerBeetle.
+type AccountLookupResponse struct {
+	AccountHolder string `json:"account_holder"`
+	AccountType   string `json:"account_type"`
+	Bank          string `json:"bank"`
+}
+
+// TransferRequest representa la solicitud de transferencia entre usuarios
+type TransferRequest struct {
+	FromUserID             uuid.UUID `json:"from_user_id"`
+	ToUserID               uuid.UUID `json:"to_user_id"`
+	Amount                 uint64    `json:"amount"`
+	ConfirmedAccountNumber string    `json:"confirmed_account_number"`
+	Category               string    `json:"category,omitempty"`
+	Tags                   []string  `json:"tags,omitempty"`
+}
+
+// AccountTransferRequest representa la solicitud de transferencia desde una cuenta bancaria hacia
+// otra identificada por su número de cuenta
+type AccountTransferRequest struct {
+	ToAccountNumber string `json:"to_account_number"`
+	Amount          uint64 `json:"amount"`
+}
+
+// TransferToEmailRequest representa la solicitud de transferencia hacia el email de otro usuario
+type TransferToEmailRequest struct {
+	ToEmail string `json:"to_email"`
+	Amount  uint64 `json:"amount"`
+}
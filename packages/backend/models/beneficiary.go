@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Beneficiary representa un destinatario guardado por el usuario para sus transferencias
+type Beneficiary struct {
+	ID              uuid.UUID `json:"id" db:"id"`
+	OwnerUserID     uuid.UUID `json:"owner_user_id" db:"owner_user_id"`
+	RecipientUserID uuid.UUID `json:"recipient_user_id" db:"recipient_user_id"`
+	Alias           string    `json:"alias,omitempty" db:"alias"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// CreateBeneficiaryRequest representa la solicitud para guardar un destinatario
+type CreateBeneficiaryRequest struct {
+	RecipientUserID uuid.UUID `json:"recipient_user_id"`
+	Alias           string    `json:"alias"`
+}
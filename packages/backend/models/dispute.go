@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Estados de una disputa
+const (
+	DisputeStatusOpen     = "open"
+	DisputeStatusApproved = "approved"
+	DisputeStatusRejected = "rejected"
+)
+
+// MaxDisputeReasonLength es la longitud máxima del motivo de una disputa
+const MaxDisputeReasonLength = 1000
+
+// Dispute representa el reclamo formal de un usuario sobre una de sus transacciones, que el
+// equipo de cumplimiento aprueba o rechaza
+type Dispute struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	UserID         uuid.UUID  `json:"user_id" db:"user_id"`
+	TransactionID  uuid.UUID  `json:"transaction_id" db:"transaction_id"`
+	Reason         string     `json:"reason" db:"reason"`
+	Status         string     `json:"status" db:"status"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty" db:"resolved_at"`
+	ResolvedBy     *uuid.UUID `json:"resolved_by,omitempty" db:"resolved_by"`
+	ResolutionNote string     `json:"resolution_note,omitempty" db:"resolution_note"`
+}
+
+// CreateDisputeRequest representa la solicitud para disputar una transacción
+type CreateDisputeRequest struct {
+	TransactionID uuid.UUID `json:"transaction_id"`
+	Reason        string    `json:"reason"`
+}
+
+// ResolveDisputeRequest representa la resolución de una disputa ("approved" o "rejected")
+type ResolveDisputeRequest struct {
+	Status string `json:"status"`
+	Note   string `json:"note"`
+}
@@ -0,0 +1,6 @@
+package models
+
+// VerifyEmailRequest representa la solicitud de verificación de email
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
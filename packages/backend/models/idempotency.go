@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyRecord representa la respuesta guardada para una llave de idempotencia
+type IdempotencyRecord struct {
+	KeyHash      string    `json:"-" db:"key_hash"`
+	UserID       uuid.UUID `json:"user_id" db:"user_id"`
+	StatusCode   int       `json:"status_code" db:"status_code"`
+	ContentType  string    `json:"content_type" db:"content_type"`
+	ResponseBody []byte    `json:"-" db:"response_body"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	ExpiresAt    time.Time `json:"expires_at" db:"expires_at"`
+}
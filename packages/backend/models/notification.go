@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification representa una notificación enviada a un usuario
+type Notification struct {
+	ID               uuid.UUID `json:"id" db:"id"`
+	UserID           uuid.UUID `json:"user_id" db:"user_id"`
+	Channel          string    `json:"channel" db:"channel"`
+	NotificationType string    `json:"notification_type" db:"notification_type"`
+	Subject          string    `json:"subject" db:"subject"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+}
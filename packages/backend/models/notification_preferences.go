@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Canales por los que se envían las notificaciones
+const (
+	NotificationChannelEmail = "email"
+	NotificationChannelSMS   = "sms"
+)
+
+// Tipos de notificación que requieren el consentimiento del usuario
+const (
+	NotificationKindLargeTransaction = "large_tx"
+	NotificationKindLogin            = "login"
+	NotificationKindStatement        = "statement"
+)
+
+// NotificationPreferences representa qué notificaciones acepta recibir un usuario y por qué canal.
+// Los valores por defecto deben coincidir con la migración 040.
+type NotificationPreferences struct {
+	UserID         uuid.UUID `json:"user_id" db:"user_id"`
+	EmailLargeTx   bool      `json:"email_large_tx" db:"email_large_tx"`
+	EmailLogin     bool      `json:"email_login" db:"email_login"`
+	EmailStatement bool      `json:"email_statement" db:"email_statement"`
+	SMSLargeTx     bool      `json:"sms_large_tx" db:"sms_large_tx"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// UpdateNotificationPreferencesRequest representa el reemplazo completo de las preferencias de
+// notificación: todos los campos son obligatorios
+type UpdateNotificationPreferencesRequest struct {
+	EmailLargeTx   *bool `json:"email_large_tx"`
+	EmailLogin     *bool `json:"email_login"`
+	EmailStatement *bool `json:"email_statement"`
+	SMSLargeTx     *bool `json:"sms_large_tx"`
+}
+
+// Complete indica si la solicitud trae todas las preferencias
+func (r *UpdateNotificationPreferencesRequest) Complete() bool {
+	return r.EmailLargeTx != nil && r.EmailLogin != nil && r.EmailStatement != nil && r.SMSLargeTx != nil
+}
+
+// DefaultNotificationPreferences retorna las preferencias de un usuario que nunca las configuró
+func DefaultNotificationPreferences(userID uuid.UUID) *NotificationPreferences {
+	return &NotificationPreferences{
+		UserID:         userID,
+		EmailLargeTx:   true,
+		EmailLogin:     false,
+		EmailStatement: true,
+		SMSLargeTx:     false,
+	}
+}
+
+// Allows indica si el usuario acepta recibir el tipo de notificación por el canal indicado.
+// Las combinaciones sin preferencia (como un estado de cuenta por SMS) no se permiten.
+func (p *NotificationPreferences) Allows(channel, kind string) bool {
+	switch channel + ":" + kind {
+	case NotificationChannelEmail + ":" + NotificationKindLargeTransaction:
+		return p.EmailLargeTx
+	case NotificationChannelEmail + ":" + NotificationKindLogin:
+		return p.EmailLogin
+	case NotificationChannelEmail + ":" + NotificationKindStatement:
+		return p.EmailStatement
+	case NotificationChannelSMS + ":" + NotificationKindLargeTransaction:
+		return p.SMSLargeTx
+	default:
+		return false
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OTPCode representa un código de un solo uso enviado al usuario para confirmar una operación.
+// El ID es el token del desafío que recibe el cliente; el código solo se envía al usuario.
+type OTPCode struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	UserID        uuid.UUID  `json:"user_id" db:"user_id"`
+	CodeHash      string     `json:"-" db:"code_hash"`
+	OperationHash string     `json:"-" db:"operation_hash"` // Hash de la operación que confirma el código
+	Attempts      int        `json:"attempts" db:"attempts"`
+	ExpiresAt     time.Time  `json:"expires_at" db:"expires_at"`
+	UsedAt        *time.Time `json:"used_at,omitempty" db:"used_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+// ForgotPasswordRequest representa la solicitud de restablecimiento de contraseña
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// ResetPasswordRequest representa la solicitud para establecer una nueva contraseña con un token
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// ChangePasswordRequest representa la solicitud de cambio de contraseña de un usuario autenticado
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
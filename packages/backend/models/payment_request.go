@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Estados de una solicitud de pago. Una solicitud pendiente cuya fecha de vencimiento pasó ya no
+// puede aprobarse.
+const (
+	PaymentRequestStatusPending   = "pending"
+	PaymentRequestStatusFulfilled = "fulfilled"
+	PaymentRequestStatusRejected  = "rejected"
+)
+
+// MaxPaymentRequestDescriptionLength es la longitud máxima de la descripción de una solicitud de pago
+const MaxPaymentRequestDescriptionLength = 140
+
+// PaymentRequest representa la solicitud de un usuario para que otro le transfiera un monto
+type PaymentRequest struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	RequesterUserID uuid.UUID  `json:"requester_user_id" db:"requester_user_id"`
+	PayerUserID     uuid.UUID  `json:"payer_user_id" db:"payer_user_id"`
+	AmountCents     int64      `json:"amount" db:"amount_cents"`
+	Description     string     `json:"description,omitempty" db:"description"`
+	Status          string     `json:"status" db:"status"`
+	ExpiresAt       time.Time  `json:"expires_at" db:"expires_at"`
+	RespondedAt     *time.Time `json:"responded_at,omitempty" db:"responded_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+}
+
+// CreatePaymentRequestRequest representa la solicitud para pedir dinero a otro usuario. El
+// solicitante es siempre el usuario autenticado.
+type CreatePaymentRequestRequest struct {
+	RequesterUserID uuid.UUID `json:"-"`
+	PayerUserID     uuid.UUID `json:"payer_user_id"`
+	Amount          uint64    `json:"amount"`
+	Description     string    `json:"description,omitempty"`
+}
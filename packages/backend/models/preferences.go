@@ -0,0 +1,95 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Valores por defecto de las preferencias de usuario (deben coincidir con la migración 007)
+const (
+	DefaultLanguage = "es"
+	DefaultTimezone = "America/Tegucigalpa"
+)
+
+// Eventos que pueden generar una notificación por email
+const (
+	NotificationEventDeposit    = "deposit"
+	NotificationEventWithdrawal = "withdrawal"
+	NotificationEventTransfer   = "transfer"
+	NotificationEventLogin      = "login"
+)
+
+// UserPreferences representa la configuración de interfaz y notificaciones de un usuario
+type UserPreferences struct {
+	UserID            uuid.UUID `json:"user_id" db:"user_id"`
+	Language          string    `json:"language" db:"language"`
+	Timezone          string    `json:"timezone" db:"timezone"`
+	EmailOnDeposit    bool      `json:"email_on_deposit" db:"email_on_deposit"`
+	EmailOnWithdrawal bool      `json:"email_on_withdrawal" db:"email_on_withdrawal"`
+	EmailOnTransfer   bool      `json:"email_on_transfer" db:"email_on_transfer"`
+	EmailOnLogin      bool      `json:"email_on_login" db:"email_on_login"`
+	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// UpdateUserPreferencesRequest representa la solicitud para actualizar preferencias
+type UpdateUserPreferencesRequest struct {
+	Language          *string `json:"language,omitempty"`
+	Timezone          *string `json:"timezone,omitempty"`
+	EmailOnDeposit    *bool   `json:"email_on_deposit,omitempty"`
+	EmailOnWithdrawal *bool   `json:"email_on_withdrawal,omitempty"`
+	EmailOnTransfer   *bool   `json:"email_on_transfer,omitempty"`
+	EmailOnLogin      *bool   `json:"email_on_login,omitempty"`
+}
+
+// DefaultUserPreferences retorna las preferencias por defecto de un usuario sin configuración guardada
+func DefaultUserPreferences(userID uuid.UUID) *UserPreferences {
+	return &UserPreferences{
+		UserID:            userID,
+		Language:          DefaultLanguage,
+		Timezone:          DefaultTimezone,
+		EmailOnDeposit:    true,
+		EmailOnWithdrawal: true,
+		EmailOnTransfer:   true,
+		EmailOnLogin:      false,
+	}
+}
+
+// Apply aplica los campos presentes en la solicitud sobre las preferencias actuales
+func (p *UserPreferences) Apply(req *UpdateUserPreferencesRequest) {
+	if req.Language != nil {
+		p.Language = *req.Language
+	}
+	if req.Timezone != nil {
+		p.Timezone = *req.Timezone
+	}
+	if req.EmailOnDeposit != nil {
+		p.EmailOnDeposit = *req.EmailOnDeposit
+	}
+	if req.EmailOnWithdrawal != nil {
+		p.EmailOnWithdrawal = *req.EmailOnWithdrawal
+	}
+	if req.EmailOnTransfer != nil {
+		p.EmailOnTransfer = *req.EmailOnTransfer
+	}
+	if req.EmailOnLogin != nil {
+		p.EmailOnLogin = *req.EmailOnLogin
+	}
+}
+
+// WantsEmail indica si el usuario desea recibir un email para el evento indicado.
+// El servicio de notificaciones debe consultarlo antes de enviar cualquier email.
+func (p *UserPreferences) WantsEmail(event string) bool {
+	switch event {
+	case NotificationEventDeposit:
+		return p.EmailOnDeposit
+	case NotificationEventWithdrawal:
+		return p.EmailOnWithdrawal
+	case NotificationEventTransfer:
+		return p.EmailOnTransfer
+	case NotificationEventLogin:
+		return p.EmailOnLogin
+	default:
+		return false
+	}
+}
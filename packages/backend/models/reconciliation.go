@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReconciliationMismatch representa una diferencia entre el balance de TigerBeetle de un usuario
+// y el calculado a partir de sus transacciones registradas. Los montos están en centavos.
+type ReconciliationMismatch struct {
+	ID                   uuid.UUID `json:"id" db:"id"`
+	RunID                uuid.UUID `json:"run_id" db:"run_id"`
+	UserID               uuid.UUID `json:"user_id" db:"user_id"`
+	TigerBeetleAccountID int64     `json:"tigerbeetle_account_id" db:"tigerbeetle_account_id"`
+	TigerBeetleBalance   int64     `json:"tigerbeetle_balance_cents" db:"tigerbeetle_balance_cents"`
+	ExpectedBalance      int64     `json:"expected_balance_cents" db:"expected_balance_cents"`
+	Difference           int64     `json:"difference_cents" db:"difference_cents"`
+	CreatedAt            time.Time `json:"created_at" db:"created_at"`
+}
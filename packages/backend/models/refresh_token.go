@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken representa un refresh token almacenado (solo se guarda su hash)
+type RefreshToken struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	UserID    uuid.UUID  `json:"user_id" db:"user_id"`
+	UserEmail string     `json:"-" db:"email"`
+	UserRoles []string   `json:"-" db:"roles"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// SessionInfo describe el origen de un inicio de sesión; se guarda junto al refresh token
+type SessionInfo struct {
+	DeviceInfo string
+	IPAddress  string
+}
+
+// Session representa una sesión activa (refresh token no revocado) sin exponer el token
+type Session struct {
+	ID         uuid.UUID `json:"id"`
+	DeviceInfo *string   `json:"device_info,omitempty"`
+	IPAddress  *string   `json:"ip_address,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// RefreshTokenRequest representa la solicitud para renovar el access token o cerrar sesión
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
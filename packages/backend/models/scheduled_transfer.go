@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Estados de una transferencia programada
+const (
+	ScheduledTransferStatusPending   = "pending"
+	ScheduledTransferStatusExecuted  = "executed"
+	ScheduledTransferStatusFailed    = "failed"
+	ScheduledTransferStatusCompleted = "completed" // Última ejecución de una serie recurrente
+	ScheduledTransferStatusCancelled = "cancelled"
+)
+
+// Tipos de recurrencia de una transferencia programada
+const (
+	RecurrenceOnce    = "once"
+	RecurrenceWeekly  = "weekly"
+	RecurrenceMonthly = "monthly"
+)
+
+// ScheduledTransfer representa una transferencia entre usuarios que se ejecuta en una fecha futura.
+// Las recurrentes generan una fila nueva por cada ejecución.
+type ScheduledTransfer struct {
+	ID                uuid.UUID  `json:"id" db:"id"`
+	FromUserID        uuid.UUID  `json:"from_user_id" db:"from_user_id"`
+	ToUserID          uuid.UUID  `json:"to_user_id" db:"to_user_id"`
+	AmountCents       int64      `json:"amount" db:"amount_cents"`
+	ScheduledAt       time.Time  `json:"scheduled_at" db:"scheduled_at"`
+	RecurrenceType    string     `json:"recurrence_type" db:"recurrence_type"`
+	RecurrenceEndDate *time.Time `json:"recurrence_end_date,omitempty" db:"recurrence_end_date"`
+	ExecutedAt        *time.Time `json:"executed_at,omitempty" db:"executed_at"`
+	CancelledAt       *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	Status            string     `json:"status" db:"status"`
+	ErrorText         string     `json:"error_text,omitempty" db:"error_text"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+}
+
+// ScheduleTransferRequest representa la solicitud para programar una transferencia. El usuario
+// origen es siempre el usuario autenticado.
+type ScheduleTransferRequest struct {
+	FromUserID        uuid.UUID  `json:"-"`
+	ToUserID          uuid.UUID  `json:"to_user_id"`
+	Amount            uint64     `json:"amount"`
+	ScheduledAt       time.Time  `json:"scheduled_at"`
+	RecurrenceType    string     `json:"recurrence_type,omitempty"` // "once" por defecto
+	RecurrenceEndDate *time.Time `json:"recurrence_end_date,omitempty"`
+}
+
+// UpdateScheduledTransferRequest representa los campos modificables de una transferencia pendiente
+type UpdateScheduledTransferRequest struct {
+	Amount            *uint64    `json:"amount,omitempty"`
+	ScheduledAt       *time.Time `json:"scheduled_at,omitempty"`
+	RecurrenceEndDate *time.Time `json:"recurrence_end_date,omitempty"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "github.com/google/uuid"
+
+// MaxSplitTargets es la cantidad máxima de destinatarios de una transferencia dividida
+const MaxSplitTargets = 50
+
+// MaxSplitDescriptionLength es la longitud máxima de la descripción de una transferencia dividida
+const MaxSplitDescriptionLength = 140
+
+// SplitTarget es uno de los destinatarios de una transferencia dividida y el monto que recibe
+type SplitTarget struct {
+	ToUserID    uuid.UUID `json:"to_user_id"`
+	AmountCents uint64    `json:"amount_cents"`
+}
+
+// SplitTransferRequest representa la solicitud para pagar un gasto grupal a varios usuarios a la vez
+type SplitTransferRequest struct {
+	Splits      []SplitTarget `json:"splits"`
+	Description string        `json:"description,omitempty"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// UserStats representa el resumen de un usuario para el dashboard
+// combinando datos de PostgreSQL y TigerBeetle
+type UserStats struct {
+	User                    UserResponse             `json:"user"`
+	Accounts                []BankAccountWithBalance `json:"accounts"`
+	TotalBalance            int64                    `json:"total_balance"`
+	MonthlyTransactionCount int                      `json:"monthly_transaction_count"`
+	MonthlyVolume           int64                    `json:"monthly_volume"`
+	LastTransactionAt       *time.Time               `json:"last_transaction_at,omitempty"`
+	BeneficiaryCount        int                      `json:"beneficiary_count"`
+}
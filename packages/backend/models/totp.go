@@ -0,0 +1,12 @@
+package models
+
+// TOTPCodeRequest representa la solicitud con un código TOTP de 6 dígitos
+type TOTPCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// TOTPConfirmRequest representa la solicitud para completar el login con el segundo factor
+type TOTPConfirmRequest struct {
+	MFAChallengeToken string `json:"mfa_challenge_token"`
+	Code              string `json:"code"`
+}
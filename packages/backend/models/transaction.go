@@ -0,0 +1,105 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tipos de transacción almacenados en PostgreSQL
+const (
+	TransactionTypeTransfer          = "transfer"
+	TransactionTypeDeposit           = "deposit"
+	TransactionTypeWithdrawal        = "withdrawal"
+	TransactionTypeBalanceCorrection = "balance_correction"
+	TransactionTypeReversal          = "reversal"
+	TransactionTypeFee               = "fee"
+)
+
+// Estados de una transacción
+const (
+	TransactionStatusPending   = "pending"
+	TransactionStatusCompleted = "completed"
+	TransactionStatusFailed    = "failed"
+	TransactionStatusCancelled = "cancelled"
+)
+
+// Transaction representa el registro de una transacción (los movimientos reales están en TigerBeetle)
+type Transaction struct {
+	ID                    uuid.UUID  `json:"id" db:"id"`
+	TigerBeetleTransferID int64      `json:"tigerbeetle_transfer_id" db:"tigerbeetle_transfer_id"`
+	FromAccountID         *uuid.UUID `json:"from_account_id,omitempty" db:"from_account_id"`
+	ToAccountID           *uuid.UUID `json:"to_account_id,omitempty" db:"to_account_id"`
+	Amount                int64      `json:"amount" db:"amount"` // En centavos
+	Currency              string     `json:"currency" db:"currency"`
+	Description           string     `json:"description" db:"description"`
+	TransactionType       string     `json:"transaction_type" db:"transaction_type"`
+	Status                string     `json:"status" db:"status"`
+	Category              string     `json:"category,omitempty" db:"category"`
+	Tags                  []string   `json:"tags" db:"tags"`
+	CreatedBy             *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	RecipientUserID       *uuid.UUID `json:"recipient_user_id,omitempty" db:"recipient_user_id"`
+	RelatedTransactionID  *uuid.UUID `json:"related_transaction_id,omitempty" db:"related_transaction_id"`
+	CreatedAt             time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Límites de la categoría y las etiquetas de una transacción
+const (
+	MaxCategoryLength = 50
+	MaxTags           = 10
+	MaxTagLength      = 30
+)
+
+// UncategorizedCategory agrupa en el desglose de gastos las transacciones sin categoría
+const UncategorizedCategory = "uncategorized"
+
+// UpdateTransactionCategoryRequest representa la solicitud para recategorizar una transacción
+type UpdateTransactionCategoryRequest struct {
+	Category string `json:"category"`
+}
+
+// CategorySpending representa el total gastado en una categoría. Total está en centavos.
+type CategorySpending struct {
+	Category string `json:"category"`
+	Total    int64  `json:"total"`
+	Count    int    `json:"count"`
+}
+
+// CategorySummary representa el total gastado y la cantidad de transacciones de una categoría
+// dentro de un resumen de gastos. Total está en centavos.
+type CategorySummary struct {
+	Total int64 `json:"total"`
+	Count int   `json:"count"`
+}
+
+// SpendingSummary representa el resumen de movimientos de un usuario en un rango de fechas.
+// Los montos están en centavos: TotalDebits es lo que salió de sus cuentas, TotalCredits lo que
+// entró, y ByCategoryBreakdown desglosa los retiros y transferencias por categoría.
+type SpendingSummary struct {
+	From                time.Time                  `json:"from"`
+	To                  time.Time                  `json:"to"`
+	TotalDebits         int64                      `json:"total_debits"`
+	TotalCredits        int64                      `json:"total_credits"`
+	TransactionCount    int                        `json:"transaction_count"`
+	ByCategoryBreakdown map[string]CategorySummary `json:"by_category"`
+}
+
+// TransactionPage representa una página del historial de transacciones. NextCursor es nil
+// cuando no hay más resultados.
+type TransactionPage struct {
+	Transactions []*Transaction `json:"transactions"`
+	NextCursor   *uuid.UUID     `json:"next_cursor"`
+}
+
+// TransactionFilter representa los filtros opcionales del historial de transacciones.
+// Los montos están en centavos y Cursor es el ID de la última transacción de la página anterior.
+type TransactionFilter struct {
+	FromDate  *time.Time
+	ToDate    *time.Time
+	Type      *string
+	MinAmount *int64
+	MaxAmount *int64
+	Cursor    *uuid.UUID
+	Limit     int
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tipos de evento de transacción
+const (
+	TransactionEventDeposit    = "deposit"
+	TransactionEventWithdrawal = "withdrawal"
+	TransactionEventTransfer   = "transfer"
+)
+
+// TransactionEvent representa una transacción completada que se publica a los suscriptores
+type TransactionEvent struct {
+	ID         string     `json:"id"`
+	Type       string     `json:"type"`
+	Amount     uint64     `json:"amount"`
+	FromUserID *uuid.UUID `json:"from_user_id,omitempty"`
+	ToUserID   *uuid.UUID `json:"to_user_id,omitempty"`
+	Timestamp  time.Time  `json:"timestamp"`
+}
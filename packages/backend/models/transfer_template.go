@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxTransferTemplateNameLength es la longitud máxima del nombre de una plantilla de transferencia
+const MaxTransferTemplateNameLength = 100
+
+// TransferTemplate representa una transferencia frecuente guardada por el usuario para
+// ejecutarla sin volver a llenar el formulario
+type TransferTemplate struct {
+	ID              uuid.UUID `json:"id" db:"id"`
+	OwnerUserID     uuid.UUID `json:"owner_user_id" db:"owner_user_id"`
+	RecipientUserID uuid.UUID `json:"recipient_user_id" db:"recipient_user_id"`
+	AmountCents     int64     `json:"amount" db:"amount_cents"`
+	Description     string    `json:"description,omitempty" db:"description"`
+	Name            string    `json:"name" db:"name"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// CreateTransferTemplateRequest representa la solicitud para guardar una plantilla de transferencia
+type CreateTransferTemplateRequest struct {
+	Name            string    `json:"name"`
+	RecipientUserID uuid.UUID `json:"recipient_user_id"`
+	Amount          uint64    `json:"amount"`
+	Description     string    `json:"description,omitempty"`
+}
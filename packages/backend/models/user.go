@@ -6,6 +6,21 @@ import (
 	"github.com/google/uuid"
 )
 
+// Roles disponibles para los usuarios
+const (
+	RoleUser       = "user"
+	RoleAdmin      = "admin"
+	RoleCompliance = "compliance"
+)
+
+// Estados del proceso KYC (Know Your Customer)
+const (
+	KYCStatusPending   = "pending"
+	KYCStatusSubmitted = "submitted"
+	KYCStatusApproved  = "approved"
+	KYCStatusRejected  = "rejected"
+)
+
 // User representa un usuario en el sistema bancario
 type User struct {
 	ID                   uuid.UUID  `json:"id" db:"id"`
@@ -16,25 +31,91 @@ type User struct {
 	Phone                *string    `json:"phone,omitempty" db:"phone"`
 	DateOfBirth          *time.Time `json:"date_of_birth,omitempty" db:"date_of_birth"`
 	TigerBeetleAccountID *int64     `json:"tigerbeetle_account_id,omitempty" db:"tigerbeetle_account_id"`
+	Roles                []string   `json:"roles" db:"roles"`
+	KYCStatus            string     `json:"kyc_status" db:"kyc_status"`
 	CreatedAt            time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt            time.Time  `json:"updated_at" db:"updated_at"`
 	IsActive             bool       `json:"is_active" db:"is_active"`
 	EmailVerified        bool       `json:"email_verified" db:"email_verified"`
+	TOTPSecret           *string    `json:"-" db:"totp_secret"` // Cifrado; nunca se expone
+	TOTPEnabled          bool       `json:"totp_enabled" db:"totp_enabled"`
+	// Límites diarios en centavos
+	DailyTransferLimitCents   int64 `json:"daily_transfer_limit_cents" db:"daily_transfer_limit_cents"`
+	DailyWithdrawalLimitCents int64 `json:"daily_withdrawal_limit_cents" db:"daily_withdrawal_limit_cents"`
+	// Congelamiento de la cuenta por administración o cumplimiento
+	IsFrozen     bool       `json:"is_frozen" db:"is_frozen"`
+	FrozenAt     *time.Time `json:"frozen_at,omitempty" db:"frozen_at"`
+	FrozenReason *string    `json:"frozen_reason,omitempty" db:"frozen_reason"`
+	// Último inicio de sesión exitoso; nil si el usuario nunca ha iniciado sesión
+	LastLoginAt *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
+	// Referencia al documento de identidad presentado y fecha de la última revisión KYC
+	KYCDocumentRef *string    `json:"kyc_document_ref,omitempty" db:"kyc_document_ref"`
+	KYCReviewedAt  *time.Time `json:"kyc_reviewed_at,omitempty" db:"kyc_reviewed_at"`
 }
 
 // CreateUserRequest representa la estructura para crear un nuevo usuario
 type CreateUserRequest struct {
-	Email     string `json:"email" validate:"required,email"`
-	Password  string `json:"password" validate:"required,min=8"`
-	FirstName string `json:"first_name" validate:"required,min=2"`
-	LastName  string `json:"last_name" validate:"required,min=2"`
+	Email     string `json:"email" validate:"required,email,max=255"`
+	Password  string `json:"password" validate:"required,min=8,max=72"`
+	FirstName string `json:"first_name" validate:"required,min=2,max=100"`
+	LastName  string `json:"last_name" validate:"required,min=2,max=100"`
+	// Phone es opcional; se aceptan espacios y guiones, que se eliminan antes de validar E.164
+	Phone *string `json:"phone,omitempty" validate:"omitempty,phone"`
+	// DateOfBirth es opcional; si se indica, el usuario debe tener al menos 18 años
+	DateOfBirth *time.Time `json:"date_of_birth,omitempty"`
+}
+
+// UpdateKYCRequest representa la estructura para que un administrador actualice el estado KYC
+type UpdateKYCRequest struct {
+	KYCStatus      string  `json:"kyc_status"`
+	KYCDocumentRef *string `json:"kyc_document_ref,omitempty"`
+}
+
+// KYCStatusResponse representa el estado de verificación de identidad de un usuario
+type KYCStatusResponse struct {
+	UserID         uuid.UUID  `json:"user_id"`
+	KYCStatus      string     `json:"kyc_status"`
+	KYCDocumentRef *string    `json:"kyc_document_ref,omitempty"`
+	KYCReviewedAt  *time.Time `json:"kyc_reviewed_at,omitempty"`
+}
+
+// ToKYCStatusResponse retorna el estado KYC del usuario
+func (u *User) ToKYCStatusResponse() KYCStatusResponse {
+	return KYCStatusResponse{
+		UserID:         u.ID,
+		KYCStatus:      u.KYCStatus,
+		KYCDocumentRef: u.KYCDocumentRef,
+		KYCReviewedAt:  u.KYCReviewedAt,
+	}
+}
+
+// UpdateDateOfBirthRequest representa la estructura para cambiar la fecha de nacimiento del usuario
+type UpdateDateOfBirthRequest struct {
+	DateOfBirth time.Time `json:"date_of_birth" validate:"required"`
+}
+
+// UpdatePhoneRequest representa la estructura para cambiar el teléfono del usuario
+type UpdatePhoneRequest struct {
+	Phone string `json:"phone" validate:"required,phone"`
+}
+
+// CreateAdminUserRequest representa la estructura para que un administrador cree un usuario
+// con rol, estado KYC y depósito inicial en una sola operación
+type CreateAdminUserRequest struct {
+	Email                  string `json:"email" validate:"required,email,max=255"`
+	Password               string `json:"password" validate:"required,min=8,max=72"`
+	FirstName              string `json:"first_name" validate:"required,min=2,max=100"`
+	LastName               string `json:"last_name" validate:"required,min=2,max=100"`
+	Role                   string `json:"role" validate:"omitempty,oneof=user admin compliance"`
+	KYCStatus              string `json:"kyc_status" validate:"omitempty,oneof=pending submitted approved rejected"`
+	InitialDepositCentavos uint64 `json:"initial_deposit_centavos"`
 }
 
 // UpdateUserRequest representa la estructura para actualizar un usuario
 type UpdateUserRequest struct {
-	Email     *string `json:"email,omitempty" validate:"omitempty,email"`
-	FirstName *string `json:"first_name,omitempty" validate:"omitempty,min=2"`
-	LastName  *string `json:"last_name,omitempty" validate:"omitempty,min=2"`
+	Email     *string `json:"email,omitempty" validate:"omitempty,email,max=255"`
+	FirstName *string `json:"first_name,omitempty" validate:"omitempty,min=2,max=100"`
+	LastName  *string `json:"last_name,omitempty" validate:"omitempty,min=2,max=100"`
 }
 
 // LoginRequest representa la estructura para el login
@@ -43,6 +124,12 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+// UpdateUserFlagsRequest representa los indicadores de cuenta que un administrador puede ajustar
+type UpdateUserFlagsRequest struct {
+	IsActive      *bool `json:"is_active,omitempty"`
+	EmailVerified *bool `json:"email_verified,omitempty"`
+}
+
 // UserResponse representa la respuesta pública del usuario (sin datos sensibles)
 type UserResponse struct {
 	ID                   uuid.UUID  `json:"id"`
@@ -52,10 +139,29 @@ type UserResponse struct {
 	Phone                *string    `json:"phone,omitempty"`
 	DateOfBirth          *time.Time `json:"date_of_birth,omitempty"`
 	TigerBeetleAccountID *int64     `json:"tigerbeetle_account_id,omitempty"`
+	Roles                []string   `json:"roles"`
+	KYCStatus            string     `json:"kyc_status"`
 	CreatedAt            time.Time  `json:"created_at"`
 	UpdatedAt            time.Time  `json:"updated_at"`
 	IsActive             bool       `json:"is_active"`
 	EmailVerified        bool       `json:"email_verified"`
+	TOTPEnabled          bool       `json:"totp_enabled"`
+	// Límites diarios en centavos
+	DailyTransferLimitCents   int64 `json:"daily_transfer_limit_cents"`
+	DailyWithdrawalLimitCents int64 `json:"daily_withdrawal_limit_cents"`
+	// Congelamiento de la cuenta por administración o cumplimiento
+	IsFrozen     bool       `json:"is_frozen"`
+	FrozenAt     *time.Time `json:"frozen_at,omitempty"`
+	FrozenReason *string    `json:"frozen_reason,omitempty"`
+	// Último inicio de sesión exitoso, para mostrar "última conexión"
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+}
+
+// UserPage representa una página del listado de usuarios. NextCursor es el ID que se envía en
+// ?after= para obtener la página siguiente y es nil cuando no hay más resultados.
+type UserPage struct {
+	Users      []UserResponse `json:"users"`
+	NextCursor *uuid.UUID     `json:"next_cursor"`
 }
 
 // ToResponse convierte un User a UserResponse
@@ -68,9 +174,32 @@ func (u *User) ToResponse() UserResponse {
 		Phone:                u.Phone,
 		DateOfBirth:          u.DateOfBirth,
 		TigerBeetleAccountID: u.TigerBeetleAccountID,
+		Roles:                u.Roles,
+		KYCStatus:            u.KYCStatus,
 		CreatedAt:            u.CreatedAt,
 		UpdatedAt:            u.UpdatedAt,
 		IsActive:             u.IsActive,
 		EmailVerified:        u.EmailVerified,
+		TOTPEnabled:          u.TOTPEnabled,
+
+		DailyTransferLimitCents:   u.DailyTransferLimitCents,
+		DailyWithdrawalLimitCents: u.DailyWithdrawalLimitCents,
+
+		IsFrozen:     u.IsFrozen,
+		FrozenAt:     u.FrozenAt,
+		FrozenReason: u.FrozenReason,
+		LastLoginAt:  u.LastLoginAt,
+	}
+}
+
+// HasRole indica si el usuario tiene alguno de los roles indicados
+func (u *User) HasRole(roles ...string) bool {
+	for _, userRole := range u.Roles {
+		for _, role := range roles {
+			if userRole == role {
+				return true
+			}
+		}
 	}
+	return false
 }
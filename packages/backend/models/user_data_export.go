@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// UserDataExport reúne todos los datos que el banco guarda sobre un usuario, para ejercer su
+// derecho a la portabilidad de datos
+type UserDataExport struct {
+	ExportedAt   time.Time      `json:"exported_at"`
+	User         UserResponse   `json:"user"`
+	BankAccounts []*BankAccount `json:"bank_accounts"`
+	Transactions []*Transaction `json:"transactions"`
+	AuditLogs    []*AuditLog    `json:"audit_logs"`
+}
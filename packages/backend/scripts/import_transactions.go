@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"os"
 	"time"
+
+	"banca-en-linea/backend/internal/banking"
 )
 
 type JSONUser struct {
@@ -129,8 +131,13 @@ func main() {
 			fmt.Printf("Procesando transacción %d/%d...\n", i+1, len(jsonData.Transactions))
 		}
 
-		// Convertir amount a uint64 (centavos)
-		amountCents := uint64(transaction.Amount * 100)
+		// Convertir amount a uint64 (centavos) sin perder precisión
+		amountCents, err := banking.HNL.FromFloat(transaction.Amount)
+		if err != nil {
+			fmt.Printf("Monto inválido en transacción %d: %v\n", i+1, err)
+			errorCount++
+			continue
+		}
 
 		switch transaction.Type {
 		case "deposit":
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/mocks"
+	"banca-en-linea/backend/models"
+)
+
+// memoryDormancyRepository marca como durmientes las cuentas cuya última transacción es anterior
+// a la fecha indicada
+type memoryDormancyRepository struct {
+	accounts        []*models.BankAccount
+	lastTransaction map[uuid.UUID]time.Time
+}
+
+func (r *memoryDormancyRepository) MarkDormant(ctx context.Context, inactiveSince time.Time) ([]*models.BankAccount, error) {
+	var marked []*models.BankAccount
+	for _, account := range r.accounts {
+		if !account.IsActive || account.IsDormant || !account.CreatedAt.Before(inactiveSince) ||
+			!r.lastTransaction[account.ID].Before(inactiveSince) {
+			continue
+		}
+		now := time.Now()
+		account.IsDormant = true
+		account.DormantSince = &now
+		marked = append(marked, account)
+	}
+	return marked, nil
+}
+
+func (r *memoryDormancyRepository) ClearReactivated(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+// recordingDormancyNotifier guarda los titulares notificados
+type recordingDormancyNotifier struct {
+	notified []uuid.UUID
+}
+
+func (n *recordingDormancyNotifier) SendDormancyNotice(user *models.User, account *models.BankAccount) error {
+	n.notified = append(n.notified, user.ID)
+	return nil
+}
+
+func TestAccountDormancyService_DetectDormant(t *testing.T) {
+	owner := &models.User{ID: uuid.New(), Email: "ana@example.com"}
+	twoYearsAgo := time.Now().AddDate(-2, 0, 0)
+
+	dormant := &models.BankAccount{ID: uuid.New(), UserID: owner.ID, IsActive: true, CreatedAt: twoYearsAgo}
+	recent := &models.BankAccount{ID: uuid.New(), UserID: owner.ID, IsActive: true, CreatedAt: twoYearsAgo}
+	newAccount := &models.BankAccount{ID: uuid.New(), UserID: owner.ID, IsActive: true, CreatedAt: time.Now().AddDate(0, -1, 0)}
+	closed := &models.BankAccount{ID: uuid.New(), UserID: owner.ID, CreatedAt: twoYearsAgo}
+
+	repo := &memoryDormancyRepository{
+		accounts: []*models.BankAccount{dormant, recent, newAccount, closed},
+		lastTransaction: map[uuid.UUID]time.Time{
+			dormant.ID: time.Now().AddDate(-1, 0, -1),
+			recent.ID:  time.Now().AddDate(0, -11, 0),
+		},
+	}
+	userRepo := new(mocks.MockUserRepository)
+	userRepo.On("GetByID", mock.Anything, owner.ID).Return(owner, nil)
+	notifier := &recordingDormancyNotifier{}
+	service := db.NewAccountDormancyService(repo, userRepo, notifier)
+
+	ids, err := service.DetectDormant(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{dormant.ID}, ids)
+	assert.True(t, dormant.IsDormant)
+	assert.NotNil(t, dormant.DormantSince)
+	assert.Equal(t, []uuid.UUID{owner.ID}, notifier.notified)
+
+	// Una cuenta ya marcada no se vuelve a notificar
+	ids, err = service.DetectDormant(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+	assert.Len(t, notifier.notified, 1)
+}
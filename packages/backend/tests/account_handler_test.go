@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/auth"
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/handlers"
+	"banca-en-linea/backend/internal/middleware"
+	"banca-en-linea/backend/internal/mocks"
+	"banca-en-linea/backend/internal/tigerbeetle"
+	"banca-en-linea/backend/models"
+)
+
+// accountHandlerFixture reúne el handler de cuentas con servicios reales sobre el stub de TigerBeetle
+type accountHandlerFixture struct {
+	handler  *handlers.AccountHandler
+	accounts *db.BankAccountService
+	repo     *memoryBankAccountRepository
+	users    *mocks.MockUserRepository
+	stub     *tigerbeetle.Service
+}
+
+func newAccountHandlerFixture(t *testing.T) *accountHandlerFixture {
+	t.Helper()
+
+	stub := tigerbeetle.NewServiceStub()
+	require.NoError(t, stub.InitializeMasterAccounts())
+
+	repo := newMemoryBankAccountRepository()
+	users := new(mocks.MockUserRepository)
+	userService := db.NewUserService(users, stub, db.WithBankAccountRepository(repo))
+	accounts := db.NewBankAccountService(repo, nil, stub, db.NewMemoryTransferIDGenerator())
+
+	return &accountHandlerFixture{
+		handler:  handlers.NewAccountHandler(userService, accounts, handlers.NewLargeTransferGuard(nil, 100000)),
+		accounts: accounts,
+		repo:     repo,
+		users:    users,
+		stub:     stub,
+	}
+}
+
+// openAccount crea un usuario con una cuenta del tipo indicado
+func (f *accountHandlerFixture) openAccount(t *testing.T, accountType string) (*models.User, *models.BankAccount) {
+	t.Helper()
+
+	user := &models.User{ID: uuid.New(), IsActive: true, DailyWithdrawalLimitCents: 1000000, DailyTransferLimitCents: 1000000}
+	f.users.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+
+	account, err := f.accounts.CreateAccount(context.Background(), user.ID, &models.CreateBankAccountRequest{AccountType: accountType})
+	require.NoError(t, err)
+	return user, account
+}
+
+// balance retorna el balance de la cuenta en TigerBeetle
+func (f *accountHandlerFixture) balance(t *testing.T, account *models.BankAccount) int64 {
+	t.Helper()
+
+	debits, credits, err := f.stub.GetAccountBalance(uint64(account.TigerBeetleAccountID))
+	require.NoError(t, err)
+	return int64(credits) - int64(debits)
+}
+
+// accountRequest arma una petición autenticada sobre /accounts/{id}
+func accountRequest(method, path string, accountID, userID uuid.UUID, body string) *http.Request {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req = mux.SetURLVars(req, map[string]string{"id": accountID.String()})
+	ctx := context.WithValue(req.Context(), middleware.UserContextKey, &auth.Claims{UserID: userID, Roles: []string{models.RoleUser}})
+	return req.WithContext(ctx)
+}
+
+func TestAccountHandler_ListAccounts_RejectsOtherUsers(t *testing.T) {
+	handler := handlers.NewAccountHandler(nil, nil, nil)
+	owner := uuid.New()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/"+owner.String()+"/accounts", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": owner.String()})
+	ctx := context.WithValue(req.Context(), middleware.UserContextKey, &auth.Claims{UserID: uuid.New(), Roles: []string{models.RoleUser}})
+	rec := httptest.NewRecorder()
+	handler.ListAccounts(rec, req.WithContext(ctx))
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestAccountHandler_Withdraw_EnforcesMinimumBalance(t *testing.T) {
+	f := newAccountHandlerFixture(t)
+	user, savings := f.openAccount(t, models.AccountTypeSavings)
+	f.repo.accounts[savings.ID].MinimumBalanceCents = 50000
+
+	rec := httptest.NewRecorder()
+	f.handler.Deposit(rec, accountRequest(http.MethodPost, "/api/v1/accounts/"+savings.ID.String()+"/deposit", savings.ID, user.ID, `{"amount":60000}`))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// Dejaría 499.99 en una cuenta de ahorros con mínimo de 500.00
+	rec = httptest.NewRecorder()
+	f.handler.Withdraw(rec, accountRequest(http.MethodPost, "/api/v1/accounts/"+savings.ID.String()+"/withdraw", savings.ID, user.ID, `{"amount":10001}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, int64(60000), f.balance(t, savings))
+
+	rec = httptest.NewRecorder()
+	f.handler.Withdraw(rec, accountRequest(http.MethodPost, "/api/v1/accounts/"+savings.ID.String()+"/withdraw", savings.ID, user.ID, `{"amount":10000}`))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, int64(50000), f.balance(t, savings))
+}
+
+func TestAccountHandler_RejectsOtherUsersAccounts(t *testing.T) {
+	f := newAccountHandlerFixture(t)
+	_, account := f.openAccount(t, models.AccountTypeChecking)
+
+	rec := httptest.NewRecorder()
+	f.handler.Deposit(rec, accountRequest(http.MethodPost, "/api/v1/accounts/"+account.ID.String()+"/deposit", account.ID, uuid.New(), `{"amount":1000}`))
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, int64(0), f.balance(t, account))
+}
+
+func TestAccountHandler_Transfer_ToAccountNumber(t *testing.T) {
+	f := newAccountHandlerFixture(t)
+	sender, from := f.openAccount(t, models.AccountTypeChecking)
+	_, to := f.openAccount(t, models.AccountTypeChecking)
+	require.NoError(t, f.stub.Deposit(uint64(from.TigerBeetleAccountID), 20000, 1))
+
+	body := `{"to_account_number":"` + to.AccountNumber + `","amount":5000}`
+	rec := httptest.NewRecorder()
+	f.handler.Transfer(rec, accountRequest(http.MethodPost, "/api/v1/accounts/"+from.ID.String()+"/transfer", from.ID, sender.ID, body))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var response struct {
+		Fee int64 `json:"fee"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	assert.Equal(t, int64(5000), f.balance(t, to))
+	assert.Equal(t, int64(20000-5000)-response.Fee, f.balance(t, from))
+}
@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/auth"
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/handlers"
+	"banca-en-linea/backend/internal/middleware"
+	"banca-en-linea/backend/internal/mocks"
+	"banca-en-linea/backend/internal/tigerbeetle"
+	"banca-en-linea/backend/models"
+)
+
+// adminHandlerFixture reúne el handler de administración con dos administradores y servicios
+// reales sobre el stub de TigerBeetle
+type adminHandlerFixture struct {
+	handler   *handlers.AdminHandler
+	auth      *auth.Service
+	repo      *memoryBankAccountRepository
+	txRepo    *ledgerTransactionRepository
+	users     *mocks.MockUserRepository
+	stub      *tigerbeetle.Service
+	requester *models.User
+	confirmer *models.User
+}
+
+func newAdminHandlerFixture(t *testing.T) *adminHandlerFixture {
+	t.Helper()
+
+	stub := tigerbeetle.NewServiceStub()
+	require.NoError(t, stub.InitializeMasterAccounts())
+
+	repo := newMemoryBankAccountRepository()
+	txRepo := &ledgerTransactionRepository{}
+	users := new(mocks.MockUserRepository)
+	authService := auth.NewService(auth.WithTokenBlacklist(&memoryTokenBlacklist{jtis: make(map[string]time.Time)}))
+	userService := db.NewUserService(users, stub, db.WithBankAccountRepository(repo))
+	accounts := db.NewBankAccountService(repo, txRepo, stub, db.NewMemoryTransferIDGenerator())
+
+	requester := &models.User{ID: uuid.New(), Email: "admin1@example.com", IsActive: true, Roles: []string{models.RoleAdmin}}
+	confirmer := &models.User{ID: uuid.New(), Email: "admin2@example.com", IsActive: true, Roles: []string{models.RoleAdmin}}
+	users.On("GetByID", mock.Anything, requester.ID).Return(requester, nil)
+	users.On("GetByID", mock.Anything, confirmer.ID).Return(confirmer, nil)
+
+	return &adminHandlerFixture{
+		handler:   handlers.NewAdminHandler(userService, accounts, authService),
+		auth:      authService,
+		repo:      repo,
+		txRepo:    txRepo,
+		users:     users,
+		stub:      stub,
+		requester: requester,
+		confirmer: confirmer,
+	}
+}
+
+// token emite un access token para el usuario
+func (f *adminHandlerFixture) token(t *testing.T, user *models.User) string {
+	t.Helper()
+
+	token, err := f.auth.GenerateToken(user)
+	require.NoError(t, err)
+	return token
+}
+
+// openAccount crea una cuenta bancaria con su cuenta en el stub de TigerBeetle
+func (f *adminHandlerFixture) openAccount(t *testing.T, tbAccountID int64) *models.BankAccount {
+	t.Helper()
+
+	account, err := f.repo.Create(context.Background(), &models.BankAccount{ID: uuid.New(), UserID: uuid.New(), TigerBeetleAccountID: tbAccountID})
+	require.NoError(t, err)
+	_, err = f.stub.CreateUserAccount(uint64(tbAccountID))
+	require.NoError(t, err)
+	return account
+}
+
+// recalculate llama a RecalculateBalance como el administrador solicitante con el token de
+// confirmación indicado
+func (f *adminHandlerFixture) recalculate(accountID uuid.UUID, confirmationToken string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/admin/accounts/"+accountID.String()+"/recalculate", strings.NewReader(`{"reason":"Ajuste de conciliación"}`))
+	req = mux.SetURLVars(req, map[string]string{"id": accountID.String()})
+	if confirmationToken != "" {
+		req.Header.Set(handlers.ConfirmationTokenHeader, confirmationToken)
+	}
+	ctx := context.WithValue(req.Context(), middleware.UserContextKey, &auth.Claims{UserID: f.requester.ID, Roles: []string{models.RoleAdmin}})
+	rec := httptest.NewRecorder()
+	f.handler.RecalculateBalance(rec, req.WithContext(ctx))
+	return rec
+}
+
+func TestAdminHandler_RecalculateBalance_RejectsRevokedConfirmation(t *testing.T) {
+	f := newAdminHandlerFixture(t)
+	token := f.token(t, f.confirmer)
+
+	claims, err := f.auth.ValidateToken(token)
+	require.NoError(t, err)
+	require.NoError(t, f.auth.RevokeToken(claims))
+
+	rec := f.recalculate(uuid.New(), token)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Invalid confirmation token")
+}
+
+func TestAdminHandler_RecalculateBalance_RequiresSecondAdmin(t *testing.T) {
+	f := newAdminHandlerFixture(t)
+	account := f.openAccount(t, 7001)
+	customer := &models.User{ID: uuid.New(), Email: "cliente@example.com", IsActive: true, Roles: []string{models.RoleUser}}
+	f.users.On("GetByID", mock.Anything, customer.ID).Return(customer, nil)
+
+	for name, tc := range map[string]struct {
+		token  string
+		detail string
+	}{
+		"missing":   {"", "Confirmation from a second admin is required"},
+		"invalid":   {"not-a-jwt", "Invalid confirmation token"},
+		"same":      {f.token(t, f.requester), "Confirmation must come from a different admin"},
+		"non-admin": {f.token(t, customer), "Confirming user is not an admin"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := f.recalculate(account.ID, tc.token)
+			assert.Equal(t, http.StatusForbidden, rec.Code)
+			assert.Contains(t, rec.Body.String(), tc.detail)
+		})
+	}
+	assert.Empty(t, f.txRepo.rows)
+}
+
+func TestAdminHandler_RecalculateBalance_ConflictOnUnrecordedMovements(t *testing.T) {
+	f := newAdminHandlerFixture(t)
+	account := f.openAccount(t, 7001)
+	require.NoError(t, f.stub.Deposit(7001, 10000, 90001))
+
+	rec := f.recalculate(account.ID, f.token(t, f.confirmer))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Empty(t, f.txRepo.rows)
+}
+
+func TestAdminHandler_RecalculateBalance_RecordsConfirmingAdmin(t *testing.T) {
+	f := newAdminHandlerFixture(t)
+	account := f.openAccount(t, 7001)
+	_, err := f.txRepo.Create(context.Background(), &models.Transaction{
+		ToAccountID:     &account.ID,
+		Amount:          10000,
+		TransactionType: models.TransactionTypeDeposit,
+		Status:          models.TransactionStatusCompleted,
+	})
+	require.NoError(t, err)
+
+	rec := f.recalculate(account.ID, f.token(t, f.confirmer))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"balance":10000`)
+
+	require.Len(t, f.txRepo.rows, 2)
+	correction := f.txRepo.rows[1]
+	assert.Equal(t, f.requester.ID, *correction.CreatedBy)
+	assert.Contains(t, correction.Description, "confirmed by "+f.confirmer.ID.String())
+}
@@ -0,0 +1,22 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"banca-en-linea/backend/internal/validation"
+)
+
+func TestValidateMinimumAge(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	// Cumple 18 exactamente hoy
+	assert.NoError(t, validation.ValidateMinimumAge(time.Date(2006, time.March, 1, 0, 0, 0, 0, time.UTC), now))
+	assert.NoError(t, validation.ValidateMinimumAge(time.Date(1980, time.July, 15, 0, 0, 0, 0, time.UTC), now))
+
+	// Cumple 18 mañana
+	assert.ErrorIs(t, validation.ValidateMinimumAge(time.Date(2006, time.March, 2, 0, 0, 0, 0, time.UTC), now), validation.ErrUnderage)
+	assert.ErrorIs(t, validation.ValidateMinimumAge(time.Date(2015, time.January, 1, 0, 0, 0, 0, time.UTC), now), validation.ErrUnderage)
+}
@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"banca-en-linea/backend/internal/banking"
+)
+
+func TestAmountScaler_FromFloat(t *testing.T) {
+	cases := []struct {
+		input    float64
+		expected uint64
+	}{
+		{0, 0},
+		{0.01, 1},
+		{0.1, 10},
+		{1.15, 115},
+		{19.99, 1999},
+		{1234567.89, 123456789},
+	}
+
+	for _, c := range cases {
+		result, err := banking.HNL.FromFloat(c.input)
+		assert.NoError(t, err, "input %v", c.input)
+		assert.Equal(t, c.expected, result, "input %v", c.input)
+	}
+}
+
+func TestAmountScaler_FromFloat_Invalid(t *testing.T) {
+	invalid := []float64{0.001, 10.005, -1, math.NaN(), math.Inf(1), 1e300}
+
+	for _, input := range invalid {
+		_, err := banking.HNL.FromFloat(input)
+		assert.Error(t, err, "input %v", input)
+	}
+}
+
+func TestAmountScaler_ToFloat(t *testing.T) {
+	assert.Equal(t, 12.34, banking.HNL.ToFloat(1234))
+	assert.Equal(t, 0.01, banking.HNL.ToFloat(1))
+	assert.Equal(t, 0.0, banking.HNL.ToFloat(0))
+}
+
+func TestAmountScaler_MustFromFloat_Panics(t *testing.T) {
+	assert.Equal(t, uint64(500), banking.HNL.MustFromFloat(5))
+	assert.Panics(t, func() { banking.HNL.MustFromFloat(0.001) })
+}
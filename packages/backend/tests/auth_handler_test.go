@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/handlers"
+	"banca-en-linea/backend/internal/problem"
+	"banca-en-linea/backend/internal/validation"
+)
+
+// validationProblem es el cuerpo de un problema de validación
+type validationProblem struct {
+	Type   string                    `json:"type"`
+	Title  string                    `json:"title"`
+	Status int                       `json:"status"`
+	Fields []handlers.FieldViolation `json:"fields"`
+}
+
+func TestAuthHandler_Register_ReportsFieldViolations(t *testing.T) {
+	handler := handlers.NewAuthHandler(nil, nil, nil)
+
+	body := `{"email":"not-an-email","password":"short","first_name":"` + strings.Repeat("a", 101) + `","last_name":"Pérez"}`
+	rec := httptest.NewRecorder()
+	handler.Register(rec, httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", strings.NewReader(body)))
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, problem.ContentType, rec.Header().Get("Content-Type"))
+
+	var response validationProblem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	assert.Equal(t, "about:blank", response.Type)
+	assert.Equal(t, "Validation failed", response.Title)
+	assert.Equal(t, http.StatusBadRequest, response.Status)
+	assert.ElementsMatch(t, []handlers.FieldViolation{
+		{Field: "email", Violation: "email"},
+		{Field: "password", Violation: "min=8"},
+		{Field: "first_name", Violation: "max=100"},
+	}, response.Fields)
+}
+
+func TestAuthHandler_Login_RequiresPassword(t *testing.T) {
+	handler := handlers.NewAuthHandler(nil, nil, nil)
+
+	rec := httptest.NewRecorder()
+	handler.Login(rec, httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(`{"email":"ana@example.com"}`)))
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var response validationProblem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	assert.Equal(t, []handlers.FieldViolation{{Field: "password", Violation: "required"}}, response.Fields)
+}
+
+func TestAuthHandler_Register_RejectsInvalidPhone(t *testing.T) {
+	handler := handlers.NewAuthHandler(nil, nil, nil)
+
+	body := `{"email":"ana@example.com","password":"Segura#2024","first_name":"Ana","last_name":"Pérez","phone":"9876-5432"}`
+	rec := httptest.NewRecorder()
+	handler.Register(rec, httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", strings.NewReader(body)))
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var response validationProblem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	assert.Equal(t, []handlers.FieldViolation{{Field: "phone", Violation: "phone"}}, response.Fields)
+}
+
+func TestAuthHandler_Register_RejectsUnderage(t *testing.T) {
+	handler := handlers.NewAuthHandler(nil, nil, nil)
+
+	dateOfBirth := time.Now().AddDate(-17, 0, 0).UTC().Format(time.RFC3339)
+	body := `{"email":"ana@example.com","password":"Segura#2024","first_name":"Ana","last_name":"Pérez","date_of_birth":"` + dateOfBirth + `"}`
+	rec := httptest.NewRecorder()
+	handler.Register(rec, httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", strings.NewReader(body)))
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var response map[string]any
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+	assert.Equal(t, "Invalid date of birth", response["title"])
+	assert.Equal(t, validation.ErrUnderage.Error(), response["detail"])
+}
@@ -0,0 +1,296 @@
+package tests
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/auth"
+	"banca-en-linea/backend/models"
+)
+
+// memoryRefreshTokenStore es un almacén de refresh tokens en memoria para testing
+type memoryRefreshTokenStore struct {
+	mu       sync.Mutex
+	tokens   map[string]*models.RefreshToken
+	email    string
+	roles    []string
+	sessions []models.SessionInfo
+}
+
+func newMemoryRefreshTokenStore(email string, roles []string) *memoryRefreshTokenStore {
+	return &memoryRefreshTokenStore{tokens: make(map[string]*models.RefreshToken), email: email, roles: roles}
+}
+
+func (s *memoryRefreshTokenStore) Create(ctx context.Context, tokenHash string, userID uuid.UUID, expiresAt time.Time, session models.SessionInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[tokenHash] = &models.RefreshToken{ID: uuid.New(), TokenHash: tokenHash, UserID: userID, UserEmail: s.email, UserRoles: s.roles, ExpiresAt: expiresAt, CreatedAt: time.Now()}
+	s.sessions = append(s.sessions, session)
+	return nil
+}
+
+func (s *memoryRefreshTokenStore) GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token, ok := s.tokens[tokenHash]
+	if !ok {
+		return nil, fmt.Errorf("refresh token not found")
+	}
+	copied := *token
+	return &copied, nil
+}
+
+func (s *memoryRefreshTokenStore) Revoke(ctx context.Context, tokenHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if token, ok := s.tokens[tokenHash]; ok {
+		now := time.Now()
+		token.RevokedAt = &now
+	}
+	return nil
+}
+
+func (s *memoryRefreshTokenStore) ListActiveByUser(ctx context.Context, userID uuid.UUID) ([]models.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sessions := []models.Session{}
+	for _, token := range s.tokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			sessions = append(sessions, models.Session{ID: token.ID, CreatedAt: token.CreatedAt, ExpiresAt: token.ExpiresAt})
+		}
+	}
+	return sessions, nil
+}
+
+func (s *memoryRefreshTokenStore) RevokeByID(ctx context.Context, id, userID uuid.UUID) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, token := range s.tokens {
+		if token.ID == id && token.UserID == userID && token.RevokedAt == nil {
+			now := time.Now()
+			token.RevokedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *memoryRefreshTokenStore) RevokeAllByUser(ctx context.Context, userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for _, token := range s.tokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func TestAuthService_RefreshAccessToken(t *testing.T) {
+	user := &models.User{ID: uuid.New(), Email: "test@example.com", Roles: []string{models.RoleAdmin}}
+	store := newMemoryRefreshTokenStore(user.Email, user.Roles)
+	service := auth.NewService(auth.WithRefreshTokenStore(store))
+
+	refreshToken, err := service.GenerateRefreshToken(user, models.SessionInfo{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, refreshToken)
+
+	// El token en claro nunca se almacena
+	_, stored := store.tokens[refreshToken]
+	assert.False(t, stored)
+
+	accessToken, err := service.RefreshAccessToken(refreshToken)
+	require.NoError(t, err)
+
+	claims, err := service.ValidateToken(accessToken)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, claims.UserID)
+	assert.Equal(t, user.Email, claims.Email)
+	assert.Equal(t, []string{models.RoleAdmin}, claims.Roles)
+
+	// Después del logout el refresh token deja de ser válido
+	require.NoError(t, service.RevokeRefreshToken(refreshToken))
+	_, err = service.RefreshAccessToken(refreshToken)
+	assert.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+}
+
+func TestAuthService_RefreshAccessToken_UnknownToken(t *testing.T) {
+	service := auth.NewService(auth.WithRefreshTokenStore(newMemoryRefreshTokenStore("test@example.com", nil)))
+
+	_, err := service.RefreshAccessToken("unknown")
+	assert.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+}
+
+func TestAuthService_Sessions(t *testing.T) {
+	user := &models.User{ID: uuid.New(), Email: "test@example.com"}
+	store := newMemoryRefreshTokenStore(user.Email, nil)
+	service := auth.NewService(auth.WithRefreshTokenStore(store))
+
+	session := models.SessionInfo{DeviceInfo: "Mozilla/5.0", IPAddress: "203.0.113.7"}
+	refreshToken, err := service.GenerateRefreshToken(user, session)
+	require.NoError(t, err)
+	assert.Equal(t, []models.SessionInfo{session}, store.sessions)
+
+	sessions, err := service.ListSessions(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+
+	// Otro usuario no puede revocar la sesión
+	err = service.RevokeSession(context.Background(), uuid.New(), sessions[0].ID)
+	assert.ErrorIs(t, err, auth.ErrSessionNotFound)
+
+	require.NoError(t, service.RevokeSession(context.Background(), user.ID, sessions[0].ID))
+	_, err = service.RefreshAccessToken(refreshToken)
+	assert.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+
+	sessions, err = service.ListSessions(context.Background(), user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+}
+
+// memoryEmailVerificationStore es un almacén de tokens de verificación en memoria para testing
+type memoryEmailVerificationStore struct {
+	mu     sync.Mutex
+	tokens map[string]uuid.UUID
+}
+
+func (s *memoryEmailVerificationStore) Create(ctx context.Context, tokenHash string, userID uuid.UUID, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[tokenHash] = userID
+	return nil
+}
+
+func (s *memoryEmailVerificationStore) Consume(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	userID, ok := s.tokens[tokenHash]
+	if !ok {
+		return uuid.Nil, fmt.Errorf("email verification token not found")
+	}
+	delete(s.tokens, tokenHash)
+	return userID, nil
+}
+
+func TestAuthService_VerifyEmailToken(t *testing.T) {
+	store := &memoryEmailVerificationStore{tokens: make(map[string]uuid.UUID)}
+	service := auth.NewService(auth.WithEmailVerificationStore(store))
+	user := &models.User{ID: uuid.New(), Email: "test@example.com"}
+
+	token, err := service.GenerateEmailVerificationToken(user)
+	require.NoError(t, err)
+
+	// Un token con la firma alterada es rechazado
+	_, err = service.VerifyEmailToken(token + "x")
+	assert.ErrorIs(t, err, auth.ErrInvalidVerificationToken)
+
+	userID, err := service.VerifyEmailToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, userID)
+
+	// El token solo puede usarse una vez
+	_, err = service.VerifyEmailToken(token)
+	assert.ErrorIs(t, err, auth.ErrInvalidVerificationToken)
+}
+
+func TestAuthService_TOTP(t *testing.T) {
+	service := auth.NewService()
+	user := &models.User{ID: uuid.New(), Email: "test@example.com"}
+
+	encryptedSecret, uri, err := service.GenerateTOTPSecret(user)
+	require.NoError(t, err)
+
+	key, err := otp.NewKeyFromURL(uri)
+	require.NoError(t, err)
+	// El secreto almacenado está cifrado
+	assert.NotEqual(t, key.Secret(), encryptedSecret)
+
+	user.TOTPSecret = &encryptedSecret
+	code, err := totp.GenerateCode(key.Secret(), time.Now())
+	require.NoError(t, err)
+
+	assert.NoError(t, service.VerifyTOTP(user, code))
+	assert.ErrorIs(t, service.VerifyTOTP(user, "000000x"), auth.ErrInvalidTOTPCode)
+}
+
+func TestAuthService_MFAChallengeTokenIsNotAnAccessToken(t *testing.T) {
+	service := auth.NewService()
+	user := &models.User{ID: uuid.New(), Email: "test@example.com"}
+
+	challenge, err := service.GenerateMFAChallengeToken(user)
+	require.NoError(t, err)
+
+	_, err = service.ValidateToken(challenge)
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+
+	claims, err := service.ValidateMFAChallengeToken(challenge)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, claims.UserID)
+
+	// Un access token no sirve como desafío MFA
+	accessToken, err := service.GenerateToken(user)
+	require.NoError(t, err)
+	_, err = service.ValidateMFAChallengeToken(accessToken)
+	assert.ErrorIs(t, err, auth.ErrInvalidMFAChallenge)
+}
+
+func newTestRSAKeyProvider(t *testing.T) (*auth.RSAKeyProvider, *rsa.PrivateKey) {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	publicDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	provider, err := auth.NewRSAKeyProvider(privatePEM, publicPEM)
+	require.NoError(t, err)
+	return provider, privateKey
+}
+
+func TestAuthService_RS256TokensAndJWKS(t *testing.T) {
+	provider, privateKey := newTestRSAKeyProvider(t)
+	service := auth.NewService(auth.WithKeyProvider(provider))
+	user := &models.User{ID: uuid.New(), Email: "test@example.com"}
+
+	token, err := service.GenerateToken(user)
+	require.NoError(t, err)
+
+	claims, err := service.ValidateToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, claims.UserID)
+
+	// Un token HS256 no es aceptado cuando el servicio usa RS256
+	hmacToken, err := auth.NewService().GenerateToken(user)
+	require.NoError(t, err)
+	_, err = service.ValidateToken(hmacToken)
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+
+	jwks, err := service.JWKS()
+	require.NoError(t, err)
+	require.Len(t, jwks.Keys, 1)
+
+	key := jwks.Keys[0]
+	assert.Equal(t, "RSA", key.KeyType)
+	assert.Equal(t, "RS256", key.Algorithm)
+	n, err := base64.RawURLEncoding.DecodeString(key.Modulus)
+	require.NoError(t, err)
+	assert.Equal(t, privateKey.N.Bytes(), n)
+}
@@ -0,0 +1,159 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/cache"
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/mocks"
+	"banca-en-linea/backend/models"
+)
+
+func newTestBalanceCache(t *testing.T) (*cache.RedisBalanceCache, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	balanceCache := cache.NewRedisBalanceCache(redis.NewClient(&redis.Options{Addr: server.Addr()}))
+	t.Cleanup(func() { balanceCache.Close() })
+	return balanceCache, server
+}
+
+func TestRedisBalanceCache_SetGetInvalidate(t *testing.T) {
+	balanceCache, _ := newTestBalanceCache(t)
+
+	_, ok := balanceCache.Get(42)
+	assert.False(t, ok)
+
+	balanceCache.Set(42, 15000, time.Minute)
+	balance, ok := balanceCache.Get(42)
+	require.True(t, ok)
+	assert.Equal(t, int64(15000), balance)
+
+	balanceCache.Invalidate(42)
+	_, ok = balanceCache.Get(42)
+	assert.False(t, ok)
+}
+
+func TestRedisBalanceCache_Expires(t *testing.T) {
+	balanceCache, server := newTestBalanceCache(t)
+
+	balanceCache.Set(42, 15000, cache.DefaultBalanceTTL)
+	server.FastForward(cache.DefaultBalanceTTL + time.Second)
+
+	_, ok := balanceCache.Get(42)
+	assert.False(t, ok)
+}
+
+func TestRedisBalanceCache_UnavailableIsMiss(t *testing.T) {
+	balanceCache, server := newTestBalanceCache(t)
+
+	balanceCache.Set(42, 15000, time.Minute)
+	server.Close()
+
+	_, ok := balanceCache.Get(42)
+	assert.False(t, ok)
+}
+
+func TestNewRedisBalanceCacheFromEnv_NotConfigured(t *testing.T) {
+	t.Setenv("REDIS_URL", "")
+
+	_, err := cache.NewRedisBalanceCacheFromEnv()
+	assert.ErrorIs(t, err, cache.ErrNoRedisConfigured)
+}
+
+func TestUserService_GetUserWithBalance_UsesCache(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	balanceCache, _ := newTestBalanceCache(t)
+
+	service := db.NewUserService(mockRepo, mockTB, db.WithBalanceCache(balanceCache))
+
+	userID := uuid.New()
+	accountID := int64(12345)
+	user := &models.User{ID: userID, TigerBeetleAccountID: &accountID}
+
+	mockRepo.On("GetByID", mock.Anything, userID).Return(user, nil)
+	mockTB.On("GetAccountBalance", uint64(accountID)).Return(uint64(1000), uint64(5000), nil).Once()
+	mockTB.On("Deposit", uint64(accountID), uint64(500), mock.AnythingOfType("uint64")).Return(nil)
+
+	// La segunda consulta se responde desde la caché
+	for i := 0; i < 2; i++ {
+		_, balance, err := service.GetUserWithBalance(userID)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(4000), balance)
+	}
+	mockTB.AssertNumberOfCalls(t, "GetAccountBalance", 1)
+
+	// El depósito invalida el balance en caché
+	require.NoError(t, service.DepositToUser(context.Background(), userID, 500))
+	_, ok := balanceCache.Get(uint64(accountID))
+	assert.False(t, ok)
+
+	mockTB.On("GetAccountBalance", uint64(accountID)).Return(uint64(1000), uint64(5500), nil).Once()
+	_, balance, err := service.GetUserWithBalance(userID)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4500), balance)
+	mockTB.AssertNumberOfCalls(t, "GetAccountBalance", 2)
+}
+
+// countingSpendingRepository cuenta las consultas del resumen de gastos
+type countingSpendingRepository struct {
+	db.TransactionRepository
+	calls int
+}
+
+func (r *countingSpendingRepository) GetSpendingSummary(ctx context.Context, userID uuid.UUID, from, to time.Time) (*models.SpendingSummary, error) {
+	r.calls++
+	return &models.SpendingSummary{
+		From:             from,
+		To:               to,
+		TotalDebits:      12000,
+		TotalCredits:     50000,
+		TransactionCount: 4,
+		ByCategoryBreakdown: map[string]models.CategorySummary{
+			"groceries":                  {Total: 8000, Count: 2},
+			models.UncategorizedCategory: {Total: 4000, Count: 1},
+		},
+	}, nil
+}
+
+func TestUserService_GetSpendingSummary_UsesCache(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	txRepo := &countingSpendingRepository{}
+	service := db.NewUserService(new(mocks.MockUserRepository), nil,
+		db.WithTransactionRepository(txRepo),
+		db.WithSpendingSummaryCache(cache.NewRedisSpendingSummaryCache(client)))
+
+	userID := uuid.New()
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC)
+
+	first, err := service.GetSpendingSummary(context.Background(), userID, from, to)
+	require.NoError(t, err)
+	second, err := service.GetSpendingSummary(context.Background(), userID, from, to)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, txRepo.calls)
+	assert.Equal(t, first.TotalDebits, second.TotalDebits)
+	assert.Equal(t, first.ByCategoryBreakdown, second.ByCategoryBreakdown)
+
+	// Otro rango no usa el resumen en caché, y el resumen vence a los cinco minutos
+	_, err = service.GetSpendingSummary(context.Background(), userID, from, to.AddDate(0, 0, -1))
+	require.NoError(t, err)
+	assert.Equal(t, 2, txRepo.calls)
+
+	server.FastForward(cache.DefaultSpendingSummaryTTL)
+	_, err = service.GetSpendingSummary(context.Background(), userID, from, to)
+	require.NoError(t, err)
+	assert.Equal(t, 3, txRepo.calls)
+}
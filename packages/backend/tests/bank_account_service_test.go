@@ -0,0 +1,422 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/tigerbeetle"
+	"banca-en-linea/backend/models"
+)
+
+// memoryBankAccountRepository es un repositorio de cuentas bancarias en memoria para testing
+type memoryBankAccountRepository struct {
+	db.BankAccountRepository
+	accounts  map[uuid.UUID]*models.BankAccount
+	sequences map[string]int
+}
+
+func newMemoryBankAccountRepository() *memoryBankAccountRepository {
+	return &memoryBankAccountRepository{
+		accounts:  map[uuid.UUID]*models.BankAccount{},
+		sequences: map[string]int{},
+	}
+}
+
+func (r *memoryBankAccountRepository) NextAccountSequence(ctx context.Context, accountType string) (int, error) {
+	r.sequences[accountType]++
+	return r.sequences[accountType], nil
+}
+
+func (r *memoryBankAccountRepository) Create(ctx context.Context, account *models.BankAccount) (*models.BankAccount, error) {
+	created := *account
+	created.IsActive = true
+	r.accounts[created.ID] = &created
+	return &created, nil
+}
+
+func (r *memoryBankAccountRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.BankAccount, error) {
+	account, ok := r.accounts[id]
+	if !ok {
+		return nil, db.ErrBankAccountNotFound
+	}
+	return account, nil
+}
+
+func (r *memoryBankAccountRepository) GetByAccountNumber(ctx context.Context, accountNumber string) (*models.BankAccount, error) {
+	for _, account := range r.accounts {
+		if account.AccountNumber == accountNumber {
+			return account, nil
+		}
+	}
+	return nil, db.ErrBankAccountNotFound
+}
+
+func (r *memoryBankAccountRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.BankAccount, error) {
+	var accounts []*models.BankAccount
+	for _, account := range r.accounts {
+		if account.UserID == userID {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts, nil
+}
+
+func (r *memoryBankAccountRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	account, ok := r.accounts[id]
+	if !ok || !account.IsActive {
+		return db.ErrBankAccountNotFound
+	}
+	account.IsActive = false
+	return nil
+}
+
+func TestBankAccountService_CreateAccount(t *testing.T) {
+	repo := newMemoryBankAccountRepository()
+	tb := new(MockTigerBeetleService)
+	tb.On("CreateUserAccount", mock.Anything).Return(nil, nil)
+	service := db.NewBankAccountService(repo, nil, tb, nil)
+
+	userID := uuid.New()
+	account, err := service.CreateAccount(context.Background(), userID, &models.CreateBankAccountRequest{
+		AccountType: models.AccountTypeSavings,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, userID, account.UserID)
+	assert.Equal(t, models.DefaultCurrency, account.Currency)
+	assert.NoError(t, db.ValidateAccountNumber(account.AccountNumber))
+	assert.Equal(t, byte('2'), account.AccountNumber[0])
+	tb.AssertCalled(t, "CreateUserAccount", uint64(account.TigerBeetleAccountID))
+}
+
+func TestBankAccountService_DeleteAccount_RequiresZeroBalance(t *testing.T) {
+	repo := newMemoryBankAccountRepository()
+	tb := new(MockTigerBeetleService)
+	tb.On("CreateUserAccount", mock.Anything).Return(nil, nil)
+	service := db.NewBankAccountService(repo, nil, tb, nil)
+
+	account, err := service.CreateAccount(context.Background(), uuid.New(), &models.CreateBankAccountRequest{
+		AccountType: models.AccountTypeChecking,
+	})
+	require.NoError(t, err)
+
+	tbAccountID := uint64(account.TigerBeetleAccountID)
+	tb.On("GetAccountBalance", tbAccountID).Return(uint64(0), uint64(500), nil).Once()
+	err = service.DeleteAccount(context.Background(), account.ID)
+	assert.ErrorIs(t, err, db.ErrAccountHasBalance)
+
+	tb.On("GetAccountBalance", tbAccountID).Return(uint64(500), uint64(500), nil).Once()
+	err = service.DeleteAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	assert.False(t, repo.accounts[account.ID].IsActive)
+}
+
+func TestBankAccountService_GetUserAccountsWithBalances_SingleLookup(t *testing.T) {
+	repo := newMemoryBankAccountRepository()
+	tb := new(MockTigerBeetleService)
+	tb.On("CreateUserAccount", mock.Anything).Return(nil, nil)
+	service := db.NewBankAccountService(repo, nil, tb, nil)
+
+	userID := uuid.New()
+	checking, err := service.CreateAccount(context.Background(), userID, &models.CreateBankAccountRequest{AccountType: models.AccountTypeChecking})
+	require.NoError(t, err)
+	savings, err := service.CreateAccount(context.Background(), userID, &models.CreateBankAccountRequest{AccountType: models.AccountTypeSavings})
+	require.NoError(t, err)
+
+	tb.On("GetMultipleAccountBalances", mock.MatchedBy(func(ids []uint64) bool {
+		return assert.ElementsMatch(t, []uint64{uint64(checking.TigerBeetleAccountID), uint64(savings.TigerBeetleAccountID)}, ids)
+	})).Return(map[uint64]int64{
+		uint64(checking.TigerBeetleAccountID): 1500,
+		uint64(savings.TigerBeetleAccountID):  -200,
+	}, nil).Once()
+
+	accounts, err := service.GetUserAccountsWithBalances(context.Background(), userID)
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+
+	balances := map[uuid.UUID]int64{}
+	for _, account := range accounts {
+		balances[account.ID] = account.Balance
+	}
+	assert.Equal(t, int64(1500), balances[checking.ID])
+	assert.Equal(t, int64(-200), balances[savings.ID])
+	tb.AssertNumberOfCalls(t, "GetMultipleAccountBalances", 1)
+	tb.AssertNotCalled(t, "GetAccountBalance", mock.Anything)
+}
+
+// ledgerTransactionRepository guarda las transacciones en memoria y calcula los balances a partir de ellas
+type ledgerTransactionRepository struct {
+	db.TransactionRepository
+	rows []*models.Transaction
+}
+
+func (r *ledgerTransactionRepository) Create(ctx context.Context, tx *models.Transaction) (*models.Transaction, error) {
+	created := *tx
+	created.ID = uuid.New()
+	r.rows = append(r.rows, &created)
+	return &created, nil
+}
+
+func (r *ledgerTransactionRepository) GetExpectedBalance(ctx context.Context, accountID uuid.UUID) (int64, error) {
+	var balance int64
+	for _, tx := range r.rows {
+		if tx.TransactionType == models.TransactionTypeBalanceCorrection {
+			continue
+		}
+		if tx.ToAccountID != nil && *tx.ToAccountID == accountID {
+			balance += tx.Amount
+		}
+		if tx.FromAccountID != nil && *tx.FromAccountID == accountID {
+			balance -= tx.Amount
+		}
+	}
+	return balance, nil
+}
+
+func (r *ledgerTransactionRepository) GetRecordedMovements(ctx context.Context, accountID uuid.UUID) (uint64, uint64, error) {
+	var debits, credits uint64
+	for _, tx := range r.rows {
+		if tx.FromAccountID != nil && *tx.FromAccountID == accountID {
+			debits += uint64(tx.Amount)
+		}
+		if tx.ToAccountID != nil && *tx.ToAccountID == accountID {
+			credits += uint64(tx.Amount)
+		}
+	}
+	return debits, credits, nil
+}
+
+// GetUserExpectedBalance suma los movimientos de la cuenta principal, que se registran sin cuenta
+func (r *ledgerTransactionRepository) GetUserExpectedBalance(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var balance int64
+	for _, tx := range r.rows {
+		if tx.TransactionType == models.TransactionTypeBalanceCorrection {
+			continue
+		}
+		if tx.ToAccountID == nil && tx.RecipientUserID != nil && *tx.RecipientUserID == userID {
+			balance += tx.Amount
+		}
+		if tx.FromAccountID == nil && tx.CreatedBy != nil && *tx.CreatedBy == userID {
+			balance -= tx.Amount
+		}
+	}
+	return balance, nil
+}
+
+func (r *ledgerTransactionRepository) GetDailyTotal(ctx context.Context, userID uuid.UUID, txType string, date time.Time) (uint64, error) {
+	var total uint64
+	for _, tx := range r.rows {
+		if tx.CreatedBy != nil && *tx.CreatedBy == userID && tx.TransactionType == txType {
+			total += uint64(tx.Amount)
+		}
+	}
+	return total, nil
+}
+
+// GetVelocity cuenta los movimientos del tipo indicado que salieron o entraron a la cuenta principal
+func (r *ledgerTransactionRepository) GetVelocity(ctx context.Context, userID uuid.UUID, txType string, since time.Time) (int, uint64, error) {
+	var count int
+	var total uint64
+	for _, tx := range r.rows {
+		outgoing := tx.FromAccountID == nil && tx.CreatedBy != nil && *tx.CreatedBy == userID
+		incoming := tx.ToAccountID == nil && tx.RecipientUserID != nil && *tx.RecipientUserID == userID
+		if tx.TransactionType == txType && (outgoing || incoming) {
+			count++
+			total += uint64(tx.Amount)
+		}
+	}
+	return count, total, nil
+}
+
+func (r *ledgerTransactionRepository) GetOutgoingTotalSince(ctx context.Context, userID uuid.UUID, since time.Time) (uint64, error) {
+	var total uint64
+	for _, tx := range r.rows {
+		spending := tx.TransactionType == models.TransactionTypeTransfer || tx.TransactionType == models.TransactionTypeWithdrawal
+		if spending && tx.CreatedBy != nil && *tx.CreatedBy == userID {
+			total += uint64(tx.Amount)
+		}
+	}
+	return total, nil
+}
+
+// newRecalculationFixture crea una cuenta bancaria respaldada por el stub de TigerBeetle
+func newRecalculationFixture(t *testing.T) (*db.BankAccountService, *ledgerTransactionRepository, *tigerbeetle.Service, *models.BankAccount) {
+	stub := tigerbeetle.NewServiceStub()
+	require.NoError(t, stub.InitializeMasterAccounts())
+
+	repo := newMemoryBankAccountRepository()
+	account, err := repo.Create(context.Background(), &models.BankAccount{ID: uuid.New(), UserID: uuid.New(), TigerBeetleAccountID: 7001})
+	require.NoError(t, err)
+	_, err = stub.CreateUserAccount(7001)
+	require.NoError(t, err)
+
+	txRepo := &ledgerTransactionRepository{}
+	return db.NewBankAccountService(repo, txRepo, stub, db.NewMemoryTransferIDGenerator()), txRepo, stub, account
+}
+
+func TestBankAccountService_RecalculateBalance_RefusesUnrecordedMovements(t *testing.T) {
+	service, txRepo, stub, account := newRecalculationFixture(t)
+
+	// Un depósito aplicado en TigerBeetle que nunca se registró en transactions
+	require.NoError(t, stub.Deposit(7001, 10000, 90001))
+
+	_, err := service.RecalculateBalance(context.Background(), account.ID, uuid.New(), "drift")
+	assert.ErrorIs(t, err, db.ErrUnrecordedMovements)
+
+	// El dinero del cliente no se movió a la cuenta de correcciones
+	debits, credits, err := stub.GetAccountBalance(7001)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10000), int64(credits)-int64(debits))
+	assert.Empty(t, txRepo.rows)
+}
+
+func TestBankAccountService_RecalculateBalance_CorrectsRecordedMovement(t *testing.T) {
+	service, txRepo, stub, account := newRecalculationFixture(t)
+
+	// Un depósito registrado que TigerBeetle no llegó a aplicar
+	_, err := txRepo.Create(context.Background(), &models.Transaction{
+		ToAccountID:     &account.ID,
+		Amount:          10000,
+		TransactionType: models.TransactionTypeDeposit,
+		Status:          models.TransactionStatusCompleted,
+	})
+	require.NoError(t, err)
+
+	balance, err := service.RecalculateBalance(context.Background(), account.ID, uuid.New(), "missing deposit")
+	require.NoError(t, err)
+	assert.Equal(t, int64(10000), balance)
+
+	debits, credits, err := stub.GetAccountBalance(7001)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10000), int64(credits)-int64(debits))
+	require.Len(t, txRepo.rows, 2)
+	correction := txRepo.rows[1]
+	assert.Equal(t, models.TransactionTypeBalanceCorrection, correction.TransactionType)
+	assert.Equal(t, account.ID, *correction.ToAccountID)
+	assert.Nil(t, correction.FromAccountID)
+	assert.Equal(t, int64(10000), correction.Amount)
+
+	// El ajuste sale de la cuenta de correcciones
+	debits, credits, err = stub.GetAccountBalance(uint64(tigerbeetle.CorrectionAccount))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10000), debits)
+	assert.Equal(t, uint64(0), credits)
+}
+
+func TestBankAccountService_RecalculateBalance_CorrectsRecordedWithdrawal(t *testing.T) {
+	service, txRepo, stub, account := newRecalculationFixture(t)
+
+	// Un depósito registrado y aplicado, y un retiro registrado que TigerBeetle no llegó a aplicar
+	require.NoError(t, stub.Deposit(7001, 10000, 90001))
+	for _, tx := range []*models.Transaction{
+		{ToAccountID: &account.ID, Amount: 10000, TransactionType: models.TransactionTypeDeposit},
+		{FromAccountID: &account.ID, Amount: 4000, TransactionType: models.TransactionTypeWithdrawal},
+	} {
+		tx.Status = models.TransactionStatusCompleted
+		_, err := txRepo.Create(context.Background(), tx)
+		require.NoError(t, err)
+	}
+
+	balance, err := service.RecalculateBalance(context.Background(), account.ID, uuid.New(), "missing withdrawal")
+	require.NoError(t, err)
+	assert.Equal(t, int64(6000), balance)
+
+	debits, credits, err := stub.GetAccountBalance(7001)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6000), int64(credits)-int64(debits))
+
+	require.Len(t, txRepo.rows, 3)
+	correction := txRepo.rows[2]
+	assert.Equal(t, account.ID, *correction.FromAccountID)
+	assert.Nil(t, correction.ToAccountID)
+	assert.Equal(t, int64(4000), correction.Amount)
+
+	// El ajuste entra a la cuenta de correcciones
+	debits, credits, err = stub.GetAccountBalance(uint64(tigerbeetle.CorrectionAccount))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), debits)
+	assert.Equal(t, uint64(4000), credits)
+}
+
+// failingCreateTransactionRepository no logra registrar transacciones nuevas
+type failingCreateTransactionRepository struct {
+	*ledgerTransactionRepository
+}
+
+func (r *failingCreateTransactionRepository) Create(ctx context.Context, tx *models.Transaction) (*models.Transaction, error) {
+	return nil, assert.AnError
+}
+
+func TestBankAccountService_RecalculateBalance_ReportsUnrecordedCorrection(t *testing.T) {
+	_, txRepo, stub, account := newRecalculationFixture(t)
+	_, err := txRepo.Create(context.Background(), &models.Transaction{
+		ToAccountID:     &account.ID,
+		Amount:          10000,
+		TransactionType: models.TransactionTypeDeposit,
+		Status:          models.TransactionStatusCompleted,
+	})
+	require.NoError(t, err)
+
+	repo := newMemoryBankAccountRepository()
+	repo.accounts[account.ID] = account
+	service := db.NewBankAccountService(repo, &failingCreateTransactionRepository{txRepo}, stub, db.NewMemoryTransferIDGenerator())
+
+	_, err = service.RecalculateBalance(context.Background(), account.ID, uuid.New(), "missing deposit")
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Contains(t, err.Error(), "error recording balance correction")
+
+	// La corrección ya quedó aplicada en TigerBeetle aunque no se registró
+	debits, credits, err := stub.GetAccountBalance(7001)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10000), int64(credits)-int64(debits))
+	assert.Len(t, txRepo.rows, 1)
+}
+
+func TestBankAccountService_UpdateOverdraft_RejectsEnabling(t *testing.T) {
+	stub := tigerbeetle.NewServiceStub()
+	require.NoError(t, stub.InitializeMasterAccounts())
+	service := db.NewBankAccountService(newMemoryBankAccountRepository(), nil, stub, db.NewMemoryTransferIDGenerator())
+
+	account, err := service.CreateAccount(context.Background(), uuid.New(), &models.CreateBankAccountRequest{AccountType: models.AccountTypeChecking})
+	require.NoError(t, err)
+
+	_, err = service.UpdateOverdraft(context.Background(), account.ID, &models.UpdateOverdraftRequest{OverdraftEnabled: true, OverdraftLimitCents: 20000})
+	assert.ErrorIs(t, err, db.ErrOverdraftNotSupported)
+
+	// TigerBeetle rechaza cualquier débito por encima del balance de la cuenta
+	err = stub.Withdraw(uint64(account.TigerBeetleAccountID), 1, 1)
+	assert.ErrorIs(t, err, tigerbeetle.ErrExceedsCredits)
+}
+
+func TestGenerateAccountNumber(t *testing.T) {
+	userID := uuid.New()
+
+	first := db.GenerateAccountNumber(userID, models.AccountTypeChecking, 1)
+	second := db.GenerateAccountNumber(userID, models.AccountTypeChecking, 2)
+
+	assert.Len(t, first, db.AccountNumberLength)
+	assert.Equal(t, first, db.GenerateAccountNumber(userID, models.AccountTypeChecking, 1))
+	assert.NotEqual(t, first, second)
+	assert.NoError(t, db.ValidateAccountNumber(first))
+	assert.NoError(t, db.ValidateAccountNumber(second))
+}
+
+func TestValidateAccountNumber(t *testing.T) {
+	valid := db.GenerateAccountNumber(uuid.New(), models.AccountTypeSavings, 42)
+	require.NoError(t, db.ValidateAccountNumber(valid))
+
+	// Alterar un dígito invalida el dígito verificador
+	altered := []byte(valid)
+	altered[5] = '0' + (altered[5]-'0'+1)%10
+	assert.ErrorIs(t, db.ValidateAccountNumber(string(altered)), db.ErrInvalidAccountNumber)
+
+	assert.ErrorIs(t, db.ValidateAccountNumber("1000000001"), db.ErrInvalidAccountNumber)
+	assert.ErrorIs(t, db.ValidateAccountNumber("10000000000a"), db.ErrInvalidAccountNumber)
+}
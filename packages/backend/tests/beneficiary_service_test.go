@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/mocks"
+	"banca-en-linea/backend/models"
+)
+
+// memoryBeneficiaryRepository guarda los beneficiarios en memoria
+type memoryBeneficiaryRepository struct {
+	db.BeneficiaryRepository
+	beneficiaries []*models.Beneficiary
+}
+
+func (r *memoryBeneficiaryRepository) Create(ctx context.Context, beneficiary *models.Beneficiary) (*models.Beneficiary, error) {
+	for _, existing := range r.beneficiaries {
+		if existing.OwnerUserID == beneficiary.OwnerUserID && existing.RecipientUserID == beneficiary.RecipientUserID {
+			return nil, db.ErrBeneficiaryExists
+		}
+	}
+	created := *beneficiary
+	created.ID = uuid.New()
+	r.beneficiaries = append(r.beneficiaries, &created)
+	return &created, nil
+}
+
+func (r *memoryBeneficiaryRepository) GetByOwner(ctx context.Context, ownerID uuid.UUID) ([]*models.Beneficiary, error) {
+	var owned []*models.Beneficiary
+	for _, beneficiary := range r.beneficiaries {
+		if beneficiary.OwnerUserID == ownerID {
+			owned = append(owned, beneficiary)
+		}
+	}
+	return owned, nil
+}
+
+func (r *memoryBeneficiaryRepository) Delete(ctx context.Context, id, ownerID uuid.UUID) error {
+	for i, beneficiary := range r.beneficiaries {
+		if beneficiary.ID == id && beneficiary.OwnerUserID == ownerID {
+			r.beneficiaries = append(r.beneficiaries[:i], r.beneficiaries[i+1:]...)
+			return nil
+		}
+	}
+	return db.ErrBeneficiaryNotFound
+}
+
+func TestBeneficiaryService_Add_SavesActiveRecipient(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	repo := &memoryBeneficiaryRepository{}
+	service := db.NewBeneficiaryService(repo, mockRepo)
+
+	ownerID := uuid.New()
+	recipient := &models.User{ID: uuid.New(), IsActive: true}
+	mockRepo.On("GetByID", mock.Anything, recipient.ID).Return(recipient, nil)
+
+	beneficiary, err := service.Add(context.Background(), ownerID, recipient.ID, "  Mamá ")
+	require.NoError(t, err)
+	assert.Equal(t, "Mamá", beneficiary.Alias)
+
+	_, err = service.Add(context.Background(), ownerID, recipient.ID, "")
+	assert.ErrorIs(t, err, db.ErrBeneficiaryExists)
+
+	list, err := service.List(context.Background(), ownerID)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+}
+
+func TestBeneficiaryService_Add_RejectsInvalidRecipient(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	service := db.NewBeneficiaryService(&memoryBeneficiaryRepository{}, mockRepo)
+
+	ownerID := uuid.New()
+	inactive := &models.User{ID: uuid.New(), IsActive: false}
+	missingID := uuid.New()
+	mockRepo.On("GetByID", mock.Anything, inactive.ID).Return(inactive, nil)
+	mockRepo.On("GetByID", mock.Anything, missingID).Return(nil, db.ErrUserNotFound)
+
+	_, err := service.Add(context.Background(), ownerID, inactive.ID, "")
+	assert.ErrorIs(t, err, db.ErrRecipientInactive)
+
+	_, err = service.Add(context.Background(), ownerID, missingID, "")
+	assert.ErrorIs(t, err, db.ErrRecipientNotFound)
+
+	_, err = service.Add(context.Background(), ownerID, ownerID, "")
+	assert.ErrorIs(t, err, db.ErrSelfBeneficiary)
+}
+
+func TestBeneficiaryService_Remove_OnlyOwnBeneficiaries(t *testing.T) {
+	repo := &memoryBeneficiaryRepository{}
+	service := db.NewBeneficiaryService(repo, nil)
+
+	ownerID := uuid.New()
+	beneficiary, err := repo.Create(context.Background(), &models.Beneficiary{OwnerUserID: ownerID, RecipientUserID: uuid.New()})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, service.Remove(context.Background(), uuid.New(), beneficiary.ID), db.ErrBeneficiaryNotFound)
+	assert.NoError(t, service.Remove(context.Background(), ownerID, beneficiary.ID))
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/compliance"
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/mocks"
+	"banca-en-linea/backend/models"
+)
+
+// stubTransactionTotals retorna siempre el mismo total saliente
+type stubTransactionTotals struct {
+	total uint64
+	since time.Time
+}
+
+func (s *stubTransactionTotals) GetOutgoingTotalSince(ctx context.Context, userID uuid.UUID, since time.Time) (uint64, error) {
+	s.since = since
+	return s.total, nil
+}
+
+func TestComplianceService_Check_UsesRolling24HourTotal(t *testing.T) {
+	totals := &stubTransactionTotals{total: 80000}
+	service := compliance.NewComplianceService(totals, 100000)
+
+	decision, err := service.Check(context.Background(), uuid.New(), 20000)
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+	assert.WithinDuration(t, time.Now().Add(-24*time.Hour), totals.since, time.Second)
+
+	decision, err = service.Check(context.Background(), uuid.New(), 20001)
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.NotEmpty(t, decision.Reason)
+}
+
+func TestComplianceService_Check_WithoutLimitAllowsEverything(t *testing.T) {
+	service := compliance.NewComplianceService(&stubTransactionTotals{total: 1 << 40}, 0)
+
+	decision, err := service.Check(context.Background(), uuid.New(), 1)
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+}
+
+func TestUserService_WithdrawFromUser_RecordsComplianceBlock(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	auditRepo := &memoryAuditLogRepository{}
+	checker := compliance.NewComplianceService(&stubTransactionTotals{total: 90000}, 100000)
+	service := db.NewUserService(mockRepo, mockTB, db.WithComplianceService(checker), db.WithAuditLogRepository(auditRepo))
+
+	accountID := int64(333)
+	user := &models.User{ID: uuid.New(), TigerBeetleAccountID: &accountID, DailyWithdrawalLimitCents: 1000000}
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+
+	err := service.WithdrawFromUser(context.Background(), user.ID, 20000)
+
+	assert.ErrorIs(t, err, compliance.ErrBlocked)
+	mockTB.AssertNotCalled(t, "Withdraw", mock.Anything, mock.Anything, mock.Anything)
+
+	var blocks []*models.AuditLog
+	for _, entry := range auditRepo.entries {
+		if entry.Action == models.AuditActionComplianceBlock {
+			blocks = append(blocks, entry)
+		}
+	}
+	require.Len(t, blocks, 1)
+	assert.Equal(t, models.AuditStatusFailure, blocks[0].Status)
+}
+
+func TestUserService_WithdrawFromUser_ComplianceCountsRecordedWithdrawals(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	txRepo := &ledgerTransactionRepository{}
+	checker := compliance.NewComplianceService(txRepo, 10000)
+	service := db.NewUserService(mockRepo, mockTB, db.WithTransactionRepository(txRepo), db.WithComplianceService(checker))
+
+	accountID := int64(333)
+	user := &models.User{ID: uuid.New(), TigerBeetleAccountID: &accountID, DailyWithdrawalLimitCents: 1000000}
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockTB.On("GetAccountBalance", uint64(accountID)).Return(uint64(0), uint64(50000), nil)
+	mockTB.On("Withdraw", uint64(accountID), uint64(6000), mock.AnythingOfType("uint64")).Return(nil).Once()
+
+	// Dos retiros bajo el límite que juntos lo superan
+	require.NoError(t, service.WithdrawFromUser(context.Background(), user.ID, 6000))
+	err := service.WithdrawFromUser(context.Background(), user.ID, 5000)
+
+	assert.ErrorIs(t, err, compliance.ErrBlocked)
+	mockTB.AssertNumberOfCalls(t, "Withdraw", 1)
+}
@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/config"
+)
+
+// clearConfigEnv vacía las variables que lee LoadConfig para partir de los valores por defecto
+func clearConfigEnv(t *testing.T) {
+	for _, key := range []string{
+		"POSTGRES_HOST", "DB_HOST", "POSTGRES_PORT", "DB_PORT", "POSTGRES_USER", "DB_USER",
+		"POSTGRES_PASSWORD", "DB_PASSWORD", "POSTGRES_DB", "DB_NAME", "DB_SSLMODE", "JWT_SECRET",
+		"TIGERBEETLE_ADDRESS", "PORT", "METRICS_PORT", "OTEL_EXPORTER_OTLP_ENDPOINT", "SEED_DATA",
+		"LOG_LEVEL", "REVERSAL_WINDOW",
+	} {
+		t.Setenv(key, "")
+	}
+}
+
+func TestLoadConfig_Defaults(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("DB_HOST", "db")
+	t.Setenv("SEED_DATA", "1")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "db", cfg.PostgresHost)
+	assert.Equal(t, "5432", cfg.PostgresPort)
+	assert.Equal(t, config.DefaultJWTSecret, cfg.JWTSecret)
+	assert.Equal(t, "localhost:3000", cfg.TigerBeetleAddress)
+	assert.Equal(t, "8080", cfg.Port)
+	assert.True(t, cfg.SeedData)
+	assert.Equal(t, "info", cfg.LogLevel)
+	assert.Equal(t, config.DefaultReversalWindow, cfg.ReversalWindow)
+}
+
+func TestLoadConfig_ListsAllInvalidFields(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("POSTGRES_PORT", "five")
+	t.Setenv("JWT_SECRET", "short")
+	t.Setenv("TIGERBEETLE_ADDRESS", "localhost")
+	t.Setenv("DB_SSLMODE", "sometimes")
+	t.Setenv("LOG_LEVEL", "verbose")
+	t.Setenv("REVERSAL_WINDOW", "one day")
+
+	cfg, err := config.LoadConfig()
+	require.Error(t, err)
+	assert.Nil(t, cfg)
+	assert.Contains(t, err.Error(), `POSTGRES_PORT must be numeric, got "five"`)
+	assert.Contains(t, err.Error(), "JWT_SECRET must be at least 32 characters long")
+	assert.Contains(t, err.Error(), "TIGERBEETLE_ADDRESS must be a host:port address")
+	assert.Contains(t, err.Error(), "DB_SSLMODE must be one of")
+	assert.Contains(t, err.Error(), `LOG_LEVEL must be one of [debug info warn error], got "verbose"`)
+	assert.Contains(t, err.Error(), "REVERSAL_WINDOW must be a positive duration")
+	assert.NotContains(t, err.Error(), "short")
+}
+
+func TestLoadConfig_ReversalWindow(t *testing.T) {
+	clearConfigEnv(t)
+	t.Setenv("REVERSAL_WINDOW", "30m")
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Minute, cfg.ReversalWindow)
+}
@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/models"
+)
+
+// memoryDisputeRepository guarda las disputas en memoria
+type memoryDisputeRepository struct {
+	db.DisputeRepository
+	disputes []*models.Dispute
+}
+
+func (r *memoryDisputeRepository) Create(ctx context.Context, dispute *models.Dispute) (*models.Dispute, error) {
+	for _, existing := range r.disputes {
+		if existing.TransactionID == dispute.TransactionID && existing.Status == models.DisputeStatusOpen {
+			return nil, db.ErrDisputeExists
+		}
+	}
+	created := *dispute
+	created.ID = uuid.New()
+	created.Status = models.DisputeStatusOpen
+	created.CreatedAt = time.Now()
+	r.disputes = append(r.disputes, &created)
+	return &created, nil
+}
+
+func (r *memoryDisputeRepository) Resolve(ctx context.Context, id, resolverID uuid.UUID, status, note string) (*models.Dispute, error) {
+	for _, dispute := range r.disputes {
+		if dispute.ID != id {
+			continue
+		}
+		if dispute.Status != models.DisputeStatusOpen {
+			return nil, db.ErrDisputeNotOpen
+		}
+		now := time.Now()
+		dispute.Status = status
+		dispute.ResolutionNote = note
+		dispute.ResolvedBy = &resolverID
+		dispute.ResolvedAt = &now
+		return dispute, nil
+	}
+	return nil, db.ErrDisputeNotFound
+}
+
+func (r *memoryDisputeRepository) HasApproved(ctx context.Context, transactionID, userID uuid.UUID) (bool, error) {
+	for _, dispute := range r.disputes {
+		if dispute.TransactionID == transactionID && dispute.UserID == userID && dispute.Status == models.DisputeStatusApproved {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestDisputeService_CreateAndResolve(t *testing.T) {
+	service := db.NewDisputeService(&memoryDisputeRepository{})
+	userID, txID, resolverID := uuid.New(), uuid.New(), uuid.New()
+
+	dispute, err := service.Create(context.Background(), userID, &models.CreateDisputeRequest{
+		TransactionID: txID, Reason: "  No reconozco este cargo  ",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "No reconozco este cargo", dispute.Reason)
+	assert.Equal(t, models.DisputeStatusOpen, dispute.Status)
+
+	_, err = service.Create(context.Background(), userID, &models.CreateDisputeRequest{TransactionID: txID, Reason: "Otra vez"})
+	assert.ErrorIs(t, err, db.ErrDisputeExists)
+
+	_, err = service.Resolve(context.Background(), dispute.ID, resolverID, &models.ResolveDisputeRequest{Status: models.DisputeStatusOpen})
+	assert.ErrorIs(t, err, db.ErrInvalidDisputeResolution)
+
+	resolved, err := service.Resolve(context.Background(), dispute.ID, resolverID, &models.ResolveDisputeRequest{
+		Status: models.DisputeStatusRejected, Note: "El cargo fue autorizado con OTP",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.DisputeStatusRejected, resolved.Status)
+	assert.Equal(t, resolverID, *resolved.ResolvedBy)
+	assert.NotNil(t, resolved.ResolvedAt)
+
+	_, err = service.Resolve(context.Background(), dispute.ID, resolverID, &models.ResolveDisputeRequest{Status: models.DisputeStatusApproved})
+	assert.ErrorIs(t, err, db.ErrDisputeNotOpen)
+
+	// Una vez resuelta, la transacción puede disputarse de nuevo
+	_, err = service.Create(context.Background(), userID, &models.CreateDisputeRequest{TransactionID: txID, Reason: "Nueva evidencia"})
+	assert.NoError(t, err)
+}
+
+func TestDisputeService_Create_ValidatesReason(t *testing.T) {
+	service := db.NewDisputeService(&memoryDisputeRepository{})
+
+	for _, reason := range []string{"   ", strings.Repeat("a", models.MaxDisputeReasonLength+1)} {
+		_, err := service.Create(context.Background(), uuid.New(), &models.CreateDisputeRequest{TransactionID: uuid.New(), Reason: reason})
+		assert.ErrorIs(t, err, db.ErrInvalidDisputeReason)
+	}
+}
@@ -0,0 +1,31 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"banca-en-linea/backend/internal/fee"
+)
+
+func TestFeeSchedule_Calculate(t *testing.T) {
+	schedule := fee.FeeSchedule{BaseFeeCents: 25, PercentFee: 0.5}
+
+	assert.Equal(t, uint64(25), schedule.Calculate(0))
+	assert.Equal(t, uint64(75), schedule.Calculate(10000))
+	// 0.5% de 333 = 1.665, se redondea a 2 centavos
+	assert.Equal(t, uint64(27), schedule.Calculate(333))
+
+	assert.Zero(t, fee.FeeSchedule{}.Calculate(10000))
+}
+
+func TestNewScheduleFromEnv(t *testing.T) {
+	t.Setenv("TRANSFER_BASE_FEE_CENTS", "10")
+	t.Setenv("TRANSFER_PERCENT_FEE", "1.5")
+
+	schedule := fee.NewScheduleFromEnv()
+	assert.Equal(t, fee.FeeSchedule{BaseFeeCents: 10, PercentFee: 1.5}, schedule)
+
+	t.Setenv("TRANSFER_PERCENT_FEE", "not-a-number")
+	assert.Zero(t, fee.NewScheduleFromEnv().PercentFee)
+}
@@ -0,0 +1,669 @@
+package tests
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"golang.org/x/time/rate"
+
+	"banca-en-linea/backend/internal/audit"
+	"banca-en-linea/backend/internal/auth"
+	"banca-en-linea/backend/internal/idempotency"
+	"banca-en-linea/backend/internal/middleware"
+	"banca-en-linea/backend/models"
+)
+
+func TestResponseCache_SetsETagAndCacheControl(t *testing.T) {
+	handler := middleware.ResponseCache(5 * time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"balance":100}`))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/1/balance", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "private, max-age=5", rec.Header().Get("Cache-Control"))
+	assert.NotEmpty(t, rec.Header().Get("ETag"))
+	assert.Equal(t, `{"balance":100}`, rec.Body.String())
+}
+
+func TestResponseCache_NotModified(t *testing.T) {
+	handler := middleware.ResponseCache(5 * time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"balance":100}`))
+	}))
+
+	first := httptest.NewRecorder()
+	handler.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/", nil))
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+}
+
+func TestResponseCache_SkipsErrorResponses(t *testing.T) {
+	handler := middleware.ResponseCache(5 * time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "User not found", http.StatusNotFound)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Empty(t, rec.Header().Get("ETag"))
+}
+
+// staticUserGetter retorna siempre el mismo usuario
+type staticUserGetter struct {
+	user *models.User
+}
+
+func (g staticUserGetter) GetUser(id uuid.UUID) (*models.User, error) {
+	return g.user, nil
+}
+
+func TestRequireEmailVerified(t *testing.T) {
+	tests := []struct {
+		name     string
+		verified bool
+		expected int
+	}{
+		{"unverified email is rejected", false, http.StatusForbidden},
+		{"verified email is allowed", true, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &models.User{ID: uuid.New(), EmailVerified: tt.verified}
+			handler := middleware.RequireEmailVerified(staticUserGetter{user: user})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/transfer", nil)
+			ctx := context.WithValue(req.Context(), middleware.UserContextKey, &auth.Claims{UserID: user.ID})
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req.WithContext(ctx))
+
+			assert.Equal(t, tt.expected, rec.Code)
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		roles    []string
+		expected int
+	}{
+		{"user without admin role is rejected", []string{models.RoleUser}, http.StatusForbidden},
+		{"admin is allowed", []string{models.RoleUser, models.RoleAdmin}, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := middleware.RequireRole(models.RoleAdmin)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/users", nil)
+			ctx := context.WithValue(req.Context(), middleware.UserContextKey, &auth.Claims{UserID: uuid.New(), Roles: tt.roles})
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req.WithContext(ctx))
+
+			assert.Equal(t, tt.expected, rec.Code)
+		})
+	}
+}
+
+func TestHasRole(t *testing.T) {
+	withRoles := func(roles ...string) context.Context {
+		return context.WithValue(context.Background(), middleware.UserContextKey, &auth.Claims{UserID: uuid.New(), Roles: roles})
+	}
+
+	assert.True(t, middleware.HasRole(withRoles(models.RoleUser, models.RoleAdmin), models.RoleAdmin))
+	assert.False(t, middleware.HasRole(withRoles(models.RoleUser), models.RoleAdmin))
+	assert.False(t, middleware.HasRole(context.Background(), models.RoleAdmin))
+}
+
+func TestAuthenticatedRateLimiter_LimitsEachUserIndependently(t *testing.T) {
+	limiter := middleware.NewAuthenticatedRateLimiter(rate.Every(time.Hour), 2)
+	handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	request := func(userID uuid.UUID) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/transfer", nil)
+		ctx := context.WithValue(req.Context(), middleware.UserContextKey, &auth.Claims{UserID: userID})
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req.WithContext(ctx))
+		return rec.Code
+	}
+
+	first, second := uuid.New(), uuid.New()
+	assert.Equal(t, http.StatusOK, request(first))
+	assert.Equal(t, http.StatusOK, request(first))
+	assert.Equal(t, http.StatusTooManyRequests, request(first))
+
+	// Otro usuario (aunque comparta IP) mantiene su propio límite
+	assert.Equal(t, http.StatusOK, request(second))
+}
+
+// memoryTokenBlacklist es una lista de JWT revocados en memoria para testing
+type memoryTokenBlacklist struct {
+	jtis map[string]time.Time
+}
+
+func (b *memoryTokenBlacklist) Add(ctx context.Context, jti string, expiresAt time.Time) error {
+	b.jtis[jti] = expiresAt
+	return nil
+}
+
+func (b *memoryTokenBlacklist) Contains(ctx context.Context, jti string) (bool, error) {
+	_, ok := b.jtis[jti]
+	return ok, nil
+}
+
+func TestAuthMiddleware_RejectsRevokedToken(t *testing.T) {
+	authService := auth.NewService(auth.WithTokenBlacklist(&memoryTokenBlacklist{jtis: make(map[string]time.Time)}))
+	handler := middleware.AuthMiddleware(authService)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	token, err := authService.GenerateToken(&models.User{ID: uuid.New(), Email: "test@example.com"})
+	require.NoError(t, err)
+
+	request := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request())
+
+	claims, err := authService.ValidateToken(token)
+	require.NoError(t, err)
+	require.NotEmpty(t, claims.ID)
+	require.NoError(t, authService.RevokeToken(claims))
+
+	assert.Equal(t, http.StatusUnauthorized, request())
+}
+
+// memoryIdempotencyStore guarda las respuestas de idempotencia en memoria para testing
+type memoryIdempotencyStore struct {
+	records map[string]*models.IdempotencyRecord
+}
+
+func (s *memoryIdempotencyStore) Get(ctx context.Context, userID uuid.UUID, keyHash string) (*models.IdempotencyRecord, error) {
+	return s.records[userID.String()+keyHash], nil
+}
+
+func (s *memoryIdempotencyStore) Save(ctx context.Context, record *models.IdempotencyRecord) error {
+	s.records[record.UserID.String()+record.KeyHash] = record
+	return nil
+}
+
+func TestIdempotency_ReplaysStoredResponse(t *testing.T) {
+	store := &memoryIdempotencyStore{records: map[string]*models.IdempotencyRecord{}}
+	calls := 0
+	var keys []idempotency.Key
+	handler := middleware.Idempotency(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		key, _ := idempotency.KeyFromContext(r.Context())
+		keys = append(keys, key)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"status":"success"}`))
+	}))
+
+	userID := uuid.New()
+	send := func(user uuid.UUID, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/transfer", nil)
+		if key != "" {
+			req.Header.Set(idempotency.HeaderName, key)
+		}
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserContextKey, &auth.Claims{UserID: user}))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := send(userID, "retry-1")
+	assert.Equal(t, http.StatusCreated, first.Code)
+	require.Len(t, keys, 1)
+	assert.Equal(t, idempotency.Key{UserID: userID, Hash: idempotency.HashKey("retry-1")}, keys[0])
+
+	replay := send(userID, "retry-1")
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusCreated, replay.Code)
+	assert.Equal(t, `{"status":"success"}`, replay.Body.String())
+	assert.Equal(t, "application/json", replay.Header().Get("Content-Type"))
+	assert.Equal(t, "true", replay.Header().Get("Idempotent-Replayed"))
+
+	// La misma llave de otro usuario y las peticiones sin llave se ejecutan normalmente
+	send(uuid.New(), "retry-1")
+	send(userID, "")
+	assert.Equal(t, 3, calls)
+}
+
+func TestIdempotency_DoesNotStoreServerErrors(t *testing.T) {
+	store := &memoryIdempotencyStore{records: map[string]*models.IdempotencyRecord{}}
+	handler := middleware.Idempotency(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Error processing transfer", http.StatusInternalServerError)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transfer", nil)
+	req.Header.Set(idempotency.HeaderName, "retry-2")
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserContextKey, &auth.Claims{UserID: uuid.New()}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Empty(t, store.records)
+}
+
+func TestIdempotency_DoesNotStoreOTPChallenges(t *testing.T) {
+	store := &memoryIdempotencyStore{records: map[string]*models.IdempotencyRecord{}}
+	handler := middleware.Idempotency(store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/transfer", nil)
+	req.Header.Set(idempotency.HeaderName, "large-transfer")
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserContextKey, &auth.Claims{UserID: uuid.New()}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Empty(t, store.records)
+}
+
+func TestIdempotencyTransferID_IsDeterministic(t *testing.T) {
+	key := idempotency.Key{UserID: uuid.New(), Hash: idempotency.HashKey("retry-3")}
+
+	assert.Equal(t, key.TransferID("transfer"), key.TransferID("transfer"))
+	assert.NotEqual(t, key.TransferID("transfer"), key.TransferID("transfer_fee"))
+	// Los IDs derivados no se cruzan con los de la secuencia de transferencias
+	assert.GreaterOrEqual(t, key.TransferID("transfer"), uint64(1)<<63)
+}
+
+func TestIdempotencyTransferID_ScopedToUser(t *testing.T) {
+	keyHash := idempotency.HashKey("retry-4")
+	first := idempotency.Key{UserID: uuid.New(), Hash: keyHash}
+	second := idempotency.Key{UserID: uuid.New(), Hash: keyHash}
+
+	// La misma llave enviada por dos usuarios no puede chocar en TigerBeetle
+	assert.NotEqual(t, first.TransferID("transfer"), second.TransferID("transfer"))
+}
+
+func TestRemoteAddr_AddsClientAddressToContext(t *testing.T) {
+	var remoteAddr string
+	handler := middleware.RemoteAddr(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remoteAddr = audit.RemoteAddrFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/users/1/deposit", nil)
+	req.RemoteAddr = "198.51.100.20:40000"
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "198.51.100.20:40000", remoteAddr)
+}
+
+func TestPrometheusMiddleware_UsesRouteTemplate(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	router := mux.NewRouter()
+	router.Use(middleware.PrometheusMiddleware(registry))
+	router.HandleFunc("/api/v1/users/{id}/accounts", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}).Methods("POST")
+
+	for _, id := range []string{"abc-123", "def-456"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/users/"+id+"/accounts", nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	expected := `
+# HELP http_requests_total Total de solicitudes HTTP por método, ruta y código de estado.
+# TYPE http_requests_total counter
+http_requests_total{method="POST",path="/api/v1/users/{id}/accounts",status="201"} 2
+`
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "http_requests_total"))
+
+	count, err := testutil.GatherAndCount(registry, "http_request_duration_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestTimeout_CancelsRequestContext(t *testing.T) {
+	cancelled := make(chan struct{})
+	handler := middleware.Timeout(20 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		close(cancelled)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/users/1", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("request context was not cancelled")
+	}
+}
+
+func TestTimeout_FastRequestSucceeds(t *testing.T) {
+	handler := middleware.Timeout(time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/transfer", nil))
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
+
+func TestMaxBodySize_RejectsLargeBody(t *testing.T) {
+	handler := middleware.MaxBodySize(16)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			middleware.WriteDecodeError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/users", strings.NewReader(`{"email":"`+strings.Repeat("a", 64)+`"}`)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/users", strings.NewReader(`{"email":`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/users", strings.NewReader(`{"a":"b"}`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestCorrelationID_GeneratesAndEchoesID(t *testing.T) {
+	var fromContext string
+	handler := middleware.CorrelationID()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fromContext = middleware.CorrelationIDFromContext(r.Context())
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/users/1", nil))
+
+	id := rec.Header().Get(middleware.CorrelationIDHeader)
+	_, err := uuid.Parse(id)
+	require.NoError(t, err)
+	assert.Equal(t, id, fromContext)
+}
+
+func TestCorrelationID_UsesIncomingHeader(t *testing.T) {
+	handler := middleware.CorrelationID()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/1", nil)
+	req.Header.Set("X-Request-ID", "req-123")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, "req-123", rec.Header().Get(middleware.CorrelationIDHeader))
+
+	// Un ID con saltos de línea se reemplaza para evitar inyectar líneas en los logs
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/users/1", nil)
+	req.Header.Set(middleware.CorrelationIDHeader, "abc\nforged log line")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.NotContains(t, rec.Header().Get(middleware.CorrelationIDHeader), "forged")
+}
+
+func TestLogger_IncludesCorrelationID(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	defer zap.ReplaceGlobals(zap.New(core))()
+
+	handler := middleware.CorrelationID()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		middleware.Logger(r.Context()).Info("handling request")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/1", nil)
+	req.Header.Set(middleware.CorrelationIDHeader, "corr-42")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "corr-42", logs.All()[0].ContextMap()["correlation_id"])
+}
+
+func TestRecovery_LogsPanicAndReturns500(t *testing.T) {
+	core, logs := observer.New(zap.ErrorLevel)
+	handler := middleware.CorrelationID()(middleware.Recovery(zap.New(core))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var user *models.User
+		_ = user.Email
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/1", nil)
+	req.Header.Set(middleware.CorrelationIDHeader, "corr-500")
+	rec := httptest.NewRecorder()
+	require.NotPanics(t, func() { handler.ServeHTTP(rec, req) })
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.JSONEq(t, `{"error":"internal server error"}`, rec.Body.String())
+
+	require.Equal(t, 1, logs.Len())
+	fields := logs.All()[0].ContextMap()
+	assert.Equal(t, "corr-500", fields["correlation_id"])
+	assert.Contains(t, fields["stack"], "runtime/debug.Stack")
+}
+
+func TestCORS_EchoesAllowedOrigin(t *testing.T) {
+	handler := middleware.CORS(middleware.CORSConfig{
+		AllowedOrigins:   []string{"https://banca.example.com"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Content-Type"},
+		AllowCredentials: true,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+	req.Header.Set("Origin", "https://banca.example.com")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, "https://banca.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "GET, POST", rec.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Origin", rec.Header().Get("Vary"))
+}
+
+func TestCORS_OmitsHeaderForUnknownOrigin(t *testing.T) {
+	handler := middleware.CORS(middleware.CORSConfig{
+		AllowedOrigins:   []string{"https://banca.example.com"},
+		AllowCredentials: true,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, origin := range []string{"https://evil.example.com", "https://banca.example.com.evil.com", ""} {
+		req := httptest.NewRequest(http.MethodOptions, "/api/v1/users", nil)
+		req.Header.Set("Origin", origin)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"), origin)
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"), origin)
+	}
+}
+
+func TestNewCORSConfigFromEnv(t *testing.T) {
+	t.Setenv("CORS_ORIGINS", "https://banca.example.com, https://admin.example.com")
+	t.Setenv("CORS_METHODS", "")
+	t.Setenv("CORS_HEADERS", "Content-Type,Authorization")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "false")
+
+	config := middleware.NewCORSConfigFromEnv()
+
+	assert.Equal(t, []string{"https://banca.example.com", "https://admin.example.com"}, config.AllowedOrigins)
+	assert.Equal(t, middleware.DefaultCORSConfig().AllowedMethods, config.AllowedMethods)
+	assert.Equal(t, []string{"Content-Type", "Authorization"}, config.AllowedHeaders)
+	assert.False(t, config.AllowCredentials)
+}
+
+func TestNewVersionedRouter_SetsVersionHeader(t *testing.T) {
+	router := mux.NewRouter()
+	middleware.NewVersionedRouter(router, "1").HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	middleware.NewVersionedRouter(router, "v2").HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	for path, version := range map[string]string{"/api/v1/users": "v1", "/api/v2/users": "v2"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code, path)
+		assert.Equal(t, version, rec.Header().Get(middleware.APIVersionHeader), path)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestSecurityHeaders_SetOnEveryResponse(t *testing.T) {
+	handler := middleware.SecurityHeaders()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/users/1", nil))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "max-age=31536000; includeSubDomains", rec.Header().Get("Strict-Transport-Security"))
+	assert.Equal(t, "DENY", rec.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "default-src 'none'", rec.Header().Get("Content-Security-Policy"))
+	assert.Equal(t, "strict-origin-when-cross-origin", rec.Header().Get("Referrer-Policy"))
+}
+
+func TestGzipCompression_CompressesLargeResponses(t *testing.T) {
+	body := "[" + strings.Repeat(`{"amount":100},`, 100) + `{"amount":100}]`
+	handler := middleware.GzipCompression()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/1/transactions", nil)
+	req.Header.Set("Accept-Encoding", "br, gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+
+	reader, err := gzip.NewReader(rec.Body)
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(decompressed))
+}
+
+func TestGzipCompression_SkipsSmallResponsesAndClientsWithoutGzip(t *testing.T) {
+	small := middleware.GzipCompression()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`[]`))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/1/transactions", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	small.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Equal(t, "[]", rec.Body.String())
+
+	large := middleware.GzipCompression()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("a", 2048)))
+	}))
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/users/1/transactions", nil)
+	req.Header.Set("Accept-Encoding", "gzip;q=0, identity")
+	rec = httptest.NewRecorder()
+	large.ServeHTTP(rec, req)
+	assert.Empty(t, rec.Header().Get("Content-Encoding"))
+	assert.Len(t, rec.Body.String(), 2048)
+}
+
+// memoryActivityLog guarda en memoria la actividad registrada
+type memoryActivityLog struct {
+	entries []*models.ActivityLog
+}
+
+func (l *memoryActivityLog) Record(ctx context.Context, entry *models.ActivityLog) error {
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+func TestActivityLogger_RecordsAuthenticatedRequests(t *testing.T) {
+	activity := &memoryActivityLog{}
+	userID := uuid.New()
+	router := mux.NewRouter()
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "" {
+				r = r.WithContext(context.WithValue(r.Context(), middleware.UserContextKey, &auth.Claims{UserID: userID}))
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	router.Use(middleware.ActivityLogger(activity))
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	router.HandleFunc("/api/v1/users/{id}/balance", ok).Methods("GET")
+	router.HandleFunc("/api/v1/health", ok).Methods("GET")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/abc/balance", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Set("User-Agent", "banca-app/2.1")
+	req.RemoteAddr = "198.51.100.20:40000"
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	// Las solicitudes anónimas y las sondas de salud no se registran
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/users/abc/balance", nil))
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Len(t, activity.entries, 1)
+	entry := activity.entries[0]
+	assert.Equal(t, userID, entry.UserID)
+	assert.Equal(t, "/api/v1/users/{id}/balance", entry.Action)
+	assert.Equal(t, "/api/v1/users/abc/balance", entry.Endpoint)
+	assert.Equal(t, http.MethodGet, entry.Method)
+	assert.Equal(t, "198.51.100.20", entry.IPAddress)
+	assert.Equal(t, "banca-app/2.1", entry.UserAgent)
+}
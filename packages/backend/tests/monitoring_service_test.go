@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/monitoring"
+	"banca-en-linea/backend/models"
+)
+
+func TestMonitoringService_PublishToSubscribers(t *testing.T) {
+	service := monitoring.NewMonitoringService()
+
+	events, unsubscribe, err := service.Subscribe()
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	service.Publish(models.TransactionEvent{ID: "tx-1", Type: models.TransactionEventDeposit, Amount: 5000})
+
+	event := <-events
+	assert.Equal(t, "tx-1", event.ID)
+	assert.Equal(t, models.TransactionEventDeposit, event.Type)
+	assert.Equal(t, uint64(5000), event.Amount)
+}
+
+func TestMonitoringService_SubscriberLimit(t *testing.T) {
+	service := monitoring.NewMonitoringService()
+
+	var unsubscribes []func()
+	for i := 0; i < monitoring.MaxSubscribers; i++ {
+		_, unsubscribe, err := service.Subscribe()
+		require.NoError(t, err)
+		unsubscribes = append(unsubscribes, unsubscribe)
+	}
+
+	_, _, err := service.Subscribe()
+	assert.ErrorIs(t, err, monitoring.ErrTooManySubscribers)
+
+	// Al desconectarse un suscriptor se libera un lugar
+	unsubscribes[0]()
+	assert.Equal(t, monitoring.MaxSubscribers-1, service.SubscriberCount())
+
+	_, unsubscribe, err := service.Subscribe()
+	assert.NoError(t, err)
+	unsubscribe()
+}
+
+func TestBalanceBroker_PublishOnlyToUser(t *testing.T) {
+	broker := monitoring.NewBalanceBroker()
+	userID := uuid.New()
+	otherUserID := uuid.New()
+
+	events, unsubscribe, err := broker.Subscribe(userID)
+	require.NoError(t, err)
+	otherEvents, unsubscribeOther, err := broker.Subscribe(otherUserID)
+	require.NoError(t, err)
+	defer unsubscribeOther()
+
+	assert.True(t, broker.HasSubscribers(userID))
+	broker.PublishBalance(userID, models.BalanceChangedEvent{AccountID: 42, NewBalance: 1500})
+
+	event := <-events
+	assert.Equal(t, int64(42), event.AccountID)
+	assert.Equal(t, int64(1500), event.NewBalance)
+	assert.Empty(t, otherEvents)
+
+	// Al desconectarse el canal se cierra y el usuario deja de tener suscriptores
+	unsubscribe()
+	_, ok := <-events
+	assert.False(t, ok)
+	assert.False(t, broker.HasSubscribers(userID))
+}
+
+func TestBalanceBroker_StreamLimitPerUser(t *testing.T) {
+	broker := monitoring.NewBalanceBroker()
+	userID := uuid.New()
+
+	for i := 0; i < monitoring.MaxBalanceStreamsPerUser; i++ {
+		_, unsubscribe, err := broker.Subscribe(userID)
+		require.NoError(t, err)
+		defer unsubscribe()
+	}
+
+	_, _, err := broker.Subscribe(userID)
+	assert.ErrorIs(t, err, monitoring.ErrTooManySubscribers)
+
+	// El límite es por usuario
+	_, unsubscribe, err := broker.Subscribe(uuid.New())
+	assert.NoError(t, err)
+	unsubscribe()
+}
@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/email"
+	"banca-en-linea/backend/models"
+)
+
+// capturingSender guarda el último email enviado
+type capturingSender struct {
+	to, subject, body string
+}
+
+func (s *capturingSender) Send(ctx context.Context, to, subject, body string) error {
+	s.to, s.subject, s.body = to, subject, body
+	return nil
+}
+
+func TestNotificationService_SendTransactionAlert(t *testing.T) {
+	sender := &capturingSender{}
+	service := email.NewNotificationService(sender)
+
+	user := &models.User{ID: uuid.New(), Email: "ana@example.com", FirstName: "Ana"}
+	event := email.TransactionEvent{
+		TransactionEvent: models.TransactionEvent{
+			Type:      models.TransactionEventTransfer,
+			Amount:    150005,
+			Timestamp: time.Date(2024, 3, 1, 14, 30, 0, 0, time.UTC),
+		},
+		CounterpartName: "Luis Pérez",
+	}
+
+	err := service.SendTransactionAlert(user, event)
+	require.NoError(t, err)
+
+	assert.Equal(t, "ana@example.com", sender.to)
+	assert.Contains(t, sender.body, "una transferencia")
+	assert.Contains(t, sender.body, "L 1500.05")
+	assert.Contains(t, sender.body, "Destinatario: Luis Pérez")
+	assert.Contains(t, sender.body, "2024-03-01 14:30:00 UTC")
+}
+
+func TestNotificationService_SendTransactionAlert_WithoutCounterpart(t *testing.T) {
+	sender := &capturingSender{}
+	service := email.NewNotificationService(sender)
+
+	user := &models.User{ID: uuid.New(), Email: "ana@example.com", FirstName: "Ana"}
+	err := service.SendTransactionAlert(user, email.TransactionEvent{
+		TransactionEvent: models.TransactionEvent{Type: models.TransactionEventWithdrawal, Amount: 200000, Timestamp: time.Now()},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, sender.body, "un retiro")
+	assert.NotContains(t, sender.body, "Destinatario")
+}
+
+func TestNotificationService_SendDormancyNotice(t *testing.T) {
+	sender := &capturingSender{}
+	service := email.NewNotificationService(sender)
+
+	user := &models.User{ID: uuid.New(), Email: "ana@example.com", FirstName: "Ana"}
+	err := service.SendDormancyNotice(user, &models.BankAccount{AccountNumber: "2001000012345678"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "ana@example.com", sender.to)
+	assert.Contains(t, sender.body, "terminada en 5678")
+	assert.NotContains(t, sender.body, "2001000012345678")
+}
@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/mocks"
+	"banca-en-linea/backend/models"
+)
+
+// memoryOTPCodeRepository guarda los códigos OTP en memoria
+type memoryOTPCodeRepository struct {
+	codes map[uuid.UUID]*models.OTPCode
+}
+
+func (r *memoryOTPCodeRepository) Create(ctx context.Context, code *models.OTPCode) (*models.OTPCode, error) {
+	created := *code
+	created.ID = uuid.New()
+	r.codes[created.ID] = &created
+	return &created, nil
+}
+
+func (r *memoryOTPCodeRepository) GetActive(ctx context.Context, id, userID uuid.UUID) (*models.OTPCode, error) {
+	code, ok := r.codes[id]
+	if !ok || code.UserID != userID || code.UsedAt != nil || !code.ExpiresAt.After(time.Now()) {
+		return nil, db.ErrOTPCodeNotFound
+	}
+	return code, nil
+}
+
+func (r *memoryOTPCodeRepository) IncrementAttempts(ctx context.Context, id uuid.UUID) error {
+	r.codes[id].Attempts++
+	return nil
+}
+
+func (r *memoryOTPCodeRepository) Consume(ctx context.Context, id uuid.UUID) error {
+	now := time.Now()
+	r.codes[id].UsedAt = &now
+	return nil
+}
+
+// capturingOTPNotifier guarda el último código enviado
+type capturingOTPNotifier struct {
+	code string
+}
+
+func (n *capturingOTPNotifier) SendOTPEmail(user *models.User, code string, ttl time.Duration) error {
+	n.code = code
+	return nil
+}
+
+func newTestOTPService() (*db.OTPService, *capturingOTPNotifier, uuid.UUID) {
+	mockRepo := new(mocks.MockUserRepository)
+	user := &models.User{ID: uuid.New(), Email: "ana@example.com"}
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+
+	notifier := &capturingOTPNotifier{}
+	service := db.NewOTPService(&memoryOTPCodeRepository{codes: map[uuid.UUID]*models.OTPCode{}}, mockRepo, notifier)
+	return service, notifier, user.ID
+}
+
+func TestOTPService_GenerateAndVerify(t *testing.T) {
+	service, notifier, userID := newTestOTPService()
+	operation := "user:" + uuid.NewString() + "|2000000"
+
+	challenge, err := service.Generate(context.Background(), userID, operation)
+	require.NoError(t, err)
+	assert.Len(t, notifier.code, 6)
+
+	assert.ErrorIs(t, service.Verify(context.Background(), uuid.New(), challenge, notifier.code, operation), db.ErrInvalidOTP)
+	require.NoError(t, service.Verify(context.Background(), userID, challenge, notifier.code, operation))
+
+	// El código solo puede usarse una vez
+	assert.ErrorIs(t, service.Verify(context.Background(), userID, challenge, notifier.code, operation), db.ErrInvalidOTP)
+}
+
+func TestOTPService_Verify_RejectsOtherOperation(t *testing.T) {
+	service, notifier, userID := newTestOTPService()
+	recipient := "user:" + uuid.NewString()
+
+	challenge, err := service.Generate(context.Background(), userID, recipient+"|2000000")
+	require.NoError(t, err)
+
+	// El código no confirma otro monto ni otro destino, y cada intento cuenta
+	for i := 0; i < 4; i++ {
+		assert.ErrorIs(t, service.Verify(context.Background(), userID, challenge, notifier.code, recipient+"|9000000"), db.ErrInvalidOTP)
+	}
+	assert.ErrorIs(t, service.Verify(context.Background(), userID, challenge, notifier.code, "user:"+uuid.NewString()+"|2000000"), db.ErrInvalidOTP)
+
+	assert.ErrorIs(t, service.Verify(context.Background(), userID, challenge, notifier.code, recipient+"|2000000"), db.ErrInvalidOTP)
+}
+
+func TestOTPService_Verify_LimitsAttempts(t *testing.T) {
+	service, notifier, userID := newTestOTPService()
+	operation := "user:" + uuid.NewString() + "|2000000"
+
+	challenge, err := service.Generate(context.Background(), userID, operation)
+	require.NoError(t, err)
+
+	wrong := "000000"
+	if notifier.code == wrong {
+		wrong = "111111"
+	}
+	for i := 0; i < 5; i++ {
+		assert.ErrorIs(t, service.Verify(context.Background(), userID, challenge, wrong, operation), db.ErrInvalidOTP)
+	}
+
+	assert.ErrorIs(t, service.Verify(context.Background(), userID, challenge, notifier.code, operation), db.ErrInvalidOTP)
+}
@@ -0,0 +1,48 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/auth"
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/handlers"
+	"banca-en-linea/backend/internal/middleware"
+	"banca-en-linea/backend/internal/mocks"
+	"banca-en-linea/backend/models"
+)
+
+func TestPaymentRequestHandler_Approve_RequiresOTPForLargeRequests(t *testing.T) {
+	service, repo, mockTB, requester, payer := newPaymentRequestFixture(t)
+
+	otpUsers := new(mocks.MockUserRepository)
+	otpUsers.On("GetByID", mock.Anything, payer.ID).Return(payer, nil)
+	notifier := &capturingOTPNotifier{}
+	otpService := db.NewOTPService(&memoryOTPCodeRepository{codes: map[uuid.UUID]*models.OTPCode{}}, otpUsers, notifier)
+	handler := handlers.NewPaymentRequestHandler(service, handlers.NewLargeTransferGuard(otpService, 100000))
+
+	request, err := service.Create(context.Background(), &models.CreatePaymentRequestRequest{
+		RequesterUserID: requester.ID, PayerUserID: payer.ID, Amount: 250000,
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/payment-requests/"+request.ID.String()+"/approve", nil)
+	req = mux.SetURLVars(req, map[string]string{"paymentRequestId": request.ID.String()})
+	ctx := context.WithValue(req.Context(), middleware.UserContextKey, &auth.Claims{UserID: payer.ID})
+	rec := httptest.NewRecorder()
+	handler.Approve(rec, req.WithContext(ctx))
+
+	// La solicitud sigue pendiente y no se transfiere nada hasta confirmar el código
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Len(t, notifier.code, 6)
+	assert.Equal(t, models.PaymentRequestStatusPending, repo.requests[0].Status)
+	mockTB.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,191 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/mocks"
+	"banca-en-linea/backend/models"
+)
+
+// memoryPaymentRequestRepository guarda las solicitudes de pago en memoria
+type memoryPaymentRequestRepository struct {
+	db.PaymentRequestRepository
+	requests []*models.PaymentRequest
+}
+
+func (r *memoryPaymentRequestRepository) Create(ctx context.Context, request *models.PaymentRequest) (*models.PaymentRequest, error) {
+	created := *request
+	created.ID = uuid.New()
+	created.Status = models.PaymentRequestStatusPending
+	created.CreatedAt = time.Now()
+	r.requests = append(r.requests, &created)
+	return &created, nil
+}
+
+func (r *memoryPaymentRequestRepository) find(id, payerID uuid.UUID) (*models.PaymentRequest, error) {
+	for _, request := range r.requests {
+		if request.ID == id && request.PayerUserID == payerID {
+			if request.Status != models.PaymentRequestStatusPending {
+				return nil, db.ErrPaymentRequestNotPending
+			}
+			return request, nil
+		}
+	}
+	return nil, db.ErrPaymentRequestNotFound
+}
+
+func (r *memoryPaymentRequestRepository) GetByID(ctx context.Context, id, payerID uuid.UUID) (*models.PaymentRequest, error) {
+	for _, request := range r.requests {
+		if request.ID == id && request.PayerUserID == payerID {
+			return request, nil
+		}
+	}
+	return nil, db.ErrPaymentRequestNotFound
+}
+
+func (r *memoryPaymentRequestRepository) Reject(ctx context.Context, id, payerID uuid.UUID) (*models.PaymentRequest, error) {
+	request, err := r.find(id, payerID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	request.Status = models.PaymentRequestStatusRejected
+	request.RespondedAt = &now
+	return request, nil
+}
+
+func (r *memoryPaymentRequestRepository) Fulfill(ctx context.Context, id, payerID uuid.UUID, pay func(ctx context.Context, request *models.PaymentRequest) error) (*models.PaymentRequest, error) {
+	request, err := r.find(id, payerID)
+	if err != nil {
+		return nil, err
+	}
+	if !request.ExpiresAt.After(time.Now()) {
+		return nil, db.ErrPaymentRequestExpired
+	}
+	if err := pay(ctx, request); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	request.Status = models.PaymentRequestStatusFulfilled
+	request.RespondedAt = &now
+	return request, nil
+}
+
+// newPaymentRequestFixture crea el servicio con un solicitante y un pagador con cuentas en TigerBeetle
+func newPaymentRequestFixture(t *testing.T) (*db.PaymentRequestService, *memoryPaymentRequestRepository, *MockTigerBeetleService, *models.User, *models.User) {
+	t.Helper()
+
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	repo := &memoryPaymentRequestRepository{}
+	service := db.NewPaymentRequestService(repo, mockRepo, db.NewUserService(mockRepo, mockTB))
+
+	requesterAccountID, payerAccountID := int64(111), int64(222)
+	requester := &models.User{ID: uuid.New(), IsActive: true, TigerBeetleAccountID: &requesterAccountID}
+	payer := &models.User{ID: uuid.New(), IsActive: true, TigerBeetleAccountID: &payerAccountID, DailyTransferLimitCents: 1000000}
+	mockRepo.On("GetByID", mock.Anything, requester.ID).Return(requester, nil)
+	mockRepo.On("GetByID", mock.Anything, payer.ID).Return(payer, nil)
+
+	return service, repo, mockTB, requester, payer
+}
+
+func TestPaymentRequestService_Approve_TransfersToRequester(t *testing.T) {
+	service, _, mockTB, requester, payer := newPaymentRequestFixture(t)
+	mockTB.On("GetAccountBalance", uint64(222)).Return(uint64(0), uint64(10000), nil)
+	mockTB.On("Transfer", uint64(222), uint64(111), uint64(2500), mock.AnythingOfType("uint64")).Return(nil).Once()
+
+	request, err := service.Create(context.Background(), &models.CreatePaymentRequestRequest{
+		RequesterUserID: requester.ID, PayerUserID: payer.ID, Amount: 2500, Description: "  Cena  ",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Cena", request.Description)
+	assert.True(t, request.ExpiresAt.After(time.Now().Add(6*24*time.Hour)))
+
+	// Solo el pagador puede aprobarla
+	_, err = service.Approve(context.Background(), request.ID, requester.ID)
+	assert.ErrorIs(t, err, db.ErrPaymentRequestNotFound)
+
+	approved, err := service.Approve(context.Background(), request.ID, payer.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.PaymentRequestStatusFulfilled, approved.Status)
+	assert.NotNil(t, approved.RespondedAt)
+
+	// Una segunda aprobación no vuelve a transferir
+	_, err = service.Approve(context.Background(), request.ID, payer.ID)
+	assert.ErrorIs(t, err, db.ErrPaymentRequestNotPending)
+	mockTB.AssertExpectations(t)
+}
+
+func TestPaymentRequestService_Approve_KeepsPendingWhenTransferFails(t *testing.T) {
+	service, repo, mockTB, requester, payer := newPaymentRequestFixture(t)
+	mockTB.On("GetAccountBalance", uint64(222)).Return(uint64(0), uint64(1000), nil)
+
+	request, err := service.Create(context.Background(), &models.CreatePaymentRequestRequest{
+		RequesterUserID: requester.ID, PayerUserID: payer.ID, Amount: 2500,
+	})
+	require.NoError(t, err)
+
+	_, err = service.Approve(context.Background(), request.ID, payer.ID)
+	var fundsErr *db.InsufficientFundsError
+	assert.ErrorAs(t, err, &fundsErr)
+	assert.Equal(t, models.PaymentRequestStatusPending, repo.requests[0].Status)
+	mockTB.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPaymentRequestService_Approve_RejectsExpired(t *testing.T) {
+	service, repo, mockTB, requester, payer := newPaymentRequestFixture(t)
+
+	request, err := service.Create(context.Background(), &models.CreatePaymentRequestRequest{
+		RequesterUserID: requester.ID, PayerUserID: payer.ID, Amount: 2500,
+	})
+	require.NoError(t, err)
+	repo.requests[0].ExpiresAt = time.Now().Add(-time.Minute)
+
+	_, err = service.Approve(context.Background(), request.ID, payer.ID)
+	assert.ErrorIs(t, err, db.ErrPaymentRequestExpired)
+	mockTB.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPaymentRequestService_Reject(t *testing.T) {
+	service, _, _, requester, payer := newPaymentRequestFixture(t)
+
+	request, err := service.Create(context.Background(), &models.CreatePaymentRequestRequest{
+		RequesterUserID: requester.ID, PayerUserID: payer.ID, Amount: 2500,
+	})
+	require.NoError(t, err)
+
+	rejected, err := service.Reject(context.Background(), request.ID, payer.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.PaymentRequestStatusRejected, rejected.Status)
+
+	_, err = service.Approve(context.Background(), request.ID, payer.ID)
+	assert.ErrorIs(t, err, db.ErrPaymentRequestNotPending)
+}
+
+func TestPaymentRequestService_Create_Validates(t *testing.T) {
+	service, _, _, requester, payer := newPaymentRequestFixture(t)
+
+	_, err := service.Create(context.Background(), &models.CreatePaymentRequestRequest{
+		RequesterUserID: requester.ID, PayerUserID: requester.ID, Amount: 2500,
+	})
+	assert.ErrorIs(t, err, db.ErrSelfPaymentRequest)
+
+	_, err = service.Create(context.Background(), &models.CreatePaymentRequestRequest{
+		RequesterUserID: requester.ID, PayerUserID: payer.ID, Amount: 0,
+	})
+	assert.ErrorIs(t, err, db.ErrInvalidPaymentRequest)
+
+	payer.IsActive = false
+	_, err = service.Create(context.Background(), &models.CreatePaymentRequestRequest{
+		RequesterUserID: requester.ID, PayerUserID: payer.ID, Amount: 2500,
+	})
+	assert.ErrorIs(t, err, db.ErrRecipientInactive)
+}
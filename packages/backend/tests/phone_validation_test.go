@@ -0,0 +1,29 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"banca-en-linea/backend/internal/validation"
+)
+
+func TestValidatePhone_Valid(t *testing.T) {
+	valid := []string{"+5041234567", "+504 9876-5432", "+1 415-555-0100", "+123456789012345"}
+
+	for _, phone := range valid {
+		assert.NoError(t, validation.ValidatePhone(phone), "phone %q", phone)
+	}
+}
+
+func TestValidatePhone_Invalid(t *testing.T) {
+	invalid := []string{"", "98765432", "+0123456789", "+12345", "+1234567890123456", "+504 (987) 65432", "+504.9876.5432"}
+
+	for _, phone := range invalid {
+		assert.ErrorIs(t, validation.ValidatePhone(phone), validation.ErrInvalidPhone, "phone %q", phone)
+	}
+}
+
+func TestNormalizePhone_StripsSpacesAndDashes(t *testing.T) {
+	assert.Equal(t, "+50498765432", validation.NormalizePhone(" +504 9876-5432 "))
+}
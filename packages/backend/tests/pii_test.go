@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/pii"
+)
+
+func TestRedact_HidesDeniedKeys(t *testing.T) {
+	body := []byte(`{
+		"email": "ana@example.com",
+		"Password": "s3cret",
+		"new_password": "n3w",
+		"amount": 150000.10,
+		"confirmed_account_number": "2001000012345678",
+		"cards": [{"card_number": "4111111111111111", "cvv": "123", "brand": "visa"}]
+	}`)
+
+	var redacted map[string]interface{}
+	require.NoError(t, json.Unmarshal(pii.Redact(body), &redacted))
+
+	assert.Equal(t, "ana@example.com", redacted["email"])
+	assert.Equal(t, pii.Placeholder, redacted["Password"])
+	assert.Equal(t, pii.Placeholder, redacted["new_password"])
+	assert.Equal(t, pii.Placeholder, redacted["confirmed_account_number"])
+	assert.Equal(t, 150000.10, redacted["amount"])
+
+	card := redacted["cards"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, pii.Placeholder, card["card_number"])
+	assert.Equal(t, pii.Placeholder, card["cvv"])
+	assert.Equal(t, "visa", card["brand"])
+}
+
+func TestRedact_CustomDenyListAndInvalidJSON(t *testing.T) {
+	redactor := pii.NewRedactor("phone")
+	assert.JSONEq(t, `{"phone":"[REDACTED]","password":"visible"}`,
+		string(redactor.Redact([]byte(`{"phone":"+50499999999","password":"visible"}`))))
+
+	// Un cuerpo que no es JSON no se puede revisar y se oculta completo
+	assert.Equal(t, pii.Placeholder, string(pii.Redact([]byte("password=s3cret&user=ana"))))
+	assert.Empty(t, pii.Redact(nil))
+}
@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/problem"
+)
+
+func TestProblemWrite_BlankType(t *testing.T) {
+	rec := httptest.NewRecorder()
+	problem.Write(rec, http.StatusNotFound, "", "user 42 does not exist", "/api/v1/users/42", nil)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, problem.ContentType, rec.Header().Get("Content-Type"))
+
+	var body map[string]any
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Equal(t, map[string]any{
+		"type":     "about:blank",
+		"title":    "Not Found",
+		"status":   float64(http.StatusNotFound),
+		"detail":   "user 42 does not exist",
+		"instance": "/api/v1/users/42",
+	}, body)
+}
+
+func TestProblemWriteType_IncludesExtensions(t *testing.T) {
+	rec := httptest.NewRecorder()
+	problem.WriteType(rec, problem.ErrInsufficientFunds, "", "/api/v1/transfer", map[string]any{
+		"available": 100,
+		"status":    999,
+	})
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	var body map[string]any
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Equal(t, "/problems/insufficient-funds", body["type"])
+	assert.Equal(t, "Insufficient funds", body["title"])
+	assert.Equal(t, float64(http.StatusUnprocessableEntity), body["status"])
+	assert.Equal(t, float64(100), body["available"])
+	assert.NotContains(t, body, "detail")
+}
+
+func TestProblemType_IsError(t *testing.T) {
+	err := error(problem.ErrAccountFrozen)
+
+	assert.True(t, errors.Is(err, problem.ErrAccountFrozen))
+	assert.Equal(t, "Account is frozen", err.Error())
+	assert.Equal(t, http.StatusForbidden, problem.ErrAccountFrozen.Status())
+}
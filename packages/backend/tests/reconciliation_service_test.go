@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/fee"
+	"banca-en-linea/backend/internal/mocks"
+	"banca-en-linea/backend/internal/tigerbeetle"
+	"banca-en-linea/backend/models"
+)
+
+// expectedBalanceTransactionRepository retorna balances esperados fijos por usuario
+type expectedBalanceTransactionRepository struct {
+	db.TransactionRepository
+	balances map[uuid.UUID]int64
+}
+
+func (r *expectedBalanceTransactionRepository) GetUserExpectedBalance(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return r.balances[userID], nil
+}
+
+// memoryReconciliationRepository guarda las diferencias registradas en memoria
+type memoryReconciliationRepository struct {
+	recorded []*models.ReconciliationMismatch
+}
+
+func (r *memoryReconciliationRepository) Record(ctx context.Context, mismatch *models.ReconciliationMismatch) error {
+	mismatch.ID = uuid.New()
+	r.recorded = append(r.recorded, mismatch)
+	return nil
+}
+
+func TestReconciliationService_RecordsMismatches(t *testing.T) {
+	stub := tigerbeetle.NewServiceStub()
+	require.NoError(t, stub.InitializeMasterAccounts())
+
+	balancedAccount, driftedAccount := int64(5001), int64(5002)
+	for _, id := range []int64{balancedAccount, driftedAccount} {
+		_, err := stub.CreateUserAccount(uint64(id))
+		require.NoError(t, err)
+		require.NoError(t, stub.Deposit(uint64(id), 10000, uint64(20000+id)))
+	}
+
+	balanced := &models.User{ID: uuid.New(), IsActive: true, TigerBeetleAccountID: &balancedAccount}
+	drifted := &models.User{ID: uuid.New(), IsActive: true, TigerBeetleAccountID: &driftedAccount}
+	inactive := &models.User{ID: uuid.New(), IsActive: false, TigerBeetleAccountID: &driftedAccount}
+	noAccount := &models.User{ID: uuid.New(), IsActive: true}
+
+	userRepo := new(mocks.MockUserRepository)
+	userRepo.On("List", mock.Anything, (*uuid.UUID)(nil), 100).Return([]*models.User{balanced, drifted, inactive, noAccount}, nil)
+
+	txRepo := &expectedBalanceTransactionRepository{balances: map[uuid.UUID]int64{
+		balanced.ID: 10000,
+		drifted.ID:  7500,
+	}}
+	reportRepo := &memoryReconciliationRepository{}
+
+	service := db.NewReconciliationService(userRepo, txRepo, reportRepo, stub)
+	mismatches, err := service.Reconcile(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, mismatches, 1)
+	assert.Equal(t, drifted.ID, mismatches[0].UserID)
+	assert.Equal(t, int64(10000), mismatches[0].TigerBeetleBalance)
+	assert.Equal(t, int64(7500), mismatches[0].ExpectedBalance)
+	assert.Equal(t, int64(2500), mismatches[0].Difference)
+
+	require.Len(t, reportRepo.recorded, 1)
+	assert.NotEqual(t, uuid.Nil, reportRepo.recorded[0].RunID)
+}
+
+func TestReconciliationService_RecordedMovementsMatch(t *testing.T) {
+	stub := tigerbeetle.NewServiceStub()
+	require.NoError(t, stub.InitializeMasterAccounts())
+
+	senderAccount, recipientAccount := int64(6001), int64(6002)
+	for _, id := range []int64{senderAccount, recipientAccount} {
+		_, err := stub.CreateUserAccount(uint64(id))
+		require.NoError(t, err)
+	}
+	sender := &models.User{ID: uuid.New(), IsActive: true, TigerBeetleAccountID: &senderAccount, DailyTransferLimitCents: 1000000}
+	recipient := &models.User{ID: uuid.New(), IsActive: true, TigerBeetleAccountID: &recipientAccount}
+
+	userRepo := new(mocks.MockUserRepository)
+	userRepo.On("GetByID", mock.Anything, sender.ID).Return(sender, nil)
+	userRepo.On("GetByID", mock.Anything, recipient.ID).Return(recipient, nil)
+	userRepo.On("List", mock.Anything, (*uuid.UUID)(nil), 100).Return([]*models.User{sender, recipient}, nil)
+
+	// Depósito y transferencia con comisión hechos por el servicio, que los registra
+	txRepo := &ledgerTransactionRepository{}
+	userService := db.NewUserService(userRepo, stub,
+		db.WithTransactionRepository(txRepo),
+		db.WithTransferIDGenerator(db.NewMemoryTransferIDGenerator()),
+		db.WithFeeSchedule(fee.FeeSchedule{BaseFeeCents: 25, PercentFee: 1}))
+	require.NoError(t, userService.DepositToUser(context.Background(), sender.ID, 10000))
+	_, err := userService.TransferBetweenUsers(context.Background(), sender.ID, recipient.ID, 5000)
+	require.NoError(t, err)
+
+	reportRepo := &memoryReconciliationRepository{}
+	service := db.NewReconciliationService(userRepo, txRepo, reportRepo, stub)
+	mismatches, err := service.Reconcile(context.Background())
+
+	require.NoError(t, err)
+	assert.Empty(t, mismatches)
+	assert.Empty(t, reportRepo.recorded)
+}
+
+func TestReconciliationService_RequiresTigerBeetle(t *testing.T) {
+	service := db.NewReconciliationService(new(mocks.MockUserRepository), &expectedBalanceTransactionRepository{}, &memoryReconciliationRepository{}, nil)
+
+	_, err := service.Reconcile(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,34 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"banca-en-linea/backend/internal/auth"
+	"banca-en-linea/backend/internal/handlers"
+	"banca-en-linea/backend/internal/middleware"
+)
+
+func TestScheduledTransferHandler_Schedule_RequiresOTPForLargeTransfers(t *testing.T) {
+	otpService, notifier, userID := newTestOTPService()
+	handler := handlers.NewScheduledTransferHandler(nil, handlers.NewLargeTransferGuard(otpService, 100000))
+
+	body := `{"to_user_id":"` + uuid.NewString() + `","amount":250000,"scheduled_at":"2099-01-01T00:00:00Z"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users/"+userID.String()+"/scheduled-transfers", strings.NewReader(body))
+	req = mux.SetURLVars(req, map[string]string{"id": userID.String()})
+	ctx := context.WithValue(req.Context(), middleware.UserContextKey, &auth.Claims{UserID: userID})
+	rec := httptest.NewRecorder()
+	handler.Schedule(rec, req.WithContext(ctx))
+
+	// La transferencia no se programa hasta confirmar el código enviado
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"otp_required":true`)
+	assert.Len(t, notifier.code, 6)
+}
@@ -0,0 +1,165 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/mocks"
+	"banca-en-linea/backend/models"
+)
+
+// memoryScheduledTransferRepository guarda las transferencias programadas en memoria
+type memoryScheduledTransferRepository struct {
+	db.ScheduledTransferRepository
+	transfers []*models.ScheduledTransfer
+}
+
+func (r *memoryScheduledTransferRepository) Create(ctx context.Context, transfer *models.ScheduledTransfer) (*models.ScheduledTransfer, error) {
+	created := *transfer
+	created.ID = uuid.New()
+	created.Status = models.ScheduledTransferStatusPending
+	r.transfers = append(r.transfers, &created)
+	return &created, nil
+}
+
+func (r *memoryScheduledTransferRepository) ProcessDue(ctx context.Context, limit int, execute func(ctx context.Context, transfer *models.ScheduledTransfer) *models.ScheduledTransfer) (int, error) {
+	processed := 0
+	for _, transfer := range r.transfers {
+		if processed == limit {
+			break
+		}
+		if transfer.Status != models.ScheduledTransferStatusPending || transfer.ScheduledAt.After(time.Now()) {
+			continue
+		}
+
+		next := execute(ctx, transfer)
+		now := time.Now()
+		transfer.ExecutedAt = &now
+		if next != nil {
+			r.Create(ctx, next)
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+func TestScheduledTransferService_Schedule_RejectsPastDate(t *testing.T) {
+	service := db.NewScheduledTransferService(&memoryScheduledTransferRepository{}, nil)
+
+	_, err := service.Schedule(context.Background(), &models.ScheduleTransferRequest{
+		FromUserID:  uuid.New(),
+		ToUserID:    uuid.New(),
+		Amount:      1000,
+		ScheduledAt: time.Now().Add(-time.Minute),
+	})
+	assert.ErrorIs(t, err, db.ErrScheduledInPast)
+}
+
+func TestScheduledTransferService_Execute_RunsDueTransfers(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	userService := db.NewUserService(mockRepo, mockTB)
+	repo := &memoryScheduledTransferRepository{}
+	service := db.NewScheduledTransferService(repo, userService)
+
+	fromAccountID, toAccountID := int64(111), int64(222)
+	from := &models.User{ID: uuid.New(), TigerBeetleAccountID: &fromAccountID, DailyTransferLimitCents: 1000000}
+	to := &models.User{ID: uuid.New(), TigerBeetleAccountID: &toAccountID}
+	mockRepo.On("GetByID", mock.Anything, from.ID).Return(from, nil)
+	mockRepo.On("GetByID", mock.Anything, to.ID).Return(to, nil)
+	mockTB.On("GetAccountBalance", uint64(fromAccountID)).Return(uint64(0), uint64(10000), nil)
+	mockTB.On("Transfer", uint64(fromAccountID), uint64(toAccountID), uint64(4000), mock.AnythingOfType("uint64")).Return(nil).Once()
+
+	due, err := service.Schedule(context.Background(), &models.ScheduleTransferRequest{
+		FromUserID: from.ID, ToUserID: to.ID, Amount: 4000, ScheduledAt: time.Now().Add(time.Millisecond),
+	})
+	require.NoError(t, err)
+	future, err := service.Schedule(context.Background(), &models.ScheduleTransferRequest{
+		FromUserID: from.ID, ToUserID: to.ID, Amount: 4000, ScheduledAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	overdrawn, err := service.Schedule(context.Background(), &models.ScheduleTransferRequest{
+		FromUserID: from.ID, ToUserID: to.ID, Amount: 50000, ScheduledAt: time.Now().Add(time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	time.Sleep(5 * time.Millisecond)
+	require.NoError(t, service.Execute(context.Background()))
+
+	statuses := map[uuid.UUID]*models.ScheduledTransfer{}
+	for _, transfer := range repo.transfers {
+		statuses[transfer.ID] = transfer
+	}
+	assert.Equal(t, models.ScheduledTransferStatusExecuted, statuses[due.ID].Status)
+	assert.Equal(t, models.ScheduledTransferStatusPending, statuses[future.ID].Status)
+	assert.Equal(t, models.ScheduledTransferStatusFailed, statuses[overdrawn.ID].Status)
+	assert.Contains(t, statuses[overdrawn.ID].ErrorText, "insufficient funds")
+	mockTB.AssertExpectations(t)
+
+	// Una segunda ejecución no repite las ya procesadas
+	require.NoError(t, service.Execute(context.Background()))
+	mockTB.AssertNumberOfCalls(t, "Transfer", 1)
+}
+
+func TestScheduledTransferService_Execute_SchedulesNextOccurrence(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	userService := db.NewUserService(mockRepo, mockTB)
+	repo := &memoryScheduledTransferRepository{}
+	service := db.NewScheduledTransferService(repo, userService)
+
+	fromAccountID, toAccountID := int64(111), int64(222)
+	from := &models.User{ID: uuid.New(), TigerBeetleAccountID: &fromAccountID, DailyTransferLimitCents: 1000000}
+	to := &models.User{ID: uuid.New(), TigerBeetleAccountID: &toAccountID}
+	mockRepo.On("GetByID", mock.Anything, from.ID).Return(from, nil)
+	mockRepo.On("GetByID", mock.Anything, to.ID).Return(to, nil)
+	mockTB.On("GetAccountBalance", uint64(fromAccountID)).Return(uint64(0), uint64(100000), nil)
+	mockTB.On("Transfer", uint64(fromAccountID), uint64(toAccountID), uint64(1000), mock.AnythingOfType("uint64")).Return(nil)
+
+	// Dos transferencias ya vencidas: una semanal que sigue y una mensual cuya serie termina
+	firstRun := time.Now().Add(-time.Hour)
+	weekly := &models.ScheduledTransfer{
+		ID: uuid.New(), FromUserID: from.ID, ToUserID: to.ID, AmountCents: 1000,
+		ScheduledAt: firstRun, RecurrenceType: models.RecurrenceWeekly, Status: models.ScheduledTransferStatusPending,
+	}
+	endDate := firstRun.AddDate(0, 0, 20)
+	monthly := &models.ScheduledTransfer{
+		ID: uuid.New(), FromUserID: from.ID, ToUserID: to.ID, AmountCents: 1000,
+		ScheduledAt: firstRun, RecurrenceType: models.RecurrenceMonthly, RecurrenceEndDate: &endDate,
+		Status: models.ScheduledTransferStatusPending,
+	}
+	repo.transfers = []*models.ScheduledTransfer{weekly, monthly}
+
+	require.NoError(t, service.Execute(context.Background()))
+
+	assert.Equal(t, models.ScheduledTransferStatusExecuted, weekly.Status)
+	assert.Equal(t, models.ScheduledTransferStatusCompleted, monthly.Status)
+
+	require.Len(t, repo.transfers, 3)
+	next := repo.transfers[2]
+	assert.Equal(t, models.ScheduledTransferStatusPending, next.Status)
+	assert.Equal(t, models.RecurrenceWeekly, next.RecurrenceType)
+	assert.True(t, next.ScheduledAt.Equal(firstRun.AddDate(0, 0, 7)))
+	assert.NotEqual(t, weekly.ID, next.ID)
+}
+
+func TestScheduledTransferService_Schedule_RejectsInvalidRecurrence(t *testing.T) {
+	service := db.NewScheduledTransferService(&memoryScheduledTransferRepository{}, nil)
+	scheduledAt := time.Now().Add(time.Hour)
+	endDate := scheduledAt.Add(-time.Minute)
+
+	for _, req := range []*models.ScheduleTransferRequest{
+		{Amount: 1000, ScheduledAt: scheduledAt, RecurrenceType: "daily"},
+		{Amount: 1000, ScheduledAt: scheduledAt, RecurrenceType: models.RecurrenceWeekly, RecurrenceEndDate: &endDate},
+	} {
+		_, err := service.Schedule(context.Background(), req)
+		assert.ErrorIs(t, err, db.ErrInvalidRecurrence)
+	}
+}
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/mocks"
+	"banca-en-linea/backend/internal/tigerbeetle"
+)
+
+func newBreakerStub(t *testing.T, opts ...tigerbeetle.BreakerOption) (*tigerbeetle.Service, *tigerbeetle.CircuitBreakerService) {
+	stub := tigerbeetle.NewServiceStub()
+	require.NoError(t, stub.InitializeMasterAccounts())
+	return stub, tigerbeetle.NewCircuitBreakerService(stub, opts...)
+}
+
+func TestCircuitBreaker_OpensAfterFiveConsecutiveErrors(t *testing.T) {
+	_, breaker := newBreakerStub(t)
+
+	// Depositar a una cuenta inexistente falla en el stub
+	for i := 0; i < 4; i++ {
+		err := breaker.Deposit(404, 100, uint64(20000+i))
+		require.Error(t, err)
+		assert.Equal(t, "closed", breaker.State())
+	}
+
+	err := breaker.Deposit(404, 100, 20004)
+	require.Error(t, err)
+	assert.Equal(t, "open", breaker.State())
+
+	// Con el circuito abierto la llamada falla sin llegar al servicio
+	_, err = breaker.CreateUserAccount(12345)
+	assert.ErrorIs(t, err, tigerbeetle.ErrCircuitOpen)
+}
+
+func TestCircuitBreaker_ClosesAfterTimeout(t *testing.T) {
+	stub, breaker := newBreakerStub(t, tigerbeetle.WithBreakerTimeout(50*time.Millisecond))
+
+	_, err := stub.CreateUserAccount(12345)
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		require.Error(t, breaker.Deposit(404, 100, uint64(20000+i)))
+	}
+	require.Equal(t, "open", breaker.State())
+
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, "half-open", breaker.State())
+
+	// La llamada de prueba tiene éxito y el circuito se cierra
+	require.NoError(t, breaker.Deposit(12345, 100, 30000))
+	assert.Equal(t, "closed", breaker.State())
+}
+
+func TestCircuitBreaker_BusinessErrorsDoNotOpen(t *testing.T) {
+	stub, breaker := newBreakerStub(t)
+
+	_, err := stub.CreateUserAccount(12345)
+	require.NoError(t, err)
+
+	// Retiros sin fondos: TigerBeetle respondió, el circuito sigue cerrado
+	for i := 0; i < 10; i++ {
+		err := breaker.Withdraw(12345, 100, uint64(20000+i))
+		require.ErrorIs(t, err, tigerbeetle.ErrExceedsCredits)
+	}
+
+	assert.Equal(t, "closed", breaker.State())
+}
+
+func TestUserService_TigerBeetleStatus(t *testing.T) {
+	disabled := db.NewUserService(new(mocks.MockUserRepository), nil)
+	status, circuit := disabled.TigerBeetleStatus()
+	assert.Equal(t, "disabled", status)
+	assert.Equal(t, "none", circuit)
+
+	_, breaker := newBreakerStub(t)
+	service := db.NewUserService(new(mocks.MockUserRepository), breaker)
+
+	status, circuit = service.TigerBeetleStatus()
+	assert.Equal(t, "connected", status)
+	assert.Equal(t, "closed", circuit)
+
+	for i := 0; i < 5; i++ {
+		require.Error(t, breaker.Deposit(404, 100, uint64(20000+i)))
+	}
+
+	status, circuit = service.TigerBeetleStatus()
+	assert.Equal(t, "disconnected", status)
+	assert.Equal(t, "open", circuit)
+}
+
+func TestUserService_TigerBeetleStatus_PingFailure(t *testing.T) {
+	// Sin cuentas maestras el stub responde sin la cuenta de débito
+	breaker := tigerbeetle.NewCircuitBreakerService(tigerbeetle.NewServiceStub())
+	service := db.NewUserService(new(mocks.MockUserRepository), breaker)
+
+	status, circuit := service.TigerBeetleStatus()
+	assert.Equal(t, "degraded", status)
+	assert.Equal(t, "closed", circuit)
+
+	mockTB := new(MockTigerBeetleService)
+	mockTB.On("Ping").Return(errors.New("connection refused"))
+	status, _ = db.NewUserService(new(mocks.MockUserRepository), mockTB).TigerBeetleStatus()
+	assert.Equal(t, "degraded", status)
+}
+
+func TestUserService_TigerBeetleStatus_NotConnected(t *testing.T) {
+	// Mientras main reintenta la conexión el servicio responde ErrNotConnected
+	mockTB := new(MockTigerBeetleService)
+	mockTB.On("Ping").Return(tigerbeetle.ErrNotConnected)
+	service := db.NewUserService(new(mocks.MockUserRepository), tigerbeetle.NewCircuitBreakerService(mockTB))
+
+	for i := 0; i < 4; i++ {
+		status, _ := service.TigerBeetleStatus()
+		require.Equal(t, "degraded", status)
+	}
+
+	// El quinto fallo consecutivo abre el circuito
+	status, circuit := service.TigerBeetleStatus()
+	assert.Equal(t, "disconnected", status)
+	assert.Equal(t, "open", circuit)
+}
+
+func TestServicePing(t *testing.T) {
+	stub := tigerbeetle.NewServiceStub()
+	assert.ErrorIs(t, stub.Ping(), tigerbeetle.ErrPingFailed)
+
+	require.NoError(t, stub.InitializeMasterAccounts())
+	assert.NoError(t, stub.Ping())
+}
@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/tigerbeetle"
+)
+
+func TestCallWithRetry_RetriesTransientErrors(t *testing.T) {
+	calls := 0
+	start := time.Now()
+
+	err := tigerbeetle.CallWithRetry(func() error {
+		calls++
+		if calls < 3 {
+			return fmt.Errorf("error creating transfer: %w", io.EOF)
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	// 100ms + 200ms de backoff como mínimo
+	assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+}
+
+func TestCallWithRetry_GivesUpAfterThreeRetries(t *testing.T) {
+	calls := 0
+	netErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+	err := tigerbeetle.CallWithRetry(func() error {
+		calls++
+		return netErr
+	})
+
+	assert.ErrorIs(t, err, netErr)
+	assert.Equal(t, 4, calls)
+}
+
+func TestCallWithRetry_DoesNotRetryBusinessErrors(t *testing.T) {
+	calls := 0
+
+	err := tigerbeetle.CallWithRetry(func() error {
+		calls++
+		return tigerbeetle.ErrExceedsCredits
+	})
+
+	assert.ErrorIs(t, err, tigerbeetle.ErrExceedsCredits)
+	assert.Equal(t, 1, calls)
+}
+
+func TestIsTransientError(t *testing.T) {
+	assert.True(t, tigerbeetle.IsTransientError(io.EOF))
+	assert.True(t, tigerbeetle.IsTransientError(&net.OpError{Op: "read", Err: errors.New("reset")}))
+	assert.False(t, tigerbeetle.IsTransientError(nil))
+	assert.False(t, tigerbeetle.IsTransientError(tigerbeetle.ErrTransferExists))
+	assert.False(t, tigerbeetle.IsTransientError(errors.New("transfer failed: AccountExistsError")))
+}
+
+func TestReconnect_BacksOffUntilConnected(t *testing.T) {
+	backoff := tigerbeetle.ReconnectBackoff{InitialDelay: 20 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+	start := time.Now()
+	var attempts []time.Duration
+
+	err := tigerbeetle.Reconnect(context.Background(), backoff, func(attempt int) error {
+		attempts = append(attempts, time.Since(start))
+		if attempt < 4 {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.Len(t, attempts, 4)
+	// Esperas de 20ms, 40ms y luego el máximo de 50ms en lugar de 80ms
+	minimum := []time.Duration{20, 60, 110, 160}
+	for i, elapsed := range attempts {
+		assert.GreaterOrEqual(t, elapsed, minimum[i]*time.Millisecond, "attempt %d", i+1)
+	}
+}
+
+func TestReconnect_StopsWhenCanceled(t *testing.T) {
+	backoff := tigerbeetle.ReconnectBackoff{InitialDelay: 10 * time.Millisecond, MaxDelay: time.Hour}
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+
+	done := make(chan error, 1)
+	go func() {
+		done <- tigerbeetle.Reconnect(ctx, backoff, func(attempt int) error {
+			calls++
+			// Cancelar durante la espera siguiente evita un segundo intento
+			cancel()
+			return errors.New("connection refused")
+		})
+	}()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, calls)
+	case <-time.After(time.Second):
+		t.Fatal("Reconnect did not stop after cancellation")
+	}
+}
@@ -1,6 +1,9 @@
 package tests
 
 import (
+	"context"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -17,15 +20,15 @@ func TestTigerBeetleService_InitializeMasterAccounts(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verificar que las cuentas maestras fueron creadas
-	debitAccount, err := service.GetAccount(tigerbeetle.MasterDebitAccount)
+	debitAccount, err := service.GetAccount(uint64(tigerbeetle.MasterDebitAccount))
 	assert.NoError(t, err)
 	assert.NotNil(t, debitAccount)
-	assert.Equal(t, tigerbeetle.MasterDebitAccount, debitAccount.ID)
+	assert.Equal(t, uint64(tigerbeetle.MasterDebitAccount), debitAccount.GetID())
 
-	creditAccount, err := service.GetAccount(tigerbeetle.MasterCreditAccount)
+	creditAccount, err := service.GetAccount(uint64(tigerbeetle.MasterCreditAccount))
 	assert.NoError(t, err)
 	assert.NotNil(t, creditAccount)
-	assert.Equal(t, tigerbeetle.MasterCreditAccount, creditAccount.ID)
+	assert.Equal(t, uint64(tigerbeetle.MasterCreditAccount), creditAccount.GetID())
 }
 
 func TestTigerBeetleService_CreateUserAccount(t *testing.T) {
@@ -41,12 +44,12 @@ func TestTigerBeetleService_CreateUserAccount(t *testing.T) {
 	account, err := service.CreateUserAccount(userID)
 	assert.NoError(t, err)
 	assert.NotNil(t, account)
-	assert.Equal(t, userID, account.ID)
+	assert.Equal(t, userID, account.GetID())
 
 	// Verificar que la cuenta se puede obtener después
 	retrievedAccount, err := service.GetAccount(userID)
 	assert.NoError(t, err)
-	assert.Equal(t, userID, retrievedAccount.ID)
+	assert.Equal(t, userID, retrievedAccount.GetID())
 }
 
 func TestTigerBeetleService_CreateUserAccount_Duplicate(t *testing.T) {
@@ -181,6 +184,44 @@ func TestTigerBeetleService_Withdraw_InsufficientFunds(t *testing.T) {
 	assert.Contains(t, err.Error(), "insufficient funds")
 }
 
+func TestTigerBeetleService_Withdraw_ConcurrentNeverOverdraws(t *testing.T) {
+	service := tigerbeetle.NewServiceStub()
+	defer service.Close()
+
+	require.NoError(t, service.InitializeMasterAccounts())
+
+	userID := uint64(12345)
+	withdrawAmount := uint64(100)
+	withdrawals := 100
+
+	_, err := service.CreateUserAccount(userID)
+	require.NoError(t, err)
+
+	// Balance suficiente solo para la mitad de los retiros
+	require.NoError(t, service.Deposit(userID, withdrawAmount*uint64(withdrawals/2), 1))
+
+	var wg sync.WaitGroup
+	var succeeded atomic.Int64
+	for i := 0; i < withdrawals; i++ {
+		wg.Add(1)
+		go func(transferID uint64) {
+			defer wg.Done()
+			err := service.Withdraw(userID, withdrawAmount, transferID)
+			if err == nil {
+				succeeded.Add(1)
+				return
+			}
+			assert.ErrorIs(t, err, tigerbeetle.ErrExceedsCredits)
+		}(uint64(i + 2))
+	}
+	wg.Wait()
+
+	debits, credits, err := service.GetAccountBalance(userID)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, debits, credits, "balance must never go negative")
+	assert.Equal(t, int64(withdrawals/2), succeeded.Load())
+}
+
 func TestTigerBeetleService_Transfer(t *testing.T) {
 	service := tigerbeetle.NewServiceStub()
 	defer service.Close()
@@ -245,6 +286,51 @@ func TestTigerBeetleService_Transfer_InsufficientFunds(t *testing.T) {
 	assert.Contains(t, err.Error(), "insufficient funds")
 }
 
+func TestTigerBeetleService_LinkedTransfer(t *testing.T) {
+	service := tigerbeetle.NewServiceStub()
+	defer service.Close()
+
+	// Inicializar cuentas maestras primero
+	err := service.InitializeMasterAccounts()
+	require.NoError(t, err)
+
+	fromUserID := uint64(12345)
+	toUserID := uint64(67890)
+
+	_, err = service.CreateUserAccount(fromUserID)
+	require.NoError(t, err)
+	_, err = service.CreateUserAccount(toUserID)
+	require.NoError(t, err)
+	require.NoError(t, service.Deposit(fromUserID, 10000, 1))
+
+	withFee := func(amount, transferID uint64) []tigerbeetle.LinkedTransferRequest {
+		return []tigerbeetle.LinkedTransferRequest{
+			{FromAccountID: fromUserID, ToAccountID: toUserID, Amount: amount, TransferID: transferID},
+			{FromAccountID: fromUserID, ToAccountID: uint64(tigerbeetle.FeeAccount), Amount: 50, TransferID: transferID + 1, Code: tigerbeetle.TransferCodeFee},
+		}
+	}
+
+	// Monto más comisión exceden el balance: no se aplica ninguna de las dos
+	err = service.LinkedTransfer(withFee(10000, 2))
+	assert.ErrorIs(t, err, tigerbeetle.ErrExceedsCredits)
+
+	_, toCredits, err := service.GetAccountBalance(toUserID)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), toCredits)
+
+	// Los IDs de la cadena revertida pueden reutilizarse
+	err = service.LinkedTransfer(withFee(9000, 2))
+	require.NoError(t, err)
+
+	debits, credits, err := service.GetAccountBalance(fromUserID)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(950), credits-debits)
+
+	_, feeCredits, err := service.GetAccountBalance(uint64(tigerbeetle.FeeAccount))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(50), feeCredits)
+}
+
 func TestTigerBeetleService_Transfer_AccountNotFound(t *testing.T) {
 	service := tigerbeetle.NewServiceStub()
 	defer service.Close()
@@ -296,3 +382,69 @@ func TestTigerBeetleService_DuplicateTransferID(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "transfer ID already exists")
 }
+
+func TestBatchLookupAccountsBalance_MultipleChunks(t *testing.T) {
+	service := tigerbeetle.NewServiceStub()
+	defer service.Close()
+
+	err := service.InitializeMasterAccounts()
+	require.NoError(t, err)
+
+	// 250 cuentas se consultan en 3 bloques
+	var ids []uint64
+	for i := uint64(0); i < 250; i++ {
+		id := 10000 + i
+		_, err := service.CreateUserAccount(id)
+		require.NoError(t, err)
+		require.NoError(t, service.Deposit(id, i+1, 100000+i))
+		ids = append(ids, id)
+	}
+
+	// Las cuentas inexistentes se omiten
+	ids = append(ids, 99999)
+
+	balances, err := tigerbeetle.BatchLookupAccountsBalance(context.Background(), service, ids)
+	require.NoError(t, err)
+	assert.Len(t, balances, 250)
+	assert.Equal(t, int64(1), balances[10000].Balance())
+	assert.Equal(t, int64(250), balances[10249].Balance())
+}
+
+func TestTigerBeetleService_GetMultipleAccountBalances(t *testing.T) {
+	service := tigerbeetle.NewServiceStub()
+	defer service.Close()
+
+	require.NoError(t, service.InitializeMasterAccounts())
+
+	for i, id := range []uint64{10001, 10002} {
+		_, err := service.CreateUserAccount(id)
+		require.NoError(t, err)
+		require.NoError(t, service.Deposit(id, uint64(i+1)*1000, id))
+	}
+	require.NoError(t, service.Withdraw(10002, 500, 1))
+
+	// Las cuentas inexistentes se omiten
+	balances, err := service.GetMultipleAccountBalances([]uint64{10001, 10002, 99999})
+	require.NoError(t, err)
+	assert.Equal(t, map[uint64]int64{10001: 1000, 10002: 1500}, balances)
+}
+
+func TestTigerBeetleService_BatchCreateUserAccounts(t *testing.T) {
+	service := tigerbeetle.NewServiceStub()
+	defer service.Close()
+
+	_, err := service.CreateUserAccount(20002)
+	require.NoError(t, err)
+
+	// La cuenta existente se reporta como fallida y las demás se crean
+	created, failed, err := service.BatchCreateUserAccounts([]uint64{20001, 20002, 20003})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{20002}, failed)
+	require.Len(t, created, 2)
+	assert.Equal(t, uint64(20001), created[0].GetID())
+	assert.Equal(t, uint64(20003), created[1].GetID())
+
+	account, err := service.GetAccount(20003)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tigerbeetle.UserAccount), account.GetCode())
+}
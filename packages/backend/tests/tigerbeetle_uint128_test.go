@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+
+	"banca-en-linea/backend/internal/tigerbeetle"
+)
+
+func TestNetBalance_AboveMaxInt64(t *testing.T) {
+	credits := uint64(math.MaxInt64) + 1
+
+	balance, err := tigerbeetle.NetBalance(types.ToUint128(credits), types.ToUint128(0))
+
+	require.NoError(t, err)
+	assert.Equal(t, credits, balance)
+
+	// La conversión anterior (int64(credits) - int64(debits)) desbordaba a un balance negativo
+	assert.Negative(t, int64(credits)-int64(0))
+}
+
+func TestNetBalance_DebitsAboveCredits(t *testing.T) {
+	balance, err := tigerbeetle.NetBalance(types.ToUint128(100), types.ToUint128(250))
+
+	require.NoError(t, err)
+	assert.Zero(t, balance)
+}
+
+func TestUint128ToUint64(t *testing.T) {
+	value, err := tigerbeetle.Uint128ToUint64(types.ToUint128(math.MaxUint64))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(math.MaxUint64), value)
+
+	// 2^64 no cabe en 64 bits
+	tooBig := new(big.Int).Lsh(big.NewInt(1), 64)
+	_, err = tigerbeetle.Uint128ToUint64(types.BigIntToUint128(*tooBig))
+	assert.ErrorIs(t, err, tigerbeetle.ErrAmountOverflow)
+
+	_, err = tigerbeetle.NetBalance(types.BigIntToUint128(*tooBig), types.ToUint128(0))
+	assert.ErrorIs(t, err, tigerbeetle.ErrAmountOverflow)
+}
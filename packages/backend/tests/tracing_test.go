@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/mocks"
+	"banca-en-linea/backend/internal/tracing"
+	"banca-en-linea/backend/models"
+)
+
+// recordSpans instala un TracerProvider global que guarda los spans en memoria durante el test
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestInit_WithoutCollectorEndpoint(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	provider, err := tracing.Init("banca-test", "")
+	require.NoError(t, err)
+	defer provider.Shutdown(context.Background())
+
+	assert.Same(t, provider, otel.GetTracerProvider())
+}
+
+func TestUserService_TransferBetweenUsers_CreatesSpans(t *testing.T) {
+	recorder := recordSpans(t)
+
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	service := db.NewUserService(db.NewTracedUserRepository(mockRepo), mockTB)
+
+	fromID, toID := uuid.New(), uuid.New()
+	fromAccount, toAccount := int64(111), int64(222)
+	mockRepo.On("GetByID", mock.Anything, fromID).Return(&models.User{ID: fromID, TigerBeetleAccountID: &fromAccount}, nil)
+	mockRepo.On("GetByID", mock.Anything, toID).Return(&models.User{ID: toID, TigerBeetleAccountID: &toAccount}, nil)
+	mockTB.On("GetAccountBalance", uint64(fromAccount)).Return(uint64(0), uint64(10000), nil)
+	mockTB.On("Transfer", uint64(fromAccount), uint64(toAccount), uint64(500), mock.AnythingOfType("uint64")).Return(nil)
+
+	_, err := service.TransferBetweenUsers(context.Background(), fromID, toID, 500)
+	require.NoError(t, err)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+
+	root, ok := spans["UserService.TransferBetweenUsers"]
+	require.True(t, ok)
+	for _, name := range []string{"UserRepository.GetByID", "TigerBeetleService.Transfer"} {
+		child, ok := spans[name]
+		require.True(t, ok, name)
+		assert.Equal(t, root.SpanContext().TraceID(), child.SpanContext().TraceID())
+		assert.Equal(t, root.SpanContext().SpanID(), child.Parent().SpanID())
+	}
+}
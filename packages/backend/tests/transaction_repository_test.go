@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/models"
+)
+
+// createPrimaryMovements registra un depósito y un retiro de la cuenta principal del usuario, que
+// no está en bank_accounts y se guarda con las cuentas nulas
+func createPrimaryMovements(t *testing.T, testDB *sql.DB) (db.TransactionRepository, *models.User) {
+	t.Helper()
+
+	user, err := db.NewUserRepository(testDB).Create(context.Background(), &models.CreateUserRequest{
+		Email:     "primary@example.com",
+		Password:  "password123",
+		FirstName: "Ana",
+		LastName:  "Pérez",
+	})
+	require.NoError(t, err)
+
+	repo := db.NewTransactionRepository(testDB)
+	_, err = repo.Create(context.Background(), &models.Transaction{
+		TigerBeetleTransferID: 9001,
+		Amount:                20000,
+		Currency:              models.DefaultCurrency,
+		TransactionType:       models.TransactionTypeDeposit,
+		Status:                models.TransactionStatusCompleted,
+		RecipientUserID:       &user.ID,
+	})
+	require.NoError(t, err)
+	_, err = repo.Create(context.Background(), &models.Transaction{
+		TigerBeetleTransferID: 9002,
+		Amount:                5000,
+		Currency:              models.DefaultCurrency,
+		TransactionType:       models.TransactionTypeWithdrawal,
+		Status:                models.TransactionStatusCompleted,
+		CreatedBy:             &user.ID,
+	})
+	require.NoError(t, err)
+
+	return repo, user
+}
+
+func TestTransactionRepository_HistoryIncludesPrimaryAccount(t *testing.T) {
+	testDB := setupTestDB(t)
+	defer testDB.Close()
+
+	repo, user := createPrimaryMovements(t, testDB)
+
+	history, err := repo.GetByUserID(context.Background(), user.ID, nil, 10)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Nil(t, history[0].FromAccountID)
+	assert.Nil(t, history[0].ToAccountID)
+
+	withdrawals := models.TransactionTypeWithdrawal
+	filtered, err := repo.Filter(context.Background(), user.ID, models.TransactionFilter{Type: &withdrawals, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, int64(5000), filtered[0].Amount)
+
+	// Otro usuario no ve los movimientos
+	others, err := repo.GetByUserID(context.Background(), uuid.New(), nil, 10)
+	require.NoError(t, err)
+	assert.Empty(t, others)
+}
+
+func TestTransactionRepository_StatsIncludePrimaryAccount(t *testing.T) {
+	testDB := setupTestDB(t)
+	defer testDB.Close()
+
+	repo, user := createPrimaryMovements(t, testDB)
+
+	count, volume, err := repo.GetSummarySince(context.Background(), user.ID, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, int64(25000), volume)
+
+	last, err := repo.GetLastTransactionDate(context.Background(), user.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, last)
+}
+
+func TestTransactionRepository_ListOutgoingByUser_IncludesPrimaryAccount(t *testing.T) {
+	testDB := setupTestDB(t)
+	defer testDB.Close()
+
+	repo, user := createPrimaryMovements(t, testDB)
+
+	outgoing, err := repo.ListOutgoingByUser(context.Background(), user.ID, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, outgoing, 1)
+	assert.Equal(t, models.TransactionTypeWithdrawal, outgoing[0].TransactionType)
+}
@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/mocks"
+	"banca-en-linea/backend/models"
+)
+
+// memoryTransactionRepository guarda las transacciones en memoria
+type memoryTransactionRepository struct {
+	db.TransactionRepository
+	transactions []*models.Transaction
+}
+
+func (r *memoryTransactionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Transaction, error) {
+	for _, tx := range r.transactions {
+		if tx.ID == id {
+			return tx, nil
+		}
+	}
+	return nil, db.ErrTransactionNotFound
+}
+
+func (r *memoryTransactionRepository) CreateReversal(ctx context.Context, tx *models.Transaction) (*models.Transaction, error) {
+	for _, existing := range r.transactions {
+		if existing.TransactionType == models.TransactionTypeReversal && existing.Status != models.TransactionStatusFailed &&
+			*existing.RelatedTransactionID == *tx.RelatedTransactionID {
+			return nil, db.ErrTransactionAlreadyReversed
+		}
+	}
+	created := *tx
+	created.ID = uuid.New()
+	created.TransactionType = models.TransactionTypeReversal
+	r.transactions = append(r.transactions, &created)
+	return &created, nil
+}
+
+func (r *memoryTransactionRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status string) error {
+	tx, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	tx.Status = status
+	return nil
+}
+
+// newReversalFixture crea el servicio con una transferencia completada entre las cuentas
+// principales de dos usuarios, con una disputa del remitente ya aprobada por cumplimiento
+func newReversalFixture(t *testing.T) (*db.TransactionService, *memoryTransactionRepository, *memoryDisputeRepository, *MockTigerBeetleService, *models.Transaction) {
+	t.Helper()
+
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	txRepo := &memoryTransactionRepository{}
+	disputeRepo := &memoryDisputeRepository{}
+	service := db.NewTransactionService(txRepo, disputeRepo, db.NewUserService(mockRepo, mockTB), time.Hour)
+
+	senderAccountID, recipientAccountID := int64(111), int64(222)
+	sender := &models.User{ID: uuid.New(), TigerBeetleAccountID: &senderAccountID}
+	recipient := &models.User{ID: uuid.New(), TigerBeetleAccountID: &recipientAccountID}
+	mockRepo.On("GetByID", mock.Anything, sender.ID).Return(sender, nil)
+	mockRepo.On("GetByID", mock.Anything, recipient.ID).Return(recipient, nil)
+
+	original := &models.Transaction{
+		ID:                    uuid.New(),
+		TigerBeetleTransferID: 5000,
+		Amount:                2500,
+		Currency:              models.DefaultCurrency,
+		TransactionType:       models.TransactionTypeTransfer,
+		Status:                models.TransactionStatusCompleted,
+		CreatedBy:             &sender.ID,
+		RecipientUserID:       &recipient.ID,
+		CreatedAt:             time.Now().Add(-10 * time.Minute),
+	}
+	txRepo.transactions = append(txRepo.transactions, original)
+	disputeRepo.disputes = append(disputeRepo.disputes, &models.Dispute{
+		ID:            uuid.New(),
+		UserID:        sender.ID,
+		TransactionID: original.ID,
+		Status:        models.DisputeStatusApproved,
+	})
+
+	return service, txRepo, disputeRepo, mockTB, original
+}
+
+func TestTransactionService_Reverse_TransfersBackToSender(t *testing.T) {
+	service, _, _, mockTB, original := newReversalFixture(t)
+	mockTB.On("GetAccountBalance", uint64(222)).Return(uint64(0), uint64(10000), nil)
+	mockTB.On("Transfer", uint64(222), uint64(111), uint64(2500), mock.AnythingOfType("uint64")).Return(nil).Once()
+
+	reversal, err := service.Reverse(context.Background(), original.ID, *original.CreatedBy)
+	require.NoError(t, err)
+	assert.Equal(t, models.TransactionTypeReversal, reversal.TransactionType)
+	assert.Equal(t, models.TransactionStatusCompleted, reversal.Status)
+	assert.Equal(t, original.ID, *reversal.RelatedTransactionID)
+	assert.Equal(t, *original.RecipientUserID, *reversal.CreatedBy)
+	assert.Equal(t, *original.CreatedBy, *reversal.RecipientUserID)
+	assert.NotEqual(t, original.TigerBeetleTransferID, reversal.TigerBeetleTransferID)
+
+	// Una transferencia no se revierte dos veces
+	_, err = service.Reverse(context.Background(), original.ID, *original.CreatedBy)
+	assert.ErrorIs(t, err, db.ErrTransactionAlreadyReversed)
+	mockTB.AssertExpectations(t)
+}
+
+func TestTransactionService_Reverse_OnlySenderCanReverse(t *testing.T) {
+	service, _, _, mockTB, original := newReversalFixture(t)
+
+	_, err := service.Reverse(context.Background(), original.ID, *original.RecipientUserID)
+	assert.ErrorIs(t, err, db.ErrTransactionNotFound)
+	mockTB.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransactionService_Reverse_RejectsNonTransfers(t *testing.T) {
+	service, _, _, mockTB, original := newReversalFixture(t)
+	original.TransactionType = models.TransactionTypeDeposit
+
+	_, err := service.Reverse(context.Background(), original.ID, *original.CreatedBy)
+	assert.ErrorIs(t, err, db.ErrTransactionNotReversible)
+	mockTB.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransactionService_Reverse_RequiresRecipientFunds(t *testing.T) {
+	service, txRepo, _, mockTB, original := newReversalFixture(t)
+	mockTB.On("GetAccountBalance", uint64(222)).Return(uint64(0), uint64(1000), nil)
+
+	_, err := service.Reverse(context.Background(), original.ID, *original.CreatedBy)
+	var fundsErr *db.InsufficientFundsError
+	assert.ErrorAs(t, err, &fundsErr)
+	assert.Len(t, txRepo.transactions, 1)
+	mockTB.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransactionService_Reverse_RequiresApprovedDispute(t *testing.T) {
+	service, _, disputeRepo, mockTB, original := newReversalFixture(t)
+
+	// Una disputa abierta o rechazada no autoriza la reversión
+	for _, status := range []string{models.DisputeStatusOpen, models.DisputeStatusRejected} {
+		disputeRepo.disputes[0].Status = status
+		_, err := service.Reverse(context.Background(), original.ID, *original.CreatedBy)
+		assert.ErrorIs(t, err, db.ErrReversalNotApproved)
+	}
+
+	// Tampoco la aprobada de otro usuario
+	disputeRepo.disputes[0].Status = models.DisputeStatusApproved
+	disputeRepo.disputes[0].UserID = *original.RecipientUserID
+	_, err := service.Reverse(context.Background(), original.ID, *original.CreatedBy)
+	assert.ErrorIs(t, err, db.ErrReversalNotApproved)
+	mockTB.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransactionService_Reverse_RejectsAfterWindow(t *testing.T) {
+	service, txRepo, _, mockTB, original := newReversalFixture(t)
+	original.CreatedAt = time.Now().Add(-2 * time.Hour)
+
+	_, err := service.Reverse(context.Background(), original.ID, *original.CreatedBy)
+	assert.ErrorIs(t, err, db.ErrReversalWindowExpired)
+	assert.Len(t, txRepo.transactions, 1)
+	mockTB.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
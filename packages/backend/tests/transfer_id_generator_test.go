@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/db"
+)
+
+func TestMemoryTransferIDGenerator_UniqueAndAboveReservedRange(t *testing.T) {
+	generator := db.NewMemoryTransferIDGenerator()
+
+	const workers = 10
+	const perWorker = 100
+
+	var mu sync.Mutex
+	seen := make(map[uint64]bool)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perWorker; j++ {
+				id, err := generator.Next(context.Background())
+				require.NoError(t, err)
+
+				mu.Lock()
+				seen[id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, seen, workers*perWorker)
+	for id := range seen {
+		assert.Greater(t, id, uint64(10000))
+	}
+}
+
+func TestRandomTransferIDGenerator_UniqueAndOutsideReservedRanges(t *testing.T) {
+	generator := db.NewRandomTransferIDGenerator()
+
+	seen := make(map[uint64]bool)
+	for i := 0; i < 10000; i++ {
+		id, err := generator.Next(context.Background())
+		require.NoError(t, err)
+
+		assert.Greater(t, id, uint64(10000))
+		// El bit alto está reservado para los IDs derivados de llaves de idempotencia
+		assert.Zero(t, id>>63)
+		assert.False(t, seen[id], "duplicate transfer ID %d", id)
+		seen[id] = true
+	}
+}
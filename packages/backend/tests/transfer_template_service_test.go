@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/mocks"
+	"banca-en-linea/backend/models"
+)
+
+// memoryTransferTemplateRepository guarda las plantillas de transferencia en memoria
+type memoryTransferTemplateRepository struct {
+	db.TransferTemplateRepository
+	templates []*models.TransferTemplate
+}
+
+func (r *memoryTransferTemplateRepository) Create(ctx context.Context, template *models.TransferTemplate) (*models.TransferTemplate, error) {
+	for _, existing := range r.templates {
+		if existing.OwnerUserID == template.OwnerUserID && existing.Name == template.Name {
+			return nil, db.ErrTransferTemplateExists
+		}
+	}
+	created := *template
+	created.ID = uuid.New()
+	r.templates = append(r.templates, &created)
+	return &created, nil
+}
+
+func (r *memoryTransferTemplateRepository) GetByID(ctx context.Context, id, ownerID uuid.UUID) (*models.TransferTemplate, error) {
+	for _, template := range r.templates {
+		if template.ID == id && template.OwnerUserID == ownerID {
+			return template, nil
+		}
+	}
+	return nil, db.ErrTransferTemplateNotFound
+}
+
+func TestTransferTemplateService_ExecutesOwnTemplate(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	service := db.NewTransferTemplateService(&memoryTransferTemplateRepository{}, mockRepo, db.NewUserService(mockRepo, mockTB))
+
+	ownerAccountID, recipientAccountID := int64(111), int64(222)
+	owner := &models.User{ID: uuid.New(), IsActive: true, TigerBeetleAccountID: &ownerAccountID, DailyTransferLimitCents: 1000000}
+	recipient := &models.User{ID: uuid.New(), IsActive: true, TigerBeetleAccountID: &recipientAccountID}
+	mockRepo.On("GetByID", mock.Anything, owner.ID).Return(owner, nil)
+	mockRepo.On("GetByID", mock.Anything, recipient.ID).Return(recipient, nil)
+	mockTB.On("GetAccountBalance", uint64(ownerAccountID)).Return(uint64(0), uint64(10000), nil)
+	mockTB.On("Transfer", uint64(ownerAccountID), uint64(recipientAccountID), uint64(3000), mock.AnythingOfType("uint64")).Return(nil).Once()
+
+	created, err := service.Create(context.Background(), owner.ID, &models.CreateTransferTemplateRequest{
+		Name: " Alquiler ", RecipientUserID: recipient.ID, Amount: 3000,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Alquiler", created.Name)
+
+	_, err = service.Create(context.Background(), owner.ID, &models.CreateTransferTemplateRequest{
+		Name: "Alquiler", RecipientUserID: recipient.ID, Amount: 5000,
+	})
+	assert.ErrorIs(t, err, db.ErrTransferTemplateExists)
+
+	// Otro usuario no puede usar la plantilla
+	_, err = service.Get(context.Background(), recipient.ID, created.ID)
+	assert.ErrorIs(t, err, db.ErrTransferTemplateNotFound)
+
+	template, err := service.Get(context.Background(), owner.ID, created.ID)
+	require.NoError(t, err)
+	_, err = service.Execute(context.Background(), template)
+	require.NoError(t, err)
+	mockTB.AssertExpectations(t)
+}
+
+func TestTransferTemplateService_Create_Validates(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	service := db.NewTransferTemplateService(&memoryTransferTemplateRepository{}, mockRepo, nil)
+
+	ownerID := uuid.New()
+	inactive := &models.User{ID: uuid.New(), IsActive: false}
+	mockRepo.On("GetByID", mock.Anything, inactive.ID).Return(inactive, nil)
+
+	_, err := service.Create(context.Background(), ownerID, &models.CreateTransferTemplateRequest{
+		Name: "Yo", RecipientUserID: ownerID, Amount: 1000,
+	})
+	assert.ErrorIs(t, err, db.ErrSelfTransferTemplate)
+
+	for _, req := range []*models.CreateTransferTemplateRequest{
+		{Name: "  ", RecipientUserID: inactive.ID, Amount: 1000},
+		{Name: "Sin monto", RecipientUserID: inactive.ID},
+	} {
+		_, err = service.Create(context.Background(), ownerID, req)
+		assert.ErrorIs(t, err, db.ErrInvalidTransferTemplate)
+	}
+
+	_, err = service.Create(context.Background(), ownerID, &models.CreateTransferTemplateRequest{
+		Name: "Inactivo", RecipientUserID: inactive.ID, Amount: 1000,
+	})
+	assert.ErrorIs(t, err, db.ErrRecipientInactive)
+}
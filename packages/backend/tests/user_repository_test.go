@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"testing"
@@ -32,7 +33,9 @@ func setupTestDB(t *testing.T) *sql.DB {
 		t.Skip("PostgreSQL test database not available")
 	}
 
-	// Limpiar la tabla antes de cada prueba
+	// Limpiar las tablas antes de cada prueba; las transacciones referencian a los usuarios
+	_, err = db.Exec("DELETE FROM transactions")
+	require.NoError(t, err)
 	_, err = db.Exec("DELETE FROM users")
 	require.NoError(t, err)
 
@@ -40,10 +43,11 @@ func setupTestDB(t *testing.T) *sql.DB {
 }
 
 func TestUserRepository_Create(t *testing.T) {
-	db := setupTestDB(t)
-	defer db.Close()
+	testDB := setupTestDB(t)
+	defer testDB.Close()
 
-	repo := db.NewUserRepository(db)
+	repo := db.NewUserRepository(testDB)
+	ctx := context.Background()
 
 	req := &models.CreateUserRequest{
 		Email:     "test@example.com",
@@ -52,7 +56,7 @@ func TestUserRepository_Create(t *testing.T) {
 		LastName:  "User",
 	}
 
-	user, err := repo.Create(req)
+	user, err := repo.Create(ctx, req)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, user)
@@ -67,10 +71,11 @@ func TestUserRepository_Create(t *testing.T) {
 }
 
 func TestUserRepository_Create_DuplicateEmail(t *testing.T) {
-	db := setupTestDB(t)
-	defer db.Close()
+	testDB := setupTestDB(t)
+	defer testDB.Close()
 
-	repo := db.NewUserRepository(db)
+	repo := db.NewUserRepository(testDB)
+	ctx := context.Background()
 
 	req := &models.CreateUserRequest{
 		Email:     "duplicate@example.com",
@@ -80,19 +85,20 @@ func TestUserRepository_Create_DuplicateEmail(t *testing.T) {
 	}
 
 	// Crear el primer usuario
-	_, err := repo.Create(req)
+	_, err := repo.Create(ctx, req)
 	assert.NoError(t, err)
 
 	// Intentar crear un usuario con el mismo email
-	_, err = repo.Create(req)
+	_, err = repo.Create(ctx, req)
 	assert.Error(t, err) // Debería fallar por email duplicado
 }
 
 func TestUserRepository_GetByID(t *testing.T) {
-	db := setupTestDB(t)
-	defer db.Close()
+	testDB := setupTestDB(t)
+	defer testDB.Close()
 
-	repo := db.NewUserRepository(db)
+	repo := db.NewUserRepository(testDB)
+	ctx := context.Background()
 
 	// Crear un usuario primero
 	req := &models.CreateUserRequest{
@@ -102,11 +108,11 @@ func TestUserRepository_GetByID(t *testing.T) {
 		LastName:  "User",
 	}
 
-	createdUser, err := repo.Create(req)
+	createdUser, err := repo.Create(ctx, req)
 	require.NoError(t, err)
 
 	// Obtener el usuario por ID
-	foundUser, err := repo.GetByID(createdUser.ID)
+	foundUser, err := repo.GetByID(ctx, createdUser.ID)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, foundUser)
@@ -117,24 +123,26 @@ func TestUserRepository_GetByID(t *testing.T) {
 }
 
 func TestUserRepository_GetByID_NotFound(t *testing.T) {
-	db := setupTestDB(t)
-	defer db.Close()
+	testDB := setupTestDB(t)
+	defer testDB.Close()
 
-	repo := db.NewUserRepository(db)
+	repo := db.NewUserRepository(testDB)
+	ctx := context.Background()
 
 	// Intentar obtener un usuario que no existe
 	nonExistentID := uuid.New()
-	_, err := repo.GetByID(nonExistentID)
+	_, err := repo.GetByID(ctx, nonExistentID)
 
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "user not found")
+	assert.ErrorIs(t, err, db.ErrUserNotFound)
 }
 
 func TestUserRepository_GetByEmail(t *testing.T) {
-	db := setupTestDB(t)
-	defer db.Close()
+	testDB := setupTestDB(t)
+	defer testDB.Close()
 
-	repo := db.NewUserRepository(db)
+	repo := db.NewUserRepository(testDB)
+	ctx := context.Background()
 
 	// Crear un usuario primero
 	req := &models.CreateUserRequest{
@@ -144,11 +152,11 @@ func TestUserRepository_GetByEmail(t *testing.T) {
 		LastName:  "User",
 	}
 
-	createdUser, err := repo.Create(req)
+	createdUser, err := repo.Create(ctx, req)
 	require.NoError(t, err)
 
 	// Obtener el usuario por email
-	foundUser, err := repo.GetByEmail(createdUser.Email)
+	foundUser, err := repo.GetByEmail(ctx, createdUser.Email)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, foundUser)
@@ -158,11 +166,43 @@ func TestUserRepository_GetByEmail(t *testing.T) {
 	assert.Equal(t, createdUser.LastName, foundUser.LastName)
 }
 
+func TestUserRepository_EmailIsCaseInsensitive(t *testing.T) {
+	testDB := setupTestDB(t)
+	defer testDB.Close()
+
+	repo := db.NewUserRepository(testDB)
+	ctx := context.Background()
+
+	createdUser, err := repo.Create(ctx, &models.CreateUserRequest{
+		Email:     "Mixed.Case@Example.com",
+		Password:  "password123",
+		FirstName: "Mixed",
+		LastName:  "Case",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "mixed.case@example.com", createdUser.Email)
+
+	// La búsqueda no distingue mayúsculas
+	foundUser, err := repo.GetByEmail(ctx, "MIXED.CASE@example.COM")
+	require.NoError(t, err)
+	assert.Equal(t, createdUser.ID, foundUser.ID)
+
+	// No se puede registrar el mismo email con otras mayúsculas
+	_, err = repo.Create(ctx, &models.CreateUserRequest{
+		Email:     "mixed.case@example.com",
+		Password:  "password123",
+		FirstName: "Duplicate",
+		LastName:  "User",
+	})
+	assert.Error(t, err)
+}
+
 func TestUserRepository_Update(t *testing.T) {
-	db := setupTestDB(t)
-	defer db.Close()
+	testDB := setupTestDB(t)
+	defer testDB.Close()
 
-	repo := db.NewUserRepository(db)
+	repo := db.NewUserRepository(testDB)
+	ctx := context.Background()
 
 	// Crear un usuario primero
 	req := &models.CreateUserRequest{
@@ -172,7 +212,7 @@ func TestUserRepository_Update(t *testing.T) {
 		LastName:  "Name",
 	}
 
-	createdUser, err := repo.Create(req)
+	createdUser, err := repo.Create(ctx, req)
 	require.NoError(t, err)
 
 	// Actualizar el usuario
@@ -185,7 +225,7 @@ func TestUserRepository_Update(t *testing.T) {
 		LastName:  &newLastName,
 	}
 
-	updatedUser, err := repo.Update(createdUser.ID, updateReq)
+	updatedUser, err := repo.Update(ctx, createdUser.ID, updateReq)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, updatedUser)
@@ -196,10 +236,11 @@ func TestUserRepository_Update(t *testing.T) {
 }
 
 func TestUserRepository_Delete(t *testing.T) {
-	db := setupTestDB(t)
-	defer db.Close()
+	testDB := setupTestDB(t)
+	defer testDB.Close()
 
-	repo := db.NewUserRepository(db)
+	repo := db.NewUserRepository(testDB)
+	ctx := context.Background()
 
 	// Crear un usuario primero
 	req := &models.CreateUserRequest{
@@ -209,24 +250,25 @@ func TestUserRepository_Delete(t *testing.T) {
 		LastName:  "User",
 	}
 
-	createdUser, err := repo.Create(req)
+	createdUser, err := repo.Create(ctx, req)
 	require.NoError(t, err)
 
 	// Eliminar el usuario
-	err = repo.Delete(createdUser.ID)
+	err = repo.Delete(ctx, createdUser.ID)
 	assert.NoError(t, err)
 
 	// Verificar que el usuario ya no se puede encontrar
-	_, err = repo.GetByID(createdUser.ID)
+	_, err = repo.GetByID(ctx, createdUser.ID)
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "user not found")
+	assert.ErrorIs(t, err, db.ErrUserNotFound)
 }
 
 func TestUserRepository_List(t *testing.T) {
-	db := setupTestDB(t)
-	defer db.Close()
+	testDB := setupTestDB(t)
+	defer testDB.Close()
 
-	repo := db.NewUserRepository(db)
+	repo := db.NewUserRepository(testDB)
+	ctx := context.Background()
 
 	// Crear varios usuarios
 	for i := 0; i < 5; i++ {
@@ -236,27 +278,64 @@ func TestUserRepository_List(t *testing.T) {
 			FirstName: fmt.Sprintf("List User %d", i),
 			LastName:  "Test",
 		}
-		_, err := repo.Create(req)
+		_, err := repo.Create(ctx, req)
 		require.NoError(t, err)
 	}
 
-	// Obtener lista de usuarios
-	users, err := repo.List(3, 0) // Limit 3, offset 0
+	// Obtener la primera página de usuarios
+	users, err := repo.List(ctx, nil, 3)
 
 	assert.NoError(t, err)
 	assert.Len(t, users, 3)
 
-	// Verificar paginación
-	moreUsers, err := repo.List(3, 3) // Limit 3, offset 3
+	// Verificar paginación por cursor: la página siguiente empieza después del último ID
+	moreUsers, err := repo.List(ctx, &users[len(users)-1].ID, 3)
 	assert.NoError(t, err)
 	assert.Len(t, moreUsers, 2) // Deberían quedar 2 usuarios
+	for _, user := range moreUsers {
+		assert.True(t, user.ID.String() > users[len(users)-1].ID.String())
+	}
+}
+
+func TestUserRepository_Search(t *testing.T) {
+	testDB := setupTestDB(t)
+	defer testDB.Close()
+
+	repo := db.NewUserRepository(testDB)
+	ctx := context.Background()
+
+	for _, req := range []*models.CreateUserRequest{
+		{Email: "ana.perez@example.com", Password: "password123", FirstName: "Ana", LastName: "Pérez"},
+		{Email: "carlos@example.com", Password: "password123", FirstName: "Carlos", LastName: "Perdomo"},
+		{Email: "luis@example.com", Password: "password123", FirstName: "Luis", LastName: "Zelaya"},
+	} {
+		_, err := repo.Create(ctx, req)
+		require.NoError(t, err)
+	}
+
+	// Coincide con el email de Ana y el apellido de Carlos sin distinguir mayúsculas
+	users, err := repo.Search(ctx, "PER", 10, nil)
+	assert.NoError(t, err)
+	assert.Len(t, users, 2)
+
+	// La página siguiente empieza después del último ID
+	next, err := repo.Search(ctx, "per", 1, &users[0].ID)
+	assert.NoError(t, err)
+	assert.Len(t, next, 1)
+	assert.Equal(t, users[1].ID, next[0].ID)
+
+	// Los comodines de LIKE se buscan literalmente
+	users, err = repo.Search(ctx, "%", 10, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, users)
 }
 
 func TestUserRepository_UpdateTigerBeetleAccountID(t *testing.T) {
-	db := setupTestDB(t)
-	defer db.Close()
+	testDB := setupTestDB(t)
+	defer testDB.Close()
 
-	repo := db.NewUserRepository(db)
+	repo := db.NewUserRepository(testDB)
+	ctx := context.Background()
 
 	// Crear un usuario primero
 	req := &models.CreateUserRequest{
@@ -266,26 +345,27 @@ func TestUserRepository_UpdateTigerBeetleAccountID(t *testing.T) {
 		LastName:  "User",
 	}
 
-	createdUser, err := repo.Create(req)
+	createdUser, err := repo.Create(ctx, req)
 	require.NoError(t, err)
 
 	// Actualizar el TigerBeetle Account ID
-	accountID := uint64(12345)
-	err = repo.UpdateTigerBeetleAccountID(createdUser.ID, accountID)
+	accountID := int64(12345)
+	err = repo.UpdateTigerBeetleAccountID(ctx, createdUser.ID, accountID)
 	assert.NoError(t, err)
 
 	// Verificar que se actualizó correctamente
-	updatedUser, err := repo.GetByID(createdUser.ID)
+	updatedUser, err := repo.GetByID(ctx, createdUser.ID)
 	assert.NoError(t, err)
 	assert.NotNil(t, updatedUser.TigerBeetleAccountID)
 	assert.Equal(t, accountID, *updatedUser.TigerBeetleAccountID)
 }
 
 func TestUserRepository_VerifyPassword(t *testing.T) {
-	db := setupTestDB(t)
-	defer db.Close()
+	testDB := setupTestDB(t)
+	defer testDB.Close()
 
-	repo := db.NewUserRepository(db)
+	repo := db.NewUserRepository(testDB)
+	ctx := context.Background()
 
 	password := "testpassword123"
 	req := &models.CreateUserRequest{
@@ -295,7 +375,7 @@ func TestUserRepository_VerifyPassword(t *testing.T) {
 		LastName:  "User",
 	}
 
-	createdUser, err := repo.Create(req)
+	createdUser, err := repo.Create(ctx, req)
 	require.NoError(t, err)
 
 	// Verificar contraseña correcta
@@ -306,3 +386,43 @@ func TestUserRepository_VerifyPassword(t *testing.T) {
 	err = repo.VerifyPassword(createdUser.PasswordHash, "wrongpassword")
 	assert.Error(t, err)
 }
+
+func TestUserRepository_GetByEmailForUpdate_ReportsConcurrentLogin(t *testing.T) {
+	testDB := setupTestDB(t)
+	defer testDB.Close()
+
+	repo := db.NewUserRepository(testDB)
+	ctx := context.Background()
+
+	_, err := repo.Create(ctx, &models.CreateUserRequest{
+		Email:     "lock@example.com",
+		Password:  "password123",
+		FirstName: "Lock",
+		LastName:  "User",
+	})
+	require.NoError(t, err)
+
+	first, err := repo.BeginTx(ctx)
+	require.NoError(t, err)
+	defer first.Rollback()
+
+	user, err := repo.GetByEmailForUpdate(ctx, first, "lock@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "lock@example.com", user.Email)
+
+	// Un segundo inicio de sesión mientras el primero tiene la fila bloqueada
+	second, err := repo.BeginTx(ctx)
+	require.NoError(t, err)
+	defer second.Rollback()
+
+	_, err = repo.GetByEmailForUpdate(ctx, second, "lock@example.com")
+	assert.ErrorIs(t, err, db.ErrConcurrentLogin)
+
+	_, err = repo.GetByEmailForUpdate(ctx, second, "missing@example.com")
+	assert.ErrorIs(t, err, db.ErrUserNotFound)
+
+	// Liberado el bloqueo, el segundo puede continuar
+	require.NoError(t, first.Rollback())
+	_, err = repo.GetByEmailForUpdate(ctx, second, "lock@example.com")
+	assert.NoError(t, err)
+}
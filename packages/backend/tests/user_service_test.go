@@ -1,94 +1,89 @@
 package tests
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
-	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
+	"golang.org/x/crypto/bcrypt"
 
+	"banca-en-linea/backend/internal/audit"
+	"banca-en-linea/backend/internal/auth"
+	"banca-en-linea/backend/internal/compliance"
 	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/email"
+	apperrors "banca-en-linea/backend/internal/errors"
+	"banca-en-linea/backend/internal/fee"
+	"banca-en-linea/backend/internal/fraud"
+	"banca-en-linea/backend/internal/idempotency"
+	"banca-en-linea/backend/internal/mocks"
+	"banca-en-linea/backend/internal/monitoring"
 	"banca-en-linea/backend/internal/tigerbeetle"
+	"banca-en-linea/backend/internal/validation"
 	"banca-en-linea/backend/models"
 )
 
-// MockUserRepository es un mock del UserRepository para testing
-type MockUserRepository struct {
+// MockTigerBeetleService es un mock del TigerBeetle Service para testing
+type MockTigerBeetleService struct {
 	mock.Mock
 }
 
-func (m *MockUserRepository) Create(user *models.CreateUserRequest) (*models.User, error) {
-	args := m.Called(user)
-	return args.Get(0).(*models.User), args.Error(1)
-}
-
-func (m *MockUserRepository) GetByID(id uuid.UUID) (*models.User, error) {
-	args := m.Called(id)
+func (m *MockTigerBeetleService) CreateUserAccount(userID uint64) (tigerbeetle.AccountInterface, error) {
+	args := m.Called(userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*models.User), args.Error(1)
+	return args.Get(0).(tigerbeetle.AccountInterface), args.Error(1)
 }
 
-func (m *MockUserRepository) GetByEmail(email string) (*models.User, error) {
-	args := m.Called(email)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
+func (m *MockTigerBeetleService) BatchCreateUserAccounts(userIDs []uint64) ([]tigerbeetle.AccountInterface, []uint64, error) {
+	args := m.Called(userIDs)
+	var created []tigerbeetle.AccountInterface
+	if args.Get(0) != nil {
+		created = args.Get(0).([]tigerbeetle.AccountInterface)
 	}
-	return args.Get(0).(*models.User), args.Error(1)
-}
-
-func (m *MockUserRepository) Update(id uuid.UUID, updates *models.UpdateUserRequest) (*models.User, error) {
-	args := m.Called(id, updates)
-	return args.Get(0).(*models.User), args.Error(1)
-}
-
-func (m *MockUserRepository) Delete(id uuid.UUID) error {
-	args := m.Called(id)
-	return args.Error(0)
-}
-
-func (m *MockUserRepository) List(limit, offset int) ([]*models.User, error) {
-	args := m.Called(limit, offset)
-	return args.Get(0).([]*models.User), args.Error(1)
-}
-
-func (m *MockUserRepository) UpdateTigerBeetleAccountID(userID uuid.UUID, accountID uint64) error {
-	args := m.Called(userID, accountID)
-	return args.Error(0)
+	var failed []uint64
+	if args.Get(1) != nil {
+		failed = args.Get(1).([]uint64)
+	}
+	return created, failed, args.Error(2)
 }
 
-func (m *MockUserRepository) VerifyPassword(hashedPassword, password string) error {
-	args := m.Called(hashedPassword, password)
-	return args.Error(0)
+func (m *MockTigerBeetleService) GetAccount(accountID uint64) (tigerbeetle.AccountInterface, error) {
+	args := m.Called(accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(tigerbeetle.AccountInterface), args.Error(1)
 }
 
-// MockTigerBeetleService es un mock del TigerBeetle Service para testing
-type MockTigerBeetleService struct {
-	mock.Mock
+func (m *MockTigerBeetleService) GetAccountBalance(accountID uint64) (uint64, uint64, error) {
+	args := m.Called(accountID)
+	return args.Get(0).(uint64), args.Get(1).(uint64), args.Error(2)
 }
 
-func (m *MockTigerBeetleService) CreateUserAccount(userID uint64) (*types.Account, error) {
-	args := m.Called(userID)
+func (m *MockTigerBeetleService) LookupAccounts(accountIDs []uint64) ([]tigerbeetle.AccountInterface, error) {
+	args := m.Called(accountIDs)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*types.Account), args.Error(1)
+	return args.Get(0).([]tigerbeetle.AccountInterface), args.Error(1)
 }
 
-func (m *MockTigerBeetleService) GetAccount(accountID uint64) (*types.Account, error) {
-	args := m.Called(accountID)
+func (m *MockTigerBeetleService) GetMultipleAccountBalances(accountIDs []uint64) (map[uint64]int64, error) {
+	args := m.Called(accountIDs)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*types.Account), args.Error(1)
-}
-
-func (m *MockTigerBeetleService) GetAccountBalance(accountID uint64) (uint64, uint64, error) {
-	args := m.Called(accountID)
-	return args.Get(0).(uint64), args.Get(1).(uint64), args.Error(2)
+	return args.Get(0).(map[uint64]int64), args.Error(1)
 }
 
 func (m *MockTigerBeetleService) Transfer(fromAccountID, toAccountID, amount uint64, transferID uint64) error {
@@ -96,6 +91,17 @@ func (m *MockTigerBeetleService) Transfer(fromAccountID, toAccountID, amount uin
 	return args.Error(0)
 }
 
+func (m *MockTigerBeetleService) LinkedTransfer(transfers []tigerbeetle.LinkedTransferRequest) error {
+	args := m.Called(transfers)
+	return args.Error(0)
+}
+
+func (m *MockTigerBeetleService) BatchTransfer(groups [][]tigerbeetle.LinkedTransferRequest) ([]error, error) {
+	args := m.Called(groups)
+	errs, _ := args.Get(0).([]error)
+	return errs, args.Error(1)
+}
+
 func (m *MockTigerBeetleService) Deposit(userAccountID, amount, transferID uint64) error {
 	args := m.Called(userAccountID, amount, transferID)
 	return args.Error(0)
@@ -106,12 +112,17 @@ func (m *MockTigerBeetleService) Withdraw(userAccountID, amount, transferID uint
 	return args.Error(0)
 }
 
+func (m *MockTigerBeetleService) Ping() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
 func (m *MockTigerBeetleService) Close() {
 	m.Called()
 }
 
 func TestUserService_CreateUserWithAccount_Success(t *testing.T) {
-	mockRepo := new(MockUserRepository)
+	mockRepo := new(mocks.MockUserRepository)
 	mockTB := new(MockTigerBeetleService)
 
 	service := db.NewUserService(mockRepo, mockTB)
@@ -131,15 +142,14 @@ func TestUserService_CreateUserWithAccount_Success(t *testing.T) {
 		LastName:  req.LastName,
 	}
 
-	accountID := uint64(12345)
-	account := &types.Account{
-		ID: accountID,
+	account := &tigerbeetle.Account{
+		ID: uint64(12345),
 	}
 
 	// Setup mocks
-	mockRepo.On("Create", req).Return(createdUser, nil)
+	mockRepo.On("Create", mock.Anything, req).Return(createdUser, nil)
 	mockTB.On("CreateUserAccount", mock.AnythingOfType("uint64")).Return(account, nil)
-	mockRepo.On("UpdateTigerBeetleAccountID", userID, mock.AnythingOfType("uint64")).Return(nil)
+	mockRepo.On("UpdateTigerBeetleAccountID", mock.Anything, userID, mock.AnythingOfType("int64")).Return(nil)
 
 	// Execute
 	result, err := service.CreateUserWithAccount(req)
@@ -155,7 +165,7 @@ func TestUserService_CreateUserWithAccount_Success(t *testing.T) {
 }
 
 func TestUserService_CreateUserWithAccount_TigerBeetleFailure(t *testing.T) {
-	mockRepo := new(MockUserRepository)
+	mockRepo := new(mocks.MockUserRepository)
 	mockTB := new(MockTigerBeetleService)
 
 	service := db.NewUserService(mockRepo, mockTB)
@@ -176,9 +186,9 @@ func TestUserService_CreateUserWithAccount_TigerBeetleFailure(t *testing.T) {
 	}
 
 	// Setup mocks
-	mockRepo.On("Create", req).Return(createdUser, nil)
+	mockRepo.On("Create", mock.Anything, req).Return(createdUser, nil)
 	mockTB.On("CreateUserAccount", mock.AnythingOfType("uint64")).Return(nil, assert.AnError)
-	mockRepo.On("Delete", userID).Return(nil) // Rollback
+	mockRepo.On("Delete", mock.Anything, userID).Return(nil) // Rollback
 
 	// Execute
 	result, err := service.CreateUserWithAccount(req)
@@ -192,14 +202,104 @@ func TestUserService_CreateUserWithAccount_TigerBeetleFailure(t *testing.T) {
 	mockTB.AssertExpectations(t)
 }
 
+// adminCreateContextKey marca el contexto de la solicitud en las pruebas de CreateAdminUser
+type adminCreateContextKey struct{}
+
+// newAdminUserFixture prepara la creación por un administrador de un usuario con KYC pendiente
+// cuyos depósitos de cliente el control de velocidad rechazaría
+func newAdminUserFixture(t *testing.T) (*db.UserService, *mocks.MockUserRepository, *MockTigerBeetleService, *models.CreateAdminUserRequest, *models.User) {
+	t.Helper()
+
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	checker := fraud.NewVelocityChecker(&stubVelocityStore{count: fraud.MaxTransactionsPerWindow})
+	service := db.NewUserService(mockRepo, mockTB, db.WithVelocityChecker(checker))
+
+	req := &models.CreateAdminUserRequest{
+		Email:                  "nuevo@example.com",
+		Password:               "Password123!",
+		FirstName:              "Nuevo",
+		LastName:               "Cliente",
+		InitialDepositCentavos: 5000000,
+	}
+	created := &models.User{ID: uuid.New(), Email: req.Email, KYCStatus: models.KYCStatusPending, IsActive: true}
+
+	requestCtx := mock.MatchedBy(func(ctx context.Context) bool { return ctx.Value(adminCreateContextKey{}) != nil })
+	mockRepo.On("CreateWithAttributes", requestCtx, mock.Anything, []string{models.RoleUser}, models.KYCStatusPending).Return(created, nil)
+	mockTB.On("CreateUserAccount", mock.AnythingOfType("uint64")).Return(&tigerbeetle.Account{}, nil)
+	mockRepo.On("UpdateTigerBeetleAccountID", requestCtx, created.ID, mock.AnythingOfType("int64")).Return(nil)
+
+	return service, mockRepo, mockTB, req, created
+}
+
+func TestUserService_CreateAdminUser_InitialDepositSkipsCustomerChecks(t *testing.T) {
+	service, mockRepo, mockTB, req, created := newAdminUserFixture(t)
+	mockTB.On("Deposit", mock.AnythingOfType("uint64"), req.InitialDepositCentavos, mock.AnythingOfType("uint64")).Return(nil).Once()
+
+	ctx := context.WithValue(context.Background(), adminCreateContextKey{}, true)
+	user, err := service.CreateAdminUser(ctx, req)
+
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, user.ID)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+	mockTB.AssertExpectations(t)
+}
+
+func TestUserService_CreateAdminUser_RollsBackWhenDepositFails(t *testing.T) {
+	service, mockRepo, mockTB, req, created := newAdminUserFixture(t)
+	mockTB.On("Deposit", mock.AnythingOfType("uint64"), req.InitialDepositCentavos, mock.AnythingOfType("uint64")).Return(assert.AnError)
+	mockRepo.On("Delete", mock.Anything, created.ID).Return(nil).Once()
+
+	ctx := context.WithValue(context.Background(), adminCreateContextKey{}, true)
+	user, err := service.CreateAdminUser(ctx, req)
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Nil(t, user)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_CreateUsersWithAccounts_RollsBackRejected(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+
+	service := db.NewUserService(mockRepo, mockTB)
+
+	accepted := &models.CreateUserRequest{Email: "accepted@example.com", Password: "password123", FirstName: "A", LastName: "User"}
+	rejected := &models.CreateUserRequest{Email: "rejected@example.com", Password: "password123", FirstName: "R", LastName: "User"}
+	acceptedUser := &models.User{ID: uuid.New(), Email: accepted.Email}
+	rejectedUser := &models.User{ID: uuid.New(), Email: rejected.Email}
+	acceptedAccountID := db.GenerateTigerBeetleAccountID(acceptedUser.ID)
+	rejectedAccountID := db.GenerateTigerBeetleAccountID(rejectedUser.ID)
+
+	mockRepo.On("Create", mock.Anything, accepted).Return(acceptedUser, nil)
+	mockRepo.On("Create", mock.Anything, rejected).Return(rejectedUser, nil)
+	mockTB.On("BatchCreateUserAccounts", []uint64{acceptedAccountID, rejectedAccountID}).
+		Return([]tigerbeetle.AccountInterface{&tigerbeetle.Account{ID: acceptedAccountID}}, []uint64{rejectedAccountID}, nil).Once()
+	mockRepo.On("UpdateTigerBeetleAccountID", mock.Anything, acceptedUser.ID, int64(acceptedAccountID)).Return(nil)
+	mockRepo.On("Delete", mock.Anything, rejectedUser.ID).Return(nil) // Rollback
+
+	users, failed := service.CreateUsersWithAccounts([]*models.CreateUserRequest{accepted, rejected})
+
+	require.Len(t, users, 1)
+	assert.Equal(t, acceptedUser.ID, users[0].ID)
+	require.NotNil(t, users[0].TigerBeetleAccountID)
+	assert.Equal(t, int64(acceptedAccountID), *users[0].TigerBeetleAccountID)
+	require.Contains(t, failed, rejected.Email)
+	assert.Contains(t, failed[rejected.Email].Error(), "error creating TigerBeetle account")
+
+	mockRepo.AssertExpectations(t)
+	mockTB.AssertNotCalled(t, "CreateUserAccount", mock.Anything)
+}
+
 func TestUserService_GetUserWithBalance_Success(t *testing.T) {
-	mockRepo := new(MockUserRepository)
+	mockRepo := new(mocks.MockUserRepository)
 	mockTB := new(MockTigerBeetleService)
 
 	service := db.NewUserService(mockRepo, mockTB)
 
 	userID := uuid.New()
-	accountID := uint64(12345)
+	accountID := int64(12345)
 	user := &models.User{
 		ID:                   userID,
 		Email:                "test@example.com",
@@ -211,8 +311,8 @@ func TestUserService_GetUserWithBalance_Success(t *testing.T) {
 	expectedBalance := credits - debits // 4000
 
 	// Setup mocks
-	mockRepo.On("GetByID", userID).Return(user, nil)
-	mockTB.On("GetAccountBalance", accountID).Return(debits, credits, nil)
+	mockRepo.On("GetByID", mock.Anything, userID).Return(user, nil)
+	mockTB.On("GetAccountBalance", uint64(accountID)).Return(debits, credits, nil)
 
 	// Execute
 	resultUser, balance, err := service.GetUserWithBalance(userID)
@@ -228,7 +328,7 @@ func TestUserService_GetUserWithBalance_Success(t *testing.T) {
 }
 
 func TestUserService_GetUserWithBalance_NoTigerBeetleAccount(t *testing.T) {
-	mockRepo := new(MockUserRepository)
+	mockRepo := new(mocks.MockUserRepository)
 	mockTB := new(MockTigerBeetleService)
 
 	service := db.NewUserService(mockRepo, mockTB)
@@ -241,7 +341,7 @@ func TestUserService_GetUserWithBalance_NoTigerBeetleAccount(t *testing.T) {
 	}
 
 	// Setup mocks
-	mockRepo.On("GetByID", userID).Return(user, nil)
+	mockRepo.On("GetByID", mock.Anything, userID).Return(user, nil)
 	// No TigerBeetle calls expected
 
 	// Execute
@@ -258,13 +358,13 @@ func TestUserService_GetUserWithBalance_NoTigerBeetleAccount(t *testing.T) {
 }
 
 func TestUserService_DepositToUser_Success(t *testing.T) {
-	mockRepo := new(MockUserRepository)
+	mockRepo := new(mocks.MockUserRepository)
 	mockTB := new(MockTigerBeetleService)
 
 	service := db.NewUserService(mockRepo, mockTB)
 
 	userID := uuid.New()
-	accountID := uint64(12345)
+	accountID := int64(12345)
 	amount := uint64(10000) // 100.00 HNL
 
 	user := &models.User{
@@ -274,11 +374,11 @@ func TestUserService_DepositToUser_Success(t *testing.T) {
 	}
 
 	// Setup mocks
-	mockRepo.On("GetByID", userID).Return(user, nil)
-	mockTB.On("Deposit", accountID, amount, mock.AnythingOfType("uint64")).Return(nil)
+	mockRepo.On("GetByID", mock.Anything, userID).Return(user, nil)
+	mockTB.On("Deposit", uint64(accountID), amount, mock.AnythingOfType("uint64")).Return(nil)
 
 	// Execute
-	err := service.DepositToUser(userID, amount)
+	err := service.DepositToUser(context.Background(), userID, amount)
 
 	// Assert
 	assert.NoError(t, err)
@@ -287,8 +387,161 @@ func TestUserService_DepositToUser_Success(t *testing.T) {
 	mockTB.AssertExpectations(t)
 }
 
+func TestUserService_DepositToUser_PublishesBalance(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	broker := monitoring.NewBalanceBroker()
+
+	service := db.NewUserService(mockRepo, mockTB, db.WithBalancePublisher(broker))
+
+	userID := uuid.New()
+	accountID := int64(12345)
+	amount := uint64(10000)
+
+	user := &models.User{ID: userID, Email: "test@example.com", TigerBeetleAccountID: &accountID}
+
+	events, unsubscribe, err := broker.Subscribe(userID)
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	mockRepo.On("GetByID", mock.Anything, userID).Return(user, nil)
+	mockTB.On("Deposit", uint64(accountID), amount, mock.AnythingOfType("uint64")).Return(nil)
+	mockTB.On("GetAccountBalance", uint64(accountID)).Return(uint64(2500), uint64(12500), nil)
+
+	err = service.DepositToUser(context.Background(), userID, amount)
+	require.NoError(t, err)
+
+	event := <-events
+	assert.Equal(t, accountID, event.AccountID)
+	assert.Equal(t, int64(10000), event.NewBalance)
+}
+
+func TestUserService_DepositToUser_SkipsBalanceWithoutSubscribers(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+
+	service := db.NewUserService(mockRepo, mockTB, db.WithBalancePublisher(monitoring.NewBalanceBroker()))
+
+	userID := uuid.New()
+	accountID := int64(12345)
+	amount := uint64(10000)
+
+	user := &models.User{ID: userID, Email: "test@example.com", TigerBeetleAccountID: &accountID}
+
+	mockRepo.On("GetByID", mock.Anything, userID).Return(user, nil)
+	mockTB.On("Deposit", uint64(accountID), amount, mock.AnythingOfType("uint64")).Return(nil)
+
+	err := service.DepositToUser(context.Background(), userID, amount)
+	require.NoError(t, err)
+
+	// Sin streams abiertos no se consulta el balance a TigerBeetle
+	mockTB.AssertNotCalled(t, "GetAccountBalance", mock.Anything)
+}
+
+func TestUserService_DepositToUser_RetriesDuplicateTransferID(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+
+	service := db.NewUserService(mockRepo, mockTB)
+
+	userID := uuid.New()
+	accountID := int64(12345)
+	amount := uint64(10000)
+
+	user := &models.User{ID: userID, Email: "test@example.com", TigerBeetleAccountID: &accountID}
+
+	var usedIDs []uint64
+	mockRepo.On("GetByID", mock.Anything, userID).Return(user, nil)
+	mockTB.On("Deposit", uint64(accountID), amount, mock.AnythingOfType("uint64")).
+		Run(func(args mock.Arguments) { usedIDs = append(usedIDs, args.Get(2).(uint64)) }).
+		Return(tigerbeetle.ErrTransferExists).Once()
+	mockTB.On("Deposit", uint64(accountID), amount, mock.AnythingOfType("uint64")).
+		Run(func(args mock.Arguments) { usedIDs = append(usedIDs, args.Get(2).(uint64)) }).
+		Return(nil).Once()
+
+	err := service.DepositToUser(context.Background(), userID, amount)
+
+	require.NoError(t, err)
+	require.Len(t, usedIDs, 2)
+	assert.NotEqual(t, usedIDs[0], usedIDs[1], "retry must use a new transfer ID")
+	mockTB.AssertExpectations(t)
+}
+
+func TestUserService_DepositToUser_IdempotentDuplicateReplayed(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+
+	service := db.NewUserService(mockRepo, mockTB)
+
+	userID := uuid.New()
+	accountID := int64(12345)
+	amount := uint64(10000)
+
+	user := &models.User{ID: userID, Email: "test@example.com", TigerBeetleAccountID: &accountID}
+
+	mockRepo.On("GetByID", mock.Anything, userID).Return(user, nil)
+	mockTB.On("Deposit", uint64(accountID), amount, mock.AnythingOfType("uint64")).
+		Return(tigerbeetle.ErrTransferExists).Once()
+
+	// Un intento anterior con la misma llave ya aplicó el depósito: el reintento es exitoso
+	ctx := idempotency.WithKey(context.Background(), userID, idempotency.HashKey("retry-key"))
+	err := service.DepositToUser(ctx, userID, amount)
+
+	require.NoError(t, err)
+	mockTB.AssertNumberOfCalls(t, "Deposit", 1)
+}
+
+func TestUserService_DepositToUser_SameKeyFromDifferentUsers(t *testing.T) {
+	stub := tigerbeetle.NewServiceStub()
+	require.NoError(t, stub.InitializeMasterAccounts())
+
+	mockRepo := new(mocks.MockUserRepository)
+	service := db.NewUserService(mockRepo, stub)
+
+	keyHash := idempotency.HashKey("same-key")
+	for _, accountID := range []int64{5001, 5002} {
+		_, err := stub.CreateUserAccount(uint64(accountID))
+		require.NoError(t, err)
+
+		user := &models.User{ID: uuid.New(), TigerBeetleAccountID: &accountID}
+		mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+
+		ctx := idempotency.WithKey(context.Background(), user.ID, keyHash)
+		require.NoError(t, service.DepositToUser(ctx, user.ID, 10000))
+		// Reintentar con la misma llave no vuelve a acreditar el depósito
+		require.NoError(t, service.DepositToUser(ctx, user.ID, 10000))
+
+		debits, credits, err := stub.GetAccountBalance(uint64(accountID))
+		require.NoError(t, err)
+		assert.Equal(t, int64(10000), int64(credits)-int64(debits))
+	}
+}
+
+func TestUserService_DepositToUser_IdempotentKeyReusedForDifferentAmount(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+
+	service := db.NewUserService(mockRepo, mockTB)
+
+	userID := uuid.New()
+	accountID := int64(12345)
+	amount := uint64(10000)
+
+	user := &models.User{ID: userID, Email: "test@example.com", TigerBeetleAccountID: &accountID}
+
+	mockRepo.On("GetByID", mock.Anything, userID).Return(user, nil)
+	mockTB.On("Deposit", uint64(accountID), amount, mock.AnythingOfType("uint64")).
+		Return(tigerbeetle.ErrTransferMismatch).Once()
+
+	ctx := idempotency.WithKey(context.Background(), userID, idempotency.HashKey("retry-key"))
+	err := service.DepositToUser(ctx, userID, amount)
+
+	assert.ErrorIs(t, err, tigerbeetle.ErrTransferMismatch)
+	mockTB.AssertNumberOfCalls(t, "Deposit", 1)
+}
+
 func TestUserService_DepositToUser_NoTigerBeetleAccount(t *testing.T) {
-	mockRepo := new(MockUserRepository)
+	mockRepo := new(mocks.MockUserRepository)
 	mockTB := new(MockTigerBeetleService)
 
 	service := db.NewUserService(mockRepo, mockTB)
@@ -303,10 +556,10 @@ func TestUserService_DepositToUser_NoTigerBeetleAccount(t *testing.T) {
 	}
 
 	// Setup mocks
-	mockRepo.On("GetByID", userID).Return(user, nil)
+	mockRepo.On("GetByID", mock.Anything, userID).Return(user, nil)
 
 	// Execute
-	err := service.DepositToUser(userID, amount)
+	err := service.DepositToUser(context.Background(), userID, amount)
 
 	// Assert
 	assert.Error(t, err)
@@ -317,13 +570,13 @@ func TestUserService_DepositToUser_NoTigerBeetleAccount(t *testing.T) {
 }
 
 func TestUserService_WithdrawFromUser_Success(t *testing.T) {
-	mockRepo := new(MockUserRepository)
+	mockRepo := new(mocks.MockUserRepository)
 	mockTB := new(MockTigerBeetleService)
 
 	service := db.NewUserService(mockRepo, mockTB)
 
 	userID := uuid.New()
-	accountID := uint64(12345)
+	accountID := int64(12345)
 	amount := uint64(5000) // 50.00 HNL
 
 	user := &models.User{
@@ -337,12 +590,12 @@ func TestUserService_WithdrawFromUser_Success(t *testing.T) {
 	credits := uint64(10000)
 
 	// Setup mocks
-	mockRepo.On("GetByID", userID).Return(user, nil)
-	mockTB.On("GetAccountBalance", accountID).Return(debits, credits, nil)
-	mockTB.On("Withdraw", accountID, amount, mock.AnythingOfType("uint64")).Return(nil)
+	mockRepo.On("GetByID", mock.Anything, userID).Return(user, nil)
+	mockTB.On("GetAccountBalance", uint64(accountID)).Return(debits, credits, nil)
+	mockTB.On("Withdraw", uint64(accountID), amount, mock.AnythingOfType("uint64")).Return(nil)
 
 	// Execute
-	err := service.WithdrawFromUser(userID, amount)
+	err := service.WithdrawFromUser(context.Background(), userID, amount)
 
 	// Assert
 	assert.NoError(t, err)
@@ -351,14 +604,139 @@ func TestUserService_WithdrawFromUser_Success(t *testing.T) {
 	mockTB.AssertExpectations(t)
 }
 
+// channelAlerter entrega los avisos de transacciones grandes en un canal
+type channelAlerter chan email.TransactionEvent
+
+func (a channelAlerter) SendTransactionAlert(user *models.User, event email.TransactionEvent) error {
+	a <- event
+	return nil
+}
+
+func TestUserService_WithdrawFromUser_AlertsLargeWithdrawal(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	alerts := make(channelAlerter, 1)
+
+	service := db.NewUserService(mockRepo, mockTB, db.WithTransactionAlerts(alerts, 100000))
+
+	userID := uuid.New()
+	accountID := int64(12345)
+	user := &models.User{ID: userID, Email: "test@example.com", TigerBeetleAccountID: &accountID}
+
+	mockRepo.On("GetByID", mock.Anything, userID).Return(user, nil)
+	mockTB.On("GetAccountBalance", uint64(accountID)).Return(uint64(0), uint64(500000), nil)
+	mockTB.On("Withdraw", uint64(accountID), mock.Anything, mock.AnythingOfType("uint64")).Return(nil)
+
+	// Un retiro igual al umbral no genera aviso
+	require.NoError(t, service.WithdrawFromUser(context.Background(), userID, 100000))
+	require.NoError(t, service.WithdrawFromUser(context.Background(), userID, 100001))
+
+	select {
+	case event := <-alerts:
+		assert.Equal(t, models.TransactionEventWithdrawal, event.Type)
+		assert.Equal(t, uint64(100001), event.Amount)
+		assert.Empty(t, event.CounterpartName)
+	case <-time.After(time.Second):
+		t.Fatal("large withdrawal alert was not sent")
+	}
+	assert.Empty(t, alerts)
+}
+
+// staticNotificationPreferences retorna siempre las mismas preferencias de notificación
+type staticNotificationPreferences struct {
+	db.NotificationPreferenceRepository
+	prefs models.NotificationPreferences
+}
+
+func (r *staticNotificationPreferences) Get(ctx context.Context, userID uuid.UUID) (*models.NotificationPreferences, error) {
+	prefs := r.prefs
+	prefs.UserID = userID
+	return &prefs, nil
+}
+
+func TestUserService_WithdrawFromUser_RespectsLargeTransactionConsent(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	alerts := make(channelAlerter, 1)
+	prefs := &staticNotificationPreferences{prefs: models.NotificationPreferences{EmailLargeTx: false, SMSLargeTx: true}}
+
+	service := db.NewUserService(mockRepo, mockTB,
+		db.WithTransactionAlerts(alerts, 100000),
+		db.WithNotificationPreferenceRepository(prefs))
+
+	userID := uuid.New()
+	accountID := int64(12345)
+	user := &models.User{ID: userID, Email: "test@example.com", TigerBeetleAccountID: &accountID}
+
+	mockRepo.On("GetByID", mock.Anything, userID).Return(user, nil)
+	mockTB.On("GetAccountBalance", uint64(accountID)).Return(uint64(0), uint64(500000), nil)
+	mockTB.On("Withdraw", uint64(accountID), mock.Anything, mock.AnythingOfType("uint64")).Return(nil)
+
+	require.NoError(t, service.WithdrawFromUser(context.Background(), userID, 200000))
+
+	select {
+	case <-alerts:
+		t.Fatal("large withdrawal alert was sent without consent")
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.False(t, service.NotificationAllowed(context.Background(), userID, models.NotificationChannelEmail, models.NotificationKindLargeTransaction))
+	assert.True(t, service.NotificationAllowed(context.Background(), userID, models.NotificationChannelSMS, models.NotificationKindLargeTransaction))
+	assert.False(t, service.NotificationAllowed(context.Background(), userID, models.NotificationChannelSMS, models.NotificationKindStatement))
+}
+
+func TestUserService_TransferBetweenUsers_AlertsSenderWithCounterpart(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	alerts := make(channelAlerter, 1)
+
+	service := db.NewUserService(mockRepo, mockTB, db.WithTransactionAlerts(alerts, 100000))
+
+	fromAccountID, toAccountID := int64(111), int64(222)
+	fromUser := &models.User{ID: uuid.New(), Email: "from@example.com", TigerBeetleAccountID: &fromAccountID}
+	toUser := &models.User{ID: uuid.New(), Email: "to@example.com", FirstName: "Luis", LastName: "Pérez", TigerBeetleAccountID: &toAccountID}
+
+	mockRepo.On("GetByID", mock.Anything, fromUser.ID).Return(fromUser, nil)
+	mockRepo.On("GetByID", mock.Anything, toUser.ID).Return(toUser, nil)
+	mockTB.On("GetAccountBalance", uint64(fromAccountID)).Return(uint64(0), uint64(500000), nil)
+	mockTB.On("Transfer", uint64(fromAccountID), uint64(toAccountID), uint64(200000), mock.AnythingOfType("uint64")).Return(nil)
+
+	_, err := service.TransferBetweenUsers(context.Background(), fromUser.ID, toUser.ID, 200000)
+	require.NoError(t, err)
+
+	select {
+	case event := <-alerts:
+		assert.Equal(t, models.TransactionEventTransfer, event.Type)
+		assert.Equal(t, "Luis Pérez", event.CounterpartName)
+		assert.Equal(t, &toUser.ID, event.ToUserID)
+	case <-time.After(time.Second):
+		t.Fatal("large transfer alert was not sent")
+	}
+}
+
+func TestUserService_WithdrawFromUser_NoTigerBeetleAccount(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+
+	service := db.NewUserService(mockRepo, mockTB)
+
+	userID := uuid.New()
+	user := &models.User{ID: userID, Email: "test@example.com", IsActive: true}
+	mockRepo.On("GetByID", mock.Anything, userID).Return(user, nil)
+
+	err := service.WithdrawFromUser(context.Background(), userID, 5000)
+	assert.ErrorIs(t, err, apperrors.ErrNoTigerBeetleAccount)
+	assert.ErrorIs(t, err, db.ErrNoTigerBeetleAccount)
+	mockTB.AssertNotCalled(t, "Withdraw", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestUserService_WithdrawFromUser_InsufficientFunds(t *testing.T) {
-	mockRepo := new(MockUserRepository)
+	mockRepo := new(mocks.MockUserRepository)
 	mockTB := new(MockTigerBeetleService)
 
 	service := db.NewUserService(mockRepo, mockTB)
 
 	userID := uuid.New()
-	accountID := uint64(12345)
+	accountID := int64(12345)
 	amount := uint64(15000) // 150.00 HNL (más que el balance)
 
 	user := &models.User{
@@ -372,11 +750,11 @@ func TestUserService_WithdrawFromUser_InsufficientFunds(t *testing.T) {
 	credits := uint64(10000)
 
 	// Setup mocks
-	mockRepo.On("GetByID", userID).Return(user, nil)
-	mockTB.On("GetAccountBalance", accountID).Return(debits, credits, nil)
+	mockRepo.On("GetByID", mock.Anything, userID).Return(user, nil)
+	mockTB.On("GetAccountBalance", uint64(accountID)).Return(debits, credits, nil)
 
 	// Execute
-	err := service.WithdrawFromUser(userID, amount)
+	err := service.WithdrawFromUser(context.Background(), userID, amount)
 
 	// Assert
 	assert.Error(t, err)
@@ -387,15 +765,15 @@ func TestUserService_WithdrawFromUser_InsufficientFunds(t *testing.T) {
 }
 
 func TestUserService_TransferBetweenUsers_Success(t *testing.T) {
-	mockRepo := new(MockUserRepository)
+	mockRepo := new(mocks.MockUserRepository)
 	mockTB := new(MockTigerBeetleService)
 
 	service := db.NewUserService(mockRepo, mockTB)
 
 	fromUserID := uuid.New()
 	toUserID := uuid.New()
-	fromAccountID := uint64(12345)
-	toAccountID := uint64(67890)
+	fromAccountID := int64(12345)
+	toAccountID := int64(67890)
 	amount := uint64(5000) // 50.00 HNL
 
 	fromUser := &models.User{
@@ -415,17 +793,1166 @@ func TestUserService_TransferBetweenUsers_Success(t *testing.T) {
 	credits := uint64(10000)
 
 	// Setup mocks
-	mockRepo.On("GetByID", fromUserID).Return(fromUser, nil)
-	mockRepo.On("GetByID", toUserID).Return(toUser, nil)
-	mockTB.On("GetAccountBalance", fromAccountID).Return(debits, credits, nil)
-	mockTB.On("Transfer", fromAccountID, toAccountID, amount, mock.AnythingOfType("uint64")).Return(nil)
+	mockRepo.On("GetByID", mock.Anything, fromUserID).Return(fromUser, nil)
+	mockRepo.On("GetByID", mock.Anything, toUserID).Return(toUser, nil)
+	mockTB.On("GetAccountBalance", uint64(fromAccountID)).Return(debits, credits, nil)
+	mockTB.On("Transfer", uint64(fromAccountID), uint64(toAccountID), amount, mock.AnythingOfType("uint64")).Return(nil)
 
 	// Execute
-	err := service.TransferBetweenUsers(fromUserID, toUserID, amount)
+	transferFee, err := service.TransferBetweenUsers(context.Background(), fromUserID, toUserID, amount)
 
 	// Assert
 	assert.NoError(t, err)
+	assert.Zero(t, transferFee)
+
+	mockRepo.AssertExpectations(t)
+	mockTB.AssertExpectations(t)
+}
+
+func TestUserService_TransferBetweenUsers_ChargesFee(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+
+	service := db.NewUserService(mockRepo, mockTB, db.WithFeeSchedule(fee.FeeSchedule{BaseFeeCents: 25, PercentFee: 1}))
+
+	fromAccountID := int64(12345)
+	toAccountID := int64(67890)
+	fromUser := &models.User{ID: uuid.New(), TigerBeetleAccountID: &fromAccountID}
+	toUser := &models.User{ID: uuid.New(), TigerBeetleAccountID: &toAccountID}
+
+	mockRepo.On("GetByID", mock.Anything, fromUser.ID).Return(fromUser, nil)
+	mockRepo.On("GetByID", mock.Anything, toUser.ID).Return(toUser, nil)
+
+	// El balance cubre el monto pero no la comisión (5000 + 25 + 50)
+	mockTB.On("GetAccountBalance", uint64(fromAccountID)).Return(uint64(0), uint64(5050), nil).Once()
+	_, err := service.TransferBetweenUsers(context.Background(), fromUser.ID, toUser.ID, 5000)
+	var fundsErr *db.InsufficientFundsError
+	require.ErrorAs(t, err, &fundsErr)
+	assert.Equal(t, uint64(5075), fundsErr.Expected)
+
+	mockTB.On("GetAccountBalance", uint64(fromAccountID)).Return(uint64(0), uint64(10000), nil).Once()
+	mockTB.On("LinkedTransfer", mock.MatchedBy(func(transfers []tigerbeetle.LinkedTransferRequest) bool {
+		return len(transfers) == 2 &&
+			transfers[0].FromAccountID == uint64(fromAccountID) && transfers[0].ToAccountID == uint64(toAccountID) &&
+			transfers[0].Amount == 5000 &&
+			transfers[1].FromAccountID == uint64(fromAccountID) && transfers[1].ToAccountID == uint64(tigerbeetle.FeeAccount) &&
+			transfers[1].Amount == 75 && transfers[1].Code == tigerbeetle.TransferCodeFee
+	})).Return(nil)
+
+	transferFee, err := service.TransferBetweenUsers(context.Background(), fromUser.ID, toUser.ID, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(75), transferFee)
+	mockTB.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserService_TransferBetweenUsers_RecordsFee(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	txRepo := &recordingTransactionRepository{}
+	service := db.NewUserService(mockRepo, mockTB, db.WithTransactionRepository(txRepo),
+		db.WithFeeSchedule(fee.FeeSchedule{BaseFeeCents: 25, PercentFee: 1}))
+
+	fromAccountID, toAccountID := int64(12345), int64(67890)
+	fromUser := &models.User{ID: uuid.New(), TigerBeetleAccountID: &fromAccountID, DailyTransferLimitCents: 1000000}
+	toUser := &models.User{ID: uuid.New(), TigerBeetleAccountID: &toAccountID}
+	mockRepo.On("GetByID", mock.Anything, fromUser.ID).Return(fromUser, nil)
+	mockRepo.On("GetByID", mock.Anything, toUser.ID).Return(toUser, nil)
+	mockTB.On("GetAccountBalance", uint64(fromAccountID)).Return(uint64(0), uint64(10000), nil)
+	mockTB.On("LinkedTransfer", mock.Anything).Return(nil)
+
+	_, err := service.TransferBetweenUsers(context.Background(), fromUser.ID, toUser.ID, 5000)
+	require.NoError(t, err)
+
+	// La comisión también mueve dinero, por lo que queda registrada junto a la transferencia
+	require.Len(t, txRepo.created, 2)
+	transfer, charged := txRepo.created[0], txRepo.created[1]
+	assert.Equal(t, models.TransactionTypeFee, charged.TransactionType)
+	assert.Equal(t, int64(75), charged.Amount)
+	assert.Equal(t, &fromUser.ID, charged.CreatedBy)
+	assert.Equal(t, &transfer.ID, charged.RelatedTransactionID)
+	assert.NotEqual(t, transfer.TigerBeetleTransferID, charged.TigerBeetleTransferID)
+}
+
+func TestUserService_TransferByEmail_ResolvesRecipient(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+
+	service := db.NewUserService(mockRepo, mockTB)
+
+	fromAccountID := int64(12345)
+	toAccountID := int64(67890)
+	fromUser := &models.User{ID: uuid.New(), Email: "from@example.com", IsActive: true, TigerBeetleAccountID: &fromAccountID}
+	toUser := &models.User{ID: uuid.New(), Email: "to@example.com", IsActive: true, TigerBeetleAccountID: &toAccountID}
+
+	mockRepo.On("GetByEmail", mock.Anything, "to@example.com").Return(toUser, nil)
+	mockRepo.On("GetByID", mock.Anything, fromUser.ID).Return(fromUser, nil)
+	mockRepo.On("GetByID", mock.Anything, toUser.ID).Return(toUser, nil)
+	mockTB.On("GetAccountBalance", uint64(fromAccountID)).Return(uint64(0), uint64(10000), nil)
+	mockTB.On("Transfer", uint64(fromAccountID), uint64(toAccountID), uint64(5000), mock.AnythingOfType("uint64")).Return(nil)
+
+	_, err := service.TransferByEmail(context.Background(), fromUser.ID, " to@example.com ", 5000)
+
+	assert.NoError(t, err)
+	mockTB.AssertExpectations(t)
+}
+
+func TestUserService_TransferByEmail_RejectsUnknownOrInactiveRecipient(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	service := db.NewUserService(mockRepo, new(MockTigerBeetleService))
+
+	fromUserID := uuid.New()
+	inactive := &models.User{ID: uuid.New(), Email: "inactive@example.com", IsActive: false}
+	mockRepo.On("GetByEmail", mock.Anything, "missing@example.com").Return(nil, db.ErrUserNotFound)
+	mockRepo.On("GetByEmail", mock.Anything, "inactive@example.com").Return(inactive, nil)
+
+	_, err := service.TransferByEmail(context.Background(), fromUserID, "missing@example.com", 5000)
+	assert.ErrorIs(t, err, db.ErrRecipientNotFound)
+
+	_, err = service.TransferByEmail(context.Background(), fromUserID, "inactive@example.com", 5000)
+	assert.ErrorIs(t, err, db.ErrRecipientInactive)
+}
+
+func TestUserService_TransferBetweenUsers_SetsQueryTimeout(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+
+	service := db.NewUserService(mockRepo, mockTB)
+
+	fromUserID := uuid.New()
+	toUserID := uuid.New()
+
+	fromUser := &models.User{ID: fromUserID, Email: "from@example.com"}
+	toUser := &models.User{ID: toUserID, Email: "to@example.com"}
+
+	// Setup mocks
+	mockRepo.On("GetByID", mock.Anything, fromUserID).Return(fromUser, nil)
+	mockRepo.On("GetByID", mock.Anything, toUserID).Return(toUser, nil).Maybe()
+
+	// Execute (el resultado no importa, solo el contexto recibido por el repositorio)
+	start := time.Now()
+	_, _ = service.TransferBetweenUsers(context.Background(), fromUserID, toUserID, 5000)
+
+	// Assert
+	ctx := mockRepo.CapturedContext()
+	require.NotNil(t, ctx)
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok, "GetByID debe recibir un contexto con deadline")
+	assert.WithinDuration(t, start.Add(5*time.Second), deadline, time.Second)
+
+	mockRepo.AssertExpectations(t)
+}
+
+// staleBalanceTigerBeetle envuelve el stub y reporta un balance desactualizado en la primera
+// consulta, simulando que otra operación debitó la cuenta después de la verificación previa
+type staleBalanceTigerBeetle struct {
+	*tigerbeetle.Service
+	staleCredits uint64
+	calls        int
+}
+
+func (s *staleBalanceTigerBeetle) GetAccountBalance(accountID uint64) (uint64, uint64, error) {
+	s.calls++
+	if s.calls == 1 {
+		return 0, s.staleCredits, nil
+	}
+	return s.Service.GetAccountBalance(accountID)
+}
+
+func TestUserService_WithdrawFromUser_ExceedsCreditsAfterStaleCheck(t *testing.T) {
+	stub := tigerbeetle.NewServiceStub()
+	require.NoError(t, stub.InitializeMasterAccounts())
+
+	accountID := int64(12345)
+	_, err := stub.CreateUserAccount(uint64(accountID))
+	require.NoError(t, err)
+	require.NoError(t, stub.Deposit(uint64(accountID), 5000, 1))
+
+	tb := &staleBalanceTigerBeetle{Service: stub, staleCredits: 10000}
+	mockRepo := new(mocks.MockUserRepository)
+	service := db.NewUserService(mockRepo, tb)
+
+	userID := uuid.New()
+	user := &models.User{ID: userID, Email: "test@example.com", TigerBeetleAccountID: &accountID}
+	mockRepo.On("GetByID", mock.Anything, userID).Return(user, nil)
+
+	// La verificación previa ve 100.00 HNL, pero TigerBeetle solo tiene 50.00 HNL
+	err = service.WithdrawFromUser(context.Background(), userID, 8000)
+
+	var fundsErr *db.InsufficientFundsError
+	require.ErrorAs(t, err, &fundsErr)
+	assert.Equal(t, uint64(8000), fundsErr.Expected)
+	assert.Equal(t, uint64(5000), fundsErr.Actual)
+	assert.ErrorIs(t, err, db.ErrInsufficientFunds)
+
+	// El balance no debe haber cambiado
+	debits, credits, err := stub.GetAccountBalance(uint64(accountID))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), debits)
+	assert.Equal(t, uint64(5000), credits)
+}
+
+func TestUserService_ResetPassword_StoresBcryptHash(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	service := db.NewUserService(mockRepo, nil)
+
+	userID := uuid.New()
+	currentHash, err := bcrypt.GenerateFromPassword([]byte("OldPassword1!"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	mockRepo.On("GetByID", mock.Anything, userID).Return(&models.User{ID: userID, PasswordHash: string(currentHash)}, nil)
+	mockRepo.On("UpdatePassword", mock.Anything, userID, mock.MatchedBy(func(hash string) bool {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte("NewPassword1!")) == nil
+	})).Return(nil)
+
+	err = service.ResetPassword(context.Background(), userID, "NewPassword1!")
+
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_PasswordChanges_RevokeRefreshTokens(t *testing.T) {
+	for name, change := range map[string]func(service *db.UserService, userID uuid.UUID) error{
+		"reset": func(service *db.UserService, userID uuid.UUID) error {
+			return service.ResetPassword(context.Background(), userID, "NewPassword1!")
+		},
+		"change": func(service *db.UserService, userID uuid.UUID) error {
+			return service.ChangePassword(userID, "OldPassword1!", "NewPassword1!")
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(mocks.MockUserRepository)
+			user := &models.User{ID: uuid.New(), Email: "test@example.com"}
+			store := newMemoryRefreshTokenStore(user.Email, nil)
+			authService := auth.NewService(auth.WithRefreshTokenStore(store))
+			service := db.NewUserService(mockRepo, nil, db.WithRefreshTokenRepository(store))
+
+			currentHash, err := bcrypt.GenerateFromPassword([]byte("OldPassword1!"), bcrypt.MinCost)
+			require.NoError(t, err)
+			user.PasswordHash = string(currentHash)
+			mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+			mockRepo.On("VerifyPassword", user.PasswordHash, "OldPassword1!").Return(nil)
+			mockRepo.On("UpdatePassword", mock.Anything, user.ID, mock.AnythingOfType("string")).Return(nil)
+
+			refreshToken, err := authService.GenerateRefreshToken(user, models.SessionInfo{})
+			require.NoError(t, err)
+			_, err = authService.RefreshAccessToken(refreshToken)
+			require.NoError(t, err)
+
+			require.NoError(t, change(service, user.ID))
+
+			_, err = authService.RefreshAccessToken(refreshToken)
+			assert.ErrorIs(t, err, auth.ErrInvalidRefreshToken)
+		})
+	}
+}
+
+// memoryPasswordHistory es un historial de contraseñas en memoria para testing
+type memoryPasswordHistory struct {
+	hashes []string
+}
+
+func (h *memoryPasswordHistory) Add(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	h.hashes = append([]string{passwordHash}, h.hashes...)
+	if len(h.hashes) > db.PasswordHistorySize {
+		h.hashes = h.hashes[:db.PasswordHistorySize]
+	}
+	return nil
+}
+
+func (h *memoryPasswordHistory) ListRecent(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	return h.hashes, nil
+}
+
+func TestUserService_ChangePassword_RejectsRecentPassword(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	history := &memoryPasswordHistory{}
+	service := db.NewUserService(mockRepo, nil, db.WithPasswordHistoryRepository(history))
+
+	previousHash, err := bcrypt.GenerateFromPassword([]byte("Previous1!"), bcrypt.MinCost)
+	require.NoError(t, err)
+	currentHash, err := bcrypt.GenerateFromPassword([]byte("Current1!"), bcrypt.MinCost)
+	require.NoError(t, err)
+	history.hashes = []string{string(currentHash), string(previousHash)}
+
+	userID := uuid.New()
+	mockRepo.On("GetByID", mock.Anything, userID).Return(&models.User{ID: userID, PasswordHash: string(currentHash)}, nil)
+	mockRepo.On("VerifyPassword", string(currentHash), "Current1!").Return(nil)
+
+	err = service.ChangePassword(userID, "Current1!", "Previous1!")
+	assert.ErrorIs(t, err, db.ErrPasswordReused)
+
+	err = service.ChangePassword(userID, "Current1!", "weak")
+	var complexityErr *auth.PasswordComplexityError
+	require.ErrorAs(t, err, &complexityErr)
+	assert.Len(t, complexityErr.Violations, 4)
+
+	mockRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// pagedTransactionRepository es un repositorio de transacciones en memoria para probar la paginación
+type pagedTransactionRepository struct {
+	db.TransactionRepository
+	transactions []*models.Transaction
+}
+
+func (r *pagedTransactionRepository) Filter(ctx context.Context, userID uuid.UUID, opts models.TransactionFilter) ([]*models.Transaction, error) {
+	var matching []*models.Transaction
+	for _, tx := range r.transactions {
+		if opts.Type != nil && tx.TransactionType != *opts.Type {
+			continue
+		}
+		matching = append(matching, tx)
+	}
+
+	start := 0
+	if opts.Cursor != nil {
+		for i, tx := range matching {
+			if tx.ID == *opts.Cursor {
+				start = i + 1
+			}
+		}
+	}
+	end := min(start+opts.Limit, len(matching))
+	return matching[start:end], nil
+}
+
+func (r *pagedTransactionRepository) GetByUserID(ctx context.Context, userID uuid.UUID, afterID *uuid.UUID, limit int) ([]*models.Transaction, error) {
+	return r.Filter(ctx, userID, models.TransactionFilter{Cursor: afterID, Limit: limit})
+}
+
+func TestUserService_GetTransactionHistory_Paginates(t *testing.T) {
+	repo := &pagedTransactionRepository{}
+	for i := 0; i < 5; i++ {
+		repo.transactions = append(repo.transactions, &models.Transaction{ID: uuid.New()})
+	}
+	service := db.NewUserService(new(mocks.MockUserRepository), nil, db.WithTransactionRepository(repo))
+	userID := uuid.New()
+
+	page, err := service.GetTransactionHistory(context.Background(), userID, models.TransactionFilter{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Transactions, 2)
+	require.NotNil(t, page.NextCursor)
+	assert.Equal(t, repo.transactions[1].ID, *page.NextCursor)
+
+	page, err = service.GetTransactionHistory(context.Background(), userID, models.TransactionFilter{Cursor: page.NextCursor, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, repo.transactions[2].ID, page.Transactions[0].ID)
+	require.NotNil(t, page.NextCursor)
+
+	page, err = service.GetTransactionHistory(context.Background(), userID, models.TransactionFilter{Cursor: page.NextCursor, Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, page.Transactions, 1)
+	assert.Nil(t, page.NextCursor)
+}
+
+func TestUserService_GetTransactionHistory_FiltersByType(t *testing.T) {
+	repo := &pagedTransactionRepository{transactions: []*models.Transaction{
+		{ID: uuid.New(), TransactionType: models.TransactionTypeDeposit},
+		{ID: uuid.New(), TransactionType: models.TransactionTypeWithdrawal},
+		{ID: uuid.New(), TransactionType: models.TransactionTypeDeposit},
+	}}
+	service := db.NewUserService(new(mocks.MockUserRepository), nil, db.WithTransactionRepository(repo))
+
+	txType := models.TransactionTypeDeposit
+	page, err := service.GetTransactionHistory(context.Background(), uuid.New(), models.TransactionFilter{Type: &txType, Limit: 20})
+	require.NoError(t, err)
+	require.Len(t, page.Transactions, 2)
+	for _, tx := range page.Transactions {
+		assert.Equal(t, models.TransactionTypeDeposit, tx.TransactionType)
+	}
+	assert.Nil(t, page.NextCursor)
+}
+
+// dailyTotalTransactionRepository retorna un total diario fijo para probar los límites
+type dailyTotalTransactionRepository struct {
+	db.TransactionRepository
+	total uint64
+}
+
+func (r *dailyTotalTransactionRepository) GetDailyTotal(ctx context.Context, userID uuid.UUID, txType string, date time.Time) (uint64, error) {
+	return r.total, nil
+}
+
+func TestUserService_WithdrawFromUser_DailyLimitExceeded(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	txRepo := &dailyTotalTransactionRepository{total: 15000}
+	service := db.NewUserService(mockRepo, mockTB, db.WithTransactionRepository(txRepo))
+
+	accountID := int64(12345)
+	user := &models.User{ID: uuid.New(), TigerBeetleAccountID: &accountID, DailyWithdrawalLimitCents: 20000}
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+
+	err := service.WithdrawFromUser(context.Background(), user.ID, 6000)
+
+	assert.ErrorIs(t, err, db.ErrDailyLimitExceeded)
+	var limitErr *db.DailyLimitExceededError
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, uint64(20000), limitErr.Limit)
+	assert.Equal(t, uint64(15000), limitErr.Used)
+	assert.Equal(t, uint64(6000), limitErr.Requested)
+	mockTB.AssertNotCalled(t, "Withdraw", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserService_WithdrawFromUser_DailyLimitAccumulatesWithdrawals(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	txRepo := &recordingTransactionRepository{}
+	service := db.NewUserService(mockRepo, mockTB, db.WithTransactionRepository(txRepo))
+
+	accountID := int64(12345)
+	user := &models.User{ID: uuid.New(), TigerBeetleAccountID: &accountID, DailyWithdrawalLimitCents: 10000}
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockTB.On("GetAccountBalance", uint64(accountID)).Return(uint64(0), uint64(50000), nil)
+	mockTB.On("Withdraw", uint64(accountID), uint64(6000), mock.AnythingOfType("uint64")).Return(nil).Once()
+
+	require.NoError(t, service.WithdrawFromUser(context.Background(), user.ID, 6000))
+
+	// El retiro queda registrado a nombre del usuario aunque la cuenta principal no esté en bank_accounts
+	require.Len(t, txRepo.created, 1)
+	assert.Equal(t, models.TransactionTypeWithdrawal, txRepo.created[0].TransactionType)
+	assert.Equal(t, &user.ID, txRepo.created[0].CreatedBy)
+	assert.Nil(t, txRepo.created[0].FromAccountID)
+
+	// Cada retiro cabe en el límite, pero los dos juntos no
+	err := service.WithdrawFromUser(context.Background(), user.ID, 5000)
+	var limitErr *db.DailyLimitExceededError
+	require.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, uint64(6000), limitErr.Used)
+	assert.Equal(t, uint64(5000), limitErr.Requested)
+	mockTB.AssertNumberOfCalls(t, "Withdraw", 1)
+}
+
+func TestUserService_DepositToAccount_UsesAccountTigerBeetleID(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	accounts := newMemoryBankAccountRepository()
+	service := db.NewUserService(mockRepo, mockTB, db.WithBankAccountRepository(accounts))
+
+	primaryID := int64(12345)
+	user := &models.User{ID: uuid.New(), TigerBeetleAccountID: &primaryID}
+	savings, err := accounts.Create(context.Background(), &models.BankAccount{
+		ID:                   uuid.New(),
+		UserID:               user.ID,
+		AccountType:          models.AccountTypeSavings,
+		TigerBeetleAccountID: 67890,
+	})
+	require.NoError(t, err)
+
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockTB.On("Deposit", uint64(67890), uint64(2500), mock.AnythingOfType("uint64")).Return(nil)
+
+	require.NoError(t, service.DepositToAccount(context.Background(), savings.ID, 2500))
+	mockTB.AssertExpectations(t)
+}
+
+func TestUserService_DepositToAccount_RecordsAccountActivity(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	accounts := newMemoryBankAccountRepository()
+	txRepo := &recordingTransactionRepository{}
+	service := db.NewUserService(mockRepo, mockTB, db.WithBankAccountRepository(accounts), db.WithTransactionRepository(txRepo))
+
+	user := &models.User{ID: uuid.New()}
+	savings, err := accounts.Create(context.Background(), &models.BankAccount{
+		ID:                   uuid.New(),
+		UserID:               user.ID,
+		AccountType:          models.AccountTypeSavings,
+		TigerBeetleAccountID: 67890,
+	})
+	require.NoError(t, err)
+
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockTB.On("Deposit", uint64(67890), uint64(2500), mock.AnythingOfType("uint64")).Return(nil)
+
+	require.NoError(t, service.DepositToAccount(context.Background(), savings.ID, 2500))
+
+	// El depósito queda registrado contra la cuenta, por lo que cuenta como actividad para la
+	// detección de cuentas durmientes
+	require.Len(t, txRepo.created, 1)
+	assert.Equal(t, models.TransactionTypeDeposit, txRepo.created[0].TransactionType)
+	assert.Equal(t, &savings.ID, txRepo.created[0].ToAccountID)
+	assert.Equal(t, &user.ID, txRepo.created[0].RecipientUserID)
+	assert.Nil(t, txRepo.created[0].CreatedBy)
+}
+
+func TestUserService_TransferBetweenAccounts_SameOwner(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	accounts := newMemoryBankAccountRepository()
+	service := db.NewUserService(mockRepo, mockTB, db.WithBankAccountRepository(accounts))
+
+	user := &models.User{ID: uuid.New()}
+	checking, _ := accounts.Create(context.Background(), &models.BankAccount{ID: uuid.New(), UserID: user.ID, TigerBeetleAccountID: 111})
+	savings, _ := accounts.Create(context.Background(), &models.BankAccount{ID: uuid.New(), UserID: user.ID, TigerBeetleAccountID: 222})
+
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockTB.On("GetAccountBalance", uint64(111)).Return(uint64(0), uint64(10000), nil)
+	mockTB.On("Transfer", uint64(111), uint64(222), uint64(4000), mock.AnythingOfType("uint64")).Return(nil)
+
+	transferFee, err := service.TransferBetweenAccounts(context.Background(), checking.ID, savings.ID, 4000)
+	require.NoError(t, err)
+	assert.Zero(t, transferFee)
+	mockTB.AssertExpectations(t)
+
+	_, err = service.TransferBetweenAccounts(context.Background(), checking.ID, checking.ID, 4000)
+	assert.ErrorIs(t, err, db.ErrSameAccount)
+}
+
+func TestUserService_TransferBetweenAccounts_RejectsInactiveAccount(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	accounts := newMemoryBankAccountRepository()
+	service := db.NewUserService(mockRepo, mockTB, db.WithBankAccountRepository(accounts))
+
+	from := &models.User{ID: uuid.New()}
+	to := &models.User{ID: uuid.New()}
+	fromAccount, _ := accounts.Create(context.Background(), &models.BankAccount{ID: uuid.New(), UserID: from.ID, TigerBeetleAccountID: 111})
+	toAccount, _ := accounts.Create(context.Background(), &models.BankAccount{ID: uuid.New(), UserID: to.ID, TigerBeetleAccountID: 222})
+	require.NoError(t, accounts.Delete(context.Background(), toAccount.ID))
+
+	mockRepo.On("GetByID", mock.Anything, from.ID).Return(from, nil)
+	mockRepo.On("GetByID", mock.Anything, to.ID).Return(to, nil)
+
+	_, err := service.TransferBetweenAccounts(context.Background(), fromAccount.ID, toAccount.ID, 1000)
+	assert.ErrorIs(t, err, db.ErrAccountInactive)
+	assert.Empty(t, mockTB.Calls)
+}
+
+func TestUserService_WithdrawFromAccount_EnforcesMinimumBalance(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	accounts := newMemoryBankAccountRepository()
+	service := db.NewUserService(mockRepo, mockTB, db.WithBankAccountRepository(accounts))
+
+	user := &models.User{ID: uuid.New(), DailyWithdrawalLimitCents: 1000000}
+	savings, _ := accounts.Create(context.Background(), &models.BankAccount{
+		ID:                   uuid.New(),
+		UserID:               user.ID,
+		AccountType:          models.AccountTypeSavings,
+		TigerBeetleAccountID: 333,
+		MinimumBalanceCents:  50000,
+	})
+
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockTB.On("GetAccountBalance", uint64(333)).Return(uint64(0), uint64(60000), nil)
+	mockTB.On("Withdraw", uint64(333), uint64(10000), mock.AnythingOfType("uint64")).Return(nil)
+
+	// Dejaría 499.99 en una cuenta de ahorros con mínimo de 500.00
+	err := service.WithdrawFromAccount(context.Background(), savings.ID, 10001)
+	assert.ErrorIs(t, err, db.ErrBelowMinimumBalance)
+	mockTB.AssertNotCalled(t, "Withdraw", mock.Anything, mock.Anything, mock.Anything)
+
+	// Dejar exactamente el mínimo está permitido
+	require.NoError(t, service.WithdrawFromAccount(context.Background(), savings.ID, 10000))
+	mockTB.AssertExpectations(t)
+}
+
+func TestUserService_WithdrawFromAccount_NeverOverdraws(t *testing.T) {
+	stub := tigerbeetle.NewServiceStub()
+	require.NoError(t, stub.InitializeMasterAccounts())
+	_, err := stub.CreateUserAccount(444)
+	require.NoError(t, err)
+	require.NoError(t, stub.Deposit(444, 5000, 1))
+
+	mockRepo := new(mocks.MockUserRepository)
+	accounts := newMemoryBankAccountRepository()
+	service := db.NewUserService(mockRepo, stub, db.WithBankAccountRepository(accounts))
+
+	user := &models.User{ID: uuid.New(), DailyWithdrawalLimitCents: 1000000}
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+
+	// Una cuenta marcada con sobregiro antes de desactivarse tampoco puede quedar en negativo
+	checking, _ := accounts.Create(context.Background(), &models.BankAccount{
+		ID:                   uuid.New(),
+		UserID:               user.ID,
+		AccountType:          models.AccountTypeChecking,
+		TigerBeetleAccountID: 444,
+		OverdraftEnabled:     true,
+		OverdraftLimitCents:  20000,
+	})
+
+	var fundsErr *db.InsufficientFundsError
+	require.ErrorAs(t, service.WithdrawFromAccount(context.Background(), checking.ID, 5001), &fundsErr)
+	assert.Equal(t, uint64(5000), fundsErr.Actual)
+
+	require.NoError(t, service.WithdrawFromAccount(context.Background(), checking.ID, 5000))
+	debits, credits, err := stub.GetAccountBalance(444)
+	require.NoError(t, err)
+	assert.Equal(t, debits, credits)
+}
+
+func TestUserService_FrozenAccountRejectsOperations(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	service := db.NewUserService(mockRepo, mockTB)
+
+	accountID := int64(12345)
+	frozen := &models.User{ID: uuid.New(), TigerBeetleAccountID: &accountID, IsFrozen: true}
+	active := &models.User{ID: uuid.New(), TigerBeetleAccountID: &accountID}
+	mockRepo.On("GetByID", mock.Anything, frozen.ID).Return(frozen, nil)
+	mockRepo.On("GetByID", mock.Anything, active.ID).Return(active, nil)
+
+	assert.ErrorIs(t, service.DepositToUser(context.Background(), frozen.ID, 1000), db.ErrAccountFrozen)
+	assert.ErrorIs(t, service.WithdrawFromUser(context.Background(), frozen.ID, 1000), db.ErrAccountFrozen)
+
+	_, err := service.TransferBetweenUsers(context.Background(), active.ID, frozen.ID, 1000)
+	assert.ErrorIs(t, err, db.ErrAccountFrozen)
+
+	// No se debe tocar TigerBeetle
+	assert.Empty(t, mockTB.Calls)
+}
+
+// memoryAuditLogRepository guarda los eventos de auditoría en memoria
+type memoryAuditLogRepository struct {
+	entries []*models.AuditLog
+}
+
+func (r *memoryAuditLogRepository) Record(ctx context.Context, entry *models.AuditLog) error {
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *memoryAuditLogRepository) ListAuthEvents(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*models.AuditLog, error) {
+	return nil, nil
+}
+
+func (r *memoryAuditLogRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.AuditLog, error) {
+	var entries []*models.AuditLog
+	for _, entry := range r.entries {
+		if (entry.ActorUserID != nil && *entry.ActorUserID == userID) || entry.EntityID == userID.String() {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// loginEvents registra en orden el cierre de la transacción de login y las auditorías
+type loginEvents []string
+
+// Connect, Driver, Open, Begin, Prepare, Close, Commit y Rollback implementan un driver de
+// database/sql mínimo cuyas transacciones solo se registran en loginEvents
+func (e *loginEvents) Connect(ctx context.Context) (driver.Conn, error) {
+	return e, nil
+}
+
+func (e *loginEvents) Driver() driver.Driver {
+	return e
+}
+
+func (e *loginEvents) Open(name string) (driver.Conn, error) {
+	return e, nil
+}
+
+func (e *loginEvents) Begin() (driver.Tx, error) {
+	return e, nil
+}
+
+func (e *loginEvents) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("queries not supported")
+}
+
+func (e *loginEvents) Close() error {
+	return nil
+}
+
+func (e *loginEvents) Commit() error {
+	*e = append(*e, "commit")
+	return nil
+}
+
+func (e *loginEvents) Rollback() error {
+	*e = append(*e, "rollback")
+	return nil
+}
+
+// Record registra la acción auditada
+func (e *loginEvents) Record(ctx context.Context, entry *models.AuditLog) error {
+	*e = append(*e, entry.Action)
+	return nil
+}
 
+func (e *loginEvents) ListAuthEvents(ctx context.Context, userID uuid.UUID, from, to time.Time) ([]*models.AuditLog, error) {
+	return nil, nil
+}
+
+func (e *loginEvents) ListByUser(ctx context.Context, userID uuid.UUID) ([]*models.AuditLog, error) {
+	return nil, nil
+}
+
+func TestUserService_Authenticate_ReleasesLockBeforeAuditing(t *testing.T) {
+	for _, tc := range []struct {
+		password string
+		verify   error
+		events   []string
+	}{
+		{"wrong", errors.New("mismatch"), []string{"rollback", models.AuditActionLoginFailed}},
+		{"Password1!", nil, []string{"commit", models.AuditActionLogin}},
+	} {
+		t.Run(tc.password, func(t *testing.T) {
+			events := &loginEvents{}
+			mockRepo := new(mocks.MockUserRepository)
+			service := db.NewUserService(mockRepo, nil, db.WithAuditLogRepository(events))
+
+			tx, err := sql.OpenDB(events).Begin()
+			require.NoError(t, err)
+			user := &models.User{ID: uuid.New(), Email: "test@example.com", PasswordHash: "hash", IsActive: true}
+			mockRepo.On("BeginTx", mock.Anything).Return(tx, nil)
+			mockRepo.On("GetByEmailForUpdate", mock.Anything, tx, user.Email).Return(user, nil)
+			mockRepo.On("VerifyPassword", user.PasswordHash, tc.password).Return(tc.verify)
+
+			_, err = service.Authenticate(context.Background(), user.Email, tc.password)
+			if tc.verify != nil {
+				assert.ErrorIs(t, err, db.ErrInvalidCredentials)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			// La transacción se cierra una sola vez y antes de auditar
+			assert.Equal(t, tc.events, []string(*events))
+		})
+	}
+}
+
+func TestUserService_FinancialOperationsAreAudited(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	auditRepo := &memoryAuditLogRepository{}
+	service := db.NewUserService(mockRepo, mockTB, db.WithAuditLogRepository(auditRepo))
+
+	accountID := int64(12345)
+	active := &models.User{ID: uuid.New(), TigerBeetleAccountID: &accountID}
+	frozen := &models.User{ID: uuid.New(), TigerBeetleAccountID: &accountID, IsFrozen: true}
+	mockRepo.On("GetByID", mock.Anything, active.ID).Return(active, nil)
+	mockRepo.On("GetByID", mock.Anything, frozen.ID).Return(frozen, nil)
+	mockTB.On("Deposit", uint64(accountID), uint64(5000), mock.AnythingOfType("uint64")).Return(nil)
+
+	ctx := audit.WithRemoteAddr(context.Background(), "203.0.113.7:51234")
+	require.NoError(t, service.DepositToUser(ctx, active.ID, 5000))
+	require.ErrorIs(t, service.WithdrawFromUser(ctx, frozen.ID, 1000), db.ErrAccountFrozen)
+
+	require.Len(t, auditRepo.entries, 2)
+
+	deposit := auditRepo.entries[0]
+	assert.Equal(t, models.AuditActionDeposit, deposit.Action)
+	assert.Equal(t, active.ID, *deposit.ActorUserID)
+	assert.Equal(t, int64(5000), *deposit.AmountCents)
+	assert.Equal(t, models.AuditStatusSuccess, deposit.Status)
+	assert.Empty(t, deposit.ErrorText)
+	assert.Equal(t, "203.0.113.7:51234", deposit.RemoteAddr)
+
+	withdrawal := auditRepo.entries[1]
+	assert.Equal(t, models.AuditActionWithdrawal, withdrawal.Action)
+	assert.Equal(t, models.AuditStatusFailure, withdrawal.Status)
+	assert.Equal(t, db.ErrAccountFrozen.Error(), withdrawal.ErrorText)
+}
+
+func TestUserService_FreezeAccount(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	service := db.NewUserService(mockRepo, nil)
+
+	userID := uuid.New()
+	mockRepo.On("Freeze", mock.Anything, userID, "suspicious activity").Return(nil)
+	mockRepo.On("Unfreeze", mock.Anything, userID).Return(nil)
+
+	require.NoError(t, service.FreezeAccount(userID, "suspicious activity"))
+	require.NoError(t, service.UnfreezeAccount(userID))
 	mockRepo.AssertExpectations(t)
+}
+
+func TestGenerateTigerBeetleAccountID_NoCollisions(t *testing.T) {
+	seen := make(map[uint64]uuid.UUID, 10000)
+	for i := 0; i < 10000; i++ {
+		id := uuid.New()
+		accountID := db.GenerateTigerBeetleAccountID(id)
+
+		assert.Greater(t, accountID, uint64(tigerbeetle.FeeAccount), "reserved ID generated for %s", id)
+		assert.NotEqual(t, uint64(tigerbeetle.CorrectionAccount), accountID)
+
+		previous, exists := seen[accountID]
+		require.False(t, exists, "UUIDs %s and %s map to the same account ID", previous, id)
+		seen[accountID] = id
+	}
+}
+
+func TestGenerateTigerBeetleAccountID_UsesAllBytes(t *testing.T) {
+	a := uuid.MustParse("11111111-2222-3333-4444-555555555555")
+	b := uuid.MustParse("11111111-2222-3333-4444-555555555556")
+
+	assert.NotEqual(t, db.GenerateTigerBeetleAccountID(a), db.GenerateTigerBeetleAccountID(b))
+}
+
+func TestGenerateTigerBeetleAccountID_AvoidsReservedIDs(t *testing.T) {
+	// Ambas mitades iguales: el XOR da 0, que está reservado
+	id := uuid.MustParse("00000000-0000-0001-0000-000000000001")
+
+	accountID := db.GenerateTigerBeetleAccountID(id)
+	assert.Greater(t, accountID, uint64(tigerbeetle.FeeAccount))
+	assert.Equal(t, accountID, db.GenerateTigerBeetleAccountID(id))
+}
+
+func TestUserService_ListUsers_ReturnsNextCursor(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	service := db.NewUserService(mockRepo, nil)
+
+	users := []*models.User{{ID: uuid.New()}, {ID: uuid.New()}, {ID: uuid.New()}}
+	mockRepo.On("List", mock.Anything, (*uuid.UUID)(nil), 3).Return(users, nil)
+	mockRepo.On("List", mock.Anything, &users[1].ID, 3).Return(users[2:], nil)
+
+	page, err := service.ListUsers(nil, 2)
+	require.NoError(t, err)
+	assert.Len(t, page.Users, 2)
+	require.NotNil(t, page.NextCursor)
+	assert.Equal(t, users[1].ID, *page.NextCursor)
+
+	page, err = service.ListUsers(page.NextCursor, 2)
+	require.NoError(t, err)
+	assert.Len(t, page.Users, 1)
+	assert.Nil(t, page.NextCursor)
+}
+
+func TestUserService_SearchUsers_PaginatesResults(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	service := db.NewUserService(mockRepo, nil)
+
+	users := []*models.User{{ID: uuid.New(), Email: "ana@example.com"}, {ID: uuid.New(), Email: "anabel@example.com"}}
+	mockRepo.On("Search", mock.Anything, "ana", 2, (*uuid.UUID)(nil)).Return(users, nil)
+
+	page, err := service.SearchUsers("ana", nil, 1)
+	require.NoError(t, err)
+	require.Len(t, page.Users, 1)
+	assert.Equal(t, "ana@example.com", page.Users[0].Email)
+	require.NotNil(t, page.NextCursor)
+	assert.Equal(t, users[0].ID, *page.NextCursor)
+}
+
+func TestUserService_ExportUserData_CollectsEverything(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	accounts := newMemoryBankAccountRepository()
+	transactions := &pagedTransactionRepository{}
+	auditRepo := &memoryAuditLogRepository{}
+	service := db.NewUserService(mockRepo, nil,
+		db.WithBankAccountRepository(accounts),
+		db.WithTransactionRepository(transactions),
+		db.WithAuditLogRepository(auditRepo),
+	)
+
+	user := &models.User{ID: uuid.New(), Email: "ana@example.com", PasswordHash: "secret-hash"}
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+
+	accounts.Create(context.Background(), &models.BankAccount{ID: uuid.New(), UserID: user.ID})
+	accounts.Create(context.Background(), &models.BankAccount{ID: uuid.New(), UserID: uuid.New()})
+	for i := 0; i < 3; i++ {
+		transactions.transactions = append(transactions.transactions, &models.Transaction{ID: uuid.New()})
+	}
+	auditRepo.Record(context.Background(), &models.AuditLog{ActorUserID: &user.ID, Action: models.AuditActionLogin})
+	other := uuid.New()
+	auditRepo.Record(context.Background(), &models.AuditLog{ActorUserID: &other, Action: models.AuditActionLogin})
+
+	export, err := service.ExportUserData(context.Background(), user.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, export.User.ID)
+	assert.Len(t, export.BankAccounts, 1)
+	assert.Len(t, export.Transactions, 3)
+	assert.Len(t, export.AuditLogs, 1)
+}
+
+func TestUserService_EraseUserData_RequiresZeroBalance(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	service := db.NewUserService(mockRepo, mockTB)
+
+	accountID := int64(555)
+	user := &models.User{ID: uuid.New(), TigerBeetleAccountID: &accountID}
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockTB.On("GetAccountBalance", uint64(accountID)).Return(uint64(0), uint64(100), nil).Once()
+
+	err := service.EraseUserData(context.Background(), user.ID)
+	assert.ErrorIs(t, err, db.ErrBalanceRemaining)
+	mockRepo.AssertNotCalled(t, "HardDelete", mock.Anything, mock.Anything)
+
+	mockTB.On("GetAccountBalance", uint64(accountID)).Return(uint64(100), uint64(100), nil).Once()
+	mockRepo.On("HardDelete", mock.Anything, user.ID).Return(nil)
+
+	require.NoError(t, service.EraseUserData(context.Background(), user.ID))
+	mockRepo.AssertCalled(t, "HardDelete", mock.Anything, user.ID)
+}
+
+func TestUserService_UpdatePhone_NormalizesAndValidates(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	service := db.NewUserService(mockRepo, mockTB)
+
+	userID := uuid.New()
+	err := service.UpdatePhone(context.Background(), userID, "55 1234")
+	assert.ErrorIs(t, err, validation.ErrInvalidPhone)
+	mockRepo.AssertNotCalled(t, "UpdatePhone", mock.Anything, mock.Anything, mock.Anything)
+
+	mockRepo.On("UpdatePhone", mock.Anything, userID, "+5215512345678").Return(nil)
+	require.NoError(t, service.UpdatePhone(context.Background(), userID, "+52 155-1234-5678"))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_RecordLogin(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	service := db.NewUserService(mockRepo, mockTB)
+
+	userID := uuid.New()
+	mockRepo.On("UpdateLastLogin", mock.Anything, userID).Return(nil).Once()
+	require.NoError(t, service.RecordLogin(context.Background(), userID))
+
+	mockRepo.On("UpdateLastLogin", mock.Anything, userID).Return(db.ErrUserNotFound).Once()
+	assert.ErrorIs(t, service.RecordLogin(context.Background(), userID), db.ErrUserNotFound)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_WithdrawFromUser_RequiresApprovedKYCAboveThreshold(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+
+	service := db.NewUserService(mockRepo, mockTB, db.WithKYCThreshold(500000))
+
+	accountID := int64(12345)
+	user := &models.User{
+		ID:                   uuid.New(),
+		Email:                "test@example.com",
+		TigerBeetleAccountID: &accountID,
+		KYCStatus:            models.KYCStatusSubmitted,
+	}
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockTB.On("GetAccountBalance", uint64(accountID)).Return(uint64(0), uint64(1000000), nil)
+	mockTB.On("Withdraw", uint64(accountID), mock.AnythingOfType("uint64"), mock.AnythingOfType("uint64")).Return(nil)
+
+	// Hasta el umbral no se exige KYC
+	require.NoError(t, service.WithdrawFromUser(context.Background(), user.ID, 500000))
+
+	err := service.WithdrawFromUser(context.Background(), user.ID, 500001)
+	assert.ErrorIs(t, err, compliance.ErrKYCRequired)
+
+	user.KYCStatus = models.KYCStatusApproved
+	require.NoError(t, service.WithdrawFromUser(context.Background(), user.ID, 500001))
+	mockTB.AssertNumberOfCalls(t, "Withdraw", 2)
+}
+
+// recordingTransactionRepository guarda las transacciones creadas y las categorías actualizadas
+type recordingTransactionRepository struct {
+	db.TransactionRepository
+	created    []*models.Transaction
+	categories map[uuid.UUID]string
+}
+
+func (r *recordingTransactionRepository) Create(ctx context.Context, tx *models.Transaction) (*models.Transaction, error) {
+	created := *tx
+	created.ID = uuid.New()
+	r.created = append(r.created, &created)
+	return &created, nil
+}
+
+// GetDailyTotal suma lo registrado a nombre del usuario con el tipo indicado, como si todo fuera del día
+func (r *recordingTransactionRepository) GetDailyTotal(ctx context.Context, userID uuid.UUID, txType string, date time.Time) (uint64, error) {
+	var total uint64
+	for _, tx := range r.created {
+		if tx.CreatedBy != nil && *tx.CreatedBy == userID && tx.TransactionType == txType &&
+			tx.Status == models.TransactionStatusCompleted {
+			total += uint64(tx.Amount)
+		}
+	}
+	return total, nil
+}
+
+func (r *recordingTransactionRepository) UpdateCategory(ctx context.Context, userID, txID uuid.UUID, category string) error {
+	for _, tx := range r.created {
+		if tx.ID == txID && *tx.CreatedBy == userID {
+			r.categories[txID] = category
+			return nil
+		}
+	}
+	return db.ErrTransactionNotFound
+}
+
+func TestUserService_TransferBetweenUsers_RecordsCategoryAndTags(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	txRepo := &recordingTransactionRepository{}
+	service := db.NewUserService(mockRepo, mockTB, db.WithTransactionRepository(txRepo))
+
+	fromAccountID, toAccountID := int64(111), int64(222)
+	fromUser := &models.User{ID: uuid.New(), TigerBeetleAccountID: &fromAccountID, DailyTransferLimitCents: 1000000}
+	toUser := &models.User{ID: uuid.New(), TigerBeetleAccountID: &toAccountID}
+
+	mockRepo.On("GetByID", mock.Anything, fromUser.ID).Return(fromUser, nil)
+	mockRepo.On("GetByID", mock.Anything, toUser.ID).Return(toUser, nil)
+	mockTB.On("GetAccountBalance", uint64(fromAccountID)).Return(uint64(0), uint64(10000), nil)
+	mockTB.On("Transfer", uint64(fromAccountID), uint64(toAccountID), uint64(5000), mock.AnythingOfType("uint64")).Return(nil)
+
+	_, err := service.TransferBetweenUsers(context.Background(), fromUser.ID, toUser.ID, 5000,
+		db.WithTransferCategory("  Groceries ", []string{"market", " market", "weekly"}))
+	require.NoError(t, err)
+
+	require.Len(t, txRepo.created, 1)
+	tx := txRepo.created[0]
+	assert.Equal(t, "groceries", tx.Category)
+	assert.Equal(t, []string{"market", "weekly"}, tx.Tags)
+	assert.Equal(t, int64(5000), tx.Amount)
+	assert.Equal(t, models.TransactionTypeTransfer, tx.TransactionType)
+	assert.Equal(t, &fromUser.ID, tx.CreatedBy)
+	// Las cuentas principales no están en bank_accounts
+	assert.Nil(t, tx.FromAccountID)
+	assert.Nil(t, tx.ToAccountID)
+}
+
+func TestUserService_SplitTransfer_RecordsPartialFailures(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	txRepo := &recordingTransactionRepository{}
+	service := db.NewUserService(mockRepo, mockTB, db.WithTransactionRepository(txRepo))
+
+	fromAccountID, firstAccountID, secondAccountID := int64(111), int64(222), int64(333)
+	fromUser := &models.User{ID: uuid.New(), TigerBeetleAccountID: &fromAccountID, DailyTransferLimitCents: 1000000}
+	first := &models.User{ID: uuid.New(), TigerBeetleAccountID: &firstAccountID, IsActive: true}
+	second := &models.User{ID: uuid.New(), TigerBeetleAccountID: &secondAccountID, IsActive: true}
+	for _, user := range []*models.User{fromUser, first, second} {
+		mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	}
+
+	// Una sola verificación de fondos por el total y un solo lote sin enlazar las partes
+	mockTB.On("GetAccountBalance", uint64(fromAccountID)).Return(uint64(0), uint64(10000), nil)
+	mockTB.On("BatchTransfer", mock.MatchedBy(func(groups [][]tigerbeetle.LinkedTransferRequest) bool {
+		return len(groups) == 2 && len(groups[0]) == 1 && len(groups[1]) == 1 &&
+			groups[0][0].ToAccountID == uint64(firstAccountID) && groups[0][0].Amount == 3000 &&
+			groups[1][0].ToAccountID == uint64(secondAccountID) && groups[1][0].Amount == 2000
+	})).Return([]error{nil, errors.New("transfer failed: credit account not found")}, nil).Once()
+
+	ids, err := service.SplitTransfer(context.Background(), fromUser.ID, []models.SplitTarget{
+		{ToUserID: first.ID, AmountCents: 3000},
+		{ToUserID: second.ID, AmountCents: 2000},
+	}, "  Cena del viernes ")
+
+	var splitErr *db.SplitTransferError
+	require.ErrorAs(t, err, &splitErr)
+	require.Len(t, splitErr.Failures, 1)
+	assert.Equal(t, second.ID, splitErr.Failures[0].ToUserID)
+
+	require.Len(t, ids, 2)
+	require.Len(t, txRepo.created, 2)
+	assert.Equal(t, ids[0], txRepo.created[0].ID)
+	assert.Equal(t, models.TransactionStatusCompleted, txRepo.created[0].Status)
+	assert.Equal(t, models.TransactionStatusFailed, txRepo.created[1].Status)
+	assert.Equal(t, "Cena del viernes", txRepo.created[0].Description)
+	assert.Equal(t, &second.ID, txRepo.created[1].RecipientUserID)
+	mockTB.AssertNumberOfCalls(t, "GetAccountBalance", 1)
 	mockTB.AssertExpectations(t)
 }
+
+func TestUserService_SplitTransfer_ChecksFundsForTotal(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	service := db.NewUserService(mockRepo, mockTB)
+
+	fromAccountID, firstAccountID, secondAccountID := int64(111), int64(222), int64(333)
+	fromUser := &models.User{ID: uuid.New(), TigerBeetleAccountID: &fromAccountID}
+	first := &models.User{ID: uuid.New(), TigerBeetleAccountID: &firstAccountID, IsActive: true}
+	second := &models.User{ID: uuid.New(), TigerBeetleAccountID: &secondAccountID, IsActive: true}
+	for _, user := range []*models.User{fromUser, first, second} {
+		mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	}
+	mockTB.On("GetAccountBalance", uint64(fromAccountID)).Return(uint64(0), uint64(4000), nil)
+
+	// Cada parte cabe en el balance, pero el total no
+	_, err := service.SplitTransfer(context.Background(), fromUser.ID, []models.SplitTarget{
+		{ToUserID: first.ID, AmountCents: 3000},
+		{ToUserID: second.ID, AmountCents: 2000},
+	}, "")
+	var fundsErr *db.InsufficientFundsError
+	require.ErrorAs(t, err, &fundsErr)
+	assert.Equal(t, uint64(5000), fundsErr.Expected)
+	mockTB.AssertNotCalled(t, "BatchTransfer", mock.Anything)
+}
+
+func TestUserService_SplitTransfer_RejectsInvalidSplits(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	service := db.NewUserService(mockRepo, new(MockTigerBeetleService))
+	fromID, toID := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name   string
+		splits []models.SplitTarget
+		want   error
+	}{
+		{"no recipients", nil, db.ErrInvalidSplitTransfer},
+		{"zero amount", []models.SplitTarget{{ToUserID: toID, AmountCents: 0}}, db.ErrInvalidSplitTransfer},
+		{"repeated recipient", []models.SplitTarget{{ToUserID: toID, AmountCents: 100}, {ToUserID: toID, AmountCents: 200}}, db.ErrInvalidSplitTransfer},
+		{"sender as recipient", []models.SplitTarget{{ToUserID: fromID, AmountCents: 100}}, db.ErrSameAccount},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.SplitTransfer(context.Background(), fromID, tt.splits, "")
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+	mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func TestUserService_TransferBetweenUsers_RejectsInvalidTags(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	service := db.NewUserService(mockRepo, mockTB)
+
+	_, err := service.TransferBetweenUsers(context.Background(), uuid.New(), uuid.New(), 5000,
+		db.WithTransferCategory("food", []string{"ok", "  "}))
+	assert.ErrorIs(t, err, db.ErrInvalidTags)
+
+	_, err = service.TransferBetweenUsers(context.Background(), uuid.New(), uuid.New(), 5000,
+		db.WithTransferCategory(strings.Repeat("x", models.MaxCategoryLength+1), nil))
+	assert.ErrorIs(t, err, db.ErrInvalidCategory)
+
+	mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	mockTB.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUserService_UpdateTransactionCategory(t *testing.T) {
+	userID := uuid.New()
+	tx := &models.Transaction{ID: uuid.New(), CreatedBy: &userID}
+	txRepo := &recordingTransactionRepository{created: []*models.Transaction{tx}, categories: map[uuid.UUID]string{}}
+	service := db.NewUserService(new(mocks.MockUserRepository), nil, db.WithTransactionRepository(txRepo))
+
+	require.NoError(t, service.UpdateTransactionCategory(context.Background(), userID, tx.ID, " Rent "))
+	assert.Equal(t, "rent", txRepo.categories[tx.ID])
+
+	err := service.UpdateTransactionCategory(context.Background(), uuid.New(), tx.ID, "rent")
+	assert.ErrorIs(t, err, db.ErrTransactionNotFound)
+}
+
+func TestUserService_GetUserStats_IncludesPrimaryAccount(t *testing.T) {
+	stub := tigerbeetle.NewServiceStub()
+	require.NoError(t, stub.InitializeMasterAccounts())
+	for _, accountID := range []uint64{6001, 6002} {
+		_, err := stub.CreateUserAccount(accountID)
+		require.NoError(t, err)
+	}
+	require.NoError(t, stub.Deposit(6001, 7000, 1))
+	require.NoError(t, stub.Deposit(6002, 3000, 2))
+
+	mockRepo := new(mocks.MockUserRepository)
+	accounts := newMemoryBankAccountRepository()
+	service := db.NewUserService(mockRepo, stub, db.WithBankAccountRepository(accounts))
+
+	primaryID := int64(6001)
+	user := &models.User{ID: uuid.New(), TigerBeetleAccountID: &primaryID}
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	_, err := accounts.Create(context.Background(), &models.BankAccount{ID: uuid.New(), UserID: user.ID, AccountType: models.AccountTypeSavings, TigerBeetleAccountID: 6002})
+	require.NoError(t, err)
+
+	stats, err := service.GetUserStats(context.Background(), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10000), stats.TotalBalance)
+	require.Len(t, stats.Accounts, 2)
+	assert.Equal(t, models.AccountTypePrimary, stats.Accounts[0].AccountType)
+	assert.Equal(t, int64(7000), stats.Accounts[0].Balance)
+
+	listed, err := service.GetUserAccounts(context.Background(), user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, stats.Accounts, listed)
+}
@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/internal/fraud"
+	"banca-en-linea/backend/internal/mocks"
+	"banca-en-linea/backend/models"
+)
+
+// stubVelocityStore retorna siempre la misma actividad reciente
+type stubVelocityStore struct {
+	count int
+	total uint64
+	since time.Time
+}
+
+func (s *stubVelocityStore) GetVelocity(ctx context.Context, userID uuid.UUID, txType string, since time.Time) (int, uint64, error) {
+	s.since = since
+	return s.count, s.total, nil
+}
+
+func TestVelocityChecker_AllowsNormalActivity(t *testing.T) {
+	store := &stubVelocityStore{count: 3, total: 10000}
+	checker := fraud.NewVelocityChecker(store)
+
+	err := checker.Check(context.Background(), uuid.New(), 5000, models.TransactionTypeTransfer, 100000)
+
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(-fraud.VelocityWindow), store.since, time.Second)
+}
+
+func TestVelocityChecker_RejectsTooManyTransactions(t *testing.T) {
+	checker := fraud.NewVelocityChecker(&stubVelocityStore{count: fraud.MaxTransactionsPerWindow})
+
+	err := checker.Check(context.Background(), uuid.New(), 100, models.TransactionTypeDeposit, 100000)
+
+	assert.ErrorIs(t, err, fraud.ErrVelocityExceeded)
+}
+
+func TestVelocityChecker_RejectsHalfTheDailyLimit(t *testing.T) {
+	checker := fraud.NewVelocityChecker(&stubVelocityStore{count: 1, total: 40000})
+
+	assert.NoError(t, checker.Check(context.Background(), uuid.New(), 10000, models.TransactionTypeTransfer, 100000))
+	assert.ErrorIs(t, checker.Check(context.Background(), uuid.New(), 10001, models.TransactionTypeTransfer, 100000), fraud.ErrVelocityExceeded)
+}
+
+func TestUserService_TransferBetweenUsers_ChecksVelocityBeforeFunds(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	checker := fraud.NewVelocityChecker(&stubVelocityStore{count: fraud.MaxTransactionsPerWindow})
+	service := db.NewUserService(mockRepo, mockTB, db.WithVelocityChecker(checker))
+
+	fromAccountID, toAccountID := int64(111), int64(222)
+	from := &models.User{ID: uuid.New(), TigerBeetleAccountID: &fromAccountID, DailyTransferLimitCents: 1000000}
+	to := &models.User{ID: uuid.New(), TigerBeetleAccountID: &toAccountID}
+	mockRepo.On("GetByID", mock.Anything, from.ID).Return(from, nil)
+	mockRepo.On("GetByID", mock.Anything, to.ID).Return(to, nil)
+
+	_, err := service.TransferBetweenUsers(context.Background(), from.ID, to.ID, 1000)
+
+	assert.ErrorIs(t, err, fraud.ErrVelocityExceeded)
+	mockTB.AssertNotCalled(t, "GetAccountBalance", mock.Anything)
+}
+
+func TestUserService_DepositToUser_RecordedDepositsCountForVelocity(t *testing.T) {
+	mockRepo := new(mocks.MockUserRepository)
+	mockTB := new(MockTigerBeetleService)
+	txRepo := &ledgerTransactionRepository{}
+	service := db.NewUserService(mockRepo, mockTB, db.WithTransactionRepository(txRepo),
+		db.WithVelocityChecker(fraud.NewVelocityChecker(txRepo)))
+
+	accountID := int64(12345)
+	user := &models.User{ID: uuid.New(), TigerBeetleAccountID: &accountID, DailyTransferLimitCents: 1000000}
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockTB.On("Deposit", uint64(accountID), uint64(100), mock.AnythingOfType("uint64")).Return(nil)
+
+	for i := 0; i < fraud.MaxTransactionsPerWindow; i++ {
+		require.NoError(t, service.DepositToUser(context.Background(), user.ID, 100))
+	}
+
+	// Los depósitos a la cuenta principal quedan registrados y cuentan para la ráfaga
+	err := service.DepositToUser(context.Background(), user.ID, 100)
+	assert.ErrorIs(t, err, fraud.ErrVelocityExceeded)
+	mockTB.AssertNumberOfCalls(t, "Deposit", fraud.MaxTransactionsPerWindow)
+}
@@ -0,0 +1,225 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"banca-en-linea/backend/internal/db"
+	"banca-en-linea/backend/models"
+)
+
+// memoryWebhookRepository es un repositorio de webhooks en memoria para testing
+type memoryWebhookRepository struct {
+	db.WebhookRepository
+	webhooks []*models.Webhook
+}
+
+func (r *memoryWebhookRepository) Create(ctx context.Context, webhook *models.Webhook) (*models.Webhook, error) {
+	created := *webhook
+	created.ID = uuid.New()
+	created.Active = true
+	r.webhooks = append(r.webhooks, &created)
+	return &created, nil
+}
+
+func (r *memoryWebhookRepository) ListActiveForEvent(ctx context.Context, userIDs []uuid.UUID, eventType string) ([]*models.Webhook, error) {
+	var matching []*models.Webhook
+	for _, webhook := range r.webhooks {
+		for _, userID := range userIDs {
+			if webhook.UserID == userID && webhook.Active && webhook.Subscribes(eventType) {
+				matching = append(matching, webhook)
+			}
+		}
+	}
+	return matching, nil
+}
+
+// allowAllAddresses permite webhooks hacia los servidores de prueba, que escuchan en loopback
+var allowAllAddresses = db.WithWebhookAddressFilter(func(netip.Addr) bool { return true })
+
+func TestWebhookService_Create_Validates(t *testing.T) {
+	service := db.NewWebhookService(&memoryWebhookRepository{})
+	userID := uuid.New()
+
+	_, err := service.Create(context.Background(), userID, &models.CreateWebhookRequest{URL: "http://203.0.113.10/hook", Events: []string{"deposit"}})
+	assert.ErrorIs(t, err, db.ErrInvalidWebhookURL)
+
+	_, err = service.Create(context.Background(), userID, &models.CreateWebhookRequest{URL: "https://203.0.113.10/hook", Events: []string{"refund"}})
+	assert.ErrorIs(t, err, db.ErrInvalidWebhookEvents)
+
+	webhook, err := service.Create(context.Background(), userID, &models.CreateWebhookRequest{URL: "https://203.0.113.10/hook", Events: []string{"deposit"}})
+	require.NoError(t, err)
+	assert.Len(t, webhook.Secret, 64)
+	assert.Equal(t, userID, webhook.UserID)
+}
+
+func TestWebhookService_Create_RejectsInternalAddresses(t *testing.T) {
+	service := db.NewWebhookService(&memoryWebhookRepository{})
+
+	for _, target := range []string{
+		"https://localhost/hook",
+		"https://127.0.0.1/hook",
+		"https://[::1]/hook",
+		"https://10.0.0.5/hook",
+		"https://192.168.1.20:8443/hook",
+		"https://169.254.169.254/latest/meta-data",
+		"https://[fd00:ec2::254]/latest/meta-data",
+		"https://100.100.100.200/latest/meta-data",
+		"https://0.0.0.0/hook",
+	} {
+		_, err := service.Create(context.Background(), uuid.New(), &models.CreateWebhookRequest{URL: target, Events: []string{"deposit"}})
+		assert.ErrorIs(t, err, db.ErrWebhookAddressNotAllowed, target)
+	}
+}
+
+func TestWebhookService_Deliver_RefusesInternalAddressOnDial(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+	}))
+	defer server.Close()
+
+	// Un webhook registrado con un host público cuyo DNS ahora apunta a loopback
+	userID := uuid.New()
+	repo := &memoryWebhookRepository{webhooks: []*models.Webhook{{
+		ID: uuid.New(), UserID: userID, URL: server.URL, Secret: "secret", Active: true,
+		Events: []string{models.TransactionEventDeposit},
+	}}}
+	service := db.NewWebhookService(repo, db.WithWebhookRetryBaseDelay(time.Millisecond))
+
+	err := service.Deliver(models.TransactionEvent{ID: "evt-1", Type: models.TransactionEventDeposit, Amount: 500, ToUserID: &userID})
+	assert.ErrorIs(t, err, db.ErrWebhookAddressNotAllowed)
+	assert.Equal(t, int32(0), attempts.Load())
+}
+
+func TestWebhookService_Deliver_SignsAndRetries(t *testing.T) {
+	var attempts atomic.Int32
+	var signature, body string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Las dos primeras entregas fallan
+		if attempts.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		payload, _ := io.ReadAll(r.Body)
+		body = string(payload)
+		signature = r.Header.Get(db.WebhookSignatureHeader)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	repo := &memoryWebhookRepository{}
+	service := db.NewWebhookService(repo,
+		db.WithWebhookHTTPClient(server.Client()),
+		db.WithWebhookRetryBaseDelay(time.Millisecond),
+		allowAllAddresses)
+
+	userID := uuid.New()
+	webhook, err := service.Create(context.Background(), userID, &models.CreateWebhookRequest{URL: server.URL, Events: []string{models.TransactionEventDeposit}})
+	require.NoError(t, err)
+
+	err = service.Deliver(models.TransactionEvent{ID: "evt-1", Type: models.TransactionEventDeposit, Amount: 500, ToUserID: &userID})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), attempts.Load())
+	assert.Contains(t, body, `"id":"evt-1"`)
+	assert.Equal(t, db.SignWebhookPayload(webhook.Secret, []byte(body)), signature)
+
+	// Los eventos a los que no está suscrito no se entregan
+	err = service.Deliver(models.TransactionEvent{ID: "evt-2", Type: models.TransactionEventWithdrawal, Amount: 500, FromUserID: &userID})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func TestWebhookService_Deliver_GivesUpAfterMaxRetries(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	service := db.NewWebhookService(&memoryWebhookRepository{},
+		db.WithWebhookHTTPClient(server.Client()),
+		db.WithWebhookRetryBaseDelay(time.Millisecond),
+		allowAllAddresses)
+
+	userID := uuid.New()
+	_, err := service.Create(context.Background(), userID, &models.CreateWebhookRequest{URL: server.URL, Events: []string{models.TransactionEventTransfer}})
+	require.NoError(t, err)
+
+	err = service.Deliver(models.TransactionEvent{ID: "evt-1", Type: models.TransactionEventTransfer, Amount: 500, FromUserID: &userID})
+	assert.ErrorContains(t, err, "status 500")
+	// Un intento inicial más tres reintentos
+	assert.Equal(t, int32(4), attempts.Load())
+}
+
+func TestWebhookService_PublishDeliversInBackground(t *testing.T) {
+	delivered := make(chan struct{}, 1)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered <- struct{}{}
+	}))
+	defer server.Close()
+
+	service := db.NewWebhookService(&memoryWebhookRepository{}, db.WithWebhookHTTPClient(server.Client()), allowAllAddresses)
+	userID := uuid.New()
+	_, err := service.Create(context.Background(), userID, &models.CreateWebhookRequest{URL: server.URL, Events: []string{models.TransactionEventDeposit}})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go service.Run(ctx)
+
+	service.Publish(models.TransactionEvent{ID: "evt-1", Type: models.TransactionEventDeposit, Amount: 500, ToUserID: &userID})
+
+	select {
+	case <-delivered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+}
+
+func TestWebhookService_SlowEndpointDoesNotBlockOthers(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	delivered := make(chan struct{}, 1)
+	fast := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered <- struct{}{}
+	}))
+	defer fast.Close()
+
+	// Ambos servidores de prueba usan el mismo certificado, así que un cliente sirve para los dos
+	service := db.NewWebhookService(&memoryWebhookRepository{}, db.WithWebhookHTTPClient(fast.Client()), allowAllAddresses)
+	slowUser, fastUser := uuid.New(), uuid.New()
+	_, err := service.Create(context.Background(), slowUser, &models.CreateWebhookRequest{URL: slow.URL, Events: []string{models.TransactionEventDeposit}})
+	require.NoError(t, err)
+	_, err = service.Create(context.Background(), fastUser, &models.CreateWebhookRequest{URL: fast.URL, Events: []string{models.TransactionEventDeposit}})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go service.Run(ctx)
+
+	service.Publish(models.TransactionEvent{ID: "evt-slow", Type: models.TransactionEventDeposit, Amount: 500, ToUserID: &slowUser})
+	service.Publish(models.TransactionEvent{ID: "evt-fast", Type: models.TransactionEventDeposit, Amount: 500, ToUserID: &fastUser})
+
+	select {
+	case <-delivered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was blocked by a slow endpoint")
+	}
+}
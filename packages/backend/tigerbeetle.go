@@ -3,21 +3,38 @@
 package main
 
 import (
-	"fmt"
+	"context"
 	"log"
 	"net"
 	"os"
-	"strconv"
+	"sync"
+	"time"
 
 	tigerbeetle_go "github.com/tigerbeetle/tigerbeetle-go"
 	"github.com/tigerbeetle/tigerbeetle-go/pkg/types"
 	"go.uber.org/zap"
+
+	internaltb "banca-en-linea/backend/internal/tigerbeetle"
 )
 
 // Variables globales
 var (
 	tb     tigerbeetle_go.Client
 	logger *zap.Logger
+
+	// tbMu protege tb, que la reconexión en segundo plano puede reemplazar mientras se atienden solicitudes
+	tbMu sync.RWMutex
+
+	// stopReconnect detiene la reconexión en curso al cerrar TigerBeetle (nil si no hay ninguna)
+	stopReconnect context.CancelFunc
+)
+
+const (
+	// reconnectInitialDelay es la espera antes del primer intento de reconexión; se duplica en cada intento
+	reconnectInitialDelay = 5 * time.Second
+
+	// reconnectMaxDelay es la espera máxima entre intentos de reconexión
+	reconnectMaxDelay = 60 * time.Second
 )
 
 // init inicializa el logger
@@ -29,20 +46,10 @@ func init() {
 	}
 }
 
-// getEnv obtiene una variable de entorno con un valor por defecto
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
-// initTigerBeetle inicializa la conexión a TigerBeetle
-func initTigerBeetle() {
+// initTigerBeetle inicializa la conexión a TigerBeetle en la dirección indicada (Config.TigerBeetleAddress)
+func initTigerBeetle(tigerBeetleAddress string) {
 	logger.Info("🔧 Inicializando conexión a TigerBeetle")
 
-	// Obtener dirección de TigerBeetle
-	tigerBeetleAddress := getEnv("TIGERBEETLE_ADDRESS", "localhost:3000")
 	logger.Info("Configurando TigerBeetle", zap.String("address", tigerBeetleAddress))
 
 	// Resolver dirección IP si es necesario
@@ -64,95 +71,96 @@ func initTigerBeetle() {
 		logger.Debug("Configurando TIGERBEETLE_DISABLE_IO_URING", zap.String("disabled", "false"))
 	}
 
-	// Configurar cluster ID
-	clusterID := uint128FromString("0")
-	logger.Debug("Configurando cluster ID", zap.String("cluster_id", "0"))
-
 	// Crear cliente TigerBeetle con configuración simplificada
-	var err error
-	client, err := tigerbeetle_go.NewClient(clusterID, []string{tigerBeetleAddress})
-	tb = client
+	client, err := newTigerBeetleClient(tigerBeetleAddress)
 	if err != nil {
 		logger.Error("❌ Error conectando a TigerBeetle",
 			zap.String("address", tigerBeetleAddress),
 			zap.Error(err),
 		)
-		logger.Warn("⚠️ Continuando sin TigerBeetle - funcionalidad limitada")
-		tb = nil
-	} else {
-		logger.Info("✅ Conectado exitosamente a TigerBeetle")
-	}
-}
-
-// getAccountBalance obtiene el balance de una cuenta desde TigerBeetle
-func getAccountBalance(accountID uint64) (int64, error) {
-	logger.Debug("Consultando balance en TigerBeetle", zap.Uint64("account_id", accountID))
+		logger.Warn("⚠️ Continuando sin TigerBeetle - funcionalidad limitada, reintentando en segundo plano")
 
-	if tb == nil {
-		logger.Warn("TigerBeetle no está disponible")
-		return 0, fmt.Errorf("TigerBeetle not available")
-	}
+		ctx, cancel := context.WithCancel(context.Background())
+		tbMu.Lock()
+		tb = nil
+		stopReconnect = cancel
+		tbMu.Unlock()
 
-	// Cast tb a tigerbeetle_go.Client para acceder a métodos específicos
-	client, ok := tb.(tigerbeetle_go.Client)
-	if !ok {
-		logger.Error("Error: tb no es un cliente TigerBeetle válido")
-		return 0, fmt.Errorf("invalid TigerBeetle client")
+		go reconnectTigerBeetle(ctx, tigerBeetleAddress)
+		return
 	}
 
-	accounts, err := client.LookupAccounts([]types.Uint128{types.ToUint128(accountID)})
-	if err != nil {
-		logger.Error("Error consultando cuenta en TigerBeetle",
-			zap.Uint64("account_id", accountID),
-			zap.Error(err),
-		)
-		return 0, err
-	}
+	tbMu.Lock()
+	tb = client
+	tbMu.Unlock()
+	logger.Info("✅ Conectado exitosamente a TigerBeetle")
+}
 
-	if len(accounts) == 0 {
-		logger.Warn("Cuenta no encontrada en TigerBeetle", zap.Uint64("account_id", accountID))
-		return 0, fmt.Errorf("account not found")
-	}
+// newTigerBeetleClient crea un cliente TigerBeetle para el cluster 0 en la dirección indicada
+func newTigerBeetleClient(address string) (tigerbeetle_go.Client, error) {
+	clusterID := types.ToUint128(0)
+	logger.Debug("Configurando cluster ID", zap.String("cluster_id", "0"))
 
-	// Convertir Uint128 a uint64 usando String() y strconv
-	creditsStr := accounts[0].CreditsPosted.String()
-	debitsStr := accounts[0].DebitsPosted.String()
+	return tigerbeetle_go.NewClient(clusterID, []string{address})
+}
 
-	creditsPosted, err := strconv.ParseUint(creditsStr, 10, 64)
-	if err != nil {
-		logger.Error("Error parseando créditos",
-			zap.Uint64("account_id", accountID),
-			zap.String("credits_str", creditsStr),
-			zap.Error(err),
+// reconnectTigerBeetle intenta crear el cliente TigerBeetle cada reconnectInitialDelay, duplicando la
+// espera hasta reconnectMaxDelay, hasta conectarse o hasta que se cancele ctx. Al conectarse
+// reemplaza tb, así que las operaciones financieras vuelven a estar disponibles sin reiniciar.
+func reconnectTigerBeetle(ctx context.Context, address string) {
+	backoff := internaltb.ReconnectBackoff{InitialDelay: reconnectInitialDelay, MaxDelay: reconnectMaxDelay}
+	internaltb.Reconnect(ctx, backoff, func(attempt int) error {
+		logger.Info("Reintentando conexión a TigerBeetle",
+			zap.Int("attempt", attempt),
+			zap.String("address", address),
 		)
-		return 0, fmt.Errorf("error parsing credits: %v", err)
-	}
 
-	debitsPosted, err := strconv.ParseUint(debitsStr, 10, 64)
-	if err != nil {
-		logger.Error("Error parseando débitos",
-			zap.Uint64("account_id", accountID),
-			zap.String("debits_str", debitsStr),
-			zap.Error(err),
-		)
-		return 0, fmt.Errorf("error parsing debits: %v", err)
-	}
+		client, err := newTigerBeetleClient(address)
+		if err != nil {
+			logger.Warn("Error reconectando a TigerBeetle",
+				zap.Int("attempt", attempt),
+				zap.Error(err),
+			)
+			return err
+		}
 
-	// Calcular balance
-	balance := int64(creditsPosted) - int64(debitsPosted)
+		tbMu.Lock()
+		// Si se cerró TigerBeetle mientras se conectaba, descartar el cliente nuevo
+		if ctx.Err() != nil {
+			tbMu.Unlock()
+			client.Close()
+			return nil
+		}
+		tb = client
+		stopReconnect = nil
+		tbMu.Unlock()
 
-	logger.Debug("Balance calculado exitosamente",
-		zap.Uint64("account_id", accountID),
-		zap.Int64("balance", balance),
-		zap.Uint64("credits", creditsPosted),
-		zap.Uint64("debits", debitsPosted),
-	)
+		logger.Info("✅ Reconectado exitosamente a TigerBeetle",
+			zap.Int("attempt", attempt),
+			zap.String("address", address),
+		)
+		return nil
+	})
+}
 
-	return balance, nil
+// tigerBeetleClient retorna el cliente TigerBeetle actual (nil si no está conectado)
+func tigerBeetleClient() tigerbeetle_go.Client {
+	tbMu.RLock()
+	defer tbMu.RUnlock()
+	return tb
 }
 
-// uint128FromString convierte un string a Uint128
-func uint128FromString(s string) types.Uint128 {
-	val, _ := strconv.ParseUint(s, 10, 64)
-	return types.ToUint128(val)
+// closeTigerBeetle detiene la reconexión en curso y cierra el cliente de TigerBeetle si está conectado
+func closeTigerBeetle() {
+	tbMu.Lock()
+	defer tbMu.Unlock()
+
+	if stopReconnect != nil {
+		stopReconnect()
+		stopReconnect = nil
+	}
+	if tb != nil {
+		tb.Close()
+		tb = nil
+	}
 }
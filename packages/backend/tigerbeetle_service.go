@@ -0,0 +1,14 @@
+//go:build !ci && !docker
+
+package main
+
+import (
+	internaltb "banca-en-linea/backend/internal/tigerbeetle"
+)
+
+// newTigerBeetleService crea el servicio de TigerBeetle que usan los servicios de la aplicación.
+// Opera con el cliente que administra initTigerBeetle, también después de una reconexión; mientras
+// no haya conexión sus operaciones fallan con ErrNotConnected.
+func newTigerBeetleService() internaltb.TigerBeetleService {
+	return internaltb.NewServiceWithClient(tigerBeetleClient)
+}
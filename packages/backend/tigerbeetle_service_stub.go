@@ -0,0 +1,19 @@
+//go:build ci || docker
+
+package main
+
+import (
+	"log"
+
+	internaltb "banca-en-linea/backend/internal/tigerbeetle"
+)
+
+// newTigerBeetleService crea el servicio de TigerBeetle que usan los servicios de la aplicación.
+// En los builds de CI y Docker el paquete tigerbeetle es el stub en memoria.
+func newTigerBeetleService() internaltb.TigerBeetleService {
+	service := internaltb.NewServiceStub()
+	if err := service.InitializeMasterAccounts(); err != nil {
+		log.Fatalf("Error inicializando cuentas maestras TigerBeetle: %v", err)
+	}
+	return service
+}
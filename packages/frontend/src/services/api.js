@@ -34,6 +34,7 @@ api.interceptors.response.use(
     if (error.response?.status === 401) {
       // Token expirado o inválido
       localStorage.removeItem('authToken');
+      localStorage.removeItem('refreshToken');
       localStorage.removeItem('user');
       window.location.href = '/login';
     }
@@ -60,6 +61,7 @@ export const authService = {
       
       if (response.data.token) {
         localStorage.setItem('authToken', response.data.token);
+        localStorage.setItem('refreshToken', response.data.refresh_token);
         localStorage.setItem('user', JSON.stringify(response.data.user));
         console.log('🌐 API: Token y usuario guardados en localStorage');
       }
@@ -75,13 +77,51 @@ export const authService = {
 
   logout: async () => {
     try {
-      await api.post('/auth/logout');
+      const refreshToken = localStorage.getItem('refreshToken');
+      await api.post('/auth/logout', refreshToken ? { refresh_token: refreshToken } : {});
     } finally {
       localStorage.removeItem('authToken');
+      localStorage.removeItem('refreshToken');
       localStorage.removeItem('user');
     }
   },
 
+  refresh: async () => {
+    const response = await api.post('/auth/refresh', {
+      refresh_token: localStorage.getItem('refreshToken'),
+    });
+    localStorage.setItem('authToken', response.data.token);
+    return response.data;
+  },
+
+  verifyEmail: async (token) => {
+    const response = await api.post('/auth/verify-email', { token });
+    return response.data;
+  },
+
+  forgotPassword: async (email) => {
+    const response = await api.post('/auth/forgot-password', { email });
+    return response.data;
+  },
+
+  resetPassword: async (token, newPassword) => {
+    const response = await api.post('/auth/reset-password', { token, new_password: newPassword });
+    return response.data;
+  },
+
+  confirmTotp: async (mfaChallengeToken, code) => {
+    const response = await api.post('/auth/totp/confirm', {
+      mfa_challenge_token: mfaChallengeToken,
+      code,
+    });
+    if (response.data.token) {
+      localStorage.setItem('authToken', response.data.token);
+      localStorage.setItem('refreshToken', response.data.refresh_token);
+      localStorage.setItem('user', JSON.stringify(response.data.user));
+    }
+    return response.data;
+  },
+
   getCurrentUser: async () => {
     const response = await api.get('/auth/me');
     return response.data;
@@ -99,8 +139,21 @@ export const authService = {
 
 // Servicios de usuarios
 export const userService = {
-  getUsers: async (limit = 10, offset = 0) => {
-    const response = await api.get(`/users?limit=${limit}&offset=${offset}`);
+  // Retorna { users, next_cursor }; next_cursor se envía como `after` para la página siguiente.
+  // query filtra por email, nombre o apellido.
+  getUsers: async (limit = 10, after = null, query = '') => {
+    const params = new URLSearchParams({ limit });
+    if (after) params.set('after', after);
+    if (query) params.set('q', query);
+    const response = await api.get(`/users?${params}`);
+    return response.data;
+  },
+
+  changePassword: async (userId, currentPassword, newPassword) => {
+    const response = await api.post(`/users/${userId}/change-password`, {
+      current_password: currentPassword,
+      new_password: newPassword,
+    });
     return response.data;
   },
 
@@ -120,25 +173,72 @@ export const userService = {
   }
 };
 
+// Servicios de cuentas bancarias
+export const accountService = {
+  createAccount: async (userId, accountType, currency) => {
+    const response = await api.post(`/users/${userId}/accounts`, {
+      account_type: accountType,
+      currency,
+    });
+    return response.data;
+  },
+
+  getAccount: async (accountId) => {
+    const response = await api.get(`/accounts/${accountId}`);
+    return response.data;
+  },
+
+  updateAccount: async (accountId, updates) => {
+    const response = await api.patch(`/accounts/${accountId}`, updates);
+    return response.data;
+  },
+
+  deleteAccount: async (accountId) => {
+    await api.delete(`/accounts/${accountId}`);
+  }
+};
+
 // Servicios de transacciones
 export const transactionService = {
-  deposit: async (userId, amount) => {
-    const response = await api.post(`/users/${userId}/deposit`, { amount });
+  // La llave de idempotencia permite reintentar la operación sin ejecutarla dos veces
+  deposit: async (userId, amount, idempotencyKey = crypto.randomUUID()) => {
+    const response = await api.post(`/users/${userId}/deposit`, { amount }, {
+      headers: { 'X-Idempotency-Key': idempotencyKey }
+    });
     return response.data;
   },
 
-  withdraw: async (userId, amount) => {
-    const response = await api.post(`/users/${userId}/withdraw`, { amount });
+  withdraw: async (userId, amount, idempotencyKey = crypto.randomUUID()) => {
+    const response = await api.post(`/users/${userId}/withdraw`, { amount }, {
+      headers: { 'X-Idempotency-Key': idempotencyKey }
+    });
     return response.data;
   },
 
-  transfer: async (fromUserId, toUserId, amount) => {
+  lookupAccount: async (accountNumber) => {
+    const response = await api.get(`/accounts/lookup?account_number=${encodeURIComponent(accountNumber)}`);
+    return response.data;
+  },
+
+  transfer: async (fromUserId, toUserId, amount, confirmedAccountNumber, idempotencyKey = crypto.randomUUID()) => {
     const response = await api.post('/transfer', {
       from_user_id: fromUserId,
       to_user_id: toUserId,
-      amount
+      amount,
+      confirmed_account_number: confirmedAccountNumber
+    }, {
+      headers: { 'X-Idempotency-Key': idempotencyKey }
     });
     return response.data;
+  },
+
+  getHistory: async (userId, after = null, limit = 20, filters = {}) => {
+    const params = { ...filters, limit };
+    if (after) {
+      params.after = after;
+    }
+    const response = await api.get(`/users/${userId}/transactions`, { params });
+    return response.data;
   }
 };
 
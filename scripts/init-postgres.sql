@@ -104,10 +104,10 @@ BEGIN
     
     -- Crear cuentas bancarias de prueba
     INSERT INTO bank_accounts (user_id, account_number, account_type, tigerbeetle_account_id) VALUES
-    (admin_user_id, '1000000001', 'checking', 1),
-    (admin_user_id, '1000000002', 'savings', 2),
-    (test_user_id, '1000000003', 'checking', 3),
-    (test_user_id, '1000000004', 'savings', 4);
+    (admin_user_id, '100000000016', 'checking', 1),
+    (admin_user_id, '200000000014', 'savings', 2),
+    (test_user_id, '100000000024', 'checking', 3),
+    (test_user_id, '200000000022', 'savings', 4);
 END $$;
 
 COMMIT;
\ No newline at end of file